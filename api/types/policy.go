@@ -99,3 +99,10 @@ var ViewerPolicy = []*PolicyDocument{
 		},
 	},
 }
+
+// RequestAction describes the verb that a request performs against a
+// resource in a given scope
+type RequestAction struct {
+	Verb     APIVerb
+	Resource NameOrUInt
+}
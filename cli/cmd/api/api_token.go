@@ -0,0 +1,121 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// CreateAPITokenRequest represents the accepted fields for creating
+// a project API token
+type CreateAPITokenRequest struct {
+	Name     string `json:"name"`
+	Kind     string `json:"kind"`
+	PolicyID uint   `json:"policy_id,omitempty"`
+	TTL      string `json:"ttl,omitempty"`
+}
+
+// CreateAPITokenResponse is the resulting API token after creation, which
+// contains the encoded token
+type CreateAPITokenResponse models.APITokenExternal
+
+// CreateAPIToken creates a new API token for a project
+func (c *Client) CreateAPIToken(
+	ctx context.Context,
+	projectID uint,
+	createToken *CreateAPITokenRequest,
+) (*CreateAPITokenResponse, error) {
+	data, err := json.Marshal(createToken)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(
+		"POST",
+		fmt.Sprintf("%s/projects/%d/api_tokens", c.BaseURL, projectID),
+		strings.NewReader(string(data)),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	bodyResp := &CreateAPITokenResponse{}
+
+	if httpErr, err := c.sendRequest(req, bodyResp, true); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return nil, fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return nil, err
+	}
+
+	return bodyResp, nil
+}
+
+// ListAPITokensResponse is the list of API tokens for a project
+type ListAPITokensResponse []models.APITokenExternal
+
+// ListAPITokens returns the list of API tokens for a project
+func (c *Client) ListAPITokens(
+	ctx context.Context,
+	projectID uint,
+) (ListAPITokensResponse, error) {
+	req, err := http.NewRequest(
+		"GET",
+		fmt.Sprintf("%s/projects/%d/api_tokens", c.BaseURL, projectID),
+		nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	bodyResp := &ListAPITokensResponse{}
+
+	if httpErr, err := c.sendRequest(req, bodyResp, true); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return nil, fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return nil, err
+	}
+
+	return *bodyResp, nil
+}
+
+// RevokeAPIToken revokes an API token given a project id and token id
+func (c *Client) RevokeAPIToken(
+	ctx context.Context,
+	projectID uint,
+	tokenID uint,
+) error {
+	req, err := http.NewRequest(
+		"POST",
+		fmt.Sprintf("%s/projects/%d/api_tokens/%d/revoke", c.BaseURL, projectID, tokenID),
+		nil,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	req = req.WithContext(ctx)
+
+	if httpErr, err := c.sendRequest(req, nil, true); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return err
+	}
+
+	return nil
+}
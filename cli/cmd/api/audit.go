@@ -0,0 +1,84 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// ListAuditEventsRequest represents the accepted filters for listing the
+// audit events of a project
+type ListAuditEventsRequest struct {
+	UserID       uint
+	ResourceType string
+
+	// Since and Until are RFC 3339 timestamps
+	Since string
+	Until string
+
+	Limit int
+	Skip  int
+}
+
+// ListAuditEventsResponse is the list of audit events for a project
+type ListAuditEventsResponse []models.AuditEventExternal
+
+// ListAuditEvents returns the audit events for a project, newest first
+func (c *Client) ListAuditEvents(
+	ctx context.Context,
+	projectID uint,
+	listReq *ListAuditEventsRequest,
+) (ListAuditEventsResponse, error) {
+	vals := url.Values{}
+
+	if listReq.UserID != 0 {
+		vals.Set("user_id", strconv.FormatUint(uint64(listReq.UserID), 10))
+	}
+
+	if listReq.ResourceType != "" {
+		vals.Set("resource_type", listReq.ResourceType)
+	}
+
+	if listReq.Since != "" {
+		vals.Set("since", listReq.Since)
+	}
+
+	if listReq.Until != "" {
+		vals.Set("until", listReq.Until)
+	}
+
+	if listReq.Limit != 0 {
+		vals.Set("limit", strconv.Itoa(listReq.Limit))
+	}
+
+	if listReq.Skip != 0 {
+		vals.Set("skip", strconv.Itoa(listReq.Skip))
+	}
+
+	req, err := http.NewRequest(
+		"GET",
+		fmt.Sprintf("%s/projects/%d/audit_logs", c.BaseURL, projectID)+"?"+vals.Encode(),
+		nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	bodyResp := &ListAuditEventsResponse{}
+
+	if httpErr, err := c.sendRequest(req, bodyResp, true); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return nil, fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return nil, err
+	}
+
+	return *bodyResp, nil
+}
@@ -8,6 +8,7 @@ import (
 	"net/url"
 	"strings"
 
+	"github.com/porter-dev/porter/internal/helm/diff"
 	"github.com/porter-dev/porter/internal/models"
 )
 
@@ -180,8 +181,9 @@ func (c *Client) DeployTemplate(
 }
 
 type UpgradeReleaseRequest struct {
-	Values    string `json:"values"`
-	Namespace string `json:"namespace"`
+	Values       string `json:"values"`
+	Namespace    string `json:"namespace"`
+	ChartVersion string `json:"version,omitempty"`
 }
 
 func (c *Client) UpgradeRelease(
@@ -222,3 +224,45 @@ func (c *Client) UpgradeRelease(
 
 	return nil
 }
+
+// PreviewUpgradeRelease renders an upgrade of a release without applying it, and
+// returns the changes to the values and rendered resources of the release
+func (c *Client) PreviewUpgradeRelease(
+	ctx context.Context,
+	projID, clusterID uint,
+	name string,
+	upgradeReq *UpgradeReleaseRequest,
+) (*diff.Preview, error) {
+	data, err := json.Marshal(upgradeReq)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(
+		"POST",
+		fmt.Sprintf("%s/projects/%d/releases/%s/upgrade/preview?"+url.Values{
+			"namespace":  []string{upgradeReq.Namespace},
+			"cluster_id": []string{fmt.Sprintf("%d", clusterID)},
+			"storage":    []string{"secret"},
+		}.Encode(), c.BaseURL, projID, name),
+		strings.NewReader(string(data)),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	bodyResp := &diff.Preview{}
+
+	if httpErr, err := c.sendRequest(req, bodyResp, true); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return nil, fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return nil, err
+	}
+
+	return bodyResp, nil
+}
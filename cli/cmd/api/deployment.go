@@ -0,0 +1,67 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// ListDeploymentsRequest represents the accepted filters for listing the
+// deployments of a release
+type ListDeploymentsRequest struct {
+	Namespace string
+
+	Limit int
+	Skip  int
+}
+
+// ListDeploymentsResponse is the list of deployments for a release
+type ListDeploymentsResponse []models.DeploymentExternal
+
+// ListDeployments returns the recorded deployments of a release, newest first
+func (c *Client) ListDeployments(
+	ctx context.Context,
+	projectID, clusterID uint,
+	name string,
+	listReq *ListDeploymentsRequest,
+) (ListDeploymentsResponse, error) {
+	vals := url.Values{}
+
+	vals.Set("cluster_id", strconv.FormatUint(uint64(clusterID), 10))
+	vals.Set("namespace", listReq.Namespace)
+
+	if listReq.Limit != 0 {
+		vals.Set("limit", strconv.Itoa(listReq.Limit))
+	}
+
+	if listReq.Skip != 0 {
+		vals.Set("skip", strconv.Itoa(listReq.Skip))
+	}
+
+	req, err := http.NewRequest(
+		"GET",
+		fmt.Sprintf("%s/projects/%d/releases/%s/deployments", c.BaseURL, projectID, name)+"?"+vals.Encode(),
+		nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	bodyResp := &ListDeploymentsResponse{}
+
+	if httpErr, err := c.sendRequest(req, bodyResp, true); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return nil, fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return nil, err
+	}
+
+	return *bodyResp, nil
+}
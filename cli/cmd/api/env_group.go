@@ -0,0 +1,125 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/server/api"
+)
+
+// ListEnvGroupsResponse is the list of env groups in a namespace
+type ListEnvGroupsResponse []*models.EnvGroupExternal
+
+// ListEnvGroups lists the env groups in a namespace
+func (c *Client) ListEnvGroups(
+	ctx context.Context,
+	projectID, clusterID uint,
+	namespace string,
+) (ListEnvGroupsResponse, error) {
+	req, err := http.NewRequest(
+		"GET",
+		fmt.Sprintf("%s/projects/%d/k8s/%s/env_groups?"+url.Values{
+			"cluster_id": []string{fmt.Sprintf("%d", clusterID)},
+		}.Encode(), c.BaseURL, projectID, namespace),
+		nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	bodyResp := ListEnvGroupsResponse{}
+
+	if httpErr, err := c.sendRequest(req, &bodyResp, true); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return nil, fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return nil, err
+	}
+
+	return bodyResp, nil
+}
+
+// LinkEnvGroupReleaseResponse is the env group after linking a release, along with
+// the result of upgrading the release
+type LinkEnvGroupReleaseResponse api.EnvGroupUpdateResponse
+
+// LinkEnvGroupRelease links a release to an env group, and upgrades the release
+// with the variables of the env group
+func (c *Client) LinkEnvGroupRelease(
+	ctx context.Context,
+	projectID, clusterID uint,
+	namespace, name string,
+	linkReq *forms.LinkEnvGroupReleaseForm,
+) (*LinkEnvGroupReleaseResponse, error) {
+	data, err := json.Marshal(linkReq)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(
+		"POST",
+		fmt.Sprintf("%s/projects/%d/k8s/%s/env_groups/%s/releases?"+url.Values{
+			"cluster_id": []string{fmt.Sprintf("%d", clusterID)},
+			"storage":    []string{"secret"},
+		}.Encode(), c.BaseURL, projectID, namespace, name),
+		strings.NewReader(string(data)),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	bodyResp := &LinkEnvGroupReleaseResponse{}
+
+	if httpErr, err := c.sendRequest(req, bodyResp, true); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return nil, fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return nil, err
+	}
+
+	return bodyResp, nil
+}
+
+// UnlinkEnvGroupRelease unlinks a release from an env group
+func (c *Client) UnlinkEnvGroupRelease(
+	ctx context.Context,
+	projectID, clusterID uint,
+	namespace, name, releaseName string,
+) error {
+	req, err := http.NewRequest(
+		"DELETE",
+		fmt.Sprintf("%s/projects/%d/k8s/%s/env_groups/%s/releases/%s?"+url.Values{
+			"cluster_id": []string{fmt.Sprintf("%d", clusterID)},
+		}.Encode(), c.BaseURL, projectID, namespace, name, releaseName),
+		nil,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	req = req.WithContext(ctx)
+
+	if httpErr, err := c.sendRequest(req, nil, true); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return err
+	}
+
+	return nil
+}
@@ -65,6 +65,7 @@ type CreatePrivateRegistryRequest struct {
 	Name               string `json:"name"`
 	URL                string `json:"url"`
 	BasicIntegrationID uint   `json:"basic_integration_id"`
+	Service            string `json:"service,omitempty"`
 }
 
 // CreatePrivateRegistryResponse is the resulting registry after creation
@@ -406,6 +407,114 @@ func (c *Client) GetDOCRAuthorizationToken(
 	return bodyResp, nil
 }
 
+// GetBasicTokenRequest is the server url of a GHCR, ACR, Quay or Harbor
+// registry to get a token for
+type GetBasicTokenRequest struct {
+	ServerURL string `json:"server_url"`
+}
+
+// GetBasicAuthorizationToken gets an authorization token for a GHCR, ACR, Quay or
+// Harbor registry
+func (c *Client) GetBasicAuthorizationToken(
+	ctx context.Context,
+	projectID uint,
+	basicRequest *GetBasicTokenRequest,
+) (*GetTokenResponse, error) {
+	data, err := json.Marshal(basicRequest)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(
+		"GET",
+		fmt.Sprintf("%s/projects/%d/registries/basic/token", c.BaseURL, projectID),
+		strings.NewReader(string(data)),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	bodyResp := &GetTokenResponse{}
+	req = req.WithContext(ctx)
+
+	if httpErr, err := c.sendRequest(req, bodyResp, true); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return nil, fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return nil, err
+	}
+
+	return bodyResp, nil
+}
+
+// PromoteImageRequest is the image to promote from one registry to another, and
+// the release to upgrade to the promoted image
+type PromoteImageRequest struct {
+	RepositoryName       string                      `json:"repository_name"`
+	Digest               string                      `json:"digest,omitempty"`
+	Tag                  string                      `json:"tag,omitempty"`
+	TargetRegistryID     uint                        `json:"target_registry_id"`
+	TargetRepositoryName string                      `json:"target_repository_name,omitempty"`
+	TargetTag            string                      `json:"target_tag,omitempty"`
+	Release              *PromoteImageReleaseRequest `json:"release,omitempty"`
+}
+
+// PromoteImageReleaseRequest identifies the release to upgrade to a promoted image
+type PromoteImageReleaseRequest struct {
+	ClusterID uint   `json:"cluster_id"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// PromoteImageResponse is the promoted image, pinned to its digest, along with the
+// revision of the release that was upgraded to it
+type PromoteImageResponse struct {
+	Image    string `json:"image"`
+	Digest   string `json:"digest"`
+	Tag      string `json:"tag"`
+	Revision int    `json:"revision"`
+}
+
+// PromoteImage copies an image from a registry to another registry of the project
+func (c *Client) PromoteImage(
+	ctx context.Context,
+	projectID uint,
+	registryID uint,
+	promoteReq *PromoteImageRequest,
+) (*PromoteImageResponse, error) {
+	data, err := json.Marshal(promoteReq)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequest(
+		"POST",
+		fmt.Sprintf("%s/projects/%d/registries/%d/promote", c.BaseURL, projectID, registryID),
+		strings.NewReader(string(data)),
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	bodyResp := &PromoteImageResponse{}
+
+	if httpErr, err := c.sendRequest(req, bodyResp, true); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return nil, fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return nil, err
+	}
+
+	return bodyResp, nil
+}
+
 // ListRegistryRepositoryResponse is the list of repositories in a registry
 type ListRegistryRepositoryResponse []registry.Repository
 
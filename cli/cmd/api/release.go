@@ -0,0 +1,81 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// ListReleasesResponse is the list of Helm releases in a namespace
+type ListReleasesResponse []*release.Release
+
+// ListReleases lists the deployed Helm releases in a namespace
+func (c *Client) ListReleases(
+	ctx context.Context,
+	projectID, clusterID uint,
+	namespace string,
+) (ListReleasesResponse, error) {
+	req, err := http.NewRequest(
+		"GET",
+		fmt.Sprintf("%s/projects/%d/releases?"+url.Values{
+			"cluster_id":   []string{fmt.Sprintf("%d", clusterID)},
+			"namespace":    []string{namespace},
+			"storage":      []string{"secret"},
+			"statusFilter": []string{"deployed", "failed", "pending-install", "pending-upgrade", "pending-rollback"},
+		}.Encode(), c.BaseURL, projectID),
+		nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req = req.WithContext(ctx)
+	bodyResp := ListReleasesResponse{}
+
+	if httpErr, err := c.sendRequest(req, &bodyResp, true); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return nil, fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return nil, err
+	}
+
+	return bodyResp, nil
+}
+
+// DeleteRelease uninstalls a Helm release
+func (c *Client) DeleteRelease(
+	ctx context.Context,
+	projectID, clusterID uint,
+	namespace, name string,
+) error {
+	req, err := http.NewRequest(
+		"POST",
+		fmt.Sprintf("%s/projects/%d/delete/%s?"+url.Values{
+			"cluster_id": []string{fmt.Sprintf("%d", clusterID)},
+			"namespace":  []string{namespace},
+			"storage":    []string{"secret"},
+		}.Encode(), c.BaseURL, projectID, name),
+		nil,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	req = req.WithContext(ctx)
+
+	if httpErr, err := c.sendRequest(req, nil, true); httpErr != nil || err != nil {
+		if httpErr != nil {
+			return fmt.Errorf("code %d, errors %v", httpErr.Code, httpErr.Errors)
+		}
+
+		return err
+	}
+
+	return nil
+}
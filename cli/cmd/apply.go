@@ -0,0 +1,189 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/porter-dev/porter/cli/cmd/api"
+	"github.com/porter-dev/porter/cli/cmd/apply"
+	"github.com/porter-dev/porter/internal/porteryaml"
+	"github.com/spf13/cobra"
+)
+
+// applyCmd represents the "porter apply" command
+var applyCmd = &cobra.Command{
+	Use:   "apply",
+	Short: "Creates, upgrades and deletes applications to match a porter.yaml file.",
+	Long: fmt.Sprintf(`
+%s
+
+Declares the applications of a namespace in a porter.yaml file, and converges the namespace to
+the file. The changes to each application are computed against the deployed releases and printed
+before they are applied. For example:
+
+  %s
+
+A porter.yaml file lists the applications of a namespace, along with their chart, chart version,
+values, env groups, build settings and domains:
+
+  version: v1
+  namespace: default
+  apps:
+  - name: web-app
+    chart: web
+    image: gcr.io/snowflake-12345/web-app:latest
+    values_file: ./values.yaml
+    env_groups: [shared-env]
+    domains: [app.example.com]
+  - name: worker-app
+    chart: worker
+    build:
+      repo: porter-dev/worker-app
+      branch: main
+      dockerfile: ./Dockerfile
+
+Declared values are merged over the values of the deployed applications, so values that are not
+declared, such as an image tag updated by a Github action, are left unchanged. Build settings are
+only used when an application is created. If "prune: true" is set, applications in the namespace
+that are not declared in the file are deleted.
+
+To print the changes without applying them, pass the --dry-run flag:
+
+  %s
+`,
+		color.New(color.FgBlue, color.Bold).Sprintf("Help for \"porter apply\":"),
+		color.New(color.FgGreen, color.Bold).Sprintf("porter apply -f porter.yaml"),
+		color.New(color.FgGreen, color.Bold).Sprintf("porter apply -f porter.yaml --dry-run"),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := checkLoginAndRun(args, applyConfig)
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var applyFile string
+var applyDryRun bool
+
+func init() {
+	rootCmd.AddCommand(applyCmd)
+
+	applyCmd.PersistentFlags().StringVarP(
+		&applyFile,
+		"file",
+		"f",
+		"porter.yaml",
+		"the path to the porter.yaml file",
+	)
+
+	applyCmd.PersistentFlags().BoolVar(
+		&applyDryRun,
+		"dry-run",
+		false,
+		"print the changes without applying them",
+	)
+}
+
+func applyConfig(_ *api.AuthCheckResponse, client *api.Client, _ []string) error {
+	conf, err := porteryaml.ReadConfig(applyFile)
+
+	if err != nil {
+		return err
+	}
+
+	applyAgent := &apply.ApplyAgent{
+		Client:    client,
+		ProjectID: config.Project,
+		ClusterID: config.Cluster,
+		Config:    conf,
+	}
+
+	if conf.Project != 0 {
+		applyAgent.ProjectID = conf.Project
+	}
+
+	if conf.Cluster != 0 {
+		applyAgent.ClusterID = conf.Cluster
+	}
+
+	plan, err := applyAgent.Plan()
+
+	if err != nil {
+		return err
+	}
+
+	printApplyPlan(plan)
+
+	if !plan.HasChanges() || applyDryRun {
+		return nil
+	}
+
+	for _, change := range plan.Changes {
+		if !change.HasChanges() {
+			continue
+		}
+
+		color.New(color.FgGreen).Printf("Applying %s: %s\n", change.Action, change.Name)
+
+		if err := applyAgent.ApplyChange(change); err != nil {
+			return fmt.Errorf("%s %s: %v", change.Action, change.Name, err)
+		}
+	}
+
+	color.New(color.FgGreen).Printf("Successfully applied %s\n", applyFile)
+
+	return nil
+}
+
+func printApplyPlan(plan *apply.Plan) {
+	color.New(color.Bold).Printf("Plan for namespace %s:\n", plan.Namespace)
+
+	if !plan.HasChanges() {
+		fmt.Println("No changes")
+		return
+	}
+
+	for _, change := range plan.Changes {
+		if !change.HasChanges() {
+			continue
+		}
+
+		switch change.Action {
+		case apply.ActionCreate:
+			color.New(color.FgGreen).Printf("\n+ create %s (%s)\n", change.Name, change.Chart)
+		case apply.ActionDelete:
+			color.New(color.FgRed).Printf("\n- delete %s (%s)\n", change.Name, change.Chart)
+			continue
+		default:
+			color.New(color.FgYellow).Printf("\n~ update %s (%s)\n", change.Name, change.Chart)
+		}
+
+		if change.ChartVersion != "" {
+			fmt.Printf("  chart version: %s\n", change.ChartVersion)
+		}
+
+		for _, valueChange := range change.ValuesDiff {
+			switch {
+			case valueChange.Old == nil:
+				color.New(color.FgGreen).Printf("  + %s: %v\n", valueChange.Path, valueChange.New)
+			case valueChange.New == nil:
+				color.New(color.FgRed).Printf("  - %s: %v\n", valueChange.Path, valueChange.Old)
+			default:
+				color.New(color.FgYellow).Printf("  ~ %s: %v -> %v\n", valueChange.Path, valueChange.Old, valueChange.New)
+			}
+		}
+
+		for _, name := range change.LinkEnvGroups {
+			color.New(color.FgGreen).Printf("  + env group %s\n", name)
+		}
+
+		for _, name := range change.UnlinkEnvGroups {
+			color.New(color.FgRed).Printf("  - env group %s\n", name)
+		}
+	}
+
+	fmt.Println()
+}
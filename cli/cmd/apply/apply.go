@@ -0,0 +1,181 @@
+package apply
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/porter-dev/porter/cli/cmd/api"
+	"github.com/porter-dev/porter/cli/cmd/deploy"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/porteryaml"
+)
+
+// ApplyAgent computes and applies plans for the namespace of a config
+type ApplyAgent struct {
+	Client    *api.Client
+	ProjectID uint
+	ClusterID uint
+	Config    *porteryaml.Config
+}
+
+// GetDeployed reads the deployed releases and env groups of the namespace
+func (a *ApplyAgent) GetDeployed() (*Deployed, error) {
+	releases, err := a.Client.ListReleases(
+		context.Background(),
+		a.ProjectID,
+		a.ClusterID,
+		a.Config.Namespace,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not list releases: %v", err)
+	}
+
+	envGroups, err := a.Client.ListEnvGroups(
+		context.Background(),
+		a.ProjectID,
+		a.ClusterID,
+		a.Config.Namespace,
+	)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not list env groups: %v", err)
+	}
+
+	return &Deployed{
+		Releases:  releases,
+		EnvGroups: envGroups,
+	}, nil
+}
+
+// Plan computes the plan that converges the namespace to the config
+func (a *ApplyAgent) Plan() (*Plan, error) {
+	deployed, err := a.GetDeployed()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return ComputePlan(a.Config, deployed)
+}
+
+// ApplyChange applies a single change of a plan. Env groups are linked after the
+// release is created or upgraded, since linking upgrades the release again with
+// the variables of the env group.
+func (a *ApplyAgent) ApplyChange(change *Change) error {
+	var err error
+
+	switch change.Action {
+	case ActionCreate:
+		err = a.create(change)
+	case ActionUpgrade:
+		err = a.upgrade(change)
+	case ActionDelete:
+		err = a.Client.DeleteRelease(
+			context.Background(),
+			a.ProjectID,
+			a.ClusterID,
+			a.Config.Namespace,
+			change.Name,
+		)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	for _, name := range change.LinkEnvGroups {
+		resp, err := a.Client.LinkEnvGroupRelease(
+			context.Background(),
+			a.ProjectID,
+			a.ClusterID,
+			a.Config.Namespace,
+			name,
+			&forms.LinkEnvGroupReleaseForm{
+				ReleaseName: change.Name,
+			},
+		)
+
+		if err != nil {
+			return fmt.Errorf("could not link env group %s: %v", name, err)
+		}
+
+		for _, upgrade := range resp.Upgrades {
+			if upgrade.Error != "" {
+				return fmt.Errorf("could not upgrade with env group %s: %s", name, upgrade.Error)
+			}
+		}
+	}
+
+	for _, name := range change.UnlinkEnvGroups {
+		err := a.Client.UnlinkEnvGroupRelease(
+			context.Background(),
+			a.ProjectID,
+			a.ClusterID,
+			a.Config.Namespace,
+			name,
+			change.Name,
+		)
+
+		if err != nil {
+			return fmt.Errorf("could not unlink env group %s: %v", name, err)
+		}
+	}
+
+	return nil
+}
+
+func (a *ApplyAgent) create(change *Change) error {
+	app := change.App
+
+	createAgent := &deploy.CreateAgent{
+		Client: a.Client,
+		CreateOpts: &deploy.CreateOpts{
+			SharedOpts: &deploy.SharedOpts{
+				ProjectID: a.ProjectID,
+				ClusterID: a.ClusterID,
+				Namespace: a.Config.Namespace,
+			},
+			Kind:            app.Chart,
+			ReleaseName:     app.Name,
+			TemplateVersion: change.ChartVersion,
+		},
+	}
+
+	if app.Build == nil {
+		_, err := createAgent.CreateFromRegistry(app.Image, change.Values)
+		return err
+	}
+
+	createAgent.CreateOpts.LocalDockerfile = app.Build.Dockerfile
+	createAgent.CreateOpts.RegistryURL = app.Build.RegistryURL
+
+	_, err := createAgent.CreateFromGithub(&deploy.GithubOpts{
+		Repo:       app.Build.Repo,
+		Branch:     app.Build.Branch,
+		FolderPath: app.Build.Folder,
+	}, change.Values)
+
+	return err
+}
+
+func (a *ApplyAgent) upgrade(change *Change) error {
+	values, err := json.Marshal(change.Values)
+
+	if err != nil {
+		return err
+	}
+
+	return a.Client.UpgradeRelease(
+		context.Background(),
+		a.ProjectID,
+		a.ClusterID,
+		change.Name,
+		&api.UpgradeReleaseRequest{
+			Values:       string(values),
+			Namespace:    a.Config.Namespace,
+			ChartVersion: change.ChartVersion,
+		},
+	)
+}
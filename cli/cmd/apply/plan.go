@@ -0,0 +1,244 @@
+package apply
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/porter-dev/porter/internal/helm/diff"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/porteryaml"
+	"github.com/porter-dev/porter/internal/templater/utils"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// Action is the change that a plan makes to a release
+type Action string
+
+// The actions of a plan
+const (
+	ActionCreate    Action = "create"
+	ActionUpgrade   Action = "upgrade"
+	ActionDelete    Action = "delete"
+	ActionUnchanged Action = "unchanged"
+)
+
+// Deployed is the deployed state of a namespace that a plan is computed against
+type Deployed struct {
+	Releases  []*release.Release
+	EnvGroups []*models.EnvGroupExternal
+}
+
+// Change is the change that a plan makes to a single application
+type Change struct {
+	Action Action
+	Name   string
+	Chart  string
+
+	// ChartVersion is the version of the chart to install or upgrade to. It is
+	// empty if an upgrade keeps the deployed version.
+	ChartVersion string
+
+	// Values are the complete values to install or upgrade the release with, and
+	// ValuesDiff are the changes to the values of the deployed release
+	Values     map[string]interface{}
+	ValuesDiff []*models.ValueChange
+
+	// LinkEnvGroups and UnlinkEnvGroups are the env groups that the release is
+	// linked to and unlinked from after it is created or upgraded
+	LinkEnvGroups   []string
+	UnlinkEnvGroups []string
+
+	// App is the declared app, and is nil for deletes
+	App *porteryaml.App
+}
+
+// HasChanges returns true if the change modifies the release or its env groups
+func (c *Change) HasChanges() bool {
+	return c.Action != ActionUnchanged || len(c.LinkEnvGroups) > 0 || len(c.UnlinkEnvGroups) > 0
+}
+
+// Plan is the set of changes that converges a namespace to a config
+type Plan struct {
+	Namespace string
+	Changes   []*Change
+}
+
+// HasChanges returns true if applying the plan modifies the namespace
+func (p *Plan) HasChanges() bool {
+	for _, change := range p.Changes {
+		if change.HasChanges() {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ComputePlan compares the declared apps of a config with the deployed releases and
+// env groups of the namespace, and returns the changes that converge them. Changes
+// are sorted by name, with deletes last.
+func ComputePlan(conf *porteryaml.Config, deployed *Deployed) (*Plan, error) {
+	plan := &Plan{
+		Namespace: conf.Namespace,
+		Changes:   make([]*Change, 0),
+	}
+
+	releases := make(map[string]*release.Release)
+
+	for _, rel := range deployed.Releases {
+		releases[rel.Name] = rel
+	}
+
+	envGroups := make(map[string]*models.EnvGroupExternal)
+
+	for _, group := range deployed.EnvGroups {
+		envGroups[group.Name] = group
+	}
+
+	declared := make(map[string]bool)
+
+	for _, app := range conf.Apps {
+		declared[app.Name] = true
+
+		for _, name := range app.EnvGroups {
+			if _, ok := envGroups[name]; !ok {
+				return nil, fmt.Errorf("app %s: env group %s does not exist in namespace %s", app.Name, name, conf.Namespace)
+			}
+		}
+
+		change := &Change{
+			Name:  app.Name,
+			Chart: app.Chart,
+			App:   app,
+		}
+
+		desired := app.DesiredValues()
+		rel, exists := releases[app.Name]
+
+		if !exists {
+			if app.Image == "" && app.Build == nil {
+				return nil, fmt.Errorf("app %s: an image or build is required to create the app", app.Name)
+			}
+
+			change.Action = ActionCreate
+			change.ChartVersion = app.Version
+			change.Values = desired
+			change.ValuesDiff = diff.Values(nil, desired)
+			change.LinkEnvGroups = append([]string{}, app.EnvGroups...)
+
+			plan.Changes = append(plan.Changes, change)
+			continue
+		}
+
+		if chartName := releaseChartName(rel); chartName != app.Chart {
+			return nil, fmt.Errorf(
+				"app %s: deployed with chart %s, the chart of an app cannot be changed",
+				app.Name,
+				chartName,
+			)
+		}
+
+		// declared values are merged over the deployed values, so that values that
+		// are managed elsewhere are not reset
+		change.Values = utils.CoalesceValues(diff.CopyValues(rel.Config), desired)
+		change.ValuesDiff = diff.Values(rel.Config, change.Values)
+
+		if app.Version != "" && app.Version != releaseChartVersion(rel) {
+			change.ChartVersion = app.Version
+		}
+
+		if len(change.ValuesDiff) > 0 || change.ChartVersion != "" {
+			change.Action = ActionUpgrade
+		} else {
+			change.Action = ActionUnchanged
+		}
+
+		change.LinkEnvGroups, change.UnlinkEnvGroups = diffEnvGroups(app, deployed.EnvGroups)
+
+		plan.Changes = append(plan.Changes, change)
+	}
+
+	sort.SliceStable(plan.Changes, func(i, j int) bool {
+		return plan.Changes[i].Name < plan.Changes[j].Name
+	})
+
+	if !conf.Prune {
+		return plan, nil
+	}
+
+	deletes := make([]*Change, 0)
+
+	for _, rel := range deployed.Releases {
+		if declared[rel.Name] || !porteryaml.ApplicationCharts[releaseChartName(rel)] {
+			continue
+		}
+
+		deletes = append(deletes, &Change{
+			Action:     ActionDelete,
+			Name:       rel.Name,
+			Chart:      releaseChartName(rel),
+			ValuesDiff: diff.Values(rel.Config, nil),
+		})
+	}
+
+	sort.SliceStable(deletes, func(i, j int) bool {
+		return deletes[i].Name < deletes[j].Name
+	})
+
+	plan.Changes = append(plan.Changes, deletes...)
+
+	return plan, nil
+}
+
+// diffEnvGroups returns the env groups that an app should be linked to and
+// unlinked from
+func diffEnvGroups(app *porteryaml.App, groups []*models.EnvGroupExternal) (link, unlink []string) {
+	declared := make(map[string]bool)
+
+	for _, name := range app.EnvGroups {
+		declared[name] = true
+	}
+
+	linked := make(map[string]bool)
+
+	for _, group := range groups {
+		for _, rel := range group.Releases {
+			if rel != app.Name {
+				continue
+			}
+
+			linked[group.Name] = true
+
+			if !declared[group.Name] {
+				unlink = append(unlink, group.Name)
+			}
+		}
+	}
+
+	for _, name := range app.EnvGroups {
+		if !linked[name] {
+			link = append(link, name)
+		}
+	}
+
+	sort.Strings(link)
+	sort.Strings(unlink)
+
+	return link, unlink
+}
+
+func releaseChartName(rel *release.Release) string {
+	if rel.Chart == nil || rel.Chart.Metadata == nil {
+		return ""
+	}
+
+	return rel.Chart.Metadata.Name
+}
+
+func releaseChartVersion(rel *release.Release) string {
+	if rel.Chart == nil || rel.Chart.Metadata == nil {
+		return ""
+	}
+
+	return rel.Chart.Metadata.Version
+}
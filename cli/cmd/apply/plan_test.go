@@ -0,0 +1,196 @@
+package apply
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/porteryaml"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+const testConfig = `
+namespace: default
+prune: true
+apps:
+- name: web-app
+  image: gcr.io/project/web-app:v2
+  env_groups: [shared]
+  domains: [app.example.com]
+- name: worker-app
+  chart: worker
+  values:
+    replicaCount: 2
+- name: new-app
+  chart: worker
+  version: 0.10.0
+  build:
+    repo: porter-dev/new-app
+    branch: main
+`
+
+func testRelease(name, chartName, version string, config map[string]interface{}) *release.Release {
+	return &release.Release{
+		Name:      name,
+		Namespace: "default",
+		Config:    config,
+		Chart: &chart.Chart{
+			Metadata: &chart.Metadata{
+				Name:    chartName,
+				Version: version,
+			},
+		},
+	}
+}
+
+func TestComputePlan(t *testing.T) {
+	conf, err := porteryaml.ParseConfig([]byte(testConfig))
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	deployed := &Deployed{
+		Releases: []*release.Release{
+			testRelease("web-app", "web", "0.20.0", map[string]interface{}{
+				"image": map[string]interface{}{
+					"repository": "gcr.io/project/web-app",
+					"tag":        "v1",
+				},
+			}),
+			testRelease("worker-app", "worker", "0.10.0", map[string]interface{}{
+				"replicaCount": float64(2),
+				"image": map[string]interface{}{
+					"tag": "abc123",
+				},
+			}),
+			testRelease("old-app", "job", "0.10.0", nil),
+			testRelease("redis", "redis", "12.0.0", nil),
+		},
+		EnvGroups: []*models.EnvGroupExternal{
+			{Name: "shared", Releases: []string{}},
+			{Name: "legacy", Releases: []string{"worker-app"}},
+		},
+	}
+
+	plan, err := ComputePlan(conf, deployed)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	actions := make(map[string]Action)
+	changes := make(map[string]*Change)
+	names := []string{}
+
+	for _, change := range plan.Changes {
+		actions[change.Name] = change.Action
+		changes[change.Name] = change
+		names = append(names, change.Name)
+	}
+
+	expNames := []string{"new-app", "web-app", "worker-app", "old-app"}
+
+	if !reflect.DeepEqual(names, expNames) {
+		t.Errorf("changes incorrect: expected %v, got %v\n", expNames, names)
+	}
+
+	expActions := map[string]Action{
+		"new-app":    ActionCreate,
+		"web-app":    ActionUpgrade,
+		"worker-app": ActionUnchanged,
+		"old-app":    ActionDelete,
+	}
+
+	if !reflect.DeepEqual(actions, expActions) {
+		t.Errorf("actions incorrect: expected %v, got %v\n", expActions, actions)
+	}
+
+	if v := changes["new-app"].ChartVersion; v != "0.10.0" {
+		t.Errorf("chart version incorrect: expected 0.10.0, got %s\n", v)
+	}
+
+	webApp := changes["web-app"]
+
+	expPaths := []string{"image.tag", "ingress"}
+	paths := []string{}
+
+	for _, change := range webApp.ValuesDiff {
+		paths = append(paths, change.Path)
+	}
+
+	if !reflect.DeepEqual(paths, expPaths) {
+		t.Errorf("values diff incorrect: expected %v, got %v\n", expPaths, paths)
+	}
+
+	if !reflect.DeepEqual(webApp.LinkEnvGroups, []string{"shared"}) {
+		t.Errorf("linked env groups incorrect: %v\n", webApp.LinkEnvGroups)
+	}
+
+	// values that are not declared are kept, and env groups that are not declared
+	// are unlinked
+	workerApp := changes["worker-app"]
+
+	if tag := workerApp.Values["image"].(map[string]interface{})["tag"]; tag != "abc123" {
+		t.Errorf("undeclared value was not kept: %v\n", tag)
+	}
+
+	if !reflect.DeepEqual(workerApp.UnlinkEnvGroups, []string{"legacy"}) {
+		t.Errorf("unlinked env groups incorrect: %v\n", workerApp.UnlinkEnvGroups)
+	}
+
+	if !plan.HasChanges() {
+		t.Errorf("expected plan to have changes\n")
+	}
+}
+
+func TestComputePlanErrors(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+	}{
+		{
+			name: "missing env group",
+			config: `
+apps:
+- name: web-app
+  image: nginx:latest
+  env_groups: [missing]
+`,
+		},
+		{
+			name: "chart changed",
+			config: `
+apps:
+- name: web-app
+  chart: worker
+`,
+		},
+		{
+			name: "no image or build",
+			config: `
+apps:
+- name: new-app
+`,
+		},
+	}
+
+	deployed := &Deployed{
+		Releases: []*release.Release{
+			testRelease("web-app", "web", "0.20.0", nil),
+		},
+	}
+
+	for _, test := range tests {
+		conf, err := porteryaml.ParseConfig([]byte(test.config))
+
+		if err != nil {
+			t.Fatalf("%s: %v\n", test.name, err)
+		}
+
+		if _, err := ComputePlan(conf, deployed); err == nil {
+			t.Errorf("%s: expected error\n", test.name)
+		}
+	}
+}
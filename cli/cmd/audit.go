@@ -0,0 +1,127 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/porter-dev/porter/cli/cmd/api"
+	"github.com/spf13/cobra"
+)
+
+// auditCmd represents the "porter audit" command
+var auditCmd = &cobra.Command{
+	Use:   "audit",
+	Short: "Lists the audit log of mutating API calls in the current project",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := checkLoginAndRun(args, listAuditEvents)
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var auditUserID uint
+var auditResourceType string
+var auditSince time.Duration
+var auditLimit int
+var auditSkip int
+
+func init() {
+	rootCmd.AddCommand(auditCmd)
+
+	auditCmd.PersistentFlags().UintVar(
+		&auditUserID,
+		"user-id",
+		0,
+		"only show events for the user with this id",
+	)
+
+	auditCmd.PersistentFlags().StringVar(
+		&auditResourceType,
+		"resource-type",
+		"",
+		"only show events for this resource type, such as releases or registries",
+	)
+
+	auditCmd.PersistentFlags().DurationVar(
+		&auditSince,
+		"since",
+		0,
+		"only show events newer than a relative duration, such as 24h",
+	)
+
+	auditCmd.PersistentFlags().IntVar(
+		&auditLimit,
+		"limit",
+		50,
+		"the maximum number of events to show",
+	)
+
+	auditCmd.PersistentFlags().IntVar(
+		&auditSkip,
+		"skip",
+		0,
+		"the number of events to skip, for paginating through older events",
+	)
+}
+
+func listAuditEvents(user *api.AuthCheckResponse, client *api.Client, args []string) error {
+	listReq := &api.ListAuditEventsRequest{
+		UserID:       auditUserID,
+		ResourceType: auditResourceType,
+		Limit:        auditLimit,
+		Skip:         auditSkip,
+	}
+
+	if auditSince != 0 {
+		listReq.Since = time.Now().Add(-1 * auditSince).Format(time.RFC3339)
+	}
+
+	events, err := client.ListAuditEvents(context.Background(), config.Project, listReq)
+
+	if err != nil {
+		return err
+	}
+
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 3, 8, 0, '\t', tabwriter.AlignRight)
+
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\n", "TIME", "USER", "METHOD", "ROUTE", "RESOURCES", "STATUS")
+
+	for _, event := range events {
+		resources := make([]string, 0)
+
+		for key, val := range event.Resources {
+			resources = append(resources, fmt.Sprintf("%s=%s", key, val))
+		}
+
+		sort.Strings(resources)
+
+		line := fmt.Sprintf(
+			"%s\t%d\t%s\t%s\t%s\t%d\n",
+			event.CreatedAt.Local().Format(time.RFC3339),
+			event.UserID,
+			event.Method,
+			event.Route,
+			strings.Join(resources, ","),
+			event.Status,
+		)
+
+		if event.Success {
+			fmt.Fprint(w, line)
+		} else {
+			color.New(color.FgRed).Fprint(w, line)
+		}
+	}
+
+	w.Flush()
+
+	return nil
+}
@@ -0,0 +1,183 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/porter-dev/porter/cli/cmd/api"
+	"github.com/porter-dev/porter/cli/cmd/utils"
+	"github.com/spf13/cobra"
+)
+
+var authTokenCmd = &cobra.Command{
+	Use:     "token",
+	Aliases: []string{"tokens"},
+	Short:   "Commands for managing project API tokens",
+}
+
+var authTokenCreateCmd = &cobra.Command{
+	Use:   "create [name]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Creates an API token for the current project",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := checkLoginAndRun(args, createAPIToken)
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var authTokenListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "Lists the API tokens for the current project",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := checkLoginAndRun(args, listAPITokens)
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var authTokenRevokeCmd = &cobra.Command{
+	Use:   "revoke [id]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Revokes the API token with the given id",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := checkLoginAndRun(args, revokeAPIToken)
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var tokenKind string
+var tokenPolicyID uint
+var tokenTTL string
+
+func init() {
+	authCmd.AddCommand(authTokenCmd)
+
+	authTokenCmd.AddCommand(authTokenCreateCmd)
+	authTokenCmd.AddCommand(authTokenListCmd)
+	authTokenCmd.AddCommand(authTokenRevokeCmd)
+
+	authTokenCreateCmd.PersistentFlags().StringVar(
+		&tokenKind,
+		"kind",
+		"developer",
+		"the role kind of the token (admin, developer, viewer or custom)",
+	)
+
+	authTokenCreateCmd.PersistentFlags().UintVar(
+		&tokenPolicyID,
+		"policy-id",
+		0,
+		"the id of the policy to use for a custom token",
+	)
+
+	authTokenCreateCmd.PersistentFlags().StringVar(
+		&tokenTTL,
+		"ttl",
+		"",
+		"how long the token is valid for, such as 720h. If not set, the token does not expire.",
+	)
+}
+
+func createAPIToken(user *api.AuthCheckResponse, client *api.Client, args []string) error {
+	resp, err := client.CreateAPIToken(
+		context.Background(),
+		config.Project,
+		&api.CreateAPITokenRequest{
+			Name:     args[0],
+			Kind:     tokenKind,
+			PolicyID: tokenPolicyID,
+			TTL:      tokenTTL,
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	color.New(color.FgGreen).Printf("Created API token with id %d\n", resp.ID)
+	color.New(color.FgYellow).Println("Copy the token below, it will not be shown again:")
+
+	fmt.Println(resp.Token)
+
+	return nil
+}
+
+func listAPITokens(user *api.AuthCheckResponse, client *api.Client, args []string) error {
+	tokens, err := client.ListAPITokens(context.Background(), config.Project)
+
+	if err != nil {
+		return err
+	}
+
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 3, 8, 0, '\t', tabwriter.AlignRight)
+
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", "ID", "NAME", "KIND", "EXPIRY", "STATUS")
+
+	for _, token := range tokens {
+		expiry := "never"
+
+		if token.Expiry != nil {
+			expiry = token.Expiry.String()
+		}
+
+		status := "active"
+
+		if token.Revoked {
+			status = "revoked"
+		} else if token.Expired {
+			status = "expired"
+		}
+
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\n", token.ID, token.Name, token.Kind, expiry, status)
+	}
+
+	w.Flush()
+
+	return nil
+}
+
+func revokeAPIToken(user *api.AuthCheckResponse, client *api.Client, args []string) error {
+	userResp, err := utils.PromptPlaintext(
+		fmt.Sprintf(
+			`Are you sure you'd like to revoke the API token with id %s? %s `,
+			args[0],
+			color.New(color.FgCyan).Sprintf("[y/n]"),
+		),
+	)
+
+	if err != nil {
+		return err
+	}
+
+	if userResp := strings.ToLower(userResp); userResp == "y" || userResp == "yes" {
+		id, err := strconv.ParseUint(args[0], 10, 64)
+
+		if err != nil {
+			return err
+		}
+
+		err = client.RevokeAPIToken(context.Background(), config.Project, uint(id))
+
+		if err != nil {
+			return err
+		}
+
+		color.New(color.FgGreen).Printf("Revoked API token with id %d\n", id)
+	}
+
+	return nil
+}
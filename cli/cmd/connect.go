@@ -55,6 +55,54 @@ var connectDockerhubCmd = &cobra.Command{
 	},
 }
 
+var connectGHCRCmd = &cobra.Command{
+	Use:   "ghcr",
+	Short: "Adds a GitHub Container Registry to a project",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := checkLoginAndRun(args, runConnectGHCR)
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var connectACRCmd = &cobra.Command{
+	Use:   "acr",
+	Short: "Adds an Azure Container Registry to a project",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := checkLoginAndRun(args, runConnectACR)
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var connectQuayCmd = &cobra.Command{
+	Use:   "quay",
+	Short: "Adds a Quay.io registry to a project",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := checkLoginAndRun(args, runConnectQuay)
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var connectHarborCmd = &cobra.Command{
+	Use:   "harbor",
+	Short: "Adds a Harbor registry to a project",
+	Run: func(cmd *cobra.Command, args []string) {
+		err := checkLoginAndRun(args, runConnectHarbor)
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
 var connectRegistryCmd = &cobra.Command{
 	Use:   "registry",
 	Short: "Adds a custom image registry to a project",
@@ -126,6 +174,10 @@ func init() {
 	connectCmd.AddCommand(connectECRCmd)
 	connectCmd.AddCommand(connectRegistryCmd)
 	connectCmd.AddCommand(connectDockerhubCmd)
+	connectCmd.AddCommand(connectGHCRCmd)
+	connectCmd.AddCommand(connectACRCmd)
+	connectCmd.AddCommand(connectQuayCmd)
+	connectCmd.AddCommand(connectHarborCmd)
 	connectCmd.AddCommand(connectGCRCmd)
 	connectCmd.AddCommand(connectDOCRCmd)
 	connectCmd.AddCommand(connectHRCmd)
@@ -205,6 +257,58 @@ func runConnectDockerhub(_ *api.AuthCheckResponse, client *api.Client, _ []strin
 	return config.SetRegistry(regID)
 }
 
+func runConnectGHCR(_ *api.AuthCheckResponse, client *api.Client, _ []string) error {
+	regID, err := connect.GHCR(
+		client,
+		config.Project,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return config.SetRegistry(regID)
+}
+
+func runConnectACR(_ *api.AuthCheckResponse, client *api.Client, _ []string) error {
+	regID, err := connect.ACR(
+		client,
+		config.Project,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return config.SetRegistry(regID)
+}
+
+func runConnectQuay(_ *api.AuthCheckResponse, client *api.Client, _ []string) error {
+	regID, err := connect.Quay(
+		client,
+		config.Project,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return config.SetRegistry(regID)
+}
+
+func runConnectHarbor(_ *api.AuthCheckResponse, client *api.Client, _ []string) error {
+	regID, err := connect.Harbor(
+		client,
+		config.Project,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	return config.SetRegistry(regID)
+}
+
 func runConnectRegistry(_ *api.AuthCheckResponse, client *api.Client, _ []string) error {
 	regID, err := connect.Registry(
 		client,
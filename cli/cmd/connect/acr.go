@@ -0,0 +1,50 @@
+package connect
+
+import (
+	"fmt"
+
+	"github.com/porter-dev/porter/cli/cmd/api"
+	"github.com/porter-dev/porter/cli/cmd/utils"
+)
+
+// ACR creates an Azure Container Registry integration
+func ACR(
+	client *api.Client,
+	projectID uint,
+) (uint, error) {
+	// if project ID is 0, ask the user to set the project ID or create a project
+	if projectID == 0 {
+		return 0, fmt.Errorf("no project set, please run porter project set [id]")
+	}
+
+	regName, err := utils.PromptPlaintext(`Provide the name of the registry, which is the first part of its login server ${name}.azurecr.io.
+Registry name: `)
+
+	if err != nil {
+		return 0, err
+	}
+
+	username, err := utils.PromptPlaintext(`Provide the application (client) ID of a service principal with the AcrPush role, or the admin username of the registry.
+Username: `)
+
+	if err != nil {
+		return 0, err
+	}
+
+	password, err := utils.PromptPassword(`Provide the client secret of the service principal, or the admin password of the registry.
+Password:`)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return createBasicRegistry(
+		client,
+		projectID,
+		"acr",
+		regName,
+		fmt.Sprintf("%s.azurecr.io", regName),
+		username,
+		password,
+	)
+}
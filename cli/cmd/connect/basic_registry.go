@@ -0,0 +1,50 @@
+package connect
+
+import (
+	"context"
+
+	"github.com/fatih/color"
+	"github.com/porter-dev/porter/cli/cmd/api"
+)
+
+// createBasicRegistry creates a basic auth integration with the given credentials,
+// and links a registry of the given service that uses it
+func createBasicRegistry(
+	client *api.Client,
+	projectID uint,
+	service, name, url, username, password string,
+) (uint, error) {
+	integration, err := client.CreateBasicAuthIntegration(
+		context.Background(),
+		projectID,
+		&api.CreateBasicAuthIntegrationRequest{
+			Username: username,
+			Password: password,
+		},
+	)
+
+	if err != nil {
+		return 0, err
+	}
+
+	color.New(color.FgGreen).Printf("created basic auth integration with id %d\n", integration.ID)
+
+	reg, err := client.CreatePrivateRegistry(
+		context.Background(),
+		projectID,
+		&api.CreatePrivateRegistryRequest{
+			URL:                url,
+			Name:               name,
+			BasicIntegrationID: integration.ID,
+			Service:            service,
+		},
+	)
+
+	if err != nil {
+		return 0, err
+	}
+
+	color.New(color.FgGreen).Printf("created private registry with id %d and name %s\n", reg.ID, reg.Name)
+
+	return reg.ID, nil
+}
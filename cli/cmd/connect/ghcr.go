@@ -0,0 +1,49 @@
+package connect
+
+import (
+	"fmt"
+
+	"github.com/porter-dev/porter/cli/cmd/api"
+	"github.com/porter-dev/porter/cli/cmd/utils"
+)
+
+// GHCR creates a GitHub Container Registry integration
+func GHCR(
+	client *api.Client,
+	projectID uint,
+) (uint, error) {
+	// if project ID is 0, ask the user to set the project ID or create a project
+	if projectID == 0 {
+		return 0, fmt.Errorf("no project set, please run porter project set [id]")
+	}
+
+	owner, err := utils.PromptPlaintext(`Provide the GitHub user or organization that owns the packages. For example, porter-dev.
+Owner: `)
+
+	if err != nil {
+		return 0, err
+	}
+
+	username, err := utils.PromptPlaintext(`GitHub username: `)
+
+	if err != nil {
+		return 0, err
+	}
+
+	password, err := utils.PromptPassword(`Provide a GitHub personal access token with the read:packages and write:packages scopes.
+Token:`)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return createBasicRegistry(
+		client,
+		projectID,
+		"ghcr",
+		fmt.Sprintf("ghcr-%s", owner),
+		fmt.Sprintf("ghcr.io/%s", owner),
+		username,
+		password,
+	)
+}
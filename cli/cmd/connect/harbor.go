@@ -0,0 +1,57 @@
+package connect
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/porter-dev/porter/cli/cmd/api"
+	"github.com/porter-dev/porter/cli/cmd/utils"
+)
+
+// Harbor creates a Harbor registry integration
+func Harbor(
+	client *api.Client,
+	projectID uint,
+) (uint, error) {
+	// if project ID is 0, ask the user to set the project ID or create a project
+	if projectID == 0 {
+		return 0, fmt.Errorf("no project set, please run porter project set [id]")
+	}
+
+	regURL, err := utils.PromptPlaintext(`Provide the URL of the Harbor project, in the form of ${harbor_host}/${project_name}. For example, harbor.example.com/porter.
+Project URL: `)
+
+	if err != nil {
+		return 0, err
+	}
+
+	regURL = strings.TrimSuffix(regURL, "/")
+
+	if spl := strings.Split(strings.TrimPrefix(strings.TrimPrefix(regURL, "https://"), "http://"), "/"); len(spl) < 2 || spl[1] == "" {
+		return 0, fmt.Errorf("Harbor project URL must be of the form ${harbor_host}/${project_name}")
+	}
+
+	username, err := utils.PromptPlaintext(`Provide the Harbor username, or the name of a robot account.
+Username: `)
+
+	if err != nil {
+		return 0, err
+	}
+
+	password, err := utils.PromptPassword(`Provide the password or robot account secret.
+Password:`)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return createBasicRegistry(
+		client,
+		projectID,
+		"harbor",
+		regURL[strings.LastIndex(regURL, "/")+1:],
+		regURL,
+		username,
+		password,
+	)
+}
@@ -0,0 +1,44 @@
+package connect
+
+import (
+	"fmt"
+
+	"github.com/porter-dev/porter/cli/cmd/api"
+	"github.com/porter-dev/porter/cli/cmd/utils"
+)
+
+// Quay creates a Quay.io registry integration
+func Quay(
+	client *api.Client,
+	projectID uint,
+) (uint, error) {
+	// if project ID is 0, ask the user to set the project ID or create a project
+	if projectID == 0 {
+		return 0, fmt.Errorf("no project set, please run porter project set [id]")
+	}
+
+	namespace, err := utils.PromptPlaintext(`Provide the Quay.io user or organization that owns the repositories. For example, porter.
+Namespace: `)
+
+	if err != nil {
+		return 0, err
+	}
+
+	password, err := utils.PromptPassword(`Provide an OAuth access token of an application in the organization, with permissions to read and write repositories.
+Token:`)
+
+	if err != nil {
+		return 0, err
+	}
+
+	// Quay.io accepts OAuth access tokens with the username $oauthtoken
+	return createBasicRegistry(
+		client,
+		projectID,
+		"quay",
+		fmt.Sprintf("quay-%s", namespace),
+		fmt.Sprintf("quay.io/%s", namespace),
+		"$oauthtoken",
+		password,
+	)
+}
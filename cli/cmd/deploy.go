@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"os"
+	"strings"
 
 	"github.com/fatih/color"
 	"github.com/porter-dev/porter/cli/cmd/api"
@@ -47,6 +48,11 @@ in your remote Git repository. For example, if a Dockerfile is found at ./docker
 specify it as follows:
 
   %s
+
+To preview the changes to the configuration and rendered resources of an application without
+building or deploying it, pass the --dry-run flag:
+
+  %s
 `,
 		color.New(color.FgBlue, color.Bold).Sprintf("Help for \"porter update\":"),
 		color.New(color.FgGreen, color.Bold).Sprintf("porter update --app example-app"),
@@ -54,6 +60,7 @@ specify it as follows:
 		color.New(color.FgGreen, color.Bold).Sprintf("porter update --app remote-git-app --source github"),
 		color.New(color.FgGreen, color.Bold).Sprintf("porter update --app example-app --values my-values.yaml"),
 		color.New(color.FgGreen, color.Bold).Sprintf("porter update --app example-app --method docker --dockerfile ./docker/prod.Dockerfile"),
+		color.New(color.FgGreen, color.Bold).Sprintf("porter update --app example-app --values my-values.yaml --dry-run"),
 	),
 	Run: func(cmd *cobra.Command, args []string) {
 		err := checkLoginAndRun(args, updateFull)
@@ -203,6 +210,7 @@ var tag string
 var dockerfile string
 var method string
 var stream bool
+var dryRun bool
 
 func init() {
 	rootCmd.AddCommand(updateCmd)
@@ -275,6 +283,13 @@ func init() {
 		"stream update logs to porter dashboard",
 	)
 
+	updateCmd.PersistentFlags().BoolVar(
+		&dryRun,
+		"dry-run",
+		false,
+		"print the changes that the update would make, without building or deploying",
+	)
+
 	updateCmd.AddCommand(updateGetEnvCmd)
 
 	updateGetEnvCmd.PersistentFlags().StringVar(
@@ -290,14 +305,19 @@ func init() {
 }
 
 func updateFull(resp *api.AuthCheckResponse, client *api.Client, args []string) error {
-	color.New(color.FgGreen).Println("Deploying app:", app)
-
 	updateAgent, err := updateGetAgent(client)
 
 	if err != nil {
 		return err
 	}
 
+	// a dry run skips the build and push, and previews the new configuration
+	if dryRun {
+		return updatePreviewWithAgent(updateAgent)
+	}
+
+	color.New(color.FgGreen).Println("Deploying app:", app)
+
 	err = updateBuildWithAgent(updateAgent)
 
 	if err != nil {
@@ -370,6 +390,10 @@ func updateUpgrade(resp *api.AuthCheckResponse, client *api.Client, args []strin
 		return err
 	}
 
+	if dryRun {
+		return updatePreviewWithAgent(updateAgent)
+	}
+
 	return updateUpgradeWithAgent(updateAgent)
 }
 
@@ -567,3 +591,61 @@ func updateUpgradeWithAgent(updateAgent *deploy.DeployAgent) error {
 
 	return nil
 }
+
+func updatePreviewWithAgent(updateAgent *deploy.DeployAgent) error {
+	color.New(color.FgGreen).Println("Previewing configuration changes for", app)
+
+	// read the values if necessary
+	valuesObj, err := readValuesFile()
+
+	if err != nil {
+		return err
+	}
+
+	preview, err := updateAgent.PreviewImageAndValues(valuesObj)
+
+	if err != nil {
+		return err
+	}
+
+	if len(preview.ValuesDiff) == 0 && len(preview.Resources) == 0 {
+		fmt.Println("No changes")
+		return nil
+	}
+
+	if len(preview.ValuesDiff) > 0 {
+		color.New(color.Bold).Println("Values:")
+
+		for _, change := range preview.ValuesDiff {
+			switch {
+			case change.Old == nil:
+				color.New(color.FgGreen).Printf("  + %s: %v\n", change.Path, change.New)
+			case change.New == nil:
+				color.New(color.FgRed).Printf("  - %s: %v\n", change.Path, change.Old)
+			default:
+				color.New(color.FgYellow).Printf("  ~ %s: %v -> %v\n", change.Path, change.Old, change.New)
+			}
+		}
+	}
+
+	for _, res := range preview.Resources {
+		color.New(color.Bold).Printf("\n%s %s (%s):\n", res.Kind, res.Name, res.Action)
+
+		for _, line := range strings.Split(strings.TrimSuffix(res.Diff, "\n"), "\n") {
+			switch {
+			case strings.HasPrefix(line, "+++") || strings.HasPrefix(line, "---"):
+				fmt.Println(line)
+			case strings.HasPrefix(line, "+"):
+				color.New(color.FgGreen).Println(line)
+			case strings.HasPrefix(line, "-"):
+				color.New(color.FgRed).Println(line)
+			case strings.HasPrefix(line, "@@"):
+				color.New(color.FgCyan).Println(line)
+			default:
+				fmt.Println(line)
+			}
+		}
+	}
+
+	return nil
+}
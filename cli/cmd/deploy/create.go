@@ -26,12 +26,20 @@ type CreateOpts struct {
 	Kind        string
 	ReleaseName string
 	RegistryURL string
+
+	// TemplateVersion is the version of the template to deploy, or the latest
+	// version if empty
+	TemplateVersion string
 }
 
 // GithubOpts are the options for linking a Github source to the app
 type GithubOpts struct {
 	Branch string
 	Repo   string
+
+	// FolderPath is the build context in the repository, or the root of the
+	// repository if empty
+	FolderPath string
 }
 
 // CreateFromGithub uses the branch/repo to link the Github source for an application.
@@ -119,6 +127,12 @@ func (c *CreateAgent) CreateFromGithub(
 		return "", err
 	}
 
+	folderPath := ghOpts.FolderPath
+
+	if folderPath == "" {
+		folderPath = "."
+	}
+
 	err = c.Client.DeployTemplate(
 		context.Background(),
 		opts.ProjectID,
@@ -136,7 +150,7 @@ func (c *CreateAgent) CreateFromGithub(
 				GitBranch:      ghOpts.Branch,
 				ImageRepoURI:   imageURL,
 				DockerfilePath: opts.LocalDockerfile,
-				FolderPath:     ".",
+				FolderPath:     folderPath,
 				GitRepoID:      gitRepoMatch,
 				BuildEnv:       env,
 				RegistryID:     regID,
@@ -429,10 +443,15 @@ func (c *CreateAgent) GetLatestTemplateDefaultValues(templateName, templateVersi
 
 func (c *CreateAgent) getMergedValues(overrideValues map[string]interface{}) (string, map[string]interface{}, error) {
 	// deploy the template
-	latestVersion, err := c.GetLatestTemplateVersion(c.CreateOpts.Kind)
+	latestVersion := c.CreateOpts.TemplateVersion
 
-	if err != nil {
-		return "", nil, err
+	if latestVersion == "" {
+		var err error
+		latestVersion, err = c.GetLatestTemplateVersion(c.CreateOpts.Kind)
+
+		if err != nil {
+			return "", nil, err
+		}
 	}
 
 	// get the values of the template
@@ -12,6 +12,7 @@ import (
 	"github.com/porter-dev/porter/cli/cmd/api"
 	"github.com/porter-dev/porter/cli/cmd/docker"
 	"github.com/porter-dev/porter/cli/cmd/github"
+	"github.com/porter-dev/porter/internal/helm/diff"
 	"github.com/porter-dev/porter/internal/templater/utils"
 	"k8s.io/client-go/util/homedir"
 )
@@ -273,6 +274,48 @@ func (d *DeployAgent) Push() error {
 // reuses the configuration set for the application. If overrideValues is not nil,
 // it will merge the overriding values with the existing configuration.
 func (d *DeployAgent) UpdateImageAndValues(overrideValues map[string]interface{}) error {
+	values, err := d.getUpgradeValues(overrideValues)
+
+	if err != nil {
+		return err
+	}
+
+	return d.client.UpgradeRelease(
+		context.Background(),
+		d.opts.ProjectID,
+		d.opts.ClusterID,
+		d.release.Name,
+		&api.UpgradeReleaseRequest{
+			Values:    values,
+			Namespace: d.release.Namespace,
+		},
+	)
+}
+
+// PreviewImageAndValues renders the same upgrade as UpdateImageAndValues without
+// applying it, and returns the changes compared to the deployed release.
+func (d *DeployAgent) PreviewImageAndValues(overrideValues map[string]interface{}) (*diff.Preview, error) {
+	values, err := d.getUpgradeValues(overrideValues)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return d.client.PreviewUpgradeRelease(
+		context.Background(),
+		d.opts.ProjectID,
+		d.opts.ClusterID,
+		d.release.Name,
+		&api.UpgradeReleaseRequest{
+			Values:    values,
+			Namespace: d.release.Namespace,
+		},
+	)
+}
+
+// getUpgradeValues merges the override values and the new image into the
+// configuration of the release, and encodes the result for an upgrade request
+func (d *DeployAgent) getUpgradeValues(overrideValues map[string]interface{}) (string, error) {
 	mergedValues := utils.CoalesceValues(d.release.Config, overrideValues)
 
 	// overwrite the tag based on a new image
@@ -289,7 +332,7 @@ func (d *DeployAgent) UpdateImageAndValues(overrideValues map[string]interface{}
 		newImage, err := d.getReleaseImage()
 
 		if err != nil {
-			return fmt.Errorf("could not overwrite hello-porter image: %s", err.Error())
+			return "", fmt.Errorf("could not overwrite hello-porter image: %s", err.Error())
 		}
 
 		currImageSection["repository"] = newImage
@@ -306,19 +349,10 @@ func (d *DeployAgent) UpdateImageAndValues(overrideValues map[string]interface{}
 	bytes, err := json.Marshal(mergedValues)
 
 	if err != nil {
-		return err
+		return "", err
 	}
 
-	return d.client.UpgradeRelease(
-		context.Background(),
-		d.opts.ProjectID,
-		d.opts.ClusterID,
-		d.release.Name,
-		&api.UpgradeReleaseRequest{
-			Values:    string(bytes),
-			Namespace: d.release.Namespace,
-		},
-	)
+	return string(bytes), nil
 }
 
 // GetEnvFromConfig gets the env vars for a standard Porter template config. These env
@@ -0,0 +1,99 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/porter-dev/porter/cli/cmd/api"
+	"github.com/spf13/cobra"
+	"sigs.k8s.io/yaml"
+)
+
+// exportCmd represents the "porter export" command
+var exportCmd = &cobra.Command{
+	Use:   "export",
+	Short: "Exports the applications of a namespace as a porter.yaml file.",
+	Long: fmt.Sprintf(`
+%s
+
+Exports the web, worker and job applications of a namespace as a porter.yaml file that can be
+applied with "porter apply". The file declares the chart, chart version, values, linked env groups
+and Github build settings of each application. Values that are equal to the defaults of the chart
+are left out. For example:
+
+  %s
+
+This command is namespace-scoped and uses the default namespace. To specify a different namespace,
+use the --namespace flag. To write the file instead of printing it, use the --output flag:
+
+  %s
+`,
+		color.New(color.FgBlue, color.Bold).Sprintf("Help for \"porter export\":"),
+		color.New(color.FgGreen, color.Bold).Sprintf("porter export"),
+		color.New(color.FgGreen, color.Bold).Sprintf("porter export --namespace custom-namespace --output porter.yaml"),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := checkLoginAndRun(args, exportNamespace)
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var exportNamespaceName string
+var exportOutput string
+
+func init() {
+	rootCmd.AddCommand(exportCmd)
+
+	exportCmd.PersistentFlags().StringVar(
+		&exportNamespaceName,
+		"namespace",
+		"default",
+		"the namespace to export",
+	)
+
+	exportCmd.PersistentFlags().StringVarP(
+		&exportOutput,
+		"output",
+		"o",
+		"",
+		"the path to write the porter.yaml file to, instead of printing it",
+	)
+}
+
+func exportNamespace(_ *api.AuthCheckResponse, client *api.Client, _ []string) error {
+	conf, err := client.ExportNamespace(
+		context.Background(),
+		config.Project,
+		config.Cluster,
+		exportNamespaceName,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	data, err := yaml.Marshal(conf)
+
+	if err != nil {
+		return err
+	}
+
+	if exportOutput == "" {
+		fmt.Print(string(data))
+		return nil
+	}
+
+	if err := ioutil.WriteFile(exportOutput, data, 0644); err != nil {
+		return err
+	}
+
+	color.New(color.FgGreen).Printf("Exported %d applications to %s\n", len(conf.Apps), exportOutput)
+
+	return nil
+}
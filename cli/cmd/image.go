@@ -0,0 +1,155 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/porter-dev/porter/cli/cmd/api"
+	"github.com/spf13/cobra"
+)
+
+// imageCmd represents the "porter image" base command when called without any
+// subcommands
+var imageCmd = &cobra.Command{
+	Use:   "image",
+	Short: "Commands that move images between connected registries",
+}
+
+var imagePromoteCmd = &cobra.Command{
+	Use:   "promote [repo_name]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Copies an image by digest from the current registry to another registry",
+	Long: fmt.Sprintf(`
+%s
+
+Copies an image from a repository of the current registry (set with --registry) to
+another registry of the project, including every platform of a multi-platform
+image. The image keeps its digest, so the image that was tested is the image that
+is deployed. For example:
+
+  %s
+
+If --app is set, the application is upgraded to the promoted image, pinned to its
+digest.
+`,
+		color.New(color.FgBlue, color.Bold).Sprintf("Help for \"porter image promote\":"),
+		color.New(color.FgGreen, color.Bold).Sprintf("porter image promote web --registry 1 --digest sha256:... --to-registry 2 --to-tag v1.2.0 --app web"),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := checkLoginAndRun(args, promoteImage)
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var promoteDigest string
+var promoteTag string
+var promoteTargetRegistry uint
+var promoteTargetRepo string
+var promoteTargetTag string
+
+func init() {
+	rootCmd.AddCommand(imageCmd)
+
+	imageCmd.AddCommand(imagePromoteCmd)
+
+	imagePromoteCmd.PersistentFlags().AddFlagSet(registryFlagSet)
+
+	imagePromoteCmd.PersistentFlags().StringVar(
+		&promoteDigest,
+		"digest",
+		"",
+		"the digest of the image to promote, of the form sha256:<hex>",
+	)
+
+	imagePromoteCmd.PersistentFlags().StringVar(
+		&promoteTag,
+		"tag",
+		"",
+		"the tag of the image to promote, if no digest is set",
+	)
+
+	imagePromoteCmd.PersistentFlags().UintVar(
+		&promoteTargetRegistry,
+		"to-registry",
+		0,
+		"the id of the registry to promote the image to",
+	)
+
+	imagePromoteCmd.MarkPersistentFlagRequired("to-registry")
+
+	imagePromoteCmd.PersistentFlags().StringVar(
+		&promoteTargetRepo,
+		"to-repo",
+		"",
+		"the repository to promote the image to, if different from the source repository",
+	)
+
+	imagePromoteCmd.PersistentFlags().StringVar(
+		&promoteTargetTag,
+		"to-tag",
+		"",
+		"the tag of the promoted image, which defaults to --tag",
+	)
+
+	imagePromoteCmd.PersistentFlags().StringVar(
+		&app,
+		"app",
+		"",
+		"application to upgrade to the promoted image",
+	)
+
+	imagePromoteCmd.PersistentFlags().StringVar(
+		&namespace,
+		"namespace",
+		"default",
+		"namespace of the application",
+	)
+}
+
+func promoteImage(_ *api.AuthCheckResponse, client *api.Client, args []string) error {
+	if promoteDigest == "" && promoteTag == "" {
+		return fmt.Errorf("a --digest or --tag of the image to promote is required")
+	}
+
+	if config.Registry == 0 {
+		return fmt.Errorf("no source registry set, please run porter config set-registry [id] or pass --registry")
+	}
+
+	promoteReq := &api.PromoteImageRequest{
+		RepositoryName:       args[0],
+		Digest:               promoteDigest,
+		Tag:                  promoteTag,
+		TargetRegistryID:     promoteTargetRegistry,
+		TargetRepositoryName: promoteTargetRepo,
+		TargetTag:            promoteTargetTag,
+	}
+
+	if app != "" {
+		promoteReq.Release = &api.PromoteImageReleaseRequest{
+			ClusterID: config.Cluster,
+			Namespace: namespace,
+			Name:      app,
+		}
+	}
+
+	color.New(color.FgGreen).Printf("Promoting image from registry %d to registry %d...\n", config.Registry, promoteTargetRegistry)
+
+	resp, err := client.PromoteImage(context.Background(), config.Project, config.Registry, promoteReq)
+
+	if err != nil {
+		return err
+	}
+
+	color.New(color.FgGreen).Printf("Promoted image %s\n", resp.Image)
+
+	if resp.Revision != 0 {
+		color.New(color.FgGreen).Printf("Upgraded %s to revision %d\n", app, resp.Revision)
+	}
+
+	return nil
+}
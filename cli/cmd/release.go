@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/porter-dev/porter/cli/cmd/api"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/spf13/cobra"
+)
+
+// releaseCmd represents the "porter release" base command
+var releaseCmd = &cobra.Command{
+	Use:   "release",
+	Short: "Commands that inspect the releases of the current cluster",
+}
+
+var releaseHistoryCmd = &cobra.Command{
+	Use:   "history [name]",
+	Args:  cobra.ExactArgs(1),
+	Short: "Lists the recorded deployments of a release",
+	Long: fmt.Sprintf(`
+%s
+
+Lists the recorded deployments of a release, newest first. Deployments are recorded for
+every upgrade, deploy webhook, rollback and batch image update, and are kept after Helm
+prunes old revisions.
+
+  %s
+
+This command is namespace-scoped and uses the default namespace. To specify a different
+namespace, use the --namespace flag:
+
+  %s
+`,
+		color.New(color.FgBlue, color.Bold).Sprintf("Help for \"porter release history\":"),
+		color.New(color.FgGreen, color.Bold).Sprintf("porter release history example-app"),
+		color.New(color.FgGreen, color.Bold).Sprintf("porter release history example-app --namespace custom-namespace"),
+	),
+	Run: func(cmd *cobra.Command, args []string) {
+		err := checkLoginAndRun(args, listReleaseHistory)
+
+		if err != nil {
+			os.Exit(1)
+		}
+	},
+}
+
+var releaseNamespace string
+var releaseHistoryLimit int
+var releaseHistorySkip int
+
+func init() {
+	rootCmd.AddCommand(releaseCmd)
+	releaseCmd.AddCommand(releaseHistoryCmd)
+
+	releaseCmd.PersistentFlags().StringVar(
+		&releaseNamespace,
+		"namespace",
+		"default",
+		"the namespace of the release",
+	)
+
+	releaseHistoryCmd.PersistentFlags().IntVar(
+		&releaseHistoryLimit,
+		"limit",
+		50,
+		"the maximum number of deployments to show",
+	)
+
+	releaseHistoryCmd.PersistentFlags().IntVar(
+		&releaseHistorySkip,
+		"skip",
+		0,
+		"the number of deployments to skip, for paginating through older deployments",
+	)
+}
+
+func listReleaseHistory(user *api.AuthCheckResponse, client *api.Client, args []string) error {
+	deployments, err := client.ListDeployments(
+		context.Background(),
+		config.Project,
+		config.Cluster,
+		args[0],
+		&api.ListDeploymentsRequest{
+			Namespace: releaseNamespace,
+			Limit:     releaseHistoryLimit,
+			Skip:      releaseHistorySkip,
+		},
+	)
+
+	if err != nil {
+		return err
+	}
+
+	w := new(tabwriter.Writer)
+	w.Init(os.Stdout, 3, 8, 0, '\t', tabwriter.AlignRight)
+
+	fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", "REVISION", "TIME", "TRIGGER", "USER", "IMAGE", "CHANGES", "STATUS")
+
+	for _, deployment := range deployments {
+		image := deployment.ImageRepoURI
+
+		if deployment.ImageTag != "" {
+			image = fmt.Sprintf("%s:%s", image, deployment.ImageTag)
+		}
+
+		user := "-"
+
+		if deployment.UserID != 0 {
+			user = fmt.Sprintf("%d", deployment.UserID)
+		} else if deployment.DeployWebhookTokenID != 0 {
+			user = fmt.Sprintf("webhook:%d", deployment.DeployWebhookTokenID)
+		}
+
+		line := fmt.Sprintf(
+			"%d\t%s\t%s\t%s\t%s\t%d\t%s\n",
+			deployment.Revision,
+			deployment.CreatedAt.Local().Format(time.RFC3339),
+			deployment.Trigger,
+			user,
+			image,
+			len(deployment.ValuesDiff),
+			deployment.Status,
+		)
+
+		if deployment.Status == models.DeploymentStatusFailed {
+			color.New(color.FgRed).Fprint(w, line)
+		} else {
+			fmt.Fprint(w, line)
+		}
+	}
+
+	w.Flush()
+
+	return nil
+}
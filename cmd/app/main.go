@@ -6,6 +6,7 @@ import (
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/porter-dev/porter/internal/repository/gorm"
 
@@ -16,6 +17,8 @@ import (
 	lr "github.com/porter-dev/porter/internal/logger"
 	"github.com/porter-dev/porter/server/router"
 
+	"github.com/porter-dev/porter/internal/kubernetes"
+	"github.com/porter-dev/porter/internal/kubernetes/alerts"
 	prov "github.com/porter-dev/porter/internal/kubernetes/provisioner"
 )
 
@@ -87,6 +90,47 @@ func main() {
 		go prov.GlobalStreamListener(redis, *repo, a.AnalyticsClient, errorChan)
 	}
 
+	if appConf.Server.ClusterAlertsEnabled {
+		// alerts for a release are sent at most once an hour for each kind of failure,
+		// and at most 5 times an hour in total
+		manager := alerts.NewManager(
+			repo,
+			a.DOConf,
+			a.AgentPool,
+			logger,
+			alerts.NewLimiter(time.Hour, 5, time.Hour),
+			a.NotifyClusterAlert,
+		)
+
+		go manager.Run(make(chan struct{}))
+	}
+
+	if appConf.Server.ImageRetentionEnabled {
+		go a.RunImageRetention(make(chan struct{}), appConf.Server.ImageRetentionInterval)
+	}
+
+	// rollouts that were interrupted by a restart cannot be resumed
+	go a.FailInterruptedRollouts()
+
+	// deliver outbound webhook notifications in the background
+	a.WebhookQueue.OnError = func(webhookID uint, err error) {
+		logger.Warn().Err(err).Msgf("could not deliver notification to webhook %d", webhookID)
+	}
+
+	go a.WebhookQueue.Run(make(chan struct{}), 4)
+
+	// evict expired cluster connections and report the hit rate of the agent pool
+	go a.AgentPool.Run(make(chan struct{}), 5*time.Minute, func(stats kubernetes.AgentPoolStats) {
+		logger.Info().
+			Int("size", stats.Size).
+			Uint64("hits", stats.Hits).
+			Uint64("misses", stats.Misses).
+			Uint64("evictions", stats.Evictions).
+			Uint64("invalidations", stats.Invalidations).
+			Float64("hit_rate", stats.HitRate()).
+			Msg("agent pool stats")
+	})
+
 	appRouter := router.New(a)
 
 	address := fmt.Sprintf(":%d", appConf.Server.Port)
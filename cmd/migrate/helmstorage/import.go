@@ -0,0 +1,102 @@
+package helmstorage
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/kubernetes"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"golang.org/x/oauth2"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+// Import copies the Helm releases stored in secrets on every cluster into the Porter
+// database, so that the clusters can be switched to the sql storage driver. Clusters
+// that cannot be reached are skipped, and Import can be run again to retry them.
+func Import(repo *repository.Repository, doConf *oauth2.Config) error {
+	clusters, err := repo.Cluster.ListClusters()
+
+	if err != nil {
+		return err
+	}
+
+	for _, listed := range clusters {
+		// read the cluster again to load the token cache
+		cluster, err := repo.Cluster.ReadCluster(listed.ID)
+
+		if err != nil {
+			fmt.Printf("error reading cluster %d: %v\n", listed.ID, err)
+			continue
+		}
+
+		imported, err := ImportCluster(repo, cluster, doConf)
+
+		if err != nil {
+			fmt.Printf("error importing helm releases of cluster %d: %v\n", cluster.ID, err)
+			continue
+		}
+
+		fmt.Printf("imported %d helm releases of cluster %d\n", imported, cluster.ID)
+	}
+
+	return nil
+}
+
+// ImportCluster copies the Helm releases stored in secrets in every namespace of a
+// cluster into the Porter database, and returns the number of imported revisions
+func ImportCluster(
+	repo *repository.Repository,
+	cluster *models.Cluster,
+	doConf *oauth2.Config,
+) (int, error) {
+	agent, err := kubernetes.GetAgentOutOfClusterConfig(&kubernetes.OutOfClusterConfig{
+		Cluster:           cluster,
+		Repo:              repo,
+		DigitalOceanOAuth: doConf,
+	})
+
+	if err != nil {
+		return 0, err
+	}
+
+	// an empty namespace lists the releases of all namespaces
+	secrets := driver.NewSecrets(agent.Clientset.CoreV1().Secrets(""))
+
+	return CopyReleases(secrets, repo.HelmRelease, cluster.ID)
+}
+
+// CopyReleases copies every release of src into the Porter database under the
+// cluster, and returns the number of copied revisions. Revisions that were already
+// copied are skipped.
+func CopyReleases(
+	src driver.Driver,
+	repo repository.HelmReleaseRepository,
+	clusterID uint,
+) (int, error) {
+	rels, err := src.List(func(_ *release.Release) bool { return true })
+
+	if err != nil {
+		return 0, err
+	}
+
+	copied := 0
+
+	for _, rel := range rels {
+		dst := helm.NewSQLDriver(repo, clusterID, rel.Namespace)
+
+		err := dst.Create(helm.ReleaseKey(rel.Name, rel.Version), rel)
+
+		if errors.Is(err, driver.ErrReleaseExists) {
+			continue
+		} else if err != nil {
+			return copied, err
+		}
+
+		copied++
+	}
+
+	return copied, nil
+}
@@ -0,0 +1,67 @@
+package helmstorage_test
+
+import (
+	"testing"
+
+	"github.com/porter-dev/porter/cmd/migrate/helmstorage"
+	"github.com/porter-dev/porter/internal/helm"
+	memory "github.com/porter-dev/porter/internal/repository/memory"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+func TestCopyReleases(t *testing.T) {
+	src := driver.NewMemory()
+
+	rels := []*release.Release{
+		{Name: "web", Namespace: "default", Version: 1, Info: &release.Info{Status: release.StatusSuperseded}},
+		{Name: "web", Namespace: "default", Version: 2, Info: &release.Info{Status: release.StatusDeployed}},
+		{Name: "worker", Namespace: "jobs", Version: 1, Info: &release.Info{Status: release.StatusDeployed}},
+	}
+
+	for _, rel := range rels {
+		src.SetNamespace(rel.Namespace)
+
+		if err := src.Create(helm.ReleaseKey(rel.Name, rel.Version), rel); err != nil {
+			t.Fatalf("%v\n", err)
+		}
+	}
+
+	// list the releases of all namespaces
+	src.SetNamespace("")
+
+	repo := memory.NewRepository(true)
+
+	copied, err := helmstorage.CopyReleases(src, repo.HelmRelease, 1)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if copied != 3 {
+		t.Errorf("copied releases incorrect: expected %d, got %d\n", 3, copied)
+	}
+
+	// running the import again should skip the copied releases
+	copied, err = helmstorage.CopyReleases(src, repo.HelmRelease, 1)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if copied != 0 {
+		t.Errorf("copied releases incorrect on second run: expected %d, got %d\n", 0, copied)
+	}
+
+	dst := helm.NewSQLDriver(repo.HelmRelease, 1, "jobs")
+
+	rel, err := dst.Get(helm.ReleaseKey("worker", 1))
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if rel.Name != "worker" || rel.Info.Status != release.StatusDeployed {
+		t.Errorf("incorrect release: got %s with status %s\n", rel.Name, rel.Info.Status)
+	}
+}
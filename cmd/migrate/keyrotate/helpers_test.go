@@ -75,6 +75,14 @@ func setupTestEnv(tester *tester, t *testing.T) {
 		&ints.ClusterTokenCache{},
 		&ints.RegTokenCache{},
 		&ints.HelmRepoTokenCache{},
+		&models.HelmRelease{},
+		&models.EnvGroup{},
+		&models.EnvGroupVersion{},
+		&models.EnvGroupRelease{},
+		&models.PreviewEnvironmentConfig{},
+		&models.PreviewEnvironment{},
+		&models.Rollout{},
+		&models.RetentionPolicy{},
 	)
 
 	if err != nil {
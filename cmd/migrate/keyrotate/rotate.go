@@ -109,6 +109,22 @@ func Rotate(db *_gorm.DB, oldKey, newKey *[32]byte) error {
 		return err
 	}
 
+	err = rotateHelmReleaseModel(db, oldKey, newKey)
+
+	if err != nil {
+		fmt.Printf("failed on helm release rotation: %v\n", err)
+
+		return err
+	}
+
+	err = rotateEnvGroupVersionModel(db, oldKey, newKey)
+
+	if err != nil {
+		fmt.Printf("failed on env group version rotation: %v\n", err)
+
+		return err
+	}
+
 	return nil
 }
 
@@ -722,3 +738,109 @@ func rotateAWSIntegrationModel(db *_gorm.DB, oldKey, newKey *[32]byte) error {
 
 	return nil
 }
+
+func rotateHelmReleaseModel(db *_gorm.DB, oldKey, newKey *[32]byte) error {
+	// get count of model
+	var count int64
+
+	if err := db.Model(&models.HelmRelease{}).Count(&count).Error; err != nil {
+		return err
+	}
+
+	// helm release-scoped repository
+	repo := gorm.NewHelmReleaseRepository(db, oldKey).(*gorm.HelmReleaseRepository)
+
+	// iterate (count / stepSize) + 1 times using Limit and Offset
+	for i := 0; i < (int(count)/stepSize)+1; i++ {
+		rels := []*models.HelmRelease{}
+
+		if err := db.Order("id asc").Offset(i * stepSize).Limit(stepSize).Find(&rels).Error; err != nil {
+			return err
+		}
+
+		// decrypt with the old key
+		for _, rel := range rels {
+			err := repo.DecryptHelmReleaseData(rel, oldKey)
+
+			if err != nil {
+				fmt.Printf("error decrypting helm release %d\n", rel.ID)
+
+				// in these cases we'll wipe the data -- if it can't be decrypted, we can't
+				// recover it
+				rel.Body = []byte{}
+			}
+		}
+
+		// encrypt with the new key and re-insert
+		for _, rel := range rels {
+			err := repo.EncryptHelmReleaseData(rel, newKey)
+
+			if err != nil {
+				fmt.Printf("error encrypting helm release %d\n", rel.ID)
+
+				return err
+			}
+
+			if err := db.Save(rel).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Printf("rotated %d helm releases\n", count)
+
+	return nil
+}
+
+func rotateEnvGroupVersionModel(db *_gorm.DB, oldKey, newKey *[32]byte) error {
+	// get count of model
+	var count int64
+
+	if err := db.Model(&models.EnvGroupVersion{}).Count(&count).Error; err != nil {
+		return err
+	}
+
+	// env group-scoped repository
+	repo := gorm.NewEnvGroupRepository(db, oldKey).(*gorm.EnvGroupRepository)
+
+	// iterate (count / stepSize) + 1 times using Limit and Offset
+	for i := 0; i < (int(count)/stepSize)+1; i++ {
+		versions := []*models.EnvGroupVersion{}
+
+		if err := db.Order("id asc").Offset(i * stepSize).Limit(stepSize).Find(&versions).Error; err != nil {
+			return err
+		}
+
+		// decrypt with the old key
+		for _, version := range versions {
+			err := repo.DecryptEnvGroupVersionData(version, oldKey)
+
+			if err != nil {
+				fmt.Printf("error decrypting env group version %d\n", version.ID)
+
+				// in these cases we'll wipe the data -- if it can't be decrypted, we can't
+				// recover it
+				version.SecretVariablesBytes = []byte{}
+			}
+		}
+
+		// encrypt with the new key and re-insert
+		for _, version := range versions {
+			err := repo.EncryptEnvGroupVersionData(version, newKey)
+
+			if err != nil {
+				fmt.Printf("error encrypting env group version %d\n", version.ID)
+
+				return err
+			}
+
+			if err := db.Save(version).Error; err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Printf("rotated %d env group versions\n", count)
+
+	return nil
+}
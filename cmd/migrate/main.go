@@ -4,14 +4,17 @@ import (
 	"fmt"
 	"log"
 
+	"github.com/porter-dev/porter/cmd/migrate/helmstorage"
 	"github.com/porter-dev/porter/cmd/migrate/keyrotate"
 
 	adapter "github.com/porter-dev/porter/internal/adapter"
 	"github.com/porter-dev/porter/internal/config"
 	lr "github.com/porter-dev/porter/internal/logger"
+	"github.com/porter-dev/porter/internal/oauth"
 	"github.com/porter-dev/porter/internal/repository/gorm"
 
 	"github.com/joeshaw/envdecode"
+	"golang.org/x/oauth2"
 )
 
 func main() {
@@ -47,6 +50,32 @@ func main() {
 			panic(err)
 		}
 	}
+
+	if shouldImportHelmReleases() {
+		var key [32]byte
+
+		for i, b := range []byte(appConf.Db.EncryptionKey) {
+			key[i] = b
+		}
+
+		repo := gorm.NewRepository(db, &key)
+
+		var doConf *oauth2.Config
+
+		if sc := appConf.Server; sc.DOClientID != "" && sc.DOClientSecret != "" {
+			doConf = oauth.NewDigitalOceanClient(&oauth.Config{
+				ClientID:     sc.DOClientID,
+				ClientSecret: sc.DOClientSecret,
+				Scopes:       []string{"read", "write"},
+				BaseURL:      sc.ServerURL,
+			})
+		}
+
+		if err := helmstorage.Import(repo, doConf); err != nil {
+			logger.Fatal().Err(err).Msg("")
+			return
+		}
+	}
 }
 
 type RotateConf struct {
@@ -66,3 +95,23 @@ func shouldKeyRotate() (bool, string, string) {
 
 	return c.OldEncryptionKey != "" && c.NewEncryptionKey != "", c.OldEncryptionKey, c.NewEncryptionKey
 }
+
+type HelmStorageConf struct {
+	// we add a dummy field to avoid empty struct issue with envdecode
+	DummyField string `env:"ASDF,default=asdf"`
+
+	// ImportHelmReleases copies the Helm releases stored in secrets on every cluster
+	// into the database, for use with the sql Helm storage driver
+	ImportHelmReleases bool `env:"IMPORT_HELM_RELEASES,default=false"`
+}
+
+func shouldImportHelmReleases() bool {
+	var c HelmStorageConf
+
+	if err := envdecode.StrictDecode(&c); err != nil {
+		log.Fatalf("Failed to decode migration conf: %s", err)
+		return false
+	}
+
+	return c.ImportHelmReleases
+}
@@ -0,0 +1,307 @@
+package policy
+
+import (
+	"fmt"
+
+	"github.com/porter-dev/porter/api/types"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+)
+
+// GetPolicyForRole returns the policy that applies to a project role. The built-in
+// role kinds map to static policies, while custom roles read the stored policy that
+// the role references.
+func GetPolicyForRole(repo repository.PolicyRepository, role *models.Role) (types.Policy, error) {
+	return getPolicyForKind(repo, role.ProjectID, role.Kind, role.PolicyID)
+}
+
+// GetPolicyForAPIToken returns the policy that applies to a project API token, which
+// is resolved in the same way as the policy for a role
+func GetPolicyForAPIToken(repo repository.PolicyRepository, token *models.APIToken) (types.Policy, error) {
+	return getPolicyForKind(repo, token.ProjectID, token.Kind, token.PolicyID)
+}
+
+func getPolicyForKind(
+	repo repository.PolicyRepository,
+	projID uint,
+	kind string,
+	policyID uint,
+) (types.Policy, error) {
+	switch kind {
+	case models.RoleAdmin:
+		return types.AdminPolicy, nil
+	case models.RoleDeveloper:
+		return types.DeveloperPolicy, nil
+	case models.RoleViewer:
+		return types.ViewerPolicy, nil
+	case models.RoleCustom:
+		policy, err := repo.ReadPolicy(projID, policyID)
+
+		if err != nil {
+			return nil, err
+		}
+
+		return policy.GetPolicy()
+	}
+
+	return nil, fmt.Errorf("unknown role kind %s", kind)
+}
+
+// HasScopeAccess checks that a policy allows all of the requested actions. The
+// requested scopes should form a path in types.ScopeHeirarchy that starts at the
+// project scope: a request against a cluster should contain both the project and
+// the cluster.
+//
+// Policy documents are evaluated top-down. A child document replaces its parent
+// when it matches the requested resource for its scope, and otherwise the parent's
+// verbs are inherited. The verb of the most specific requested scope must be
+// allowed by the document that applies at that scope.
+func HasScopeAccess(
+	policy types.Policy,
+	reqScopes map[types.PermissionScope]*types.RequestAction,
+) bool {
+	path := getScopePath(types.ScopeHeirarchy, reqScopes)
+
+	if len(path) == 0 {
+		return false
+	}
+
+	for _, doc := range policy {
+		if isDocumentMatch(doc, path, reqScopes) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ValidatePolicy checks that each policy document only uses scopes and verbs
+// which exist, and that child documents follow types.ScopeHeirarchy. Requests are
+// checked as either reads with the get verb or writes with the update verb, so
+// documents must grant all or none of the read verbs, and all or none of the write
+// verbs. Otherwise, a document that grants create without delete would also allow
+// deletes.
+func ValidatePolicy(policy types.Policy) error {
+	if len(policy) == 0 {
+		return fmt.Errorf("policy must contain at least one document")
+	}
+
+	for _, doc := range policy {
+		if doc == nil || doc.Scope != types.ProjectScope {
+			return fmt.Errorf("policy documents must start at the %s scope", types.ProjectScope)
+		}
+
+		if err := validateDocument(doc, types.ScopeHeirarchy[types.ProjectScope]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func validateDocument(doc *types.PolicyDocument, subTree types.ScopeTree) error {
+	for _, verb := range doc.Verbs {
+		if !isValidVerb(verb) {
+			return fmt.Errorf("invalid verb %s in %s scope", verb, doc.Scope)
+		}
+	}
+
+	for _, group := range []types.APIVerbGroup{readVerbs, writeVerbs} {
+		if count := countVerbs(doc.Verbs, group); count != 0 && count != len(group) {
+			return fmt.Errorf("verbs of %s scope must include all or none of %v", doc.Scope, group)
+		}
+	}
+
+	for scope, child := range doc.Children {
+		childTree, ok := subTree[scope]
+
+		if !ok {
+			return fmt.Errorf("%s is not a child scope of %s", scope, doc.Scope)
+		}
+
+		if child == nil || child.Scope != scope {
+			return fmt.Errorf("child document for %s scope has a mismatched scope", scope)
+		}
+
+		if err := validateDocument(child, childTree); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readVerbs and writeVerbs are the groups of verbs that are checked as a single verb
+var (
+	readVerbs  = types.APIVerbGroup{types.APIVerbGet, types.APIVerbList}
+	writeVerbs = types.APIVerbGroup{types.APIVerbCreate, types.APIVerbUpdate, types.APIVerbDelete}
+)
+
+// countVerbs returns the number of verbs of the group that are in verbs
+func countVerbs(verbs []types.APIVerb, group types.APIVerbGroup) int {
+	res := 0
+
+	for _, verb := range group {
+		if hasVerb(verbs, verb) {
+			res++
+		}
+	}
+
+	return res
+}
+
+func isValidVerb(verb types.APIVerb) bool {
+	for _, validVerb := range types.ReadWriteVerbGroup() {
+		if verb == validVerb {
+			return true
+		}
+	}
+
+	return false
+}
+
+// getScopePath returns the path through the scope tree which ends at the most
+// specific requested scope
+func getScopePath(
+	tree types.ScopeTree,
+	reqScopes map[types.PermissionScope]*types.RequestAction,
+) []types.PermissionScope {
+	var res []types.PermissionScope
+
+	for scope, subTree := range tree {
+		if subPath := getScopePath(subTree, reqScopes); len(subPath) > 0 {
+			return append([]types.PermissionScope{scope}, subPath...)
+		}
+
+		if _, ok := reqScopes[scope]; ok {
+			res = []types.PermissionScope{scope}
+		}
+	}
+
+	return res
+}
+
+// HasUnrestrictedScopeAccess checks that a policy allows all of the requested
+// actions, like HasScopeAccess, and that no child document below the most specific
+// requested scope restricts its verb. It is used for requests that act on every
+// resource below a scope, such as every namespace of a cluster.
+func HasUnrestrictedScopeAccess(
+	policy types.Policy,
+	reqScopes map[types.PermissionScope]*types.RequestAction,
+) bool {
+	path := getScopePath(types.ScopeHeirarchy, reqScopes)
+
+	if len(path) == 0 {
+		return false
+	}
+
+	subTree := types.ScopeHeirarchy
+
+	for _, scope := range path {
+		subTree = subTree[scope]
+	}
+
+	action := reqScopes[path[len(path)-1]]
+
+	for _, doc := range policy {
+		curr := getDocumentForPath(doc, path, reqScopes)
+
+		if curr == nil || !hasVerb(curr.Verbs, action.Verb) {
+			continue
+		}
+
+		// a document that is inherited from a parent scope has no children which
+		// apply below the requested scope
+		if curr.Scope != path[len(path)-1] || isUnrestricted(curr, subTree, action.Verb) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isDocumentMatch(
+	doc *types.PolicyDocument,
+	path []types.PermissionScope,
+	reqScopes map[types.PermissionScope]*types.RequestAction,
+) bool {
+	curr := getDocumentForPath(doc, path, reqScopes)
+
+	return curr != nil && hasVerb(curr.Verbs, reqScopes[path[len(path)-1]].Verb)
+}
+
+// getDocumentForPath returns the document that applies at the end of the path, or
+// nil if the document does not match the requested resource at the start of the path
+func getDocumentForPath(
+	doc *types.PolicyDocument,
+	path []types.PermissionScope,
+	reqScopes map[types.PermissionScope]*types.RequestAction,
+) *types.PolicyDocument {
+	if doc == nil || doc.Scope != path[0] || !isResourceMatch(doc.Resources, reqScopes[path[0]]) {
+		return nil
+	}
+
+	curr := doc
+
+	for _, scope := range path[1:] {
+		child, ok := curr.Children[scope]
+
+		if ok && child != nil && isResourceMatch(child.Resources, reqScopes[scope]) {
+			curr = child
+		}
+	}
+
+	return curr
+}
+
+// isUnrestricted returns true if every child document of the scopes in subTree
+// allows the verb
+func isUnrestricted(doc *types.PolicyDocument, subTree types.ScopeTree, verb types.APIVerb) bool {
+	for scope, child := range doc.Children {
+		childTree, ok := subTree[scope]
+
+		if !ok || child == nil {
+			continue
+		}
+
+		if !hasVerb(child.Verbs, verb) || !isUnrestricted(child, childTree, verb) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func hasVerb(verbs []types.APIVerb, verb types.APIVerb) bool {
+	for _, v := range verbs {
+		if v == verb {
+			return true
+		}
+	}
+
+	return false
+}
+
+// isResourceMatch returns true if the list of resources is empty, or if the list
+// contains the requested resource
+func isResourceMatch(resources []types.NameOrUInt, action *types.RequestAction) bool {
+	if len(resources) == 0 {
+		return true
+	}
+
+	if action == nil {
+		return false
+	}
+
+	for _, resource := range resources {
+		if resource.UInt != 0 && resource.UInt == action.Resource.UInt {
+			return true
+		}
+
+		if resource.Name != "" && resource.Name == action.Resource.Name {
+			return true
+		}
+	}
+
+	return false
+}
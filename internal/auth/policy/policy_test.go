@@ -0,0 +1,316 @@
+package policy_test
+
+import (
+	"testing"
+
+	"github.com/porter-dev/porter/api/types"
+	"github.com/porter-dev/porter/internal/auth/policy"
+)
+
+// deployerPolicy can read everything in the project, and can write to the "staging"
+// namespace in cluster 3
+var deployerPolicy = types.Policy{
+	{
+		Scope: types.ProjectScope,
+		Verbs: types.ReadVerbGroup(),
+		Children: map[types.PermissionScope]*types.PolicyDocument{
+			types.ClusterScope: {
+				Scope:     types.ClusterScope,
+				Resources: []types.NameOrUInt{{UInt: 3}},
+				Verbs:     types.ReadVerbGroup(),
+				Children: map[types.PermissionScope]*types.PolicyDocument{
+					types.NamespaceScope: {
+						Scope:     types.NamespaceScope,
+						Resources: []types.NameOrUInt{{Name: "staging"}},
+						Verbs:     types.ReadWriteVerbGroup(),
+					},
+				},
+			},
+		},
+	},
+}
+
+// webPolicy can read everything in the project, and can only write to the "web"
+// application in the "production" namespace
+var webPolicy = types.Policy{
+	{
+		Scope: types.ProjectScope,
+		Verbs: types.ReadVerbGroup(),
+		Children: map[types.PermissionScope]*types.PolicyDocument{
+			types.ClusterScope: {
+				Scope: types.ClusterScope,
+				Verbs: types.ReadVerbGroup(),
+				Children: map[types.PermissionScope]*types.PolicyDocument{
+					types.NamespaceScope: {
+						Scope:     types.NamespaceScope,
+						Resources: []types.NameOrUInt{{Name: "production"}},
+						Verbs:     types.ReadVerbGroup(),
+						Children: map[types.PermissionScope]*types.PolicyDocument{
+							types.ApplicationScope: {
+								Scope:     types.ApplicationScope,
+								Resources: []types.NameOrUInt{{Name: "web"}},
+								Verbs:     types.ReadWriteVerbGroup(),
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+type scopeTest struct {
+	msg       string
+	policy    types.Policy
+	reqScopes map[types.PermissionScope]*types.RequestAction
+	expAccess bool
+}
+
+func getReqScopes(verb types.APIVerb, cluster uint, namespace string) map[types.PermissionScope]*types.RequestAction {
+	res := map[types.PermissionScope]*types.RequestAction{
+		types.ProjectScope: {
+			Verb:     verb,
+			Resource: types.NameOrUInt{UInt: 1},
+		},
+	}
+
+	if cluster != 0 {
+		res[types.ClusterScope] = &types.RequestAction{
+			Verb:     verb,
+			Resource: types.NameOrUInt{UInt: cluster},
+		}
+	}
+
+	if namespace != "" {
+		res[types.NamespaceScope] = &types.RequestAction{
+			Verb:     verb,
+			Resource: types.NameOrUInt{Name: namespace},
+		}
+	}
+
+	return res
+}
+
+func getAppReqScopes(verb types.APIVerb, cluster uint, namespace, name string) map[types.PermissionScope]*types.RequestAction {
+	res := getReqScopes(verb, cluster, namespace)
+
+	res[types.ApplicationScope] = &types.RequestAction{
+		Verb:     verb,
+		Resource: types.NameOrUInt{Name: name},
+	}
+
+	return res
+}
+
+var scopeTests = []*scopeTest{
+	{
+		msg:       "admin can write to project",
+		policy:    types.AdminPolicy,
+		reqScopes: getReqScopes(types.APIVerbUpdate, 0, ""),
+		expAccess: true,
+	},
+	{
+		msg:    "developer cannot write to settings",
+		policy: types.DeveloperPolicy,
+		reqScopes: map[types.PermissionScope]*types.RequestAction{
+			types.ProjectScope:  {Verb: types.APIVerbUpdate, Resource: types.NameOrUInt{UInt: 1}},
+			types.SettingsScope: {Verb: types.APIVerbUpdate},
+		},
+		expAccess: false,
+	},
+	{
+		msg:       "viewer cannot write to project",
+		policy:    types.ViewerPolicy,
+		reqScopes: getReqScopes(types.APIVerbUpdate, 0, ""),
+		expAccess: false,
+	},
+	{
+		msg:       "deployer can read other clusters",
+		policy:    deployerPolicy,
+		reqScopes: getReqScopes(types.APIVerbGet, 4, ""),
+		expAccess: true,
+	},
+	{
+		msg:       "deployer cannot write to cluster",
+		policy:    deployerPolicy,
+		reqScopes: getReqScopes(types.APIVerbUpdate, 3, ""),
+		expAccess: false,
+	},
+	{
+		msg:       "deployer can write to staging namespace",
+		policy:    deployerPolicy,
+		reqScopes: getReqScopes(types.APIVerbUpdate, 3, "staging"),
+		expAccess: true,
+	},
+	{
+		msg:       "deployer cannot write to staging namespace in other cluster",
+		policy:    deployerPolicy,
+		reqScopes: getReqScopes(types.APIVerbUpdate, 4, "staging"),
+		expAccess: false,
+	},
+	{
+		msg:       "deployer cannot write to other namespace",
+		policy:    deployerPolicy,
+		reqScopes: getReqScopes(types.APIVerbDelete, 3, "production"),
+		expAccess: false,
+	},
+	{
+		msg:       "deployer can write to application in staging namespace",
+		policy:    deployerPolicy,
+		reqScopes: getAppReqScopes(types.APIVerbUpdate, 3, "staging", "web"),
+		expAccess: true,
+	},
+	{
+		msg:       "deployer cannot write to application in other namespace",
+		policy:    deployerPolicy,
+		reqScopes: getAppReqScopes(types.APIVerbUpdate, 3, "production", "web"),
+		expAccess: false,
+	},
+	{
+		msg:       "web deployer can write to web application",
+		policy:    webPolicy,
+		reqScopes: getAppReqScopes(types.APIVerbUpdate, 3, "production", "web"),
+		expAccess: true,
+	},
+	{
+		msg:       "web deployer cannot write to other application",
+		policy:    webPolicy,
+		reqScopes: getAppReqScopes(types.APIVerbUpdate, 3, "production", "worker"),
+		expAccess: false,
+	},
+	{
+		msg:       "web deployer cannot write to web application in other namespace",
+		policy:    webPolicy,
+		reqScopes: getAppReqScopes(types.APIVerbUpdate, 3, "staging", "web"),
+		expAccess: false,
+	},
+	{
+		msg:       "web deployer can read other application",
+		policy:    webPolicy,
+		reqScopes: getAppReqScopes(types.APIVerbGet, 3, "production", "worker"),
+		expAccess: true,
+	},
+}
+
+func TestHasScopeAccess(t *testing.T) {
+	for _, c := range scopeTests {
+		if access := policy.HasScopeAccess(c.policy, c.reqScopes); access != c.expAccess {
+			t.Errorf("%s: expected access %t, got %t", c.msg, c.expAccess, access)
+		}
+	}
+}
+
+func TestValidatePolicy(t *testing.T) {
+	if err := policy.ValidatePolicy(deployerPolicy); err != nil {
+		t.Errorf("expected valid policy, got %v", err)
+	}
+
+	if err := policy.ValidatePolicy(webPolicy); err != nil {
+		t.Errorf("expected valid policy, got %v", err)
+	}
+
+	invalid := types.Policy{
+		{
+			Scope: types.ProjectScope,
+			Verbs: types.ReadVerbGroup(),
+			Children: map[types.PermissionScope]*types.PolicyDocument{
+				types.NamespaceScope: {
+					Scope: types.NamespaceScope,
+					Verbs: types.ReadVerbGroup(),
+				},
+			},
+		},
+	}
+
+	if err := policy.ValidatePolicy(invalid); err == nil {
+		t.Errorf("expected error for namespace scope as a direct child of project scope")
+	}
+
+	// writes are all checked with the update verb, so a document cannot grant
+	// create without delete
+	partial := types.Policy{
+		{
+			Scope: types.ProjectScope,
+			Verbs: []types.APIVerb{types.APIVerbGet, types.APIVerbList, types.APIVerbCreate},
+		},
+	}
+
+	if err := policy.ValidatePolicy(partial); err == nil {
+		t.Errorf("expected error for document with part of the write verbs")
+	}
+
+	partial[0].Verbs = []types.APIVerb{types.APIVerbGet}
+
+	if err := policy.ValidatePolicy(partial); err == nil {
+		t.Errorf("expected error for document with part of the read verbs")
+	}
+}
+
+// clusterWriterPolicy can write to the whole project, except for the "production"
+// namespace, which is read-only
+var clusterWriterPolicy = types.Policy{
+	{
+		Scope: types.ProjectScope,
+		Verbs: types.ReadWriteVerbGroup(),
+		Children: map[types.PermissionScope]*types.PolicyDocument{
+			types.ClusterScope: {
+				Scope: types.ClusterScope,
+				Verbs: types.ReadWriteVerbGroup(),
+				Children: map[types.PermissionScope]*types.PolicyDocument{
+					types.NamespaceScope: {
+						Scope:     types.NamespaceScope,
+						Resources: []types.NameOrUInt{{Name: "production"}},
+						Verbs:     types.ReadVerbGroup(),
+					},
+				},
+			},
+		},
+	},
+}
+
+var unrestrictedScopeTests = []*scopeTest{
+	{
+		msg:       "developer can write to the whole cluster",
+		policy:    types.DeveloperPolicy,
+		reqScopes: getReqScopes(types.APIVerbUpdate, 3, ""),
+		expAccess: true,
+	},
+	{
+		msg:       "deployer can read the whole cluster",
+		policy:    deployerPolicy,
+		reqScopes: getReqScopes(types.APIVerbGet, 3, ""),
+		expAccess: true,
+	},
+	{
+		msg:       "deployer cannot write to the whole cluster",
+		policy:    deployerPolicy,
+		reqScopes: getReqScopes(types.APIVerbUpdate, 3, ""),
+		expAccess: false,
+	},
+	{
+		msg:       "web deployer can read the whole cluster",
+		policy:    webPolicy,
+		reqScopes: getReqScopes(types.APIVerbGet, 3, ""),
+		expAccess: true,
+	},
+	{
+		msg:       "cluster writer cannot write to the whole cluster",
+		policy:    clusterWriterPolicy,
+		reqScopes: getReqScopes(types.APIVerbUpdate, 3, ""),
+		expAccess: false,
+	},
+	{
+		msg:       "cluster writer can write to a namespace",
+		policy:    clusterWriterPolicy,
+		reqScopes: getReqScopes(types.APIVerbUpdate, 3, "staging"),
+		expAccess: true,
+	},
+}
+
+func TestHasUnrestrictedScopeAccess(t *testing.T) {
+	for _, c := range unrestrictedScopeTests {
+		if access := policy.HasUnrestrictedScopeAccess(c.policy, c.reqScopes); access != c.expAccess {
+			t.Errorf("%s: expected access %t, got %t", c.msg, c.expAccess, access)
+		}
+	}
+}
@@ -25,6 +25,14 @@ type Token struct {
 	ProjectID uint       `json:"project_id"`
 	IBy       uint       `json:"iby"`
 	IAt       *time.Time `json:"iat"`
+
+	// TokenID is the unique id of a persisted API token, and is empty for tokens
+	// that are not stored in the database
+	TokenID string `json:"token_id"`
+
+	// Expiry is the time after which the token is no longer valid. A nil expiry
+	// means that the token does not expire.
+	Expiry *time.Time `json:"exp"`
 }
 
 func GetTokenForUser(userID uint) (*Token, error) {
@@ -59,13 +67,24 @@ func GetTokenForAPI(userID, projID uint) (*Token, error) {
 }
 
 func (t *Token) EncodeToken(conf *TokenGeneratorConf) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+	claims := jwt.MapClaims{
 		"sub_kind":   t.SubKind,
 		"sub":        t.Sub,
 		"iby":        t.IBy,
 		"iat":        fmt.Sprintf("%d", t.IAt.Unix()),
 		"project_id": t.ProjectID,
-	})
+	}
+
+	if t.TokenID != "" {
+		claims["token_id"] = t.TokenID
+	}
+
+	// the exp claim is verified by the jwt library when the token is parsed
+	if t.Expiry != nil {
+		claims["exp"] = t.Expiry.Unix()
+	}
+
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
 
 	// Sign and get the complete encoded token as a string using the secret
 	return token.SignedString([]byte(conf.TokenSecret))
@@ -105,13 +124,24 @@ func GetTokenFromEncoded(tokenString string, conf *TokenGeneratorConf) (*Token,
 
 		iat := time.Unix(iatUnix, 0)
 
-		return &Token{
+		res := &Token{
 			SubKind:   Subject(fmt.Sprintf("%v", claims["sub_kind"])),
 			Sub:       fmt.Sprintf("%v", claims["sub"]),
 			IBy:       uint(iby),
 			IAt:       &iat,
 			ProjectID: uint(projID),
-		}, nil
+		}
+
+		if tokenID, ok := claims["token_id"].(string); ok {
+			res.TokenID = tokenID
+		}
+
+		if expUnix, ok := claims["exp"].(float64); ok {
+			exp := time.Unix(int64(expUnix), 0)
+			res.Expiry = &exp
+		}
+
+		return res, nil
 	}
 
 	return nil, fmt.Errorf("invalid token")
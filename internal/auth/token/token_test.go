@@ -50,3 +50,53 @@ func TestGetAndEncodeTokenForUser(t *testing.T) {
 		t.Error(diff)
 	}
 }
+
+func TestGetAndEncodeTokenForAPIWithExpiry(t *testing.T) {
+	conf := &token.TokenGeneratorConf{
+		TokenSecret: "fakesecret",
+	}
+
+	tok, err := token.GetTokenForAPI(1, 2)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	expiry := time.Now().Add(time.Hour)
+	tok.TokenID = "abcdef"
+	tok.Expiry = &expiry
+
+	tokString, err := tok.EncodeToken(conf)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	gotToken, err := token.GetTokenFromEncoded(tokString, conf)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if gotToken.TokenID != "abcdef" {
+		t.Errorf("incorrect token id: expected %s, got %s\n", "abcdef", gotToken.TokenID)
+	}
+
+	if gotToken.Expiry == nil || gotToken.Expiry.Unix() != expiry.Unix() {
+		t.Errorf("incorrect expiry: expected %d, got %v\n", expiry.Unix(), gotToken.Expiry)
+	}
+
+	// an expired token should fail to decode
+	expiry = time.Now().Add(-1 * time.Hour)
+	tok.Expiry = &expiry
+
+	tokString, err = tok.EncodeToken(conf)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if _, err := token.GetTokenFromEncoded(tokString, conf); err == nil {
+		t.Errorf("expected error for expired token, got nil\n")
+	}
+}
@@ -59,6 +59,13 @@ type ServerConf struct {
 	SendgridProjectInviteTemplateID string `env:"SENDGRID_INVITE_TEMPLATE_ID"`
 	SendgridSenderEmail             string `env:"SENDGRID_SENDER_EMAIL"`
 
+	// SMTP is used to send notification emails when SendGrid is not configured
+	SMTPHost        string `env:"SMTP_HOST"`
+	SMTPPort        int    `env:"SMTP_PORT,default=587"`
+	SMTPUsername    string `env:"SMTP_USERNAME"`
+	SMTPPassword    string `env:"SMTP_PASSWORD"`
+	SMTPSenderEmail string `env:"SMTP_SENDER_EMAIL"`
+
 	SlackClientID     string `env:"SLACK_CLIENT_ID"`
 	SlackClientSecret string `env:"SLACK_CLIENT_SECRET"`
 
@@ -73,6 +80,25 @@ type ServerConf struct {
 	SelfKubeconfig     string `env:"SELF_KUBECONFIG"`
 
 	WelcomeFormWebhook string `env:"WELCOME_FORM_WEBHOOK"`
+
+	// ClusterAlertsEnabled runs a watcher against every connected cluster that
+	// alerts on crash looping, OOM killed and image pull failures, and failed jobs
+	ClusterAlertsEnabled bool `env:"CLUSTER_ALERTS_ENABLED,default=false"`
+
+	// AgentPoolTTL is how long a connection to a cluster is reused across requests
+	// before its credentials are resolved again; 0 disables the agent pool
+	AgentPoolTTL time.Duration `env:"AGENT_POOL_TTL,default=5m"`
+
+	// ImageRetentionEnabled runs a background job that applies the enabled image
+	// retention policies of every registry, at the given interval
+	ImageRetentionEnabled  bool          `env:"IMAGE_RETENTION_ENABLED,default=false"`
+	ImageRetentionInterval time.Duration `env:"IMAGE_RETENTION_INTERVAL,default=6h"`
+
+	// LocalScanner scans the images of registries that do not scan images
+	// themselves, by running the binary at LocalScannerPath. The only local scanner
+	// is "trivy", and images are not scanned locally if it is empty.
+	LocalScanner     string `env:"LOCAL_SCANNER"`
+	LocalScannerPath string `env:"LOCAL_SCANNER_PATH,default=trivy"`
 }
 
 // DBConf is the database configuration: if generated from environment variables,
@@ -0,0 +1,59 @@
+package forms
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/oauth"
+)
+
+// CreateAPITokenForm represents the accepted values for creating a
+// project-scoped API token
+type CreateAPITokenForm struct {
+	Name     string `json:"name" form:"required"`
+	Kind     string `json:"kind" form:"required"`
+	PolicyID uint   `json:"policy_id"`
+
+	// TTL is a duration string such as "720h". Tokens without a TTL do not expire.
+	TTL string `json:"ttl"`
+
+	ProjectID uint `form:"required"`
+	UserID    uint `form:"required"`
+}
+
+// ToAPIToken converts the form to a gorm API token model
+func (ctf *CreateAPITokenForm) ToAPIToken() (*models.APIToken, error) {
+	switch ctf.Kind {
+	case models.RoleAdmin, models.RoleDeveloper, models.RoleViewer, models.RoleCustom:
+	default:
+		return nil, fmt.Errorf("invalid token kind %s", ctf.Kind)
+	}
+
+	var expiry *time.Time
+
+	if ctf.TTL != "" {
+		ttl, err := time.ParseDuration(ctf.TTL)
+
+		if err != nil {
+			return nil, fmt.Errorf("invalid ttl: %v", err)
+		}
+
+		if ttl <= 0 {
+			return nil, fmt.Errorf("ttl must be positive")
+		}
+
+		exp := time.Now().Add(ttl)
+		expiry = &exp
+	}
+
+	return &models.APIToken{
+		UniqueID:        oauth.CreateRandomState(),
+		ProjectID:       ctf.ProjectID,
+		CreatedByUserID: ctf.UserID,
+		Name:            ctf.Name,
+		Kind:            ctf.Kind,
+		PolicyID:        ctf.PolicyID,
+		Expiry:          expiry,
+	}, nil
+}
@@ -0,0 +1,87 @@
+package forms
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/porter-dev/porter/internal/repository"
+)
+
+// The default and maximum number of audit events returned in a single page
+const (
+	DefaultAuditEventLimit = 50
+	MaxAuditEventLimit     = 500
+)
+
+// ListAuditEventsForm represents the accepted values for listing the audit
+// events of a project
+type ListAuditEventsForm struct {
+	*repository.AuditEventFilter
+}
+
+// PopulateListFromQueryParams populates fields in the ListAuditEventsForm using the
+// passed url.Values (the parsed query params). The since and until params are
+// RFC 3339 timestamps.
+func (laf *ListAuditEventsForm) PopulateListFromQueryParams(vals url.Values) error {
+	laf.Limit = DefaultAuditEventLimit
+
+	if userID := vals.Get("user_id"); userID != "" {
+		id, err := strconv.ParseUint(userID, 10, 64)
+
+		if err != nil {
+			return fmt.Errorf("invalid user_id: %v", err)
+		}
+
+		laf.UserID = uint(id)
+	}
+
+	laf.ResourceType = vals.Get("resource_type")
+
+	if since := vals.Get("since"); since != "" {
+		t, err := time.Parse(time.RFC3339, since)
+
+		if err != nil {
+			return fmt.Errorf("invalid since: %v", err)
+		}
+
+		laf.Since = &t
+	}
+
+	if until := vals.Get("until"); until != "" {
+		t, err := time.Parse(time.RFC3339, until)
+
+		if err != nil {
+			return fmt.Errorf("invalid until: %v", err)
+		}
+
+		laf.Until = &t
+	}
+
+	if limit := vals.Get("limit"); limit != "" {
+		limitInt, err := strconv.ParseInt(limit, 10, 64)
+
+		if err != nil || limitInt <= 0 {
+			return fmt.Errorf("invalid limit %s", limit)
+		}
+
+		laf.Limit = int(limitInt)
+
+		if laf.Limit > MaxAuditEventLimit {
+			laf.Limit = MaxAuditEventLimit
+		}
+	}
+
+	if skip := vals.Get("skip"); skip != "" {
+		skipInt, err := strconv.ParseInt(skip, 10, 64)
+
+		if err != nil || skipInt < 0 {
+			return fmt.Errorf("invalid skip %s", skip)
+		}
+
+		laf.Offset = int(skipInt)
+	}
+
+	return nil
+}
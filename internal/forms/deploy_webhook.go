@@ -0,0 +1,87 @@
+package forms
+
+import (
+	"regexp"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+)
+
+// CreateDeployWebhookTokenForm represents the accepted values for creating a named
+// deploy webhook token for a release
+type CreateDeployWebhookTokenForm struct {
+	Name string `json:"name" form:"required,max=255"`
+
+	// RequireSignature generates a signing secret for the token, and rejects
+	// requests that are not signed with it
+	RequireSignature bool `json:"require_signature"`
+
+	ReleaseID       uint `form:"required"`
+	ProjectID       uint `form:"required"`
+	CreatedByUserID uint
+}
+
+// ToDeployWebhookToken converts the form to a gorm deploy webhook token model,
+// generating a random token and, if required, a random signing secret
+func (cdf *CreateDeployWebhookTokenForm) ToDeployWebhookToken() (*models.DeployWebhookToken, error) {
+	token, err := repository.GenerateRandomBytes(16)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := &models.DeployWebhookToken{
+		ReleaseID:       cdf.ReleaseID,
+		ProjectID:       cdf.ProjectID,
+		Name:            cdf.Name,
+		CreatedByUserID: cdf.CreatedByUserID,
+		Token:           token,
+	}
+
+	if cdf.RequireSignature {
+		secret, err := repository.GenerateRandomBytes(32)
+
+		if err != nil {
+			return nil, err
+		}
+
+		res.SigningSecret = []byte(secret)
+	}
+
+	return res, nil
+}
+
+var imageDigestRegex = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// DeployWebhookForm represents the accepted values of the JSON body of a deploy
+// webhook request. Older webhook requests pass the tag in the commit query param
+// and have no body.
+type DeployWebhookForm struct {
+	Tag    string `json:"tag" form:"max=128"`
+	Digest string `json:"digest"`
+
+	// Values are merged into the values of the release before the new image
+	// is set
+	Values map[string]interface{} `json:"values"`
+}
+
+// IsValidDigest returns true if the digest is empty or a valid sha256 image digest
+func (dwf *DeployWebhookForm) IsValidDigest() bool {
+	return dwf.Digest == "" || imageDigestRegex.MatchString(dwf.Digest)
+}
+
+// ImageTag returns the value of the image tag to deploy. If a digest is set, the
+// tag is pinned to the digest.
+func (dwf *DeployWebhookForm) ImageTag() string {
+	if dwf.Digest == "" {
+		return dwf.Tag
+	}
+
+	tag := dwf.Tag
+
+	if tag == "" {
+		tag = "latest"
+	}
+
+	return tag + "@" + dwf.Digest
+}
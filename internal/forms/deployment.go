@@ -0,0 +1,65 @@
+package forms
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+
+	"github.com/porter-dev/porter/internal/repository"
+)
+
+// The default and maximum number of deployments returned in a single page
+const (
+	DefaultDeploymentLimit = 50
+	MaxDeploymentLimit     = 500
+)
+
+// ListDeploymentsForm represents the accepted values for listing the deployments
+// of a release
+type ListDeploymentsForm struct {
+	*repository.DeploymentFilter
+}
+
+// PopulateListFromQueryParams populates fields in the ListDeploymentsForm using the
+// passed url.Values (the parsed query params)
+func (ldf *ListDeploymentsForm) PopulateListFromQueryParams(vals url.Values) error {
+	ldf.Limit = DefaultDeploymentLimit
+
+	if clusterID := vals.Get("cluster_id"); clusterID != "" {
+		id, err := strconv.ParseUint(clusterID, 10, 64)
+
+		if err != nil {
+			return fmt.Errorf("invalid cluster_id: %v", err)
+		}
+
+		ldf.ClusterID = uint(id)
+	}
+
+	ldf.Namespace = vals.Get("namespace")
+
+	if limit := vals.Get("limit"); limit != "" {
+		limitInt, err := strconv.ParseInt(limit, 10, 64)
+
+		if err != nil || limitInt <= 0 {
+			return fmt.Errorf("invalid limit %s", limit)
+		}
+
+		ldf.Limit = int(limitInt)
+
+		if ldf.Limit > MaxDeploymentLimit {
+			ldf.Limit = MaxDeploymentLimit
+		}
+	}
+
+	if skip := vals.Get("skip"); skip != "" {
+		skipInt, err := strconv.ParseInt(skip, 10, 64)
+
+		if err != nil || skipInt < 0 {
+			return fmt.Errorf("invalid skip %s", skip)
+		}
+
+		ldf.Offset = int(skipInt)
+	}
+
+	return nil
+}
@@ -0,0 +1,30 @@
+package forms
+
+// CreateEnvGroupForm represents the accepted values for creating an env group. The
+// name is also used as the name of the ConfigMap and Secret of the env group.
+type CreateEnvGroupForm struct {
+	Name            string            `json:"name" form:"required,max=63"`
+	Variables       map[string]string `json:"variables"`
+	SecretVariables map[string]string `json:"secret_variables"`
+}
+
+// UpdateEnvGroupForm represents the accepted values for creating a new version of an
+// env group. The variables replace the variables of the current version, except that
+// a secret variable with an empty value keeps its current value, since secret values
+// are not returned to clients.
+type UpdateEnvGroupForm struct {
+	Variables       map[string]string `json:"variables"`
+	SecretVariables map[string]string `json:"secret_variables"`
+}
+
+// RollbackEnvGroupForm represents the accepted values for rolling an env group back
+// to an earlier version
+type RollbackEnvGroupForm struct {
+	Version uint `json:"version" form:"required"`
+}
+
+// LinkEnvGroupReleaseForm represents the accepted values for linking a release to
+// an env group
+type LinkEnvGroupReleaseForm struct {
+	ReleaseName string `json:"release_name" form:"required"`
+}
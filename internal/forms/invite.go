@@ -12,6 +12,7 @@ import (
 type CreateInvite struct {
 	Email     string `json:"email" form:"required"`
 	Kind      string `json:"kind" form:"required"`
+	PolicyID  uint   `json:"policy_id"`
 	ProjectID uint   `form:"required"`
 }
 
@@ -23,6 +24,7 @@ func (ci *CreateInvite) ToInvite() (*models.Invite, error) {
 	return &models.Invite{
 		Email:     ci.Email,
 		Kind:      ci.Kind,
+		PolicyID:  ci.PolicyID,
 		Expiry:    &expiry,
 		ProjectID: ci.ProjectID,
 		Token:     oauth.CreateRandomState(),
@@ -0,0 +1,66 @@
+package forms
+
+import (
+	"encoding/json"
+
+	"github.com/porter-dev/porter/api/types"
+	"github.com/porter-dev/porter/internal/auth/policy"
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// CreatePolicyForm represents the accepted values for creating a named
+// policy document in a project
+type CreatePolicyForm struct {
+	Name      string       `json:"name" form:"required"`
+	Policy    types.Policy `json:"policy" form:"required"`
+	ProjectID uint         `form:"required"`
+}
+
+// ToPolicy validates the policy document and converts the form to a gorm
+// policy model
+func (cpf *CreatePolicyForm) ToPolicy() (*models.Policy, error) {
+	if err := policy.ValidatePolicy(cpf.Policy); err != nil {
+		return nil, err
+	}
+
+	policyBytes, err := json.Marshal(cpf.Policy)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Policy{
+		ProjectID:   cpf.ProjectID,
+		Name:        cpf.Name,
+		PolicyBytes: policyBytes,
+	}, nil
+}
+
+// UpdatePolicyForm represents the accepted values for updating a policy
+type UpdatePolicyForm struct {
+	Name   string       `json:"name"`
+	Policy types.Policy `json:"policy"`
+}
+
+// ToPolicy applies the updated fields to an existing gorm policy model
+func (upf *UpdatePolicyForm) ToPolicy(existing *models.Policy) (*models.Policy, error) {
+	if upf.Name != "" {
+		existing.Name = upf.Name
+	}
+
+	if upf.Policy != nil {
+		if err := policy.ValidatePolicy(upf.Policy); err != nil {
+			return nil, err
+		}
+
+		policyBytes, err := json.Marshal(upf.Policy)
+
+		if err != nil {
+			return nil, err
+		}
+
+		existing.PolicyBytes = policyBytes
+	}
+
+	return existing, nil
+}
@@ -0,0 +1,10 @@
+package forms
+
+// CreatePreviewEnvironmentConfigForm represents the accepted values for enabling
+// preview environments for a release
+type CreatePreviewEnvironmentConfigForm struct {
+	// BaseDomain is the domain that preview hosts are created under, such as
+	// preview.example.com. A wildcard DNS record for the domain must point to the
+	// ingress controller of the cluster.
+	BaseDomain string `json:"base_domain" form:"omitempty,hostname_rfc1123"`
+}
@@ -27,4 +27,7 @@ func (cpf *CreateProjectForm) ToProject(_ repository.ProjectRepository) (*models
 // role
 type UpdateProjectRoleForm struct {
 	Kind string `json:"kind"`
+
+	// PolicyID is required when Kind is models.RoleCustom
+	PolicyID uint `json:"policy_id"`
 }
@@ -1,8 +1,11 @@
 package forms
 
 import (
+	"fmt"
+
 	"github.com/aws/aws-sdk-go/service/ecr"
 	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/models/integrations"
 	"github.com/porter-dev/porter/internal/repository"
 )
 
@@ -16,6 +19,10 @@ type CreateRegistry struct {
 	AWSIntegrationID   uint   `json:"aws_integration_id"`
 	DOIntegrationID    uint   `json:"do_integration_id"`
 	BasicIntegrationID uint   `json:"basic_integration_id"`
+
+	// Service is the service of a registry that uses a basic integration. It is
+	// determined from the URL if it is not set, except for Harbor registries.
+	Service string `json:"service" form:"omitempty,oneof=ghcr acr quay harbor"`
 }
 
 // ToRegistry converts the form to a gorm registry model
@@ -30,6 +37,18 @@ func (cr *CreateRegistry) ToRegistry(repo repository.Repository) (*models.Regist
 		BasicIntegrationID: cr.BasicIntegrationID,
 	}
 
+	if registry.BasicIntegrationID != 0 {
+		registry.Service = integrations.IntegrationService(cr.Service)
+
+		if registry.Service == "" {
+			if serv := integrations.RegistryServiceFromURL(cr.URL); integrations.BasicRegistryServices[serv] {
+				registry.Service = serv
+			}
+		}
+	} else if cr.Service != "" {
+		return nil, fmt.Errorf("a basic integration is required for %s registries", cr.Service)
+	}
+
 	if registry.URL == "" && registry.AWSIntegrationID != 0 {
 		awsInt, err := repo.AWSIntegration.ReadAWSIntegration(registry.AWSIntegrationID)
 
@@ -83,3 +102,64 @@ func (urf *UpdateRegistryForm) ToRegistry(repo repository.RegistryRepository) (*
 type CreateRepository struct {
 	ImageRepoURI string `json:"image_repo_uri" form:"required"`
 }
+
+// PromoteImageForm represents the accepted values for promoting an image from a
+// repository of one registry to a repository of another. The image is identified by
+// digest or, if no digest is set, by tag.
+type PromoteImageForm struct {
+	RepositoryName string `json:"repository_name" form:"required"`
+	Digest         string `json:"digest" form:"required_without=Tag"`
+	Tag            string `json:"tag" form:"max=128"`
+
+	TargetRegistryID uint `json:"target_registry_id" form:"required"`
+
+	// TargetRepositoryName defaults to the name of the source repository
+	TargetRepositoryName string `json:"target_repository_name"`
+
+	// TargetTag defaults to the tag of the source image, if any
+	TargetTag string `json:"target_tag" form:"max=128"`
+
+	// Release is upgraded to the promoted image, if set
+	Release *PromoteImageReleaseForm `json:"release,omitempty"`
+}
+
+// PromoteImageReleaseForm identifies the release that a promoted image is
+// deployed to
+type PromoteImageReleaseForm struct {
+	ClusterID uint   `json:"cluster_id" form:"required"`
+	Namespace string `json:"namespace" form:"required"`
+	Name      string `json:"name" form:"required"`
+}
+
+// IsValidDigest returns true if the digest is empty or a valid sha256 image digest
+func (pif *PromoteImageForm) IsValidDigest() bool {
+	return pif.Digest == "" || imageDigestRegex.MatchString(pif.Digest)
+}
+
+// Reference returns the digest or tag that the source image is read by
+func (pif *PromoteImageForm) Reference() string {
+	if pif.Digest != "" {
+		return pif.Digest
+	}
+
+	return pif.Tag
+}
+
+// GetTargetRepositoryName returns the name of the repository that the image is
+// promoted to
+func (pif *PromoteImageForm) GetTargetRepositoryName() string {
+	if pif.TargetRepositoryName != "" {
+		return pif.TargetRepositoryName
+	}
+
+	return pif.RepositoryName
+}
+
+// GetTargetTag returns the tag of the promoted image
+func (pif *PromoteImageForm) GetTargetTag() string {
+	if pif.TargetTag != "" {
+		return pif.TargetTag
+	}
+
+	return pif.Tag
+}
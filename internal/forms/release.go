@@ -112,6 +112,9 @@ type UpgradeReleaseForm struct {
 	Name         string `json:"name" form:"required"`
 	Values       string `json:"values" form:"required"`
 	ChartVersion string `json:"version"`
+
+	// Rollout is set to upgrade a web release through a rollout
+	Rollout *RolloutForm `json:"rollout,omitempty"`
 }
 
 // ChartTemplateForm represents the accepted values for installing a new chart from a template.
@@ -137,3 +140,27 @@ type UpdateImageForm struct {
 	ImageRepoURI string `json:"image_repo_uri" form:"required"`
 	Tag          string `json:"tag" form:"required"`
 }
+
+// UpdateAutoRollbackForm represents the accepted values for enabling or disabling
+// automatic rollbacks of a release
+type UpdateAutoRollbackForm struct {
+	Enabled bool `json:"enabled"`
+
+	// Timeout is the number of seconds that the controllers of a new revision have
+	// to become ready, which defaults to 5 minutes
+	Timeout uint `json:"timeout" form:"omitempty,min=30,max=3600"`
+}
+
+// UpdateImageDigestPinningForm represents the accepted values for enabling or
+// disabling the pinning of the images of a release to their digests
+type UpdateImageDigestPinningForm struct {
+	Enabled bool `json:"enabled"`
+}
+
+// UpdateVulnerabilityPolicyForm represents the accepted values for blocking the
+// deploys of a release with vulnerable images
+type UpdateVulnerabilityPolicyForm struct {
+	// BlockSeverity blocks deploys of images with vulnerabilities of this severity
+	// or above, and unblocks all deploys if empty
+	BlockSeverity string `json:"block_severity" form:"omitempty,oneof=critical high medium low"`
+}
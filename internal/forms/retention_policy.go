@@ -0,0 +1,44 @@
+package forms
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// CreateRetentionPolicyForm represents the accepted values for creating or
+// updating the retention policy of a registry repository. At least one of
+// KeepLast and MaxAgeDays must be set.
+type CreateRetentionPolicyForm struct {
+	RepositoryName string `json:"repository_name" form:"required"`
+	KeepLast       uint   `json:"keep_last" form:"required_without=MaxAgeDays,max=10000"`
+	MaxAgeDays     uint   `json:"max_age_days" form:"required_without=KeepLast,max=3650"`
+
+	// KeepDeployed defaults to true, so that deployed images are not deleted
+	// unless explicitly allowed
+	KeepDeployed *bool `json:"keep_deployed"`
+
+	Enabled bool `json:"enabled"`
+}
+
+// ToRetentionPolicy converts the form to a retention policy, or updates the
+// existing policy of the repository if there is one
+func (cr *CreateRetentionPolicyForm) ToRetentionPolicy(
+	projectID, registryID uint,
+	existing *models.RetentionPolicy,
+) *models.RetentionPolicy {
+	policy := existing
+
+	if policy == nil {
+		policy = &models.RetentionPolicy{
+			ProjectID:      projectID,
+			RegistryID:     registryID,
+			RepositoryName: cr.RepositoryName,
+		}
+	}
+
+	policy.KeepLast = cr.KeepLast
+	policy.MaxAgeDays = cr.MaxAgeDays
+	policy.KeepDeployed = cr.KeepDeployed == nil || *cr.KeepDeployed
+	policy.Enabled = cr.Enabled
+
+	return policy
+}
@@ -0,0 +1,69 @@
+package forms
+
+import (
+	"time"
+
+	"github.com/porter-dev/porter/internal/helm/rollout"
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// RolloutForm represents the accepted values for upgrading a web release through
+// a canary or blue/green rollout instead of a straight upgrade
+type RolloutForm struct {
+	Strategy string `json:"strategy" form:"required,oneof=canary blue_green"`
+
+	// Steps are the percentages of traffic that are sent to the canary, which
+	// default to 10 and 50. Steps are ignored by blue/green rollouts, which send
+	// all traffic to the canary at once.
+	Steps []uint `json:"steps" form:"omitempty,max=10,dive,min=1,max=100"`
+
+	// StepDuration is the number of seconds that each step is analyzed for, which
+	// defaults to 5 minutes
+	StepDuration uint `json:"step_duration" form:"omitempty,min=60,max=86400"`
+
+	// MaxErrorRate is the highest percentage of 5xx responses, and MaxLatency the
+	// highest average latency in seconds, that the canary may serve before the
+	// rollout is rolled back
+	MaxErrorRate float64 `json:"max_error_rate" form:"omitempty,min=0,max=100"`
+	MaxLatency   float64 `json:"max_latency" form:"omitempty,min=0"`
+}
+
+// ToRollout converts the form to a gorm rollout model, filling in the defaults of
+// unset fields
+func (rf *RolloutForm) ToRollout(
+	projectID, clusterID uint,
+	namespace, name string,
+) *models.Rollout {
+	res := &models.Rollout{
+		ProjectID:    projectID,
+		ClusterID:    clusterID,
+		Namespace:    namespace,
+		ReleaseName:  name,
+		Strategy:     rf.Strategy,
+		StepDuration: rf.StepDuration,
+		MaxErrorRate: rf.MaxErrorRate,
+		MaxLatency:   rf.MaxLatency,
+		Status:       models.RolloutStatusProgressing,
+	}
+
+	conf := &rollout.Config{
+		Strategy: rf.Strategy,
+		Steps:    rf.Steps,
+	}
+
+	res.SetSteps(conf.GetSteps())
+
+	if res.StepDuration == 0 {
+		res.StepDuration = uint(rollout.DefaultStepDuration / time.Second)
+	}
+
+	if res.MaxErrorRate == 0 {
+		res.MaxErrorRate = rollout.DefaultMaxErrorRate
+	}
+
+	if res.MaxLatency == 0 {
+		res.MaxLatency = rollout.DefaultMaxLatency
+	}
+
+	return res
+}
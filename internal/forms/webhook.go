@@ -0,0 +1,92 @@
+package forms
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/porter-dev/porter/internal/models/integrations"
+	"github.com/porter-dev/porter/internal/oauth"
+)
+
+// CreateWebhookIntegrationForm represents the accepted values for creating a
+// generic outbound webhook
+type CreateWebhookIntegrationForm struct {
+	Name string `json:"name" form:"required"`
+	URL  string `json:"url" form:"required"`
+
+	// Secret is used to sign the payloads. A random secret is generated if it
+	// is not set.
+	Secret string `json:"secret"`
+
+	ProjectID uint `form:"required"`
+	UserID    uint `form:"required"`
+}
+
+// ToWebhookIntegration converts the form to a gorm webhook integration model
+func (cwf *CreateWebhookIntegrationForm) ToWebhookIntegration() (*integrations.WebhookIntegration, error) {
+	if err := validateWebhookURL(cwf.URL); err != nil {
+		return nil, err
+	}
+
+	secret := cwf.Secret
+
+	if secret == "" {
+		secret = oauth.CreateRandomState()
+	}
+
+	return &integrations.WebhookIntegration{
+		UserID:    cwf.UserID,
+		ProjectID: cwf.ProjectID,
+		Name:      cwf.Name,
+		URL:       []byte(cwf.URL),
+		Secret:    []byte(secret),
+	}, nil
+}
+
+// UpdateWebhookIntegrationForm represents the accepted values for updating a
+// generic outbound webhook. Fields that are not set are left unchanged.
+type UpdateWebhookIntegrationForm struct {
+	Name   string `json:"name"`
+	URL    string `json:"url"`
+	Secret string `json:"secret"`
+}
+
+// ToWebhookIntegration applies the form to an existing webhook integration
+func (uwf *UpdateWebhookIntegrationForm) ToWebhookIntegration(
+	webhookInt *integrations.WebhookIntegration,
+) (*integrations.WebhookIntegration, error) {
+	if uwf.Name != "" {
+		webhookInt.Name = uwf.Name
+	}
+
+	if uwf.URL != "" {
+		if err := validateWebhookURL(uwf.URL); err != nil {
+			return nil, err
+		}
+
+		webhookInt.URL = []byte(uwf.URL)
+	}
+
+	if uwf.Secret != "" {
+		webhookInt.Secret = []byte(uwf.Secret)
+	}
+
+	return webhookInt, nil
+}
+
+// validateWebhookURL checks that a webhook url is an absolute http or https url.
+// The address that the url resolves to is checked by the notifier when delivering,
+// since the records of its host can change after it is validated.
+func validateWebhookURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+
+	if err != nil {
+		return fmt.Errorf("invalid webhook url: %v", err)
+	}
+
+	if (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+		return fmt.Errorf("webhook url must be an absolute http or https url")
+	}
+
+	return nil
+}
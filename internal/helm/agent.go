@@ -90,6 +90,10 @@ type UpgradeReleaseConfig struct {
 
 	// Optional, if chart should be overriden
 	Chart *chart.Chart
+
+	// DryRun renders the upgrade without storing a new revision, applying it
+	// to the cluster or creating image pull secrets
+	DryRun bool
 }
 
 // UpgradeRelease upgrades a specific release with new values.yaml
@@ -129,9 +133,10 @@ func (a *Agent) UpgradeReleaseByValues(
 
 	cmd := action.NewUpgrade(a.ActionConfig)
 	cmd.Namespace = rel.Namespace
+	cmd.DryRun = conf.DryRun
 
 	if conf.Cluster != nil && a.K8sAgent != nil && conf.Registries != nil && len(conf.Registries) > 0 {
-		cmd.PostRenderer, err = NewDockerSecretsPostRenderer(
+		postRenderer, err := NewDockerSecretsPostRenderer(
 			conf.Cluster,
 			conf.Repo,
 			a.K8sAgent,
@@ -143,6 +148,9 @@ func (a *Agent) UpgradeReleaseByValues(
 		if err != nil {
 			return nil, err
 		}
+
+		postRenderer.DryRun = conf.DryRun
+		cmd.PostRenderer = postRenderer
 	}
 
 	res, err := cmd.Run(conf.Name, ch, conf.Values)
@@ -19,12 +19,15 @@ import (
 
 // Form represents the options for connecting to a cluster and
 // creating a Helm agent
+//
+// The connection fields are never decoded from a request body, since forms which embed
+// a Form are decoded over the cluster that the request was authorized for.
 type Form struct {
-	Cluster           *models.Cluster `form:"required"`
-	Repo              *repository.Repository
-	DigitalOceanOAuth *oauth2.Config
-	Storage           string `json:"storage" form:"oneof=secret configmap memory" default:"secret"`
-	Namespace         string `json:"namespace"`
+	Cluster           *models.Cluster        `json:"-" form:"required"`
+	Repo              *repository.Repository `json:"-"`
+	DigitalOceanOAuth *oauth2.Config         `json:"-"`
+	Storage           string                 `json:"storage" form:"oneof=secret configmap memory sql" default:"secret"`
+	Namespace         string                 `json:"namespace"`
 }
 
 // GetAgentOutOfClusterConfig creates a new Agent from outside the cluster using
@@ -44,7 +47,48 @@ func GetAgentOutOfClusterConfig(form *Form, l *logger.Logger) (*Agent, error) {
 		return nil, err
 	}
 
-	return GetAgentFromK8sAgent(form.Storage, form.Namespace, l, k8sAgent)
+	return getAgentFromForm(form, l, k8sAgent)
+}
+
+// GetAgentFromPool creates a new Agent from outside the cluster, reusing the
+// connection to the cluster stored in the agent pool
+func GetAgentFromPool(form *Form, l *logger.Logger, pool *kubernetes.AgentPool) (*Agent, error) {
+	k8sAgent, err := pool.GetAgent(&kubernetes.OutOfClusterConfig{
+		Cluster:           form.Cluster,
+		DefaultNamespace:  form.Namespace,
+		Repo:              form.Repo,
+		DigitalOceanOAuth: form.DigitalOceanOAuth,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return getAgentFromForm(form, l, k8sAgent)
+}
+
+// getAgentFromForm creates a new Agent using the storage driver of the form
+func getAgentFromForm(form *Form, l *logger.Logger, k8sAgent *kubernetes.Agent) (*Agent, error) {
+	if form.Storage != SQLDriverName {
+		return GetAgentFromK8sAgent(form.Storage, form.Namespace, l, k8sAgent)
+	}
+
+	// Helm does not know about the sql driver, so the action configuration is
+	// initialized with the memory driver, which is then replaced
+	agent, err := GetAgentFromK8sAgent("memory", form.Namespace, l, k8sAgent)
+
+	if err != nil {
+		return nil, err
+	}
+
+	agent.ActionConfig.Releases = NewSQLStorageDriver(
+		l,
+		form.Repo.HelmRelease,
+		form.Cluster.ID,
+		form.Namespace,
+	)
+
+	return agent, nil
 }
 
 // GetAgentFromK8sAgent creates a new Agent
@@ -0,0 +1,153 @@
+package diff
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/pmezard/go-difflib/difflib"
+	"github.com/porter-dev/porter/internal/models"
+	"gopkg.in/yaml.v2"
+	"helm.sh/helm/v3/pkg/releaseutil"
+)
+
+// The actions of a resource diff
+const (
+	ResourceAdded   = "added"
+	ResourceRemoved = "removed"
+	ResourceChanged = "changed"
+)
+
+// ResourceDiff is the change to a single Kubernetes resource between two
+// rendered manifests. Diff is a unified diff of the resource's YAML.
+type ResourceDiff struct {
+	Kind      string `json:"kind"`
+	Name      string `json:"name"`
+	Namespace string `json:"namespace,omitempty"`
+	Action    string `json:"action"`
+	Diff      string `json:"diff"`
+}
+
+// Preview is the result of a dry-run upgrade: the changes to the values of the
+// release and to each resource that the new revision would render
+type Preview struct {
+	ValuesDiff []*models.ValueChange `json:"values_diff"`
+	Resources  []*ResourceDiff       `json:"resources"`
+}
+
+type resourceHeader struct {
+	Kind     string `yaml:"kind"`
+	Metadata struct {
+		Name      string `yaml:"name"`
+		Namespace string `yaml:"namespace"`
+	} `yaml:"metadata"`
+}
+
+type resource struct {
+	kind      string
+	name      string
+	namespace string
+	content   string
+}
+
+func (r *resource) key() string {
+	return fmt.Sprintf("%s/%s/%s", r.kind, r.namespace, r.name)
+}
+
+// Manifests returns the changes between two rendered Helm manifests, matching
+// resources by kind, namespace and name. Unchanged resources are omitted, and
+// the changes are sorted by kind and name.
+func Manifests(prev, curr string) ([]*ResourceDiff, error) {
+	prevResources, err := parseManifest(prev)
+
+	if err != nil {
+		return nil, err
+	}
+
+	currResources, err := parseManifest(curr)
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*ResourceDiff, 0)
+
+	for key, prevRes := range prevResources {
+		currRes, ok := currResources[key]
+
+		if !ok {
+			res = append(res, newResourceDiff(prevRes, ResourceRemoved, prevRes.content, ""))
+		} else if prevRes.content != currRes.content {
+			res = append(res, newResourceDiff(currRes, ResourceChanged, prevRes.content, currRes.content))
+		}
+	}
+
+	for key, currRes := range currResources {
+		if _, ok := prevResources[key]; !ok {
+			res = append(res, newResourceDiff(currRes, ResourceAdded, "", currRes.content))
+		}
+	}
+
+	sort.Slice(res, func(i, j int) bool {
+		if res[i].Kind != res[j].Kind {
+			return res[i].Kind < res[j].Kind
+		}
+
+		if res[i].Namespace != res[j].Namespace {
+			return res[i].Namespace < res[j].Namespace
+		}
+
+		return res[i].Name < res[j].Name
+	})
+
+	return res, nil
+}
+
+func parseManifest(manifest string) (map[string]*resource, error) {
+	res := make(map[string]*resource)
+
+	for _, doc := range releaseutil.SplitManifests(manifest) {
+		header := &resourceHeader{}
+
+		if err := yaml.Unmarshal([]byte(doc), header); err != nil {
+			return nil, fmt.Errorf("could not parse manifest: %v", err)
+		}
+
+		// skip empty documents, such as templates that render only comments
+		if header.Kind == "" {
+			continue
+		}
+
+		r := &resource{
+			kind:      header.Kind,
+			name:      header.Metadata.Name,
+			namespace: header.Metadata.Namespace,
+			content:   strings.TrimSpace(doc) + "\n",
+		}
+
+		res[r.key()] = r
+	}
+
+	return res, nil
+}
+
+func newResourceDiff(r *resource, action, prev, curr string) *ResourceDiff {
+	title := fmt.Sprintf("%s %s", r.kind, r.name)
+
+	// the error is ignored, since writing to a string cannot fail
+	unified, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(prev),
+		B:        difflib.SplitLines(curr),
+		FromFile: title,
+		ToFile:   title,
+		Context:  3,
+	})
+
+	return &ResourceDiff{
+		Kind:      r.kind,
+		Name:      r.name,
+		Namespace: r.namespace,
+		Action:    action,
+		Diff:      unified,
+	}
+}
@@ -0,0 +1,112 @@
+package diff_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/helm/diff"
+)
+
+const prevManifest = `---
+# Source: web/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 80
+---
+# Source: web/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - image: nginx:1.19
+---
+# Source: web/templates/configmap.yaml
+apiVersion: v1
+kind: ConfigMap
+metadata:
+  name: web-config
+`
+
+const currManifest = `---
+# Source: web/templates/service.yaml
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+spec:
+  ports:
+  - port: 80
+---
+# Source: web/templates/deployment.yaml
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - image: nginx:1.20
+---
+# Source: web/templates/ingress.yaml
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: web
+`
+
+func TestManifests(t *testing.T) {
+	res, err := diff.Manifests(prevManifest, currManifest)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	expected := []struct {
+		kind   string
+		name   string
+		action string
+	}{
+		{"ConfigMap", "web-config", diff.ResourceRemoved},
+		{"Deployment", "web", diff.ResourceChanged},
+		{"Ingress", "web", diff.ResourceAdded},
+	}
+
+	if len(res) != len(expected) {
+		t.Fatalf("length of resource diffs incorrect: expected %d, got %d\n", len(expected), len(res))
+	}
+
+	for i, exp := range expected {
+		if res[i].Kind != exp.kind || res[i].Name != exp.name || res[i].Action != exp.action {
+			t.Errorf("incorrect resource diff %d: expected %s %s %s, got %s %s %s\n",
+				i, exp.kind, exp.name, exp.action, res[i].Kind, res[i].Name, res[i].Action)
+		}
+	}
+
+	changed := res[1].Diff
+
+	if !strings.Contains(changed, "-      - image: nginx:1.19") ||
+		!strings.Contains(changed, "+      - image: nginx:1.20") {
+		t.Errorf("incorrect diff for changed resource:\n%s", changed)
+	}
+}
+
+func TestManifestsUnchanged(t *testing.T) {
+	res, err := diff.Manifests(prevManifest, prevManifest)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(res) != 0 {
+		t.Errorf("expected no resource diffs, got %d\n", len(res))
+	}
+}
@@ -0,0 +1,105 @@
+package diff
+
+import (
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// Values returns the changes between two sets of Helm values. Nested maps are
+// compared key by key, while lists and scalars are compared as a whole. Changes
+// are sorted by path.
+func Values(prev, curr map[string]interface{}) []*models.ValueChange {
+	res := make([]*models.ValueChange, 0)
+
+	diffMaps(nil, prev, curr, &res)
+
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].Path < res[j].Path
+	})
+
+	return res
+}
+
+func diffMaps(path []string, prev, curr map[string]interface{}, res *[]*models.ValueChange) {
+	for key, prevVal := range prev {
+		currVal, ok := curr[key]
+
+		if !ok {
+			*res = append(*res, &models.ValueChange{
+				Path: joinPath(path, key),
+				Old:  prevVal,
+			})
+
+			continue
+		}
+
+		diffValues(append(path, key), prevVal, currVal, res)
+	}
+
+	for key, currVal := range curr {
+		if _, ok := prev[key]; !ok {
+			*res = append(*res, &models.ValueChange{
+				Path: joinPath(path, key),
+				New:  currVal,
+			})
+		}
+	}
+}
+
+func diffValues(path []string, prev, curr interface{}, res *[]*models.ValueChange) {
+	prevMap, prevIsMap := prev.(map[string]interface{})
+	currMap, currIsMap := curr.(map[string]interface{})
+
+	if prevIsMap && currIsMap {
+		diffMaps(path, prevMap, currMap, res)
+		return
+	}
+
+	if !reflect.DeepEqual(prev, curr) {
+		*res = append(*res, &models.ValueChange{
+			Path: strings.Join(path, "."),
+			Old:  prev,
+			New:  curr,
+		})
+	}
+}
+
+func joinPath(path []string, key string) string {
+	return strings.Join(append(append([]string{}, path...), key), ".")
+}
+
+// CopyValues returns a deep copy of a set of Helm values, so that the values can be
+// compared after the original has been modified
+func CopyValues(values map[string]interface{}) map[string]interface{} {
+	if values == nil {
+		return nil
+	}
+
+	res := make(map[string]interface{}, len(values))
+
+	for key, val := range values {
+		res[key] = copyValue(val)
+	}
+
+	return res
+}
+
+func copyValue(val interface{}) interface{} {
+	switch typed := val.(type) {
+	case map[string]interface{}:
+		return CopyValues(typed)
+	case []interface{}:
+		res := make([]interface{}, len(typed))
+
+		for i, item := range typed {
+			res[i] = copyValue(item)
+		}
+
+		return res
+	default:
+		return val
+	}
+}
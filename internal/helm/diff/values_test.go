@@ -0,0 +1,61 @@
+package diff_test
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/porter-dev/porter/internal/helm/diff"
+	"github.com/porter-dev/porter/internal/models"
+)
+
+func TestValues(t *testing.T) {
+	prev := map[string]interface{}{
+		"image": map[string]interface{}{
+			"repository": "nginx",
+			"tag":        "1.19",
+		},
+		"replicaCount": 1,
+		"ingress": map[string]interface{}{
+			"enabled": false,
+		},
+		"hosts": []interface{}{"a.example.com"},
+	}
+
+	curr := diff.CopyValues(prev)
+
+	curr["image"].(map[string]interface{})["tag"] = "1.20"
+	curr["hosts"] = []interface{}{"a.example.com", "b.example.com"}
+	curr["resources"] = map[string]interface{}{"memory": "256Mi"}
+	delete(curr, "ingress")
+
+	expected := []*models.ValueChange{
+		{
+			Path: "hosts",
+			Old:  []interface{}{"a.example.com"},
+			New:  []interface{}{"a.example.com", "b.example.com"},
+		},
+		{
+			Path: "image.tag",
+			Old:  "1.19",
+			New:  "1.20",
+		},
+		{
+			Path: "ingress",
+			Old:  map[string]interface{}{"enabled": false},
+		},
+		{
+			Path: "resources",
+			New:  map[string]interface{}{"memory": "256Mi"},
+		},
+	}
+
+	if d := deep.Equal(expected, diff.Values(prev, curr)); d != nil {
+		t.Errorf("incorrect values diff")
+		t.Error(d)
+	}
+
+	// the copy should not share nested maps with the original
+	if prev["image"].(map[string]interface{})["tag"] != "1.19" {
+		t.Errorf("copied values modified the original")
+	}
+}
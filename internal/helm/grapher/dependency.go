@@ -0,0 +1,351 @@
+package grapher
+
+import (
+	"sort"
+	"strings"
+)
+
+// The ways in which a release can depend on a resource
+const (
+	// DependencyEnv is an env var whose value contains the host of a Service
+	DependencyEnv = "env"
+	// DependencyEnvFrom is an env var or envFrom that reads a ConfigMap or Secret
+	DependencyEnvFrom = "env_from"
+	// DependencyVolume is a volume that mounts a ConfigMap, Secret or PVC
+	DependencyVolume = "volume"
+	// DependencyImagePullSecret is a Secret used to pull the images of a pod
+	DependencyImagePullSecret = "image_pull_secret"
+	// DependencyIngressBackend is a Service that an Ingress routes to
+	DependencyIngressBackend = "ingress_backend"
+	// DependencyIngressTLS is a Secret that an Ingress reads its certificate from
+	DependencyIngressTLS = "ingress_tls"
+)
+
+// ReleaseManifest is the rendered manifest of a single release
+type ReleaseManifest struct {
+	Name      string
+	Namespace string
+	Manifest  string
+}
+
+// Dependency is an edge from a release to a resource that it uses. Target is the
+// release that defines the resource, and is empty if the resource is not part of
+// any release, such as a ConfigMap created outside of Helm.
+type Dependency struct {
+	Source string `json:"source"`
+	Target string `json:"target,omitempty"`
+	Kind   string `json:"kind"`
+	Name   string `json:"name"`
+	Type   string `json:"type"`
+}
+
+// DependencyGraph contains the dependencies between the releases of a namespace
+type DependencyGraph struct {
+	Namespace    string       `json:"namespace"`
+	Releases     []string     `json:"releases"`
+	Dependencies []Dependency `json:"dependencies"`
+}
+
+// DependentsOf returns the names of the releases that depend on a release
+func (g *DependencyGraph) DependentsOf(release string) []string {
+	res := []string{}
+
+	for _, dep := range g.Dependencies {
+		if dep.Target == release {
+			res = appendIfNotDuplicate(res, dep.Source)
+		}
+	}
+
+	return res
+}
+
+// GetDependencyGraph parses the manifests of the releases in a namespace, and returns
+// the Services, ConfigMaps, Secrets and PVCs that each release uses along with the
+// release that defines them. Objects in other namespaces are ignored.
+func GetDependencyGraph(namespace string, manifests []ReleaseManifest) *DependencyGraph {
+	graph := &DependencyGraph{
+		Namespace:    namespace,
+		Releases:     []string{},
+		Dependencies: []Dependency{},
+	}
+
+	// the objects of each release, and the release that owns each object
+	releaseObjs := make(map[string][]Object)
+	owners := make(map[string]string)
+
+	for _, manifest := range manifests {
+		objs := ParseObjs(ImportMultiDocYAML([]byte(manifest.Manifest)), manifest.Namespace)
+
+		for _, obj := range objs {
+			if obj.Namespace == namespace {
+				owners[objectKey(obj.Kind, obj.Name)] = manifest.Name
+			}
+		}
+
+		graph.Releases = append(graph.Releases, manifest.Name)
+		releaseObjs[manifest.Name] = objs
+	}
+
+	seen := make(map[Dependency]bool)
+
+	for _, release := range graph.Releases {
+		for _, obj := range releaseObjs[release] {
+			if obj.Namespace != namespace {
+				continue
+			}
+
+			for _, ref := range findReferences(obj, namespace, owners) {
+				dep := Dependency{
+					Source: release,
+					Target: owners[objectKey(ref.kind, ref.name)],
+					Kind:   ref.kind,
+					Name:   ref.name,
+					Type:   ref.depType,
+				}
+
+				// resources of a release used by the release itself are not dependencies
+				if dep.Target == release || seen[dep] {
+					continue
+				}
+
+				seen[dep] = true
+				graph.Dependencies = append(graph.Dependencies, dep)
+			}
+		}
+	}
+
+	sort.Strings(graph.Releases)
+
+	sort.SliceStable(graph.Dependencies, func(i, j int) bool {
+		a, b := graph.Dependencies[i], graph.Dependencies[j]
+
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+
+		if a.Kind != b.Kind {
+			return a.Kind < b.Kind
+		}
+
+		if a.Name != b.Name {
+			return a.Name < b.Name
+		}
+
+		return a.Type < b.Type
+	})
+
+	return graph
+}
+
+// reference is a resource referenced by an object
+type reference struct {
+	kind    string
+	name    string
+	depType string
+}
+
+func objectKey(kind, name string) string {
+	return kind + "/" + name
+}
+
+// findReferences returns the resources that an object references
+func findReferences(obj Object, namespace string, owners map[string]string) []reference {
+	refs := []reference{}
+
+	switch obj.Kind {
+	case "Ingress":
+		refs = append(refs, findIngressReferences(obj.RawYAML)...)
+	case "Pod":
+		refs = append(refs, findPodReferences(nestedMap(obj.RawYAML, "spec"), namespace, owners)...)
+	case "Deployment", "StatefulSet", "ReplicaSet", "DaemonSet", "Job":
+		refs = append(refs, findPodReferences(nestedMap(obj.RawYAML, "spec", "template", "spec"), namespace, owners)...)
+	case "CronJob":
+		refs = append(refs, findPodReferences(
+			nestedMap(obj.RawYAML, "spec", "jobTemplate", "spec", "template", "spec"),
+			namespace,
+			owners,
+		)...)
+	}
+
+	return refs
+}
+
+func findIngressReferences(yaml map[string]interface{}) []reference {
+	refs := []reference{}
+
+	addBackend := func(backend map[string]interface{}) {
+		// the service name is under serviceName for extensions/v1beta1, and under
+		// service.name for networking.k8s.io/v1
+		name := nestedString(backend, "serviceName")
+
+		if name == "" {
+			name = nestedString(backend, "service", "name")
+		}
+
+		if name != "" {
+			refs = append(refs, reference{"Service", name, DependencyIngressBackend})
+		}
+	}
+
+	addBackend(nestedMap(yaml, "spec", "backend"))
+	addBackend(nestedMap(yaml, "spec", "defaultBackend"))
+
+	for _, rule := range nestedSlice(yaml, "spec", "rules") {
+		for _, path := range nestedSlice(asMap(rule), "http", "paths") {
+			addBackend(nestedMap(asMap(path), "backend"))
+		}
+	}
+
+	for _, tls := range nestedSlice(yaml, "spec", "tls") {
+		if name := nestedString(asMap(tls), "secretName"); name != "" {
+			refs = append(refs, reference{"Secret", name, DependencyIngressTLS})
+		}
+	}
+
+	return refs
+}
+
+func findPodReferences(spec map[string]interface{}, namespace string, owners map[string]string) []reference {
+	refs := []reference{}
+
+	if spec == nil {
+		return refs
+	}
+
+	containers := []interface{}{}
+	containers = append(containers, nestedSlice(spec, "containers")...)
+	containers = append(containers, nestedSlice(spec, "initContainers")...)
+
+	for _, c := range containers {
+		container := asMap(c)
+
+		for _, e := range nestedSlice(container, "env") {
+			env := asMap(e)
+
+			if name := nestedString(env, "valueFrom", "configMapKeyRef", "name"); name != "" {
+				refs = append(refs, reference{"ConfigMap", name, DependencyEnvFrom})
+			}
+
+			if name := nestedString(env, "valueFrom", "secretKeyRef", "name"); name != "" {
+				refs = append(refs, reference{"Secret", name, DependencyEnvFrom})
+			}
+
+			for _, host := range findServiceHosts(nestedString(env, "value"), namespace, owners) {
+				refs = append(refs, reference{"Service", host, DependencyEnv})
+			}
+		}
+
+		for _, e := range nestedSlice(container, "envFrom") {
+			envFrom := asMap(e)
+
+			if name := nestedString(envFrom, "configMapRef", "name"); name != "" {
+				refs = append(refs, reference{"ConfigMap", name, DependencyEnvFrom})
+			}
+
+			if name := nestedString(envFrom, "secretRef", "name"); name != "" {
+				refs = append(refs, reference{"Secret", name, DependencyEnvFrom})
+			}
+		}
+	}
+
+	for _, v := range nestedSlice(spec, "volumes") {
+		volume := asMap(v)
+
+		if name := nestedString(volume, "configMap", "name"); name != "" {
+			refs = append(refs, reference{"ConfigMap", name, DependencyVolume})
+		}
+
+		if name := nestedString(volume, "secret", "secretName"); name != "" {
+			refs = append(refs, reference{"Secret", name, DependencyVolume})
+		}
+
+		if name := nestedString(volume, "persistentVolumeClaim", "claimName"); name != "" {
+			refs = append(refs, reference{"PersistentVolumeClaim", name, DependencyVolume})
+		}
+	}
+
+	for _, s := range nestedSlice(spec, "imagePullSecrets") {
+		if name := nestedString(asMap(s), "name"); name != "" {
+			refs = append(refs, reference{"Secret", name, DependencyImagePullSecret})
+		}
+	}
+
+	return refs
+}
+
+// findServiceHosts returns the names of the Services in owners whose host appears in
+// an env var value, either as the short name or as a name qualified by the namespace,
+// such as "redis", "redis:6379" or "postgres://user@db.default.svc.cluster.local/app"
+func findServiceHosts(value, namespace string, owners map[string]string) []string {
+	hosts := []string{}
+
+	if value == "" {
+		return hosts
+	}
+
+	tokens := strings.FieldsFunc(value, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '-' || r == '.')
+	})
+
+	for _, token := range tokens {
+		name := token
+
+		if i := strings.Index(token, "."); i >= 0 {
+			// only hosts qualified with this namespace refer to its services
+			if !strings.HasPrefix(token[i+1:], namespace) {
+				continue
+			}
+
+			rest := token[i+1+len(namespace):]
+
+			if rest != "" && !strings.HasPrefix(rest, ".svc") {
+				continue
+			}
+
+			name = token[:i]
+		}
+
+		if _, ok := owners[objectKey("Service", name)]; ok {
+			hosts = appendIfNotDuplicate(hosts, name)
+		}
+	}
+
+	return hosts
+}
+
+// =============== helpers for reading fields that may be missing ===============
+
+func asMap(val interface{}) map[string]interface{} {
+	m, _ := val.(map[string]interface{})
+	return m
+}
+
+func nestedField(yaml map[string]interface{}, keys ...string) interface{} {
+	var val interface{} = yaml
+
+	for _, key := range keys {
+		m := asMap(val)
+
+		if m == nil {
+			return nil
+		}
+
+		val = m[key]
+	}
+
+	return val
+}
+
+func nestedMap(yaml map[string]interface{}, keys ...string) map[string]interface{} {
+	return asMap(nestedField(yaml, keys...))
+}
+
+func nestedSlice(yaml map[string]interface{}, keys ...string) []interface{} {
+	s, _ := nestedField(yaml, keys...).([]interface{})
+	return s
+}
+
+func nestedString(yaml map[string]interface{}, keys ...string) string {
+	s, _ := nestedField(yaml, keys...).(string)
+	return s
+}
@@ -0,0 +1,133 @@
+package grapher_test
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/helm/grapher"
+)
+
+const dbManifest = `
+apiVersion: v1
+kind: Service
+metadata:
+  name: db-postgresql
+spec:
+  ports:
+  - port: 5432
+---
+apiVersion: v1
+kind: Secret
+metadata:
+  name: db-postgresql
+---
+apiVersion: v1
+kind: PersistentVolumeClaim
+metadata:
+  name: db-data
+`
+
+const webManifest = `
+apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      imagePullSecrets:
+      - name: registry
+      containers:
+      - name: web
+        env:
+        - name: DATABASE_URL
+          value: postgres://user@db-postgresql.default.svc.cluster.local:5432/app
+        - name: DB_PASSWORD
+          valueFrom:
+            secretKeyRef:
+              name: db-postgresql
+              key: password
+        - name: OTHER_NAMESPACE
+          value: db-postgresql.staging
+        envFrom:
+        - configMapRef:
+            name: shared-env
+      volumes:
+      - name: data
+        persistentVolumeClaim:
+          claimName: db-data
+---
+apiVersion: v1
+kind: Service
+metadata:
+  name: web
+---
+apiVersion: networking.k8s.io/v1
+kind: Ingress
+metadata:
+  name: web
+spec:
+  tls:
+  - secretName: web-tls
+  rules:
+  - http:
+      paths:
+      - backend:
+          service:
+            name: web
+`
+
+const workerManifest = `
+apiVersion: batch/v1beta1
+kind: CronJob
+metadata:
+  name: worker
+spec:
+  jobTemplate:
+    spec:
+      template:
+        spec:
+          containers:
+          - name: worker
+            env:
+            - name: WEB_HOST
+              value: web:80
+            envFrom:
+            - configMapRef:
+                name: shared-env
+`
+
+func TestGetDependencyGraph(t *testing.T) {
+	graph := grapher.GetDependencyGraph("default", []grapher.ReleaseManifest{
+		{Name: "web", Namespace: "default", Manifest: webManifest},
+		{Name: "worker", Namespace: "default", Manifest: workerManifest},
+		{Name: "db", Namespace: "default", Manifest: dbManifest},
+	})
+
+	expReleases := []string{"db", "web", "worker"}
+
+	if !reflect.DeepEqual(graph.Releases, expReleases) {
+		t.Errorf("releases incorrect: expected %v, got %v\n", expReleases, graph.Releases)
+	}
+
+	expDeps := []grapher.Dependency{
+		{Source: "web", Kind: "ConfigMap", Name: "shared-env", Type: grapher.DependencyEnvFrom},
+		{Source: "web", Target: "db", Kind: "PersistentVolumeClaim", Name: "db-data", Type: grapher.DependencyVolume},
+		{Source: "web", Target: "db", Kind: "Secret", Name: "db-postgresql", Type: grapher.DependencyEnvFrom},
+		{Source: "web", Kind: "Secret", Name: "registry", Type: grapher.DependencyImagePullSecret},
+		{Source: "web", Kind: "Secret", Name: "web-tls", Type: grapher.DependencyIngressTLS},
+		{Source: "web", Target: "db", Kind: "Service", Name: "db-postgresql", Type: grapher.DependencyEnv},
+		{Source: "worker", Kind: "ConfigMap", Name: "shared-env", Type: grapher.DependencyEnvFrom},
+		{Source: "worker", Target: "web", Kind: "Service", Name: "web", Type: grapher.DependencyEnv},
+	}
+
+	if !reflect.DeepEqual(graph.Dependencies, expDeps) {
+		t.Errorf("dependencies incorrect:\nexpected %v\ngot      %v\n", expDeps, graph.Dependencies)
+	}
+
+	expDependents := []string{"web"}
+
+	if dependents := graph.DependentsOf("db"); !reflect.DeepEqual(dependents, expDependents) {
+		t.Errorf("dependents incorrect: expected %v, got %v\n", expDependents, dependents)
+	}
+}
@@ -0,0 +1,92 @@
+package helm
+
+import (
+	"sort"
+
+	"helm.sh/helm/v3/pkg/releaseutil"
+	"sigs.k8s.io/yaml"
+)
+
+// ListRevisionImageRefs lists the container images in the manifests of every stored
+// revision of every release that the agent's storage can read, including revisions
+// that have been superseded or failed, since a release can be rolled back to them
+func (a *Agent) ListRevisionImageRefs() ([]string, error) {
+	rels, err := a.ActionConfig.Releases.ListReleases()
+
+	if err != nil {
+		return nil, err
+	}
+
+	refs := make(map[string]bool)
+
+	for _, rel := range rels {
+		for _, image := range GetManifestImages(rel.Manifest) {
+			refs[image] = true
+		}
+	}
+
+	res := make([]string, 0, len(refs))
+
+	for ref := range refs {
+		res = append(res, ref)
+	}
+
+	sort.Strings(res)
+
+	return res, nil
+}
+
+// GetManifestImages returns the images of the containers and init containers of the
+// resources in a rendered manifest. Documents that cannot be parsed are skipped.
+func GetManifestImages(manifest string) []string {
+	refs := make(map[string]bool)
+
+	for _, doc := range releaseutil.SplitManifests(manifest) {
+		obj := make(map[string]interface{})
+
+		if err := yaml.Unmarshal([]byte(doc), &obj); err != nil {
+			continue
+		}
+
+		addContainerImages(obj, refs)
+	}
+
+	res := make([]string, 0, len(refs))
+
+	for ref := range refs {
+		res = append(res, ref)
+	}
+
+	sort.Strings(res)
+
+	return res
+}
+
+// addContainerImages walks a parsed resource and adds the image of each container
+// that it finds, so that pod templates nested at any depth are included
+func addContainerImages(val interface{}, refs map[string]bool) {
+	switch v := val.(type) {
+	case map[string]interface{}:
+		for key, child := range v {
+			if key == "containers" || key == "initContainers" {
+				if containers, ok := child.([]interface{}); ok {
+					for _, container := range containers {
+						if c, ok := container.(map[string]interface{}); ok {
+							if image, ok := c["image"].(string); ok && image != "" {
+								refs[image] = true
+							}
+						}
+					}
+				}
+
+				continue
+			}
+
+			addContainerImages(child, refs)
+		}
+	case []interface{}:
+		for _, child := range v {
+			addContainerImages(child, refs)
+		}
+	}
+}
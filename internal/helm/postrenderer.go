@@ -32,12 +32,18 @@ type DockerSecretsPostRenderer struct {
 	Namespace string
 	DOAuth    *oauth2.Config
 
+	// DryRun links the image pull secrets to the pod specs without creating or
+	// updating them
+	DryRun bool
+
 	registries map[string]*models.Registry
 
 	podSpecs  []resource
 	resources []resource
 }
 
+var _ postrender.PostRenderer = &DockerSecretsPostRenderer{}
+
 // while manifests are map[string]interface{} at the top level,
 // nested keys will be of type map[interface{}]interface{}
 type resource map[interface{}]interface{}
@@ -49,7 +55,7 @@ func NewDockerSecretsPostRenderer(
 	namespace string,
 	regs []*models.Registry,
 	doAuth *oauth2.Config,
-) (postrender.PostRenderer, error) {
+) (*DockerSecretsPostRenderer, error) {
 	// Registries is a map of registry URLs to registry ids
 	registries := make(map[string]*models.Registry)
 
@@ -148,6 +154,7 @@ func (d *DockerSecretsPostRenderer) Run(
 					Agent:      d.Agent,
 					Namespace:  d.Namespace,
 					DOAuth:     d.DOAuth,
+					DryRun:     d.DryRun,
 					registries: d.registries,
 					podSpecs:   make([]resource, 0),
 					resources:  make([]resource, 0),
@@ -167,12 +174,7 @@ func (d *DockerSecretsPostRenderer) Run(
 	}
 
 	// create the necessary secrets
-	secrets, err := d.Agent.CreateImagePullSecrets(
-		d.Repo,
-		d.Namespace,
-		linkedRegs,
-		d.DOAuth,
-	)
+	secrets, err := d.createImagePullSecrets(linkedRegs)
 
 	if err != nil {
 		return renderedManifests, nil
@@ -195,6 +197,25 @@ func (d *DockerSecretsPostRenderer) Run(
 	return modifiedManifests, nil
 }
 
+// createImagePullSecrets creates the image pull secrets of the linked registries, and
+// returns a map from the registry name to the name of the secret. A dry run only
+// returns the names of the secrets.
+func (d *DockerSecretsPostRenderer) createImagePullSecrets(
+	linkedRegs map[string]*models.Registry,
+) (map[string]string, error) {
+	if !d.DryRun {
+		return d.Agent.CreateImagePullSecrets(d.Repo, d.Namespace, linkedRegs, d.DOAuth)
+	}
+
+	secrets := make(map[string]string)
+
+	for key, reg := range linkedRegs {
+		secrets[key] = kubernetes.ImagePullSecretName(reg)
+	}
+
+	return secrets, nil
+}
+
 func (d *DockerSecretsPostRenderer) getRegistriesToLink(renderedManifests *bytes.Buffer) (map[string]*models.Registry, error) {
 	// create a map of registry names to registries: these are the registries
 	// that a secret will be generated for, if it does not exist
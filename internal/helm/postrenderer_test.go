@@ -0,0 +1,73 @@
+package helm
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/kubernetes"
+	"github.com/porter-dev/porter/internal/models"
+	memory "github.com/porter-dev/porter/internal/repository/memory"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const postRendererManifest = `apiVersion: apps/v1
+kind: Deployment
+metadata:
+  name: web
+spec:
+  template:
+    spec:
+      containers:
+      - name: web
+        image: registry.example.com/web:latest
+`
+
+func TestDockerSecretsPostRendererDryRun(t *testing.T) {
+	k8sAgent := kubernetes.GetAgentTesting()
+	repo := memory.NewRepository(true)
+
+	reg := &models.Registry{
+		ProjectID: 1,
+		URL:       "registry.example.com",
+	}
+
+	reg.ID = 1
+
+	d, err := NewDockerSecretsPostRenderer(
+		&models.Cluster{ProjectID: 1},
+		*repo,
+		k8sAgent,
+		"default",
+		[]*models.Registry{reg},
+		nil,
+	)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	d.DryRun = true
+
+	res, err := d.Run(bytes.NewBufferString(postRendererManifest))
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	// the manifest links the secret that the upgrade would create
+	if secretName := kubernetes.ImagePullSecretName(reg); !strings.Contains(res.String(), secretName) {
+		t.Errorf("expected manifest to reference secret %s, got:\n%s\n", secretName, res.String())
+	}
+
+	secrets, err := k8sAgent.Clientset.CoreV1().Secrets("default").List(context.TODO(), metav1.ListOptions{})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(secrets.Items) != 0 {
+		t.Errorf("expected dry run to not create secrets, got %d\n", len(secrets.Items))
+	}
+}
@@ -0,0 +1,232 @@
+package rollout
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/porter-dev/porter/internal/helm/diff"
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// The strategies of a rollout
+const (
+	// StrategyCanary shifts traffic to the new values in steps
+	StrategyCanary = "canary"
+	// StrategyBlueGreen shifts all traffic to the new values at once, and keeps the
+	// old values running until they are promoted
+	StrategyBlueGreen = "blue_green"
+)
+
+// The NGINX ingress annotations that split traffic between a release and its canary
+const (
+	AnnotationCanary       = "nginx.ingress.kubernetes.io/canary"
+	AnnotationCanaryWeight = "nginx.ingress.kubernetes.io/canary-weight"
+)
+
+// The defaults of a rollout
+const (
+	DefaultStepDuration = 5 * time.Minute
+	DefaultMaxErrorRate = 5.0
+	DefaultMaxLatency   = 1.0
+)
+
+// DefaultCanarySteps are the percentages of traffic that a canary rollout sends to
+// the new values if no steps are set
+var DefaultCanarySteps = []uint{10, 50}
+
+// CanaryName returns the name of the release that runs the new values during a
+// rollout
+func CanaryName(name string) string {
+	return name + "-canary"
+}
+
+// Config is the configuration of a rollout
+type Config struct {
+	Strategy     string
+	Steps        []uint
+	StepDuration time.Duration
+
+	// MaxErrorRate is the highest percentage of 5xx responses, and MaxLatency the
+	// highest average latency in seconds, that the canary may serve. A threshold of
+	// 0 is not checked.
+	MaxErrorRate float64
+	MaxLatency   float64
+}
+
+// GetSteps returns the percentages of traffic that are sent to the canary
+func (c *Config) GetSteps() []uint {
+	if c.Strategy == StrategyBlueGreen {
+		return []uint{100}
+	}
+
+	if len(c.Steps) == 0 {
+		return DefaultCanarySteps
+	}
+
+	return c.Steps
+}
+
+// NewConfig returns the configuration of a stored rollout
+func NewConfig(r *models.Rollout) *Config {
+	return &Config{
+		Strategy:     r.Strategy,
+		Steps:        r.GetSteps(),
+		StepDuration: time.Duration(r.StepDuration) * time.Second,
+		MaxErrorRate: r.MaxErrorRate,
+		MaxLatency:   r.MaxLatency,
+	}
+}
+
+// Analysis is the error rate and latency that the canary served during a step
+type Analysis struct {
+	ErrorRate float64
+	Latency   float64
+}
+
+// Check returns an error describing the first threshold that an analysis exceeds
+func (c *Config) Check(analysis *Analysis) error {
+	if c.MaxErrorRate > 0 && analysis.ErrorRate > c.MaxErrorRate {
+		return fmt.Errorf("error rate of %.2f%% exceeded the maximum of %.2f%%", analysis.ErrorRate, c.MaxErrorRate)
+	}
+
+	if c.MaxLatency > 0 && analysis.Latency > c.MaxLatency {
+		return fmt.Errorf("average latency of %.3fs exceeded the maximum of %.3fs", analysis.Latency, c.MaxLatency)
+	}
+
+	return nil
+}
+
+// CanaryValues returns the values of the canary release, which are the new values
+// with an ingress that receives weight percent of the traffic of the release
+func CanaryValues(values map[string]interface{}, weight uint) map[string]interface{} {
+	res := diff.CopyValues(values)
+
+	if res == nil {
+		res = make(map[string]interface{})
+	}
+
+	ingress, ok := res["ingress"].(map[string]interface{})
+
+	if !ok {
+		ingress = make(map[string]interface{})
+		res["ingress"] = ingress
+	}
+
+	annotations, ok := ingress["annotations"].(map[string]interface{})
+
+	if !ok {
+		annotations = make(map[string]interface{})
+		ingress["annotations"] = annotations
+	}
+
+	annotations[AnnotationCanary] = "true"
+	annotations[AnnotationCanaryWeight] = fmt.Sprintf("%d", weight)
+
+	return res
+}
+
+// Target is the release that a rollout is run against
+type Target interface {
+	// InstallCanary installs the canary release with the new values, and sends
+	// weight percent of traffic to it
+	InstallCanary(weight uint) error
+
+	// SetCanaryWeight sends weight percent of traffic to the canary release
+	SetCanaryWeight(weight uint) error
+
+	// Analyze returns the error rate and latency that the canary served over the
+	// last interval
+	Analyze(interval time.Duration) (*Analysis, error)
+
+	// Promote upgrades the release with the new values, returning the new
+	// revision
+	Promote() (int, error)
+
+	// RemoveCanary uninstalls the canary release
+	RemoveCanary() error
+}
+
+// Result is the outcome of a rollout
+type Result struct {
+	Status   string
+	Revision int
+	Info     string
+}
+
+// Run runs a rollout against a target. The canary receives each step of traffic
+// for conf.StepDuration, and is removed as soon as an analysis exceeds the
+// thresholds. If every step passes, the new values are promoted to the release.
+// wait is called to wait out each step, and returns false if the rollout was
+// canceled in the meantime. onStep is called when the canary starts receiving a
+// new percentage of traffic.
+func Run(target Target, conf *Config, wait func(time.Duration) bool, onStep func(weight uint)) *Result {
+	for i, weight := range conf.GetSteps() {
+		var err error
+
+		if i == 0 {
+			err = target.InstallCanary(weight)
+		} else {
+			err = target.SetCanaryWeight(weight)
+		}
+
+		if err != nil {
+			return removeCanary(target, &Result{
+				Status: models.RolloutStatusFailed,
+				Info:   fmt.Sprintf("could not send %d%% of traffic to the canary: %v", weight, err),
+			})
+		}
+
+		onStep(weight)
+
+		if !wait(conf.StepDuration) {
+			return removeCanary(target, &Result{
+				Status: models.RolloutStatusCanceled,
+				Info:   fmt.Sprintf("canceled at %d%% of traffic", weight),
+			})
+		}
+
+		analysis, err := target.Analyze(conf.StepDuration)
+
+		if err != nil {
+			return removeCanary(target, &Result{
+				Status: models.RolloutStatusFailed,
+				Info:   fmt.Sprintf("could not analyze the canary: %v", err),
+			})
+		}
+
+		if err := conf.Check(analysis); err != nil {
+			return removeCanary(target, &Result{
+				Status: models.RolloutStatusRolledBack,
+				Info:   fmt.Sprintf("at %d%% of traffic, %v", weight, err),
+			})
+		}
+	}
+
+	revision, err := target.Promote()
+
+	if err != nil {
+		return removeCanary(target, &Result{
+			Status: models.RolloutStatusFailed,
+			Info:   fmt.Sprintf("could not promote the new values: %v", err),
+		})
+	}
+
+	return removeCanary(target, &Result{
+		Status:   models.RolloutStatusPromoted,
+		Revision: revision,
+	})
+}
+
+// removeCanary removes the canary release, and adds the error to the result if it
+// could not be removed
+func removeCanary(target Target, res *Result) *Result {
+	if err := target.RemoveCanary(); err != nil {
+		if res.Info != "" {
+			res.Info += "; "
+		}
+
+		res.Info += fmt.Sprintf("could not remove the canary: %v", err)
+	}
+
+	return res
+}
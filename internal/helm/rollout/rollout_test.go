@@ -0,0 +1,192 @@
+package rollout
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// fakeTarget records the calls that a rollout makes, and returns the analyses in
+// order
+type fakeTarget struct {
+	calls    []string
+	analyses []*Analysis
+
+	promoteErr error
+}
+
+func (f *fakeTarget) InstallCanary(weight uint) error {
+	f.calls = append(f.calls, fmt.Sprintf("install %d", weight))
+	return nil
+}
+
+func (f *fakeTarget) SetCanaryWeight(weight uint) error {
+	f.calls = append(f.calls, fmt.Sprintf("weight %d", weight))
+	return nil
+}
+
+func (f *fakeTarget) Analyze(interval time.Duration) (*Analysis, error) {
+	f.calls = append(f.calls, "analyze")
+
+	analysis := f.analyses[0]
+	f.analyses = f.analyses[1:]
+
+	return analysis, nil
+}
+
+func (f *fakeTarget) Promote() (int, error) {
+	f.calls = append(f.calls, "promote")
+	return 3, f.promoteErr
+}
+
+func (f *fakeTarget) RemoveCanary() error {
+	f.calls = append(f.calls, "remove")
+	return nil
+}
+
+func TestRunPromotes(t *testing.T) {
+	target := &fakeTarget{
+		analyses: []*Analysis{{ErrorRate: 1, Latency: 0.2}, {ErrorRate: 0.5, Latency: 0.3}},
+	}
+
+	conf := &Config{
+		Strategy:     StrategyCanary,
+		Steps:        []uint{20, 60},
+		StepDuration: time.Minute,
+		MaxErrorRate: 2,
+		MaxLatency:   0.5,
+	}
+
+	var waited time.Duration
+	weights := []uint{}
+
+	res := Run(target, conf, func(d time.Duration) bool { waited += d; return true }, func(w uint) { weights = append(weights, w) })
+
+	if res.Status != models.RolloutStatusPromoted || res.Revision != 3 {
+		t.Errorf("result incorrect: %+v\n", res)
+	}
+
+	expCalls := []string{"install 20", "analyze", "weight 60", "analyze", "promote", "remove"}
+
+	if !reflect.DeepEqual(target.calls, expCalls) {
+		t.Errorf("calls incorrect: expected %v, got %v\n", expCalls, target.calls)
+	}
+
+	if !reflect.DeepEqual(weights, []uint{20, 60}) || waited != 2*time.Minute {
+		t.Errorf("steps incorrect: weights %v, waited %s\n", weights, waited)
+	}
+}
+
+func TestRunRollsBack(t *testing.T) {
+	target := &fakeTarget{
+		analyses: []*Analysis{{ErrorRate: 1, Latency: 0.2}, {ErrorRate: 1, Latency: 2}},
+	}
+
+	conf := &Config{
+		Strategy:     StrategyCanary,
+		MaxErrorRate: 2,
+		MaxLatency:   0.5,
+	}
+
+	res := Run(target, conf, func(time.Duration) bool { return true }, func(uint) {})
+
+	if res.Status != models.RolloutStatusRolledBack {
+		t.Errorf("status incorrect: expected %s, got %s\n", models.RolloutStatusRolledBack, res.Status)
+	}
+
+	if !strings.Contains(res.Info, "at 50% of traffic") || !strings.Contains(res.Info, "latency") {
+		t.Errorf("info incorrect: %s\n", res.Info)
+	}
+
+	expCalls := []string{"install 10", "analyze", "weight 50", "analyze", "remove"}
+
+	if !reflect.DeepEqual(target.calls, expCalls) {
+		t.Errorf("calls incorrect: expected %v, got %v\n", expCalls, target.calls)
+	}
+}
+
+func TestRunBlueGreenFailsToPromote(t *testing.T) {
+	target := &fakeTarget{
+		analyses:   []*Analysis{{}},
+		promoteErr: fmt.Errorf("upgrade failed"),
+	}
+
+	conf := &Config{
+		Strategy: StrategyBlueGreen,
+		Steps:    []uint{10},
+	}
+
+	res := Run(target, conf, func(time.Duration) bool { return true }, func(uint) {})
+
+	if res.Status != models.RolloutStatusFailed || !strings.Contains(res.Info, "upgrade failed") {
+		t.Errorf("result incorrect: %+v\n", res)
+	}
+
+	expCalls := []string{"install 100", "analyze", "promote", "remove"}
+
+	if !reflect.DeepEqual(target.calls, expCalls) {
+		t.Errorf("calls incorrect: expected %v, got %v\n", expCalls, target.calls)
+	}
+}
+
+func TestRunCanceled(t *testing.T) {
+	target := &fakeTarget{
+		analyses: []*Analysis{{}},
+	}
+
+	conf := &Config{
+		Strategy: StrategyCanary,
+		Steps:    []uint{20, 60},
+	}
+
+	waits := 0
+
+	res := Run(target, conf, func(time.Duration) bool {
+		waits++
+		return waits < 2
+	}, func(uint) {})
+
+	if res.Status != models.RolloutStatusCanceled || !strings.Contains(res.Info, "at 60% of traffic") {
+		t.Errorf("result incorrect: %+v\n", res)
+	}
+
+	expCalls := []string{"install 20", "analyze", "weight 60", "remove"}
+
+	if !reflect.DeepEqual(target.calls, expCalls) {
+		t.Errorf("calls incorrect: expected %v, got %v\n", expCalls, target.calls)
+	}
+}
+
+func TestCanaryValues(t *testing.T) {
+	values := map[string]interface{}{
+		"ingress": map[string]interface{}{
+			"hosts": []interface{}{"app.example.com"},
+			"annotations": map[string]interface{}{
+				"cert-manager.io/cluster-issuer": "letsencrypt-prod",
+			},
+		},
+	}
+
+	res := CanaryValues(values, 25)
+
+	expected := map[string]interface{}{
+		"cert-manager.io/cluster-issuer": "letsencrypt-prod",
+		AnnotationCanary:                 "true",
+		AnnotationCanaryWeight:           "25",
+	}
+
+	annotations := res["ingress"].(map[string]interface{})["annotations"]
+
+	if !reflect.DeepEqual(annotations, expected) {
+		t.Errorf("annotations incorrect: expected %v, got %v\n", expected, annotations)
+	}
+
+	// the values of the release are not modified
+	if len(values["ingress"].(map[string]interface{})["annotations"].(map[string]interface{})) != 1 {
+		t.Errorf("values of the release were modified\n")
+	}
+}
@@ -0,0 +1,344 @@
+package helm
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"strconv"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+// SQLDriverName is the name of the storage driver that stores releases in the
+// Porter database
+const SQLDriverName = "sql"
+
+// sqlDriverOwner is the owner label that Helm sets on the releases it stores
+const sqlDriverOwner = "helm"
+
+// ReleaseKey returns the key that Helm stores a revision of a release under
+func ReleaseKey(name string, version int) string {
+	return fmt.Sprintf("sh.helm.release.v1.%s.v%d", name, version)
+}
+
+// SQLDriver is a Helm storage driver that stores the releases of a cluster in the
+// Porter database, so that release history is not lost when a namespace is deleted
+// and can be queried across clusters. Releases are scoped to a single cluster, and
+// to a single namespace unless the namespace is empty. A driver without a namespace
+// lists releases across namespaces, but reads and writes single releases in the
+// namespace of the release, or "default".
+type SQLDriver struct {
+	repo      repository.HelmReleaseRepository
+	clusterID uint
+	namespace string
+
+	Log func(string, ...interface{})
+}
+
+var _ driver.Driver = &SQLDriver{}
+
+// NewSQLDriver returns a new SQLDriver for the releases of a cluster in a namespace
+func NewSQLDriver(
+	repo repository.HelmReleaseRepository,
+	clusterID uint,
+	namespace string,
+) *SQLDriver {
+	return &SQLDriver{
+		repo:      repo,
+		clusterID: clusterID,
+		namespace: namespace,
+		Log:       func(_ string, _ ...interface{}) {},
+	}
+}
+
+// Name returns the name of the driver
+func (d *SQLDriver) Name() string {
+	return SQLDriverName
+}
+
+// Get returns the release named by key, or driver.ErrReleaseNotFound
+func (d *SQLDriver) Get(key string) (*release.Release, error) {
+	record, err := d.repo.ReadHelmRelease(d.clusterID, d.storageNamespace(""), key)
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, driver.ErrReleaseNotFound
+		}
+
+		d.Log("get: failed to read release %s: %v", key, err)
+		return nil, err
+	}
+
+	rel, err := decodeSQLRelease(record.Body)
+
+	if err != nil {
+		d.Log("get: failed to decode release %s: %v", key, err)
+		return nil, err
+	}
+
+	return rel, nil
+}
+
+// List returns all releases owned by Helm for which filter returns true
+func (d *SQLDriver) List(filter func(*release.Release) bool) ([]*release.Release, error) {
+	records, err := d.repo.ListHelmReleases(&repository.HelmReleaseFilter{
+		ClusterID: d.clusterID,
+		Namespace: d.namespace,
+		Owner:     sqlDriverOwner,
+	})
+
+	if err != nil {
+		d.Log("list: failed to list releases: %v", err)
+		return nil, err
+	}
+
+	var rels []*release.Release
+
+	for _, rel := range d.decodeRecords(records) {
+		if filter(rel) {
+			rels = append(rels, rel)
+		}
+	}
+
+	return rels, nil
+}
+
+// Query returns all releases that match the labels, or driver.ErrReleaseNotFound.
+// The supported labels are name, status, owner and version.
+func (d *SQLDriver) Query(labels map[string]string) ([]*release.Release, error) {
+	filter := &repository.HelmReleaseFilter{
+		ClusterID: d.clusterID,
+		Namespace: d.namespace,
+	}
+
+	for key, val := range labels {
+		switch key {
+		case "name":
+			filter.Name = val
+		case "status":
+			filter.Status = val
+		case "owner":
+			filter.Owner = val
+		case "version":
+			version, err := strconv.Atoi(val)
+
+			if err != nil {
+				return nil, err
+			}
+
+			filter.Version = version
+		default:
+			d.Log("query: unknown label %s", key)
+			return nil, errors.New("unknown label " + key)
+		}
+	}
+
+	records, err := d.repo.ListHelmReleases(filter)
+
+	if err != nil {
+		d.Log("query: failed to query releases: %v", err)
+		return nil, err
+	}
+
+	rels := d.decodeRecords(records)
+
+	if len(rels) == 0 {
+		return nil, driver.ErrReleaseNotFound
+	}
+
+	return rels, nil
+}
+
+// Create stores the release, or returns driver.ErrReleaseExists
+func (d *SQLDriver) Create(key string, rel *release.Release) error {
+	namespace := d.storageNamespace(rel.Namespace)
+
+	if _, err := d.repo.ReadHelmRelease(d.clusterID, namespace, key); err == nil {
+		return driver.ErrReleaseExists
+	}
+
+	record := &models.HelmRelease{
+		ClusterID: d.clusterID,
+		Namespace: namespace,
+		Key:       key,
+	}
+
+	if err := setSQLRecordFromRelease(record, rel); err != nil {
+		d.Log("create: failed to encode release %s: %v", key, err)
+		return err
+	}
+
+	if _, err := d.repo.CreateHelmRelease(record); err != nil {
+		// the unique index on the key rejects a release that was created after it
+		// was read above, which is reported to Helm as an existing release
+		if _, readErr := d.repo.ReadHelmRelease(d.clusterID, namespace, key); readErr == nil {
+			return driver.ErrReleaseExists
+		}
+
+		d.Log("create: failed to store release %s: %v", key, err)
+		return err
+	}
+
+	return nil
+}
+
+// Update updates the stored release, or returns driver.ErrReleaseNotFound
+func (d *SQLDriver) Update(key string, rel *release.Release) error {
+	record, err := d.repo.ReadHelmRelease(d.clusterID, d.storageNamespace(rel.Namespace), key)
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return driver.ErrReleaseNotFound
+		}
+
+		return err
+	}
+
+	if err := setSQLRecordFromRelease(record, rel); err != nil {
+		d.Log("update: failed to encode release %s: %v", key, err)
+		return err
+	}
+
+	if _, err := d.repo.UpdateHelmRelease(record); err != nil {
+		d.Log("update: failed to update release %s: %v", key, err)
+		return err
+	}
+
+	return nil
+}
+
+// Delete removes the release named by key, or returns driver.ErrReleaseNotFound
+func (d *SQLDriver) Delete(key string) (*release.Release, error) {
+	record, err := d.repo.ReadHelmRelease(d.clusterID, d.storageNamespace(""), key)
+
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, driver.ErrReleaseNotFound
+		}
+
+		return nil, err
+	}
+
+	rel, err := decodeSQLRelease(record.Body)
+
+	if err != nil {
+		d.Log("delete: failed to decode release %s: %v", key, err)
+		return nil, err
+	}
+
+	if err := d.repo.DeleteHelmRelease(record); err != nil {
+		d.Log("delete: failed to delete release %s: %v", key, err)
+		return nil, err
+	}
+
+	return rel, nil
+}
+
+// storageNamespace returns the namespace that a single release is read from and
+// written to: the namespace of the driver, else the namespace of the release, else
+// "default". Reads pass an empty namespace, since Helm only passes the key.
+func (d *SQLDriver) storageNamespace(relNamespace string) string {
+	if d.namespace != "" {
+		return d.namespace
+	}
+
+	if relNamespace != "" {
+		return relNamespace
+	}
+
+	return "default"
+}
+
+// decodeRecords decodes a list of records, skipping records that cannot be decoded
+func (d *SQLDriver) decodeRecords(records []*models.HelmRelease) []*release.Release {
+	rels := make([]*release.Release, 0, len(records))
+
+	for _, record := range records {
+		rel, err := decodeSQLRelease(record.Body)
+
+		if err != nil {
+			d.Log("failed to decode release %s: %v", record.Key, err)
+			continue
+		}
+
+		rels = append(rels, rel)
+	}
+
+	return rels
+}
+
+func setSQLRecordFromRelease(record *models.HelmRelease, rel *release.Release) error {
+	body, err := encodeSQLRelease(rel)
+
+	if err != nil {
+		return err
+	}
+
+	record.Name = rel.Name
+	record.Version = rel.Version
+	record.Owner = sqlDriverOwner
+	record.Body = body
+
+	if rel.Info != nil {
+		record.Status = rel.Info.Status.String()
+	}
+
+	return nil
+}
+
+func encodeSQLRelease(rel *release.Release) ([]byte, error) {
+	data, err := json.Marshal(rel)
+
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+
+	w, err := gzip.NewWriterLevel(&buf, gzip.BestCompression)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := w.Write(data); err != nil {
+		return nil, err
+	}
+
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func decodeSQLRelease(body []byte) (*release.Release, error) {
+	r, err := gzip.NewReader(bytes.NewReader(body))
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer r.Close()
+
+	data, err := ioutil.ReadAll(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	rel := &release.Release{}
+
+	if err := json.Unmarshal(data, rel); err != nil {
+		return nil, err
+	}
+
+	return rel, nil
+}
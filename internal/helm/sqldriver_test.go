@@ -0,0 +1,143 @@
+package helm
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/logger"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	memory "github.com/porter-dev/porter/internal/repository/memory"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	"helm.sh/helm/v3/pkg/storage/driver"
+)
+
+func newSQLTestRelease(namespace string) *release.Release {
+	return &release.Release{
+		Name:      "porter",
+		Namespace: namespace,
+		Version:   1,
+		Info: &release.Info{
+			Status: release.StatusDeployed,
+		},
+		Chart: &chart.Chart{
+			Metadata: &chart.Metadata{
+				Version: "1.0.0",
+				Icon:    "https://example.com/icon.png",
+			},
+		},
+	}
+}
+
+func TestNewSQLStorageDriver(t *testing.T) {
+	repo := memory.NewRepository(true)
+	l := logger.NewConsole(true)
+
+	stg := NewSQLStorageDriver(l, repo.HelmRelease, 1, "default")
+	rel := newSQLTestRelease("default")
+
+	if err := stg.Create(rel); err != nil {
+		t.Fatal(err)
+	}
+
+	gotRel, err := stg.Get("porter", 1)
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if !reflect.DeepEqual(rel, gotRel) {
+		t.Fatalf("Objects not equal: expected %v, got %v\n", rel, gotRel)
+	}
+
+	// releases are scoped to their cluster
+	other := NewSQLStorageDriver(l, repo.HelmRelease, 2, "default")
+
+	if _, err := other.Get("porter", 1); err == nil {
+		t.Errorf("expected release of another cluster to not be found\n")
+	}
+
+	// the deployed release can be found by querying labels
+	deployed, err := stg.Deployed("porter")
+
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if deployed.Version != 1 {
+		t.Errorf("incorrect deployed version: expected %d, got %d\n", 1, deployed.Version)
+	}
+
+	if _, err := stg.Delete("porter", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := stg.Get("porter", 1); err == nil {
+		t.Errorf("expected deleted release to not be found\n")
+	}
+}
+
+func TestSQLDriverNamespace(t *testing.T) {
+	repo := memory.NewRepository(true)
+
+	// a driver without a namespace reads releases from the namespace they are
+	// written to
+	d := NewSQLDriver(repo.HelmRelease, 1, "")
+	key := ReleaseKey("porter", 1)
+
+	if err := d.Create(key, newSQLTestRelease("")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := d.Get(key); err != nil {
+		t.Fatalf("release written without a namespace was not found: %v\n", err)
+	}
+
+	if _, err := d.Delete(key); err != nil {
+		t.Fatalf("release written without a namespace was not deleted: %v\n", err)
+	}
+
+	// a driver with a namespace writes releases to its own namespace
+	d = NewSQLDriver(repo.HelmRelease, 1, "porter")
+
+	if err := d.Create(key, newSQLTestRelease("other")); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := d.Get(key); err != nil {
+		t.Fatalf("release was not written to the namespace of the driver: %v\n", err)
+	}
+
+	if err := d.Update(key, newSQLTestRelease("other")); err != nil {
+		t.Fatalf("release was not updated in the namespace of the driver: %v\n", err)
+	}
+}
+
+// racingHelmReleaseRepository stores a release with the same key as the release
+// being created, as if another writer created it first
+type racingHelmReleaseRepository struct {
+	repository.HelmReleaseRepository
+}
+
+func (repo *racingHelmReleaseRepository) CreateHelmRelease(rel *models.HelmRelease) (*models.HelmRelease, error) {
+	other := *rel
+
+	if _, err := repo.HelmReleaseRepository.CreateHelmRelease(&other); err != nil {
+		return nil, err
+	}
+
+	return repo.HelmReleaseRepository.CreateHelmRelease(rel)
+}
+
+func TestSQLDriverCreateExists(t *testing.T) {
+	repo := &racingHelmReleaseRepository{memory.NewHelmReleaseRepository(true)}
+	d := NewSQLDriver(repo, 1, "default")
+
+	err := d.Create(ReleaseKey("porter", 1), newSQLTestRelease("default"))
+
+	if !errors.Is(err, driver.ErrReleaseExists) {
+		t.Errorf("expected %v, got %v\n", driver.ErrReleaseExists, err)
+	}
+}
@@ -9,12 +9,12 @@ package helm
 // - postgres
 //
 // This file implements first-class support for the first three driver types
-// and integrates with the logger.
-//
-// TODO -- include support for SQL storage...
+// and integrates with the logger. Instead of Helm's postgres driver, releases can
+// be stored in the Porter database using the "sql" driver (see sqldriver.go).
 
 import (
 	"github.com/porter-dev/porter/internal/logger"
+	"github.com/porter-dev/porter/internal/repository"
 
 	"helm.sh/helm/v3/pkg/storage"
 	"helm.sh/helm/v3/pkg/storage/driver"
@@ -67,3 +67,20 @@ func newMemoryStorageDriver(
 	d := driver.NewMemory()
 	return storage.Init(d)
 }
+
+// NewSQLStorageDriver returns a storage using the SQL driver, which stores the
+// releases of a cluster in the Porter database.
+func NewSQLStorageDriver(
+	l *logger.Logger,
+	repo repository.HelmReleaseRepository,
+	clusterID uint,
+	namespace string,
+) *storage.Storage {
+	d := NewSQLDriver(repo, clusterID, namespace)
+
+	if l != nil {
+		d.Log = l.Printf
+	}
+
+	return storage.Init(d)
+}
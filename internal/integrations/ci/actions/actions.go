@@ -40,6 +40,10 @@ type GithubActions struct {
 	ReleaseName      string
 	ReleaseNamespace string
 
+	// PreviewConfigID is the ID of the preview environment config, which the
+	// namespaces of preview workflows are derived from
+	PreviewConfigID uint
+
 	GitBranch      string
 	DockerFilePath string
 	FolderPath     string
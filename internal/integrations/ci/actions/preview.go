@@ -0,0 +1,168 @@
+package actions
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/google/go-github/v33/github"
+	"github.com/porter-dev/porter/internal/models"
+	"gopkg.in/yaml.v2"
+)
+
+type GithubActionYAMLOnPullRequestTypes struct {
+	Types    []string `yaml:"types,omitempty"`
+	Branches []string `yaml:"branches,omitempty"`
+}
+
+type GithubActionYAMLOnPullRequest struct {
+	PullRequest GithubActionYAMLOnPullRequestTypes `yaml:"pull_request,omitempty"`
+}
+
+type GithubPreviewActionYAML struct {
+	On GithubActionYAMLOnPullRequest `yaml:"on,omitempty"`
+
+	Name string `yaml:"name,omitempty"`
+
+	Jobs map[string]GithubActionYAMLJob `yaml:"jobs,omitempty"`
+}
+
+// SetupPreview commits a workflow to the base branch that builds the head of each
+// pull request against the branch, and updates the copy of the release in the
+// preview namespace of the pull request
+func (g *GithubActions) SetupPreview() ([]byte, error) {
+	client, err := g.getClient()
+
+	if err != nil {
+		return nil, err
+	}
+
+	// get the repository to find the default branch
+	repo, _, err := client.Repositories.Get(
+		context.TODO(),
+		g.GitRepoOwner,
+		g.GitRepoName,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	g.defaultBranch = repo.GetDefaultBranch()
+
+	if !g.ShouldGenerateOnly {
+		// create porter token secret
+		if err := g.createGithubSecret(client, g.getPorterTokenSecretName(), g.PorterToken); err != nil {
+			return nil, err
+		}
+	}
+
+	workflowYAML, err := g.GetGithubPreviewActionYAML()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if !g.ShouldGenerateOnly {
+		_, err = g.commitGithubFile(client, g.getPorterPreviewYMLFileName(), workflowYAML)
+
+		if err != nil {
+			return workflowYAML, err
+		}
+	}
+
+	return workflowYAML, nil
+}
+
+// CleanupPreview deletes the preview workflow from the base branch
+func (g *GithubActions) CleanupPreview() error {
+	client, err := g.getClient()
+
+	if err != nil {
+		return err
+	}
+
+	// get the repository to find the default branch
+	repo, _, err := client.Repositories.Get(
+		context.TODO(),
+		g.GitRepoOwner,
+		g.GitRepoName,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	g.defaultBranch = repo.GetDefaultBranch()
+
+	return g.deleteGithubFile(client, g.getPorterPreviewYMLFileName())
+}
+
+// CreateCommitStatus sets a commit status on a commit of the repository
+func (g *GithubActions) CreateCommitStatus(sha string, status *github.RepoStatus) error {
+	client, err := g.getClient()
+
+	if err != nil {
+		return err
+	}
+
+	_, _, err = client.Repositories.CreateStatus(
+		context.TODO(),
+		g.GitRepoOwner,
+		g.GitRepoName,
+		sha,
+		status,
+	)
+
+	return err
+}
+
+func (g *GithubActions) GetGithubPreviewActionYAML() ([]byte, error) {
+	// the namespace must match models.PreviewNamespace
+	namespace := fmt.Sprintf(
+		"pr-${{ github.event.pull_request.number }}-%s",
+		models.PreviewNamespaceSuffix(g.PreviewConfigID, g.ReleaseName),
+	)
+
+	gaSteps := []GithubActionYAMLStep{
+		getCheckoutPullRequestStep(),
+		getSetTagStep(),
+		getUpdateAppStep(g.ServerURL, g.getPorterTokenSecretName(), g.ProjectID, g.ClusterID, g.ReleaseName, namespace, g.Version),
+	}
+
+	branch := g.GitBranch
+
+	if branch == "" {
+		branch = g.defaultBranch
+	}
+
+	actionYAML := GithubPreviewActionYAML{
+		On: GithubActionYAMLOnPullRequest{
+			PullRequest: GithubActionYAMLOnPullRequestTypes{
+				Types: []string{
+					"opened",
+					"synchronize",
+					"reopened",
+				},
+				Branches: []string{
+					branch,
+				},
+			},
+		},
+		Name: "Deploy preview to Porter",
+		Jobs: map[string]GithubActionYAMLJob{
+			"porter-preview": {
+				RunsOn: "ubuntu-latest",
+				Steps:  gaSteps,
+			},
+		},
+	}
+
+	return yaml.Marshal(actionYAML)
+}
+
+func (g *GithubActions) getPorterPreviewYMLFileName() string {
+	return fmt.Sprintf("porter_preview_%s.yml", strings.Replace(
+		strings.ToLower(g.ReleaseName), "-", "_", -1),
+	)
+}
@@ -37,3 +37,13 @@ func getUpdateAppStep(serverURL, porterTokenSecretName string, projectID uint, c
 		Timeout: 20,
 	}
 }
+
+func getCheckoutPullRequestStep() GithubActionYAMLStep {
+	return GithubActionYAMLStep{
+		Name: "Checkout pull request",
+		Uses: "actions/checkout@v2.3.4",
+		With: map[string]string{
+			"ref": "${{ github.event.pull_request.head.sha }}",
+		},
+	}
+}
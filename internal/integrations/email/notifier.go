@@ -0,0 +1,85 @@
+package email
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/porter-dev/porter/internal/integrations/slack"
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// EmailNotifier sends deployment notifications by email
+type EmailNotifier struct {
+	transport  Transport
+	recipients []string
+	Config     *models.NotificationConfigExternal
+}
+
+// NewEmailNotifier returns a Notifier that emails each of the recipients through
+// the given transport
+func NewEmailNotifier(
+	conf *models.NotificationConfigExternal,
+	transport Transport,
+	recipients ...string,
+) *EmailNotifier {
+	return &EmailNotifier{
+		transport:  transport,
+		recipients: recipients,
+		Config:     conf,
+	}
+}
+
+// Notify emails the recipients about a deployment
+func (n *EmailNotifier) Notify(opts *slack.NotifyOpts) error {
+	if !slack.ShouldNotify(n.Config, opts, time.Now()) {
+		return nil
+	}
+
+	if n.transport == nil || len(n.recipients) == 0 {
+		return nil
+	}
+
+	return n.transport.Send(n.recipients, getSubject(opts), getBody(opts))
+}
+
+func getSubject(opts *slack.NotifyOpts) string {
+	switch opts.Status {
+	case slack.StatusFailed:
+		return fmt.Sprintf("[Porter] %s failed to deploy", opts.Name)
+	case slack.StatusAlert:
+		return fmt.Sprintf("[Porter] %s is unhealthy (%s)", opts.Name, opts.Reason)
+	default:
+		return fmt.Sprintf("[Porter] %s was successfully deployed", opts.Name)
+	}
+}
+
+func getBody(opts *slack.NotifyOpts) string {
+	var sb strings.Builder
+
+	switch opts.Status {
+	case slack.StatusFailed:
+		sb.WriteString(fmt.Sprintf("Your application %s failed to deploy on Porter.\n\n", opts.Name))
+	case slack.StatusAlert:
+		sb.WriteString(fmt.Sprintf("Your application %s is unhealthy on Porter (%s).\n\n", opts.Name, opts.Reason))
+	default:
+		sb.WriteString(fmt.Sprintf("Your application %s was successfully updated on Porter.\n\n", opts.Name))
+	}
+
+	sb.WriteString(fmt.Sprintf("Name: %s\n", opts.Name))
+	sb.WriteString(fmt.Sprintf("Namespace: %s\n", opts.Namespace))
+	sb.WriteString(fmt.Sprintf("Cluster: %s\n", opts.ClusterName))
+	if opts.Status != slack.StatusAlert {
+		sb.WriteString(fmt.Sprintf("Version: %d\n", opts.Version))
+	}
+
+	if (opts.Status == slack.StatusFailed || opts.Status == slack.StatusAlert) && opts.Info != "" {
+		sb.WriteString(fmt.Sprintf("\nError:\n%s\n", opts.Info))
+	}
+
+	if opts.URL != "" {
+		sb.WriteString(fmt.Sprintf("\nView the release: %s\n", opts.URL))
+	}
+
+	return sb.String()
+}
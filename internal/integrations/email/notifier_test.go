@@ -0,0 +1,186 @@
+package email_test
+
+import (
+	"bufio"
+	"net"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/integrations/email"
+	"github.com/porter-dev/porter/internal/integrations/slack"
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// smtpStandIn is a minimal SMTP server that records the messages it receives
+type smtpStandIn struct {
+	listener net.Listener
+
+	mu         sync.Mutex
+	recipients []string
+	messages   []string
+}
+
+func newSMTPStandIn(t *testing.T) *smtpStandIn {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	s := &smtpStandIn{listener: listener}
+
+	go func() {
+		for {
+			conn, err := listener.Accept()
+
+			if err != nil {
+				return
+			}
+
+			go s.handle(conn)
+		}
+	}()
+
+	return s
+}
+
+func (s *smtpStandIn) port() int {
+	return s.listener.Addr().(*net.TCPAddr).Port
+}
+
+func (s *smtpStandIn) handle(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	write := func(line string) {
+		conn.Write([]byte(line + "\r\n"))
+	}
+
+	write("220 localhost ESMTP")
+
+	for {
+		line, err := reader.ReadString('\n')
+
+		if err != nil {
+			return
+		}
+
+		cmd := strings.ToUpper(strings.TrimSpace(line))
+
+		switch {
+		case strings.HasPrefix(cmd, "EHLO"), strings.HasPrefix(cmd, "HELO"):
+			write("250 localhost")
+		case strings.HasPrefix(cmd, "RCPT TO:"):
+			s.mu.Lock()
+			s.recipients = append(s.recipients, strings.Trim(strings.TrimSpace(line)[8:], "<>"))
+			s.mu.Unlock()
+			write("250 OK")
+		case cmd == "DATA":
+			write("354 End data with <CR><LF>.<CR><LF>")
+
+			var sb strings.Builder
+
+			for {
+				dataLine, err := reader.ReadString('\n')
+
+				if err != nil {
+					return
+				}
+
+				if dataLine == ".\r\n" {
+					break
+				}
+
+				sb.WriteString(dataLine)
+			}
+
+			s.mu.Lock()
+			s.messages = append(s.messages, sb.String())
+			s.mu.Unlock()
+			write("250 OK")
+		case cmd == "QUIT":
+			write("221 Bye")
+			return
+		default:
+			write("250 OK")
+		}
+	}
+}
+
+func TestSMTPNotifier(t *testing.T) {
+	server := newSMTPStandIn(t)
+	defer server.listener.Close()
+
+	transport := &email.SMTPTransport{
+		Host:        "127.0.0.1",
+		Port:        server.port(),
+		SenderEmail: "porter@example.com",
+	}
+
+	notifier := email.NewEmailNotifier(nil, transport, "a@example.com", "b@example.com")
+
+	err := notifier.Notify(&slack.NotifyOpts{
+		Name:      "web",
+		Namespace: "default",
+		Status:    slack.StatusFailed,
+		Info:      "image pull failed",
+		Version:   3,
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	server.mu.Lock()
+	defer server.mu.Unlock()
+
+	if strings.Join(server.recipients, ",") != "a@example.com,b@example.com" {
+		t.Errorf("incorrect recipients: got %v", server.recipients)
+	}
+
+	if len(server.messages) != 2 {
+		t.Fatalf("expected 2 messages, got %d", len(server.messages))
+	}
+
+	msg := server.messages[0]
+
+	if !strings.Contains(msg, "Subject: [Porter] web failed to deploy") {
+		t.Errorf("message has incorrect subject: %s", msg)
+	}
+
+	if !strings.Contains(msg, "image pull failed") || !strings.Contains(msg, "Version: 3") {
+		t.Errorf("message has incorrect body: %s", msg)
+	}
+}
+
+type recordingTransport struct {
+	sent int
+}
+
+func (r *recordingTransport) Send(to []string, subject, body string) error {
+	r.sent++
+	return nil
+}
+
+func TestEmailNotifierHonorsConfig(t *testing.T) {
+	transport := &recordingTransport{}
+
+	notifier := email.NewEmailNotifier(&models.NotificationConfigExternal{
+		Enabled: true,
+		Success: true,
+		Failure: false,
+	}, transport, "a@example.com")
+
+	notifier.Notify(&slack.NotifyOpts{Status: slack.StatusFailed})
+
+	if transport.sent != 0 {
+		t.Errorf("email sent for failure notification when failure is disabled")
+	}
+
+	notifier.Notify(&slack.NotifyOpts{Status: slack.StatusDeployed})
+
+	if transport.sent != 1 {
+		t.Errorf("email not sent for success notification")
+	}
+}
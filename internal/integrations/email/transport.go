@@ -0,0 +1,108 @@
+package email
+
+import (
+	"fmt"
+	"net/smtp"
+	"strings"
+
+	"github.com/sendgrid/sendgrid-go"
+	"github.com/sendgrid/sendgrid-go/helpers/mail"
+)
+
+// Transport sends a plaintext email to a list of recipients
+type Transport interface {
+	Send(to []string, subject, body string) error
+}
+
+// SendgridTransport sends emails through the SendGrid API
+type SendgridTransport struct {
+	APIKey      string
+	SenderEmail string
+}
+
+// Send sends an email to each recipient through SendGrid
+func (t *SendgridTransport) Send(to []string, subject, body string) error {
+	request := sendgrid.GetRequest(t.APIKey, "/v3/mail/send", "https://api.sendgrid.com")
+	request.Method = "POST"
+
+	personalizations := make([]*mail.Personalization, 0)
+
+	// each recipient gets a separate personalization so that collaborators do not
+	// see each other's email addresses
+	for _, addr := range to {
+		p := mail.NewPersonalization()
+		p.AddTos(mail.NewEmail("", addr))
+		personalizations = append(personalizations, p)
+	}
+
+	sgMail := &mail.SGMailV3{
+		Personalizations: personalizations,
+		From: &mail.Email{
+			Address: t.SenderEmail,
+			Name:    "Porter",
+		},
+		Subject: subject,
+		Content: []*mail.Content{
+			mail.NewContent("text/plain", body),
+		},
+	}
+
+	request.Body = mail.GetRequestBody(sgMail)
+
+	resp, err := sendgrid.API(request)
+
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode >= 400 {
+		return fmt.Errorf("sendgrid returned status %d: %s", resp.StatusCode, resp.Body)
+	}
+
+	return nil
+}
+
+// SMTPTransport sends emails through an SMTP server, for installations that do not
+// use SendGrid
+type SMTPTransport struct {
+	Host        string
+	Port        int
+	Username    string
+	Password    string
+	SenderEmail string
+}
+
+// Send sends an email to each recipient through the SMTP server
+func (t *SMTPTransport) Send(to []string, subject, body string) error {
+	addr := fmt.Sprintf("%s:%d", t.Host, t.Port)
+
+	var auth smtp.Auth
+
+	if t.Username != "" {
+		auth = smtp.PlainAuth("", t.Username, t.Password, t.Host)
+	}
+
+	for _, recipient := range to {
+		msg := buildMessage(t.SenderEmail, recipient, subject, body)
+
+		if err := smtp.SendMail(addr, auth, t.SenderEmail, []string{recipient}, msg); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func buildMessage(from, to, subject, body string) []byte {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("From: Porter <%s>\r\n", from))
+	sb.WriteString(fmt.Sprintf("To: %s\r\n", to))
+	sb.WriteString(fmt.Sprintf("Subject: %s\r\n", subject))
+	sb.WriteString("MIME-Version: 1.0\r\n")
+	sb.WriteString("Content-Type: text/plain; charset=\"utf-8\"\r\n")
+	sb.WriteString("\r\n")
+	sb.WriteString(strings.ReplaceAll(body, "\n", "\r\n"))
+
+	return []byte(sb.String())
+}
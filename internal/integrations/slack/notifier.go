@@ -21,6 +21,10 @@ type DeploymentStatus string
 const (
 	StatusDeployed string = "deployed"
 	StatusFailed   string = "failed"
+
+	// StatusAlert is used for failures of a running release that are detected in
+	// the cluster, outside of a deployment
+	StatusAlert string = "alert"
 )
 
 type NotifyOpts struct {
@@ -49,6 +53,17 @@ type NotifyOpts struct {
 	URL string
 
 	Version int
+
+	// Trigger is what started the deployment, either models.NotificationTriggerManual
+	// or models.NotificationTriggerWebhook
+	Trigger string
+
+	// ConsecutiveFailures is the number of deploys of this release that have failed
+	// in a row, including this one
+	ConsecutiveFailures uint
+
+	// Reason is the kind of failure for an alert, such as CrashLoopBackOff
+	Reason string
 }
 
 type SlackNotifier struct {
@@ -78,16 +93,8 @@ type SlackText struct {
 }
 
 func (s *SlackNotifier) Notify(opts *NotifyOpts) error {
-	if s.Config != nil {
-		if !s.Config.Enabled {
-			return nil
-		}
-		if opts.Status == StatusDeployed && !s.Config.Success {
-			return nil
-		}
-		if opts.Status == StatusFailed && !s.Config.Failure {
-			return nil
-		}
+	if !ShouldNotify(s.Config, opts, time.Now()) {
+		return nil
 	}
 
 	blocks := []*SlackBlock{
@@ -126,23 +133,41 @@ func (s *SlackNotifier) Notify(opts *NotifyOpts) error {
 		return err
 	}
 
-	basicReqBody := bytes.NewReader(basicPayload)
-	reqBody := bytes.NewReader(payload)
 	client := &http.Client{
 		Timeout: time.Second * 5,
 	}
 
 	for _, slackInt := range s.slackInts {
-		resp, err := client.Post(string(slackInt.Webhook), "application/json", reqBody)
+		if !isSlackIntegrationSelected(s.Config, slackInt.ID) {
+			continue
+		}
 
-		if err != nil || resp.StatusCode != 200 {
-			client.Post(string(slackInt.Webhook), "application/json", basicReqBody)
+		if err := postSlackPayload(client, string(slackInt.Webhook), payload); err != nil {
+			postSlackPayload(client, string(slackInt.Webhook), basicPayload)
 		}
 	}
 
 	return nil
 }
 
+// postSlackPayload posts a payload to a Slack webhook. The request body is created
+// for each call, since a reader cannot be sent more than once.
+func postSlackPayload(client *http.Client, webhook string, payload []byte) error {
+	resp, err := client.Post(webhook, "application/json", bytes.NewReader(payload))
+
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slack webhook returned status %d", resp.StatusCode)
+	}
+
+	return nil
+}
+
 func getDividerBlock() *SlackBlock {
 	return &SlackBlock{
 		Type: "divider",
@@ -167,6 +192,8 @@ func getMessageBlock(opts *NotifyOpts) *SlackBlock {
 		md = getSuccessMessage(opts)
 	case StatusFailed:
 		md = getFailedMessage(opts)
+	case StatusAlert:
+		md = getAlertMessage(opts)
 	}
 
 	return getMarkdownBlock(md)
@@ -176,7 +203,7 @@ func getInfoBlock(opts *NotifyOpts) *SlackBlock {
 	var md string
 
 	switch opts.Status {
-	case StatusFailed:
+	case StatusFailed, StatusAlert:
 		md = getFailedInfoMessage(opts)
 	default:
 		return nil
@@ -201,6 +228,15 @@ func getFailedMessage(opts *NotifyOpts) string {
 	)
 }
 
+func getAlertMessage(opts *NotifyOpts) string {
+	return fmt.Sprintf(
+		":warning: Your application %s is unhealthy on Porter (%s). <%s|View the status here.>",
+		"`"+opts.Name+"`",
+		opts.Reason,
+		opts.URL,
+	)
+}
+
 func getFailedInfoMessage(opts *NotifyOpts) string {
 	info := opts.Info
 
@@ -224,3 +260,137 @@ func getFailedInfoMessage(opts *NotifyOpts) string {
 
 	return fmt.Sprintf("```\n%s\n```", info)
 }
+
+type multiNotifier struct {
+	notifiers []Notifier
+}
+
+// NewMultiNotifier returns a Notifier that sends a notification through each of the
+// given notifiers, and returns the last error encountered
+func NewMultiNotifier(notifiers ...Notifier) Notifier {
+	return &multiNotifier{notifiers}
+}
+
+func (m *multiNotifier) Notify(opts *NotifyOpts) error {
+	var lastErr error
+
+	for _, notifier := range m.notifiers {
+		if err := notifier.Notify(opts); err != nil {
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
+
+// ShouldNotify evaluates the rules of a notification config against a deployment.
+// A nil config notifies for every deployment.
+func ShouldNotify(conf *models.NotificationConfigExternal, opts *NotifyOpts, now time.Time) bool {
+	if conf == nil {
+		return true
+	}
+
+	if !conf.Enabled {
+		return false
+	}
+
+	if opts.Status == StatusDeployed && !conf.Success {
+		return false
+	}
+
+	if opts.Status == StatusAlert && !conf.Failure {
+		return false
+	}
+
+	if opts.Status == StatusFailed {
+		if !conf.Failure {
+			return false
+		}
+
+		if conf.FailureThreshold > 1 && opts.ConsecutiveFailures < conf.FailureThreshold {
+			return false
+		}
+	}
+
+	if len(conf.Namespaces) > 0 && !containsString(conf.Namespaces, opts.Namespace) {
+		return false
+	}
+
+	// alerts are not started by a deploy, so trigger rules do not apply to them
+	if opts.Status != StatusAlert && len(conf.Triggers) > 0 && !containsString(conf.Triggers, opts.Trigger) {
+		return false
+	}
+
+	if conf.QuietHours != nil && isQuietHours(conf.QuietHours, now) {
+		return false
+	}
+
+	return true
+}
+
+// ParseQuietHours parses the start and end of the quiet hours as minutes since
+// midnight, and loads the timezone they are evaluated in
+func ParseQuietHours(quietHours *models.QuietHours) (int, int, *time.Location, error) {
+	start, err := time.Parse("15:04", quietHours.Start)
+
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("invalid quiet hours start: %s", quietHours.Start)
+	}
+
+	end, err := time.Parse("15:04", quietHours.End)
+
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("invalid quiet hours end: %s", quietHours.End)
+	}
+
+	loc, err := time.LoadLocation(quietHours.Timezone)
+
+	if err != nil {
+		return 0, 0, nil, fmt.Errorf("invalid quiet hours timezone: %s", quietHours.Timezone)
+	}
+
+	return start.Hour()*60 + start.Minute(), end.Hour()*60 + end.Minute(), loc, nil
+}
+
+// isQuietHours returns true if now falls in the quiet hours. Windows where the end
+// is before the start wrap around midnight.
+func isQuietHours(quietHours *models.QuietHours, now time.Time) bool {
+	start, end, loc, err := ParseQuietHours(quietHours)
+
+	if err != nil || start == end {
+		return false
+	}
+
+	local := now.In(loc)
+	curr := local.Hour()*60 + local.Minute()
+
+	if start < end {
+		return curr >= start && curr < end
+	}
+
+	return curr >= start || curr < end
+}
+
+func isSlackIntegrationSelected(conf *models.NotificationConfigExternal, id uint) bool {
+	if conf == nil || len(conf.SlackIntegrationIDs) == 0 {
+		return true
+	}
+
+	for _, selectedID := range conf.SlackIntegrationIDs {
+		if selectedID == id {
+			return true
+		}
+	}
+
+	return false
+}
+
+func containsString(list []string, val string) bool {
+	for _, item := range list {
+		if item == val {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,233 @@
+package slack_test
+
+import (
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/porter-dev/porter/internal/integrations/slack"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/models/integrations"
+)
+
+type shouldNotifyTest struct {
+	msg  string
+	conf *models.NotificationConfigExternal
+	opts *slack.NotifyOpts
+	now  time.Time
+	exp  bool
+}
+
+var noon = time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+var midnight = time.Date(2021, 6, 1, 0, 30, 0, 0, time.UTC)
+
+func baseConf() *models.NotificationConfigExternal {
+	return &models.NotificationConfigExternal{
+		Enabled: true,
+		Success: true,
+		Failure: true,
+	}
+}
+
+func withConf(fn func(conf *models.NotificationConfigExternal)) *models.NotificationConfigExternal {
+	conf := baseConf()
+	fn(conf)
+	return conf
+}
+
+var shouldNotifyTests = []shouldNotifyTest{
+	{
+		msg:  "nil config notifies",
+		opts: &slack.NotifyOpts{Status: slack.StatusDeployed},
+		now:  noon,
+		exp:  true,
+	},
+	{
+		msg:  "disabled config does not notify",
+		conf: withConf(func(c *models.NotificationConfigExternal) { c.Enabled = false }),
+		opts: &slack.NotifyOpts{Status: slack.StatusDeployed},
+		now:  noon,
+		exp:  false,
+	},
+	{
+		msg:  "success disabled",
+		conf: withConf(func(c *models.NotificationConfigExternal) { c.Success = false }),
+		opts: &slack.NotifyOpts{Status: slack.StatusDeployed},
+		now:  noon,
+		exp:  false,
+	},
+	{
+		msg:  "namespace not selected",
+		conf: withConf(func(c *models.NotificationConfigExternal) { c.Namespaces = []string{"production"} }),
+		opts: &slack.NotifyOpts{Status: slack.StatusDeployed, Namespace: "staging"},
+		now:  noon,
+		exp:  false,
+	},
+	{
+		msg:  "namespace selected",
+		conf: withConf(func(c *models.NotificationConfigExternal) { c.Namespaces = []string{"production"} }),
+		opts: &slack.NotifyOpts{Status: slack.StatusDeployed, Namespace: "production"},
+		now:  noon,
+		exp:  true,
+	},
+	{
+		msg:  "failure below threshold",
+		conf: withConf(func(c *models.NotificationConfigExternal) { c.FailureThreshold = 3 }),
+		opts: &slack.NotifyOpts{Status: slack.StatusFailed, ConsecutiveFailures: 2},
+		now:  noon,
+		exp:  false,
+	},
+	{
+		msg:  "failure at threshold",
+		conf: withConf(func(c *models.NotificationConfigExternal) { c.FailureThreshold = 3 }),
+		opts: &slack.NotifyOpts{Status: slack.StatusFailed, ConsecutiveFailures: 3},
+		now:  noon,
+		exp:  true,
+	},
+	{
+		msg:  "threshold does not apply to success",
+		conf: withConf(func(c *models.NotificationConfigExternal) { c.FailureThreshold = 3 }),
+		opts: &slack.NotifyOpts{Status: slack.StatusDeployed},
+		now:  noon,
+		exp:  true,
+	},
+	{
+		msg:  "trigger not selected",
+		conf: withConf(func(c *models.NotificationConfigExternal) { c.Triggers = []string{models.NotificationTriggerWebhook} }),
+		opts: &slack.NotifyOpts{Status: slack.StatusDeployed, Trigger: models.NotificationTriggerManual},
+		now:  noon,
+		exp:  false,
+	},
+	{
+		msg:  "trigger selected",
+		conf: withConf(func(c *models.NotificationConfigExternal) { c.Triggers = []string{models.NotificationTriggerWebhook} }),
+		opts: &slack.NotifyOpts{Status: slack.StatusDeployed, Trigger: models.NotificationTriggerWebhook},
+		now:  noon,
+		exp:  true,
+	},
+	{
+		msg: "inside quiet hours that wrap midnight",
+		conf: withConf(func(c *models.NotificationConfigExternal) {
+			c.QuietHours = &models.QuietHours{Start: "22:00", End: "07:00", Timezone: "UTC"}
+		}),
+		opts: &slack.NotifyOpts{Status: slack.StatusDeployed},
+		now:  midnight,
+		exp:  false,
+	},
+	{
+		msg: "outside quiet hours that wrap midnight",
+		conf: withConf(func(c *models.NotificationConfigExternal) {
+			c.QuietHours = &models.QuietHours{Start: "22:00", End: "07:00", Timezone: "UTC"}
+		}),
+		opts: &slack.NotifyOpts{Status: slack.StatusDeployed},
+		now:  noon,
+		exp:  true,
+	},
+	{
+		msg: "quiet hours evaluated in timezone",
+		conf: withConf(func(c *models.NotificationConfigExternal) {
+			// noon UTC is 08:00 in New York during daylight saving time
+			c.QuietHours = &models.QuietHours{Start: "07:00", End: "09:00", Timezone: "America/New_York"}
+		}),
+		opts: &slack.NotifyOpts{Status: slack.StatusDeployed},
+		now:  noon,
+		exp:  false,
+	},
+}
+
+func TestShouldNotify(t *testing.T) {
+	for _, test := range shouldNotifyTests {
+		if got := slack.ShouldNotify(test.conf, test.opts, test.now); got != test.exp {
+			t.Errorf("%s: expected %t, got %t", test.msg, test.exp, got)
+		}
+	}
+}
+
+func TestParseQuietHours(t *testing.T) {
+	_, _, _, err := slack.ParseQuietHours(&models.QuietHours{Start: "25:00", End: "07:00", Timezone: "UTC"})
+
+	if err == nil {
+		t.Errorf("expected error for invalid start")
+	}
+
+	_, _, _, err = slack.ParseQuietHours(&models.QuietHours{Start: "22:00", End: "07:00", Timezone: "Not/AZone"})
+
+	if err == nil {
+		t.Errorf("expected error for invalid timezone")
+	}
+
+	start, end, _, err := slack.ParseQuietHours(&models.QuietHours{Start: "22:00", End: "07:30", Timezone: "UTC"})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if start != 22*60 || end != 7*60+30 {
+		t.Errorf("incorrect quiet hours: got %d-%d", start, end)
+	}
+}
+
+func TestSlackNotifierRouting(t *testing.T) {
+	called := make(map[string]bool)
+
+	newServer := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			called[name] = true
+		}))
+	}
+
+	serverA := newServer("a")
+	defer serverA.Close()
+
+	serverB := newServer("b")
+	defer serverB.Close()
+
+	slackIntA := &integrations.SlackIntegration{Webhook: []byte(serverA.URL)}
+	slackIntA.ID = 1
+
+	slackIntB := &integrations.SlackIntegration{Webhook: []byte(serverB.URL)}
+	slackIntB.ID = 2
+
+	conf := withConf(func(c *models.NotificationConfigExternal) { c.SlackIntegrationIDs = []uint{2} })
+
+	notifier := slack.NewSlackNotifier(conf, slackIntA, slackIntB)
+	notifier.Notify(&slack.NotifyOpts{Status: slack.StatusDeployed})
+
+	if called["a"] || !called["b"] {
+		t.Errorf("expected only the selected slack integration to be notified, got %v", called)
+	}
+}
+
+func TestSlackNotifierMultipleIntegrations(t *testing.T) {
+	bodies := make(map[string]int)
+
+	newServer := func(name string) *httptest.Server {
+		return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			body, _ := ioutil.ReadAll(r.Body)
+			bodies[name] = len(body)
+		}))
+	}
+
+	serverA := newServer("a")
+	defer serverA.Close()
+
+	serverB := newServer("b")
+	defer serverB.Close()
+
+	slackIntA := &integrations.SlackIntegration{Webhook: []byte(serverA.URL)}
+	slackIntA.ID = 1
+
+	slackIntB := &integrations.SlackIntegration{Webhook: []byte(serverB.URL)}
+	slackIntB.ID = 2
+
+	conf := withConf(func(c *models.NotificationConfigExternal) { c.SlackIntegrationIDs = []uint{1, 2} })
+
+	notifier := slack.NewSlackNotifier(conf, slackIntA, slackIntB)
+	notifier.Notify(&slack.NotifyOpts{Status: slack.StatusDeployed, Name: "web"})
+
+	if bodies["a"] == 0 || bodies["b"] != bodies["a"] {
+		t.Errorf("expected every selected slack integration to receive the payload, got body lengths %v", bodies)
+	}
+}
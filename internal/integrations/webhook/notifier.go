@@ -0,0 +1,333 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/porter-dev/porter/internal/integrations/slack"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/models/integrations"
+)
+
+const (
+	// EventHeader is the header that contains the type of event being delivered
+	EventHeader = "X-Porter-Event"
+
+	// TimestampHeader is the header that contains the unix timestamp at which the
+	// payload was signed
+	TimestampHeader = "X-Porter-Timestamp"
+
+	// SignatureHeader is the header that contains the HMAC-SHA256 signature of the
+	// payload, in the form sha256=<hex digest>
+	SignatureHeader = "X-Porter-Signature"
+
+	// DeploymentEvent is the event type for deployment notifications
+	DeploymentEvent = "deployment"
+
+	// AlertEvent is the event type for failures detected in a running release
+	AlertEvent = "alert"
+)
+
+// ErrBlockedAddress is returned when a webhook resolves to a loopback, link-local,
+// private or otherwise internal address, which Porter does not deliver to
+var ErrBlockedAddress = errors.New("webhook address is not publicly routable")
+
+// blockedNetworks are the networks that webhooks are not delivered to, in addition
+// to loopback, link-local, multicast and unspecified addresses
+var blockedNetworks = mustParseCIDRs(
+	"10.0.0.0/8",
+	"172.16.0.0/12",
+	"192.168.0.0/16",
+	"100.64.0.0/10",
+	"198.18.0.0/15",
+	"fc00::/7",
+)
+
+// WebhookNotifier posts deployment notifications to generic outbound webhooks
+type WebhookNotifier struct {
+	webhookInts []*integrations.WebhookIntegration
+	Config      *models.NotificationConfigExternal
+
+	// MaxAttempts is the number of times a delivery is attempted before giving up
+	MaxAttempts int
+
+	// Backoff is the delay before the first retry, which doubles on each retry
+	Backoff time.Duration
+
+	// Queue delivers notifications in the background if set, in which case Notify
+	// returns once the deliveries are queued. If nil, Notify waits for each delivery
+	// and its retries.
+	Queue *Queue
+
+	// AllowPrivateAddresses allows deliveries to webhooks that resolve to internal
+	// addresses, which are otherwise rejected with ErrBlockedAddress
+	AllowPrivateAddresses bool
+
+	client *http.Client
+}
+
+// NewWebhookNotifier returns a Notifier that delivers to each of the given webhooks
+func NewWebhookNotifier(
+	conf *models.NotificationConfigExternal,
+	webhookInts ...*integrations.WebhookIntegration,
+) *WebhookNotifier {
+	n := &WebhookNotifier{
+		webhookInts: webhookInts,
+		Config:      conf,
+		MaxAttempts: 3,
+		Backoff:     time.Second,
+	}
+
+	// the address is checked when connecting rather than when the webhook is
+	// created, so that a hostname cannot be pointed at an internal address later.
+	// Requests are not sent through a proxy, since the proxy address would be
+	// checked instead.
+	dialer := &net.Dialer{
+		Timeout: time.Second * 5,
+		Control: func(network, address string, _ syscall.RawConn) error {
+			if n.AllowPrivateAddresses {
+				return nil
+			}
+
+			return checkAddress(address)
+		},
+	}
+
+	n.client = &http.Client{
+		Timeout: time.Second * 5,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: time.Second * 5,
+		},
+	}
+
+	return n
+}
+
+// checkAddress returns ErrBlockedAddress if the resolved address of a connection
+// is not publicly routable
+func checkAddress(address string) error {
+	host, _, err := net.SplitHostPort(address)
+
+	if err != nil {
+		return err
+	}
+
+	ip := net.ParseIP(host)
+
+	if ip == nil {
+		return ErrBlockedAddress
+	}
+
+	if ip.IsLoopback() || ip.IsLinkLocalUnicast() || ip.IsLinkLocalMulticast() ||
+		ip.IsInterfaceLocalMulticast() || ip.IsMulticast() || ip.IsUnspecified() {
+		return ErrBlockedAddress
+	}
+
+	for _, network := range blockedNetworks {
+		if network.Contains(ip) {
+			return ErrBlockedAddress
+		}
+	}
+
+	return nil
+}
+
+func mustParseCIDRs(cidrs ...string) []*net.IPNet {
+	res := make([]*net.IPNet, 0, len(cidrs))
+
+	for _, cidr := range cidrs {
+		_, network, err := net.ParseCIDR(cidr)
+
+		if err != nil {
+			panic(err)
+		}
+
+		res = append(res, network)
+	}
+
+	return res
+}
+
+// WebhookPayload is the JSON body that is posted to a webhook
+type WebhookPayload struct {
+	Event       string `json:"event"`
+	Timestamp   int64  `json:"timestamp"`
+	ProjectID   uint   `json:"project_id"`
+	ClusterID   uint   `json:"cluster_id"`
+	ClusterName string `json:"cluster_name"`
+	Name        string `json:"name"`
+	Namespace   string `json:"namespace"`
+	Status      string `json:"status"`
+	Info        string `json:"info,omitempty"`
+	Reason      string `json:"reason,omitempty"`
+	URL         string `json:"url"`
+	Version     int    `json:"version"`
+}
+
+// Notify posts a signed payload to every webhook. Deliveries are retried on network
+// errors, 5xx responses and 429 responses, and the last delivery error is returned.
+// If the notifier has a queue, the deliveries are queued instead, and only errors
+// from queueing them are returned.
+func (n *WebhookNotifier) Notify(opts *slack.NotifyOpts) error {
+	if !slack.ShouldNotify(n.Config, opts, time.Now()) {
+		return nil
+	}
+
+	timestamp := time.Now().Unix()
+
+	event := DeploymentEvent
+
+	if opts.Status == slack.StatusAlert {
+		event = AlertEvent
+	}
+
+	payload, err := json.Marshal(&WebhookPayload{
+		Event:       event,
+		Timestamp:   timestamp,
+		ProjectID:   opts.ProjectID,
+		ClusterID:   opts.ClusterID,
+		ClusterName: opts.ClusterName,
+		Name:        opts.Name,
+		Namespace:   opts.Namespace,
+		Status:      opts.Status,
+		Info:        opts.Info,
+		Reason:      opts.Reason,
+		URL:         opts.URL,
+		Version:     opts.Version,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	var lastErr error
+
+	for _, webhookInt := range n.webhookInts {
+		d := &delivery{
+			notifier:   n,
+			webhookInt: webhookInt,
+			event:      event,
+			timestamp:  timestamp,
+			payload:    payload,
+		}
+
+		if n.Queue != nil {
+			if err := n.Queue.enqueue(d); err != nil {
+				lastErr = err
+			}
+
+			continue
+		}
+
+		if err := d.deliver(context.Background()); err != nil {
+			lastErr = err
+		}
+	}
+
+	return lastErr
+}
+
+// delivery is a single payload that is posted to a single webhook
+type delivery struct {
+	notifier   *WebhookNotifier
+	webhookInt *integrations.WebhookIntegration
+	event      string
+	timestamp  int64
+	payload    []byte
+}
+
+// deliver posts the payload, and retries with an exponential backoff until the
+// delivery succeeds, the maximum number of attempts is reached or the context is
+// cancelled
+func (d *delivery) deliver(ctx context.Context) error {
+	var err error
+
+	backoff := d.notifier.Backoff
+
+	for attempt := 0; attempt < d.notifier.MaxAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+
+			backoff *= 2
+		}
+
+		var retry bool
+
+		retry, err = d.notifier.post(ctx, d.webhookInt, d.event, d.timestamp, d.payload)
+
+		if !retry {
+			return err
+		}
+	}
+
+	return err
+}
+
+// post sends a single delivery, and returns whether the delivery should be retried
+func (n *WebhookNotifier) post(
+	ctx context.Context,
+	webhookInt *integrations.WebhookIntegration,
+	event string,
+	timestamp int64,
+	payload []byte,
+) (bool, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", string(webhookInt.URL), bytes.NewReader(payload))
+
+	if err != nil {
+		return false, err
+	}
+
+	ts := strconv.FormatInt(timestamp, 10)
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, event)
+	req.Header.Set(TimestampHeader, ts)
+	req.Header.Set(SignatureHeader, "sha256="+Sign(webhookInt.Secret, ts, payload))
+
+	resp, err := n.client.Do(req)
+
+	if err != nil {
+		// requests that were cancelled or blocked are not retried
+		return ctx.Err() == nil && !errors.Is(err, ErrBlockedAddress), err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 500 || resp.StatusCode == http.StatusTooManyRequests {
+		return true, fmt.Errorf("webhook %d returned status %d", webhookInt.ID, resp.StatusCode)
+	}
+
+	if resp.StatusCode >= 400 {
+		return false, fmt.Errorf("webhook %d returned status %d", webhookInt.ID, resp.StatusCode)
+	}
+
+	return false, nil
+}
+
+// Sign computes the hex-encoded HMAC-SHA256 of "<timestamp>.<payload>" with the
+// given secret. Receivers should recompute the signature from the X-Porter-Timestamp
+// header and the raw body, and reject stale timestamps.
+func Sign(secret []byte, timestamp string, payload []byte) string {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(payload)
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
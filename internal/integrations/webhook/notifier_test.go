@@ -0,0 +1,294 @@
+package webhook_test
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/porter-dev/porter/internal/integrations/slack"
+	"github.com/porter-dev/porter/internal/integrations/webhook"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/models/integrations"
+)
+
+func TestNotifySignsPayload(t *testing.T) {
+	var gotBody []byte
+	var gotHeaders http.Header
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotBody, _ = ioutil.ReadAll(r.Body)
+		gotHeaders = r.Header
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	defer server.Close()
+
+	webhookInt := &integrations.WebhookIntegration{
+		URL:    []byte(server.URL),
+		Secret: []byte("secret"),
+	}
+
+	notifier := webhook.NewWebhookNotifier(nil, webhookInt)
+
+	notifier.AllowPrivateAddresses = true
+
+	err := notifier.Notify(&slack.NotifyOpts{
+		ProjectID: 1,
+		Name:      "web",
+		Namespace: "default",
+		Status:    slack.StatusDeployed,
+		Version:   2,
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if gotHeaders.Get(webhook.EventHeader) != webhook.DeploymentEvent {
+		t.Errorf("incorrect event header: %s", gotHeaders.Get(webhook.EventHeader))
+	}
+
+	ts := gotHeaders.Get(webhook.TimestampHeader)
+	expSig := "sha256=" + webhook.Sign([]byte("secret"), ts, gotBody)
+
+	if sig := gotHeaders.Get(webhook.SignatureHeader); sig != expSig {
+		t.Errorf("incorrect signature: expected %s, got %s", expSig, sig)
+	}
+
+	payload := &webhook.WebhookPayload{}
+
+	if err := json.Unmarshal(gotBody, payload); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if payload.Name != "web" || payload.Namespace != "default" || payload.Version != 2 {
+		t.Errorf("incorrect payload: %v", payload)
+	}
+}
+
+func TestNotifyRetries(t *testing.T) {
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+
+		if attempts < 3 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	defer server.Close()
+
+	notifier := webhook.NewWebhookNotifier(nil, &integrations.WebhookIntegration{
+		URL: []byte(server.URL),
+	})
+
+	notifier.AllowPrivateAddresses = true
+	notifier.Backoff = time.Millisecond
+
+	if err := notifier.Notify(&slack.NotifyOpts{Status: slack.StatusDeployed}); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if attempts != 3 {
+		t.Errorf("expected 3 attempts, got %d", attempts)
+	}
+
+}
+
+func TestNotifyBlocksInternalAddresses(t *testing.T) {
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusOK)
+	}))
+
+	defer server.Close()
+
+	// the test server listens on a loopback address
+	notifier := webhook.NewWebhookNotifier(nil, &integrations.WebhookIntegration{
+		URL: []byte(server.URL),
+	})
+
+	notifier.Backoff = time.Millisecond
+
+	if err := notifier.Notify(&slack.NotifyOpts{Status: slack.StatusDeployed}); !errors.Is(err, webhook.ErrBlockedAddress) {
+		t.Errorf("expected %v, got %v", webhook.ErrBlockedAddress, err)
+	}
+
+	if attempts != 0 {
+		t.Errorf("expected no deliveries to a loopback address, got %d", attempts)
+	}
+}
+
+func TestNotifyDoesNotRetryClientErrors(t *testing.T) {
+	attempts := 0
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(http.StatusBadRequest)
+	}))
+
+	defer server.Close()
+
+	notifier := webhook.NewWebhookNotifier(nil, &integrations.WebhookIntegration{
+		URL: []byte(server.URL),
+	})
+
+	notifier.AllowPrivateAddresses = true
+	notifier.Backoff = time.Millisecond
+
+	if err := notifier.Notify(&slack.NotifyOpts{Status: slack.StatusDeployed}); err == nil {
+		t.Errorf("expected error for bad request response")
+	}
+
+	if attempts != 1 {
+		t.Errorf("expected a single attempt, got %d", attempts)
+	}
+}
+
+func TestNotifyHonorsConfig(t *testing.T) {
+	called := false
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+	}))
+
+	defer server.Close()
+
+	notifier := webhook.NewWebhookNotifier(&models.NotificationConfigExternal{
+		Enabled: true,
+		Success: false,
+		Failure: true,
+	}, &integrations.WebhookIntegration{
+		URL: []byte(server.URL),
+	})
+
+	notifier.AllowPrivateAddresses = true
+
+	notifier.Notify(&slack.NotifyOpts{Status: slack.StatusDeployed})
+
+	if called {
+		t.Errorf("webhook called for success notification when success is disabled")
+	}
+
+	notifier.Notify(&slack.NotifyOpts{Status: slack.StatusFailed})
+
+	if !called {
+		t.Errorf("webhook not called for failure notification")
+	}
+}
+
+func TestNotifyQueueDoesNotBlock(t *testing.T) {
+	release := make(chan struct{})
+	delivered := make(chan struct{}, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.WriteHeader(http.StatusOK)
+		delivered <- struct{}{}
+	}))
+
+	defer server.Close()
+
+	notifier := webhook.NewWebhookNotifier(nil, &integrations.WebhookIntegration{
+		URL: []byte(server.URL),
+	})
+
+	notifier.AllowPrivateAddresses = true
+	notifier.Queue = webhook.NewQueue(1)
+
+	stopCh := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		notifier.Queue.Run(stopCh, 1)
+		close(stopped)
+	}()
+
+	start := time.Now()
+
+	if err := notifier.Notify(&slack.NotifyOpts{Status: slack.StatusDeployed}); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected notify to return before the delivery completed, took %s", elapsed)
+	}
+
+	close(release)
+
+	select {
+	case <-delivered:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("queued delivery was not sent")
+	}
+
+	close(stopCh)
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("queue did not stop")
+	}
+}
+
+func TestNotifyQueueCancelsRetries(t *testing.T) {
+	attempted := make(chan struct{}, 1)
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		attempted <- struct{}{}
+	}))
+
+	defer server.Close()
+
+	notifier := webhook.NewWebhookNotifier(nil, &integrations.WebhookIntegration{
+		URL: []byte(server.URL),
+	})
+
+	notifier.AllowPrivateAddresses = true
+	notifier.Backoff = time.Hour
+	notifier.Queue = webhook.NewQueue(1)
+
+	errCh := make(chan error, 1)
+
+	notifier.Queue.OnError = func(webhookID uint, err error) {
+		errCh <- err
+	}
+
+	stopCh := make(chan struct{})
+	stopped := make(chan struct{})
+
+	go func() {
+		notifier.Queue.Run(stopCh, 1)
+		close(stopped)
+	}()
+
+	if err := notifier.Notify(&slack.NotifyOpts{Status: slack.StatusDeployed}); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	// wait for the first attempt to be made, so that the delivery is waiting to retry
+	<-attempted
+
+	close(stopCh)
+
+	select {
+	case <-stopped:
+	case <-time.After(5 * time.Second):
+		t.Fatalf("queue did not cancel the delivery that was waiting to retry")
+	}
+
+	if err := <-errCh; err == nil {
+		t.Errorf("expected an error for the cancelled delivery")
+	}
+}
@@ -0,0 +1,66 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// Queue delivers webhook notifications in the background, so that the retries of
+// a slow or unreachable webhook do not delay the request that sent the notification.
+// The queue is bounded, and deliveries are dropped when it is full.
+type Queue struct {
+	deliveries chan *delivery
+
+	// OnError is called with the error of each delivery that failed after all of
+	// its attempts, if set
+	OnError func(webhookID uint, err error)
+}
+
+// NewQueue returns a queue that holds up to size pending deliveries
+func NewQueue(size int) *Queue {
+	return &Queue{
+		deliveries: make(chan *delivery, size),
+	}
+}
+
+// Run delivers queued notifications with the given number of workers until stopCh
+// is closed. Deliveries that are in progress when stopCh is closed are cancelled.
+func (q *Queue) Run(stopCh <-chan struct{}, workers int) {
+	ctx, cancel := context.WithCancel(context.Background())
+
+	var wg sync.WaitGroup
+
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case d := <-q.deliveries:
+					if err := d.deliver(ctx); err != nil && q.OnError != nil {
+						q.OnError(d.webhookInt.ID, err)
+					}
+				}
+			}
+		}()
+	}
+
+	<-stopCh
+
+	cancel()
+	wg.Wait()
+}
+
+func (q *Queue) enqueue(d *delivery) error {
+	select {
+	case q.deliveries <- d:
+		return nil
+	default:
+		return fmt.Errorf("webhook queue is full, dropping delivery to webhook %d", d.webhookInt.ID)
+	}
+}
@@ -0,0 +1,53 @@
+package webhook
+
+import (
+	"crypto/hmac"
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultReplayWindow is the maximum age of a signed request, and how far in the
+// future its timestamp may be to allow for clock skew
+const DefaultReplayWindow = 5 * time.Minute
+
+// Errors returned when verifying a signed request
+var (
+	ErrMissingSignature = errors.New("missing signature or timestamp header")
+	ErrInvalidTimestamp = errors.New("invalid timestamp header")
+	ErrStaleTimestamp   = errors.New("timestamp is outside of the replay window")
+	ErrInvalidSignature = errors.New("invalid signature")
+)
+
+// Verify checks the X-Porter-Timestamp and X-Porter-Signature headers of a request
+// against its raw body, using the same scheme as Sign. Requests with a timestamp
+// further than window from now are rejected, so that captured requests cannot be
+// replayed later.
+func Verify(secret []byte, header http.Header, payload []byte, now time.Time, window time.Duration) error {
+	ts := header.Get(TimestampHeader)
+	sig := header.Get(SignatureHeader)
+
+	if ts == "" || sig == "" {
+		return ErrMissingSignature
+	}
+
+	unix, err := strconv.ParseInt(ts, 10, 64)
+
+	if err != nil {
+		return ErrInvalidTimestamp
+	}
+
+	if diff := now.Sub(time.Unix(unix, 0)); diff > window || diff < -window {
+		return ErrStaleTimestamp
+	}
+
+	expected := Sign(secret, ts, payload)
+
+	if !hmac.Equal([]byte(strings.TrimPrefix(sig, "sha256=")), []byte(expected)) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
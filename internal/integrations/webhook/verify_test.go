@@ -0,0 +1,65 @@
+package webhook_test
+
+import (
+	"net/http"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/porter-dev/porter/internal/integrations/webhook"
+)
+
+func TestVerify(t *testing.T) {
+	secret := []byte("secret")
+	payload := []byte(`{"tag":"v2"}`)
+	now := time.Unix(1600000000, 0)
+
+	signedHeader := func(ts time.Time, body []byte) http.Header {
+		tsStr := strconv.FormatInt(ts.Unix(), 10)
+
+		header := http.Header{}
+		header.Set(webhook.TimestampHeader, tsStr)
+		header.Set(webhook.SignatureHeader, "sha256="+webhook.Sign(secret, tsStr, body))
+
+		return header
+	}
+
+	tests := []struct {
+		msg    string
+		header http.Header
+		expErr error
+	}{
+		{
+			msg:    "valid signature",
+			header: signedHeader(now.Add(-1*time.Minute), payload),
+		},
+		{
+			msg:    "missing headers",
+			header: http.Header{},
+			expErr: webhook.ErrMissingSignature,
+		},
+		{
+			msg:    "stale timestamp",
+			header: signedHeader(now.Add(-10*time.Minute), payload),
+			expErr: webhook.ErrStaleTimestamp,
+		},
+		{
+			msg:    "timestamp in the future",
+			header: signedHeader(now.Add(10*time.Minute), payload),
+			expErr: webhook.ErrStaleTimestamp,
+		},
+		{
+			msg:    "signature of a different body",
+			header: signedHeader(now, []byte(`{"tag":"v3"}`)),
+			expErr: webhook.ErrInvalidSignature,
+		},
+	}
+
+	for _, test := range tests {
+		err := webhook.Verify(secret, test.header, payload, now, webhook.DefaultReplayWindow)
+
+		if err != test.expErr {
+			t.Errorf("%s: expected error %v, got %v", test.msg, test.expErr, err)
+		}
+	}
+}
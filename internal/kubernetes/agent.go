@@ -1142,6 +1142,12 @@ func (a *Agent) provision(
 	return job, nil
 }
 
+// ImagePullSecretName returns the name of the image pull secret that Porter creates
+// for a registry
+func ImagePullSecretName(reg *models.Registry) string {
+	return fmt.Sprintf("porter-%s-%d", reg.Externalize().Service, reg.ID)
+}
+
 // CreateImagePullSecrets will create the required image pull secrets and
 // return a map from the registry name to the name of the secret.
 func (a *Agent) CreateImagePullSecrets(
@@ -1161,7 +1167,7 @@ func (a *Agent) CreateImagePullSecrets(
 			return nil, err
 		}
 
-		secretName := fmt.Sprintf("porter-%s-%d", val.Externalize().Service, val.ID)
+		secretName := ImagePullSecretName(val)
 
 		secret, err := a.Clientset.CoreV1().Secrets(namespace).Get(
 			context.TODO(),
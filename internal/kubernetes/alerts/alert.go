@@ -0,0 +1,162 @@
+package alerts
+
+import (
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+)
+
+// AlertKind is the type of failure that an alert reports
+type AlertKind string
+
+const (
+	CrashLoopBackOff AlertKind = "CrashLoopBackOff"
+	OOMKilled        AlertKind = "OOMKilled"
+	ImagePullBackOff AlertKind = "ImagePullBackOff"
+	JobFailed        AlertKind = "JobFailed"
+)
+
+// releaseLabels are the labels that identify the helm release of an object, in order
+// of precedence
+var releaseLabels = []string{
+	"app.kubernetes.io/instance",
+	"meta.helm.sh/release-name",
+	"release",
+}
+
+// Alert is a failure of a workload that belongs to a helm release
+type Alert struct {
+	Kind AlertKind
+
+	Namespace   string
+	ReleaseName string
+
+	// ObjectName is the name of the pod or job that failed
+	ObjectName string
+
+	// Message is a human-readable description of the failure
+	Message string
+}
+
+// Key identifies the release and the kind of failure, and is used to deduplicate
+// alerts across the pods of a release
+func (a *Alert) Key() string {
+	return fmt.Sprintf("%s/%s/%s", a.Namespace, a.ReleaseName, a.Kind)
+}
+
+// GetPodAlerts returns the alerts for a pod that belongs to a helm release
+func GetPodAlerts(pod *v1.Pod) []*Alert {
+	releaseName := getReleaseName(pod.Labels)
+
+	if releaseName == "" {
+		return nil
+	}
+
+	kinds := make(map[AlertKind]string)
+
+	statuses := append([]v1.ContainerStatus{}, pod.Status.InitContainerStatuses...)
+	statuses = append(statuses, pod.Status.ContainerStatuses...)
+
+	for _, status := range statuses {
+		if kind, msg := getContainerAlert(status); kind != "" {
+			if _, exists := kinds[kind]; !exists {
+				kinds[kind] = msg
+			}
+		}
+	}
+
+	res := make([]*Alert, 0)
+
+	for _, kind := range []AlertKind{OOMKilled, CrashLoopBackOff, ImagePullBackOff} {
+		if msg, ok := kinds[kind]; ok {
+			res = append(res, &Alert{
+				Kind:        kind,
+				Namespace:   pod.Namespace,
+				ReleaseName: releaseName,
+				ObjectName:  pod.Name,
+				Message:     msg,
+			})
+		}
+	}
+
+	return res
+}
+
+// GetJobAlert returns an alert if a job that belongs to a helm release has failed
+func GetJobAlert(job *batchv1.Job) *Alert {
+	releaseName := getReleaseName(job.Labels)
+
+	if releaseName == "" {
+		return nil
+	}
+
+	for _, cond := range job.Status.Conditions {
+		if cond.Type == batchv1.JobFailed && cond.Status == v1.ConditionTrue {
+			msg := fmt.Sprintf("job %s failed", job.Name)
+
+			if cond.Message != "" {
+				msg = fmt.Sprintf("%s: %s", msg, cond.Message)
+			}
+
+			return &Alert{
+				Kind:        JobFailed,
+				Namespace:   job.Namespace,
+				ReleaseName: releaseName,
+				ObjectName:  job.Name,
+				Message:     msg,
+			}
+		}
+	}
+
+	return nil
+}
+
+func getContainerAlert(status v1.ContainerStatus) (AlertKind, string) {
+	// a container that was OOM killed is usually restarted into CrashLoopBackOff, so
+	// the termination reason is checked first as it is more specific
+	if terminated := status.LastTerminationState.Terminated; terminated != nil && terminated.Reason == "OOMKilled" {
+		return OOMKilled, fmt.Sprintf("container %s was killed for running out of memory", status.Name)
+	}
+
+	if terminated := status.State.Terminated; terminated != nil && terminated.Reason == "OOMKilled" {
+		return OOMKilled, fmt.Sprintf("container %s was killed for running out of memory", status.Name)
+	}
+
+	waiting := status.State.Waiting
+
+	if waiting == nil {
+		return "", ""
+	}
+
+	switch waiting.Reason {
+	case "CrashLoopBackOff":
+		msg := fmt.Sprintf("container %s is crash looping", status.Name)
+
+		if terminated := status.LastTerminationState.Terminated; terminated != nil {
+			msg = fmt.Sprintf("%s (last exit code %d, reason %s)", msg, terminated.ExitCode, terminated.Reason)
+		}
+
+		return CrashLoopBackOff, msg
+	case "ImagePullBackOff", "ErrImagePull":
+		msg := fmt.Sprintf("container %s cannot pull image %s", status.Name, status.Image)
+
+		if waiting.Message != "" {
+			msg = fmt.Sprintf("%s: %s", msg, waiting.Message)
+		}
+
+		return ImagePullBackOff, msg
+	}
+
+	return "", ""
+}
+
+func getReleaseName(labels map[string]string) string {
+	for _, label := range releaseLabels {
+		if val, ok := labels[label]; ok && val != "" {
+			return val
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,145 @@
+package alerts_test
+
+import (
+	"testing"
+
+	"github.com/porter-dev/porter/internal/kubernetes/alerts"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newPod(labels map[string]string, statuses ...v1.ContainerStatus) *v1.Pod {
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "web-abc123",
+			Namespace: "default",
+			Labels:    labels,
+		},
+		Status: v1.PodStatus{
+			ContainerStatuses: statuses,
+		},
+	}
+}
+
+var releaseLabels = map[string]string{
+	"app.kubernetes.io/instance": "web",
+}
+
+func TestGetPodAlerts(t *testing.T) {
+	tests := []struct {
+		msg     string
+		pod     *v1.Pod
+		expKind alerts.AlertKind
+	}{
+		{
+			msg: "crash looping container",
+			pod: newPod(releaseLabels, v1.ContainerStatus{
+				Name: "web",
+				State: v1.ContainerState{
+					Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+				},
+				LastTerminationState: v1.ContainerState{
+					Terminated: &v1.ContainerStateTerminated{Reason: "Error", ExitCode: 1},
+				},
+			}),
+			expKind: alerts.CrashLoopBackOff,
+		},
+		{
+			msg: "OOM killed container that is crash looping",
+			pod: newPod(releaseLabels, v1.ContainerStatus{
+				Name: "web",
+				State: v1.ContainerState{
+					Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+				},
+				LastTerminationState: v1.ContainerState{
+					Terminated: &v1.ContainerStateTerminated{Reason: "OOMKilled", ExitCode: 137},
+				},
+			}),
+			expKind: alerts.OOMKilled,
+		},
+		{
+			msg: "image pull failure",
+			pod: newPod(releaseLabels, v1.ContainerStatus{
+				Name:  "web",
+				Image: "nginx:doesnotexist",
+				State: v1.ContainerState{
+					Waiting: &v1.ContainerStateWaiting{Reason: "ErrImagePull"},
+				},
+			}),
+			expKind: alerts.ImagePullBackOff,
+		},
+		{
+			msg: "running container",
+			pod: newPod(releaseLabels, v1.ContainerStatus{
+				Name: "web",
+				State: v1.ContainerState{
+					Running: &v1.ContainerStateRunning{},
+				},
+			}),
+		},
+		{
+			msg: "pod without a release",
+			pod: newPod(nil, v1.ContainerStatus{
+				Name: "web",
+				State: v1.ContainerState{
+					Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+				},
+			}),
+		},
+	}
+
+	for _, test := range tests {
+		res := alerts.GetPodAlerts(test.pod)
+
+		if test.expKind == "" {
+			if len(res) != 0 {
+				t.Errorf("%s: expected no alerts, got %v", test.msg, res)
+			}
+
+			continue
+		}
+
+		if len(res) != 1 {
+			t.Fatalf("%s: expected 1 alert, got %d", test.msg, len(res))
+		}
+
+		if res[0].Kind != test.expKind || res[0].ReleaseName != "web" || res[0].Namespace != "default" {
+			t.Errorf("%s: incorrect alert: %v", test.msg, res[0])
+		}
+	}
+}
+
+func TestGetJobAlert(t *testing.T) {
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "migrate-1",
+			Namespace: "default",
+			Labels: map[string]string{
+				"meta.helm.sh/release-name": "migrate",
+			},
+		},
+	}
+
+	if alert := alerts.GetJobAlert(job); alert != nil {
+		t.Errorf("expected no alert for a running job, got %v", alert)
+	}
+
+	job.Status.Conditions = []batchv1.JobCondition{
+		{
+			Type:    batchv1.JobFailed,
+			Status:  v1.ConditionTrue,
+			Message: "Job has reached the specified backoff limit",
+		},
+	}
+
+	alert := alerts.GetJobAlert(job)
+
+	if alert == nil {
+		t.Fatalf("expected an alert for a failed job")
+	}
+
+	if alert.Kind != alerts.JobFailed || alert.ReleaseName != "migrate" {
+		t.Errorf("incorrect alert: %v", alert)
+	}
+}
@@ -0,0 +1,87 @@
+package alerts
+
+import (
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Limiter deduplicates alerts and rate limits the alerts sent for a release
+type Limiter struct {
+	// DedupWindow is the time after an alert is sent during which alerts with the
+	// same key are dropped
+	DedupWindow time.Duration
+
+	// MaxPerRelease is the maximum number of alerts sent for a single release in
+	// RateWindow
+	MaxPerRelease int
+	RateWindow    time.Duration
+
+	now func() time.Time
+
+	mu       sync.Mutex
+	lastSent map[string]time.Time
+	sent     map[string][]time.Time
+}
+
+// NewLimiter returns a Limiter with the given windows
+func NewLimiter(dedupWindow time.Duration, maxPerRelease int, rateWindow time.Duration) *Limiter {
+	return &Limiter{
+		DedupWindow:   dedupWindow,
+		MaxPerRelease: maxPerRelease,
+		RateWindow:    rateWindow,
+		now:           time.Now,
+		lastSent:      make(map[string]time.Time),
+		sent:          make(map[string][]time.Time),
+	}
+}
+
+// Allow returns true if the alert should be sent, and records it as sent
+func (l *Limiter) Allow(clusterID uint, alert *Alert) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	now := l.now()
+	key := fmt.Sprintf("%d/%s", clusterID, alert.Key())
+	releaseKey := fmt.Sprintf("%d/%s/%s", clusterID, alert.Namespace, alert.ReleaseName)
+
+	if last, ok := l.lastSent[key]; ok && now.Sub(last) < l.DedupWindow {
+		return false
+	}
+
+	recent := make([]time.Time, 0)
+
+	for _, sentAt := range l.sent[releaseKey] {
+		if now.Sub(sentAt) < l.RateWindow {
+			recent = append(recent, sentAt)
+		}
+	}
+
+	if len(recent) >= l.MaxPerRelease {
+		l.sent[releaseKey] = recent
+		return false
+	}
+
+	l.lastSent[key] = now
+	l.sent[releaseKey] = append(recent, now)
+
+	l.prune(now)
+
+	return true
+}
+
+// prune removes dedup entries that have expired, so that the limiter does not grow
+// without bound as pods come and go
+func (l *Limiter) prune(now time.Time) {
+	for key, last := range l.lastSent {
+		if now.Sub(last) >= l.DedupWindow {
+			delete(l.lastSent, key)
+		}
+	}
+
+	for key, sent := range l.sent {
+		if len(sent) == 0 || now.Sub(sent[len(sent)-1]) >= l.RateWindow {
+			delete(l.sent, key)
+		}
+	}
+}
@@ -0,0 +1,43 @@
+package alerts
+
+import (
+	"testing"
+	"time"
+)
+
+func TestLimiter(t *testing.T) {
+	now := time.Date(2021, 6, 1, 12, 0, 0, 0, time.UTC)
+
+	limiter := NewLimiter(time.Hour, 2, time.Hour)
+	limiter.now = func() time.Time { return now }
+
+	crash := &Alert{Kind: CrashLoopBackOff, Namespace: "default", ReleaseName: "web"}
+	oom := &Alert{Kind: OOMKilled, Namespace: "default", ReleaseName: "web"}
+	pull := &Alert{Kind: ImagePullBackOff, Namespace: "default", ReleaseName: "web"}
+
+	if !limiter.Allow(1, crash) {
+		t.Errorf("expected first alert to be allowed")
+	}
+
+	if limiter.Allow(1, crash) {
+		t.Errorf("expected duplicate alert to be dropped")
+	}
+
+	if !limiter.Allow(2, crash) {
+		t.Errorf("expected alert for a different cluster to be allowed")
+	}
+
+	if !limiter.Allow(1, oom) {
+		t.Errorf("expected alert of a different kind to be allowed")
+	}
+
+	if limiter.Allow(1, pull) {
+		t.Errorf("expected alert over the release rate limit to be dropped")
+	}
+
+	now = now.Add(time.Hour)
+
+	if !limiter.Allow(1, crash) {
+		t.Errorf("expected alert to be allowed after the dedup window")
+	}
+}
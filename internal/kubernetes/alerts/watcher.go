@@ -0,0 +1,288 @@
+package alerts
+
+import (
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/porter-dev/porter/internal/kubernetes"
+	lr "github.com/porter-dev/porter/internal/logger"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"golang.org/x/oauth2"
+	batchv1 "k8s.io/api/batch/v1"
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Handler is called for each alert that is not dropped by the limiter
+type Handler func(cluster *models.Cluster, alert *Alert)
+
+// Watcher runs pod and job informers against a single cluster and reports the
+// failures of Porter-managed workloads
+type Watcher struct {
+	cluster *models.Cluster
+	agent   *kubernetes.Agent
+	limiter *Limiter
+	handler Handler
+
+	stopper chan struct{}
+
+	mu  sync.Mutex
+	err error
+}
+
+// NewWatcher creates a Watcher for a cluster. The watcher does not run until Start
+// is called.
+func NewWatcher(cluster *models.Cluster, agent *kubernetes.Agent, limiter *Limiter, handler Handler) *Watcher {
+	return &Watcher{
+		cluster: cluster,
+		agent:   agent,
+		limiter: limiter,
+		handler: handler,
+		stopper: make(chan struct{}),
+	}
+}
+
+// Start runs the informers in the background until Stop is called
+func (w *Watcher) Start() {
+	factory := informers.NewSharedInformerFactoryWithOptions(
+		w.agent.Clientset,
+		0,
+	)
+
+	podInformer := factory.Core().V1().Pods().Informer()
+	podInformer.SetWatchErrorHandler(w.handleWatchError)
+
+	podInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			w.handlePod(obj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			w.handlePod(newObj)
+		},
+	})
+
+	jobInformer := factory.Batch().V1().Jobs().Informer()
+	jobInformer.SetWatchErrorHandler(w.handleWatchError)
+
+	jobInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			w.handleJob(obj)
+		},
+		UpdateFunc: func(oldObj, newObj interface{}) {
+			w.handleJob(newObj)
+		},
+	})
+
+	go podInformer.Run(w.stopper)
+	go jobInformer.Run(w.stopper)
+}
+
+// Stop stops the informers
+func (w *Watcher) Stop() {
+	close(w.stopper)
+}
+
+// Err returns the first error that the informers failed to list or watch with, for
+// example when the credentials of the cluster have expired. The informers keep
+// retrying with the same credentials, so the watcher should be replaced.
+func (w *Watcher) Err() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.err
+}
+
+func (w *Watcher) handleWatchError(r *cache.Reflector, err error) {
+	// closed and expired watches are restarted by the informers
+	if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) ||
+		apierrors.IsResourceExpired(err) || apierrors.IsGone(err) {
+		return
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.err == nil {
+		w.err = err
+	}
+}
+
+func (w *Watcher) handlePod(obj interface{}) {
+	pod, ok := obj.(*v1.Pod)
+
+	if !ok {
+		return
+	}
+
+	for _, alert := range GetPodAlerts(pod) {
+		w.send(alert)
+	}
+}
+
+func (w *Watcher) handleJob(obj interface{}) {
+	job, ok := obj.(*batchv1.Job)
+
+	if !ok {
+		return
+	}
+
+	if alert := GetJobAlert(job); alert != nil {
+		w.send(alert)
+	}
+}
+
+func (w *Watcher) send(alert *Alert) {
+	if w.limiter.Allow(w.cluster.ID, alert) {
+		w.handler(w.cluster, alert)
+	}
+}
+
+// Manager keeps a Watcher running for every cluster that is connected to Porter.
+// A cluster's watcher is restarted when the credentials of the cluster change or
+// its informers fail, since cloud credentials are short-lived and the informers
+// are created with the credentials that were valid when the watcher started.
+type Manager struct {
+	// SyncInterval is how often the list of clusters is refreshed
+	SyncInterval time.Duration
+
+	repo    *repository.Repository
+	doConf  *oauth2.Config
+	pool    *kubernetes.AgentPool
+	logger  *lr.Logger
+	limiter *Limiter
+	handler Handler
+
+	mu       sync.Mutex
+	watchers map[uint]*managedWatcher
+}
+
+// managedWatcher is a running watcher along with the credential version of the
+// cluster that it was started with
+type managedWatcher struct {
+	*Watcher
+	version string
+}
+
+// NewManager creates a Manager. Every watcher shares the limiter, so that the rate
+// limits apply across restarts of a cluster's watcher. Connections to clusters are
+// created through the pool, so that expired credentials are resolved again.
+func NewManager(
+	repo *repository.Repository,
+	doConf *oauth2.Config,
+	pool *kubernetes.AgentPool,
+	logger *lr.Logger,
+	limiter *Limiter,
+	handler Handler,
+) *Manager {
+	return &Manager{
+		SyncInterval: 5 * time.Minute,
+		repo:         repo,
+		doConf:       doConf,
+		pool:         pool,
+		logger:       logger,
+		limiter:      limiter,
+		handler:      handler,
+		watchers:     make(map[uint]*managedWatcher),
+	}
+}
+
+// Run starts, restarts and stops watchers as clusters are added, updated and
+// removed, until stop is closed
+func (m *Manager) Run(stop <-chan struct{}) {
+	ticker := time.NewTicker(m.SyncInterval)
+	defer ticker.Stop()
+
+	m.sync()
+
+	for {
+		select {
+		case <-ticker.C:
+			m.sync()
+		case <-stop:
+			m.stopAll()
+			return
+		}
+	}
+}
+
+func (m *Manager) sync() {
+	clusters, err := m.repo.Cluster.ListClusters()
+
+	if err != nil {
+		m.logger.Warn().Err(err).Msg("could not list clusters for alerting")
+		return
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	seen := make(map[uint]bool)
+
+	for _, listed := range clusters {
+		seen[listed.ID] = true
+
+		// read the cluster again to load the token cache
+		cluster, err := m.repo.Cluster.ReadCluster(listed.ID)
+
+		if err != nil {
+			m.logger.Warn().Err(err).Msgf("could not read cluster %d for alerting", listed.ID)
+			continue
+		}
+
+		version := kubernetes.CredentialVersion(cluster)
+
+		if existing, exists := m.watchers[cluster.ID]; exists {
+			if watchErr := existing.Err(); watchErr != nil {
+				m.logger.Warn().Err(watchErr).Msgf("restarting alert watcher for cluster %d after watch error", cluster.ID)
+			} else if existing.version != version {
+				m.logger.Info().Msgf("restarting alert watcher for cluster %d after credentials changed", cluster.ID)
+			} else {
+				continue
+			}
+
+			existing.Stop()
+			delete(m.watchers, cluster.ID)
+
+			// the pooled connection may hold the credentials that the watcher failed with
+			m.pool.Invalidate(cluster.ID)
+		}
+
+		agent, err := m.pool.GetAgent(&kubernetes.OutOfClusterConfig{
+			Cluster:           cluster,
+			Repo:              m.repo,
+			DigitalOceanOAuth: m.doConf,
+		})
+
+		if err != nil {
+			m.logger.Warn().Err(err).Msgf("could not connect to cluster %d for alerting", cluster.ID)
+			continue
+		}
+
+		watcher := NewWatcher(cluster, agent, m.limiter, m.handler)
+		watcher.Start()
+
+		m.watchers[cluster.ID] = &managedWatcher{watcher, version}
+	}
+
+	for clusterID, watcher := range m.watchers {
+		if !seen[clusterID] {
+			watcher.Stop()
+			delete(m.watchers, clusterID)
+		}
+	}
+}
+
+func (m *Manager) stopAll() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for clusterID, watcher := range m.watchers {
+		watcher.Stop()
+		delete(m.watchers, clusterID)
+	}
+}
@@ -0,0 +1,111 @@
+package alerts_test
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/porter-dev/porter/internal/kubernetes"
+	"github.com/porter-dev/porter/internal/kubernetes/alerts"
+	"github.com/porter-dev/porter/internal/models"
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func TestWatcher(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+
+	cluster := &models.Cluster{Name: "cluster-test"}
+	cluster.ID = 1
+
+	received := make(chan *alerts.Alert, 10)
+
+	watcher := alerts.NewWatcher(
+		cluster,
+		&kubernetes.Agent{Clientset: clientset},
+		alerts.NewLimiter(time.Hour, 5, time.Hour),
+		func(c *models.Cluster, alert *alerts.Alert) {
+			received <- alert
+		},
+	)
+
+	watcher.Start()
+	defer watcher.Stop()
+
+	pod := newPod(releaseLabels, v1.ContainerStatus{
+		Name: "web",
+		State: v1.ContainerState{
+			Waiting: &v1.ContainerStateWaiting{Reason: "CrashLoopBackOff"},
+		},
+	})
+
+	_, err := clientset.CoreV1().Pods("default").Create(context.TODO(), pod, metav1.CreateOptions{})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	select {
+	case alert := <-received:
+		if alert.Kind != alerts.CrashLoopBackOff || alert.ReleaseName != "web" {
+			t.Errorf("incorrect alert: %v", alert)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatalf("timed out waiting for alert")
+	}
+
+	// updating the pod with the same failure should be deduplicated
+	pod.Status.ContainerStatuses[0].RestartCount = 2
+
+	_, err = clientset.CoreV1().Pods("default").Update(context.TODO(), pod, metav1.UpdateOptions{})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	select {
+	case alert := <-received:
+		t.Errorf("expected duplicate alert to be dropped, got %v", alert)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestWatcherListError(t *testing.T) {
+	clientset := fake.NewSimpleClientset()
+
+	// simulate credentials that have expired since the watcher was created
+	clientset.PrependReactor("list", "pods", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		return true, nil, fmt.Errorf("Unauthorized")
+	})
+
+	cluster := &models.Cluster{Name: "cluster-test"}
+	cluster.ID = 1
+
+	watcher := alerts.NewWatcher(
+		cluster,
+		&kubernetes.Agent{Clientset: clientset},
+		alerts.NewLimiter(time.Hour, 5, time.Hour),
+		func(c *models.Cluster, alert *alerts.Alert) {},
+	)
+
+	if err := watcher.Err(); err != nil {
+		t.Fatalf("expected no error before start, got %v", err)
+	}
+
+	watcher.Start()
+	defer watcher.Stop()
+
+	deadline := time.Now().Add(5 * time.Second)
+
+	for watcher.Err() == nil {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for watch error")
+		}
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
@@ -98,13 +98,26 @@ func GetAgentTesting(objects ...runtime.Object) *Agent {
 
 // OutOfClusterConfig is the set of parameters required for an out-of-cluster connection.
 // This implements RESTClientGetter
+//
+// None of its fields are decoded from a request body, since forms which embed an
+// OutOfClusterConfig are decoded over the cluster that the request was authorized for.
 type OutOfClusterConfig struct {
-	Cluster          *models.Cluster
-	Repo             *repository.Repository
-	DefaultNamespace string // optional
+	Cluster          *models.Cluster        `json:"-"`
+	Repo             *repository.Repository `json:"-"`
+	DefaultNamespace string                 `json:"-"` // optional
 
 	// Only required if using DigitalOcean OAuth as an auth mechanism
-	DigitalOceanOAuth *oauth2.Config
+	DigitalOceanOAuth *oauth2.Config `json:"-"`
+
+	// tokenExpiry is the expiry of the cloud token that the last raw config was
+	// created with, and is zero if the auth mechanism does not use one
+	tokenExpiry time.Time
+}
+
+// TokenExpiry returns the expiry of the cloud token that the last call to
+// CreateRawConfigFromCluster used, or the zero time if it did not use one
+func (conf *OutOfClusterConfig) TokenExpiry() time.Time {
+	return conf.tokenExpiry
 }
 
 // ToRESTConfig creates a kubernetes REST client factory -- it calls ClientConfig on
@@ -145,6 +158,12 @@ func (conf *OutOfClusterConfig) ToDiscoveryClient() (discovery.CachedDiscoveryIn
 		return nil, err
 	}
 
+	return newCachedDiscoveryClient(restConf)
+}
+
+// newCachedDiscoveryClient creates a discovery client that caches the API groups of
+// the cluster on disk
+func newCachedDiscoveryClient(restConf *rest.Config) (discovery.CachedDiscoveryInterface, error) {
 	restConf.Burst = 100
 	defaultHTTPCacheDir := filepath.Join(homedir.HomeDir(), ".kube", "http-cache")
 
@@ -167,9 +186,12 @@ func (conf *OutOfClusterConfig) ToRESTMapper() (meta.RESTMapper, error) {
 		return nil, err
 	}
 
+	return newRESTMapper(discoveryClient), nil
+}
+
+func newRESTMapper(discoveryClient discovery.CachedDiscoveryInterface) meta.RESTMapper {
 	mapper := restmapper.NewDeferredDiscoveryRESTMapper(discoveryClient)
-	expander := restmapper.NewShortcutExpander(mapper, discoveryClient)
-	return expander, nil
+	return restmapper.NewShortcutExpander(mapper, discoveryClient)
 }
 
 // GetClientConfigFromCluster will construct new clientcmd.ClientConfig using
@@ -197,21 +219,26 @@ func (conf *OutOfClusterConfig) GetClientConfigFromCluster() (clientcmd.ClientCo
 		return nil, err
 	}
 
+	return newNamespacedClientConfig(apiConfig, conf.DefaultNamespace), nil
+}
+
+// newNamespacedClientConfig creates a clientcmd.ClientConfig from a raw config, using
+// namespace as the default namespace if it is set
+func newNamespacedClientConfig(apiConfig *api.Config, namespace string) clientcmd.ClientConfig {
 	overrides := &clientcmd.ConfigOverrides{}
 
-	if conf.DefaultNamespace != "" {
+	if namespace != "" {
 		overrides.Context = api.Context{
-			Namespace: conf.DefaultNamespace,
+			Namespace: namespace,
 		}
 	}
 
-	config := clientcmd.NewDefaultClientConfig(*apiConfig, overrides)
-
-	return config, nil
+	return clientcmd.NewDefaultClientConfig(*apiConfig, overrides)
 }
 
 func (conf *OutOfClusterConfig) CreateRawConfigFromCluster() (*api.Config, error) {
 	cluster := conf.Cluster
+	conf.tokenExpiry = time.Time{}
 
 	apiConfig := &api.Config{}
 
@@ -313,6 +340,7 @@ func (conf *OutOfClusterConfig) CreateRawConfigFromCluster() (*api.Config, error
 
 		// add this as a bearer token
 		authInfoMap[authInfoName].Token = tok.AccessToken
+		conf.tokenExpiry = tok.Expiry
 	case models.AWS:
 		awsAuth, err := conf.Repo.AWSIntegration.ReadAWSIntegration(
 			cluster.AWSIntegrationID,
@@ -322,6 +350,10 @@ func (conf *OutOfClusterConfig) CreateRawConfigFromCluster() (*api.Config, error
 			return nil, err
 		}
 
+		// the cached token is used unless it has expired, in which case the new
+		// expiry is set by conf.setTokenCache
+		conf.tokenExpiry = cluster.TokenCache.Expiry
+
 		tok, err := awsAuth.GetBearerToken(conf.getTokenCache, conf.setTokenCache)
 
 		if err != nil {
@@ -339,12 +371,16 @@ func (conf *OutOfClusterConfig) CreateRawConfigFromCluster() (*api.Config, error
 			return nil, err
 		}
 
-		tok, _, err := oauth.GetAccessToken(oauthInt.SharedOAuthModel, conf.DigitalOceanOAuth, oauth.MakeUpdateOAuthIntegrationTokenFunction(oauthInt, *conf.Repo))
+		tok, expiry, err := oauth.GetAccessToken(oauthInt.SharedOAuthModel, conf.DigitalOceanOAuth, oauth.MakeUpdateOAuthIntegrationTokenFunction(oauthInt, *conf.Repo))
 
 		if err != nil {
 			return nil, err
 		}
 
+		if expiry != nil {
+			conf.tokenExpiry = *expiry
+		}
+
 		// add this as a bearer token
 		authInfoMap[authInfoName].Token = tok
 	default:
@@ -373,6 +409,8 @@ func (conf *OutOfClusterConfig) getTokenCache() (tok *ints.TokenCache, err error
 }
 
 func (conf *OutOfClusterConfig) setTokenCache(token string, expiry time.Time) error {
+	conf.tokenExpiry = expiry
+
 	_, err := conf.Repo.Cluster.UpdateClusterTokenCache(
 		&ints.ClusterTokenCache{
 			ClusterID: conf.Cluster.ID,
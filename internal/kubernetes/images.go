@@ -0,0 +1,112 @@
+package kubernetes
+
+import (
+	"context"
+	"strings"
+
+	v1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ListImageRefs lists the image references of every pod and controller in the
+// cluster, including controllers that are scaled to zero, cron jobs that are not
+// running and the old replica sets that a deployment can be rolled back to. The
+// references of running containers include the digest that the image was resolved
+// to.
+func (a *Agent) ListImageRefs() ([]string, error) {
+	refs := make(map[string]bool)
+
+	addSpec := func(spec v1.PodSpec) {
+		for _, c := range spec.InitContainers {
+			refs[c.Image] = true
+		}
+
+		for _, c := range spec.Containers {
+			refs[c.Image] = true
+		}
+	}
+
+	pods, err := a.Clientset.CoreV1().Pods("").List(context.TODO(), metav1.ListOptions{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, pod := range pods.Items {
+		addSpec(pod.Spec)
+
+		for _, status := range append(pod.Status.InitContainerStatuses, pod.Status.ContainerStatuses...) {
+			if status.ImageID != "" {
+				refs[trimImageIDPrefix(status.ImageID)] = true
+			}
+		}
+	}
+
+	deployments, err := a.Clientset.AppsV1().Deployments("").List(context.TODO(), metav1.ListOptions{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, depl := range deployments.Items {
+		addSpec(depl.Spec.Template.Spec)
+	}
+
+	replicaSets, err := a.Clientset.AppsV1().ReplicaSets("").List(context.TODO(), metav1.ListOptions{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, rs := range replicaSets.Items {
+		addSpec(rs.Spec.Template.Spec)
+	}
+
+	statefulSets, err := a.Clientset.AppsV1().StatefulSets("").List(context.TODO(), metav1.ListOptions{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ss := range statefulSets.Items {
+		addSpec(ss.Spec.Template.Spec)
+	}
+
+	daemonSets, err := a.Clientset.AppsV1().DaemonSets("").List(context.TODO(), metav1.ListOptions{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ds := range daemonSets.Items {
+		addSpec(ds.Spec.Template.Spec)
+	}
+
+	cronJobs, err := a.Clientset.BatchV1beta1().CronJobs("").List(context.TODO(), metav1.ListOptions{})
+
+	if err != nil {
+		return nil, err
+	}
+
+	for _, cj := range cronJobs.Items {
+		addSpec(cj.Spec.JobTemplate.Spec.Template.Spec)
+	}
+
+	res := make([]string, 0, len(refs))
+
+	for ref := range refs {
+		res = append(res, ref)
+	}
+
+	return res, nil
+}
+
+// trimImageIDPrefix removes the prefix that container runtimes add to the image ID
+// of a container status, such as docker-pullable://
+func trimImageIDPrefix(imageID string) string {
+	if spl := strings.SplitN(imageID, "://", 2); len(spl) == 2 {
+		return spl[1]
+	}
+
+	return imageID
+}
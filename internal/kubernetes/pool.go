@@ -0,0 +1,388 @@
+package kubernetes
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/porter-dev/porter/internal/models"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/discovery"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+	"k8s.io/client-go/tools/clientcmd/api"
+)
+
+// DefaultAgentPoolTTL is the default amount of time that a connection to a cluster is
+// reused for. A connection that was created with a cloud token, such as an EKS or
+// GKE token, also expires tokenExpiryMargin before the token, since the token is
+// only refreshed when a new connection is created.
+const DefaultAgentPoolTTL = 5 * time.Minute
+
+// tokenExpiryMargin is how long before the expiry of its cloud token a connection
+// stops being reused, so that requests that are in flight do not use an expired
+// token
+const tokenExpiryMargin = 30 * time.Second
+
+// AgentPool caches the resolved connection to each cluster -- the kubeconfig, REST
+// config, clientset, discovery client and REST mapper -- so that requests against the
+// same cluster do not re-resolve cloud credentials or re-run discovery. Entries are
+// keyed by cluster ID and the credential version of the cluster, and expire after the
+// pool's TTL or before their cloud token expires, whichever is first.
+//
+// A pool with a TTL of 0 does not cache, and creates a new connection on every call.
+type AgentPool struct {
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[uint]*poolEntry
+	stats   AgentPoolStats
+}
+
+// AgentPoolStats contains the counters of an AgentPool
+type AgentPoolStats struct {
+	Size          int    `json:"size"`
+	Hits          uint64 `json:"hits"`
+	Misses        uint64 `json:"misses"`
+	Evictions     uint64 `json:"evictions"`
+	Invalidations uint64 `json:"invalidations"`
+}
+
+// HitRate returns the fraction of lookups that were served from the pool
+func (s AgentPoolStats) HitRate() float64 {
+	if total := s.Hits + s.Misses; total > 0 {
+		return float64(s.Hits) / float64(total)
+	}
+
+	return 0
+}
+
+// NewAgentPool creates a new AgentPool whose entries expire after ttl
+func NewAgentPool(ttl time.Duration) *AgentPool {
+	return &AgentPool{
+		ttl:     ttl,
+		now:     time.Now,
+		entries: make(map[uint]*poolEntry),
+	}
+}
+
+// GetAgent returns an Agent for the cluster of conf, reusing the pooled connection to
+// the cluster if there is one. The agent uses conf.DefaultNamespace as its namespace.
+func (p *AgentPool) GetAgent(conf *OutOfClusterConfig) (*Agent, error) {
+	if p.ttl <= 0 {
+		return GetAgentOutOfClusterConfig(conf)
+	}
+
+	entry, err := p.getEntry(conf)
+
+	if err != nil {
+		return nil, err
+	}
+
+	getter := &pooledRESTClientGetter{
+		entry:     entry,
+		namespace: conf.DefaultNamespace,
+	}
+
+	return &Agent{getter, entry.clientset}, nil
+}
+
+// GetDynamicClient returns a dynamic client for the cluster of conf, reusing the
+// pooled REST config of the cluster if there is one
+func (p *AgentPool) GetDynamicClient(conf *OutOfClusterConfig) (dynamic.Interface, error) {
+	if p.ttl <= 0 {
+		return GetDynamicClientOutOfClusterConfig(conf)
+	}
+
+	entry, err := p.getEntry(conf)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return dynamic.NewForConfig(rest.CopyConfig(entry.restConfig))
+}
+
+// Invalidate removes the pooled connection to a cluster, so that the next call
+// resolves the credentials of the cluster again
+func (p *AgentPool) Invalidate(clusterID uint) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if _, ok := p.entries[clusterID]; ok {
+		delete(p.entries, clusterID)
+		p.stats.Invalidations++
+	}
+}
+
+// EvictExpired removes all expired entries from the pool, and returns the number of
+// entries that were removed
+func (p *AgentPool) EvictExpired() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := p.now()
+	evicted := 0
+
+	for clusterID, entry := range p.entries {
+		if !now.Before(entry.expiresAt) {
+			delete(p.entries, clusterID)
+			evicted++
+		}
+	}
+
+	p.stats.Evictions += uint64(evicted)
+
+	return evicted
+}
+
+// Stats returns the current counters of the pool
+func (p *AgentPool) Stats() AgentPoolStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	stats := p.stats
+	stats.Size = len(p.entries)
+
+	return stats
+}
+
+// Run evicts expired entries from the pool every interval and reports the counters
+// of the pool, until stop is closed
+func (p *AgentPool) Run(stop <-chan struct{}, interval time.Duration, report func(stats AgentPoolStats)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			p.EvictExpired()
+
+			if report != nil {
+				report(p.Stats())
+			}
+		}
+	}
+}
+
+// getEntry returns the pooled entry for the cluster of conf, creating it if it does
+// not exist, has expired or was created for an older version of the credentials.
+// Concurrent callers for the same cluster wait on a single entry, so that credentials
+// are only resolved once.
+func (p *AgentPool) getEntry(conf *OutOfClusterConfig) (*poolEntry, error) {
+	if conf.Cluster == nil {
+		return nil, fmt.Errorf("cluster cannot be nil")
+	}
+
+	clusterID := conf.Cluster.ID
+	version := CredentialVersion(conf.Cluster)
+
+	p.mu.Lock()
+
+	now := p.now()
+
+	if entry, ok := p.entries[clusterID]; ok {
+		if entry.version == version && now.Before(entry.expiresAt) {
+			p.stats.Hits++
+			p.mu.Unlock()
+
+			<-entry.ready
+
+			if entry.err != nil {
+				return nil, entry.err
+			}
+
+			return entry, nil
+		}
+
+		if entry.version != version {
+			p.stats.Invalidations++
+		} else {
+			p.stats.Evictions++
+		}
+	}
+
+	p.stats.Misses++
+
+	entry := &poolEntry{
+		version:   version,
+		expiresAt: now.Add(p.ttl),
+		ready:     make(chan struct{}),
+	}
+
+	p.entries[clusterID] = entry
+	p.mu.Unlock()
+
+	entry.err = entry.connect(conf)
+
+	if expiry := conf.TokenExpiry(); entry.err == nil && !expiry.IsZero() {
+		p.mu.Lock()
+
+		if tokenExpiresAt := expiry.Add(-tokenExpiryMargin); tokenExpiresAt.Before(entry.expiresAt) {
+			entry.expiresAt = tokenExpiresAt
+		}
+
+		p.mu.Unlock()
+	}
+
+	close(entry.ready)
+
+	if entry.err != nil {
+		// failed connections are not cached, so that the next call can retry
+		p.mu.Lock()
+
+		if p.entries[clusterID] == entry {
+			delete(p.entries, clusterID)
+		}
+
+		p.mu.Unlock()
+
+		return nil, entry.err
+	}
+
+	return entry, nil
+}
+
+// CredentialVersion identifies the version of the credentials of a cluster: it changes
+// whenever the cluster is updated, its auth mechanism or integrations change, or its
+// token cache is written to. Updates to an integration should touch the clusters that
+// use it with repository.TouchClusters.
+func CredentialVersion(cluster *models.Cluster) string {
+	return fmt.Sprintf(
+		"%d/%s/%d/%d/%d/%d/%d/%d",
+		cluster.UpdatedAt.UnixNano(),
+		cluster.AuthMechanism,
+		cluster.KubeIntegrationID,
+		cluster.OIDCIntegrationID,
+		cluster.GCPIntegrationID,
+		cluster.AWSIntegrationID,
+		cluster.DOIntegrationID,
+		cluster.TokenCache.UpdatedAt.UnixNano(),
+	)
+}
+
+// poolEntry is the resolved connection to a single cluster
+type poolEntry struct {
+	version   string
+	expiresAt time.Time
+
+	// ready is closed once the connection has been resolved, after which err
+	// and the fields below are set
+	ready chan struct{}
+	err   error
+
+	rawConfig  *api.Config
+	namespaced bool
+	restConfig *rest.Config
+	clientset  kubernetes.Interface
+
+	discoveryMu     sync.Mutex
+	discoveryClient discovery.CachedDiscoveryInterface
+	restMapper      meta.RESTMapper
+}
+
+func (e *poolEntry) connect(conf *OutOfClusterConfig) error {
+	if conf.Cluster.AuthMechanism == models.Local {
+		kubeAuth, err := conf.Repo.KubeIntegration.ReadKubeIntegration(
+			conf.Cluster.KubeIntegrationID,
+		)
+
+		if err != nil {
+			return err
+		}
+
+		rawConfig, err := clientcmd.Load(kubeAuth.Kubeconfig)
+
+		if err != nil {
+			return err
+		}
+
+		e.rawConfig = rawConfig
+	} else {
+		rawConfig, err := conf.CreateRawConfigFromCluster()
+
+		if err != nil {
+			return err
+		}
+
+		e.rawConfig = rawConfig
+		e.namespaced = true
+	}
+
+	restConf, err := newNamespacedClientConfig(e.rawConfig, "").ClientConfig()
+
+	if err != nil {
+		return err
+	}
+
+	rest.SetKubernetesDefaults(restConf)
+
+	clientset, err := kubernetes.NewForConfig(restConf)
+
+	if err != nil {
+		return err
+	}
+
+	e.restConfig = restConf
+	e.clientset = clientset
+
+	return nil
+}
+
+// discovery lazily creates the discovery client and REST mapper of the cluster, which
+// are shared by all agents of the entry. Errors are not cached, so that the next call
+// can retry.
+func (e *poolEntry) discovery() (discovery.CachedDiscoveryInterface, meta.RESTMapper, error) {
+	e.discoveryMu.Lock()
+	defer e.discoveryMu.Unlock()
+
+	if e.discoveryClient == nil {
+		discoveryClient, err := newCachedDiscoveryClient(rest.CopyConfig(e.restConfig))
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		e.discoveryClient = discoveryClient
+		e.restMapper = newRESTMapper(discoveryClient)
+	}
+
+	return e.discoveryClient, e.restMapper, nil
+}
+
+// pooledRESTClientGetter implements genericclioptions.RESTClientGetter on top of a
+// pooled connection, scoped to a namespace
+type pooledRESTClientGetter struct {
+	entry     *poolEntry
+	namespace string
+}
+
+func (g *pooledRESTClientGetter) ToRESTConfig() (*rest.Config, error) {
+	return rest.CopyConfig(g.entry.restConfig), nil
+}
+
+func (g *pooledRESTClientGetter) ToRawKubeConfigLoader() clientcmd.ClientConfig {
+	// local kubeconfigs are loaded as-is, matching OutOfClusterConfig
+	if !g.entry.namespaced {
+		return newNamespacedClientConfig(g.entry.rawConfig, "")
+	}
+
+	return newNamespacedClientConfig(g.entry.rawConfig, g.namespace)
+}
+
+func (g *pooledRESTClientGetter) ToDiscoveryClient() (discovery.CachedDiscoveryInterface, error) {
+	discoveryClient, _, err := g.entry.discovery()
+
+	return discoveryClient, err
+}
+
+func (g *pooledRESTClientGetter) ToRESTMapper() (meta.RESTMapper, error) {
+	_, restMapper, err := g.entry.discovery()
+
+	return restMapper, err
+}
@@ -0,0 +1,281 @@
+package kubernetes
+
+import (
+	"testing"
+	"time"
+
+	"github.com/porter-dev/porter/internal/models"
+	ints "github.com/porter-dev/porter/internal/models/integrations"
+	"github.com/porter-dev/porter/internal/repository"
+	memory "github.com/porter-dev/porter/internal/repository/memory"
+	"k8s.io/client-go/rest"
+)
+
+// countingKubeIntegrationRepository counts the number of times credentials are read
+type countingKubeIntegrationRepository struct {
+	repository.KubeIntegrationRepository
+
+	reads int
+}
+
+func (repo *countingKubeIntegrationRepository) ReadKubeIntegration(id uint) (*ints.KubeIntegration, error) {
+	repo.reads++
+
+	return repo.KubeIntegrationRepository.ReadKubeIntegration(id)
+}
+
+func newPoolFixture(t *testing.T) (*AgentPool, *OutOfClusterConfig, *countingKubeIntegrationRepository, *time.Time) {
+	t.Helper()
+
+	repo := memory.NewRepository(true)
+	kubeRepo := &countingKubeIntegrationRepository{KubeIntegrationRepository: repo.KubeIntegration}
+	repo.KubeIntegration = kubeRepo
+
+	ki, err := kubeRepo.CreateKubeIntegration(&ints.KubeIntegration{
+		Mechanism: ints.KubeBearer,
+		ProjectID: 1,
+		Token:     []byte("token"),
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	cluster := &models.Cluster{
+		ProjectID:         1,
+		Name:              "cluster-test",
+		Server:            "https://localhost",
+		AuthMechanism:     models.Bearer,
+		KubeIntegrationID: ki.ID,
+	}
+
+	cluster.ID = 1
+
+	now := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	pool := NewAgentPool(time.Minute)
+	pool.now = func() time.Time { return now }
+
+	return pool, &OutOfClusterConfig{Cluster: cluster, Repo: repo}, kubeRepo, &now
+}
+
+func TestAgentPoolReusesConnection(t *testing.T) {
+	pool, conf, kubeRepo, _ := newPoolFixture(t)
+
+	for _, ns := range []string{"default", "porter", "default"} {
+		conf.DefaultNamespace = ns
+
+		agent, err := pool.GetAgent(conf)
+
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+
+		gotNs, _, err := agent.RESTClientGetter.ToRawKubeConfigLoader().Namespace()
+
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+
+		if gotNs != ns {
+			t.Errorf("namespace incorrect: expected %s, got %s\n", ns, gotNs)
+		}
+	}
+
+	if kubeRepo.reads != 1 {
+		t.Errorf("credentials read %d times, expected 1\n", kubeRepo.reads)
+	}
+
+	stats := pool.Stats()
+
+	if stats.Hits != 2 || stats.Misses != 1 || stats.Size != 1 {
+		t.Errorf("stats incorrect: %+v\n", stats)
+	}
+
+	if rate := stats.HitRate(); rate < 0.66 || rate > 0.67 {
+		t.Errorf("hit rate incorrect: %f\n", rate)
+	}
+}
+
+func TestAgentPoolExpiresEntries(t *testing.T) {
+	pool, conf, kubeRepo, now := newPoolFixture(t)
+
+	if _, err := pool.GetAgent(conf); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	*now = now.Add(2 * time.Minute)
+
+	if evicted := pool.EvictExpired(); evicted != 1 {
+		t.Errorf("expected 1 evicted entry, got %d\n", evicted)
+	}
+
+	if _, err := pool.GetAgent(conf); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if kubeRepo.reads != 2 {
+		t.Errorf("credentials read %d times, expected 2\n", kubeRepo.reads)
+	}
+
+	if stats := pool.Stats(); stats.Evictions != 1 || stats.Misses != 2 {
+		t.Errorf("stats incorrect: %+v\n", stats)
+	}
+}
+
+func TestAgentPoolInvalidatesEntries(t *testing.T) {
+	pool, conf, kubeRepo, now := newPoolFixture(t)
+
+	if _, err := pool.GetAgent(conf); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	// updating the cluster changes the credential version
+	conf.Cluster.UpdatedAt = *now
+
+	if _, err := pool.GetAgent(conf); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	pool.Invalidate(conf.Cluster.ID)
+
+	if _, err := pool.GetAgent(conf); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if kubeRepo.reads != 3 {
+		t.Errorf("credentials read %d times, expected 3\n", kubeRepo.reads)
+	}
+
+	if stats := pool.Stats(); stats.Invalidations != 2 || stats.Hits != 0 {
+		t.Errorf("stats incorrect: %+v\n", stats)
+	}
+}
+
+func TestAgentPoolExpiresEntriesWithToken(t *testing.T) {
+	pool, conf, _, _ := newPoolFixture(t)
+
+	aws, err := conf.Repo.AWSIntegration.CreateAWSIntegration(&ints.AWSIntegration{
+		ProjectID:    1,
+		AWSClusterID: []byte("cluster-test"),
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	// the cached token is used, since it has not expired
+	expiry := time.Now().Add(10 * time.Second).Truncate(time.Second)
+
+	conf.Cluster.AuthMechanism = models.AWS
+	conf.Cluster.AWSIntegrationID = aws.ID
+	conf.Cluster.TokenCache.Token = []byte("token")
+	conf.Cluster.TokenCache.Expiry = expiry
+
+	pool.now = time.Now
+	pool.ttl = time.Hour
+
+	if _, err := pool.GetAgent(conf); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if expiresAt := pool.entries[conf.Cluster.ID].expiresAt; !expiresAt.Equal(expiry.Add(-tokenExpiryMargin)) {
+		t.Errorf("entry expires at %v, expected %v\n", expiresAt, expiry.Add(-tokenExpiryMargin))
+	}
+
+	if evicted := pool.EvictExpired(); evicted != 1 {
+		t.Errorf("expected entry with an expiring token to be evicted, got %d\n", evicted)
+	}
+}
+
+func TestAgentPoolDoesNotCacheErrors(t *testing.T) {
+	pool, conf, kubeRepo, _ := newPoolFixture(t)
+
+	conf.Cluster.KubeIntegrationID = 2
+
+	if _, err := pool.GetAgent(conf); err == nil {
+		t.Fatalf("expected error for missing integration\n")
+	}
+
+	if stats := pool.Stats(); stats.Size != 0 {
+		t.Errorf("failed connection was cached: %+v\n", stats)
+	}
+
+	conf.Cluster.KubeIntegrationID = 1
+
+	if _, err := pool.GetAgent(conf); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if kubeRepo.reads != 2 {
+		t.Errorf("credentials read %d times, expected 2\n", kubeRepo.reads)
+	}
+}
+
+func TestAgentPoolTouchedClusters(t *testing.T) {
+	pool, conf, kubeRepo, _ := newPoolFixture(t)
+
+	cluster, err := conf.Repo.Cluster.CreateCluster(conf.Cluster)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if _, err := pool.GetAgent(conf); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	// an update to the integration of the cluster touches the cluster
+	err = repository.TouchClusters(conf.Repo.Cluster, cluster.ProjectID, func(c *models.Cluster) bool {
+		return c.KubeIntegrationID == cluster.KubeIntegrationID
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if conf.Cluster, err = conf.Repo.Cluster.ReadCluster(cluster.ID); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if _, err := pool.GetAgent(conf); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if kubeRepo.reads != 2 {
+		t.Errorf("credentials read %d times, expected 2\n", kubeRepo.reads)
+	}
+
+	if stats := pool.Stats(); stats.Invalidations != 1 || stats.Hits != 0 {
+		t.Errorf("stats incorrect: %+v\n", stats)
+	}
+}
+
+func TestAgentPoolRetriesDiscovery(t *testing.T) {
+	t.Setenv("HOME", t.TempDir())
+
+	entry := &poolEntry{
+		restConfig: &rest.Config{
+			Host: "https://localhost",
+			TLSClientConfig: rest.TLSClientConfig{
+				CAFile: "/does/not/exist",
+			},
+		},
+	}
+
+	if _, _, err := entry.discovery(); err == nil {
+		t.Fatalf("expected error for missing CA file\n")
+	}
+
+	entry.restConfig.TLSClientConfig.CAFile = ""
+
+	discoveryClient, restMapper, err := entry.discovery()
+
+	if err != nil {
+		t.Fatalf("discovery error was cached: %v\n", err)
+	}
+
+	if discoveryClient == nil || restMapper == nil {
+		t.Errorf("discovery client and REST mapper must be set\n")
+	}
+}
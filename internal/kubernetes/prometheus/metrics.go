@@ -4,6 +4,8 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"math"
+	"strconv"
 	"strings"
 
 	v1 "k8s.io/api/core/v1"
@@ -194,6 +196,60 @@ func QueryPrometheus(
 	return parseQuery(rawQuery, opts.Metric)
 }
 
+// QueryLatestValue queries a metric that has a single series, such as the error
+// rate or latency of an ingress, and returns its most recent value. A metric without
+// any data points, such as an ingress that has not served requests, returns 0.
+func QueryLatestValue(
+	clientset kubernetes.Interface,
+	service *v1.Service,
+	opts *QueryOpts,
+) (float64, error) {
+	rawRes, err := QueryPrometheus(clientset, service, opts)
+
+	if err != nil {
+		return 0, err
+	}
+
+	res := make([]*promParsedSingletonQuery, 0)
+
+	if err := json.Unmarshal(rawRes, &res); err != nil {
+		return 0, err
+	}
+
+	if len(res) == 0 || len(res[0].Results) == 0 {
+		return 0, nil
+	}
+
+	latest := res[0].Results[len(res[0].Results)-1]
+
+	var val interface{}
+
+	for _, v := range []interface{}{latest.ErrorPct, latest.Latency, latest.CPU, latest.Memory, latest.Bytes, latest.Replicas} {
+		if v != nil {
+			val = v
+			break
+		}
+	}
+
+	strVal, ok := val.(string)
+
+	if !ok {
+		return 0, nil
+	}
+
+	floatVal, err := strconv.ParseFloat(strVal, 64)
+
+	if err != nil {
+		return 0, fmt.Errorf("could not parse value %s of metric %s: %v", strVal, opts.Metric, err)
+	}
+
+	if math.IsNaN(floatVal) {
+		return 0, nil
+	}
+
+	return floatVal, nil
+}
+
 type promRawQuery struct {
 	Data struct {
 		Result []struct {
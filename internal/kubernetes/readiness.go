@@ -0,0 +1,177 @@
+package kubernetes
+
+import (
+	"fmt"
+
+	"github.com/porter-dev/porter/internal/helm/grapher"
+	appsv1 "k8s.io/api/apps/v1"
+)
+
+// GetControllers gets the current state of the controllers of a release, in the
+// namespace of the release. Controllers of unsupported kinds are skipped.
+func (a *Agent) GetControllers(controllers []grapher.Object, namespace string) ([]interface{}, error) {
+	res := make([]interface{}, 0)
+
+	for _, c := range controllers {
+		c.Namespace = namespace
+
+		switch c.Kind {
+		case "Deployment":
+			rc, err := a.GetDeployment(c)
+
+			if err != nil {
+				return nil, err
+			}
+
+			rc.Kind = c.Kind
+			res = append(res, rc)
+		case "StatefulSet":
+			rc, err := a.GetStatefulSet(c)
+
+			if err != nil {
+				return nil, err
+			}
+
+			rc.Kind = c.Kind
+			res = append(res, rc)
+		case "DaemonSet":
+			rc, err := a.GetDaemonSet(c)
+
+			if err != nil {
+				return nil, err
+			}
+
+			rc.Kind = c.Kind
+			res = append(res, rc)
+		case "ReplicaSet":
+			rc, err := a.GetReplicaSet(c)
+
+			if err != nil {
+				return nil, err
+			}
+
+			rc.Kind = c.Kind
+			res = append(res, rc)
+		case "CronJob":
+			rc, err := a.GetCronJob(c)
+
+			if err != nil {
+				return nil, err
+			}
+
+			rc.Kind = c.Kind
+			res = append(res, rc)
+		}
+	}
+
+	return res, nil
+}
+
+// ControllersReady returns whether every controller has rolled out its latest spec
+// and has all of its replicas ready. If a controller is not ready, the reason
+// describes the first controller that is not.
+func ControllersReady(controllers []interface{}) (bool, string) {
+	for _, c := range controllers {
+		if ready, reason := ControllerReady(c); !ready {
+			return false, reason
+		}
+	}
+
+	return true, ""
+}
+
+// ControllerReady returns whether a controller has rolled out its latest spec,
+// along with the reason that it has not. As with kubectl rollout status, every
+// replica must be updated and available, and no replicas of the previous spec may
+// remain. Controllers without replicas, such as cron jobs, are always ready.
+func ControllerReady(controller interface{}) (bool, string) {
+	switch c := controller.(type) {
+	case *appsv1.Deployment:
+		replicas := int32(1)
+
+		if c.Spec.Replicas != nil {
+			replicas = *c.Spec.Replicas
+		}
+
+		return replicasReady(
+			"deployment",
+			c.Name,
+			c.Generation,
+			c.Status.ObservedGeneration,
+			replicas,
+			c.Status.Replicas,
+			c.Status.UpdatedReplicas,
+			c.Status.AvailableReplicas,
+		)
+	case *appsv1.StatefulSet:
+		replicas := int32(1)
+
+		if c.Spec.Replicas != nil {
+			replicas = *c.Spec.Replicas
+		}
+
+		return replicasReady(
+			"statefulset",
+			c.Name,
+			c.Generation,
+			c.Status.ObservedGeneration,
+			replicas,
+			c.Status.Replicas,
+			c.Status.UpdatedReplicas,
+			c.Status.ReadyReplicas,
+		)
+	case *appsv1.DaemonSet:
+		return replicasReady(
+			"daemonset",
+			c.Name,
+			c.Generation,
+			c.Status.ObservedGeneration,
+			c.Status.DesiredNumberScheduled,
+			c.Status.DesiredNumberScheduled,
+			c.Status.UpdatedNumberScheduled,
+			c.Status.NumberAvailable,
+		)
+	case *appsv1.ReplicaSet:
+		replicas := int32(1)
+
+		if c.Spec.Replicas != nil {
+			replicas = *c.Spec.Replicas
+		}
+
+		return replicasReady(
+			"replicaset",
+			c.Name,
+			c.Generation,
+			c.Status.ObservedGeneration,
+			replicas,
+			c.Status.Replicas,
+			replicas,
+			c.Status.AvailableReplicas,
+		)
+	}
+
+	return true, ""
+}
+
+// replicasReady checks the replica counts of a controller: replicas is the desired
+// count, total counts the replicas of every spec, and updated and available count
+// the replicas of the latest spec.
+func replicasReady(kind, name string, generation, observedGeneration int64, replicas, total, updated, available int32) (bool, string) {
+	if observedGeneration < generation {
+		return false, fmt.Sprintf("%s %s has not observed its latest spec", kind, name)
+	}
+
+	if updated < replicas {
+		return false, fmt.Sprintf("%s %s has %d/%d updated replicas", kind, name, updated, replicas)
+	}
+
+	if total > updated {
+		return false, fmt.Sprintf("%s %s has %d old replicas pending termination", kind, name, total-updated)
+	}
+
+	if available < updated {
+		return false, fmt.Sprintf("%s %s has %d/%d available replicas", kind, name, available, updated)
+	}
+
+	return true, ""
+}
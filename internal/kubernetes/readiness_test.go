@@ -0,0 +1,106 @@
+package kubernetes_test
+
+import (
+	"testing"
+
+	"github.com/porter-dev/porter/internal/helm/grapher"
+	"github.com/porter-dev/porter/internal/kubernetes"
+
+	appsv1 "k8s.io/api/apps/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func newDeployment(name string, replicas, updated, ready int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:       name,
+			Namespace:  "default",
+			Generation: 2,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+		},
+		Status: appsv1.DeploymentStatus{
+			ObservedGeneration: 2,
+			Replicas:           updated,
+			UpdatedReplicas:    updated,
+			ReadyReplicas:      ready,
+			AvailableReplicas:  ready,
+		},
+	}
+}
+
+func TestControllersReady(t *testing.T) {
+	k8sAgent := newAgentFixture(
+		t,
+		newDeployment("ready-web", 2, 2, 2),
+		newDeployment("crashing-web", 2, 2, 0),
+	)
+
+	controllers, err := k8sAgent.GetControllers([]grapher.Object{
+		{Kind: "Deployment", Name: "ready-web"},
+	}, "default")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if ready, reason := kubernetes.ControllersReady(controllers); !ready {
+		t.Errorf("expected controllers to be ready, got reason %s\n", reason)
+	}
+
+	controllers, err = k8sAgent.GetControllers([]grapher.Object{
+		{Kind: "Deployment", Name: "ready-web"},
+		{Kind: "Deployment", Name: "crashing-web"},
+	}, "default")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	ready, reason := kubernetes.ControllersReady(controllers)
+
+	if expReason := "deployment crashing-web has 0/2 available replicas"; ready || reason != expReason {
+		t.Errorf("expected reason %s, got ready %t with reason %s\n", expReason, ready, reason)
+	}
+}
+
+func TestControllerReadyObservedGeneration(t *testing.T) {
+	deployment := newDeployment("web", 1, 1, 1)
+	deployment.Status.ObservedGeneration = 1
+
+	ready, reason := kubernetes.ControllerReady(deployment)
+
+	if expReason := "deployment web has not observed its latest spec"; ready || reason != expReason {
+		t.Errorf("expected reason %s, got ready %t with reason %s\n", expReason, ready, reason)
+	}
+
+	deployment = newDeployment("web", 3, 1, 3)
+
+	ready, reason = kubernetes.ControllerReady(deployment)
+
+	if expReason := "deployment web has 1/3 updated replicas"; ready || reason != expReason {
+		t.Errorf("expected reason %s, got ready %t with reason %s\n", expReason, ready, reason)
+	}
+}
+
+func TestControllerReadyOldReplicas(t *testing.T) {
+	// the replica of the new spec is ready, but the replica of the old spec has not
+	// been terminated and is still ready
+	deployment := newDeployment("web", 1, 1, 1)
+	deployment.Status.Replicas = 2
+	deployment.Status.ReadyReplicas = 2
+
+	ready, reason := kubernetes.ControllerReady(deployment)
+
+	if expReason := "deployment web has 1 old replicas pending termination"; ready || reason != expReason {
+		t.Errorf("expected reason %s, got ready %t with reason %s\n", expReason, ready, reason)
+	}
+
+	deployment.Status.Replicas = 1
+	deployment.Status.ReadyReplicas = 1
+
+	if ready, reason := kubernetes.ControllerReady(deployment); !ready {
+		t.Errorf("expected deployment to be ready, got reason %s\n", reason)
+	}
+}
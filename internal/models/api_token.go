@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// APIToken type that extends gorm.Model. An API token is a persisted record of a
+// project-scoped token that has been issued to a user, so that the token can be
+// listed, expired and revoked.
+type APIToken struct {
+	gorm.Model
+
+	// UniqueID is stored in the token_id claim of the encoded token
+	UniqueID string `gorm:"unique"`
+
+	ProjectID       uint
+	CreatedByUserID uint
+	Name            string
+
+	// Kind is the role kind that the token is granted
+	Kind string
+
+	// PolicyID is the policy used when Kind is a custom role
+	PolicyID uint
+
+	// Expiry is the time after which the token is rejected. A nil expiry
+	// means that the token does not expire.
+	Expiry  *time.Time
+	Revoked bool
+}
+
+// APITokenExternal represents the APIToken type that is sent over REST
+type APITokenExternal struct {
+	ID              uint       `json:"id"`
+	ProjectID       uint       `json:"project_id"`
+	CreatedByUserID uint       `json:"created_by_user_id"`
+	Name            string     `json:"name"`
+	Kind            string     `json:"kind"`
+	PolicyID        uint       `json:"policy_id,omitempty"`
+	CreatedAt       time.Time  `json:"created_at"`
+	Expiry          *time.Time `json:"expiry,omitempty"`
+	Expired         bool       `json:"expired"`
+	Revoked         bool       `json:"revoked"`
+
+	// Token is the encoded token, which is only returned when the token is created
+	Token string `json:"token,omitempty"`
+}
+
+// Externalize generates an external APIToken to be shared over REST
+func (t *APIToken) Externalize() *APITokenExternal {
+	return &APITokenExternal{
+		ID:              t.ID,
+		ProjectID:       t.ProjectID,
+		CreatedByUserID: t.CreatedByUserID,
+		Name:            t.Name,
+		Kind:            t.Kind,
+		PolicyID:        t.PolicyID,
+		CreatedAt:       t.CreatedAt,
+		Expiry:          t.Expiry,
+		Expired:         t.IsExpired(),
+		Revoked:         t.Revoked,
+	}
+}
+
+// IsExpired returns true if the token has an expiry that has passed
+func (t *APIToken) IsExpired() bool {
+	return t.Expiry != nil && t.Expiry.Before(time.Now())
+}
+
+// IsValid returns true if the token has not been revoked and has not expired
+func (t *APIToken) IsValid() bool {
+	return !t.Revoked && !t.IsExpired()
+}
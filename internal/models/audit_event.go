@@ -0,0 +1,77 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// AuditEvent type that extends gorm.Model. An audit event records a single
+// mutating API call: who made it, which route and resources it targeted, and
+// whether it succeeded.
+type AuditEvent struct {
+	gorm.Model
+
+	ProjectID uint `gorm:"index"`
+	UserID    uint
+
+	// APITokenID is the unique id of the API token used for the request, if any
+	APITokenID string
+
+	Method string
+
+	// Route is the route pattern that matched the request, such as
+	// /api/projects/{project_id}/releases/{name}/upgrade
+	Route        string
+	ResourceType string
+
+	// ResourceBytes is the JSON-encoded map of resource identifiers (such as the
+	// cluster id, namespace and release name) that the request targeted
+	ResourceBytes []byte
+
+	Status int
+}
+
+// AuditEventExternal represents the AuditEvent type that is sent over REST
+type AuditEventExternal struct {
+	ID           uint              `json:"id"`
+	CreatedAt    time.Time         `json:"created_at"`
+	ProjectID    uint              `json:"project_id"`
+	UserID       uint              `json:"user_id"`
+	APITokenID   string            `json:"api_token_id,omitempty"`
+	Method       string            `json:"method"`
+	Route        string            `json:"route"`
+	ResourceType string            `json:"resource_type"`
+	Resources    map[string]string `json:"resources"`
+	Status       int               `json:"status"`
+	Success      bool              `json:"success"`
+}
+
+// GetResources decodes the stored resource identifiers
+func (e *AuditEvent) GetResources() map[string]string {
+	res := make(map[string]string)
+
+	if len(e.ResourceBytes) > 0 {
+		json.Unmarshal(e.ResourceBytes, &res)
+	}
+
+	return res
+}
+
+// Externalize generates an external AuditEvent to be shared over REST
+func (e *AuditEvent) Externalize() *AuditEventExternal {
+	return &AuditEventExternal{
+		ID:           e.ID,
+		CreatedAt:    e.CreatedAt,
+		ProjectID:    e.ProjectID,
+		UserID:       e.UserID,
+		APITokenID:   e.APITokenID,
+		Method:       e.Method,
+		Route:        e.Route,
+		ResourceType: e.ResourceType,
+		Resources:    e.GetResources(),
+		Status:       e.Status,
+		Success:      e.Status < 400,
+	}
+}
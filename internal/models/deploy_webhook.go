@@ -0,0 +1,63 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// DeployWebhookToken type that extends gorm.Model. A deploy webhook token is a
+// named token that can trigger deploys of a single release, and can be revoked
+// independently of the other tokens of the release.
+type DeployWebhookToken struct {
+	gorm.Model
+
+	ReleaseID uint `gorm:"index"`
+	ProjectID uint
+
+	Name            string
+	CreatedByUserID uint
+
+	// Token is the opaque token in the path of the webhook URL
+	Token string `gorm:"unique"`
+
+	// SigningSecret is used to verify the HMAC signature of webhook requests. If
+	// it is set, unsigned requests are rejected.
+	SigningSecret []byte
+
+	Revoked    bool
+	LastUsedAt *time.Time
+}
+
+// DeployWebhookTokenExternal represents the DeployWebhookToken type that is sent
+// over REST
+type DeployWebhookTokenExternal struct {
+	ID               uint       `json:"id"`
+	ReleaseID        uint       `json:"release_id"`
+	ProjectID        uint       `json:"project_id"`
+	Name             string     `json:"name"`
+	CreatedByUserID  uint       `json:"created_by_user_id"`
+	CreatedAt        time.Time  `json:"created_at"`
+	RequireSignature bool       `json:"require_signature"`
+	Revoked          bool       `json:"revoked"`
+	LastUsedAt       *time.Time `json:"last_used_at,omitempty"`
+
+	// Token and SigningSecret are only returned when the token is created
+	Token         string `json:"token,omitempty"`
+	SigningSecret string `json:"signing_secret,omitempty"`
+}
+
+// Externalize generates an external DeployWebhookToken to be shared over REST
+func (t *DeployWebhookToken) Externalize() *DeployWebhookTokenExternal {
+	return &DeployWebhookTokenExternal{
+		ID:               t.ID,
+		ReleaseID:        t.ReleaseID,
+		ProjectID:        t.ProjectID,
+		Name:             t.Name,
+		CreatedByUserID:  t.CreatedByUserID,
+		CreatedAt:        t.CreatedAt,
+		RequireSignature: len(t.SigningSecret) > 0,
+		Revoked:          t.Revoked,
+		LastUsedAt:       t.LastUsedAt,
+	}
+}
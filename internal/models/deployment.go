@@ -0,0 +1,147 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// The triggers of a deployment
+const (
+	DeploymentTriggerManual           = "manual"
+	DeploymentTriggerWebhook          = "webhook"
+	DeploymentTriggerRollback         = "rollback"
+	DeploymentTriggerBatchImageUpdate = "batch_image_update"
+	DeploymentTriggerEnvGroup         = "env_group"
+	DeploymentTriggerRollout          = "rollout"
+	DeploymentTriggerPromotion        = "promotion"
+)
+
+// DeploymentStatusFailed is the status of a deployment that Helm could not apply.
+// Other deployments use the status of the resulting Helm release.
+const DeploymentStatusFailed = "failed"
+
+// Deployment type that extends gorm.Model. A deployment records a single change to
+// a release, and is kept after Helm has pruned the matching revision.
+type Deployment struct {
+	gorm.Model
+
+	ProjectID uint   `gorm:"index"`
+	ClusterID uint   `gorm:"index:idx_deployment_release"`
+	Namespace string `gorm:"index:idx_deployment_release"`
+	Name      string `gorm:"index:idx_deployment_release"`
+
+	// Revision is the Helm revision that the deployment created, or 0 if the
+	// deployment failed before a revision was created
+	Revision int
+
+	// UserID and APITokenID identify the actor. Both are empty for deployments
+	// triggered by a deploy webhook.
+	UserID     uint
+	APITokenID string
+
+	// DeployWebhookTokenID is the named deploy webhook token that triggered the
+	// deployment, if any
+	DeployWebhookTokenID uint
+
+	Trigger string
+
+	ImageRepoURI string
+	ImageTag     string
+
+	// ImageDigest is the digest that the image tag was pinned to, if any
+	ImageDigest string
+	GitCommit   string
+
+	// ValuesDiffBytes is the JSON-encoded list of changes to the values of the
+	// release
+	ValuesDiffBytes []byte
+
+	Status string
+
+	// Info is any additional information about the status, such as an error message
+	Info string
+}
+
+// ValueChange is a single changed value of a release. Path is the dot-separated
+// path to the value, and Old or New is nil if the value was added or removed.
+type ValueChange struct {
+	Path string      `json:"path"`
+	Old  interface{} `json:"old,omitempty"`
+	New  interface{} `json:"new,omitempty"`
+}
+
+// DeploymentExternal represents the Deployment type that is sent over REST
+type DeploymentExternal struct {
+	ID                   uint           `json:"id"`
+	CreatedAt            time.Time      `json:"created_at"`
+	ProjectID            uint           `json:"project_id"`
+	ClusterID            uint           `json:"cluster_id"`
+	Namespace            string         `json:"namespace"`
+	Name                 string         `json:"name"`
+	Revision             int            `json:"revision"`
+	UserID               uint           `json:"user_id"`
+	APITokenID           string         `json:"api_token_id,omitempty"`
+	DeployWebhookTokenID uint           `json:"deploy_webhook_token_id,omitempty"`
+	Trigger              string         `json:"trigger"`
+	ImageRepoURI         string         `json:"image_repo_uri"`
+	ImageTag             string         `json:"image_tag"`
+	ImageDigest          string         `json:"image_digest,omitempty"`
+	GitCommit            string         `json:"git_commit,omitempty"`
+	ValuesDiff           []*ValueChange `json:"values_diff"`
+	Status               string         `json:"status"`
+	Info                 string         `json:"info,omitempty"`
+}
+
+// GetValuesDiff decodes the stored values diff
+func (d *Deployment) GetValuesDiff() []*ValueChange {
+	res := make([]*ValueChange, 0)
+
+	if len(d.ValuesDiffBytes) > 0 {
+		json.Unmarshal(d.ValuesDiffBytes, &res)
+	}
+
+	return res
+}
+
+// SetValuesDiff encodes the values diff for storage
+func (d *Deployment) SetValuesDiff(changes []*ValueChange) error {
+	if changes == nil {
+		changes = make([]*ValueChange, 0)
+	}
+
+	bytes, err := json.Marshal(changes)
+
+	if err != nil {
+		return err
+	}
+
+	d.ValuesDiffBytes = bytes
+
+	return nil
+}
+
+// Externalize generates an external Deployment to be shared over REST
+func (d *Deployment) Externalize() *DeploymentExternal {
+	return &DeploymentExternal{
+		ID:                   d.ID,
+		CreatedAt:            d.CreatedAt,
+		ProjectID:            d.ProjectID,
+		ClusterID:            d.ClusterID,
+		Namespace:            d.Namespace,
+		Name:                 d.Name,
+		Revision:             d.Revision,
+		UserID:               d.UserID,
+		APITokenID:           d.APITokenID,
+		DeployWebhookTokenID: d.DeployWebhookTokenID,
+		Trigger:              d.Trigger,
+		ImageRepoURI:         d.ImageRepoURI,
+		ImageTag:             d.ImageTag,
+		ImageDigest:          d.ImageDigest,
+		GitCommit:            d.GitCommit,
+		ValuesDiff:           d.GetValuesDiff(),
+		Status:               d.Status,
+		Info:                 d.Info,
+	}
+}
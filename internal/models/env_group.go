@@ -0,0 +1,184 @@
+package models
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// EnvGroup type that extends gorm.Model. An env group is a versioned set of
+// environment variables, stored in the cluster as a ConfigMap and a linked Secret of
+// the same name, that is shared by the releases of a namespace.
+type EnvGroup struct {
+	gorm.Model
+
+	ProjectID uint   `gorm:"index"`
+	ClusterID uint   `gorm:"uniqueIndex:idx_env_group_name"`
+	Namespace string `gorm:"uniqueIndex:idx_env_group_name"`
+	Name      string `gorm:"uniqueIndex:idx_env_group_name"`
+
+	// Version is the current version of the env group
+	Version uint
+
+	// Releases are the releases in the namespace that use the env group, and are
+	// upgraded when the env group changes
+	Releases []EnvGroupRelease
+}
+
+// EnvGroupExternal is an external EnvGroup to be shared over REST
+type EnvGroupExternal struct {
+	ID        uint      `json:"id"`
+	ProjectID uint      `json:"project_id"`
+	ClusterID uint      `json:"cluster_id"`
+	Namespace string    `json:"namespace"`
+	Name      string    `json:"name"`
+	Version   uint      `json:"version"`
+	Releases  []string  `json:"releases"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// Externalize generates an external EnvGroup to be shared over REST
+func (e *EnvGroup) Externalize() *EnvGroupExternal {
+	releases := make([]string, 0, len(e.Releases))
+
+	for _, rel := range e.Releases {
+		releases = append(releases, rel.ReleaseName)
+	}
+
+	sort.Strings(releases)
+
+	return &EnvGroupExternal{
+		ID:        e.ID,
+		ProjectID: e.ProjectID,
+		ClusterID: e.ClusterID,
+		Namespace: e.Namespace,
+		Name:      e.Name,
+		Version:   e.Version,
+		Releases:  releases,
+		CreatedAt: e.CreatedAt,
+		UpdatedAt: e.UpdatedAt,
+	}
+}
+
+// HasRelease returns true if the release is linked to the env group
+func (e *EnvGroup) HasRelease(name string) bool {
+	for _, rel := range e.Releases {
+		if rel.ReleaseName == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+// EnvGroupRelease links a release to the env group that it uses
+type EnvGroupRelease struct {
+	gorm.Model
+
+	EnvGroupID  uint `gorm:"index"`
+	ReleaseName string
+}
+
+// EnvGroupVersion type that extends gorm.Model. Every change to an env group,
+// including a rollback, creates a new version.
+type EnvGroupVersion struct {
+	gorm.Model
+
+	EnvGroupID uint `gorm:"index"`
+	Version    uint
+
+	// UserID is the user that created the version
+	UserID uint
+
+	// VariablesBytes is the JSON-encoded map of plain variables
+	VariablesBytes []byte
+
+	// ------------------------------------------------------------------
+	// All fields below this line are encrypted before storage
+	// ------------------------------------------------------------------
+
+	// SecretVariablesBytes is the JSON-encoded map of secret variables
+	SecretVariablesBytes []byte
+}
+
+// EnvGroupVersionExternal is an external EnvGroupVersion to be shared over REST.
+// The values of secret variables are not shared.
+type EnvGroupVersionExternal struct {
+	Version    uint              `json:"version"`
+	UserID     uint              `json:"user_id"`
+	Variables  map[string]string `json:"variables"`
+	SecretKeys []string          `json:"secret_keys"`
+	CreatedAt  time.Time         `json:"created_at"`
+}
+
+// Externalize generates an external EnvGroupVersion to be shared over REST
+func (v *EnvGroupVersion) Externalize() *EnvGroupVersionExternal {
+	vars, secrets, _ := v.GetVariables()
+
+	secretKeys := make([]string, 0, len(secrets))
+
+	for key := range secrets {
+		secretKeys = append(secretKeys, key)
+	}
+
+	sort.Strings(secretKeys)
+
+	return &EnvGroupVersionExternal{
+		Version:    v.Version,
+		UserID:     v.UserID,
+		Variables:  vars,
+		SecretKeys: secretKeys,
+		CreatedAt:  v.CreatedAt,
+	}
+}
+
+// SetVariables encodes the plain and secret variables of the version
+func (v *EnvGroupVersion) SetVariables(vars, secrets map[string]string) error {
+	if vars == nil {
+		vars = map[string]string{}
+	}
+
+	if secrets == nil {
+		secrets = map[string]string{}
+	}
+
+	varsBytes, err := json.Marshal(vars)
+
+	if err != nil {
+		return err
+	}
+
+	secretsBytes, err := json.Marshal(secrets)
+
+	if err != nil {
+		return err
+	}
+
+	v.VariablesBytes = varsBytes
+	v.SecretVariablesBytes = secretsBytes
+
+	return nil
+}
+
+// GetVariables decodes the plain and secret variables of the version
+func (v *EnvGroupVersion) GetVariables() (map[string]string, map[string]string, error) {
+	vars := map[string]string{}
+	secrets := map[string]string{}
+
+	if len(v.VariablesBytes) > 0 {
+		if err := json.Unmarshal(v.VariablesBytes, &vars); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	if len(v.SecretVariablesBytes) > 0 {
+		if err := json.Unmarshal(v.SecretVariablesBytes, &secrets); err != nil {
+			return nil, nil, err
+		}
+	}
+
+	return vars, secrets, nil
+}
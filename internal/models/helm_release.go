@@ -0,0 +1,31 @@
+package models
+
+import (
+	"gorm.io/gorm"
+)
+
+// HelmRelease type that extends gorm.Model. A HelmRelease is a single revision of a
+// Helm release, written by the "sql" Helm storage driver so that release history is
+// kept in the Porter database rather than in the cluster.
+type HelmRelease struct {
+	gorm.Model
+
+	// ClusterID, Namespace and Key identify a revision, where the key has the
+	// format sh.helm.release.v1.<name>.v<version>
+	ClusterID uint   `gorm:"uniqueIndex:idx_helm_release_key"`
+	Namespace string `gorm:"uniqueIndex:idx_helm_release_key"`
+	Key       string `gorm:"uniqueIndex:idx_helm_release_key"`
+
+	// The labels of the revision, which can be queried by Helm
+	Name    string `gorm:"index"`
+	Version int
+	Status  string
+	Owner   string
+
+	// ------------------------------------------------------------------
+	// All fields below this line are encrypted before storage
+	// ------------------------------------------------------------------
+
+	// Body is the gzipped, JSON-encoded Helm release
+	Body []byte
+}
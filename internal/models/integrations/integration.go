@@ -1,5 +1,7 @@
 package integrations
 
+import "strings"
+
 // IntegrationService is the name of a third-party service
 type IntegrationService string
 
@@ -18,8 +20,48 @@ const (
 	Github    IntegrationService = "github"
 	DockerHub IntegrationService = "dockerhub"
 	Docker    IntegrationService = "docker"
+	GHCR      IntegrationService = "ghcr"
+	ACR       IntegrationService = "acr"
+	Quay      IntegrationService = "quay"
+	Harbor    IntegrationService = "harbor"
 )
 
+// BasicRegistryServices are the registry services that authenticate with a basic
+// integration, and are listed through their native APIs instead of the Docker
+// registry API
+var BasicRegistryServices = map[IntegrationService]bool{
+	GHCR:   true,
+	ACR:    true,
+	Quay:   true,
+	Harbor: true,
+}
+
+// RegistryServiceFromURL returns the registry service that hosts a registry URL,
+// or an empty service if it cannot be determined from the URL. Harbor registries
+// are self-hosted, so they are never determined from the URL.
+func RegistryServiceFromURL(registryURL string) IntegrationService {
+	host := registryURL
+
+	if spl := strings.Split(host, "://"); len(spl) > 1 {
+		host = spl[1]
+	}
+
+	host = strings.Split(host, "/")[0]
+
+	switch {
+	case host == "ghcr.io":
+		return GHCR
+	case strings.HasSuffix(host, ".azurecr.io"):
+		return ACR
+	case host == "quay.io":
+		return Quay
+	case host == "index.docker.io":
+		return DockerHub
+	}
+
+	return ""
+}
+
 // PorterIntegration is a supported integration service, specifying an auth
 // mechanism and the category of integration. A single service can have multiple
 // auth mechanisms. For example, a GKE integration can have both an "oauth" mechanism
@@ -78,6 +120,26 @@ var PorterRegistryIntegrations = []PorterIntegration{
 		Category:      "registry",
 		Service:       Docker,
 	},
+	PorterIntegration{
+		AuthMechanism: "basic",
+		Category:      "registry",
+		Service:       GHCR,
+	},
+	PorterIntegration{
+		AuthMechanism: "basic",
+		Category:      "registry",
+		Service:       ACR,
+	},
+	PorterIntegration{
+		AuthMechanism: "basic",
+		Category:      "registry",
+		Service:       Quay,
+	},
+	PorterIntegration{
+		AuthMechanism: "basic",
+		Category:      "registry",
+		Service:       Harbor,
+	},
 }
 
 // PorterHelmRepoIntegrations are the supported helm repo integrations
@@ -0,0 +1,52 @@
+package integrations
+
+import "gorm.io/gorm"
+
+// WebhookIntegration is a generic outbound webhook that receives deployment
+// notifications as signed JSON payloads
+type WebhookIntegration struct {
+	gorm.Model
+
+	// The id of the user that linked this webhook
+	UserID uint `json:"user_id"`
+
+	// The project that this integration belongs to
+	ProjectID uint `json:"project_id"`
+
+	// A human-readable name for the webhook
+	Name string `json:"name"`
+
+	// ------------------------------------------------------------------
+	// All fields below encrypted before storage.
+	// ------------------------------------------------------------------
+
+	// The URL that notifications are posted to
+	URL []byte
+
+	// The secret used to sign the payloads with HMAC-SHA256
+	Secret []byte
+}
+
+// WebhookIntegrationExternal is an external WebhookIntegration to be shared over
+// rest
+type WebhookIntegrationExternal struct {
+	ID uint `json:"id"`
+
+	ProjectID uint `json:"project_id"`
+
+	// A human-readable name for the webhook
+	Name string `json:"name"`
+
+	// The signing secret, which is only returned when the webhook is created
+	Secret string `json:"secret,omitempty"`
+}
+
+// Externalize generates an external WebhookIntegration to be shared over
+// rest
+func (w *WebhookIntegration) Externalize() *WebhookIntegrationExternal {
+	return &WebhookIntegrationExternal{
+		ID:        w.ID,
+		ProjectID: w.ProjectID,
+		Name:      w.Name,
+	}
+}
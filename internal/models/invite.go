@@ -17,6 +17,9 @@ type Invite struct {
 	// Kind is the role kind that this refers to
 	Kind string
 
+	// PolicyID is the policy used when Kind is a custom role
+	PolicyID uint
+
 	ProjectID uint
 	UserID    uint
 }
@@ -29,6 +32,7 @@ type InviteExternal struct {
 	Email    string `json:"email"`
 	Accepted bool   `json:"accepted"`
 	Kind     string `json:"kind"`
+	PolicyID uint   `json:"policy_id,omitempty"`
 }
 
 // Externalize generates an external Invite to be shared over REST
@@ -40,6 +44,7 @@ func (i *Invite) Externalize() *InviteExternal {
 		Expired:  i.IsExpired(),
 		Accepted: i.IsAccepted(),
 		Kind:     i.Kind,
+		PolicyID: i.PolicyID,
 	}
 }
 
@@ -1,6 +1,18 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"encoding/json"
+
+	"gorm.io/gorm"
+)
+
+const (
+	// NotificationTriggerManual is a deploy started by a user
+	NotificationTriggerManual = "manual"
+
+	// NotificationTriggerWebhook is a deploy started by a deploy webhook
+	NotificationTriggerWebhook = "webhook"
+)
 
 type NotificationConfig struct {
 	gorm.Model
@@ -9,18 +21,167 @@ type NotificationConfig struct {
 
 	Success bool
 	Failure bool
+
+	// Email is true if notifications should also be emailed to project collaborators
+	Email bool
+
+	// WebhookIDs is a JSON-encoded list of the webhook integrations that should
+	// receive notifications for this release
+	WebhookIDs []byte
+
+	// Namespaces is a JSON-encoded list of namespaces to notify for. All namespaces
+	// are notified if the list is empty.
+	Namespaces []byte
+
+	// Triggers is a JSON-encoded list of deploy triggers to notify for. All triggers
+	// are notified if the list is empty.
+	Triggers []byte
+
+	// FailureThreshold is the number of consecutive failures before a failure is
+	// notified. Values of 0 and 1 notify on every failure.
+	FailureThreshold uint
+
+	// ConsecutiveFailures is the number of deploys that have failed since the last
+	// successful deploy
+	ConsecutiveFailures uint
+
+	// QuietHoursStart and QuietHoursEnd are times of day in the form "15:04" during
+	// which notifications are not sent, evaluated in QuietHoursTimezone
+	QuietHoursStart    string
+	QuietHoursEnd      string
+	QuietHoursTimezone string
+
+	// SlackIntegrationIDs is a JSON-encoded list of the slack integrations that
+	// should receive notifications. All slack integrations in the project receive
+	// notifications if the list is empty.
+	SlackIntegrationIDs []byte
+}
+
+// QuietHours is a daily window in which notifications are not sent
+type QuietHours struct {
+	Start    string `json:"start"`
+	End      string `json:"end"`
+	Timezone string `json:"timezone"`
 }
 
 type NotificationConfigExternal struct {
-	Enabled bool `json:"enabled"`
-	Success bool `json:"success"`
-	Failure bool `json:"failure"`
+	Enabled    bool   `json:"enabled"`
+	Success    bool   `json:"success"`
+	Failure    bool   `json:"failure"`
+	Email      bool   `json:"email"`
+	WebhookIDs []uint `json:"webhook_ids"`
+
+	Namespaces          []string    `json:"namespaces"`
+	Triggers            []string    `json:"triggers"`
+	FailureThreshold    uint        `json:"failure_threshold"`
+	ConsecutiveFailures uint        `json:"consecutive_failures"`
+	QuietHours          *QuietHours `json:"quiet_hours,omitempty"`
+	SlackIntegrationIDs []uint      `json:"slack_integration_ids"`
 }
 
 func (conf *NotificationConfig) Externalize() *NotificationConfigExternal {
-	return &NotificationConfigExternal{
-		Enabled: conf.Enabled,
-		Success: conf.Success,
-		Failure: conf.Failure,
+	res := &NotificationConfigExternal{
+		Enabled:             conf.Enabled,
+		Success:             conf.Success,
+		Failure:             conf.Failure,
+		Email:               conf.Email,
+		WebhookIDs:          conf.GetWebhookIDs(),
+		Namespaces:          getStringList(conf.Namespaces),
+		Triggers:            getStringList(conf.Triggers),
+		FailureThreshold:    conf.FailureThreshold,
+		ConsecutiveFailures: conf.ConsecutiveFailures,
+		SlackIntegrationIDs: getUintList(conf.SlackIntegrationIDs),
+	}
+
+	if conf.QuietHoursStart != "" && conf.QuietHoursEnd != "" {
+		res.QuietHours = &QuietHours{
+			Start:    conf.QuietHoursStart,
+			End:      conf.QuietHoursEnd,
+			Timezone: conf.QuietHoursTimezone,
+		}
+	}
+
+	return res
+}
+
+// GetWebhookIDs returns the ids of the webhook integrations selected for this
+// release, or an empty list if none are selected
+func (conf *NotificationConfig) GetWebhookIDs() []uint {
+	return getUintList(conf.WebhookIDs)
+}
+
+// SetWebhookIDs sets the ids of the webhook integrations selected for this release
+func (conf *NotificationConfig) SetWebhookIDs(ids []uint) error {
+	bytes, err := setUintList(ids)
+
+	if err != nil {
+		return err
+	}
+
+	conf.WebhookIDs = bytes
+
+	return nil
+}
+
+// SetRules sets the namespace, trigger and slack routing rules of the config
+func (conf *NotificationConfig) SetRules(namespaces, triggers []string, slackIntegrationIDs []uint) error {
+	var err error
+
+	if conf.Namespaces, err = setStringList(namespaces); err != nil {
+		return err
+	}
+
+	if conf.Triggers, err = setStringList(triggers); err != nil {
+		return err
+	}
+
+	if conf.SlackIntegrationIDs, err = setUintList(slackIntegrationIDs); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+func getUintList(bytes []byte) []uint {
+	res := make([]uint, 0)
+
+	if len(bytes) == 0 {
+		return res
+	}
+
+	if err := json.Unmarshal(bytes, &res); err != nil {
+		return make([]uint, 0)
+	}
+
+	return res
+}
+
+func setUintList(list []uint) ([]byte, error) {
+	if list == nil {
+		list = make([]uint, 0)
+	}
+
+	return json.Marshal(list)
+}
+
+func getStringList(bytes []byte) []string {
+	res := make([]string, 0)
+
+	if len(bytes) == 0 {
+		return res
 	}
+
+	if err := json.Unmarshal(bytes, &res); err != nil {
+		return make([]string, 0)
+	}
+
+	return res
+}
+
+func setStringList(list []string) ([]byte, error) {
+	if list == nil {
+		list = make([]string, 0)
+	}
+
+	return json.Marshal(list)
 }
@@ -0,0 +1,55 @@
+package models
+
+import (
+	"encoding/json"
+
+	"github.com/porter-dev/porter/api/types"
+	"gorm.io/gorm"
+)
+
+// Policy type that extends gorm.Model. A policy is a named, project-scoped
+// policy document that can be assigned to collaborators through a custom role.
+type Policy struct {
+	gorm.Model
+
+	ProjectID uint
+	Name      string
+
+	// PolicyBytes is the JSON-encoded types.Policy
+	PolicyBytes []byte
+}
+
+// PolicyExternal represents the Policy type that is sent over REST
+type PolicyExternal struct {
+	ID        uint         `json:"id"`
+	ProjectID uint         `json:"project_id"`
+	Name      string       `json:"name"`
+	Policy    types.Policy `json:"policy"`
+}
+
+// GetPolicy decodes the stored policy document
+func (p *Policy) GetPolicy() (types.Policy, error) {
+	res := make(types.Policy, 0)
+
+	if len(p.PolicyBytes) == 0 {
+		return res, nil
+	}
+
+	if err := json.Unmarshal(p.PolicyBytes, &res); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// Externalize generates an external Policy to be shared over REST
+func (p *Policy) Externalize() *PolicyExternal {
+	policy, _ := p.GetPolicy()
+
+	return &PolicyExternal{
+		ID:        p.ID,
+		ProjectID: p.ProjectID,
+		Name:      p.Name,
+		Policy:    policy,
+	}
+}
@@ -0,0 +1,183 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// The states of a preview environment
+const (
+	// PreviewEnvironmentStatusPending is the status of a preview environment that is
+	// installed, and waits for the preview workflow to deploy the pull request
+	PreviewEnvironmentStatusPending  = "pending"
+	PreviewEnvironmentStatusDeployed = "deployed"
+	PreviewEnvironmentStatusFailed   = "failed"
+	PreviewEnvironmentStatusDeleted  = "deleted"
+)
+
+// PreviewEnvironmentConfig type that extends gorm.Model. It enables preview
+// environments for a release that is built from a GitHub repository: each pull
+// request against the repository gets a copy of the release in its own namespace.
+type PreviewEnvironmentConfig struct {
+	gorm.Model
+
+	ProjectID uint `gorm:"index"`
+	ClusterID uint
+	ReleaseID uint `gorm:"uniqueIndex"`
+
+	// The namespace and name of the release that is copied
+	Namespace   string
+	ReleaseName string
+
+	// The Helm storage driver of the release
+	Storage string
+
+	// The git repo in ${owner}/${repo} form
+	GitRepo              string `gorm:"index"`
+	GithubInstallationID uint
+
+	// GitBranch is the branch that pull requests must be opened against
+	GitBranch string
+
+	// BaseDomain is the domain that preview hosts are created under. If empty, the
+	// preview environments are not exposed through an ingress.
+	BaseDomain string
+}
+
+// PreviewEnvironmentConfigExternal is an external PreviewEnvironmentConfig to be
+// shared over REST
+type PreviewEnvironmentConfigExternal struct {
+	ID          uint      `json:"id"`
+	ProjectID   uint      `json:"project_id"`
+	ClusterID   uint      `json:"cluster_id"`
+	ReleaseID   uint      `json:"release_id"`
+	Namespace   string    `json:"namespace"`
+	ReleaseName string    `json:"release_name"`
+	GitRepo     string    `json:"git_repo"`
+	GitBranch   string    `json:"git_branch"`
+	BaseDomain  string    `json:"base_domain,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// Externalize generates an external PreviewEnvironmentConfig to be shared over REST
+func (p *PreviewEnvironmentConfig) Externalize() *PreviewEnvironmentConfigExternal {
+	return &PreviewEnvironmentConfigExternal{
+		ID:          p.ID,
+		ProjectID:   p.ProjectID,
+		ClusterID:   p.ClusterID,
+		ReleaseID:   p.ReleaseID,
+		Namespace:   p.Namespace,
+		ReleaseName: p.ReleaseName,
+		GitRepo:     p.GitRepo,
+		GitBranch:   p.GitBranch,
+		BaseDomain:  p.BaseDomain,
+		CreatedAt:   p.CreatedAt,
+	}
+}
+
+// PreviewNamespace returns the namespace of the preview environment of a pull
+// request
+func (p *PreviewEnvironmentConfig) PreviewNamespace(prNumber int) string {
+	return PreviewNamespace(p.ID, p.ReleaseName, prNumber)
+}
+
+// PreviewHost returns the host of the preview environment of a pull request, or an
+// empty string if the config has no base domain
+func (p *PreviewEnvironmentConfig) PreviewHost(prNumber int) string {
+	if p.BaseDomain == "" {
+		return ""
+	}
+
+	return fmt.Sprintf("%s.%s", p.PreviewNamespace(prNumber), p.BaseDomain)
+}
+
+// maxPreviewReleaseNameLength is the length that release names are truncated to in
+// preview namespaces, so that namespaces are valid labels of at most 63 characters
+// for pull request numbers below 10^7
+const maxPreviewReleaseNameLength = 63 - len("pr--") - 7 - previewHashLength - 1
+
+// previewHashLength is the length of the hash of the config ID in preview namespaces
+const previewHashLength = 6
+
+// PreviewNamespace returns the namespace of the preview environment of a release for
+// a pull request. The namespace contains a hash of the config ID, since releases of
+// different namespaces or clusters can share a name.
+func PreviewNamespace(configID uint, releaseName string, prNumber int) string {
+	ns := fmt.Sprintf("pr-%d-%s", prNumber, PreviewNamespaceSuffix(configID, releaseName))
+
+	if len(ns) > 63 {
+		ns = strings.TrimRight(ns[:63], "-")
+	}
+
+	return ns
+}
+
+// PreviewNamespaceSuffix returns the part of a preview namespace that follows the
+// pull request number. It is also used by the preview GitHub workflow, which only
+// knows the pull request number when it runs.
+func PreviewNamespaceSuffix(configID uint, releaseName string) string {
+	sum := sha256.Sum256([]byte(strconv.FormatUint(uint64(configID), 10)))
+
+	if len(releaseName) > maxPreviewReleaseNameLength {
+		releaseName = strings.TrimRight(releaseName[:maxPreviewReleaseNameLength], "-")
+	}
+
+	return fmt.Sprintf("%s-%s", hex.EncodeToString(sum[:])[:previewHashLength], releaseName)
+}
+
+// PreviewEnvironment type that extends gorm.Model. It is the copy of a release that
+// is deployed for a single pull request.
+type PreviewEnvironment struct {
+	gorm.Model
+
+	PreviewEnvironmentConfigID uint `gorm:"index"`
+	PullRequestNumber          int
+
+	Namespace string `gorm:"index"`
+	HeadRef   string
+	HeadSHA   string
+
+	// URL is the URL of the preview environment, if the config has a base domain
+	URL string
+
+	Status string
+
+	// Info is any additional information about the status, such as an error message
+	Info string
+}
+
+// PreviewEnvironmentExternal is an external PreviewEnvironment to be shared over REST
+type PreviewEnvironmentExternal struct {
+	ID                uint      `json:"id"`
+	PullRequestNumber int       `json:"pull_request_number"`
+	Namespace         string    `json:"namespace"`
+	HeadRef           string    `json:"head_ref"`
+	HeadSHA           string    `json:"head_sha"`
+	URL               string    `json:"url,omitempty"`
+	Status            string    `json:"status"`
+	Info              string    `json:"info,omitempty"`
+	CreatedAt         time.Time `json:"created_at"`
+	UpdatedAt         time.Time `json:"updated_at"`
+}
+
+// Externalize generates an external PreviewEnvironment to be shared over REST
+func (p *PreviewEnvironment) Externalize() *PreviewEnvironmentExternal {
+	return &PreviewEnvironmentExternal{
+		ID:                p.ID,
+		PullRequestNumber: p.PullRequestNumber,
+		Namespace:         p.Namespace,
+		HeadRef:           p.HeadRef,
+		HeadSHA:           p.HeadSHA,
+		URL:               p.URL,
+		Status:            p.Status,
+		Info:              p.Info,
+		CreatedAt:         p.CreatedAt,
+		UpdatedAt:         p.UpdatedAt,
+	}
+}
@@ -0,0 +1,41 @@
+package models_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+func TestPreviewNamespace(t *testing.T) {
+	if ns := models.PreviewNamespace(1, "web", 12); ns != "pr-12-6b86b2-web" {
+		t.Errorf("incorrect namespace: expected %s, got %s", "pr-12-6b86b2-web", ns)
+	}
+
+	// releases of different configs can share a name
+	if models.PreviewNamespace(1, "web", 12) == models.PreviewNamespace(2, "web", 12) {
+		t.Errorf("namespaces of different configs are equal")
+	}
+
+	// release names are at most 53 characters
+	longName := strings.Repeat("a", 44) + "-" + strings.Repeat("b", 8)
+
+	for _, prNumber := range []int{1, 9999999, 123456789} {
+		ns := models.PreviewNamespace(1, longName, prNumber)
+
+		if len(ns) > 63 {
+			t.Errorf("namespace %s is longer than 63 characters", ns)
+		}
+
+		if strings.HasSuffix(ns, "-") {
+			t.Errorf("namespace %s ends with a dash", ns)
+		}
+	}
+
+	// the workflow computes the namespace from the suffix
+	ns := models.PreviewNamespace(1, longName, 9999999)
+
+	if expNS := "pr-9999999-" + models.PreviewNamespaceSuffix(1, longName); ns != expNS {
+		t.Errorf("incorrect namespace: expected %s, got %s", expNS, ns)
+	}
+}
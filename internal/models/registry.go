@@ -24,6 +24,10 @@ type Registry struct {
 	// The infra id, if registry was provisioned with Porter
 	InfraID uint `json:"infra_id"`
 
+	// Service is set for registries that authenticate with a basic integration but
+	// are listed through the native API of the service, such as GHCR or Harbor
+	Service integrations.IntegrationService `json:"service"`
+
 	// ------------------------------------------------------------------
 	// All fields below this line are encrypted before storage
 	// ------------------------------------------------------------------
@@ -61,7 +65,9 @@ type RegistryExternal struct {
 func (r *Registry) Externalize() *RegistryExternal {
 	var serv integrations.IntegrationService
 
-	if r.AWSIntegrationID != 0 {
+	if r.Service != "" {
+		serv = r.Service
+	} else if r.AWSIntegrationID != 0 {
 		serv = integrations.ECR
 	} else if r.GCPIntegrationID != 0 {
 		serv = integrations.GCR
@@ -23,14 +23,38 @@ type Release struct {
 	GitActionConfig    GitActionConfig `json:"git_action_config"`
 	EventContainer     uint
 	NotificationConfig uint
+
+	// AutoRollback rolls an upgrade back to the previous revision if the controllers
+	// of the new revision are not ready within AutoRollbackTimeout seconds
+	AutoRollback        bool `json:"auto_rollback"`
+	AutoRollbackTimeout uint `json:"auto_rollback_timeout"`
+
+	// PinImageDigest resolves the tag of each image that is deployed by a webhook or
+	// batch image update to its digest, so that a tag that is pushed again does not
+	// change the image that is running
+	PinImageDigest bool `json:"pin_image_digest"`
+
+	// VulnerabilityBlockSeverity blocks deploys by a webhook or batch image update of
+	// images with vulnerabilities of this severity or above, if set
+	VulnerabilityBlockSeverity string `json:"vulnerability_block_severity"`
 }
 
+// DefaultAutoRollbackTimeout is the number of seconds that the controllers of a new
+// revision have to become ready if the release does not set a timeout
+const DefaultAutoRollbackTimeout = 300
+
 // ReleaseExternal represents the Release type that is sent over REST
 type ReleaseExternal struct {
 	ID uint `json:"id"`
 
 	WebhookToken    string                   `json:"webhook_token"`
 	GitActionConfig *GitActionConfigExternal `json:"git_action_config,omitempty"`
+
+	AutoRollback        bool `json:"auto_rollback"`
+	AutoRollbackTimeout uint `json:"auto_rollback_timeout"`
+
+	PinImageDigest             bool   `json:"pin_image_digest"`
+	VulnerabilityBlockSeverity string `json:"vulnerability_block_severity,omitempty"`
 }
 
 // Externalize generates an external User to be shared over REST
@@ -39,5 +63,21 @@ func (r *Release) Externalize() *ReleaseExternal {
 		ID:              r.ID,
 		WebhookToken:    r.WebhookToken,
 		GitActionConfig: r.GitActionConfig.Externalize(),
+
+		AutoRollback:        r.AutoRollback,
+		AutoRollbackTimeout: r.GetAutoRollbackTimeout(),
+
+		PinImageDigest:             r.PinImageDigest,
+		VulnerabilityBlockSeverity: r.VulnerabilityBlockSeverity,
+	}
+}
+
+// GetAutoRollbackTimeout returns the number of seconds that the controllers of a
+// new revision have to become ready before the upgrade is rolled back
+func (r *Release) GetAutoRollbackTimeout() uint {
+	if r.AutoRollbackTimeout == 0 {
+		return DefaultAutoRollbackTimeout
 	}
+
+	return r.AutoRollbackTimeout
 }
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RetentionPolicy type that extends gorm.Model. A retention policy deletes the old
+// images of a registry repository, keeping the most recent images, the images that
+// are younger than a maximum age, and optionally the images that are deployed in
+// any cluster of the project.
+type RetentionPolicy struct {
+	gorm.Model
+
+	ProjectID      uint `gorm:"index"`
+	RegistryID     uint `gorm:"index"`
+	RepositoryName string
+
+	// KeepLast is the number of most recently pushed tags to keep, where 0 does not
+	// keep any tags by count
+	KeepLast uint
+
+	// MaxAgeDays is the number of days after which an image may be deleted, where 0
+	// allows images of any age to be deleted
+	MaxAgeDays uint
+
+	// KeepDeployed keeps the images that are referenced by a workload in any
+	// cluster of the project
+	KeepDeployed bool
+
+	// Enabled policies are applied by the background job. Policies are created
+	// disabled, so that the dry-run report can be reviewed before images are
+	// deleted.
+	Enabled bool
+
+	LastRunAt      *time.Time
+	LastRunDeleted uint
+
+	// LastRunError is the reason that the last run did not delete images
+	LastRunError string
+}
+
+// RetentionPolicyExternal represents the RetentionPolicy type that is sent over
+// REST
+type RetentionPolicyExternal struct {
+	ID             uint       `json:"id"`
+	ProjectID      uint       `json:"project_id"`
+	RegistryID     uint       `json:"registry_id"`
+	RepositoryName string     `json:"repository_name"`
+	KeepLast       uint       `json:"keep_last"`
+	MaxAgeDays     uint       `json:"max_age_days"`
+	KeepDeployed   bool       `json:"keep_deployed"`
+	Enabled        bool       `json:"enabled"`
+	LastRunAt      *time.Time `json:"last_run_at,omitempty"`
+	LastRunDeleted uint       `json:"last_run_deleted"`
+	LastRunError   string     `json:"last_run_error,omitempty"`
+}
+
+// Externalize generates an external RetentionPolicy to be shared over REST
+func (p *RetentionPolicy) Externalize() *RetentionPolicyExternal {
+	return &RetentionPolicyExternal{
+		ID:             p.ID,
+		ProjectID:      p.ProjectID,
+		RegistryID:     p.RegistryID,
+		RepositoryName: p.RepositoryName,
+		KeepLast:       p.KeepLast,
+		MaxAgeDays:     p.MaxAgeDays,
+		KeepDeployed:   p.KeepDeployed,
+		Enabled:        p.Enabled,
+		LastRunAt:      p.LastRunAt,
+		LastRunDeleted: p.LastRunDeleted,
+		LastRunError:   p.LastRunError,
+	}
+}
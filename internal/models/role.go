@@ -9,6 +9,9 @@ const (
 	RoleAdmin     string = "admin"
 	RoleDeveloper string = "developer"
 	RoleViewer    string = "viewer"
+
+	// RoleCustom roles read their permissions from a stored Policy
+	RoleCustom string = "custom"
 )
 
 // Role type that extends gorm.Model
@@ -18,6 +21,9 @@ type Role struct {
 	Kind      string `json:"kind"`
 	UserID    uint   `json:"user_id"`
 	ProjectID uint   `json:"project_id"`
+
+	// PolicyID is the ID of the Policy used by a custom role
+	PolicyID uint `json:"policy_id"`
 }
 
 // RoleExternal represents the Role type that is sent over REST
@@ -26,6 +32,7 @@ type RoleExternal struct {
 	Kind      string `json:"kind"`
 	UserID    uint   `json:"user_id"`
 	ProjectID uint   `json:"project_id"`
+	PolicyID  uint   `json:"policy_id,omitempty"`
 }
 
 // Externalize generates an external Role to be shared over REST
@@ -35,5 +42,6 @@ func (r *Role) Externalize() *RoleExternal {
 		Kind:      r.Kind,
 		UserID:    r.UserID,
 		ProjectID: r.ProjectID,
+		PolicyID:  r.PolicyID,
 	}
 }
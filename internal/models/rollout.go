@@ -0,0 +1,131 @@
+package models
+
+import (
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// The states of a rollout
+const (
+	RolloutStatusProgressing = "progressing"
+	RolloutStatusPromoted    = "promoted"
+	RolloutStatusRolledBack  = "rolled_back"
+	RolloutStatusFailed      = "failed"
+	RolloutStatusCanceled    = "canceled"
+)
+
+// Rollout type that extends gorm.Model. A rollout upgrades a web release by first
+// sending part of its traffic to a canary copy of the release that runs the new
+// values, and promotes the new values to the release if the error rate and latency
+// of the canary stay under the thresholds.
+type Rollout struct {
+	gorm.Model
+
+	ProjectID   uint   `gorm:"index"`
+	ClusterID   uint   `gorm:"index:idx_rollout_release"`
+	Namespace   string `gorm:"index:idx_rollout_release"`
+	ReleaseName string `gorm:"index:idx_rollout_release"`
+
+	// Storage is the Helm storage driver of the release, which the canary release
+	// is stored with
+	Storage string
+
+	// Strategy is either canary or blue_green
+	Strategy string
+
+	// Steps are the comma-separated percentages of traffic that are sent to the
+	// canary, and Weight is the current percentage
+	Steps  string
+	Weight uint
+
+	// StepDuration is the number of seconds that each step is analyzed for
+	StepDuration uint
+
+	// MaxErrorRate is the highest percentage of 5xx responses, and MaxLatency the
+	// highest average latency in seconds, that the canary may serve
+	MaxErrorRate float64
+	MaxLatency   float64
+
+	// Revision is the revision of the release that the new values were promoted to
+	Revision int
+
+	UserID uint
+
+	Status string `gorm:"index"`
+
+	// Info is the reason that a rollout was rolled back or failed
+	Info string
+
+	FinishedAt *time.Time
+}
+
+// RolloutExternal represents the Rollout type that is sent over REST
+type RolloutExternal struct {
+	ID           uint       `json:"id"`
+	CreatedAt    time.Time  `json:"created_at"`
+	ProjectID    uint       `json:"project_id"`
+	ClusterID    uint       `json:"cluster_id"`
+	Namespace    string     `json:"namespace"`
+	ReleaseName  string     `json:"release_name"`
+	Strategy     string     `json:"strategy"`
+	Steps        []uint     `json:"steps"`
+	Weight       uint       `json:"weight"`
+	StepDuration uint       `json:"step_duration"`
+	MaxErrorRate float64    `json:"max_error_rate"`
+	MaxLatency   float64    `json:"max_latency"`
+	Revision     int        `json:"revision,omitempty"`
+	UserID       uint       `json:"user_id"`
+	Status       string     `json:"status"`
+	Info         string     `json:"info,omitempty"`
+	FinishedAt   *time.Time `json:"finished_at,omitempty"`
+}
+
+// GetSteps decodes the stored steps
+func (r *Rollout) GetSteps() []uint {
+	res := make([]uint, 0)
+
+	for _, step := range strings.Split(r.Steps, ",") {
+		if weight, err := strconv.ParseUint(step, 10, 64); err == nil {
+			res = append(res, uint(weight))
+		}
+	}
+
+	return res
+}
+
+// SetSteps encodes the steps for storage
+func (r *Rollout) SetSteps(steps []uint) {
+	strs := make([]string, 0, len(steps))
+
+	for _, step := range steps {
+		strs = append(strs, strconv.FormatUint(uint64(step), 10))
+	}
+
+	r.Steps = strings.Join(strs, ",")
+}
+
+// Externalize generates an external Rollout to be shared over REST
+func (r *Rollout) Externalize() *RolloutExternal {
+	return &RolloutExternal{
+		ID:           r.ID,
+		CreatedAt:    r.CreatedAt,
+		ProjectID:    r.ProjectID,
+		ClusterID:    r.ClusterID,
+		Namespace:    r.Namespace,
+		ReleaseName:  r.ReleaseName,
+		Strategy:     r.Strategy,
+		Steps:        r.GetSteps(),
+		Weight:       r.Weight,
+		StepDuration: r.StepDuration,
+		MaxErrorRate: r.MaxErrorRate,
+		MaxLatency:   r.MaxLatency,
+		Revision:     r.Revision,
+		UserID:       r.UserID,
+		Status:       r.Status,
+		Info:         r.Info,
+		FinishedAt:   r.FinishedAt,
+	}
+}
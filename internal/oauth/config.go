@@ -6,6 +6,7 @@ import (
 	"encoding/base64"
 	"time"
 
+	"github.com/porter-dev/porter/internal/models"
 	"github.com/porter-dev/porter/internal/models/integrations"
 	"github.com/porter-dev/porter/internal/repository"
 
@@ -129,9 +130,15 @@ func MakeUpdateOAuthIntegrationTokenFunction(
 		o.RefreshToken = refreshToken
 		o.Expiry = expiry
 
-		_, err := repo.OAuthIntegration.UpdateOAuthIntegration(o)
+		if _, err := repo.OAuthIntegration.UpdateOAuthIntegration(o); err != nil {
+			return err
+		}
 
-		return err
+		// clusters that authenticate with the integration must reconnect with the
+		// new access token
+		return repository.TouchClusters(repo.Cluster, o.ProjectID, func(cluster *models.Cluster) bool {
+			return cluster.DOIntegrationID == o.ID
+		})
 	}
 }
 
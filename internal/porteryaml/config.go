@@ -0,0 +1,229 @@
+package porteryaml
+
+import (
+	"fmt"
+	"io/ioutil"
+	"path/filepath"
+	"strings"
+
+	"github.com/porter-dev/porter/internal/helm/diff"
+	"github.com/porter-dev/porter/internal/templater/utils"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"sigs.k8s.io/yaml"
+)
+
+// ConfigVersion is the only supported version of the porter.yaml format
+const ConfigVersion = "v1"
+
+// ApplicationCharts are the Porter application templates
+var ApplicationCharts = map[string]bool{"web": true, "worker": true, "job": true}
+
+// Config is the desired state of the applications in a namespace, as declared in a
+// porter.yaml file
+type Config struct {
+	Version string `json:"version"`
+
+	// Project and Cluster override the project and cluster of the CLI config
+	Project   uint   `json:"project,omitempty"`
+	Cluster   uint   `json:"cluster,omitempty"`
+	Namespace string `json:"namespace"`
+
+	// Prune deletes the applications in the namespace that are not declared in the
+	// file. Only releases of Porter application templates are deleted.
+	Prune bool `json:"prune,omitempty"`
+
+	Apps []*App `json:"apps"`
+}
+
+// App is an application declared in a porter.yaml file
+type App struct {
+	Name string `json:"name"`
+
+	// Chart is the name of the Porter template, such as web, worker or job
+	Chart string `json:"chart"`
+
+	// Version is the version of the template. New applications are created with
+	// the latest version if it is empty, and existing applications keep their
+	// version unless it is set.
+	Version string `json:"version,omitempty"`
+
+	// Values are merged over the values of the deployed release, so values that
+	// are not declared, such as an image tag updated by CI, are left unchanged.
+	// Values read from ValuesFile are overridden by Values.
+	Values     map[string]interface{} `json:"values,omitempty"`
+	ValuesFile string                 `json:"values_file,omitempty"`
+
+	// Image is the image to deploy, in repository:tag format. Applications are
+	// either deployed from an image or built from a Github repository.
+	Image string `json:"image,omitempty"`
+	Build *Build `json:"build,omitempty"`
+
+	EnvGroups []string `json:"env_groups,omitempty"`
+	Domains   []string `json:"domains,omitempty"`
+}
+
+// Build are the settings for building an application from a Github repository.
+// They are only used when the application is created.
+type Build struct {
+	Repo        string `json:"repo"`
+	Branch      string `json:"branch"`
+	Dockerfile  string `json:"dockerfile,omitempty"`
+	Folder      string `json:"folder,omitempty"`
+	RegistryURL string `json:"registry_url,omitempty"`
+}
+
+// ReadConfig reads and validates a porter.yaml file. Values files are read relative
+// to the directory of the file.
+func ReadConfig(path string) (*Config, error) {
+	data, err := ioutil.ReadFile(path)
+
+	if err != nil {
+		return nil, err
+	}
+
+	conf, err := ParseConfig(data)
+
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Dir(path)
+
+	for _, app := range conf.Apps {
+		if app.ValuesFile == "" {
+			continue
+		}
+
+		valuesPath := app.ValuesFile
+
+		if !filepath.IsAbs(valuesPath) {
+			valuesPath = filepath.Join(dir, valuesPath)
+		}
+
+		valuesData, err := ioutil.ReadFile(valuesPath)
+
+		if err != nil {
+			return nil, fmt.Errorf("app %s: could not read values file: %v", app.Name, err)
+		}
+
+		fileValues := make(map[string]interface{})
+
+		if err := yaml.Unmarshal(valuesData, &fileValues); err != nil {
+			return nil, fmt.Errorf("app %s: could not parse values file: %v", app.Name, err)
+		}
+
+		app.Values = utils.CoalesceValues(fileValues, app.Values)
+	}
+
+	return conf, nil
+}
+
+// ParseConfig parses and validates the contents of a porter.yaml file
+func ParseConfig(data []byte) (*Config, error) {
+	conf := &Config{}
+
+	if err := yaml.UnmarshalStrict(data, conf); err != nil {
+		return nil, err
+	}
+
+	if conf.Version == "" {
+		conf.Version = ConfigVersion
+	}
+
+	if conf.Namespace == "" {
+		conf.Namespace = "default"
+	}
+
+	if err := conf.Validate(); err != nil {
+		return nil, err
+	}
+
+	return conf, nil
+}
+
+// Validate checks that the config is well-formed, and sets the defaults of the apps
+func (c *Config) Validate() error {
+	if c.Version != ConfigVersion {
+		return fmt.Errorf("unsupported version %s: must be %s", c.Version, ConfigVersion)
+	}
+
+	names := make(map[string]bool)
+
+	for i, app := range c.Apps {
+		if app.Name == "" {
+			return fmt.Errorf("app %d: name is required", i)
+		}
+
+		if errs := validation.IsDNS1123Label(app.Name); len(errs) > 0 {
+			return fmt.Errorf("app %s: invalid name: %s", app.Name, strings.Join(errs, ", "))
+		}
+
+		if names[app.Name] {
+			return fmt.Errorf("app %s: declared more than once", app.Name)
+		}
+
+		names[app.Name] = true
+
+		if app.Chart == "" {
+			app.Chart = "web"
+		}
+
+		if app.Image != "" && app.Build != nil {
+			return fmt.Errorf("app %s: only one of image and build can be set", app.Name)
+		}
+
+		if app.Image != "" && len(strings.Split(app.Image, ":")) != 2 {
+			return fmt.Errorf("app %s: invalid image format: must be image-path:tag format", app.Name)
+		}
+
+		if app.Build != nil && (app.Build.Repo == "" || app.Build.Branch == "") {
+			return fmt.Errorf("app %s: build requires a repo and a branch", app.Name)
+		}
+
+		if len(app.Domains) > 0 && app.Chart != "web" {
+			return fmt.Errorf("app %s: domains can only be set for the web chart", app.Name)
+		}
+	}
+
+	return nil
+}
+
+// DesiredValues returns the values declared for an app, including the image and
+// domains
+func (a *App) DesiredValues() map[string]interface{} {
+	values := diff.CopyValues(a.Values)
+
+	if values == nil {
+		values = make(map[string]interface{})
+	}
+
+	if a.Image != "" {
+		imageSpl := strings.Split(a.Image, ":")
+
+		values["image"] = utils.CoalesceValues(nestedValues(values, "image"), map[string]interface{}{
+			"repository": imageSpl[0],
+			"tag":        imageSpl[1],
+		})
+	}
+
+	if len(a.Domains) > 0 {
+		hosts := make([]interface{}, 0, len(a.Domains))
+
+		for _, domain := range a.Domains {
+			hosts = append(hosts, domain)
+		}
+
+		values["ingress"] = utils.CoalesceValues(nestedValues(values, "ingress"), map[string]interface{}{
+			"enabled":       true,
+			"custom_domain": true,
+			"hosts":         hosts,
+		})
+	}
+
+	return values
+}
+
+func nestedValues(values map[string]interface{}, key string) map[string]interface{} {
+	res, _ := values[key].(map[string]interface{})
+	return res
+}
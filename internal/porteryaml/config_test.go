@@ -0,0 +1,20 @@
+package porteryaml
+
+import "testing"
+
+func TestParseConfigErrors(t *testing.T) {
+	configs := map[string]string{
+		"unsupported version": "version: v2\n",
+		"duplicate app":       "apps:\n- name: a\n- name: a\n",
+		"invalid name":        "apps:\n- name: Web_App\n",
+		"image and build":     "apps:\n- name: a\n  image: nginx:latest\n  build:\n    repo: a/b\n    branch: main\n",
+		"unknown field":       "apps:\n- name: a\n  replicas: 2\n",
+		"domains for worker":  "apps:\n- name: a\n  chart: worker\n  domains: [a.example.com]\n",
+	}
+
+	for name, config := range configs {
+		if _, err := ParseConfig([]byte(config)); err == nil {
+			t.Errorf("%s: expected error\n", name)
+		}
+	}
+}
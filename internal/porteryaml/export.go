@@ -0,0 +1,189 @@
+package porteryaml
+
+import (
+	"encoding/json"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/porter-dev/porter/internal/helm/diff"
+	"github.com/porter-dev/porter/internal/models"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// ExportRelease is a deployed release along with the Porter settings that are
+// exported with it
+type ExportRelease struct {
+	Release *release.Release
+
+	// EnvGroups are the env groups that the release is linked to, and
+	// EnvGroupVariables are the variables that they set on the release
+	EnvGroups         []string
+	EnvGroupVariables map[string]string
+
+	// GitActionConfig is the Github action that builds the release, if any
+	GitActionConfig *models.GitActionConfig
+}
+
+// Export returns a config that declares the releases of Porter application
+// templates in a namespace. Releases of other charts are left out.
+func Export(projectID, clusterID uint, namespace string, releases []*ExportRelease) *Config {
+	conf := &Config{
+		Version:   ConfigVersion,
+		Project:   projectID,
+		Cluster:   clusterID,
+		Namespace: namespace,
+		Apps:      make([]*App, 0),
+	}
+
+	for _, rel := range releases {
+		if rel.Release.Chart == nil || rel.Release.Chart.Metadata == nil {
+			continue
+		}
+
+		if !ApplicationCharts[rel.Release.Chart.Metadata.Name] {
+			continue
+		}
+
+		conf.Apps = append(conf.Apps, ExportApp(rel))
+	}
+
+	sort.SliceStable(conf.Apps, func(i, j int) bool {
+		return conf.Apps[i].Name < conf.Apps[j].Name
+	})
+
+	return conf
+}
+
+// ExportApp returns the app that declares a release. Values that are equal to the
+// defaults of the chart are left out, along with values that are managed by Porter:
+// the variables of linked env groups, generated subdomains, and the image of an app
+// that is built by a Github action.
+func ExportApp(rel *ExportRelease) *App {
+	chartName := rel.Release.Chart.Metadata.Name
+
+	app := &App{
+		Name:    rel.Release.Name,
+		Chart:   chartName,
+		Version: rel.Release.Chart.Metadata.Version,
+	}
+
+	values := diff.CopyValues(rel.Release.Config)
+
+	if values == nil {
+		values = make(map[string]interface{})
+	}
+
+	if len(rel.EnvGroups) > 0 {
+		app.EnvGroups = append([]string{}, rel.EnvGroups...)
+		sort.Strings(app.EnvGroups)
+
+		normal := nestedValues(nestedValues(nestedValues(values, "container"), "env"), "normal")
+
+		for key := range rel.EnvGroupVariables {
+			delete(normal, key)
+		}
+	}
+
+	image := nestedValues(values, "image")
+
+	if ga := rel.GitActionConfig; ga != nil {
+		app.Build = &Build{
+			Repo:       ga.GitRepo,
+			Branch:     ga.GitBranch,
+			Dockerfile: ga.DockerfilePath,
+			Folder:     ga.FolderPath,
+		}
+
+		if strings.Contains(ga.ImageRepoURI, "/") {
+			app.Build.RegistryURL = path.Dir(ga.ImageRepoURI)
+		}
+
+		// the image is updated by the Github action
+		delete(values, "image")
+	} else if ref := imageRef(image); ref != "" {
+		app.Image = ref
+
+		delete(image, "repository")
+		delete(image, "tag")
+	}
+
+	if ingress := nestedValues(values, "ingress"); ingress != nil {
+		// subdomains are generated when an app is created
+		delete(ingress, "porter_hosts")
+
+		if customDomain, _ := ingress["custom_domain"].(bool); chartName == "web" && customDomain {
+			hosts, _ := ingress["hosts"].([]interface{})
+
+			for _, host := range hosts {
+				if h, ok := host.(string); ok && h != "" {
+					app.Domains = append(app.Domains, h)
+				}
+			}
+
+			if len(app.Domains) > 0 {
+				delete(ingress, "enabled")
+				delete(ingress, "custom_domain")
+				delete(ingress, "hosts")
+			}
+		}
+	}
+
+	if values = StripDefaults(values, rel.Release.Chart.Values); len(values) > 0 {
+		app.Values = values
+	}
+
+	return app
+}
+
+// imageRef returns the image of a release in repository:tag format, or an empty
+// string if the image cannot be declared as the image of an app
+func imageRef(image map[string]interface{}) string {
+	repo, _ := image["repository"].(string)
+	tag, _ := image["tag"].(string)
+
+	if repo == "" || tag == "" || strings.Contains(repo, ":") || strings.Contains(tag, ":") {
+		return ""
+	}
+
+	return repo + ":" + tag
+}
+
+// StripDefaults returns the values that differ from the default values of a chart.
+// Nested maps are compared key by key, and maps that are left empty are removed.
+func StripDefaults(values, defaults map[string]interface{}) map[string]interface{} {
+	res := make(map[string]interface{})
+
+	for key, val := range values {
+		defaultVal, hasDefault := defaults[key]
+
+		valMap, isMap := val.(map[string]interface{})
+
+		if isMap {
+			defaultMap, _ := defaultVal.(map[string]interface{})
+
+			if stripped := StripDefaults(valMap, defaultMap); len(stripped) > 0 {
+				res[key] = stripped
+			}
+
+			continue
+		}
+
+		if hasDefault && valuesEqual(val, defaultVal) {
+			continue
+		}
+
+		res[key] = val
+	}
+
+	return res
+}
+
+// valuesEqual compares values by their JSON encoding, so that numbers decoded as
+// different types are equal
+func valuesEqual(a, b interface{}) bool {
+	aBytes, aErr := json.Marshal(a)
+	bBytes, bErr := json.Marshal(b)
+
+	return aErr == nil && bErr == nil && string(aBytes) == string(bBytes)
+}
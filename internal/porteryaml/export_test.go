@@ -0,0 +1,189 @@
+package porteryaml
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/models"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/release"
+	"sigs.k8s.io/yaml"
+)
+
+var webDefaults = map[string]interface{}{
+	"replicaCount": float64(1),
+	"image": map[string]interface{}{
+		"repository": "public.ecr.aws/o1j4x7p4/hello-porter",
+		"tag":        "latest",
+		"pullPolicy": "Always",
+	},
+	"ingress": map[string]interface{}{
+		"enabled":       true,
+		"custom_domain": false,
+		"hosts":         []interface{}{},
+		"porter_hosts":  []interface{}{},
+	},
+	"container": map[string]interface{}{
+		"port": float64(80),
+		"env": map[string]interface{}{
+			"normal": map[string]interface{}{},
+		},
+	},
+}
+
+func testRelease(name, chartName string, config map[string]interface{}) *release.Release {
+	return &release.Release{
+		Name:      name,
+		Namespace: "default",
+		Config:    config,
+		Chart: &chart.Chart{
+			Metadata: &chart.Metadata{
+				Name:    chartName,
+				Version: "0.20.0",
+			},
+			Values: webDefaults,
+		},
+	}
+}
+
+func TestExport(t *testing.T) {
+	releases := []*ExportRelease{
+		{
+			Release: testRelease("web-app", "web", map[string]interface{}{
+				"replicaCount": 3,
+				"image": map[string]interface{}{
+					"repository": "gcr.io/project/web-app",
+					"tag":        "v1",
+					"pullPolicy": "Always",
+				},
+				"ingress": map[string]interface{}{
+					"enabled":       true,
+					"custom_domain": true,
+					"hosts":         []interface{}{"app.example.com"},
+					"porter_hosts":  []interface{}{"web-app.porter.run"},
+				},
+				"container": map[string]interface{}{
+					"port": float64(80),
+					"env": map[string]interface{}{
+						"normal": map[string]interface{}{
+							"LOG_LEVEL":    "debug",
+							"DATABASE_URL": "PORTERSECRET_shared",
+						},
+					},
+				},
+			}),
+			EnvGroups:         []string{"shared"},
+			EnvGroupVariables: map[string]string{"DATABASE_URL": "PORTERSECRET_shared"},
+		},
+		{
+			Release: testRelease("built-app", "worker", map[string]interface{}{
+				"image": map[string]interface{}{
+					"repository": "gcr.io/project/built-app-default",
+					"tag":        "abc123",
+				},
+			}),
+			GitActionConfig: &models.GitActionConfig{
+				GitRepo:        "porter-dev/built-app",
+				GitBranch:      "main",
+				ImageRepoURI:   "gcr.io/project/built-app-default",
+				DockerfilePath: "./Dockerfile",
+				FolderPath:     ".",
+			},
+		},
+		{
+			Release: testRelease("redis", "redis", map[string]interface{}{
+				"replicaCount": 2,
+			}),
+		},
+	}
+
+	conf := Export(1, 2, "default", releases)
+
+	expected := &Config{
+		Version:   ConfigVersion,
+		Project:   1,
+		Cluster:   2,
+		Namespace: "default",
+		Apps: []*App{
+			{
+				Name:    "built-app",
+				Chart:   "worker",
+				Version: "0.20.0",
+				Build: &Build{
+					Repo:        "porter-dev/built-app",
+					Branch:      "main",
+					Dockerfile:  "./Dockerfile",
+					Folder:      ".",
+					RegistryURL: "gcr.io/project",
+				},
+			},
+			{
+				Name:    "web-app",
+				Chart:   "web",
+				Version: "0.20.0",
+				Values: map[string]interface{}{
+					"replicaCount": 3,
+					"container": map[string]interface{}{
+						"env": map[string]interface{}{
+							"normal": map[string]interface{}{
+								"LOG_LEVEL": "debug",
+							},
+						},
+					},
+				},
+				Image:     "gcr.io/project/web-app:v1",
+				EnvGroups: []string{"shared"},
+				Domains:   []string{"app.example.com"},
+			},
+		},
+	}
+
+	if !reflect.DeepEqual(conf, expected) {
+		expBytes, _ := yaml.Marshal(expected)
+		gotBytes, _ := yaml.Marshal(conf)
+
+		t.Fatalf("config incorrect:\nexpected:\n%s\ngot:\n%s\n", expBytes, gotBytes)
+	}
+
+	// the exported config can be read back
+	data, err := yaml.Marshal(conf)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if _, err := ParseConfig(data); err != nil {
+		t.Errorf("could not parse exported config: %v\n", err)
+	}
+}
+
+func TestStripDefaults(t *testing.T) {
+	values := map[string]interface{}{
+		"replicaCount": 1,
+		"nodeSelector": map[string]interface{}{},
+		"resources": map[string]interface{}{
+			"limits": map[string]interface{}{
+				"memory": "256Mi",
+			},
+		},
+		"container": map[string]interface{}{
+			"port":    float64(80),
+			"command": "./start.sh",
+		},
+	}
+
+	expected := map[string]interface{}{
+		"resources": map[string]interface{}{
+			"limits": map[string]interface{}{
+				"memory": "256Mi",
+			},
+		},
+		"container": map[string]interface{}{
+			"command": "./start.sh",
+		},
+	}
+
+	if res := StripDefaults(values, webDefaults); !reflect.DeepEqual(res, expected) {
+		t.Errorf("values incorrect: expected %v, got %v\n", expected, res)
+	}
+}
@@ -0,0 +1,153 @@
+package registry
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"time"
+
+	"github.com/porter-dev/porter/internal/repository"
+)
+
+// acrPageSize is the number of repositories or tags that are listed per request
+const acrPageSize = 100
+
+type acrCatalogResp struct {
+	Repositories []string `json:"repositories"`
+}
+
+type acrTagsResp struct {
+	Tags []struct {
+		Name        string    `json:"name"`
+		Digest      string    `json:"digest"`
+		CreatedTime time.Time `json:"createdTime"`
+	} `json:"tags"`
+}
+
+// getACRToken exchanges the credentials of the service principal or admin user of
+// an ACR registry for a token with the given scope
+func (r *Registry) getACRToken(repo repository.Repository, scope string) (string, *url.URL, error) {
+	basic, err := repo.BasicIntegration.ReadBasicIntegration(r.BasicIntegrationID)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	parsedURL, err := parseRegistryURL(r.URL)
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	token, err := getBearerToken(parsedURL, scope, string(basic.Username), string(basic.Password))
+
+	if err != nil {
+		return "", nil, err
+	}
+
+	return token, parsedURL, nil
+}
+
+// newACRRequest returns a paginated request to the ACR API
+func newACRRequest(parsedURL *url.URL, token, path, last string) (*http.Request, error) {
+	query := url.Values{}
+	query.Set("n", fmt.Sprintf("%d", acrPageSize))
+
+	if last != "" {
+		query.Set("last", last)
+	}
+
+	req, err := http.NewRequest(
+		"GET",
+		fmt.Sprintf("%s://%s/acr/v1/%s?%s", parsedURL.Scheme, parsedURL.Host, path, query.Encode()),
+		nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return req, nil
+}
+
+func (r *Registry) listACRRepositories(repo repository.Repository) ([]*Repository, error) {
+	token, parsedURL, err := r.getACRToken(repo, "registry:catalog:*")
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*Repository, 0)
+	last := ""
+
+	for {
+		req, err := newACRRequest(parsedURL, token, "_catalog", last)
+
+		if err != nil {
+			return nil, err
+		}
+
+		catalog := &acrCatalogResp{}
+
+		if err := doJSONRequest(req, catalog); err != nil {
+			return nil, fmt.Errorf("could not list ACR repositories: %v", err)
+		}
+
+		for _, name := range catalog.Repositories {
+			res = append(res, &Repository{
+				Name: name,
+				URI:  parsedURL.Host + "/" + name,
+			})
+		}
+
+		if len(catalog.Repositories) < acrPageSize {
+			return res, nil
+		}
+
+		last = catalog.Repositories[len(catalog.Repositories)-1]
+	}
+}
+
+func (r *Registry) listACRImages(repoName string, repo repository.Repository) ([]*Image, error) {
+	token, parsedURL, err := r.getACRToken(repo, fmt.Sprintf("repository:%s:metadata_read", repoName))
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*Image, 0)
+	last := ""
+
+	for {
+		req, err := newACRRequest(parsedURL, token, repoName+"/_tags", last)
+
+		if err != nil {
+			return nil, err
+		}
+
+		tags := &acrTagsResp{}
+
+		if err := doJSONRequest(req, tags); err != nil {
+			return nil, fmt.Errorf("could not list ACR tags: %v", err)
+		}
+
+		for _, tag := range tags.Tags {
+			pushedAt := tag.CreatedTime
+
+			res = append(res, &Image{
+				Digest:         tag.Digest,
+				Tag:            tag.Name,
+				RepositoryName: repoName,
+				PushedAt:       &pushedAt,
+			})
+		}
+
+		if len(tags.Tags) < acrPageSize {
+			return res, nil
+		}
+
+		last = tags.Tags[len(tags.Tags)-1].Name
+	}
+}
@@ -0,0 +1,487 @@
+package registry
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/docker/cli/cli/config/configfile"
+	"github.com/porter-dev/porter/internal/repository"
+	"golang.org/x/oauth2"
+)
+
+// The manifest media types that can be copied. Schema 1 manifests are not
+// supported, since they are signed for the repository that they were pushed to.
+const (
+	mediaTypeDockerManifest     = "application/vnd.docker.distribution.manifest.v2+json"
+	mediaTypeDockerManifestList = "application/vnd.docker.distribution.manifest.list.v2+json"
+	mediaTypeOCIManifest        = "application/vnd.oci.image.manifest.v1+json"
+	mediaTypeOCIIndex           = "application/vnd.oci.image.index.v1+json"
+)
+
+var manifestMediaTypes = []string{
+	mediaTypeDockerManifestList,
+	mediaTypeOCIIndex,
+	mediaTypeDockerManifest,
+	mediaTypeOCIManifest,
+}
+
+var digestRegex = regexp.MustCompile(`^sha256:[a-f0-9]{64}$`)
+
+// copyClient is the client used to copy images between registries, which has a
+// longer timeout than the API clients since layers may be large
+var copyClient = &http.Client{
+	Timeout: 30 * time.Minute,
+}
+
+// CopyImageOpts are the options for copying an image between registries
+type CopyImageOpts struct {
+	// SourceRepository is the name of the repository in the source registry
+	SourceRepository string
+
+	// Reference is the digest or tag of the source image. Copying by digest is
+	// preferred, since a tag may be moved while the image is copied.
+	Reference string
+
+	// TargetRepository is the name of the repository in the target registry
+	TargetRepository string
+
+	// TargetTag tags the image in the target repository. If empty, the image is
+	// only pushed by digest.
+	TargetTag string
+}
+
+// CopyImage copies an image from a repository of this registry to a repository of
+// the target registry, using the credentials of each registry's docker config. If
+// the image is a manifest list or OCI index, the image of every platform is copied.
+// The digest of the image is unchanged, and is returned.
+func (r *Registry) CopyImage(
+	target *Registry,
+	opts *CopyImageOpts,
+	repo repository.Repository,
+	doAuth *oauth2.Config, // only required if using DOCR
+) (string, error) {
+	src, err := r.newImageClient(opts.SourceRepository, "pull", repo, doAuth)
+
+	if err != nil {
+		return "", fmt.Errorf("could not authenticate with the source registry: %v", err)
+	}
+
+	dst, err := target.newImageClient(opts.TargetRepository, "pull,push", repo, doAuth)
+
+	if err != nil {
+		return "", fmt.Errorf("could not authenticate with the target registry: %v", err)
+	}
+
+	if target.AWSIntegrationID != 0 {
+		if err := target.CreateRepository(repo, opts.TargetRepository); err != nil {
+			return "", fmt.Errorf("could not create target repository: %v", err)
+		}
+	}
+
+	targetRef := opts.TargetTag
+
+	if targetRef == "" {
+		targetRef = opts.Reference
+	}
+
+	return copyManifest(src, dst, opts.Reference, targetRef)
+}
+
+// imageClient calls the Docker registry API for a single repository, handling the
+// basic and token authentication challenges of the registry
+type imageClient struct {
+	baseURL  string
+	path     string
+	scope    string
+	username string
+	password string
+
+	authHeader string
+}
+
+// newImageClient creates a client for a repository of the registry, with the
+// credentials of the registry's docker config
+func (r *Registry) newImageClient(
+	repoName, actions string,
+	repo repository.Repository,
+	doAuth *oauth2.Config,
+) (*imageClient, error) {
+	baseURL, path, err := r.getRepositoryAPIURL(repoName)
+
+	if err != nil {
+		return nil, err
+	}
+
+	host := strings.SplitN(baseURL, "://", 2)[1]
+
+	confBytes, err := r.GetDockerConfigJSON(repo, doAuth)
+
+	if err != nil {
+		return nil, err
+	}
+
+	conf := &configfile.ConfigFile{}
+
+	if err := json.Unmarshal(confBytes, conf); err != nil {
+		return nil, err
+	}
+
+	client := &imageClient{
+		baseURL: baseURL,
+		path:    path,
+		scope:   fmt.Sprintf("repository:%s:%s", path, actions),
+	}
+
+	for key, authConf := range conf.AuthConfigs {
+		if key == "https://index.docker.io/v1/" {
+			key = dockerHubAPIHost
+		}
+
+		if parsed, err := parseRegistryURL(key); err == nil && parsed.Host == host {
+			client.username = authConf.Username
+			client.password = authConf.Password
+		}
+	}
+
+	return client, nil
+}
+
+// dockerHubAPIHost is the host of the Docker registry API of Docker Hub, which
+// differs from the host that images are named by
+const dockerHubAPIHost = "registry-1.docker.io"
+
+// getRepositoryAPIURL returns the base URL of the registry API and the path of a
+// repository on it
+func (r *Registry) getRepositoryAPIURL(repoName string) (string, string, error) {
+	if strings.Contains(r.URL, "docker.io") {
+		path := repoName
+
+		// official images are stored under the library namespace
+		if !strings.Contains(path, "/") {
+			path = "library/" + path
+		}
+
+		return "https://" + dockerHubAPIHost, path, nil
+	}
+
+	parsedURL, err := parseRegistryURL(r.URL)
+
+	if err != nil {
+		return "", "", err
+	}
+
+	path := strings.TrimPrefix(r.GetRepositoryURI(repoName), parsedURL.Host+"/")
+
+	return fmt.Sprintf("%s://%s", parsedURL.Scheme, parsedURL.Host), path, nil
+}
+
+// do sends a request to the registry. If the registry responds with an
+// authentication challenge, the request is retried with basic authentication or a
+// bearer token for the repository, which is reused for later requests.
+func (c *imageClient) do(req *http.Request) (*http.Response, error) {
+	if c.authHeader != "" {
+		req.Header.Set("Authorization", c.authHeader)
+	}
+
+	resp, err := copyClient.Do(req)
+
+	if err != nil || resp.StatusCode != http.StatusUnauthorized {
+		return resp, err
+	}
+
+	resp.Body.Close()
+
+	if req.Body != nil && req.GetBody == nil {
+		return nil, fmt.Errorf("request to %s was not authorized", req.URL.Path)
+	}
+
+	challenge := resp.Header.Get("WWW-Authenticate")
+
+	if strings.HasPrefix(strings.ToLower(challenge), "basic") {
+		c.authHeader = "Basic " + generateAuthToken(c.username, c.password)
+	} else {
+		realm, service, err := parseBearerChallenge(challenge)
+
+		if err != nil {
+			return nil, err
+		}
+
+		token, err := fetchBearerToken(realm, service, c.scope, c.username, c.password)
+
+		if err != nil {
+			return nil, err
+		}
+
+		c.authHeader = "Bearer " + token
+	}
+
+	retry := req.Clone(req.Context())
+	retry.Header.Set("Authorization", c.authHeader)
+
+	if req.GetBody != nil {
+		if retry.Body, err = req.GetBody(); err != nil {
+			return nil, err
+		}
+	}
+
+	return copyClient.Do(retry)
+}
+
+// checkStatus returns an error if the response does not have one of the expected
+// status codes, and closes the response body in that case
+func checkStatus(resp *http.Response, expected ...int) error {
+	for _, code := range expected {
+		if resp.StatusCode == code {
+			return nil
+		}
+	}
+
+	defer resp.Body.Close()
+
+	body, _ := ioutil.ReadAll(io.LimitReader(resp.Body, 4096))
+
+	return &nativeAPIError{
+		StatusCode: resp.StatusCode,
+		URL:        resp.Request.URL.String(),
+		Body:       strings.TrimSpace(string(body)),
+	}
+}
+
+type manifestDescriptor struct {
+	MediaType string   `json:"mediaType"`
+	Digest    string   `json:"digest"`
+	Size      int64    `json:"size"`
+	URLs      []string `json:"urls,omitempty"`
+}
+
+type imageManifest struct {
+	SchemaVersion int                  `json:"schemaVersion"`
+	MediaType     string               `json:"mediaType"`
+	Config        manifestDescriptor   `json:"config"`
+	Layers        []manifestDescriptor `json:"layers"`
+	Manifests     []manifestDescriptor `json:"manifests"`
+}
+
+func (c *imageClient) getManifest(ref string) ([]byte, string, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s/v2/%s/manifests/%s", c.baseURL, c.path, ref), nil)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+
+	resp, err := c.do(req)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	if err := checkStatus(resp, http.StatusOK); err != nil {
+		return nil, "", fmt.Errorf("could not read manifest %s of %s: %v", ref, c.path, err)
+	}
+
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	return body, strings.Split(resp.Header.Get("Content-Type"), ";")[0], nil
+}
+
+func (c *imageClient) putManifest(ref, mediaType string, body []byte) error {
+	req, err := http.NewRequest("PUT", fmt.Sprintf("%s/v2/%s/manifests/%s", c.baseURL, c.path, ref), bytes.NewReader(body))
+
+	if err != nil {
+		return err
+	}
+
+	req.Header.Set("Content-Type", mediaType)
+
+	resp, err := c.do(req)
+
+	if err != nil {
+		return err
+	}
+
+	if err := checkStatus(resp, http.StatusCreated, http.StatusOK); err != nil {
+		return fmt.Errorf("could not push manifest %s to %s: %v", ref, c.path, err)
+	}
+
+	resp.Body.Close()
+
+	return nil
+}
+
+// copyManifest copies a manifest and everything that it references, and returns
+// its digest
+func copyManifest(src, dst *imageClient, ref, targetRef string) (string, error) {
+	body, mediaType, err := src.getManifest(ref)
+
+	if err != nil {
+		return "", err
+	}
+
+	digest := fmt.Sprintf("sha256:%x", sha256.Sum256(body))
+
+	if digestRegex.MatchString(ref) && ref != digest {
+		return "", fmt.Errorf("manifest %s of %s has digest %s", ref, src.path, digest)
+	}
+
+	manifest := &imageManifest{}
+
+	if err := json.Unmarshal(body, manifest); err != nil {
+		return "", fmt.Errorf("could not parse manifest %s of %s: %v", ref, src.path, err)
+	}
+
+	// the media type may only be set in the manifest
+	if mediaType == "" || mediaType == "application/json" {
+		mediaType = manifest.MediaType
+	}
+
+	switch mediaType {
+	case mediaTypeDockerManifestList, mediaTypeOCIIndex:
+		for _, child := range manifest.Manifests {
+			if _, err := copyManifest(src, dst, child.Digest, child.Digest); err != nil {
+				return "", err
+			}
+		}
+	case mediaTypeDockerManifest, mediaTypeOCIManifest:
+		blobs := append([]manifestDescriptor{manifest.Config}, manifest.Layers...)
+
+		for _, blob := range blobs {
+			// foreign layers are pulled from their urls rather than the registry
+			if len(blob.URLs) > 0 {
+				continue
+			}
+
+			if err := copyBlob(src, dst, blob); err != nil {
+				return "", err
+			}
+		}
+	default:
+		return "", fmt.Errorf("manifest %s of %s has unsupported media type %s", ref, src.path, mediaType)
+	}
+
+	if err := dst.putManifest(targetRef, mediaType, body); err != nil {
+		return "", err
+	}
+
+	return digest, nil
+}
+
+// copyBlob copies a blob to the target repository, unless it already exists there
+func copyBlob(src, dst *imageClient, blob manifestDescriptor) error {
+	req, err := http.NewRequest("HEAD", fmt.Sprintf("%s/v2/%s/blobs/%s", dst.baseURL, dst.path, blob.Digest), nil)
+
+	if err != nil {
+		return err
+	}
+
+	resp, err := dst.do(req)
+
+	if err != nil {
+		return err
+	}
+
+	resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		return nil
+	}
+
+	// start the upload before the source blob is read, so that the target is
+	// authorized before the blob is streamed to it
+	req, err = http.NewRequest("POST", fmt.Sprintf("%s/v2/%s/blobs/uploads/", dst.baseURL, dst.path), nil)
+
+	if err != nil {
+		return err
+	}
+
+	resp, err = dst.do(req)
+
+	if err != nil {
+		return err
+	}
+
+	if err := checkStatus(resp, http.StatusAccepted); err != nil {
+		return fmt.Errorf("could not start upload of blob %s to %s: %v", blob.Digest, dst.path, err)
+	}
+
+	resp.Body.Close()
+
+	uploadURL, err := resp.Request.URL.Parse(resp.Header.Get("Location"))
+
+	if err != nil {
+		return fmt.Errorf("invalid upload location for blob %s: %v", blob.Digest, err)
+	}
+
+	query := uploadURL.Query()
+	query.Set("digest", blob.Digest)
+	uploadURL.RawQuery = query.Encode()
+
+	req, err = http.NewRequest("GET", fmt.Sprintf("%s/v2/%s/blobs/%s", src.baseURL, src.path, blob.Digest), nil)
+
+	if err != nil {
+		return err
+	}
+
+	srcResp, err := src.do(req)
+
+	if err != nil {
+		return err
+	}
+
+	if err := checkStatus(srcResp, http.StatusOK); err != nil {
+		return fmt.Errorf("could not read blob %s of %s: %v", blob.Digest, src.path, err)
+	}
+
+	defer srcResp.Body.Close()
+
+	req, err = http.NewRequest("PUT", uploadURL.String(), srcResp.Body)
+
+	if err != nil {
+		return err
+	}
+
+	req.ContentLength = srcResp.ContentLength
+	req.Header.Set("Content-Type", "application/octet-stream")
+
+	// the upload url may be on a different host, which must not receive the
+	// credentials of the registry
+	if !isSameHost(uploadURL, dst.baseURL) {
+		resp, err = copyClient.Do(req)
+	} else {
+		resp, err = dst.do(req)
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if err := checkStatus(resp, http.StatusCreated); err != nil {
+		return fmt.Errorf("could not upload blob %s to %s: %v", blob.Digest, dst.path, err)
+	}
+
+	resp.Body.Close()
+
+	return nil
+}
+
+func isSameHost(u *url.URL, baseURL string) bool {
+	parsed, err := url.Parse(baseURL)
+
+	return err == nil && parsed.Host == u.Host
+}
@@ -0,0 +1,245 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// fakeRegistry is an in-memory registry that implements the parts of the Docker
+// registry API that are used to copy images, behind token authentication
+type fakeRegistry struct {
+	mu        sync.Mutex
+	server    *httptest.Server
+	manifests map[string][]byte
+	types     map[string]string
+	blobs     map[string][]byte
+	uploads   int
+
+	// noDigestHeader omits the digest from responses to HEAD manifest requests
+	noDigestHeader bool
+}
+
+func newFakeRegistry() *fakeRegistry {
+	reg := &fakeRegistry{
+		manifests: make(map[string][]byte),
+		types:     make(map[string]string),
+		blobs:     make(map[string][]byte),
+	}
+
+	reg.server = httptest.NewServer(http.HandlerFunc(reg.handle))
+
+	return reg
+}
+
+func digestOf(body []byte) string {
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(body))
+}
+
+func (f *fakeRegistry) addManifest(repo, ref, mediaType string, body []byte) string {
+	digest := digestOf(body)
+
+	for _, key := range []string{repo + ":" + ref, repo + ":" + digest} {
+		f.manifests[key] = body
+		f.types[key] = mediaType
+	}
+
+	return digest
+}
+
+func (f *fakeRegistry) handle(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if r.URL.Path == "/token" {
+		if username, password, _ := r.BasicAuth(); username != "porter" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		json.NewEncoder(w).Encode(map[string]string{"token": "token-" + r.URL.Query().Get("scope")})
+		return
+	}
+
+	path := strings.TrimPrefix(r.URL.Path, "/v2/")
+	var repo, kind, ref string
+
+	for _, k := range []string{"/manifests/", "/blobs/uploads/", "/blobs/"} {
+		if i := strings.Index(path, k); i != -1 {
+			repo, kind, ref = path[:i], k, path[i+len(k):]
+			break
+		}
+	}
+
+	if !strings.HasPrefix(r.Header.Get("Authorization"), "Bearer token-repository:"+repo+":") {
+		w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/token",service="fake"`, f.server.URL))
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	switch {
+	case kind == "/manifests/" && (r.Method == "GET" || r.Method == "HEAD"):
+		body, ok := f.manifests[repo+":"+ref]
+
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Header().Set("Content-Type", f.types[repo+":"+ref])
+
+		if r.Method == "HEAD" {
+			if !f.noDigestHeader {
+				w.Header().Set("Docker-Content-Digest", digestOf(body))
+			}
+
+			return
+		}
+
+		w.Write(body)
+	case kind == "/manifests/" && r.Method == "PUT":
+		body, _ := ioutil.ReadAll(r.Body)
+		f.addManifest(repo, ref, r.Header.Get("Content-Type"), body)
+		w.WriteHeader(http.StatusCreated)
+	case kind == "/blobs/" && (r.Method == "GET" || r.Method == "HEAD"):
+		body, ok := f.blobs[repo+":"+ref]
+
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		if r.Method == "GET" {
+			w.Write(body)
+		}
+	case kind == "/blobs/uploads/" && r.Method == "POST":
+		w.Header().Set("Location", fmt.Sprintf("/v2/%s/blobs/uploads/session?state=abc", repo))
+		w.WriteHeader(http.StatusAccepted)
+	case kind == "/blobs/uploads/" && r.Method == "PUT":
+		body, _ := ioutil.ReadAll(r.Body)
+		digest := r.URL.Query().Get("digest")
+
+		if digestOf(body) != digest || r.URL.Query().Get("state") != "abc" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		f.blobs[repo+":"+digest] = body
+		f.uploads++
+		w.WriteHeader(http.StatusCreated)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func TestCopyImageManifestList(t *testing.T) {
+	src := newFakeRegistry()
+	defer src.server.Close()
+
+	dst := newFakeRegistry()
+	defer dst.server.Close()
+
+	// each platform image has its own config and layer, and both share a layer
+	shared := []byte("shared layer")
+	src.blobs["staging/web:"+digestOf(shared)] = shared
+
+	platforms := make([]manifestDescriptor, 0)
+
+	for _, arch := range []string{"amd64", "arm64"} {
+		config := []byte("config " + arch)
+		layer := []byte("layer " + arch)
+
+		src.blobs["staging/web:"+digestOf(config)] = config
+		src.blobs["staging/web:"+digestOf(layer)] = layer
+
+		manifest, _ := json.Marshal(&imageManifest{
+			SchemaVersion: 2,
+			MediaType:     mediaTypeDockerManifest,
+			Config:        manifestDescriptor{MediaType: "application/vnd.docker.container.image.v1+json", Digest: digestOf(config)},
+			Layers: []manifestDescriptor{
+				{MediaType: "application/vnd.docker.image.rootfs.diff.tar.gzip", Digest: digestOf(shared)},
+				{MediaType: "application/vnd.docker.image.rootfs.diff.tar.gzip", Digest: digestOf(layer)},
+			},
+		})
+
+		platforms = append(platforms, manifestDescriptor{
+			MediaType: mediaTypeDockerManifest,
+			Digest:    src.addManifest("staging/web", arch, mediaTypeDockerManifest, manifest),
+		})
+	}
+
+	list, _ := json.Marshal(&imageManifest{
+		SchemaVersion: 2,
+		MediaType:     mediaTypeDockerManifestList,
+		Manifests:     platforms,
+	})
+
+	listDigest := src.addManifest("staging/web", "latest", mediaTypeDockerManifestList, list)
+
+	srcReg, repo := newBasicRegistry(t, src.server.URL+"/staging", "")
+	dstReg, _ := newBasicRegistry(t, dst.server.URL+"/production", "")
+
+	// the target registry uses the basic integration of the source registry
+	dstReg.BasicIntegrationID = srcReg.BasicIntegrationID
+
+	digest, err := srcReg.CopyImage(dstReg, &CopyImageOpts{
+		SourceRepository: "web",
+		Reference:        listDigest,
+		TargetRepository: "web",
+		TargetTag:        "v1",
+	}, repo, nil)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if digest != listDigest {
+		t.Errorf("expected digest %s, got %s\n", listDigest, digest)
+	}
+
+	if got := dst.manifests["production/web:v1"]; string(got) != string(list) {
+		t.Errorf("manifest list was not pushed with the same bytes\n")
+	}
+
+	for _, platform := range platforms {
+		if _, ok := dst.manifests["production/web:"+platform.Digest]; !ok {
+			t.Errorf("platform manifest %s was not copied\n", platform.Digest)
+		}
+	}
+
+	// 2 configs, 2 layers and the shared layer, which is only uploaded once
+	if dst.uploads != 5 || len(dst.blobs) != 5 {
+		t.Errorf("expected 5 blobs to be uploaded once, got %d uploads of %d blobs\n", dst.uploads, len(dst.blobs))
+	}
+}
+
+func TestCopyImageDigestMismatch(t *testing.T) {
+	src := newFakeRegistry()
+	defer src.server.Close()
+
+	manifest, _ := json.Marshal(&imageManifest{SchemaVersion: 2, MediaType: mediaTypeDockerManifest})
+	src.addManifest("staging/web", "latest", mediaTypeDockerManifest, manifest)
+
+	// serve a different manifest for the requested digest
+	wrongDigest := digestOf([]byte("other"))
+	src.manifests["staging/web:"+wrongDigest] = manifest
+	src.types["staging/web:"+wrongDigest] = mediaTypeDockerManifest
+
+	srcReg, repo := newBasicRegistry(t, src.server.URL+"/staging", "")
+
+	_, err := srcReg.CopyImage(srcReg, &CopyImageOpts{
+		SourceRepository: "web",
+		Reference:        wrongDigest,
+		TargetRepository: "web-copy",
+	}, repo, nil)
+
+	if err == nil || !strings.Contains(err.Error(), "has digest") {
+		t.Errorf("expected a digest mismatch error, got %v\n", err)
+	}
+}
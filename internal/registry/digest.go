@@ -0,0 +1,100 @@
+package registry
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"golang.org/x/oauth2"
+)
+
+// GetImageDigest resolves a tag of a repository to the digest of its manifest. If
+// the tag points to a manifest list or OCI index, the digest of the list is
+// returned, so that each node still pulls the image of its own platform.
+func (r *Registry) GetImageDigest(
+	repoName, tag string,
+	repo repository.Repository,
+	doAuth *oauth2.Config, // only required if using DOCR
+) (string, error) {
+	client, err := r.newImageClient(repoName, "pull", repo, doAuth)
+
+	if err != nil {
+		return "", fmt.Errorf("could not authenticate with the registry: %v", err)
+	}
+
+	// a HEAD request is tried first, since it does not count as a pull for
+	// registries that limit the rate of pulls
+	req, err := http.NewRequest("HEAD", fmt.Sprintf("%s/v2/%s/manifests/%s", client.baseURL, client.path, tag), nil)
+
+	if err != nil {
+		return "", err
+	}
+
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+
+	resp, err := client.do(req)
+
+	if err != nil {
+		return "", err
+	}
+
+	resp.Body.Close()
+
+	if resp.StatusCode == http.StatusOK {
+		if digest := resp.Header.Get("Docker-Content-Digest"); digestRegex.MatchString(digest) {
+			return digest, nil
+		}
+	} else if resp.StatusCode == http.StatusNotFound {
+		return "", fmt.Errorf("tag %s of %s was not found", tag, repoName)
+	}
+
+	// registries that do not return the digest for a HEAD request return the
+	// manifest, which the digest is computed from
+	body, _, err := client.getManifest(tag)
+
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("sha256:%x", sha256.Sum256(body)), nil
+}
+
+// FindImageRegistry finds the registry that an image repository, such as
+// registry.digitalocean.com/porter/web, is pulled from, and returns the name of the
+// repository in that registry. If several registries match, the registry with the
+// longest URL is used. If no registry matches, the returned registry is nil.
+func FindImageRegistry(registries []*models.Registry, imageRepo string) (*Registry, string) {
+	name, _, _ := parseImageRef(imageRepo)
+
+	var res *Registry
+	var resRepoName string
+	var resLen int
+
+	for _, reg := range registries {
+		_reg := Registry(*reg)
+
+		// docker hub registries are connected for a single repository, which may be
+		// pulled with or without the docker hub host
+		if strings.Contains(reg.URL, "docker.io/") {
+			hubRepoName := strings.Split(reg.URL, "docker.io/")[1]
+			hubName := strings.TrimPrefix(strings.TrimPrefix(name, "index."), "docker.io/")
+
+			if hubName == hubRepoName && len(reg.URL) > resLen {
+				res, resRepoName, resLen = &_reg, hubRepoName, len(reg.URL)
+			}
+
+			continue
+		}
+
+		prefix := _reg.GetRepositoryURI("")
+
+		if strings.HasPrefix(name, prefix) && len(name) > len(prefix) && len(prefix) > resLen {
+			res, resRepoName, resLen = &_reg, strings.TrimPrefix(name, prefix), len(prefix)
+		}
+	}
+
+	return res, resRepoName
+}
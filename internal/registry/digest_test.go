@@ -0,0 +1,78 @@
+package registry
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+func TestGetImageDigest(t *testing.T) {
+	src := newFakeRegistry()
+	defer src.server.Close()
+
+	manifest, _ := json.Marshal(&imageManifest{SchemaVersion: 2, MediaType: mediaTypeDockerManifest})
+	expDigest := src.addManifest("staging/web", "v1", mediaTypeDockerManifest, manifest)
+
+	reg, repo := newBasicRegistry(t, src.server.URL+"/staging", "")
+
+	for _, noDigestHeader := range []bool{false, true} {
+		src.noDigestHeader = noDigestHeader
+
+		digest, err := reg.GetImageDigest("web", "v1", repo, nil)
+
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+
+		if digest != expDigest {
+			t.Errorf("expected digest %s, got %s (digest header omitted: %t)\n", expDigest, digest, noDigestHeader)
+		}
+	}
+
+	if _, err := reg.GetImageDigest("web", "v2", repo, nil); err == nil {
+		t.Errorf("expected an error for a tag that does not exist\n")
+	}
+}
+
+func TestFindImageRegistry(t *testing.T) {
+	registries := []*models.Registry{
+		{URL: "https://123456789012.dkr.ecr.us-east-1.amazonaws.com"},
+		{URL: "registry.digitalocean.com/porter"},
+		{URL: "gcr.io/porter"},
+		{URL: "gcr.io/porter/team"},
+		{URL: "index.docker.io/porter/web"},
+	}
+
+	tests := []struct {
+		imageRepo   string
+		expURL      string
+		expRepoName string
+	}{
+		{"123456789012.dkr.ecr.us-east-1.amazonaws.com/web", "https://123456789012.dkr.ecr.us-east-1.amazonaws.com", "web"},
+		{"registry.digitalocean.com/porter/api", "registry.digitalocean.com/porter", "api"},
+		{"gcr.io/porter/worker", "gcr.io/porter", "worker"},
+		{"gcr.io/porter/team/worker", "gcr.io/porter/team", "worker"},
+		{"porter/web", "index.docker.io/porter/web", "porter/web"},
+		{"docker.io/porter/web", "index.docker.io/porter/web", "porter/web"},
+		{"index.docker.io/porter/web", "index.docker.io/porter/web", "porter/web"},
+		{"porter/other", "", ""},
+		{"registry.digitalocean.com/other/api", "", ""},
+	}
+
+	for _, test := range tests {
+		reg, repoName := FindImageRegistry(registries, test.imageRepo)
+
+		if test.expURL == "" {
+			if reg != nil {
+				t.Errorf("%s: expected no registry, got %s\n", test.imageRepo, reg.URL)
+			}
+
+			continue
+		}
+
+		if reg == nil || reg.URL != test.expURL || repoName != test.expRepoName {
+			t.Errorf("%s: expected %s and %s, got %v and %s\n", test.imageRepo, test.expURL, test.expRepoName, reg, repoName)
+		}
+	}
+}
@@ -0,0 +1,198 @@
+package registry
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/porter-dev/porter/internal/repository"
+)
+
+// githubAPIURL is the URL of the GitHub API, which lists the container packages
+// that are stored in GHCR
+var githubAPIURL = "https://api.github.com"
+
+type ghcrPackage struct {
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+type ghcrPackageVersion struct {
+	// Name is the digest of the image
+	Name      string    `json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+	Metadata  struct {
+		Container struct {
+			Tags []string `json:"tags"`
+		} `json:"container"`
+	} `json:"metadata"`
+}
+
+// getGHCROwner returns the user or organization of a GHCR registry, which has a URL
+// of the form ghcr.io/<owner>
+func (r *Registry) getGHCROwner() (string, error) {
+	parsedURL, err := parseRegistryURL(r.URL)
+
+	if err != nil {
+		return "", err
+	}
+
+	owner := strings.Split(parsedURL.Path, "/")[0]
+
+	if owner == "" {
+		return "", fmt.Errorf("GHCR registry url must be of the form ghcr.io/<owner>")
+	}
+
+	return owner, nil
+}
+
+// getGHCRPackagesPath returns the path of the packages API of the owner of a GHCR
+// registry, which differs for organizations and users
+func getGHCRPackagesPath(owner, token string) (string, error) {
+	for _, path := range []string{"orgs/" + owner, "users/" + owner} {
+		req, err := newGHCRRequest(fmt.Sprintf("%s/%s/packages?package_type=container&per_page=1", githubAPIURL, path), token)
+
+		if err != nil {
+			return "", err
+		}
+
+		err = doJSONRequest(req, &[]ghcrPackage{})
+
+		if err == nil {
+			return path, nil
+		}
+
+		if apiErr, ok := err.(*nativeAPIError); !ok || apiErr.StatusCode != http.StatusNotFound {
+			return "", err
+		}
+	}
+
+	return "", fmt.Errorf("no GitHub user or organization named %s", owner)
+}
+
+func newGHCRRequest(reqURL, token string) (*http.Request, error) {
+	req, err := http.NewRequest("GET", reqURL, nil)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return req, nil
+}
+
+func (r *Registry) listGHCRRepositories(repo repository.Repository) ([]*Repository, error) {
+	basic, err := repo.BasicIntegration.ReadBasicIntegration(r.BasicIntegrationID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := r.getGHCROwner()
+
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := getGHCRPackagesPath(owner, string(basic.Password))
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*Repository, 0)
+
+	for page := 1; ; page++ {
+		req, err := newGHCRRequest(
+			fmt.Sprintf("%s/%s/packages?package_type=container&per_page=100&page=%d", githubAPIURL, path, page),
+			string(basic.Password),
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		packages := make([]ghcrPackage, 0)
+
+		if err := doJSONRequest(req, &packages); err != nil {
+			return nil, fmt.Errorf("could not list GHCR packages: %v", err)
+		}
+
+		for _, pkg := range packages {
+			res = append(res, &Repository{
+				Name:      pkg.Name,
+				CreatedAt: pkg.CreatedAt,
+				URI:       fmt.Sprintf("ghcr.io/%s/%s", owner, pkg.Name),
+			})
+		}
+
+		if len(packages) < 100 {
+			return res, nil
+		}
+	}
+}
+
+func (r *Registry) listGHCRImages(repoName string, repo repository.Repository) ([]*Image, error) {
+	basic, err := repo.BasicIntegration.ReadBasicIntegration(r.BasicIntegrationID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	owner, err := r.getGHCROwner()
+
+	if err != nil {
+		return nil, err
+	}
+
+	path, err := getGHCRPackagesPath(owner, string(basic.Password))
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*Image, 0)
+
+	for page := 1; ; page++ {
+		req, err := newGHCRRequest(
+			fmt.Sprintf(
+				"%s/%s/packages/container/%s/versions?per_page=100&page=%d",
+				githubAPIURL,
+				path,
+				url.PathEscape(repoName),
+				page,
+			),
+			string(basic.Password),
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		versions := make([]ghcrPackageVersion, 0)
+
+		if err := doJSONRequest(req, &versions); err != nil {
+			return nil, fmt.Errorf("could not list GHCR package versions: %v", err)
+		}
+
+		for _, version := range versions {
+			pushedAt := version.CreatedAt
+
+			for _, tag := range version.Metadata.Container.Tags {
+				res = append(res, &Image{
+					Digest:         version.Name,
+					Tag:            tag,
+					RepositoryName: repoName,
+					PushedAt:       &pushedAt,
+				})
+			}
+		}
+
+		if len(versions) < 100 {
+			return res, nil
+		}
+	}
+}
@@ -0,0 +1,183 @@
+package registry
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/porter-dev/porter/internal/models/integrations"
+	"github.com/porter-dev/porter/internal/repository"
+)
+
+// harborPageSize is the number of repositories or artifacts that are listed per
+// request
+const harborPageSize = 100
+
+type harborRepository struct {
+	// Name is the name of the repository, prefixed with the project
+	Name         string    `json:"name"`
+	CreationTime time.Time `json:"creation_time"`
+}
+
+type harborArtifact struct {
+	Digest   string    `json:"digest"`
+	PushTime time.Time `json:"push_time"`
+	Tags     []struct {
+		Name string `json:"name"`
+	} `json:"tags"`
+}
+
+// getHarborProject returns the URL and project of a Harbor registry, which has a
+// URL of the form <host>/<project>
+func (r *Registry) getHarborProject() (*url.URL, string, error) {
+	parsedURL, err := parseRegistryURL(r.URL)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	project := strings.Split(parsedURL.Path, "/")[0]
+
+	if project == "" {
+		return nil, "", fmt.Errorf("Harbor registry url must be of the form <host>/<project>")
+	}
+
+	return parsedURL, project, nil
+}
+
+// newHarborRequest returns a paginated request to the Harbor API
+func newHarborRequest(
+	parsedURL *url.URL,
+	basic *integrations.BasicIntegration,
+	path string,
+	query url.Values,
+	page int,
+) (*http.Request, error) {
+	query.Set("page", fmt.Sprintf("%d", page))
+	query.Set("page_size", fmt.Sprintf("%d", harborPageSize))
+
+	req, err := http.NewRequest(
+		"GET",
+		fmt.Sprintf("%s://%s/api/v2.0/%s?%s", parsedURL.Scheme, parsedURL.Host, path, query.Encode()),
+		nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.SetBasicAuth(string(basic.Username), string(basic.Password))
+
+	return req, nil
+}
+
+func (r *Registry) listHarborRepositories(repo repository.Repository) ([]*Repository, error) {
+	basic, err := repo.BasicIntegration.ReadBasicIntegration(r.BasicIntegrationID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	parsedURL, project, err := r.getHarborProject()
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*Repository, 0)
+
+	for page := 1; ; page++ {
+		req, err := newHarborRequest(
+			parsedURL,
+			basic,
+			fmt.Sprintf("projects/%s/repositories", url.PathEscape(project)),
+			url.Values{},
+			page,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		repos := make([]harborRepository, 0)
+
+		if err := doJSONRequest(req, &repos); err != nil {
+			return nil, fmt.Errorf("could not list Harbor repositories: %v", err)
+		}
+
+		for _, harborRepo := range repos {
+			res = append(res, &Repository{
+				Name:      strings.TrimPrefix(harborRepo.Name, project+"/"),
+				CreatedAt: harborRepo.CreationTime,
+				URI:       parsedURL.Host + "/" + harborRepo.Name,
+			})
+		}
+
+		if len(repos) < harborPageSize {
+			return res, nil
+		}
+	}
+}
+
+func (r *Registry) listHarborImages(repoName string, repo repository.Repository) ([]*Image, error) {
+	basic, err := repo.BasicIntegration.ReadBasicIntegration(r.BasicIntegrationID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	parsedURL, project, err := r.getHarborProject()
+
+	if err != nil {
+		return nil, err
+	}
+
+	query := url.Values{}
+	query.Set("with_tag", "true")
+
+	res := make([]*Image, 0)
+
+	for page := 1; ; page++ {
+		// repository names that contain slashes must be encoded twice
+		req, err := newHarborRequest(
+			parsedURL,
+			basic,
+			fmt.Sprintf(
+				"projects/%s/repositories/%s/artifacts",
+				url.PathEscape(project),
+				url.PathEscape(url.PathEscape(repoName)),
+			),
+			query,
+			page,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		artifacts := make([]harborArtifact, 0)
+
+		if err := doJSONRequest(req, &artifacts); err != nil {
+			return nil, fmt.Errorf("could not list Harbor artifacts: %v", err)
+		}
+
+		for _, artifact := range artifacts {
+			pushedAt := artifact.PushTime
+
+			for _, tag := range artifact.Tags {
+				res = append(res, &Image{
+					Digest:         artifact.Digest,
+					Tag:            tag.Name,
+					RepositoryName: repoName,
+					PushedAt:       &pushedAt,
+				})
+			}
+		}
+
+		if len(artifacts) < harborPageSize {
+			return res, nil
+		}
+	}
+}
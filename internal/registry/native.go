@@ -0,0 +1,187 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// nativeAPIClient is the client used to call the native APIs of registry services
+var nativeAPIClient = &http.Client{
+	Timeout: 30 * time.Second,
+}
+
+// parseRegistryURL parses a registry URL, which may not include the protocol
+func parseRegistryURL(registryURL string) (*url.URL, error) {
+	if !strings.Contains(registryURL, "://") {
+		registryURL = "https://" + registryURL
+	}
+
+	parsedURL, err := url.Parse(registryURL)
+
+	if err != nil {
+		return nil, fmt.Errorf("invalid registry url %s: %v", registryURL, err)
+	}
+
+	parsedURL.Path = strings.Trim(parsedURL.Path, "/")
+
+	return parsedURL, nil
+}
+
+// doJSONRequest sends a request and decodes the JSON response into v, returning an
+// error if the response does not have a 2xx status code
+func doJSONRequest(req *http.Request, v interface{}) error {
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := nativeAPIClient.Do(req)
+
+	if err != nil {
+		return err
+	}
+
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		body, _ := ioutil.ReadAll(resp.Body)
+
+		return &nativeAPIError{
+			StatusCode: resp.StatusCode,
+			URL:        req.URL.String(),
+			Body:       strings.TrimSpace(string(body)),
+		}
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// nativeAPIError is returned when the API of a registry service responds with a
+// non-2xx status code
+type nativeAPIError struct {
+	StatusCode int
+	URL        string
+	Body       string
+}
+
+func (e *nativeAPIError) Error() string {
+	return fmt.Sprintf("request to %s failed with status %d: %s", e.URL, e.StatusCode, e.Body)
+}
+
+type bearerTokenResp struct {
+	Token       string `json:"token"`
+	AccessToken string `json:"access_token"`
+}
+
+// getBearerToken exchanges basic credentials for a bearer token that is scoped to
+// the given scope, using the token authentication of the Docker registry API. The
+// realm and service of the token server are read from the challenge that the
+// registry responds with.
+func getBearerToken(baseURL *url.URL, scope, username, password string) (string, error) {
+	req, err := http.NewRequest("GET", fmt.Sprintf("%s://%s/v2/", baseURL.Scheme, baseURL.Host), nil)
+
+	if err != nil {
+		return "", err
+	}
+
+	resp, err := nativeAPIClient.Do(req)
+
+	if err != nil {
+		return "", err
+	}
+
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusUnauthorized {
+		return "", fmt.Errorf("registry %s did not respond with an authentication challenge", baseURL.Host)
+	}
+
+	realm, service, err := parseBearerChallenge(resp.Header.Get("WWW-Authenticate"))
+
+	if err != nil {
+		return "", err
+	}
+
+	return fetchBearerToken(realm, service, scope, username, password)
+}
+
+// fetchBearerToken requests a token with the given scope from the token server of
+// a registry. The credentials are omitted if the username is empty, which requests
+// an anonymous token.
+func fetchBearerToken(realm, service, scope, username, password string) (string, error) {
+	tokenURL, err := url.Parse(realm)
+
+	if err != nil {
+		return "", fmt.Errorf("invalid token realm %s: %v", realm, err)
+	}
+
+	query := tokenURL.Query()
+
+	if service != "" {
+		query.Set("service", service)
+	}
+
+	query.Set("scope", scope)
+	tokenURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequest("GET", tokenURL.String(), nil)
+
+	if err != nil {
+		return "", err
+	}
+
+	if username != "" {
+		req.SetBasicAuth(username, password)
+	}
+
+	tokenResp := &bearerTokenResp{}
+
+	if err := doJSONRequest(req, tokenResp); err != nil {
+		return "", fmt.Errorf("could not exchange credentials for a token: %v", err)
+	}
+
+	if tokenResp.Token != "" {
+		return tokenResp.Token, nil
+	}
+
+	if tokenResp.AccessToken != "" {
+		return tokenResp.AccessToken, nil
+	}
+
+	return "", fmt.Errorf("token server %s did not return a token", tokenURL.Host)
+}
+
+// parseBearerChallenge parses the realm and service of a WWW-Authenticate header of
+// the form: Bearer realm="https://auth.example.com/token",service="example.com"
+func parseBearerChallenge(header string) (realm, service string, err error) {
+	spl := strings.SplitN(strings.TrimSpace(header), " ", 2)
+
+	if len(spl) != 2 || !strings.EqualFold(spl[0], "bearer") {
+		return "", "", fmt.Errorf("unsupported authentication challenge: %s", header)
+	}
+
+	for _, param := range strings.Split(spl[1], ",") {
+		kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+
+		if len(kv) != 2 {
+			continue
+		}
+
+		val := strings.Trim(kv[1], `"`)
+
+		switch strings.ToLower(kv[0]) {
+		case "realm":
+			realm = val
+		case "service":
+			service = val
+		}
+	}
+
+	if realm == "" {
+		return "", "", fmt.Errorf("authentication challenge does not have a realm: %s", header)
+	}
+
+	return realm, service, nil
+}
@@ -0,0 +1,156 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/models/integrations"
+	"github.com/porter-dev/porter/internal/repository"
+	memory "github.com/porter-dev/porter/internal/repository/memory"
+)
+
+func newBasicRegistry(t *testing.T, registryURL string, service integrations.IntegrationService) (*Registry, repository.Repository) {
+	t.Helper()
+
+	repo := memory.NewRepository(true)
+
+	basic, err := repo.BasicIntegration.CreateBasicIntegration(&integrations.BasicIntegration{
+		Username: []byte("porter"),
+		Password: []byte("secret"),
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	return &Registry{
+		URL:                registryURL,
+		BasicIntegrationID: basic.ID,
+		Service:            service,
+	}, *repo
+}
+
+func TestParseBearerChallenge(t *testing.T) {
+	realm, service, err := parseBearerChallenge(`Bearer realm="https://porter.azurecr.io/oauth2/token",service="porter.azurecr.io"`)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if realm != "https://porter.azurecr.io/oauth2/token" || service != "porter.azurecr.io" {
+		t.Errorf("challenge parsed incorrectly: realm %s, service %s\n", realm, service)
+	}
+
+	if _, _, err := parseBearerChallenge(`Basic realm="registry"`); err == nil {
+		t.Errorf("expected an error for a basic challenge\n")
+	}
+}
+
+func TestListACRRepositories(t *testing.T) {
+	var server *httptest.Server
+
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/":
+			w.Header().Set("WWW-Authenticate", fmt.Sprintf(`Bearer realm="%s/oauth2/token",service="porter.azurecr.io"`, server.URL))
+			w.WriteHeader(http.StatusUnauthorized)
+		case "/oauth2/token":
+			username, password, _ := r.BasicAuth()
+
+			if username != "porter" || password != "secret" || r.URL.Query().Get("scope") != "registry:catalog:*" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			json.NewEncoder(w).Encode(map[string]string{"access_token": "exchanged"})
+		case "/acr/v1/_catalog":
+			if r.Header.Get("Authorization") != "Bearer exchanged" {
+				w.WriteHeader(http.StatusUnauthorized)
+				return
+			}
+
+			json.NewEncoder(w).Encode(map[string][]string{"repositories": {"api", "web"}})
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+
+	defer server.Close()
+
+	reg, repo := newBasicRegistry(t, server.URL, integrations.ACR)
+
+	repos, err := reg.ListRepositories(repo, nil)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	names := make([]string, 0)
+
+	for _, r := range repos {
+		names = append(names, r.Name)
+	}
+
+	if !reflect.DeepEqual(names, []string{"api", "web"}) {
+		t.Errorf("repositories incorrect: expected [api web], got %v\n", names)
+	}
+}
+
+func TestListHarborImages(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		username, password, _ := r.BasicAuth()
+
+		if username != "porter" || password != "secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		// the repository name is encoded twice
+		if r.URL.EscapedPath() != "/api/v2.0/projects/apps/repositories/team%252Fweb/artifacts" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+
+		w.Write([]byte(`[
+			{"digest": "sha256:1", "push_time": "2021-05-01T10:00:00Z", "tags": [{"name": "v1"}, {"name": "latest"}]},
+			{"digest": "sha256:0", "push_time": "2021-04-01T10:00:00Z", "tags": []}
+		]`))
+	}))
+
+	defer server.Close()
+
+	reg, repo := newBasicRegistry(t, server.URL+"/apps", integrations.Harbor)
+
+	images, err := reg.ListImages("team/web", repo, nil)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(images) != 2 {
+		t.Fatalf("expected 2 images, got %d\n", len(images))
+	}
+
+	for i, tag := range []string{"v1", "latest"} {
+		if images[i].Tag != tag || images[i].Digest != "sha256:1" || images[i].RepositoryName != "team/web" {
+			t.Errorf("image %d incorrect: %+v\n", i, images[i])
+		}
+	}
+}
+
+func TestExternalizeService(t *testing.T) {
+	reg := &models.Registry{
+		URL:                "ghcr.io/porter-dev",
+		BasicIntegrationID: 1,
+		Service:            integrations.GHCR,
+	}
+
+	if serv := reg.Externalize().Service; serv != integrations.GHCR {
+		t.Errorf("service incorrect: expected %s, got %s\n", integrations.GHCR, serv)
+	}
+}
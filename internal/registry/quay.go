@@ -0,0 +1,173 @@
+package registry
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/porter-dev/porter/internal/repository"
+)
+
+type quayRepositoriesResp struct {
+	Repositories []struct {
+		Namespace string `json:"namespace"`
+		Name      string `json:"name"`
+	} `json:"repositories"`
+
+	NextPage string `json:"next_page"`
+}
+
+type quayTagsResp struct {
+	Tags []struct {
+		Name           string `json:"name"`
+		ManifestDigest string `json:"manifest_digest"`
+		StartTS        int64  `json:"start_ts"`
+	} `json:"tags"`
+
+	HasAdditional bool `json:"has_additional"`
+}
+
+// getQuayNamespace returns the API URL and namespace of a Quay registry, which has
+// a URL of the form quay.io/<namespace>
+func (r *Registry) getQuayNamespace() (*url.URL, string, error) {
+	parsedURL, err := parseRegistryURL(r.URL)
+
+	if err != nil {
+		return nil, "", err
+	}
+
+	namespace := strings.Split(parsedURL.Path, "/")[0]
+
+	if namespace == "" {
+		return nil, "", fmt.Errorf("Quay registry url must be of the form quay.io/<namespace>")
+	}
+
+	return parsedURL, namespace, nil
+}
+
+// newQuayRequest returns a request to the Quay API. The API is authenticated with
+// an OAuth application token, which is also used to pull images with the
+// $oauthtoken username.
+func newQuayRequest(parsedURL *url.URL, token, path string, query url.Values) (*http.Request, error) {
+	req, err := http.NewRequest(
+		"GET",
+		fmt.Sprintf("%s://%s/api/v1/%s?%s", parsedURL.Scheme, parsedURL.Host, path, query.Encode()),
+		nil,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	return req, nil
+}
+
+func (r *Registry) listQuayRepositories(repo repository.Repository) ([]*Repository, error) {
+	basic, err := repo.BasicIntegration.ReadBasicIntegration(r.BasicIntegrationID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	parsedURL, namespace, err := r.getQuayNamespace()
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*Repository, 0)
+	nextPage := ""
+
+	for {
+		query := url.Values{}
+		query.Set("namespace", namespace)
+
+		if nextPage != "" {
+			query.Set("next_page", nextPage)
+		}
+
+		req, err := newQuayRequest(parsedURL, string(basic.Password), "repository", query)
+
+		if err != nil {
+			return nil, err
+		}
+
+		repos := &quayRepositoriesResp{}
+
+		if err := doJSONRequest(req, repos); err != nil {
+			return nil, fmt.Errorf("could not list Quay repositories: %v", err)
+		}
+
+		for _, quayRepo := range repos.Repositories {
+			res = append(res, &Repository{
+				Name: quayRepo.Name,
+				URI:  fmt.Sprintf("%s/%s/%s", parsedURL.Host, quayRepo.Namespace, quayRepo.Name),
+			})
+		}
+
+		if repos.NextPage == "" {
+			return res, nil
+		}
+
+		nextPage = repos.NextPage
+	}
+}
+
+func (r *Registry) listQuayImages(repoName string, repo repository.Repository) ([]*Image, error) {
+	basic, err := repo.BasicIntegration.ReadBasicIntegration(r.BasicIntegrationID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	parsedURL, namespace, err := r.getQuayNamespace()
+
+	if err != nil {
+		return nil, err
+	}
+
+	res := make([]*Image, 0)
+
+	for page := 1; ; page++ {
+		query := url.Values{}
+		query.Set("onlyActiveTags", "true")
+		query.Set("limit", "100")
+		query.Set("page", fmt.Sprintf("%d", page))
+
+		req, err := newQuayRequest(
+			parsedURL,
+			string(basic.Password),
+			fmt.Sprintf("repository/%s/%s/tag/", namespace, repoName),
+			query,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		tags := &quayTagsResp{}
+
+		if err := doJSONRequest(req, tags); err != nil {
+			return nil, fmt.Errorf("could not list Quay tags: %v", err)
+		}
+
+		for _, tag := range tags.Tags {
+			pushedAt := time.Unix(tag.StartTS, 0)
+
+			res = append(res, &Image{
+				Digest:         tag.ManifestDigest,
+				Tag:            tag.Name,
+				RepositoryName: repoName,
+				PushedAt:       &pushedAt,
+			})
+		}
+
+		if !tags.HasAdditional {
+			return res, nil
+		}
+	}
+}
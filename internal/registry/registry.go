@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/aws/aws-sdk-go/aws"
 	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/ecr"
 	"github.com/porter-dev/porter/internal/models"
@@ -76,6 +77,17 @@ func (r *Registry) ListRepositories(
 	}
 
 	if r.BasicIntegrationID != 0 {
+		switch r.Service {
+		case ints.GHCR:
+			return r.listGHCRRepositories(repo)
+		case ints.ACR:
+			return r.listACRRepositories(repo)
+		case ints.Quay:
+			return r.listQuayRepositories(repo)
+		case ints.Harbor:
+			return r.listHarborRepositories(repo)
+		}
+
 		return r.listPrivateRegistryRepositories(repo)
 	}
 
@@ -460,6 +472,17 @@ func (r *Registry) ListImages(
 	}
 
 	if r.BasicIntegrationID != 0 {
+		switch r.Service {
+		case ints.GHCR:
+			return r.listGHCRImages(repoName, repo)
+		case ints.ACR:
+			return r.listACRImages(repoName, repo)
+		case ints.Quay:
+			return r.listQuayImages(repoName, repo)
+		case ints.Harbor:
+			return r.listHarborImages(repoName, repo)
+		}
+
 		return r.listPrivateRegistryImages(repoName, repo)
 	}
 
@@ -467,7 +490,7 @@ func (r *Registry) ListImages(
 }
 
 func (r *Registry) listECRImages(repoName string, repo repository.Repository) ([]*Image, error) {
-	aws, err := repo.AWSIntegration.ReadAWSIntegration(
+	awsInt, err := repo.AWSIntegration.ReadAWSIntegration(
 		r.AWSIntegrationID,
 	)
 
@@ -475,7 +498,7 @@ func (r *Registry) listECRImages(repoName string, repo repository.Repository) ([
 		return nil, err
 	}
 
-	sess, err := aws.GetSession()
+	sess, err := awsInt.GetSession()
 
 	if err != nil {
 		return nil, err
@@ -483,41 +506,23 @@ func (r *Registry) listECRImages(repoName string, repo repository.Repository) ([
 
 	svc := ecr.New(sess)
 
-	resp, err := svc.ListImages(&ecr.ListImagesInput{
-		RepositoryName: &repoName,
-	})
-
-	if err != nil {
-		return nil, err
-	}
+	imageDetails := make([]*ecr.ImageDetail, 0)
 
-	describeResp, err := svc.DescribeImages(&ecr.DescribeImagesInput{
+	// page through every tagged image, since repositories may have thousands of tags
+	err = svc.DescribeImagesPages(&ecr.DescribeImagesInput{
 		RepositoryName: &repoName,
-		ImageIds:       resp.ImageIds,
+		Filter: &ecr.DescribeImagesFilter{
+			TagStatus: aws.String(ecr.TagStatusTagged),
+		},
+	}, func(page *ecr.DescribeImagesOutput, lastPage bool) bool {
+		imageDetails = append(imageDetails, page.ImageDetails...)
+		return true
 	})
 
 	if err != nil {
 		return nil, err
 	}
 
-	imageDetails := describeResp.ImageDetails
-
-	nextToken := describeResp.NextToken
-
-	for nextToken != nil {
-		describeResp, err := svc.DescribeImages(&ecr.DescribeImagesInput{
-			RepositoryName: &repoName,
-			ImageIds:       resp.ImageIds,
-		})
-
-		if err != nil {
-			return nil, err
-		}
-
-		nextToken = describeResp.NextToken
-		imageDetails = append(imageDetails, describeResp.ImageDetails...)
-	}
-
 	res := make([]*Image, 0)
 
 	for _, img := range imageDetails {
@@ -623,22 +628,39 @@ func (r *Registry) listDOCRImages(
 
 	name := urlArr[1]
 
-	tags, _, err := client.Registry.ListRepositoryTags(context.TODO(), name, repoName, &godo.ListOptions{})
+	res := make([]*Image, 0)
+	opts := &godo.ListOptions{PerPage: 200}
 
-	if err != nil {
-		return nil, err
-	}
+	for {
+		tags, resp, err := client.Registry.ListRepositoryTags(context.TODO(), name, repoName, opts)
 
-	res := make([]*Image, 0)
+		if err != nil {
+			return nil, err
+		}
 
-	for _, tag := range tags {
-		res = append(res, &Image{
-			RepositoryName: repoName,
-			Tag:            tag.Tag,
-		})
-	}
+		for _, tag := range tags {
+			updatedAt := tag.UpdatedAt
 
-	return res, nil
+			res = append(res, &Image{
+				Digest:         tag.ManifestDigest,
+				RepositoryName: repoName,
+				Tag:            tag.Tag,
+				PushedAt:       &updatedAt,
+			})
+		}
+
+		if resp == nil || resp.Links == nil || resp.Links.IsLastPage() {
+			return res, nil
+		}
+
+		page, err := resp.Links.CurrentPage()
+
+		if err != nil {
+			return nil, err
+		}
+
+		opts.Page = page + 1
+	}
 }
 
 func (r *Registry) listPrivateRegistryImages(repoName string, repo repository.Repository) ([]*Image, error) {
@@ -819,6 +841,8 @@ func (r *Registry) GetDockerConfigJSON(
 		conf, err = r.getDOCRDockerConfigFile(repo, doAuth)
 	}
 
+	// GHCR, ACR, Quay and Harbor registries pull images with the credentials of
+	// their basic integration as well
 	if r.BasicIntegrationID != 0 {
 		conf, err = r.getPrivateRegistryDockerConfigFile(repo)
 	}
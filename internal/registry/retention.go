@@ -0,0 +1,343 @@
+package registry
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/digitalocean/godo"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/oauth"
+	"github.com/porter-dev/porter/internal/repository"
+	"golang.org/x/oauth2"
+)
+
+// ecrBatchDeleteSize is the maximum number of images that can be deleted by a
+// single ECR request
+const ecrBatchDeleteSize = 100
+
+// RetentionDecision is an image of a retention report, along with the reason that
+// it is kept or deleted
+type RetentionDecision struct {
+	*Image
+
+	Reason string `json:"reason"`
+}
+
+// RetentionReport lists the images of a repository that a retention policy keeps
+// and deletes
+type RetentionReport struct {
+	RepositoryName string               `json:"repository_name"`
+	Kept           []*RetentionDecision `json:"kept"`
+	Deleted        []*RetentionDecision `json:"deleted"`
+
+	// Warnings are the clusters that could not be scanned for deployed images. If
+	// there are warnings, the images of the report are not deleted.
+	Warnings []string `json:"warnings,omitempty"`
+}
+
+// DeployedImages are the tags and digests of a repository that are referenced by
+// the workloads of a cluster
+type DeployedImages struct {
+	Tags    map[string]bool
+	Digests map[string]bool
+}
+
+// NewDeployedImages creates an empty set of deployed images
+func NewDeployedImages() *DeployedImages {
+	return &DeployedImages{
+		Tags:    make(map[string]bool),
+		Digests: make(map[string]bool),
+	}
+}
+
+// Add adds the image references that belong to the repository with the given URI,
+// such as registry.digitalocean.com/porter/web. References of the form
+// <uri>:<tag>, <uri>@<digest> and <uri>:<tag>@<digest> are matched.
+func (d *DeployedImages) Add(refs []string, repoURI string) {
+	for _, ref := range refs {
+		name, tag, digest := parseImageRef(ref)
+
+		if name != repoURI {
+			continue
+		}
+
+		if tag != "" {
+			d.Tags[tag] = true
+		}
+
+		if digest != "" {
+			d.Digests[digest] = true
+		}
+	}
+}
+
+// Contains returns whether an image is deployed, by tag or by digest
+func (d *DeployedImages) Contains(img *Image) bool {
+	return d.Tags[img.Tag] || (img.Digest != "" && d.Digests[img.Digest])
+}
+
+// parseImageRef splits an image reference into its name, tag and digest. The name
+// does not include the protocol.
+func parseImageRef(ref string) (name, tag, digest string) {
+	name = ref
+
+	if spl := strings.SplitN(name, "://", 2); len(spl) == 2 {
+		name = spl[1]
+	}
+
+	if i := strings.Index(name, "@"); i != -1 {
+		name, digest = name[:i], name[i+1:]
+	}
+
+	// the tag follows the last colon, unless the colon is part of the host's port
+	if i := strings.LastIndex(name, ":"); i != -1 && !strings.Contains(name[i+1:], "/") {
+		name, tag = name[:i], name[i+1:]
+	}
+
+	return name, tag, digest
+}
+
+// GetRepositoryURI returns the name that images of a repository are pulled by
+func (r *Registry) GetRepositoryURI(repoName string) string {
+	uri := r.URL
+
+	if spl := strings.SplitN(uri, "://", 2); len(spl) == 2 {
+		uri = spl[1]
+	}
+
+	return strings.TrimSuffix(uri, "/") + "/" + repoName
+}
+
+// SupportsRetention returns whether the images of the registry can be deleted by a
+// retention policy
+func (r *Registry) SupportsRetention() bool {
+	return r.AWSIntegrationID != 0 || r.DOIntegrationID != 0
+}
+
+// EvaluateRetention decides which images of a repository a retention policy keeps
+// and deletes. A tag is kept if it is deployed, if it is one of the most recently
+// pushed tags, or if it is younger than the maximum age. Since images are deleted
+// by digest, a tag that would be deleted is kept if its digest is shared with a
+// tag that is kept.
+func EvaluateRetention(
+	policy *models.RetentionPolicy,
+	images []*Image,
+	deployed *DeployedImages,
+	now time.Time,
+) *RetentionReport {
+	report := &RetentionReport{
+		RepositoryName: policy.RepositoryName,
+		Kept:           make([]*RetentionDecision, 0),
+		Deleted:        make([]*RetentionDecision, 0),
+	}
+
+	sorted := make([]*Image, len(images))
+	copy(sorted, images)
+
+	// sort from the most to the least recently pushed, with unknown push times last
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].PushedAt == nil || sorted[j].PushedAt == nil {
+			return sorted[j].PushedAt == nil && sorted[i].PushedAt != nil
+		}
+
+		return sorted[i].PushedAt.After(*sorted[j].PushedAt)
+	})
+
+	maxAge := time.Duration(policy.MaxAgeDays) * 24 * time.Hour
+	keptDigests := make(map[string]string)
+	candidates := make([]*Image, 0)
+
+	for i, img := range sorted {
+		var reason string
+
+		switch {
+		case policy.KeepDeployed && deployed != nil && deployed.Contains(img):
+			reason = "deployed in a cluster of the project"
+		case uint(i) < policy.KeepLast:
+			reason = fmt.Sprintf("one of the %d most recently pushed tags", policy.KeepLast)
+		case policy.KeepLast == 0 && policy.MaxAgeDays == 0:
+			reason = "the policy does not delete images"
+		case img.PushedAt == nil:
+			reason = "push time is unknown"
+		case policy.MaxAgeDays != 0 && now.Sub(*img.PushedAt) < maxAge:
+			reason = fmt.Sprintf("pushed less than %d days ago", policy.MaxAgeDays)
+		default:
+			candidates = append(candidates, img)
+			continue
+		}
+
+		report.Kept = append(report.Kept, &RetentionDecision{img, reason})
+
+		if img.Digest != "" {
+			keptDigests[img.Digest] = img.Tag
+		}
+	}
+
+	for _, img := range candidates {
+		if tag, ok := keptDigests[img.Digest]; ok && img.Digest != "" {
+			report.Kept = append(report.Kept, &RetentionDecision{
+				img,
+				fmt.Sprintf("shares its digest with the kept tag %s", tag),
+			})
+
+			continue
+		}
+
+		var reason string
+
+		if policy.MaxAgeDays != 0 {
+			reason = fmt.Sprintf("pushed more than %d days ago", policy.MaxAgeDays)
+		} else {
+			reason = fmt.Sprintf("not one of the %d most recently pushed tags", policy.KeepLast)
+		}
+
+		report.Deleted = append(report.Deleted, &RetentionDecision{img, reason})
+	}
+
+	return report
+}
+
+// DeleteImages deletes images from a repository by digest, which removes every tag
+// of each image. Deletion is supported for ECR and DOCR registries: the space of
+// images deleted from DOCR is reclaimed by the registry's garbage collection.
+func (r *Registry) DeleteImages(
+	repoName string,
+	images []*Image,
+	repo repository.Repository,
+	doAuth *oauth2.Config, // only required if using DOCR
+) error {
+	digests := make([]string, 0)
+	seen := make(map[string]bool)
+
+	for _, img := range images {
+		if img.Digest == "" {
+			return fmt.Errorf("image %s:%s does not have a digest", repoName, img.Tag)
+		}
+
+		if !seen[img.Digest] {
+			seen[img.Digest] = true
+			digests = append(digests, img.Digest)
+		}
+	}
+
+	if len(digests) == 0 {
+		return nil
+	}
+
+	if r.AWSIntegrationID != 0 {
+		return r.deleteECRImages(repoName, digests, repo)
+	}
+
+	if r.DOIntegrationID != 0 {
+		return r.deleteDOCRImages(repoName, digests, repo, doAuth)
+	}
+
+	return fmt.Errorf("deleting images is only supported for ECR and DOCR registries")
+}
+
+func (r *Registry) deleteECRImages(repoName string, digests []string, repo repository.Repository) error {
+	awsInt, err := repo.AWSIntegration.ReadAWSIntegration(
+		r.AWSIntegrationID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	sess, err := awsInt.GetSession()
+
+	if err != nil {
+		return err
+	}
+
+	svc := ecr.New(sess)
+
+	for start := 0; start < len(digests); start += ecrBatchDeleteSize {
+		end := start + ecrBatchDeleteSize
+
+		if end > len(digests) {
+			end = len(digests)
+		}
+
+		ids := make([]*ecr.ImageIdentifier, 0, end-start)
+
+		for _, digest := range digests[start:end] {
+			ids = append(ids, &ecr.ImageIdentifier{
+				ImageDigest: aws.String(digest),
+			})
+		}
+
+		resp, err := svc.BatchDeleteImage(&ecr.BatchDeleteImageInput{
+			RepositoryName: &repoName,
+			ImageIds:       ids,
+		})
+
+		if err != nil {
+			return err
+		}
+
+		for _, failure := range resp.Failures {
+			// images that were already deleted do not need to be deleted again
+			if aws.StringValue(failure.FailureCode) == ecr.ImageFailureCodeImageNotFound {
+				continue
+			}
+
+			return fmt.Errorf(
+				"could not delete image %s: %s",
+				aws.StringValue(failure.ImageId.ImageDigest),
+				aws.StringValue(failure.FailureReason),
+			)
+		}
+	}
+
+	return nil
+}
+
+func (r *Registry) deleteDOCRImages(
+	repoName string,
+	digests []string,
+	repo repository.Repository,
+	doAuth *oauth2.Config,
+) error {
+	oauthInt, err := repo.OAuthIntegration.ReadOAuthIntegration(
+		r.DOIntegrationID,
+	)
+
+	if err != nil {
+		return err
+	}
+
+	tok, _, err := oauth.GetAccessToken(oauthInt.SharedOAuthModel, doAuth, oauth.MakeUpdateOAuthIntegrationTokenFunction(oauthInt, repo))
+
+	if err != nil {
+		return err
+	}
+
+	client := godo.NewFromToken(tok)
+
+	urlArr := strings.Split(r.URL, "/")
+
+	if len(urlArr) != 2 {
+		return fmt.Errorf("invalid digital ocean registry url")
+	}
+
+	for _, digest := range digests {
+		resp, err := client.Registry.DeleteManifest(context.TODO(), urlArr[1], repoName, digest)
+
+		// images that were already deleted do not need to be deleted again
+		if resp != nil && resp.StatusCode == 404 {
+			continue
+		}
+
+		if err != nil {
+			return fmt.Errorf("could not delete image %s: %v", digest, err)
+		}
+	}
+
+	return nil
+}
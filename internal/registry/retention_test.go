@@ -0,0 +1,101 @@
+package registry
+
+import (
+	"testing"
+	"time"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+func TestParseImageRef(t *testing.T) {
+	tests := []struct {
+		ref               string
+		name, tag, digest string
+	}{
+		{"registry.digitalocean.com/porter/web:abc123", "registry.digitalocean.com/porter/web", "abc123", ""},
+		{"localhost:5000/web", "localhost:5000/web", "", ""},
+		{"localhost:5000/web:v1", "localhost:5000/web", "v1", ""},
+		{"docker-pullable://123.dkr.ecr.us-east-2.amazonaws.com/web@sha256:aaa", "123.dkr.ecr.us-east-2.amazonaws.com/web", "", "sha256:aaa"},
+		{"123.dkr.ecr.us-east-2.amazonaws.com/web:v2@sha256:bbb", "123.dkr.ecr.us-east-2.amazonaws.com/web", "v2", "sha256:bbb"},
+	}
+
+	for _, test := range tests {
+		name, tag, digest := parseImageRef(test.ref)
+
+		if name != test.name || tag != test.tag || digest != test.digest {
+			t.Errorf("parseImageRef(%s): got (%s, %s, %s), want (%s, %s, %s)", test.ref, name, tag, digest, test.name, test.tag, test.digest)
+		}
+	}
+}
+
+func TestEvaluateRetention(t *testing.T) {
+	now := time.Date(2021, 6, 1, 0, 0, 0, 0, time.UTC)
+
+	daysAgo := func(days int) *time.Time {
+		pushedAt := now.Add(-time.Duration(days) * 24 * time.Hour)
+		return &pushedAt
+	}
+
+	images := []*Image{
+		{Tag: "old-deployed", Digest: "sha256:1", PushedAt: daysAgo(100)},
+		{Tag: "latest", Digest: "sha256:5", PushedAt: daysAgo(1)},
+		{Tag: "old", Digest: "sha256:2", PushedAt: daysAgo(90)},
+		{Tag: "old-shared", Digest: "sha256:5", PushedAt: daysAgo(80)},
+		{Tag: "recent", Digest: "sha256:4", PushedAt: daysAgo(10)},
+		{Tag: "older", Digest: "sha256:3", PushedAt: daysAgo(60)},
+		{Tag: "unknown", Digest: "sha256:6"},
+	}
+
+	deployed := NewDeployedImages()
+	deployed.Add([]string{
+		"registry.digitalocean.com/porter/web@sha256:1",
+		"registry.digitalocean.com/porter/api:older",
+	}, "registry.digitalocean.com/porter/web")
+
+	report := EvaluateRetention(&models.RetentionPolicy{
+		RepositoryName: "web",
+		KeepLast:       1,
+		MaxAgeDays:     30,
+		KeepDeployed:   true,
+	}, images, deployed, now)
+
+	kept := make(map[string]bool)
+	deleted := make(map[string]bool)
+
+	for _, decision := range report.Kept {
+		kept[decision.Tag] = true
+	}
+
+	for _, decision := range report.Deleted {
+		deleted[decision.Tag] = true
+	}
+
+	for _, tag := range []string{"latest", "recent", "old-deployed", "old-shared", "unknown"} {
+		if !kept[tag] {
+			t.Errorf("expected tag %s to be kept", tag)
+		}
+	}
+
+	// the tag "older" is deployed from a different repository, so it is not kept
+	for _, tag := range []string{"old", "older"} {
+		if !deleted[tag] {
+			t.Errorf("expected tag %s to be deleted", tag)
+		}
+	}
+
+	if len(report.Kept)+len(report.Deleted) != len(images) {
+		t.Errorf("expected %d images in report, got %d", len(images), len(report.Kept)+len(report.Deleted))
+	}
+}
+
+func TestEvaluateRetentionNoRules(t *testing.T) {
+	pushedAt := time.Now().Add(-1000 * time.Hour)
+
+	report := EvaluateRetention(&models.RetentionPolicy{RepositoryName: "web"}, []*Image{
+		{Tag: "v1", Digest: "sha256:1", PushedAt: &pushedAt},
+	}, nil, time.Now())
+
+	if len(report.Deleted) != 0 {
+		t.Errorf("expected a policy without rules to keep every image, got %d deleted", len(report.Deleted))
+	}
+}
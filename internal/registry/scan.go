@@ -0,0 +1,539 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/ecr"
+	"github.com/porter-dev/porter/internal/repository"
+	"golang.org/x/oauth2"
+	"golang.org/x/oauth2/google"
+)
+
+// The severities of a vulnerability, from the most to the least severe. The
+// severities reported by each scanner are mapped to these.
+const (
+	SeverityCritical = "critical"
+	SeverityHigh     = "high"
+	SeverityMedium   = "medium"
+	SeverityLow      = "low"
+	SeverityUnknown  = "unknown"
+)
+
+var severityRanks = map[string]int{
+	SeverityCritical: 4,
+	SeverityHigh:     3,
+	SeverityMedium:   2,
+	SeverityLow:      1,
+	SeverityUnknown:  0,
+}
+
+// IsValidSeverity returns whether a severity is one of the known severities
+func IsValidSeverity(severity string) bool {
+	_, ok := severityRanks[severity]
+
+	return ok
+}
+
+// normalizeSeverity maps the severity of a scanner to one of the known severities
+func normalizeSeverity(severity string) string {
+	switch severity = strings.ToLower(severity); severity {
+	case SeverityCritical, SeverityHigh, SeverityMedium, SeverityLow:
+		return severity
+	case "informational", "minimal", "negligible":
+		return SeverityLow
+	default:
+		return SeverityUnknown
+	}
+}
+
+// The sources of an image scan
+const (
+	ScanSourceECR   = "ecr"
+	ScanSourceGCR   = "gcr"
+	ScanSourceLocal = "local"
+)
+
+// ErrScanNotSupported is returned when the registry does not scan images and no
+// local scanner is configured
+var ErrScanNotSupported = errors.New("the registry does not scan images, and no local scanner is configured")
+
+// Vulnerability is a single vulnerability that was found in a package of an image
+type Vulnerability struct {
+	ID           string `json:"id"`
+	Severity     string `json:"severity"`
+	Package      string `json:"package,omitempty"`
+	Version      string `json:"version,omitempty"`
+	FixedVersion string `json:"fixed_version,omitempty"`
+	Description  string `json:"description,omitempty"`
+	URL          string `json:"url,omitempty"`
+}
+
+// ImageScan is the result of a vulnerability scan of an image
+type ImageScan struct {
+	RepositoryName  string           `json:"repository_name"`
+	Digest          string           `json:"digest,omitempty"`
+	Source          string           `json:"source"`
+	ScannedAt       *time.Time       `json:"scanned_at,omitempty"`
+	Vulnerabilities []*Vulnerability `json:"vulnerabilities"`
+}
+
+// VulnerabilitySummary counts the vulnerabilities of an image scan by severity
+type VulnerabilitySummary struct {
+	Critical int `json:"critical"`
+	High     int `json:"high"`
+	Medium   int `json:"medium"`
+	Low      int `json:"low"`
+	Unknown  int `json:"unknown"`
+	Total    int `json:"total"`
+}
+
+// Summary counts the vulnerabilities of the scan by severity
+func (s *ImageScan) Summary() *VulnerabilitySummary {
+	res := &VulnerabilitySummary{}
+
+	for _, vuln := range s.Vulnerabilities {
+		switch vuln.Severity {
+		case SeverityCritical:
+			res.Critical++
+		case SeverityHigh:
+			res.High++
+		case SeverityMedium:
+			res.Medium++
+		case SeverityLow:
+			res.Low++
+		default:
+			res.Unknown++
+		}
+
+		res.Total++
+	}
+
+	return res
+}
+
+// CountAtOrAbove returns the number of vulnerabilities of the scan that are at least
+// as severe as the given severity
+func (s *ImageScan) CountAtOrAbove(severity string) int {
+	res := 0
+
+	for _, vuln := range s.Vulnerabilities {
+		if severityRanks[vuln.Severity] >= severityRanks[severity] {
+			res++
+		}
+	}
+
+	return res
+}
+
+// LocalScanner scans the images of registries that do not scan images themselves
+type LocalScanner interface {
+	// ScanImage scans an image reference of the form <repository>@<digest>, pulling
+	// it with the credentials of the given docker config. The scan stops once the
+	// context is done.
+	ScanImage(ctx context.Context, imageRef string, dockerConfigJSON []byte) ([]*Vulnerability, error)
+}
+
+// DefaultScanCacheTTL is how long the scan of an image digest is reused, which
+// bounds how long new findings for the digest go unreported
+const DefaultScanCacheTTL = time.Hour
+
+// NewCachedLocalScanner returns a local scanner that reuses the scans of the given
+// scanner for ttl. Scans are made by digest, so the image that is scanned does not
+// change while its scan is cached. Failed scans are not cached.
+func NewCachedLocalScanner(scanner LocalScanner, ttl time.Duration) LocalScanner {
+	return &cachedLocalScanner{
+		scanner: scanner,
+		ttl:     ttl,
+		scans:   make(map[string]*cachedScan),
+	}
+}
+
+type cachedLocalScanner struct {
+	scanner LocalScanner
+	ttl     time.Duration
+
+	mu    sync.Mutex
+	scans map[string]*cachedScan
+}
+
+type cachedScan struct {
+	vulns     []*Vulnerability
+	expiresAt time.Time
+}
+
+func (c *cachedLocalScanner) ScanImage(ctx context.Context, imageRef string, dockerConfigJSON []byte) ([]*Vulnerability, error) {
+	c.mu.Lock()
+	scan, ok := c.scans[imageRef]
+	c.mu.Unlock()
+
+	if ok && time.Now().Before(scan.expiresAt) {
+		return scan.vulns, nil
+	}
+
+	vulns, err := c.scanner.ScanImage(ctx, imageRef, dockerConfigJSON)
+
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	// expired scans are evicted as new scans are stored, so that the cache does not
+	// grow with every digest that was ever scanned
+	for ref, scan := range c.scans {
+		if now.After(scan.expiresAt) {
+			delete(c.scans, ref)
+		}
+	}
+
+	c.scans[imageRef] = &cachedScan{
+		vulns:     vulns,
+		expiresAt: now.Add(c.ttl),
+	}
+
+	return vulns, nil
+}
+
+// GetImageScan returns the vulnerabilities of an image, given by tag or digest. The
+// findings of ECR image scans and GCR vulnerability occurrences are read from the
+// registry. Images of other registries, and ECR images that were not scanned, are
+// scanned by the local scanner if one is set.
+func (r *Registry) GetImageScan(
+	ctx context.Context,
+	repoName, ref string,
+	repo repository.Repository,
+	doAuth *oauth2.Config, // only required if using DOCR
+	local LocalScanner,
+) (*ImageScan, error) {
+	if r.AWSIntegrationID != 0 {
+		scan, err := r.getECRImageScan(repoName, ref, repo)
+
+		if aerr, ok := err.(awserr.Error); !ok || aerr.Code() != ecr.ErrCodeScanNotFoundException || local == nil {
+			return scan, err
+		}
+	}
+
+	if r.GCPIntegrationID == 0 && local == nil {
+		return nil, ErrScanNotSupported
+	}
+
+	// the other scans are made by digest, so that the result is not changed if the
+	// tag is pushed again
+	digest := ref
+
+	if !digestRegex.MatchString(ref) {
+		var err error
+
+		if digest, err = r.GetImageDigest(repoName, ref, repo, doAuth); err != nil {
+			return nil, err
+		}
+	}
+
+	if r.GCPIntegrationID != 0 {
+		return r.getGCRImageScan(repoName, digest, repo)
+	}
+
+	return r.getLocalImageScan(ctx, repoName, digest, repo, doAuth, local)
+}
+
+func (r *Registry) getECRImageScan(repoName, ref string, repo repository.Repository) (*ImageScan, error) {
+	awsInt, err := repo.AWSIntegration.ReadAWSIntegration(
+		r.AWSIntegrationID,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sess, err := awsInt.GetSession()
+
+	if err != nil {
+		return nil, err
+	}
+
+	svc := ecr.New(sess)
+
+	imageID := &ecr.ImageIdentifier{}
+
+	if digestRegex.MatchString(ref) {
+		imageID.ImageDigest = aws.String(ref)
+	} else {
+		imageID.ImageTag = aws.String(ref)
+	}
+
+	res := &ImageScan{
+		RepositoryName:  repoName,
+		Source:          ScanSourceECR,
+		Vulnerabilities: make([]*Vulnerability, 0),
+	}
+
+	var statusErr error
+
+	err = svc.DescribeImageScanFindingsPages(&ecr.DescribeImageScanFindingsInput{
+		RepositoryName: &repoName,
+		ImageId:        imageID,
+	}, func(page *ecr.DescribeImageScanFindingsOutput, lastPage bool) bool {
+		if page.ImageScanStatus != nil && aws.StringValue(page.ImageScanStatus.Status) != ecr.ScanStatusComplete {
+			statusErr = fmt.Errorf(
+				"the scan of %s:%s is not complete: %s %s",
+				repoName,
+				ref,
+				strings.ToLower(aws.StringValue(page.ImageScanStatus.Status)),
+				aws.StringValue(page.ImageScanStatus.Description),
+			)
+
+			return false
+		}
+
+		if page.ImageId != nil {
+			res.Digest = aws.StringValue(page.ImageId.ImageDigest)
+		}
+
+		if page.ImageScanFindings == nil {
+			return true
+		}
+
+		res.ScannedAt = page.ImageScanFindings.ImageScanCompletedAt
+
+		for _, finding := range page.ImageScanFindings.Findings {
+			vuln := &Vulnerability{
+				ID:          aws.StringValue(finding.Name),
+				Severity:    normalizeSeverity(aws.StringValue(finding.Severity)),
+				Description: aws.StringValue(finding.Description),
+				URL:         aws.StringValue(finding.Uri),
+			}
+
+			for _, attr := range finding.Attributes {
+				switch aws.StringValue(attr.Key) {
+				case "package_name":
+					vuln.Package = aws.StringValue(attr.Value)
+				case "package_version":
+					vuln.Version = aws.StringValue(attr.Value)
+				}
+			}
+
+			res.Vulnerabilities = append(res.Vulnerabilities, vuln)
+		}
+
+		return true
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if statusErr != nil {
+		return nil, statusErr
+	}
+
+	return res, nil
+}
+
+type gcrOccurrencesResp struct {
+	Occurrences []struct {
+		NoteName      string `json:"noteName"`
+		UpdateTime    string `json:"updateTime"`
+		Vulnerability struct {
+			Severity          string `json:"severity"`
+			EffectiveSeverity string `json:"effectiveSeverity"`
+			ShortDescription  string `json:"shortDescription"`
+			RelatedURLs       []struct {
+				URL string `json:"url"`
+			} `json:"relatedUrls"`
+			PackageIssue []struct {
+				AffectedPackage string `json:"affectedPackage"`
+				AffectedVersion struct {
+					FullName string `json:"fullName"`
+				} `json:"affectedVersion"`
+				FixedVersion struct {
+					FullName string `json:"fullName"`
+				} `json:"fixedVersion"`
+			} `json:"packageIssue"`
+		} `json:"vulnerability"`
+	} `json:"occurrences"`
+	NextPageToken string `json:"nextPageToken"`
+}
+
+// containerAnalysisURL is the base URL of the Container Analysis API, which stores
+// the vulnerability occurrences of GCR and Artifact Registry images
+const containerAnalysisURL = "https://containeranalysis.googleapis.com/v1"
+
+func (r *Registry) getGCRImageScan(repoName, digest string, repo repository.Repository) (*ImageScan, error) {
+	gcp, err := repo.GCPIntegration.ReadGCPIntegration(
+		r.GCPIntegrationID,
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	// the token of the registry's token cache only has the storage scope, so a token
+	// for the Container Analysis API is requested for each scan
+	creds, err := google.CredentialsFromJSON(
+		context.Background(),
+		gcp.GCPKeyData,
+		"https://www.googleapis.com/auth/cloud-platform",
+	)
+
+	if err != nil {
+		return nil, err
+	}
+
+	tok, err := creds.TokenSource.Token()
+
+	if err != nil {
+		return nil, err
+	}
+
+	// occurrences are stored in the project that the registry belongs to, which is
+	// the first path segment of both gcr.io and pkg.dev registry URLs
+	uri := r.GetRepositoryURI(repoName)
+	gcpProject := strings.Split(uri, "/")[1]
+
+	res := &ImageScan{
+		RepositoryName:  repoName,
+		Digest:          digest,
+		Source:          ScanSourceGCR,
+		Vulnerabilities: make([]*Vulnerability, 0),
+	}
+
+	query := url.Values{}
+	query.Set("filter", fmt.Sprintf(`kind="VULNERABILITY" AND resourceUrl="https://%s@%s"`, uri, digest))
+	query.Set("pageSize", "1000")
+
+	for {
+		req, err := http.NewRequest(
+			"GET",
+			fmt.Sprintf("%s/projects/%s/occurrences?%s", containerAnalysisURL, gcpProject, query.Encode()),
+			nil,
+		)
+
+		if err != nil {
+			return nil, err
+		}
+
+		req.Header.Set("Authorization", "Bearer "+tok.AccessToken)
+
+		resp, err := http.DefaultClient.Do(req)
+
+		if err != nil {
+			return nil, err
+		}
+
+		if err := checkStatus(resp, http.StatusOK); err != nil {
+			return nil, fmt.Errorf("could not read vulnerability occurrences of %s: %v", uri, err)
+		}
+
+		occResp := &gcrOccurrencesResp{}
+		err = json.NewDecoder(resp.Body).Decode(occResp)
+		resp.Body.Close()
+
+		if err != nil {
+			return nil, fmt.Errorf("could not read vulnerability occurrences of %s: %v", uri, err)
+		}
+
+		for _, occ := range occResp.Occurrences {
+			severity := occ.Vulnerability.EffectiveSeverity
+
+			if severity == "" || severity == "SEVERITY_UNSPECIFIED" {
+				severity = occ.Vulnerability.Severity
+			}
+
+			vuln := &Vulnerability{
+				// the note name is of the form projects/goog-vulnz/notes/CVE-2021-1234
+				ID:          occ.NoteName[strings.LastIndex(occ.NoteName, "/")+1:],
+				Severity:    normalizeSeverity(severity),
+				Description: occ.Vulnerability.ShortDescription,
+			}
+
+			if len(occ.Vulnerability.RelatedURLs) > 0 {
+				vuln.URL = occ.Vulnerability.RelatedURLs[0].URL
+			}
+
+			if len(occ.Vulnerability.PackageIssue) > 0 {
+				issue := occ.Vulnerability.PackageIssue[0]
+
+				vuln.Package = issue.AffectedPackage
+				vuln.Version = issue.AffectedVersion.FullName
+				vuln.FixedVersion = issue.FixedVersion.FullName
+			}
+
+			if updated, err := time.Parse(time.RFC3339, occ.UpdateTime); err == nil {
+				if res.ScannedAt == nil || updated.After(*res.ScannedAt) {
+					res.ScannedAt = &updated
+				}
+			}
+
+			res.Vulnerabilities = append(res.Vulnerabilities, vuln)
+		}
+
+		if occResp.NextPageToken == "" {
+			break
+		}
+
+		query.Set("pageToken", occResp.NextPageToken)
+	}
+
+	return res, nil
+}
+
+func (r *Registry) getLocalImageScan(
+	ctx context.Context,
+	repoName, digest string,
+	repo repository.Repository,
+	doAuth *oauth2.Config,
+	local LocalScanner,
+) (*ImageScan, error) {
+	confBytes, err := r.GetDockerConfigJSON(repo, doAuth)
+
+	if err != nil {
+		return nil, err
+	}
+
+	imageRef := r.GetRepositoryURI(repoName)
+
+	// docker hub registries are connected for a single repository, which is the
+	// repository name
+	if strings.Contains(r.URL, "docker.io/") {
+		imageRef = "docker.io/" + repoName
+	}
+
+	vulns, err := local.ScanImage(ctx, imageRef+"@"+digest, confBytes)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not scan %s@%s: %v", imageRef, digest, err)
+	}
+
+	// the findings are copied, since they may be shared with the scan cache
+	res := make([]*Vulnerability, 0, len(vulns))
+
+	for _, vuln := range vulns {
+		normalized := *vuln
+		normalized.Severity = normalizeSeverity(vuln.Severity)
+
+		res = append(res, &normalized)
+	}
+
+	now := time.Now()
+
+	return &ImageScan{
+		RepositoryName:  repoName,
+		Digest:          digest,
+		Source:          ScanSourceLocal,
+		ScannedAt:       &now,
+		Vulnerabilities: res,
+	}, nil
+}
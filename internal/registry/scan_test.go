@@ -0,0 +1,157 @@
+package registry
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+type fakeScanner struct {
+	refs  []string
+	vulns []*Vulnerability
+}
+
+func (f *fakeScanner) ScanImage(ctx context.Context, imageRef string, dockerConfigJSON []byte) ([]*Vulnerability, error) {
+	f.refs = append(f.refs, imageRef)
+
+	return f.vulns, nil
+}
+
+func TestGetImageScanLocal(t *testing.T) {
+	src := newFakeRegistry()
+	defer src.server.Close()
+
+	manifest, _ := json.Marshal(&imageManifest{SchemaVersion: 2, MediaType: mediaTypeDockerManifest})
+	digest := src.addManifest("staging/web", "v1", mediaTypeDockerManifest, manifest)
+
+	reg, repo := newBasicRegistry(t, src.server.URL+"/staging", "")
+
+	if _, err := reg.GetImageScan(context.Background(), "web", "v1", repo, nil, nil); err != ErrScanNotSupported {
+		t.Errorf("expected ErrScanNotSupported without a local scanner, got %v\n", err)
+	}
+
+	scanner := &fakeScanner{
+		vulns: []*Vulnerability{
+			{ID: "CVE-2021-0001", Severity: "CRITICAL"},
+			{ID: "CVE-2021-0002", Severity: "HIGH"},
+			{ID: "CVE-2021-0003", Severity: "NEGLIGIBLE"},
+			{ID: "CVE-2021-0004", Severity: "UNDEFINED"},
+		},
+	}
+
+	scan, err := reg.GetImageScan(context.Background(), "web", "v1", repo, nil, scanner)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	// the tag is resolved, so that the image is scanned by digest
+	expRef := reg.GetRepositoryURI("web") + "@" + digest
+
+	if len(scanner.refs) != 1 || scanner.refs[0] != expRef {
+		t.Errorf("expected the scanner to scan %s, got %v\n", expRef, scanner.refs)
+	}
+
+	if scan.Digest != digest || scan.Source != ScanSourceLocal {
+		t.Errorf("incorrect scan: digest %s, source %s\n", scan.Digest, scan.Source)
+	}
+
+	summary := scan.Summary()
+
+	if summary.Critical != 1 || summary.High != 1 || summary.Low != 1 || summary.Unknown != 1 || summary.Total != 4 {
+		t.Errorf("incorrect summary: %v\n", summary)
+	}
+
+	if count := scan.CountAtOrAbove(SeverityHigh); count != 2 {
+		t.Errorf("expected 2 vulnerabilities of severity high or above, got %d\n", count)
+	}
+
+	if count := scan.CountAtOrAbove(SeverityLow); count != 3 {
+		t.Errorf("expected 3 vulnerabilities of severity low or above, got %d\n", count)
+	}
+}
+
+func TestCachedLocalScanner(t *testing.T) {
+	scanner := &fakeScanner{
+		vulns: []*Vulnerability{{ID: "CVE-2021-0001", Severity: "CRITICAL"}},
+	}
+
+	cached := NewCachedLocalScanner(scanner, time.Hour)
+
+	for _, ref := range []string{"web@sha256:1", "web@sha256:1", "web@sha256:2"} {
+		if _, err := cached.ScanImage(context.Background(), ref, nil); err != nil {
+			t.Fatalf("%v\n", err)
+		}
+	}
+
+	// the second scan of a digest is read from the cache
+	if len(scanner.refs) != 2 || scanner.refs[0] != "web@sha256:1" || scanner.refs[1] != "web@sha256:2" {
+		t.Errorf("expected each digest to be scanned once, got %v\n", scanner.refs)
+	}
+
+	expired := NewCachedLocalScanner(scanner, 0)
+	scanner.refs = nil
+
+	for i := 0; i < 2; i++ {
+		if _, err := expired.ScanImage(context.Background(), "web@sha256:1", nil); err != nil {
+			t.Fatalf("%v\n", err)
+		}
+	}
+
+	if len(scanner.refs) != 2 {
+		t.Errorf("expected expired scans to be made again, got %v\n", scanner.refs)
+	}
+}
+
+func TestParseTrivyReport(t *testing.T) {
+	result := `{
+		"Target": "web (debian 10.9)",
+		"Vulnerabilities": [
+			{
+				"VulnerabilityID": "CVE-2021-3520",
+				"PkgName": "liblz4-1",
+				"InstalledVersion": "1.8.3-1",
+				"FixedVersion": "1.8.3-1+deb10u1",
+				"Severity": "CRITICAL",
+				"Title": "memory corruption due to an integer overflow",
+				"PrimaryURL": "https://avd.aquasec.com/nvd/cve-2021-3520"
+			}
+		]
+	}`
+
+	outputs := map[string]string{
+		"report":  `{"SchemaVersion": 2, "Results": [` + result + `, {"Target": "app/go.sum"}]}`,
+		"results": `[` + result + `]`,
+	}
+
+	for format, output := range outputs {
+		vulns, err := parseTrivyReport([]byte(output))
+
+		if err != nil {
+			t.Fatalf("%s: %v\n", format, err)
+		}
+
+		if len(vulns) != 1 {
+			t.Fatalf("%s: expected 1 vulnerability, got %d\n", format, len(vulns))
+		}
+
+		exp := Vulnerability{
+			ID:           "CVE-2021-3520",
+			Severity:     SeverityCritical,
+			Package:      "liblz4-1",
+			Version:      "1.8.3-1",
+			FixedVersion: "1.8.3-1+deb10u1",
+			Description:  "memory corruption due to an integer overflow",
+			URL:          "https://avd.aquasec.com/nvd/cve-2021-3520",
+		}
+
+		if *vulns[0] != exp {
+			t.Errorf("%s: expected %v, got %v\n", format, exp, *vulns[0])
+		}
+	}
+
+	if _, err := parseTrivyReport([]byte("not json")); err == nil {
+		t.Errorf("expected an error for invalid output\n")
+	}
+}
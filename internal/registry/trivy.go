@@ -0,0 +1,112 @@
+package registry
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+)
+
+// NewLocalScanner creates the local scanner with the given name, which runs the
+// binary at the given path. The only local scanner is "trivy". Scans are cached by
+// digest for DefaultScanCacheTTL.
+func NewLocalScanner(name, path string) (LocalScanner, error) {
+	switch name {
+	case "trivy":
+		return NewCachedLocalScanner(&TrivyScanner{Path: path}, DefaultScanCacheTTL), nil
+	default:
+		return nil, fmt.Errorf("unknown local scanner %s", name)
+	}
+}
+
+// TrivyScanner scans images with the trivy CLI
+type TrivyScanner struct {
+	// Path is the path of the trivy binary
+	Path string
+}
+
+type trivyReport struct {
+	Results []*trivyResult `json:"Results"`
+}
+
+type trivyResult struct {
+	Vulnerabilities []struct {
+		VulnerabilityID  string `json:"VulnerabilityID"`
+		PkgName          string `json:"PkgName"`
+		InstalledVersion string `json:"InstalledVersion"`
+		FixedVersion     string `json:"FixedVersion"`
+		Severity         string `json:"Severity"`
+		Title            string `json:"Title"`
+		PrimaryURL       string `json:"PrimaryURL"`
+	} `json:"Vulnerabilities"`
+}
+
+// ScanImage runs trivy against an image, with a docker config that only contains
+// the credentials of the image's registry. trivy is killed if the context is done.
+func (t *TrivyScanner) ScanImage(ctx context.Context, imageRef string, dockerConfigJSON []byte) ([]*Vulnerability, error) {
+	dir, err := ioutil.TempDir("", "porter-scan")
+
+	if err != nil {
+		return nil, err
+	}
+
+	defer os.RemoveAll(dir)
+
+	if err := ioutil.WriteFile(filepath.Join(dir, "config.json"), dockerConfigJSON, 0600); err != nil {
+		return nil, err
+	}
+
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+
+	cmd := exec.CommandContext(ctx, t.Path, "image", "--quiet", "--no-progress", "--format", "json", imageRef)
+	cmd.Env = append(os.Environ(), "DOCKER_CONFIG="+dir)
+	cmd.Stdout = stdout
+	cmd.Stderr = stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%v: %s", err, strings.TrimSpace(stderr.String()))
+	}
+
+	return parseTrivyReport(stdout.Bytes())
+}
+
+// parseTrivyReport parses the JSON output of trivy, which is a list of results in
+// older versions and a report with a list of results in newer versions
+func parseTrivyReport(output []byte) ([]*Vulnerability, error) {
+	report := &trivyReport{}
+
+	if trimmed := bytes.TrimSpace(output); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &report.Results); err != nil {
+			return nil, fmt.Errorf("could not parse trivy output: %v", err)
+		}
+	} else if err := json.Unmarshal(trimmed, report); err != nil {
+		return nil, fmt.Errorf("could not parse trivy output: %v", err)
+	}
+
+	res := make([]*Vulnerability, 0)
+
+	for _, result := range report.Results {
+		if result == nil {
+			continue
+		}
+
+		for _, vuln := range result.Vulnerabilities {
+			res = append(res, &Vulnerability{
+				ID:           vuln.VulnerabilityID,
+				Severity:     normalizeSeverity(vuln.Severity),
+				Package:      vuln.PkgName,
+				Version:      vuln.InstalledVersion,
+				FixedVersion: vuln.FixedVersion,
+				Description:  vuln.Title,
+				URL:          vuln.PrimaryURL,
+			})
+		}
+	}
+
+	return res, nil
+}
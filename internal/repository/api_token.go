@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// APITokenRepository represents the set of queries on the APIToken model
+type APITokenRepository interface {
+	CreateAPIToken(token *models.APIToken) (*models.APIToken, error)
+	ReadAPIToken(projID, tokenID uint) (*models.APIToken, error)
+	ReadAPITokenByUniqueID(uid string) (*models.APIToken, error)
+	ListAPITokensByProjectID(projID uint) ([]*models.APIToken, error)
+	UpdateAPIToken(token *models.APIToken) (*models.APIToken, error)
+}
@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// AuditEventFilter filters and paginates the audit events of a project. Zero
+// values are not used as filters.
+type AuditEventFilter struct {
+	UserID       uint
+	ResourceType string
+	Since        *time.Time
+	Until        *time.Time
+
+	Limit  int
+	Offset int
+}
+
+// AuditEventRepository represents the set of queries on the AuditEvent model
+type AuditEventRepository interface {
+	CreateAuditEvent(event *models.AuditEvent) (*models.AuditEvent, error)
+	ListAuditEventsByProjectID(projID uint, filter *AuditEventFilter) ([]*models.AuditEvent, error)
+}
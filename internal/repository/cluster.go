@@ -1,6 +1,8 @@
 package repository
 
 import (
+	"time"
+
 	"github.com/porter-dev/porter/internal/models"
 	ints "github.com/porter-dev/porter/internal/models/integrations"
 )
@@ -16,7 +18,41 @@ type ClusterRepository interface {
 	CreateCluster(cluster *models.Cluster) (*models.Cluster, error)
 	ReadCluster(id uint) (*models.Cluster, error)
 	ListClustersByProjectID(projectID uint) ([]*models.Cluster, error)
+	ListClusters() ([]*models.Cluster, error)
 	UpdateCluster(cluster *models.Cluster) (*models.Cluster, error)
 	UpdateClusterTokenCache(tokenCache *ints.ClusterTokenCache) (*models.Cluster, error)
 	DeleteCluster(cluster *models.Cluster) error
 }
+
+// TouchClusters updates the clusters of a project that match, so that their
+// UpdatedAt timestamp changes. Connections to a cluster are cached by the version of
+// its credentials, which includes this timestamp, so the clusters that use an
+// integration must be touched when the integration is updated.
+func TouchClusters(repo ClusterRepository, projID uint, match func(cluster *models.Cluster) bool) error {
+	clusters, err := repo.ListClustersByProjectID(projID)
+
+	if err != nil {
+		return err
+	}
+
+	for _, listed := range clusters {
+		if !match(listed) {
+			continue
+		}
+
+		// read the cluster again to load the token cache
+		cluster, err := repo.ReadCluster(listed.ID)
+
+		if err != nil {
+			return err
+		}
+
+		cluster.UpdatedAt = time.Now()
+
+		if _, err := repo.UpdateCluster(cluster); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
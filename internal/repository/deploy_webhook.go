@@ -0,0 +1,15 @@
+package repository
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// DeployWebhookTokenRepository represents the set of queries on the
+// DeployWebhookToken model
+type DeployWebhookTokenRepository interface {
+	CreateDeployWebhookToken(token *models.DeployWebhookToken) (*models.DeployWebhookToken, error)
+	ReadDeployWebhookToken(releaseID, tokenID uint) (*models.DeployWebhookToken, error)
+	ReadDeployWebhookTokenByToken(token string) (*models.DeployWebhookToken, error)
+	ListDeployWebhookTokensByReleaseID(releaseID uint) ([]*models.DeployWebhookToken, error)
+	UpdateDeployWebhookToken(token *models.DeployWebhookToken) (*models.DeployWebhookToken, error)
+}
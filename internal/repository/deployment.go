@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// DeploymentFilter filters and paginates the deployments of a project. Zero
+// values are not used as filters.
+type DeploymentFilter struct {
+	ClusterID uint
+	Namespace string
+	Name      string
+
+	Limit  int
+	Offset int
+}
+
+// DeploymentRepository represents the set of queries on the Deployment model
+type DeploymentRepository interface {
+	CreateDeployment(deployment *models.Deployment) (*models.Deployment, error)
+	ListDeploymentsByProjectID(projID uint, filter *DeploymentFilter) ([]*models.Deployment, error)
+}
@@ -0,0 +1,22 @@
+package repository
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// EnvGroupRepository represents the set of queries on the EnvGroup model and its
+// versions and linked releases
+type EnvGroupRepository interface {
+	CreateEnvGroup(group *models.EnvGroup) (*models.EnvGroup, error)
+	ReadEnvGroup(clusterID uint, namespace, name string) (*models.EnvGroup, error)
+	ListEnvGroups(clusterID uint, namespace string) ([]*models.EnvGroup, error)
+	UpdateEnvGroup(group *models.EnvGroup) (*models.EnvGroup, error)
+	DeleteEnvGroup(group *models.EnvGroup) error
+
+	CreateEnvGroupVersion(version *models.EnvGroupVersion) (*models.EnvGroupVersion, error)
+	ReadEnvGroupVersion(groupID, version uint) (*models.EnvGroupVersion, error)
+	ListEnvGroupVersions(groupID uint) ([]*models.EnvGroupVersion, error)
+
+	AddEnvGroupRelease(rel *models.EnvGroupRelease) (*models.EnvGroupRelease, error)
+	RemoveEnvGroupRelease(groupID uint, releaseName string) error
+}
@@ -0,0 +1,109 @@
+package gorm_test
+
+import (
+	"testing"
+
+	"github.com/porter-dev/porter/internal/models"
+	orm "gorm.io/gorm"
+)
+
+func TestCreateAPIToken(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_create_api_token.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	token := &models.APIToken{
+		UniqueID:  "abcdef",
+		ProjectID: tester.initProjects[0].ID,
+		Name:      "ci",
+		Kind:      models.RoleDeveloper,
+	}
+
+	token, err := tester.repo.APIToken.CreateAPIToken(token)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	token, err = tester.repo.APIToken.ReadAPIToken(tester.initProjects[0].ID, token.ID)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	// make sure id is 1 and name is "ci"
+	if token.Model.ID != 1 {
+		t.Errorf("incorrect token ID: expected %d, got %d\n", 1, token.Model.ID)
+	}
+
+	if token.Name != "ci" {
+		t.Errorf("incorrect token name: expected %s, got %s\n", "ci", token.Name)
+	}
+
+	// make sure the token can be read by its unique id
+	token, err = tester.repo.APIToken.ReadAPITokenByUniqueID("abcdef")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if token.Model.ID != 1 {
+		t.Errorf("incorrect token ID: expected %d, got %d\n", 1, token.Model.ID)
+	}
+
+	// make sure the token cannot be read from a different project
+	_, err = tester.repo.APIToken.ReadAPIToken(tester.initProjects[0].ID+1, token.ID)
+
+	if err != orm.ErrRecordNotFound {
+		t.Fatalf("incorrect error: expected %v, got %v\n", orm.ErrRecordNotFound, err)
+	}
+}
+
+func TestRevokeAPIToken(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_revoke_api_token.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	token := &models.APIToken{
+		UniqueID:  "abcdef",
+		ProjectID: tester.initProjects[0].ID,
+		Name:      "ci",
+		Kind:      models.RoleDeveloper,
+	}
+
+	token, err := tester.repo.APIToken.CreateAPIToken(token)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	token.Revoked = true
+
+	_, err = tester.repo.APIToken.UpdateAPIToken(token)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	tokens, err := tester.repo.APIToken.ListAPITokensByProjectID(tester.initProjects[0].ID)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(tokens) != 1 {
+		t.Fatalf("length of tokens incorrect: expected %d, got %d\n", 1, len(tokens))
+	}
+
+	if tokens[0].IsValid() {
+		t.Errorf("expected revoked token to be invalid\n")
+	}
+}
@@ -0,0 +1,68 @@
+package gorm
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// AuditEventRepository uses gorm.DB for querying the database
+type AuditEventRepository struct {
+	db *gorm.DB
+}
+
+// NewAuditEventRepository returns an AuditEventRepository which uses
+// gorm.DB for querying the database
+func NewAuditEventRepository(db *gorm.DB) repository.AuditEventRepository {
+	return &AuditEventRepository{db}
+}
+
+// CreateAuditEvent creates a new audit event
+func (repo *AuditEventRepository) CreateAuditEvent(event *models.AuditEvent) (*models.AuditEvent, error) {
+	if err := repo.db.Create(event).Error; err != nil {
+		return nil, err
+	}
+
+	return event, nil
+}
+
+// ListAuditEventsByProjectID finds the audit events for a project that match the
+// filter, ordered from newest to oldest
+func (repo *AuditEventRepository) ListAuditEventsByProjectID(
+	projID uint,
+	filter *repository.AuditEventFilter,
+) ([]*models.AuditEvent, error) {
+	events := []*models.AuditEvent{}
+
+	query := repo.db.Where("project_id = ?", projID)
+
+	if filter.UserID != 0 {
+		query = query.Where("user_id = ?", filter.UserID)
+	}
+
+	if filter.ResourceType != "" {
+		query = query.Where("resource_type = ?", filter.ResourceType)
+	}
+
+	if filter.Since != nil {
+		query = query.Where("created_at >= ?", *filter.Since)
+	}
+
+	if filter.Until != nil {
+		query = query.Where("created_at <= ?", *filter.Until)
+	}
+
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
+	if err := query.Order("created_at desc, id desc").Find(&events).Error; err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
@@ -0,0 +1,92 @@
+package gorm_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+)
+
+func TestListAuditEventsByProjectID(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_list_audit_events.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	projID := tester.initProjects[0].ID
+
+	events := []*models.AuditEvent{
+		{ProjectID: projID, UserID: 1, Method: "POST", ResourceType: "releases", Status: 200},
+		{ProjectID: projID, UserID: 2, Method: "POST", ResourceType: "registries", Status: 200},
+		{ProjectID: projID, UserID: 1, Method: "DELETE", ResourceType: "releases", Status: 403},
+		{ProjectID: projID + 1, UserID: 1, Method: "POST", ResourceType: "releases", Status: 200},
+	}
+
+	for _, event := range events {
+		if _, err := tester.repo.AuditEvent.CreateAuditEvent(event); err != nil {
+			t.Fatalf("%v\n", err)
+		}
+	}
+
+	// all events in the project, newest first
+	gotEvents, err := tester.repo.AuditEvent.ListAuditEventsByProjectID(projID, &repository.AuditEventFilter{})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(gotEvents) != 3 {
+		t.Fatalf("length of events incorrect: expected %d, got %d\n", 3, len(gotEvents))
+	}
+
+	if gotEvents[0].ID != 3 {
+		t.Errorf("incorrect first event: expected id %d, got %d\n", 3, gotEvents[0].ID)
+	}
+
+	// filter by user and resource type
+	gotEvents, err = tester.repo.AuditEvent.ListAuditEventsByProjectID(projID, &repository.AuditEventFilter{
+		UserID:       1,
+		ResourceType: "releases",
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(gotEvents) != 2 {
+		t.Fatalf("length of filtered events incorrect: expected %d, got %d\n", 2, len(gotEvents))
+	}
+
+	// paginate
+	gotEvents, err = tester.repo.AuditEvent.ListAuditEventsByProjectID(projID, &repository.AuditEventFilter{
+		Limit:  1,
+		Offset: 1,
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(gotEvents) != 1 || gotEvents[0].ID != 2 {
+		t.Fatalf("incorrect page of events: got %v\n", gotEvents)
+	}
+
+	// filter by a time range that excludes every event
+	until := time.Now().Add(-1 * time.Hour)
+
+	gotEvents, err = tester.repo.AuditEvent.ListAuditEventsByProjectID(projID, &repository.AuditEventFilter{
+		Until: &until,
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(gotEvents) != 0 {
+		t.Fatalf("length of events incorrect: expected %d, got %d\n", 0, len(gotEvents))
+	}
+}
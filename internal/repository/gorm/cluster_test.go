@@ -299,6 +299,31 @@ func TestListClustersByProjectID(t *testing.T) {
 	}
 }
 
+func TestListClusters(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_list_all_clusters.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	initCluster(tester, t)
+	defer cleanup(tester, t)
+
+	clusters, err := tester.repo.Cluster.ListClusters()
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(clusters) != 1 {
+		t.Fatalf("length of clusters incorrect: expected %d, got %d\n", 1, len(clusters))
+	}
+
+	if clusters[0].Name != "cluster-test" {
+		t.Errorf("incorrect cluster name: expected %s, got %s\n", "cluster-test", clusters[0].Name)
+	}
+}
+
 func TestUpdateCluster(t *testing.T) {
 	tester := &tester{
 		dbFileName: "./porter_update_cluster.db",
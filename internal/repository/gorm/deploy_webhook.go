@@ -0,0 +1,163 @@
+package gorm
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// DeployWebhookTokenRepository uses gorm.DB for querying the database
+type DeployWebhookTokenRepository struct {
+	db  *gorm.DB
+	key *[32]byte
+}
+
+// NewDeployWebhookTokenRepository returns a DeployWebhookTokenRepository which uses
+// gorm.DB for querying the database. It accepts an encryption key to encrypt
+// the signing secrets of tokens
+func NewDeployWebhookTokenRepository(
+	db *gorm.DB,
+	key *[32]byte,
+) repository.DeployWebhookTokenRepository {
+	return &DeployWebhookTokenRepository{db, key}
+}
+
+// CreateDeployWebhookToken creates a new deploy webhook token
+func (repo *DeployWebhookTokenRepository) CreateDeployWebhookToken(
+	token *models.DeployWebhookToken,
+) (*models.DeployWebhookToken, error) {
+	err := repo.EncryptDeployWebhookTokenData(token, repo.key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repo.db.Create(token).Error; err != nil {
+		return nil, err
+	}
+
+	err = repo.DecryptDeployWebhookTokenData(token, repo.key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// ReadDeployWebhookToken finds a deploy webhook token by release id and id
+func (repo *DeployWebhookTokenRepository) ReadDeployWebhookToken(
+	releaseID, tokenID uint,
+) (*models.DeployWebhookToken, error) {
+	token := &models.DeployWebhookToken{}
+
+	if err := repo.db.Where("release_id = ? AND id = ?", releaseID, tokenID).First(&token).Error; err != nil {
+		return nil, err
+	}
+
+	err := repo.DecryptDeployWebhookTokenData(token, repo.key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// ReadDeployWebhookTokenByToken finds a deploy webhook token by its unique token
+func (repo *DeployWebhookTokenRepository) ReadDeployWebhookTokenByToken(
+	tokenStr string,
+) (*models.DeployWebhookToken, error) {
+	token := &models.DeployWebhookToken{}
+
+	if err := repo.db.Where("token = ?", tokenStr).First(&token).Error; err != nil {
+		return nil, err
+	}
+
+	err := repo.DecryptDeployWebhookTokenData(token, repo.key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// ListDeployWebhookTokensByReleaseID finds all deploy webhook tokens for a
+// given release id
+func (repo *DeployWebhookTokenRepository) ListDeployWebhookTokensByReleaseID(
+	releaseID uint,
+) ([]*models.DeployWebhookToken, error) {
+	tokens := []*models.DeployWebhookToken{}
+
+	if err := repo.db.Where("release_id = ?", releaseID).Find(&tokens).Error; err != nil {
+		return nil, err
+	}
+
+	for _, token := range tokens {
+		repo.DecryptDeployWebhookTokenData(token, repo.key)
+	}
+
+	return tokens, nil
+}
+
+// UpdateDeployWebhookToken modifies an existing deploy webhook token in the database
+func (repo *DeployWebhookTokenRepository) UpdateDeployWebhookToken(
+	token *models.DeployWebhookToken,
+) (*models.DeployWebhookToken, error) {
+	err := repo.EncryptDeployWebhookTokenData(token, repo.key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repo.db.Save(token).Error; err != nil {
+		return nil, err
+	}
+
+	err = repo.DecryptDeployWebhookTokenData(token, repo.key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// EncryptDeployWebhookTokenData will encrypt the signing secret of the token
+// before writing to the DB
+func (repo *DeployWebhookTokenRepository) EncryptDeployWebhookTokenData(
+	token *models.DeployWebhookToken,
+	key *[32]byte,
+) error {
+	if len(token.SigningSecret) > 0 {
+		cipherData, err := repository.Encrypt(token.SigningSecret, key)
+
+		if err != nil {
+			return err
+		}
+
+		token.SigningSecret = cipherData
+	}
+
+	return nil
+}
+
+// DecryptDeployWebhookTokenData will decrypt the signing secret of the token
+// before returning it from the DB
+func (repo *DeployWebhookTokenRepository) DecryptDeployWebhookTokenData(
+	token *models.DeployWebhookToken,
+	key *[32]byte,
+) error {
+	if len(token.SigningSecret) > 0 {
+		plaintext, err := repository.Decrypt(token.SigningSecret, key)
+
+		if err != nil {
+			return err
+		}
+
+		token.SigningSecret = plaintext
+	}
+
+	return nil
+}
@@ -0,0 +1,85 @@
+package gorm_test
+
+import (
+	"testing"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+func TestDeployWebhookTokens(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_deploy_webhook_tokens.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	initRelease(tester, t)
+	defer cleanup(tester, t)
+
+	releaseID := tester.initReleases[0].ID
+
+	tokens := []*models.DeployWebhookToken{
+		{ReleaseID: releaseID, ProjectID: 1, Name: "ci", Token: "abc", SigningSecret: []byte("secret")},
+		{ReleaseID: releaseID, ProjectID: 1, Name: "manual", Token: "def"},
+		{ReleaseID: releaseID + 1, ProjectID: 1, Name: "other", Token: "ghi"},
+	}
+
+	for _, token := range tokens {
+		if _, err := tester.repo.DeployWebhookToken.CreateDeployWebhookToken(token); err != nil {
+			t.Fatalf("%v\n", err)
+		}
+	}
+
+	// the signing secret should be decrypted when read
+	token, err := tester.repo.DeployWebhookToken.ReadDeployWebhookTokenByToken("abc")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if token.Name != "ci" || string(token.SigningSecret) != "secret" {
+		t.Errorf("incorrect token: got name %s, secret %s\n", token.Name, string(token.SigningSecret))
+	}
+
+	gotTokens, err := tester.repo.DeployWebhookToken.ListDeployWebhookTokensByReleaseID(releaseID)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(gotTokens) != 2 {
+		t.Fatalf("length of tokens incorrect: expected %d, got %d\n", 2, len(gotTokens))
+	}
+
+	// revoke the token, and make sure the secret is still readable
+	token.Revoked = true
+
+	if _, err := tester.repo.DeployWebhookToken.UpdateDeployWebhookToken(token); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	token, err = tester.repo.DeployWebhookToken.ReadDeployWebhookToken(releaseID, token.ID)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if !token.Revoked || string(token.SigningSecret) != "secret" {
+		t.Errorf("incorrect revoked token: got revoked %t, secret %s\n", token.Revoked, string(token.SigningSecret))
+	}
+
+	// tokens of other releases should not be readable
+	if _, err := tester.repo.DeployWebhookToken.ReadDeployWebhookToken(releaseID, 3); err == nil {
+		t.Errorf("expected error reading token of another release\n")
+	}
+
+	release, err := tester.repo.Release.ReadReleaseByID(releaseID)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if release.Name != tester.initReleases[0].Name {
+		t.Errorf("incorrect release: expected %s, got %s\n", tester.initReleases[0].Name, release.Name)
+	}
+}
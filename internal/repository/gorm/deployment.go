@@ -0,0 +1,64 @@
+package gorm
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// DeploymentRepository uses gorm.DB for querying the database
+type DeploymentRepository struct {
+	db *gorm.DB
+}
+
+// NewDeploymentRepository returns a DeploymentRepository which uses
+// gorm.DB for querying the database
+func NewDeploymentRepository(db *gorm.DB) repository.DeploymentRepository {
+	return &DeploymentRepository{db}
+}
+
+// CreateDeployment creates a new deployment record
+func (repo *DeploymentRepository) CreateDeployment(deployment *models.Deployment) (*models.Deployment, error) {
+	if err := repo.db.Create(deployment).Error; err != nil {
+		return nil, err
+	}
+
+	return deployment, nil
+}
+
+// ListDeploymentsByProjectID finds the deployments for a project that match the
+// filter, ordered from newest to oldest
+func (repo *DeploymentRepository) ListDeploymentsByProjectID(
+	projID uint,
+	filter *repository.DeploymentFilter,
+) ([]*models.Deployment, error) {
+	deployments := []*models.Deployment{}
+
+	query := repo.db.Where("project_id = ?", projID)
+
+	if filter.ClusterID != 0 {
+		query = query.Where("cluster_id = ?", filter.ClusterID)
+	}
+
+	if filter.Namespace != "" {
+		query = query.Where("namespace = ?", filter.Namespace)
+	}
+
+	if filter.Name != "" {
+		query = query.Where("name = ?", filter.Name)
+	}
+
+	if filter.Limit > 0 {
+		query = query.Limit(filter.Limit)
+	}
+
+	if filter.Offset > 0 {
+		query = query.Offset(filter.Offset)
+	}
+
+	if err := query.Order("created_at desc, id desc").Find(&deployments).Error; err != nil {
+		return nil, err
+	}
+
+	return deployments, nil
+}
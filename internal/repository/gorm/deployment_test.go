@@ -0,0 +1,78 @@
+package gorm_test
+
+import (
+	"testing"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+)
+
+func TestListDeploymentsByProjectID(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_list_deployments.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	projID := tester.initProjects[0].ID
+
+	deployments := []*models.Deployment{
+		{ProjectID: projID, ClusterID: 1, Namespace: "default", Name: "web", Revision: 1, Trigger: models.DeploymentTriggerManual},
+		{ProjectID: projID, ClusterID: 1, Namespace: "default", Name: "web", Revision: 2, Trigger: models.DeploymentTriggerWebhook},
+		{ProjectID: projID, ClusterID: 1, Namespace: "jobs", Name: "web", Revision: 1, Trigger: models.DeploymentTriggerManual},
+		{ProjectID: projID + 1, ClusterID: 1, Namespace: "default", Name: "web", Revision: 3, Trigger: models.DeploymentTriggerManual},
+	}
+
+	changes := []*models.ValueChange{
+		{Path: "image.tag", Old: "v1", New: "v2"},
+	}
+
+	deployments[1].SetValuesDiff(changes)
+
+	for _, deployment := range deployments {
+		if _, err := tester.repo.Deployment.CreateDeployment(deployment); err != nil {
+			t.Fatalf("%v\n", err)
+		}
+	}
+
+	// filter by release, newest first
+	gotDeployments, err := tester.repo.Deployment.ListDeploymentsByProjectID(projID, &repository.DeploymentFilter{
+		ClusterID: 1,
+		Namespace: "default",
+		Name:      "web",
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(gotDeployments) != 2 {
+		t.Fatalf("length of deployments incorrect: expected %d, got %d\n", 2, len(gotDeployments))
+	}
+
+	if gotDeployments[0].Revision != 2 {
+		t.Errorf("incorrect first deployment: expected revision %d, got %d\n", 2, gotDeployments[0].Revision)
+	}
+
+	gotChanges := gotDeployments[0].GetValuesDiff()
+
+	if len(gotChanges) != 1 || gotChanges[0].Path != "image.tag" || gotChanges[0].New != "v2" {
+		t.Errorf("incorrect values diff: got %v\n", gotChanges)
+	}
+
+	// all deployments in the project, paginated
+	gotDeployments, err = tester.repo.Deployment.ListDeploymentsByProjectID(projID, &repository.DeploymentFilter{
+		Limit:  1,
+		Offset: 2,
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(gotDeployments) != 1 || gotDeployments[0].ID != 1 {
+		t.Fatalf("incorrect page of deployments: got %v\n", gotDeployments)
+	}
+}
@@ -0,0 +1,226 @@
+package gorm
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// EnvGroupRepository uses gorm.DB for querying the database
+type EnvGroupRepository struct {
+	db  *gorm.DB
+	key *[32]byte
+}
+
+// NewEnvGroupRepository returns an EnvGroupRepository which uses
+// gorm.DB for querying the database. It accepts an encryption key to encrypt
+// the secret variables of env group versions
+func NewEnvGroupRepository(
+	db *gorm.DB,
+	key *[32]byte,
+) repository.EnvGroupRepository {
+	return &EnvGroupRepository{db, key}
+}
+
+// CreateEnvGroup creates a new env group
+func (repo *EnvGroupRepository) CreateEnvGroup(
+	group *models.EnvGroup,
+) (*models.EnvGroup, error) {
+	if err := repo.db.Create(group).Error; err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+// ReadEnvGroup finds an env group by cluster id, namespace and name, along with
+// its linked releases
+func (repo *EnvGroupRepository) ReadEnvGroup(
+	clusterID uint,
+	namespace, name string,
+) (*models.EnvGroup, error) {
+	group := &models.EnvGroup{}
+
+	if err := repo.db.Preload("Releases").Where(
+		"cluster_id = ? AND namespace = ? AND name = ?",
+		clusterID,
+		namespace,
+		name,
+	).First(&group).Error; err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+// ListEnvGroups finds all env groups in a namespace of a cluster, ordered by name
+func (repo *EnvGroupRepository) ListEnvGroups(
+	clusterID uint,
+	namespace string,
+) ([]*models.EnvGroup, error) {
+	groups := []*models.EnvGroup{}
+
+	if err := repo.db.Preload("Releases").Where(
+		"cluster_id = ? AND namespace = ?",
+		clusterID,
+		namespace,
+	).Order("name asc").Find(&groups).Error; err != nil {
+		return nil, err
+	}
+
+	return groups, nil
+}
+
+// UpdateEnvGroup modifies an existing env group in the database. Linked releases
+// are modified through AddEnvGroupRelease and RemoveEnvGroupRelease.
+func (repo *EnvGroupRepository) UpdateEnvGroup(
+	group *models.EnvGroup,
+) (*models.EnvGroup, error) {
+	if err := repo.db.Omit("Releases").Save(group).Error; err != nil {
+		return nil, err
+	}
+
+	return group, nil
+}
+
+// DeleteEnvGroup permanently removes an env group along with its versions and
+// linked releases, so that the name can be reused
+func (repo *EnvGroupRepository) DeleteEnvGroup(
+	group *models.EnvGroup,
+) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where("env_group_id = ?", group.ID).Delete(&models.EnvGroupRelease{}).Error; err != nil {
+			return err
+		}
+
+		if err := tx.Unscoped().Where("env_group_id = ?", group.ID).Delete(&models.EnvGroupVersion{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Delete(group).Error
+	})
+}
+
+// CreateEnvGroupVersion creates a new version of an env group
+func (repo *EnvGroupRepository) CreateEnvGroupVersion(
+	version *models.EnvGroupVersion,
+) (*models.EnvGroupVersion, error) {
+	err := repo.EncryptEnvGroupVersionData(version, repo.key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repo.db.Create(version).Error; err != nil {
+		return nil, err
+	}
+
+	err = repo.DecryptEnvGroupVersionData(version, repo.key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return version, nil
+}
+
+// ReadEnvGroupVersion finds a version of an env group by its version number
+func (repo *EnvGroupRepository) ReadEnvGroupVersion(
+	groupID, version uint,
+) (*models.EnvGroupVersion, error) {
+	res := &models.EnvGroupVersion{}
+
+	if err := repo.db.Where(
+		"env_group_id = ? AND version = ?",
+		groupID,
+		version,
+	).First(&res).Error; err != nil {
+		return nil, err
+	}
+
+	err := repo.DecryptEnvGroupVersionData(res, repo.key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+// ListEnvGroupVersions finds all versions of an env group, newest first
+func (repo *EnvGroupRepository) ListEnvGroupVersions(
+	groupID uint,
+) ([]*models.EnvGroupVersion, error) {
+	versions := []*models.EnvGroupVersion{}
+
+	if err := repo.db.Where("env_group_id = ?", groupID).Order("version desc").Find(&versions).Error; err != nil {
+		return nil, err
+	}
+
+	for _, version := range versions {
+		repo.DecryptEnvGroupVersionData(version, repo.key)
+	}
+
+	return versions, nil
+}
+
+// AddEnvGroupRelease links a release to an env group
+func (repo *EnvGroupRepository) AddEnvGroupRelease(
+	rel *models.EnvGroupRelease,
+) (*models.EnvGroupRelease, error) {
+	if err := repo.db.Create(rel).Error; err != nil {
+		return nil, err
+	}
+
+	return rel, nil
+}
+
+// RemoveEnvGroupRelease unlinks a release from an env group
+func (repo *EnvGroupRepository) RemoveEnvGroupRelease(
+	groupID uint,
+	releaseName string,
+) error {
+	return repo.db.Unscoped().Where(
+		"env_group_id = ? AND release_name = ?",
+		groupID,
+		releaseName,
+	).Delete(&models.EnvGroupRelease{}).Error
+}
+
+// EncryptEnvGroupVersionData will encrypt the secret variables of the env group
+// version before writing to the DB
+func (repo *EnvGroupRepository) EncryptEnvGroupVersionData(
+	version *models.EnvGroupVersion,
+	key *[32]byte,
+) error {
+	if len(version.SecretVariablesBytes) > 0 {
+		cipherData, err := repository.Encrypt(version.SecretVariablesBytes, key)
+
+		if err != nil {
+			return err
+		}
+
+		version.SecretVariablesBytes = cipherData
+	}
+
+	return nil
+}
+
+// DecryptEnvGroupVersionData will decrypt the secret variables of the env group
+// version before returning it from the DB
+func (repo *EnvGroupRepository) DecryptEnvGroupVersionData(
+	version *models.EnvGroupVersion,
+	key *[32]byte,
+) error {
+	if len(version.SecretVariablesBytes) > 0 {
+		plaintext, err := repository.Decrypt(version.SecretVariablesBytes, key)
+
+		if err != nil {
+			return err
+		}
+
+		version.SecretVariablesBytes = plaintext
+	}
+
+	return nil
+}
@@ -0,0 +1,141 @@
+package gorm_test
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/porter-dev/porter/internal/models"
+	"gorm.io/gorm"
+)
+
+func TestEnvGroups(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_env_groups.db",
+	}
+
+	setupTestEnv(tester, t)
+	defer cleanup(tester, t)
+
+	group, err := tester.repo.EnvGroup.CreateEnvGroup(&models.EnvGroup{
+		ProjectID: 1,
+		ClusterID: 1,
+		Namespace: "default",
+		Name:      "shared",
+		Version:   1,
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	// the same name cannot be used twice in a namespace
+	_, err = tester.repo.EnvGroup.CreateEnvGroup(&models.EnvGroup{
+		ProjectID: 1,
+		ClusterID: 1,
+		Namespace: "default",
+		Name:      "shared",
+	})
+
+	if err == nil {
+		t.Errorf("expected error creating duplicate env group\n")
+	}
+
+	for i, vars := range []map[string]string{{"A": "1"}, {"A": "2", "B": "3"}} {
+		version := &models.EnvGroupVersion{
+			EnvGroupID: group.ID,
+			Version:    uint(i + 1),
+		}
+
+		if err := version.SetVariables(vars, map[string]string{"SECRET": "hidden"}); err != nil {
+			t.Fatalf("%v\n", err)
+		}
+
+		if _, err := tester.repo.EnvGroup.CreateEnvGroupVersion(version); err != nil {
+			t.Fatalf("%v\n", err)
+		}
+	}
+
+	// secret variables should be decrypted when read
+	version, err := tester.repo.EnvGroup.ReadEnvGroupVersion(group.ID, 2)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	vars, secrets, err := version.GetVariables()
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if diff := deep.Equal(vars, map[string]string{"A": "2", "B": "3"}); diff != nil {
+		t.Errorf("incorrect variables:\n%v\n", diff)
+	}
+
+	if diff := deep.Equal(secrets, map[string]string{"SECRET": "hidden"}); diff != nil {
+		t.Errorf("incorrect secret variables:\n%v\n", diff)
+	}
+
+	versions, err := tester.repo.EnvGroup.ListEnvGroupVersions(group.ID)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(versions) != 2 || versions[0].Version != 2 {
+		t.Fatalf("versions should be listed newest first, got %d versions\n", len(versions))
+	}
+
+	for _, name := range []string{"web", "worker"} {
+		_, err := tester.repo.EnvGroup.AddEnvGroupRelease(&models.EnvGroupRelease{
+			EnvGroupID:  group.ID,
+			ReleaseName: name,
+		})
+
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+	}
+
+	if err := tester.repo.EnvGroup.RemoveEnvGroupRelease(group.ID, "worker"); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	group, err = tester.repo.EnvGroup.ReadEnvGroup(1, "default", "shared")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if diff := deep.Equal(group.Externalize().Releases, []string{"web"}); diff != nil {
+		t.Errorf("incorrect releases:\n%v\n", diff)
+	}
+
+	group.Version = 2
+
+	if _, err := tester.repo.EnvGroup.UpdateEnvGroup(group); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	groups, err := tester.repo.EnvGroup.ListEnvGroups(1, "default")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(groups) != 1 || groups[0].Version != 2 || len(groups[0].Releases) != 1 {
+		t.Fatalf("incorrect env groups listed\n")
+	}
+
+	if err := tester.repo.EnvGroup.DeleteEnvGroup(group); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if _, err := tester.repo.EnvGroup.ReadEnvGroup(1, "default", "shared"); err != gorm.ErrRecordNotFound {
+		t.Errorf("expected %v, got %v\n", gorm.ErrRecordNotFound, err)
+	}
+
+	if versions, _ := tester.repo.EnvGroup.ListEnvGroupVersions(group.ID); len(versions) != 0 {
+		t.Errorf("expected versions to be deleted, got %d\n", len(versions))
+	}
+}
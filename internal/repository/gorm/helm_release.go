@@ -0,0 +1,188 @@
+package gorm
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// HelmReleaseRepository uses gorm.DB for querying the database
+type HelmReleaseRepository struct {
+	db  *gorm.DB
+	key *[32]byte
+}
+
+// NewHelmReleaseRepository returns a HelmReleaseRepository which uses
+// gorm.DB for querying the database. It accepts an encryption key to encrypt
+// the bodies of Helm releases
+func NewHelmReleaseRepository(
+	db *gorm.DB,
+	key *[32]byte,
+) repository.HelmReleaseRepository {
+	return &HelmReleaseRepository{db, key}
+}
+
+// CreateHelmRelease creates a new Helm release record
+func (repo *HelmReleaseRepository) CreateHelmRelease(
+	rel *models.HelmRelease,
+) (*models.HelmRelease, error) {
+	err := repo.EncryptHelmReleaseData(rel, repo.key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repo.db.Create(rel).Error; err != nil {
+		return nil, err
+	}
+
+	err = repo.DecryptHelmReleaseData(rel, repo.key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return rel, nil
+}
+
+// ReadHelmRelease finds a Helm release record by cluster id, namespace and key
+func (repo *HelmReleaseRepository) ReadHelmRelease(
+	clusterID uint,
+	namespace, key string,
+) (*models.HelmRelease, error) {
+	rel := &models.HelmRelease{}
+
+	// "key" is quoted by gorm when passed as a map, as it is a keyword in some dialects
+	if err := repo.db.Where(map[string]interface{}{
+		"cluster_id": clusterID,
+		"namespace":  namespace,
+		"key":        key,
+	}).First(&rel).Error; err != nil {
+		return nil, err
+	}
+
+	err := repo.DecryptHelmReleaseData(rel, repo.key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return rel, nil
+}
+
+// ListHelmReleases finds the Helm release records that match the filter, ordered
+// by name and version
+func (repo *HelmReleaseRepository) ListHelmReleases(
+	filter *repository.HelmReleaseFilter,
+) ([]*models.HelmRelease, error) {
+	rels := []*models.HelmRelease{}
+
+	query := repo.db
+
+	if filter.ClusterID != 0 {
+		query = query.Where("cluster_id = ?", filter.ClusterID)
+	}
+
+	if filter.Namespace != "" {
+		query = query.Where("namespace = ?", filter.Namespace)
+	}
+
+	if filter.Name != "" {
+		query = query.Where("name = ?", filter.Name)
+	}
+
+	if filter.Status != "" {
+		query = query.Where("status = ?", filter.Status)
+	}
+
+	if filter.Owner != "" {
+		query = query.Where("owner = ?", filter.Owner)
+	}
+
+	if filter.Version != 0 {
+		query = query.Where("version = ?", filter.Version)
+	}
+
+	if err := query.Order("name asc, version asc").Find(&rels).Error; err != nil {
+		return nil, err
+	}
+
+	for _, rel := range rels {
+		repo.DecryptHelmReleaseData(rel, repo.key)
+	}
+
+	return rels, nil
+}
+
+// UpdateHelmRelease modifies an existing Helm release record in the database
+func (repo *HelmReleaseRepository) UpdateHelmRelease(
+	rel *models.HelmRelease,
+) (*models.HelmRelease, error) {
+	err := repo.EncryptHelmReleaseData(rel, repo.key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repo.db.Save(rel).Error; err != nil {
+		return nil, err
+	}
+
+	err = repo.DecryptHelmReleaseData(rel, repo.key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return rel, nil
+}
+
+// DeleteHelmRelease permanently removes a Helm release record, so that the key
+// can be reused if the release is installed again
+func (repo *HelmReleaseRepository) DeleteHelmRelease(
+	rel *models.HelmRelease,
+) error {
+	if err := repo.db.Unscoped().Delete(rel).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// EncryptHelmReleaseData will encrypt the body of the Helm release before
+// writing to the DB
+func (repo *HelmReleaseRepository) EncryptHelmReleaseData(
+	rel *models.HelmRelease,
+	key *[32]byte,
+) error {
+	if len(rel.Body) > 0 {
+		cipherData, err := repository.Encrypt(rel.Body, key)
+
+		if err != nil {
+			return err
+		}
+
+		rel.Body = cipherData
+	}
+
+	return nil
+}
+
+// DecryptHelmReleaseData will decrypt the body of the Helm release before
+// returning it from the DB
+func (repo *HelmReleaseRepository) DecryptHelmReleaseData(
+	rel *models.HelmRelease,
+	key *[32]byte,
+) error {
+	if len(rel.Body) > 0 {
+		plaintext, err := repository.Decrypt(rel.Body, key)
+
+		if err != nil {
+			return err
+		}
+
+		rel.Body = plaintext
+	}
+
+	return nil
+}
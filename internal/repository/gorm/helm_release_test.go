@@ -0,0 +1,115 @@
+package gorm_test
+
+import (
+	"testing"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+)
+
+func TestHelmReleases(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_helm_releases.db",
+	}
+
+	setupTestEnv(tester, t)
+	defer cleanup(tester, t)
+
+	rels := []*models.HelmRelease{
+		{ClusterID: 1, Namespace: "default", Key: "sh.helm.release.v1.web.v1", Name: "web", Version: 1, Status: "superseded", Owner: "helm", Body: []byte("web-1")},
+		{ClusterID: 1, Namespace: "default", Key: "sh.helm.release.v1.web.v2", Name: "web", Version: 2, Status: "deployed", Owner: "helm", Body: []byte("web-2")},
+		{ClusterID: 1, Namespace: "staging", Key: "sh.helm.release.v1.web.v1", Name: "web", Version: 1, Status: "deployed", Owner: "helm", Body: []byte("staging-1")},
+		{ClusterID: 2, Namespace: "default", Key: "sh.helm.release.v1.web.v1", Name: "web", Version: 1, Status: "deployed", Owner: "helm", Body: []byte("other-1")},
+	}
+
+	for _, rel := range rels {
+		if _, err := tester.repo.HelmRelease.CreateHelmRelease(rel); err != nil {
+			t.Fatalf("%v\n", err)
+		}
+	}
+
+	// the same key cannot be stored twice in a namespace
+	_, err := tester.repo.HelmRelease.CreateHelmRelease(&models.HelmRelease{
+		ClusterID: 1,
+		Namespace: "default",
+		Key:       "sh.helm.release.v1.web.v1",
+	})
+
+	if err == nil {
+		t.Errorf("expected error creating duplicate key\n")
+	}
+
+	// the body should be decrypted when read
+	rel, err := tester.repo.HelmRelease.ReadHelmRelease(1, "staging", "sh.helm.release.v1.web.v1")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if string(rel.Body) != "staging-1" {
+		t.Errorf("incorrect body: expected %s, got %s\n", "staging-1", string(rel.Body))
+	}
+
+	gotRels, err := tester.repo.HelmRelease.ListHelmReleases(&repository.HelmReleaseFilter{
+		ClusterID: 1,
+		Namespace: "default",
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(gotRels) != 2 || gotRels[0].Version != 1 || gotRels[1].Version != 2 {
+		t.Fatalf("incorrect releases for namespace: got %v\n", gotRels)
+	}
+
+	// releases can be queried across clusters
+	gotRels, err = tester.repo.HelmRelease.ListHelmReleases(&repository.HelmReleaseFilter{
+		Name:   "web",
+		Status: "deployed",
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(gotRels) != 3 {
+		t.Fatalf("length of deployed releases incorrect: expected %d, got %d\n", 3, len(gotRels))
+	}
+
+	// updating and deleting should not affect other namespaces
+	rel.Status = "superseded"
+
+	if _, err := tester.repo.HelmRelease.UpdateHelmRelease(rel); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if err := tester.repo.HelmRelease.DeleteHelmRelease(rels[0]); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	gotRels, err = tester.repo.HelmRelease.ListHelmReleases(&repository.HelmReleaseFilter{
+		ClusterID: 1,
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(gotRels) != 2 {
+		t.Fatalf("length of releases incorrect: expected %d, got %d\n", 2, len(gotRels))
+	}
+
+	// a deleted key can be reused
+	_, err = tester.repo.HelmRelease.CreateHelmRelease(&models.HelmRelease{
+		ClusterID: 1,
+		Namespace: "default",
+		Key:       "sh.helm.release.v1.web.v1",
+		Name:      "web",
+		Version:   1,
+	})
+
+	if err != nil {
+		t.Errorf("expected deleted key to be reusable: %v\n", err)
+	}
+}
@@ -63,6 +63,19 @@ func setupTestEnv(tester *tester, t *testing.T) {
 		&models.Infra{},
 		&models.GitActionConfig{},
 		&models.Invite{},
+		&models.Policy{},
+		&models.APIToken{},
+		&models.AuditEvent{},
+		&models.Deployment{},
+		&models.DeployWebhookToken{},
+		&models.HelmRelease{},
+		&models.EnvGroup{},
+		&models.EnvGroupVersion{},
+		&models.EnvGroupRelease{},
+		&models.PreviewEnvironmentConfig{},
+		&models.PreviewEnvironment{},
+		&models.Rollout{},
+		&models.NotificationConfig{},
 		&ints.KubeIntegration{},
 		&ints.BasicIntegration{},
 		&ints.OIDCIntegration{},
@@ -73,6 +86,7 @@ func setupTestEnv(tester *tester, t *testing.T) {
 		&ints.RegTokenCache{},
 		&ints.HelmRepoTokenCache{},
 		&ints.GithubAppInstallation{},
+		&ints.WebhookIntegration{},
 	)
 
 	if err != nil {
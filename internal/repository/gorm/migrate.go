@@ -29,6 +29,19 @@ func AutoMigrate(db *gorm.DB) error {
 		&models.NotificationConfig{},
 		&models.EventContainer{},
 		&models.SubEvent{},
+		&models.Policy{},
+		&models.APIToken{},
+		&models.AuditEvent{},
+		&models.Deployment{},
+		&models.DeployWebhookToken{},
+		&models.HelmRelease{},
+		&models.EnvGroup{},
+		&models.EnvGroupVersion{},
+		&models.EnvGroupRelease{},
+		&models.PreviewEnvironmentConfig{},
+		&models.PreviewEnvironment{},
+		&models.Rollout{},
+		&models.RetentionPolicy{},
 		&ints.KubeIntegration{},
 		&ints.BasicIntegration{},
 		&ints.OIDCIntegration{},
@@ -42,5 +55,6 @@ func AutoMigrate(db *gorm.DB) error {
 		&ints.GithubAppInstallation{},
 		&ints.GithubAppOAuthIntegration{},
 		&ints.SlackIntegration{},
+		&ints.WebhookIntegration{},
 	)
 }
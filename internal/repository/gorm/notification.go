@@ -34,11 +34,31 @@ func (repo NotificationConfigRepository) ReadNotificationConfig(id uint) (*model
 	return ret, nil
 }
 
-// UpdateNotificationConfig updates a given NotificationConfig
+// UpdateNotificationConfig updates a given NotificationConfig. The failure count is
+// not written, since it is updated by UpdateNotificationConfigFailures.
 func (repo NotificationConfigRepository) UpdateNotificationConfig(am *models.NotificationConfig) (*models.NotificationConfig, error) {
-	if err := repo.db.Save(am).Error; err != nil {
+	if err := repo.db.Omit("consecutive_failures").Save(am).Error; err != nil {
 		return nil, err
 	}
 
 	return am, nil
 }
+
+// UpdateNotificationConfigFailures increments the failure count of a
+// NotificationConfig if a deploy failed, and resets it otherwise. The count is
+// updated in a single statement, so that concurrent deploys are all counted.
+func (repo NotificationConfigRepository) UpdateNotificationConfigFailures(id uint, failed bool) (*models.NotificationConfig, error) {
+	failures := gorm.Expr("consecutive_failures + 1")
+
+	if !failed {
+		failures = gorm.Expr("0")
+	}
+
+	if err := repo.db.Model(&models.NotificationConfig{}).
+		Where("id = ?", id).
+		Update("consecutive_failures", failures).Error; err != nil {
+		return nil, err
+	}
+
+	return repo.ReadNotificationConfig(id)
+}
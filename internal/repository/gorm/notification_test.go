@@ -0,0 +1,59 @@
+package gorm_test
+
+import (
+	"testing"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+func TestUpdateNotificationConfigFailures(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_notification_failures.db",
+	}
+
+	setupTestEnv(tester, t)
+	defer cleanup(tester, t)
+
+	conf, err := tester.repo.NotificationConfig.CreateNotificationConfig(&models.NotificationConfig{
+		Enabled:          true,
+		FailureThreshold: 3,
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, err := tester.repo.NotificationConfig.UpdateNotificationConfigFailures(conf.ID, true); err != nil {
+			t.Fatalf("%v\n", err)
+		}
+	}
+
+	// updating the rules with a stale config does not overwrite the failure count
+	conf.FailureThreshold = 5
+
+	if _, err := tester.repo.NotificationConfig.UpdateNotificationConfig(conf); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	gotConf, err := tester.repo.NotificationConfig.ReadNotificationConfig(conf.ID)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if gotConf.ConsecutiveFailures != 2 || gotConf.FailureThreshold != 5 {
+		t.Errorf("incorrect config: expected 2 failures and a threshold of 5, got %d and %d\n",
+			gotConf.ConsecutiveFailures, gotConf.FailureThreshold)
+	}
+
+	gotConf, err = tester.repo.NotificationConfig.UpdateNotificationConfigFailures(conf.ID, false)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if gotConf.ConsecutiveFailures != 0 {
+		t.Errorf("expected failures to be reset, got %d\n", gotConf.ConsecutiveFailures)
+	}
+}
@@ -0,0 +1,67 @@
+package gorm
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// PolicyRepository uses gorm.DB for querying the database
+type PolicyRepository struct {
+	db *gorm.DB
+}
+
+// NewPolicyRepository returns a PolicyRepository which uses
+// gorm.DB for querying the database
+func NewPolicyRepository(db *gorm.DB) repository.PolicyRepository {
+	return &PolicyRepository{db}
+}
+
+// CreatePolicy creates a new policy
+func (repo *PolicyRepository) CreatePolicy(policy *models.Policy) (*models.Policy, error) {
+	if err := repo.db.Create(policy).Error; err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// ReadPolicy gets a policy specified by a project id and a unique id
+func (repo *PolicyRepository) ReadPolicy(projID, policyID uint) (*models.Policy, error) {
+	policy := &models.Policy{}
+
+	if err := repo.db.Where("project_id = ? AND id = ?", projID, policyID).First(&policy).Error; err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// ListPoliciesByProjectID finds all policies for a given project id
+func (repo *PolicyRepository) ListPoliciesByProjectID(projID uint) ([]*models.Policy, error) {
+	policies := []*models.Policy{}
+
+	if err := repo.db.Where("project_id = ?", projID).Find(&policies).Error; err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+// UpdatePolicy modifies an existing Policy in the database
+func (repo *PolicyRepository) UpdatePolicy(policy *models.Policy) (*models.Policy, error) {
+	if err := repo.db.Save(policy).Error; err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// DeletePolicy removes a policy from the db
+func (repo *PolicyRepository) DeletePolicy(policy *models.Policy) (*models.Policy, error) {
+	if err := repo.db.Delete(&policy).Error; err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
@@ -0,0 +1,125 @@
+package gorm_test
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/porter-dev/porter/internal/models"
+	orm "gorm.io/gorm"
+)
+
+func TestCreatePolicy(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_create_policy.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	policy := &models.Policy{
+		ProjectID:   tester.initProjects[0].ID,
+		Name:        "deployer",
+		PolicyBytes: []byte(`[{"scope":"project","verbs":["get","list"]}]`),
+	}
+
+	policy, err := tester.repo.Policy.CreatePolicy(policy)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	policy, err = tester.repo.Policy.ReadPolicy(tester.initProjects[0].ID, policy.ID)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	// make sure id is 1 and name is "deployer"
+	if policy.Model.ID != 1 {
+		t.Errorf("incorrect policy ID: expected %d, got %d\n", 1, policy.Model.ID)
+	}
+
+	if policy.Name != "deployer" {
+		t.Errorf("incorrect policy name: expected %s, got %s\n", "deployer", policy.Name)
+	}
+
+	// make sure the policy cannot be read from a different project
+	_, err = tester.repo.Policy.ReadPolicy(tester.initProjects[0].ID+1, policy.ID)
+
+	if err != orm.ErrRecordNotFound {
+		t.Fatalf("incorrect error: expected %v, got %v\n", orm.ErrRecordNotFound, err)
+	}
+}
+
+func TestListPoliciesByProjectID(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_list_policies.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	policy := &models.Policy{
+		ProjectID:   tester.initProjects[0].ID,
+		Name:        "deployer",
+		PolicyBytes: []byte(`[{"scope":"project","verbs":["get","list"]}]`),
+	}
+
+	policy, err := tester.repo.Policy.CreatePolicy(policy)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	policies, err := tester.repo.Policy.ListPoliciesByProjectID(tester.initProjects[0].ID)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(policies) != 1 {
+		t.Fatalf("length of policies incorrect: expected %d, got %d\n", 1, len(policies))
+	}
+
+	// make sure data is correct
+	expPolicy := policy.Externalize()
+	gotPolicy := policies[0].Externalize()
+
+	if diff := deep.Equal(expPolicy, gotPolicy); diff != nil {
+		t.Errorf("incorrect policy")
+		t.Error(diff)
+	}
+}
+
+func TestDeletePolicy(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_delete_policy.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	policy, err := tester.repo.Policy.CreatePolicy(&models.Policy{
+		ProjectID: tester.initProjects[0].ID,
+		Name:      "deployer",
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	_, err = tester.repo.Policy.DeletePolicy(policy)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	_, err = tester.repo.Policy.ReadPolicy(tester.initProjects[0].ID, policy.ID)
+
+	if err != orm.ErrRecordNotFound {
+		t.Fatalf("incorrect error: expected %v, got %v\n", orm.ErrRecordNotFound, err)
+	}
+}
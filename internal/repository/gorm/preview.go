@@ -0,0 +1,158 @@
+package gorm
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// PreviewEnvironmentRepository uses gorm.DB for querying the database
+type PreviewEnvironmentRepository struct {
+	db *gorm.DB
+}
+
+// NewPreviewEnvironmentRepository returns a PreviewEnvironmentRepository which uses
+// gorm.DB for querying the database
+func NewPreviewEnvironmentRepository(db *gorm.DB) repository.PreviewEnvironmentRepository {
+	return &PreviewEnvironmentRepository{db}
+}
+
+// CreatePreviewEnvironmentConfig creates a new preview environment config
+func (repo *PreviewEnvironmentRepository) CreatePreviewEnvironmentConfig(
+	conf *models.PreviewEnvironmentConfig,
+) (*models.PreviewEnvironmentConfig, error) {
+	if err := repo.db.Create(conf).Error; err != nil {
+		return nil, err
+	}
+
+	return conf, nil
+}
+
+// ReadPreviewEnvironmentConfig finds a preview environment config by id
+func (repo *PreviewEnvironmentRepository) ReadPreviewEnvironmentConfig(
+	id uint,
+) (*models.PreviewEnvironmentConfig, error) {
+	conf := &models.PreviewEnvironmentConfig{}
+
+	if err := repo.db.Where("id = ?", id).First(&conf).Error; err != nil {
+		return nil, err
+	}
+
+	return conf, nil
+}
+
+// ReadPreviewEnvironmentConfigByReleaseID finds the preview environment config of
+// a release
+func (repo *PreviewEnvironmentRepository) ReadPreviewEnvironmentConfigByReleaseID(
+	releaseID uint,
+) (*models.PreviewEnvironmentConfig, error) {
+	conf := &models.PreviewEnvironmentConfig{}
+
+	if err := repo.db.Where("release_id = ?", releaseID).First(&conf).Error; err != nil {
+		return nil, err
+	}
+
+	return conf, nil
+}
+
+// ListPreviewEnvironmentConfigsByGitRepo finds all preview environment configs of
+// releases built from a git repo
+func (repo *PreviewEnvironmentRepository) ListPreviewEnvironmentConfigsByGitRepo(
+	gitRepo string,
+) ([]*models.PreviewEnvironmentConfig, error) {
+	confs := []*models.PreviewEnvironmentConfig{}
+
+	if err := repo.db.Where("git_repo = ?", gitRepo).Find(&confs).Error; err != nil {
+		return nil, err
+	}
+
+	return confs, nil
+}
+
+// DeletePreviewEnvironmentConfig permanently removes a preview environment config
+// along with the records of its preview environments
+func (repo *PreviewEnvironmentRepository) DeletePreviewEnvironmentConfig(
+	conf *models.PreviewEnvironmentConfig,
+) error {
+	return repo.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Where(
+			"preview_environment_config_id = ?",
+			conf.ID,
+		).Delete(&models.PreviewEnvironment{}).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Delete(conf).Error
+	})
+}
+
+// CreatePreviewEnvironment creates a new preview environment
+func (repo *PreviewEnvironmentRepository) CreatePreviewEnvironment(
+	env *models.PreviewEnvironment,
+) (*models.PreviewEnvironment, error) {
+	if err := repo.db.Create(env).Error; err != nil {
+		return nil, err
+	}
+
+	return env, nil
+}
+
+// ReadPreviewEnvironment finds the preview environment of a pull request
+func (repo *PreviewEnvironmentRepository) ReadPreviewEnvironment(
+	configID uint,
+	prNumber int,
+) (*models.PreviewEnvironment, error) {
+	env := &models.PreviewEnvironment{}
+
+	if err := repo.db.Where(
+		"preview_environment_config_id = ? AND pull_request_number = ?",
+		configID,
+		prNumber,
+	).First(&env).Error; err != nil {
+		return nil, err
+	}
+
+	return env, nil
+}
+
+// ReadPreviewEnvironmentByNamespace finds the preview environment that is deployed
+// to a namespace
+func (repo *PreviewEnvironmentRepository) ReadPreviewEnvironmentByNamespace(
+	namespace string,
+) (*models.PreviewEnvironment, error) {
+	env := &models.PreviewEnvironment{}
+
+	if err := repo.db.Where("namespace = ?", namespace).First(&env).Error; err != nil {
+		return nil, err
+	}
+
+	return env, nil
+}
+
+// ListPreviewEnvironments finds all preview environments of a config, ordered by
+// pull request number
+func (repo *PreviewEnvironmentRepository) ListPreviewEnvironments(
+	configID uint,
+) ([]*models.PreviewEnvironment, error) {
+	envs := []*models.PreviewEnvironment{}
+
+	if err := repo.db.Where(
+		"preview_environment_config_id = ?",
+		configID,
+	).Order("pull_request_number asc").Find(&envs).Error; err != nil {
+		return nil, err
+	}
+
+	return envs, nil
+}
+
+// UpdatePreviewEnvironment modifies an existing preview environment in the database
+func (repo *PreviewEnvironmentRepository) UpdatePreviewEnvironment(
+	env *models.PreviewEnvironment,
+) (*models.PreviewEnvironment, error) {
+	if err := repo.db.Save(env).Error; err != nil {
+		return nil, err
+	}
+
+	return env, nil
+}
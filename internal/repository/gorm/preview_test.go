@@ -0,0 +1,107 @@
+package gorm_test
+
+import (
+	"testing"
+
+	"github.com/porter-dev/porter/internal/models"
+	"gorm.io/gorm"
+)
+
+func TestPreviewEnvironments(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_preview_environments.db",
+	}
+
+	setupTestEnv(tester, t)
+	defer cleanup(tester, t)
+
+	conf, err := tester.repo.PreviewEnvironment.CreatePreviewEnvironmentConfig(&models.PreviewEnvironmentConfig{
+		ProjectID:   1,
+		ClusterID:   1,
+		ReleaseID:   1,
+		Namespace:   "default",
+		ReleaseName: "web",
+		GitRepo:     "porter-dev/porter",
+		BaseDomain:  "preview.example.com",
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	// a release can only have a single config
+	_, err = tester.repo.PreviewEnvironment.CreatePreviewEnvironmentConfig(&models.PreviewEnvironmentConfig{
+		ReleaseID: 1,
+		GitRepo:   "porter-dev/porter",
+	})
+
+	if err == nil {
+		t.Errorf("expected error creating duplicate config\n")
+	}
+
+	confs, err := tester.repo.PreviewEnvironment.ListPreviewEnvironmentConfigsByGitRepo("porter-dev/porter")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(confs) != 1 || confs[0].PreviewHost(12) != "pr-12-6b86b2-web.preview.example.com" {
+		t.Errorf("incorrect configs listed\n")
+	}
+
+	for _, num := range []int{12, 3} {
+		_, err := tester.repo.PreviewEnvironment.CreatePreviewEnvironment(&models.PreviewEnvironment{
+			PreviewEnvironmentConfigID: conf.ID,
+			PullRequestNumber:          num,
+			Namespace:                  conf.PreviewNamespace(num),
+			Status:                     models.PreviewEnvironmentStatusDeployed,
+		})
+
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+	}
+
+	env, err := tester.repo.PreviewEnvironment.ReadPreviewEnvironment(conf.ID, 12)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	// the preview workflow upgrades the release in the namespace of the environment
+	if nsEnv, err := tester.repo.PreviewEnvironment.ReadPreviewEnvironmentByNamespace(conf.PreviewNamespace(12)); err != nil || nsEnv.ID != env.ID {
+		t.Errorf("expected environment of pull request 12 by namespace, got %v\n", err)
+	}
+
+	if readConf, err := tester.repo.PreviewEnvironment.ReadPreviewEnvironmentConfig(env.PreviewEnvironmentConfigID); err != nil || readConf.ReleaseName != "web" {
+		t.Errorf("expected config of environment, got %v\n", err)
+	}
+
+	env.Status = models.PreviewEnvironmentStatusDeleted
+
+	if _, err := tester.repo.PreviewEnvironment.UpdatePreviewEnvironment(env); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	envs, err := tester.repo.PreviewEnvironment.ListPreviewEnvironments(conf.ID)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(envs) != 2 || envs[0].PullRequestNumber != 3 || envs[1].Status != models.PreviewEnvironmentStatusDeleted {
+		t.Errorf("incorrect environments listed\n")
+	}
+
+	if err := tester.repo.PreviewEnvironment.DeletePreviewEnvironmentConfig(conf); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if _, err := tester.repo.PreviewEnvironment.ReadPreviewEnvironmentConfigByReleaseID(1); err != gorm.ErrRecordNotFound {
+		t.Errorf("expected %v, got %v\n", gorm.ErrRecordNotFound, err)
+	}
+
+	if envs, _ := tester.repo.PreviewEnvironment.ListPreviewEnvironments(conf.ID); len(envs) != 0 {
+		t.Errorf("expected environments to be deleted, got %d\n", len(envs))
+	}
+}
@@ -34,6 +34,15 @@ func (repo *ReleaseRepository) ReadRelease(clusterID uint, name, namespace strin
 	return release, nil
 }
 
+// ReadReleaseByID finds a single release based on its id.
+func (repo *ReleaseRepository) ReadReleaseByID(id uint) (*models.Release, error) {
+	release := &models.Release{}
+	if err := repo.db.Preload("GitActionConfig").Where("id = ?", id).First(&release).Error; err != nil {
+		return nil, err
+	}
+	return release, nil
+}
+
 // ReadRelease finds a single release based on their unique name and namespace pair.
 func (repo *ReleaseRepository) ListReleasesByImageRepoURI(clusterID uint, imageRepoURI string) ([]*models.Release, error) {
 	releases := make([]*models.Release, 0)
@@ -32,7 +32,18 @@ func NewRepository(db *gorm.DB, key *[32]byte) *repository.Repository {
 		GithubAppInstallation:     NewGithubAppInstallationRepository(db),
 		GithubAppOAuthIntegration: NewGithubAppOAuthIntegrationRepository(db),
 		SlackIntegration:          NewSlackIntegrationRepository(db, key),
+		WebhookIntegration:        NewWebhookIntegrationRepository(db, key),
 		NotificationConfig:        NewNotificationConfigRepository(db),
 		Event:                     NewEventRepository(db),
+		Policy:                    NewPolicyRepository(db),
+		APIToken:                  NewAPITokenRepository(db),
+		AuditEvent:                NewAuditEventRepository(db),
+		Deployment:                NewDeploymentRepository(db),
+		DeployWebhookToken:        NewDeployWebhookTokenRepository(db, key),
+		HelmRelease:               NewHelmReleaseRepository(db, key),
+		EnvGroup:                  NewEnvGroupRepository(db, key),
+		PreviewEnvironment:        NewPreviewEnvironmentRepository(db),
+		Rollout:                   NewRolloutRepository(db),
+		RetentionPolicy:           NewRetentionPolicyRepository(db),
 	}
 }
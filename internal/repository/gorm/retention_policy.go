@@ -0,0 +1,102 @@
+package gorm
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// RetentionPolicyRepository uses gorm.DB for querying the database
+type RetentionPolicyRepository struct {
+	db *gorm.DB
+}
+
+// NewRetentionPolicyRepository returns a RetentionPolicyRepository which uses
+// gorm.DB for querying the database
+func NewRetentionPolicyRepository(db *gorm.DB) repository.RetentionPolicyRepository {
+	return &RetentionPolicyRepository{db}
+}
+
+// CreateRetentionPolicy creates a new retention policy
+func (repo *RetentionPolicyRepository) CreateRetentionPolicy(
+	policy *models.RetentionPolicy,
+) (*models.RetentionPolicy, error) {
+	if err := repo.db.Create(policy).Error; err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// ReadRetentionPolicy finds a retention policy by registry id and id
+func (repo *RetentionPolicyRepository) ReadRetentionPolicy(
+	registryID, id uint,
+) (*models.RetentionPolicy, error) {
+	policy := &models.RetentionPolicy{}
+
+	if err := repo.db.Where("registry_id = ? AND id = ?", registryID, id).First(policy).Error; err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// ReadRetentionPolicyByRepository finds the retention policy of a repository in a
+// registry
+func (repo *RetentionPolicyRepository) ReadRetentionPolicyByRepository(
+	registryID uint,
+	repoName string,
+) (*models.RetentionPolicy, error) {
+	policy := &models.RetentionPolicy{}
+
+	if err := repo.db.Where("registry_id = ? AND repository_name = ?", registryID, repoName).First(policy).Error; err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// ListRetentionPoliciesByRegistryID finds all retention policies of a registry
+func (repo *RetentionPolicyRepository) ListRetentionPoliciesByRegistryID(
+	registryID uint,
+) ([]*models.RetentionPolicy, error) {
+	policies := []*models.RetentionPolicy{}
+
+	if err := repo.db.Where("registry_id = ?", registryID).Find(&policies).Error; err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+// ListEnabledRetentionPolicies finds the retention policies of all registries that
+// are applied by the background job
+func (repo *RetentionPolicyRepository) ListEnabledRetentionPolicies() ([]*models.RetentionPolicy, error) {
+	policies := []*models.RetentionPolicy{}
+
+	if err := repo.db.Where("enabled = ?", true).Find(&policies).Error; err != nil {
+		return nil, err
+	}
+
+	return policies, nil
+}
+
+// UpdateRetentionPolicy modifies an existing retention policy
+func (repo *RetentionPolicyRepository) UpdateRetentionPolicy(
+	policy *models.RetentionPolicy,
+) (*models.RetentionPolicy, error) {
+	if err := repo.db.Save(policy).Error; err != nil {
+		return nil, err
+	}
+
+	return policy, nil
+}
+
+// DeleteRetentionPolicy deletes a retention policy by id
+func (repo *RetentionPolicyRepository) DeleteRetentionPolicy(id uint) error {
+	if err := repo.db.Where("id = ?", id).Delete(&models.RetentionPolicy{}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package gorm
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// RolloutRepository uses gorm.DB for querying the database
+type RolloutRepository struct {
+	db *gorm.DB
+}
+
+// NewRolloutRepository returns a RolloutRepository which uses gorm.DB for
+// querying the database
+func NewRolloutRepository(db *gorm.DB) repository.RolloutRepository {
+	return &RolloutRepository{db}
+}
+
+// CreateRollout creates a new rollout
+func (repo *RolloutRepository) CreateRollout(rollout *models.Rollout) (*models.Rollout, error) {
+	if err := repo.db.Create(rollout).Error; err != nil {
+		return nil, err
+	}
+
+	return rollout, nil
+}
+
+// ReadRollout finds a rollout by id
+func (repo *RolloutRepository) ReadRollout(id uint) (*models.Rollout, error) {
+	rollout := &models.Rollout{}
+
+	if err := repo.db.Where("id = ?", id).First(rollout).Error; err != nil {
+		return nil, err
+	}
+
+	return rollout, nil
+}
+
+// ListRolloutsByRelease finds the rollouts of a release, ordered from newest to
+// oldest
+func (repo *RolloutRepository) ListRolloutsByRelease(
+	clusterID uint,
+	namespace, name string,
+) ([]*models.Rollout, error) {
+	rollouts := []*models.Rollout{}
+
+	query := repo.db.Where("cluster_id = ? AND namespace = ? AND release_name = ?", clusterID, namespace, name)
+
+	if err := query.Order("created_at desc, id desc").Find(&rollouts).Error; err != nil {
+		return nil, err
+	}
+
+	return rollouts, nil
+}
+
+// ListRolloutsByStatus finds the rollouts of every project with a status
+func (repo *RolloutRepository) ListRolloutsByStatus(status string) ([]*models.Rollout, error) {
+	rollouts := []*models.Rollout{}
+
+	if err := repo.db.Where("status = ?", status).Find(&rollouts).Error; err != nil {
+		return nil, err
+	}
+
+	return rollouts, nil
+}
+
+// UpdateRollout modifies an existing rollout
+func (repo *RolloutRepository) UpdateRollout(rollout *models.Rollout) (*models.Rollout, error) {
+	if err := repo.db.Save(rollout).Error; err != nil {
+		return nil, err
+	}
+
+	return rollout, nil
+}
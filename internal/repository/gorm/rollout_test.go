@@ -0,0 +1,139 @@
+package gorm_test
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+func TestCreateAndUpdateRollout(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_create_rollout.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	projID := tester.initProjects[0].ID
+
+	rollout := &models.Rollout{
+		ProjectID:    projID,
+		ClusterID:    1,
+		Namespace:    "default",
+		ReleaseName:  "web",
+		Strategy:     "canary",
+		StepDuration: 300,
+		MaxErrorRate: 5,
+		MaxLatency:   1,
+		Status:       models.RolloutStatusProgressing,
+	}
+
+	rollout.SetSteps([]uint{10, 50})
+
+	rollout, err := tester.repo.Rollout.CreateRollout(rollout)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	now := time.Now()
+
+	rollout.Weight = 50
+	rollout.Revision = 4
+	rollout.Status = models.RolloutStatusPromoted
+	rollout.FinishedAt = &now
+
+	if _, err := tester.repo.Rollout.UpdateRollout(rollout); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	gotRollout, err := tester.repo.Rollout.ReadRollout(rollout.ID)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if gotRollout.Status != models.RolloutStatusPromoted || gotRollout.Revision != 4 || gotRollout.FinishedAt == nil {
+		t.Errorf("incorrect rollout: got %v\n", gotRollout.Externalize())
+	}
+
+	if steps := gotRollout.GetSteps(); !reflect.DeepEqual(steps, []uint{10, 50}) {
+		t.Errorf("incorrect steps: expected %v, got %v\n", []uint{10, 50}, steps)
+	}
+}
+
+func TestListRolloutsByRelease(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_list_rollouts.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	projID := tester.initProjects[0].ID
+
+	rollouts := []*models.Rollout{
+		{ProjectID: projID, ClusterID: 1, Namespace: "default", ReleaseName: "web", Strategy: "canary"},
+		{ProjectID: projID, ClusterID: 1, Namespace: "default", ReleaseName: "web", Strategy: "blue_green"},
+		{ProjectID: projID, ClusterID: 1, Namespace: "default", ReleaseName: "api", Strategy: "canary"},
+		{ProjectID: projID, ClusterID: 2, Namespace: "default", ReleaseName: "web", Strategy: "canary"},
+	}
+
+	for _, rollout := range rollouts {
+		if _, err := tester.repo.Rollout.CreateRollout(rollout); err != nil {
+			t.Fatalf("%v\n", err)
+		}
+	}
+
+	gotRollouts, err := tester.repo.Rollout.ListRolloutsByRelease(1, "default", "web")
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(gotRollouts) != 2 {
+		t.Fatalf("length of rollouts incorrect: expected %d, got %d\n", 2, len(gotRollouts))
+	}
+
+	if gotRollouts[0].Strategy != "blue_green" {
+		t.Errorf("incorrect first rollout: expected strategy %s, got %s\n", "blue_green", gotRollouts[0].Strategy)
+	}
+}
+
+func TestListRolloutsByStatus(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_list_rollouts_status.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	projID := tester.initProjects[0].ID
+
+	rollouts := []*models.Rollout{
+		{ProjectID: projID, ClusterID: 1, Namespace: "default", ReleaseName: "web", Status: models.RolloutStatusPromoted},
+		{ProjectID: projID, ClusterID: 1, Namespace: "default", ReleaseName: "web", Status: models.RolloutStatusProgressing},
+		{ProjectID: projID, ClusterID: 2, Namespace: "default", ReleaseName: "api", Status: models.RolloutStatusProgressing},
+	}
+
+	for _, rollout := range rollouts {
+		if _, err := tester.repo.Rollout.CreateRollout(rollout); err != nil {
+			t.Fatalf("%v\n", err)
+		}
+	}
+
+	gotRollouts, err := tester.repo.Rollout.ListRolloutsByStatus(models.RolloutStatusProgressing)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(gotRollouts) != 2 {
+		t.Fatalf("length of rollouts incorrect: expected %d, got %d\n", 2, len(gotRollouts))
+	}
+}
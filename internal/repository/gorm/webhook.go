@@ -0,0 +1,176 @@
+package gorm
+
+import (
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+
+	ints "github.com/porter-dev/porter/internal/models/integrations"
+)
+
+// WebhookIntegrationRepository uses gorm.DB for querying the database
+type WebhookIntegrationRepository struct {
+	db  *gorm.DB
+	key *[32]byte
+}
+
+// NewWebhookIntegrationRepository returns a WebhookIntegrationRepository which uses
+// gorm.DB for querying the database. It accepts an encryption key to encrypt
+// sensitive data
+func NewWebhookIntegrationRepository(
+	db *gorm.DB,
+	key *[32]byte,
+) repository.WebhookIntegrationRepository {
+	return &WebhookIntegrationRepository{db, key}
+}
+
+// CreateWebhookIntegration creates a new webhook integration
+func (repo *WebhookIntegrationRepository) CreateWebhookIntegration(
+	webhookInt *ints.WebhookIntegration,
+) (*ints.WebhookIntegration, error) {
+	err := repo.EncryptWebhookIntegrationData(webhookInt, repo.key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repo.db.Create(webhookInt).Error; err != nil {
+		return nil, err
+	}
+
+	err = repo.DecryptWebhookIntegrationData(webhookInt, repo.key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return webhookInt, nil
+}
+
+// ReadWebhookIntegration finds a webhook integration by project id and id
+func (repo *WebhookIntegrationRepository) ReadWebhookIntegration(
+	projectID, integrationID uint,
+) (*ints.WebhookIntegration, error) {
+	webhookInt := &ints.WebhookIntegration{}
+
+	if err := repo.db.Where("project_id = ? AND id = ?", projectID, integrationID).First(&webhookInt).Error; err != nil {
+		return nil, err
+	}
+
+	err := repo.DecryptWebhookIntegrationData(webhookInt, repo.key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return webhookInt, nil
+}
+
+// ListWebhookIntegrationsByProjectID finds all webhook integrations
+// for a given project id
+func (repo *WebhookIntegrationRepository) ListWebhookIntegrationsByProjectID(
+	projectID uint,
+) ([]*ints.WebhookIntegration, error) {
+	webhookInts := []*ints.WebhookIntegration{}
+
+	if err := repo.db.Where("project_id = ?", projectID).Find(&webhookInts).Error; err != nil {
+		return nil, err
+	}
+
+	for _, webhookInt := range webhookInts {
+		repo.DecryptWebhookIntegrationData(webhookInt, repo.key)
+	}
+
+	return webhookInts, nil
+}
+
+// UpdateWebhookIntegration modifies an existing webhook integration in the database
+func (repo *WebhookIntegrationRepository) UpdateWebhookIntegration(
+	webhookInt *ints.WebhookIntegration,
+) (*ints.WebhookIntegration, error) {
+	err := repo.EncryptWebhookIntegrationData(webhookInt, repo.key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := repo.db.Save(webhookInt).Error; err != nil {
+		return nil, err
+	}
+
+	err = repo.DecryptWebhookIntegrationData(webhookInt, repo.key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return webhookInt, nil
+}
+
+// DeleteWebhookIntegration deletes a webhook integration by ID
+func (repo *WebhookIntegrationRepository) DeleteWebhookIntegration(
+	integrationID uint,
+) error {
+	if err := repo.db.Where("id = ?", integrationID).Delete(&ints.WebhookIntegration{}).Error; err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// EncryptWebhookIntegrationData will encrypt the webhook integration data before
+// writing to the DB
+func (repo *WebhookIntegrationRepository) EncryptWebhookIntegrationData(
+	webhookInt *ints.WebhookIntegration,
+	key *[32]byte,
+) error {
+	if len(webhookInt.URL) > 0 {
+		cipherData, err := repository.Encrypt(webhookInt.URL, key)
+
+		if err != nil {
+			return err
+		}
+
+		webhookInt.URL = cipherData
+	}
+
+	if len(webhookInt.Secret) > 0 {
+		cipherData, err := repository.Encrypt(webhookInt.Secret, key)
+
+		if err != nil {
+			return err
+		}
+
+		webhookInt.Secret = cipherData
+	}
+
+	return nil
+}
+
+// DecryptWebhookIntegrationData will decrypt the webhook integration data before
+// returning it from the DB
+func (repo *WebhookIntegrationRepository) DecryptWebhookIntegrationData(
+	webhookInt *ints.WebhookIntegration,
+	key *[32]byte,
+) error {
+	if len(webhookInt.URL) > 0 {
+		plaintext, err := repository.Decrypt(webhookInt.URL, key)
+
+		if err != nil {
+			return err
+		}
+
+		webhookInt.URL = plaintext
+	}
+
+	if len(webhookInt.Secret) > 0 {
+		plaintext, err := repository.Decrypt(webhookInt.Secret, key)
+
+		if err != nil {
+			return err
+		}
+
+		webhookInt.Secret = plaintext
+	}
+
+	return nil
+}
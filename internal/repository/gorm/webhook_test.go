@@ -0,0 +1,91 @@
+package gorm_test
+
+import (
+	"testing"
+
+	ints "github.com/porter-dev/porter/internal/models/integrations"
+	orm "gorm.io/gorm"
+)
+
+func TestCreateWebhookIntegration(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_create_webhook_int.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	webhookInt := &ints.WebhookIntegration{
+		ProjectID: tester.initProjects[0].ID,
+		Name:      "deploys",
+		URL:       []byte("https://example.com/hook"),
+		Secret:    []byte("secret"),
+	}
+
+	webhookInt, err := tester.repo.WebhookIntegration.CreateWebhookIntegration(webhookInt)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	webhookInt, err = tester.repo.WebhookIntegration.ReadWebhookIntegration(tester.initProjects[0].ID, webhookInt.ID)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if webhookInt.Name != "deploys" {
+		t.Errorf("incorrect webhook name: expected %s, got %s\n", "deploys", webhookInt.Name)
+	}
+
+	if string(webhookInt.URL) != "https://example.com/hook" {
+		t.Errorf("incorrect webhook url: expected %s, got %s\n", "https://example.com/hook", webhookInt.URL)
+	}
+
+	if string(webhookInt.Secret) != "secret" {
+		t.Errorf("incorrect webhook secret: expected %s, got %s\n", "secret", webhookInt.Secret)
+	}
+
+	// make sure the webhook cannot be read from a different project
+	_, err = tester.repo.WebhookIntegration.ReadWebhookIntegration(tester.initProjects[0].ID+1, webhookInt.ID)
+
+	if err != orm.ErrRecordNotFound {
+		t.Fatalf("incorrect error: expected %v, got %v\n", orm.ErrRecordNotFound, err)
+	}
+}
+
+func TestDeleteWebhookIntegration(t *testing.T) {
+	tester := &tester{
+		dbFileName: "./porter_delete_webhook_int.db",
+	}
+
+	setupTestEnv(tester, t)
+	initProject(tester, t)
+	defer cleanup(tester, t)
+
+	webhookInt, err := tester.repo.WebhookIntegration.CreateWebhookIntegration(&ints.WebhookIntegration{
+		ProjectID: tester.initProjects[0].ID,
+		Name:      "deploys",
+		URL:       []byte("https://example.com/hook"),
+		Secret:    []byte("secret"),
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if err := tester.repo.WebhookIntegration.DeleteWebhookIntegration(webhookInt.ID); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	webhookInts, err := tester.repo.WebhookIntegration.ListWebhookIntegrationsByProjectID(tester.initProjects[0].ID)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(webhookInts) != 0 {
+		t.Errorf("incorrect number of webhook integrations: expected %d, got %d\n", 0, len(webhookInts))
+	}
+}
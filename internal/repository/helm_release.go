@@ -0,0 +1,25 @@
+package repository
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// HelmReleaseFilter selects Helm release records. Zero values are not used as
+// filters, so an empty filter lists the records of every cluster.
+type HelmReleaseFilter struct {
+	ClusterID uint
+	Namespace string
+	Name      string
+	Status    string
+	Owner     string
+	Version   int
+}
+
+// HelmReleaseRepository represents the set of queries on the HelmRelease model
+type HelmReleaseRepository interface {
+	CreateHelmRelease(rel *models.HelmRelease) (*models.HelmRelease, error)
+	ReadHelmRelease(clusterID uint, namespace, key string) (*models.HelmRelease, error)
+	ListHelmReleases(filter *HelmReleaseFilter) ([]*models.HelmRelease, error)
+	UpdateHelmRelease(rel *models.HelmRelease) (*models.HelmRelease, error)
+	DeleteHelmRelease(rel *models.HelmRelease) error
+}
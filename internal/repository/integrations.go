@@ -77,3 +77,13 @@ type GithubAppInstallationRepository interface {
 	ReadGithubAppInstallationByAccountIDs(accountIDs []int64) ([]*ints.GithubAppInstallation, error)
 	DeleteGithubAppInstallationByAccountID(accountID int64) error
 }
+
+// WebhookIntegrationRepository represents the set of queries on a generic
+// webhook integration
+type WebhookIntegrationRepository interface {
+	CreateWebhookIntegration(webhookInt *ints.WebhookIntegration) (*ints.WebhookIntegration, error)
+	ReadWebhookIntegration(projectID, integrationID uint) (*ints.WebhookIntegration, error)
+	ListWebhookIntegrationsByProjectID(projectID uint) ([]*ints.WebhookIntegration, error)
+	UpdateWebhookIntegration(webhookInt *ints.WebhookIntegration) (*ints.WebhookIntegration, error)
+	DeleteWebhookIntegration(integrationID uint) error
+}
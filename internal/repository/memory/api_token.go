@@ -0,0 +1,101 @@
+package test
+
+import (
+	"errors"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// APITokenRepository will return errors on queries if canQuery is false
+// and only stores a small set of API tokens in-memory that are indexed by their
+// array index + 1
+type APITokenRepository struct {
+	canQuery bool
+	tokens   []*models.APIToken
+}
+
+// NewAPITokenRepository will return errors if canQuery is false
+func NewAPITokenRepository(canQuery bool) repository.APITokenRepository {
+	return &APITokenRepository{canQuery, []*models.APIToken{}}
+}
+
+// CreateAPIToken appends a new API token to the in-memory tokens array
+func (repo *APITokenRepository) CreateAPIToken(token *models.APIToken) (*models.APIToken, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	repo.tokens = append(repo.tokens, token)
+	token.ID = uint(len(repo.tokens))
+
+	return token, nil
+}
+
+// ReadAPIToken gets an API token specified by a project id and a unique id
+func (repo *APITokenRepository) ReadAPIToken(projID, tokenID uint) (*models.APIToken, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	if int(tokenID-1) >= len(repo.tokens) || repo.tokens[tokenID-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(tokenID - 1)
+
+	if repo.tokens[index].ProjectID != projID {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return repo.tokens[index], nil
+}
+
+// ReadAPITokenByUniqueID gets an API token by the unique id stored in the encoded token
+func (repo *APITokenRepository) ReadAPITokenByUniqueID(uid string) (*models.APIToken, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	for _, token := range repo.tokens {
+		if token != nil && token.UniqueID == uid {
+			return token, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+// ListAPITokensByProjectID finds all API tokens for a given project id
+func (repo *APITokenRepository) ListAPITokensByProjectID(projID uint) ([]*models.APIToken, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	res := make([]*models.APIToken, 0)
+
+	for _, token := range repo.tokens {
+		if token != nil && token.ProjectID == projID {
+			res = append(res, token)
+		}
+	}
+
+	return res, nil
+}
+
+// UpdateAPIToken modifies an existing APIToken in the database
+func (repo *APITokenRepository) UpdateAPIToken(token *models.APIToken) (*models.APIToken, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	if int(token.ID-1) >= len(repo.tokens) || repo.tokens[token.ID-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(token.ID - 1)
+	repo.tokens[index] = token
+
+	return token, nil
+}
@@ -0,0 +1,89 @@
+package test
+
+import (
+	"errors"
+	"time"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+)
+
+// AuditEventRepository will return errors on queries if canQuery is false
+// and only stores a small set of audit events in-memory that are indexed by their
+// array index + 1
+type AuditEventRepository struct {
+	canQuery bool
+	events   []*models.AuditEvent
+}
+
+// NewAuditEventRepository will return errors if canQuery is false
+func NewAuditEventRepository(canQuery bool) repository.AuditEventRepository {
+	return &AuditEventRepository{canQuery, []*models.AuditEvent{}}
+}
+
+// CreateAuditEvent appends a new audit event to the in-memory events array
+func (repo *AuditEventRepository) CreateAuditEvent(event *models.AuditEvent) (*models.AuditEvent, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+
+	repo.events = append(repo.events, event)
+	event.ID = uint(len(repo.events))
+
+	return event, nil
+}
+
+// ListAuditEventsByProjectID finds the audit events for a project that match the
+// filter, ordered from newest to oldest
+func (repo *AuditEventRepository) ListAuditEventsByProjectID(
+	projID uint,
+	filter *repository.AuditEventFilter,
+) ([]*models.AuditEvent, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	res := make([]*models.AuditEvent, 0)
+
+	for i := len(repo.events) - 1; i >= 0; i-- {
+		event := repo.events[i]
+
+		if event.ProjectID != projID {
+			continue
+		}
+
+		if filter.UserID != 0 && event.UserID != filter.UserID {
+			continue
+		}
+
+		if filter.ResourceType != "" && event.ResourceType != filter.ResourceType {
+			continue
+		}
+
+		if filter.Since != nil && event.CreatedAt.Before(*filter.Since) {
+			continue
+		}
+
+		if filter.Until != nil && event.CreatedAt.After(*filter.Until) {
+			continue
+		}
+
+		res = append(res, event)
+	}
+
+	if filter.Offset >= len(res) {
+		return make([]*models.AuditEvent, 0), nil
+	}
+
+	res = res[filter.Offset:]
+
+	if filter.Limit > 0 && filter.Limit < len(res) {
+		res = res[:filter.Limit]
+	}
+
+	return res, nil
+}
@@ -0,0 +1,112 @@
+package test
+
+import (
+	"errors"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// DeployWebhookTokenRepository will return errors on queries if canQuery is false
+// and only stores a small set of deploy webhook tokens in-memory that are indexed
+// by their array index + 1
+type DeployWebhookTokenRepository struct {
+	canQuery bool
+	tokens   []*models.DeployWebhookToken
+}
+
+// NewDeployWebhookTokenRepository will return errors if canQuery is false
+func NewDeployWebhookTokenRepository(canQuery bool) repository.DeployWebhookTokenRepository {
+	return &DeployWebhookTokenRepository{canQuery, []*models.DeployWebhookToken{}}
+}
+
+// CreateDeployWebhookToken appends a new deploy webhook token to the in-memory array
+func (repo *DeployWebhookTokenRepository) CreateDeployWebhookToken(
+	token *models.DeployWebhookToken,
+) (*models.DeployWebhookToken, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	repo.tokens = append(repo.tokens, token)
+	token.ID = uint(len(repo.tokens))
+
+	return token, nil
+}
+
+// ReadDeployWebhookToken finds a deploy webhook token by release id and id
+func (repo *DeployWebhookTokenRepository) ReadDeployWebhookToken(
+	releaseID, tokenID uint,
+) (*models.DeployWebhookToken, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	if int(tokenID-1) >= len(repo.tokens) || repo.tokens[tokenID-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(tokenID - 1)
+
+	if repo.tokens[index].ReleaseID != releaseID {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return repo.tokens[index], nil
+}
+
+// ReadDeployWebhookTokenByToken finds a deploy webhook token by its unique token
+func (repo *DeployWebhookTokenRepository) ReadDeployWebhookTokenByToken(
+	tokenStr string,
+) (*models.DeployWebhookToken, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	for _, token := range repo.tokens {
+		if token != nil && token.Token == tokenStr {
+			return token, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+// ListDeployWebhookTokensByReleaseID finds all deploy webhook tokens for a
+// given release id
+func (repo *DeployWebhookTokenRepository) ListDeployWebhookTokensByReleaseID(
+	releaseID uint,
+) ([]*models.DeployWebhookToken, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	res := make([]*models.DeployWebhookToken, 0)
+
+	for _, token := range repo.tokens {
+		if token != nil && token.ReleaseID == releaseID {
+			res = append(res, token)
+		}
+	}
+
+	return res, nil
+}
+
+// UpdateDeployWebhookToken modifies an existing deploy webhook token in memory
+func (repo *DeployWebhookTokenRepository) UpdateDeployWebhookToken(
+	token *models.DeployWebhookToken,
+) (*models.DeployWebhookToken, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	if int(token.ID-1) >= len(repo.tokens) || repo.tokens[token.ID-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(token.ID - 1)
+	repo.tokens[index] = token
+
+	return token, nil
+}
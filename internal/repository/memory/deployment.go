@@ -0,0 +1,94 @@
+package test
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+)
+
+// DeploymentRepository will return errors on queries if canQuery is false
+// and only stores a small set of deployments in-memory that are indexed by their
+// array index + 1
+type DeploymentRepository struct {
+	canQuery    bool
+	mu          sync.Mutex
+	deployments []*models.Deployment
+}
+
+// NewDeploymentRepository will return errors if canQuery is false
+func NewDeploymentRepository(canQuery bool) repository.DeploymentRepository {
+	return &DeploymentRepository{canQuery: canQuery, deployments: []*models.Deployment{}}
+}
+
+// CreateDeployment appends a new deployment to the in-memory deployments array
+func (repo *DeploymentRepository) CreateDeployment(deployment *models.Deployment) (*models.Deployment, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	// deployments from a batch image update are recorded concurrently
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if deployment.CreatedAt.IsZero() {
+		deployment.CreatedAt = time.Now()
+	}
+
+	repo.deployments = append(repo.deployments, deployment)
+	deployment.ID = uint(len(repo.deployments))
+
+	return deployment, nil
+}
+
+// ListDeploymentsByProjectID finds the deployments for a project that match the
+// filter, ordered from newest to oldest
+func (repo *DeploymentRepository) ListDeploymentsByProjectID(
+	projID uint,
+	filter *repository.DeploymentFilter,
+) ([]*models.Deployment, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	res := make([]*models.Deployment, 0)
+
+	for i := len(repo.deployments) - 1; i >= 0; i-- {
+		deployment := repo.deployments[i]
+
+		if deployment.ProjectID != projID {
+			continue
+		}
+
+		if filter.ClusterID != 0 && deployment.ClusterID != filter.ClusterID {
+			continue
+		}
+
+		if filter.Namespace != "" && deployment.Namespace != filter.Namespace {
+			continue
+		}
+
+		if filter.Name != "" && deployment.Name != filter.Name {
+			continue
+		}
+
+		res = append(res, deployment)
+	}
+
+	if filter.Offset >= len(res) {
+		return make([]*models.Deployment, 0), nil
+	}
+
+	res = res[filter.Offset:]
+
+	if filter.Limit > 0 && filter.Limit < len(res) {
+		res = res[:filter.Limit]
+	}
+
+	return res, nil
+}
@@ -0,0 +1,248 @@
+package test
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// EnvGroupRepository will return errors on queries if canQuery is false
+// and only stores a small set of env groups, versions and linked releases
+// in-memory that are indexed by their array index + 1
+type EnvGroupRepository struct {
+	canQuery bool
+	groups   []*models.EnvGroup
+	versions []*models.EnvGroupVersion
+	releases []*models.EnvGroupRelease
+}
+
+// NewEnvGroupRepository will return errors if canQuery is false
+func NewEnvGroupRepository(canQuery bool) repository.EnvGroupRepository {
+	return &EnvGroupRepository{
+		canQuery,
+		[]*models.EnvGroup{},
+		[]*models.EnvGroupVersion{},
+		[]*models.EnvGroupRelease{},
+	}
+}
+
+// CreateEnvGroup appends a new env group to the in-memory array
+func (repo *EnvGroupRepository) CreateEnvGroup(
+	group *models.EnvGroup,
+) (*models.EnvGroup, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	if _, err := repo.ReadEnvGroup(group.ClusterID, group.Namespace, group.Name); err == nil {
+		return nil, errors.New("Env group already exists")
+	}
+
+	repo.groups = append(repo.groups, group)
+	group.ID = uint(len(repo.groups))
+
+	for _, rel := range group.Releases {
+		rel.EnvGroupID = group.ID
+
+		if _, err := repo.AddEnvGroupRelease(&rel); err != nil {
+			return nil, err
+		}
+	}
+
+	return group, nil
+}
+
+// ReadEnvGroup finds an env group by cluster id, namespace and name, along with
+// its linked releases
+func (repo *EnvGroupRepository) ReadEnvGroup(
+	clusterID uint,
+	namespace, name string,
+) (*models.EnvGroup, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	for _, group := range repo.groups {
+		if group != nil && group.ClusterID == clusterID && group.Namespace == namespace && group.Name == name {
+			group.Releases = repo.getReleases(group.ID)
+			return group, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+// ListEnvGroups finds all env groups in a namespace of a cluster, ordered by name
+func (repo *EnvGroupRepository) ListEnvGroups(
+	clusterID uint,
+	namespace string,
+) ([]*models.EnvGroup, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	res := make([]*models.EnvGroup, 0)
+
+	for _, group := range repo.groups {
+		if group != nil && group.ClusterID == clusterID && group.Namespace == namespace {
+			group.Releases = repo.getReleases(group.ID)
+			res = append(res, group)
+		}
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Name < res[j].Name
+	})
+
+	return res, nil
+}
+
+// UpdateEnvGroup modifies an existing env group in memory
+func (repo *EnvGroupRepository) UpdateEnvGroup(
+	group *models.EnvGroup,
+) (*models.EnvGroup, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	if int(group.ID-1) >= len(repo.groups) || repo.groups[group.ID-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(group.ID - 1)
+	repo.groups[index] = group
+
+	return group, nil
+}
+
+// DeleteEnvGroup removes an env group along with its versions and linked releases
+// from memory
+func (repo *EnvGroupRepository) DeleteEnvGroup(
+	group *models.EnvGroup,
+) error {
+	if !repo.canQuery {
+		return errors.New("Cannot write database")
+	}
+
+	if int(group.ID-1) >= len(repo.groups) || repo.groups[group.ID-1] == nil {
+		return gorm.ErrRecordNotFound
+	}
+
+	index := int(group.ID - 1)
+	repo.groups[index] = nil
+
+	for i, version := range repo.versions {
+		if version != nil && version.EnvGroupID == group.ID {
+			repo.versions[i] = nil
+		}
+	}
+
+	for i, rel := range repo.releases {
+		if rel != nil && rel.EnvGroupID == group.ID {
+			repo.releases[i] = nil
+		}
+	}
+
+	return nil
+}
+
+// CreateEnvGroupVersion appends a new env group version to the in-memory array
+func (repo *EnvGroupRepository) CreateEnvGroupVersion(
+	version *models.EnvGroupVersion,
+) (*models.EnvGroupVersion, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	repo.versions = append(repo.versions, version)
+	version.ID = uint(len(repo.versions))
+
+	return version, nil
+}
+
+// ReadEnvGroupVersion finds a version of an env group by its version number
+func (repo *EnvGroupRepository) ReadEnvGroupVersion(
+	groupID, version uint,
+) (*models.EnvGroupVersion, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	for _, v := range repo.versions {
+		if v != nil && v.EnvGroupID == groupID && v.Version == version {
+			return v, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+// ListEnvGroupVersions finds all versions of an env group, newest first
+func (repo *EnvGroupRepository) ListEnvGroupVersions(
+	groupID uint,
+) ([]*models.EnvGroupVersion, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	res := make([]*models.EnvGroupVersion, 0)
+
+	for _, v := range repo.versions {
+		if v != nil && v.EnvGroupID == groupID {
+			res = append(res, v)
+		}
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].Version > res[j].Version
+	})
+
+	return res, nil
+}
+
+// AddEnvGroupRelease links a release to an env group in memory
+func (repo *EnvGroupRepository) AddEnvGroupRelease(
+	rel *models.EnvGroupRelease,
+) (*models.EnvGroupRelease, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	repo.releases = append(repo.releases, rel)
+	rel.ID = uint(len(repo.releases))
+
+	return rel, nil
+}
+
+// RemoveEnvGroupRelease unlinks a release from an env group in memory
+func (repo *EnvGroupRepository) RemoveEnvGroupRelease(
+	groupID uint,
+	releaseName string,
+) error {
+	if !repo.canQuery {
+		return errors.New("Cannot write database")
+	}
+
+	for i, rel := range repo.releases {
+		if rel != nil && rel.EnvGroupID == groupID && rel.ReleaseName == releaseName {
+			repo.releases[i] = nil
+		}
+	}
+
+	return nil
+}
+
+func (repo *EnvGroupRepository) getReleases(groupID uint) []models.EnvGroupRelease {
+	res := make([]models.EnvGroupRelease, 0)
+
+	for _, rel := range repo.releases {
+		if rel != nil && rel.EnvGroupID == groupID {
+			res = append(res, *rel)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,131 @@
+package test
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// HelmReleaseRepository will return errors on queries if canQuery is false
+// and only stores a small set of Helm release records in-memory that are indexed
+// by their array index + 1
+type HelmReleaseRepository struct {
+	canQuery bool
+	rels     []*models.HelmRelease
+}
+
+// NewHelmReleaseRepository will return errors if canQuery is false
+func NewHelmReleaseRepository(canQuery bool) repository.HelmReleaseRepository {
+	return &HelmReleaseRepository{canQuery, []*models.HelmRelease{}}
+}
+
+// CreateHelmRelease appends a new Helm release record to the in-memory array
+func (repo *HelmReleaseRepository) CreateHelmRelease(
+	rel *models.HelmRelease,
+) (*models.HelmRelease, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	if _, err := repo.ReadHelmRelease(rel.ClusterID, rel.Namespace, rel.Key); err == nil {
+		return nil, errors.New("Helm release key already exists")
+	}
+
+	repo.rels = append(repo.rels, rel)
+	rel.ID = uint(len(repo.rels))
+
+	return rel, nil
+}
+
+// ReadHelmRelease finds a Helm release record by cluster id, namespace and key
+func (repo *HelmReleaseRepository) ReadHelmRelease(
+	clusterID uint,
+	namespace, key string,
+) (*models.HelmRelease, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	for _, rel := range repo.rels {
+		if rel != nil && rel.ClusterID == clusterID && rel.Namespace == namespace && rel.Key == key {
+			return rel, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+// ListHelmReleases finds the Helm release records that match the filter, ordered
+// by name and version
+func (repo *HelmReleaseRepository) ListHelmReleases(
+	filter *repository.HelmReleaseFilter,
+) ([]*models.HelmRelease, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	res := make([]*models.HelmRelease, 0)
+
+	for _, rel := range repo.rels {
+		if rel == nil ||
+			(filter.ClusterID != 0 && rel.ClusterID != filter.ClusterID) ||
+			(filter.Namespace != "" && rel.Namespace != filter.Namespace) ||
+			(filter.Name != "" && rel.Name != filter.Name) ||
+			(filter.Status != "" && rel.Status != filter.Status) ||
+			(filter.Owner != "" && rel.Owner != filter.Owner) ||
+			(filter.Version != 0 && rel.Version != filter.Version) {
+			continue
+		}
+
+		res = append(res, rel)
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		if res[i].Name != res[j].Name {
+			return res[i].Name < res[j].Name
+		}
+
+		return res[i].Version < res[j].Version
+	})
+
+	return res, nil
+}
+
+// UpdateHelmRelease modifies an existing Helm release record in memory
+func (repo *HelmReleaseRepository) UpdateHelmRelease(
+	rel *models.HelmRelease,
+) (*models.HelmRelease, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	if int(rel.ID-1) >= len(repo.rels) || repo.rels[rel.ID-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(rel.ID - 1)
+	repo.rels[index] = rel
+
+	return rel, nil
+}
+
+// DeleteHelmRelease removes a Helm release record from memory
+func (repo *HelmReleaseRepository) DeleteHelmRelease(
+	rel *models.HelmRelease,
+) error {
+	if !repo.canQuery {
+		return errors.New("Cannot write database")
+	}
+
+	if int(rel.ID-1) >= len(repo.rels) || repo.rels[rel.ID-1] == nil {
+		return gorm.ErrRecordNotFound
+	}
+
+	index := int(rel.ID - 1)
+	repo.rels[index] = nil
+
+	return nil
+}
@@ -0,0 +1,102 @@
+package test
+
+import (
+	"errors"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// PolicyRepository will return errors on queries if canQuery is false
+// and only stores a small set of policies in-memory that are indexed by their
+// array index + 1
+type PolicyRepository struct {
+	canQuery bool
+	policies []*models.Policy
+}
+
+// NewPolicyRepository will return errors if canQuery is false
+func NewPolicyRepository(canQuery bool) repository.PolicyRepository {
+	return &PolicyRepository{canQuery, []*models.Policy{}}
+}
+
+// CreatePolicy appends a new policy to the in-memory policies array
+func (repo *PolicyRepository) CreatePolicy(policy *models.Policy) (*models.Policy, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	repo.policies = append(repo.policies, policy)
+	policy.ID = uint(len(repo.policies))
+
+	return policy, nil
+}
+
+// ReadPolicy gets a policy specified by a project id and a unique id
+func (repo *PolicyRepository) ReadPolicy(projID, policyID uint) (*models.Policy, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	if int(policyID-1) >= len(repo.policies) || repo.policies[policyID-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(policyID - 1)
+
+	if repo.policies[index].ProjectID != projID {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return repo.policies[index], nil
+}
+
+// ListPoliciesByProjectID finds all policies for a given project id
+func (repo *PolicyRepository) ListPoliciesByProjectID(projID uint) ([]*models.Policy, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	res := make([]*models.Policy, 0)
+
+	for _, policy := range repo.policies {
+		if policy != nil && policy.ProjectID == projID {
+			res = append(res, policy)
+		}
+	}
+
+	return res, nil
+}
+
+// UpdatePolicy modifies an existing Policy in the database
+func (repo *PolicyRepository) UpdatePolicy(policy *models.Policy) (*models.Policy, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	if int(policy.ID-1) >= len(repo.policies) || repo.policies[policy.ID-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(policy.ID - 1)
+	repo.policies[index] = policy
+
+	return policy, nil
+}
+
+// DeletePolicy removes a policy from the array by setting it to nil
+func (repo *PolicyRepository) DeletePolicy(policy *models.Policy) (*models.Policy, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	if int(policy.ID-1) >= len(repo.policies) || repo.policies[policy.ID-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(policy.ID - 1)
+	repo.policies[index] = nil
+
+	return policy, nil
+}
@@ -0,0 +1,217 @@
+package test
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// PreviewEnvironmentRepository will return errors on queries if canQuery is false
+// and only stores a small set of preview environment configs and environments
+// in-memory that are indexed by their array index + 1
+type PreviewEnvironmentRepository struct {
+	canQuery bool
+	confs    []*models.PreviewEnvironmentConfig
+	envs     []*models.PreviewEnvironment
+}
+
+// NewPreviewEnvironmentRepository will return errors if canQuery is false
+func NewPreviewEnvironmentRepository(canQuery bool) repository.PreviewEnvironmentRepository {
+	return &PreviewEnvironmentRepository{
+		canQuery,
+		[]*models.PreviewEnvironmentConfig{},
+		[]*models.PreviewEnvironment{},
+	}
+}
+
+// CreatePreviewEnvironmentConfig appends a new preview environment config to the
+// in-memory array
+func (repo *PreviewEnvironmentRepository) CreatePreviewEnvironmentConfig(
+	conf *models.PreviewEnvironmentConfig,
+) (*models.PreviewEnvironmentConfig, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	if _, err := repo.ReadPreviewEnvironmentConfigByReleaseID(conf.ReleaseID); err == nil {
+		return nil, errors.New("Preview environment config already exists")
+	}
+
+	repo.confs = append(repo.confs, conf)
+	conf.ID = uint(len(repo.confs))
+
+	return conf, nil
+}
+
+// ReadPreviewEnvironmentConfig finds a preview environment config by id
+func (repo *PreviewEnvironmentRepository) ReadPreviewEnvironmentConfig(
+	id uint,
+) (*models.PreviewEnvironmentConfig, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	if id == 0 || int(id-1) >= len(repo.confs) || repo.confs[id-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return repo.confs[id-1], nil
+}
+
+// ReadPreviewEnvironmentConfigByReleaseID finds the preview environment config of
+// a release
+func (repo *PreviewEnvironmentRepository) ReadPreviewEnvironmentConfigByReleaseID(
+	releaseID uint,
+) (*models.PreviewEnvironmentConfig, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	for _, conf := range repo.confs {
+		if conf != nil && conf.ReleaseID == releaseID {
+			return conf, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+// ListPreviewEnvironmentConfigsByGitRepo finds all preview environment configs of
+// releases built from a git repo
+func (repo *PreviewEnvironmentRepository) ListPreviewEnvironmentConfigsByGitRepo(
+	gitRepo string,
+) ([]*models.PreviewEnvironmentConfig, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	res := make([]*models.PreviewEnvironmentConfig, 0)
+
+	for _, conf := range repo.confs {
+		if conf != nil && conf.GitRepo == gitRepo {
+			res = append(res, conf)
+		}
+	}
+
+	return res, nil
+}
+
+// DeletePreviewEnvironmentConfig removes a preview environment config along with
+// its preview environments from memory
+func (repo *PreviewEnvironmentRepository) DeletePreviewEnvironmentConfig(
+	conf *models.PreviewEnvironmentConfig,
+) error {
+	if !repo.canQuery {
+		return errors.New("Cannot write database")
+	}
+
+	if int(conf.ID-1) >= len(repo.confs) || repo.confs[conf.ID-1] == nil {
+		return gorm.ErrRecordNotFound
+	}
+
+	index := int(conf.ID - 1)
+	repo.confs[index] = nil
+
+	for i, env := range repo.envs {
+		if env != nil && env.PreviewEnvironmentConfigID == conf.ID {
+			repo.envs[i] = nil
+		}
+	}
+
+	return nil
+}
+
+// CreatePreviewEnvironment appends a new preview environment to the in-memory array
+func (repo *PreviewEnvironmentRepository) CreatePreviewEnvironment(
+	env *models.PreviewEnvironment,
+) (*models.PreviewEnvironment, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	repo.envs = append(repo.envs, env)
+	env.ID = uint(len(repo.envs))
+
+	return env, nil
+}
+
+// ReadPreviewEnvironment finds the preview environment of a pull request
+func (repo *PreviewEnvironmentRepository) ReadPreviewEnvironment(
+	configID uint,
+	prNumber int,
+) (*models.PreviewEnvironment, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	for _, env := range repo.envs {
+		if env != nil && env.PreviewEnvironmentConfigID == configID && env.PullRequestNumber == prNumber {
+			return env, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+// ReadPreviewEnvironmentByNamespace finds the preview environment that is deployed
+// to a namespace
+func (repo *PreviewEnvironmentRepository) ReadPreviewEnvironmentByNamespace(
+	namespace string,
+) (*models.PreviewEnvironment, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	for _, env := range repo.envs {
+		if env != nil && env.Namespace == namespace {
+			return env, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+// ListPreviewEnvironments finds all preview environments of a config, ordered by
+// pull request number
+func (repo *PreviewEnvironmentRepository) ListPreviewEnvironments(
+	configID uint,
+) ([]*models.PreviewEnvironment, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	res := make([]*models.PreviewEnvironment, 0)
+
+	for _, env := range repo.envs {
+		if env != nil && env.PreviewEnvironmentConfigID == configID {
+			res = append(res, env)
+		}
+	}
+
+	sort.SliceStable(res, func(i, j int) bool {
+		return res[i].PullRequestNumber < res[j].PullRequestNumber
+	})
+
+	return res, nil
+}
+
+// UpdatePreviewEnvironment modifies an existing preview environment in memory
+func (repo *PreviewEnvironmentRepository) UpdatePreviewEnvironment(
+	env *models.PreviewEnvironment,
+) (*models.PreviewEnvironment, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	if int(env.ID-1) >= len(repo.envs) || repo.envs[env.ID-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(env.ID - 1)
+	repo.envs[index] = env
+
+	return env, nil
+}
@@ -27,5 +27,16 @@ func NewRepository(canQuery bool) *repository.Repository {
 		AWSIntegration:            NewAWSIntegrationRepository(canQuery),
 		GithubAppInstallation:     NewGithubAppInstallationRepository(canQuery),
 		GithubAppOAuthIntegration: NewGithubAppOAuthIntegrationRepository(canQuery),
+		Policy:                    NewPolicyRepository(canQuery),
+		APIToken:                  NewAPITokenRepository(canQuery),
+		AuditEvent:                NewAuditEventRepository(canQuery),
+		Deployment:                NewDeploymentRepository(canQuery),
+		DeployWebhookToken:        NewDeployWebhookTokenRepository(canQuery),
+		HelmRelease:               NewHelmReleaseRepository(canQuery),
+		EnvGroup:                  NewEnvGroupRepository(canQuery),
+		PreviewEnvironment:        NewPreviewEnvironmentRepository(canQuery),
+		Rollout:                   NewRolloutRepository(canQuery),
+		RetentionPolicy:           NewRetentionPolicyRepository(canQuery),
+		WebhookIntegration:        NewWebhookIntegrationRepository(canQuery),
 	}
 }
@@ -0,0 +1,167 @@
+package test
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// RetentionPolicyRepository will return errors on queries if canQuery is false and
+// only stores a small set of retention policies in-memory that are indexed by their
+// array index + 1
+type RetentionPolicyRepository struct {
+	canQuery bool
+	mu       sync.Mutex
+	policies []*models.RetentionPolicy
+}
+
+// NewRetentionPolicyRepository will return errors if canQuery is false
+func NewRetentionPolicyRepository(canQuery bool) repository.RetentionPolicyRepository {
+	return &RetentionPolicyRepository{canQuery: canQuery, policies: []*models.RetentionPolicy{}}
+}
+
+// CreateRetentionPolicy appends a new retention policy to the in-memory array
+func (repo *RetentionPolicyRepository) CreateRetentionPolicy(
+	policy *models.RetentionPolicy,
+) (*models.RetentionPolicy, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	// policies are updated from the background job
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.policies = append(repo.policies, policy)
+	policy.ID = uint(len(repo.policies))
+
+	return policy, nil
+}
+
+// ReadRetentionPolicy finds a retention policy by registry id and id
+func (repo *RetentionPolicyRepository) ReadRetentionPolicy(
+	registryID, id uint,
+) (*models.RetentionPolicy, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if id == 0 || int(id-1) >= len(repo.policies) || repo.policies[id-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	if repo.policies[id-1].RegistryID != registryID {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return repo.policies[id-1], nil
+}
+
+// ReadRetentionPolicyByRepository finds the retention policy of a repository in a
+// registry
+func (repo *RetentionPolicyRepository) ReadRetentionPolicyByRepository(
+	registryID uint,
+	repoName string,
+) (*models.RetentionPolicy, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	for _, policy := range repo.policies {
+		if policy != nil && policy.RegistryID == registryID && policy.RepositoryName == repoName {
+			return policy, nil
+		}
+	}
+
+	return nil, gorm.ErrRecordNotFound
+}
+
+// ListRetentionPoliciesByRegistryID finds all retention policies of a registry
+func (repo *RetentionPolicyRepository) ListRetentionPoliciesByRegistryID(
+	registryID uint,
+) ([]*models.RetentionPolicy, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	res := make([]*models.RetentionPolicy, 0)
+
+	for _, policy := range repo.policies {
+		if policy != nil && policy.RegistryID == registryID {
+			res = append(res, policy)
+		}
+	}
+
+	return res, nil
+}
+
+// ListEnabledRetentionPolicies finds the retention policies of all registries that
+// are applied by the background job
+func (repo *RetentionPolicyRepository) ListEnabledRetentionPolicies() ([]*models.RetentionPolicy, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	res := make([]*models.RetentionPolicy, 0)
+
+	for _, policy := range repo.policies {
+		if policy != nil && policy.Enabled {
+			res = append(res, policy)
+		}
+	}
+
+	return res, nil
+}
+
+// UpdateRetentionPolicy modifies an existing retention policy in memory
+func (repo *RetentionPolicyRepository) UpdateRetentionPolicy(
+	policy *models.RetentionPolicy,
+) (*models.RetentionPolicy, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if policy.ID == 0 || int(policy.ID-1) >= len(repo.policies) || repo.policies[policy.ID-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	repo.policies[policy.ID-1] = policy
+
+	return policy, nil
+}
+
+// DeleteRetentionPolicy removes a retention policy by setting it to nil
+func (repo *RetentionPolicyRepository) DeleteRetentionPolicy(id uint) error {
+	if !repo.canQuery {
+		return errors.New("Cannot write database")
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if id == 0 || int(id-1) >= len(repo.policies) || repo.policies[id-1] == nil {
+		return gorm.ErrRecordNotFound
+	}
+
+	repo.policies[id-1] = nil
+
+	return nil
+}
@@ -0,0 +1,124 @@
+package test
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// RolloutRepository will return errors on queries if canQuery is false and only
+// stores a small set of rollouts in-memory that are indexed by their array index + 1
+type RolloutRepository struct {
+	canQuery bool
+	mu       sync.Mutex
+	rollouts []*models.Rollout
+}
+
+// NewRolloutRepository will return errors if canQuery is false
+func NewRolloutRepository(canQuery bool) repository.RolloutRepository {
+	return &RolloutRepository{canQuery: canQuery, rollouts: []*models.Rollout{}}
+}
+
+// CreateRollout appends a new rollout to the in-memory rollouts array
+func (repo *RolloutRepository) CreateRollout(rollout *models.Rollout) (*models.Rollout, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	// rollouts are updated from a background goroutine
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if rollout.CreatedAt.IsZero() {
+		rollout.CreatedAt = time.Now()
+	}
+
+	repo.rollouts = append(repo.rollouts, rollout)
+	rollout.ID = uint(len(repo.rollouts))
+
+	return rollout, nil
+}
+
+// ReadRollout finds a rollout by id
+func (repo *RolloutRepository) ReadRollout(id uint) (*models.Rollout, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if id == 0 || int(id-1) >= len(repo.rollouts) || repo.rollouts[id-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return repo.rollouts[id-1], nil
+}
+
+// ListRolloutsByRelease finds the rollouts of a release, ordered from newest to
+// oldest
+func (repo *RolloutRepository) ListRolloutsByRelease(
+	clusterID uint,
+	namespace, name string,
+) ([]*models.Rollout, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	res := make([]*models.Rollout, 0)
+
+	for i := len(repo.rollouts) - 1; i >= 0; i-- {
+		rollout := repo.rollouts[i]
+
+		if rollout.ClusterID == clusterID && rollout.Namespace == namespace && rollout.ReleaseName == name {
+			res = append(res, rollout)
+		}
+	}
+
+	return res, nil
+}
+
+// ListRolloutsByStatus finds the rollouts of every project with a status
+func (repo *RolloutRepository) ListRolloutsByStatus(status string) ([]*models.Rollout, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	res := make([]*models.Rollout, 0)
+
+	for _, rollout := range repo.rollouts {
+		if rollout.Status == status {
+			res = append(res, rollout)
+		}
+	}
+
+	return res, nil
+}
+
+// UpdateRollout modifies an existing rollout in memory
+func (repo *RolloutRepository) UpdateRollout(rollout *models.Rollout) (*models.Rollout, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	if rollout.ID == 0 || int(rollout.ID-1) >= len(repo.rollouts) || repo.rollouts[rollout.ID-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	repo.rollouts[rollout.ID-1] = rollout
+
+	return rollout, nil
+}
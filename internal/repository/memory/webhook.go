@@ -0,0 +1,113 @@
+package test
+
+import (
+	"errors"
+
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+
+	ints "github.com/porter-dev/porter/internal/models/integrations"
+)
+
+// WebhookIntegrationRepository will return errors on queries if canQuery is false
+// and only stores a small set of webhook integrations in-memory that are indexed by
+// their array index + 1
+type WebhookIntegrationRepository struct {
+	canQuery    bool
+	webhookInts []*ints.WebhookIntegration
+}
+
+// NewWebhookIntegrationRepository will return errors if canQuery is false
+func NewWebhookIntegrationRepository(canQuery bool) repository.WebhookIntegrationRepository {
+	return &WebhookIntegrationRepository{canQuery, []*ints.WebhookIntegration{}}
+}
+
+// CreateWebhookIntegration appends a new webhook integration to the in-memory array
+func (repo *WebhookIntegrationRepository) CreateWebhookIntegration(
+	webhookInt *ints.WebhookIntegration,
+) (*ints.WebhookIntegration, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	repo.webhookInts = append(repo.webhookInts, webhookInt)
+	webhookInt.ID = uint(len(repo.webhookInts))
+
+	return webhookInt, nil
+}
+
+// ReadWebhookIntegration finds a webhook integration by project id and id
+func (repo *WebhookIntegrationRepository) ReadWebhookIntegration(
+	projectID, integrationID uint,
+) (*ints.WebhookIntegration, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	if int(integrationID-1) >= len(repo.webhookInts) || repo.webhookInts[integrationID-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(integrationID - 1)
+
+	if repo.webhookInts[index].ProjectID != projectID {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return repo.webhookInts[index], nil
+}
+
+// ListWebhookIntegrationsByProjectID finds all webhook integrations
+// for a given project id
+func (repo *WebhookIntegrationRepository) ListWebhookIntegrationsByProjectID(
+	projectID uint,
+) ([]*ints.WebhookIntegration, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot read from database")
+	}
+
+	res := make([]*ints.WebhookIntegration, 0)
+
+	for _, webhookInt := range repo.webhookInts {
+		if webhookInt != nil && webhookInt.ProjectID == projectID {
+			res = append(res, webhookInt)
+		}
+	}
+
+	return res, nil
+}
+
+// UpdateWebhookIntegration modifies an existing webhook integration in memory
+func (repo *WebhookIntegrationRepository) UpdateWebhookIntegration(
+	webhookInt *ints.WebhookIntegration,
+) (*ints.WebhookIntegration, error) {
+	if !repo.canQuery {
+		return nil, errors.New("Cannot write database")
+	}
+
+	if int(webhookInt.ID-1) >= len(repo.webhookInts) || repo.webhookInts[webhookInt.ID-1] == nil {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	index := int(webhookInt.ID - 1)
+	repo.webhookInts[index] = webhookInt
+
+	return webhookInt, nil
+}
+
+// DeleteWebhookIntegration removes a webhook integration by setting it to nil
+func (repo *WebhookIntegrationRepository) DeleteWebhookIntegration(
+	integrationID uint,
+) error {
+	if !repo.canQuery {
+		return errors.New("Cannot write database")
+	}
+
+	if int(integrationID-1) >= len(repo.webhookInts) || repo.webhookInts[integrationID-1] == nil {
+		return gorm.ErrRecordNotFound
+	}
+
+	repo.webhookInts[integrationID-1] = nil
+
+	return nil
+}
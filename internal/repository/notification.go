@@ -8,4 +8,5 @@ type NotificationConfigRepository interface {
 	CreateNotificationConfig(am *models.NotificationConfig) (*models.NotificationConfig, error)
 	ReadNotificationConfig(id uint) (*models.NotificationConfig, error)
 	UpdateNotificationConfig(am *models.NotificationConfig) (*models.NotificationConfig, error)
+	UpdateNotificationConfigFailures(id uint, failed bool) (*models.NotificationConfig, error)
 }
@@ -0,0 +1,21 @@
+package repository
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// PreviewEnvironmentRepository represents the set of queries on the
+// PreviewEnvironmentConfig and PreviewEnvironment models
+type PreviewEnvironmentRepository interface {
+	CreatePreviewEnvironmentConfig(conf *models.PreviewEnvironmentConfig) (*models.PreviewEnvironmentConfig, error)
+	ReadPreviewEnvironmentConfig(id uint) (*models.PreviewEnvironmentConfig, error)
+	ReadPreviewEnvironmentConfigByReleaseID(releaseID uint) (*models.PreviewEnvironmentConfig, error)
+	ListPreviewEnvironmentConfigsByGitRepo(gitRepo string) ([]*models.PreviewEnvironmentConfig, error)
+	DeletePreviewEnvironmentConfig(conf *models.PreviewEnvironmentConfig) error
+
+	CreatePreviewEnvironment(env *models.PreviewEnvironment) (*models.PreviewEnvironment, error)
+	ReadPreviewEnvironment(configID uint, prNumber int) (*models.PreviewEnvironment, error)
+	ReadPreviewEnvironmentByNamespace(namespace string) (*models.PreviewEnvironment, error)
+	ListPreviewEnvironments(configID uint) ([]*models.PreviewEnvironment, error)
+	UpdatePreviewEnvironment(env *models.PreviewEnvironment) (*models.PreviewEnvironment, error)
+}
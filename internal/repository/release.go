@@ -11,6 +11,7 @@ type WriteRelease func(release *models.Release) (*models.Release, error)
 type ReleaseRepository interface {
 	CreateRelease(release *models.Release) (*models.Release, error)
 	ReadRelease(clusterID uint, name, namespace string) (*models.Release, error)
+	ReadReleaseByID(id uint) (*models.Release, error)
 	ReadReleaseByWebhookToken(token string) (*models.Release, error)
 	ListReleasesByImageRepoURI(clusterID uint, imageRepoURI string) ([]*models.Release, error)
 	UpdateRelease(release *models.Release) (*models.Release, error)
@@ -25,6 +25,17 @@ type Repository struct {
 	GithubAppInstallation     GithubAppInstallationRepository
 	GithubAppOAuthIntegration GithubAppOAuthIntegrationRepository
 	SlackIntegration          SlackIntegrationRepository
+	WebhookIntegration        WebhookIntegrationRepository
 	NotificationConfig        NotificationConfigRepository
 	Event                     EventRepository
+	Policy                    PolicyRepository
+	APIToken                  APITokenRepository
+	AuditEvent                AuditEventRepository
+	Deployment                DeploymentRepository
+	DeployWebhookToken        DeployWebhookTokenRepository
+	HelmRelease               HelmReleaseRepository
+	EnvGroup                  EnvGroupRepository
+	PreviewEnvironment        PreviewEnvironmentRepository
+	Rollout                   RolloutRepository
+	RetentionPolicy           RetentionPolicyRepository
 }
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// RetentionPolicyRepository represents the set of queries on the RetentionPolicy
+// model
+type RetentionPolicyRepository interface {
+	CreateRetentionPolicy(policy *models.RetentionPolicy) (*models.RetentionPolicy, error)
+	ReadRetentionPolicy(registryID, id uint) (*models.RetentionPolicy, error)
+	ReadRetentionPolicyByRepository(registryID uint, repoName string) (*models.RetentionPolicy, error)
+	ListRetentionPoliciesByRegistryID(registryID uint) ([]*models.RetentionPolicy, error)
+	ListEnabledRetentionPolicies() ([]*models.RetentionPolicy, error)
+	UpdateRetentionPolicy(policy *models.RetentionPolicy) (*models.RetentionPolicy, error)
+	DeleteRetentionPolicy(id uint) error
+}
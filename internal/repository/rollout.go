@@ -0,0 +1,14 @@
+package repository
+
+import (
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// RolloutRepository represents the set of queries on the Rollout model
+type RolloutRepository interface {
+	CreateRollout(rollout *models.Rollout) (*models.Rollout, error)
+	ReadRollout(id uint) (*models.Rollout, error)
+	ListRolloutsByRelease(clusterID uint, namespace, name string) ([]*models.Rollout, error)
+	ListRolloutsByStatus(status string) ([]*models.Rollout, error)
+	UpdateRollout(rollout *models.Rollout) (*models.Rollout, error)
+}
@@ -5,14 +5,18 @@ import (
 	"net/http"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/go-playground/locales/en"
 	ut "github.com/go-playground/universal-translator"
 	vr "github.com/go-playground/validator/v10"
 	"github.com/porter-dev/porter/internal/auth/sessionstore"
 	"github.com/porter-dev/porter/internal/auth/token"
+	"github.com/porter-dev/porter/internal/integrations/email"
+	"github.com/porter-dev/porter/internal/integrations/webhook"
 	"github.com/porter-dev/porter/internal/kubernetes/local"
 	"github.com/porter-dev/porter/internal/oauth"
+	"github.com/porter-dev/porter/internal/registry"
 	"golang.org/x/oauth2"
 	"gorm.io/gorm"
 
@@ -74,6 +78,9 @@ type App struct {
 	ProvisionerAgent *kubernetes.Agent
 	IngressAgent     *kubernetes.Agent
 
+	// AgentPool caches the connection to each cluster across requests
+	AgentPool *kubernetes.AgentPool
+
 	// redis client for redis connection
 	RedisConf *config.RedisConf
 
@@ -99,6 +106,20 @@ type App struct {
 	// analytics client for reporting
 	AnalyticsClient analytics.AnalyticsSegmentClient
 
+	// EmailTransport sends notification emails, and is nil if neither SendGrid nor
+	// SMTP is configured
+	EmailTransport email.Transport
+
+	// LocalScanner scans the images of registries that do not scan images
+	// themselves, and is nil if no local scanner is configured
+	LocalScanner registry.LocalScanner
+
+	// WebhookQueue delivers outbound webhook notifications in the background
+	WebhookQueue *webhook.Queue
+
+	// rollouts are the rollouts that are run by this server, by rollout id
+	rollouts sync.Map
+
 	db         *gorm.DB
 	validator  *vr.Validate
 	translator *ut.Translator
@@ -114,6 +135,7 @@ type AppCapabilities struct {
 	GoogleLogin        bool   `json:"google_login"`
 	SlackNotifications bool   `json:"slack_notifs"`
 	Email              bool   `json:"email"`
+	EmailNotifications bool   `json:"email_notifs"`
 	Analytics          bool   `json:"analytics"`
 }
 
@@ -137,6 +159,9 @@ func New(conf *AppConfig) (*App, error) {
 		RedisConf:  conf.RedisConf,
 		DBConf:     conf.DBConf,
 		TestAgents: conf.TestAgents,
+		AgentPool:  kubernetes.NewAgentPool(conf.ServerConf.AgentPoolTTL),
+		// up to 100 webhook deliveries are queued before new deliveries are dropped
+		WebhookQueue: webhook.NewQueue(100),
 		Capabilities: &AppCapabilities{
 			Version: conf.Version,
 		},
@@ -240,6 +265,31 @@ func New(conf *AppConfig) (*App, error) {
 	}
 
 	app.Capabilities.Email = sc.SendgridAPIKey != ""
+
+	if sc.SendgridAPIKey != "" {
+		app.EmailTransport = &email.SendgridTransport{
+			APIKey:      sc.SendgridAPIKey,
+			SenderEmail: sc.SendgridSenderEmail,
+		}
+	} else if sc.SMTPHost != "" {
+		app.EmailTransport = &email.SMTPTransport{
+			Host:        sc.SMTPHost,
+			Port:        sc.SMTPPort,
+			Username:    sc.SMTPUsername,
+			Password:    sc.SMTPPassword,
+			SenderEmail: sc.SMTPSenderEmail,
+		}
+	}
+
+	app.Capabilities.EmailNotifications = app.EmailTransport != nil
+
+	if sc.LocalScanner != "" {
+		app.LocalScanner, err = registry.NewLocalScanner(sc.LocalScanner, sc.LocalScannerPath)
+
+		if err != nil {
+			return nil, err
+		}
+	}
 	app.Capabilities.Analytics = sc.SegmentClientKey != ""
 	app.Capabilities.BasicLogin = sc.BasicLoginEnabled
 
@@ -328,6 +378,15 @@ func (app *App) getTokenFromRequest(r *http.Request) *token.Token {
 		return nil
 	}
 
+	// tokens which are persisted as API tokens must not be revoked or expired
+	if tok.TokenID != "" {
+		apiToken, err := app.Repo.APIToken.ReadAPITokenByUniqueID(tok.TokenID)
+
+		if err != nil || !apiToken.IsValid() || apiToken.ProjectID != tok.ProjectID {
+			return nil
+		}
+	}
+
 	return tok
 }
 
@@ -0,0 +1,171 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/auth/token"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// HandleCreateAPIToken creates a new API token for a project. The encoded token is
+// only returned in this response, and cannot be read again.
+func (app *App) HandleCreateAPIToken(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	userID, err := app.getUserIDFromRequest(r)
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	form := &forms.CreateAPITokenForm{
+		ProjectID: uint(projID),
+		UserID:    userID,
+	}
+
+	// decode from JSON to form value
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	// validate the form
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrProjectValidateFields, w)
+		return
+	}
+
+	if err := app.validateRolePolicy(uint(projID), form.Kind, form.PolicyID); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	apiToken, err := form.ToAPIToken()
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrProjectValidateFields,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return
+	}
+
+	apiToken, err = app.Repo.APIToken.CreateAPIToken(apiToken)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	jwt, err := token.GetTokenForAPI(userID, uint(projID))
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	jwt.TokenID = apiToken.UniqueID
+	jwt.Expiry = apiToken.Expiry
+
+	encoded, err := jwt.EncodeToken(app.tokenConf)
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	app.Logger.Info().Msgf("New API token created: %d", apiToken.ID)
+
+	w.WriteHeader(http.StatusCreated)
+
+	res := apiToken.Externalize()
+	res.Token = encoded
+
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleListAPITokens lists the API tokens in a project, including tokens that
+// have been revoked or have expired
+func (app *App) HandleListAPITokens(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	apiTokens, err := app.Repo.APIToken.ListAPITokensByProjectID(uint(projID))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return
+	}
+
+	res := make([]*models.APITokenExternal, 0)
+
+	for _, apiToken := range apiTokens {
+		res = append(res, apiToken.Externalize())
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleRevokeAPIToken revokes an API token. Revoked tokens are kept so that they
+// are still listed, but are rejected by the auth middleware.
+func (app *App) HandleRevokeAPIToken(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	tokenID, err := strconv.ParseUint(chi.URLParam(r, "api_token_id"), 0, 64)
+
+	if err != nil || tokenID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	apiToken, err := app.Repo.APIToken.ReadAPIToken(uint(projID), uint(tokenID))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return
+	}
+
+	apiToken.Revoked = true
+
+	apiToken, err = app.Repo.APIToken.UpdateAPIToken(apiToken)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(apiToken.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
@@ -0,0 +1,137 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/auth/token"
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// ------------------------- TEST FIXTURES AND FUNCTIONS  ------------------------- //
+
+var createAPITokenTests = []*projTest{
+	&projTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+		},
+		msg:       "Create API token",
+		method:    "POST",
+		endpoint:  "/api/projects/1/api_tokens",
+		body:      `{"name":"ci","kind":"developer","ttl":"24h"}`,
+		expStatus: http.StatusCreated,
+		useCookie: true,
+		validators: []func(c *projTest, tester *tester, t *testing.T){
+			apiTokenCreatedValidator,
+		},
+	},
+	&projTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+		},
+		msg:       "Create API token with custom kind and no policy",
+		method:    "POST",
+		endpoint:  "/api/projects/1/api_tokens",
+		body:      `{"name":"ci","kind":"custom"}`,
+		expStatus: http.StatusBadRequest,
+		useCookie: true,
+	},
+	&projTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+		},
+		msg:       "Create API token with invalid ttl",
+		method:    "POST",
+		endpoint:  "/api/projects/1/api_tokens",
+		body:      `{"name":"ci","kind":"developer","ttl":"forever"}`,
+		expStatus: http.StatusBadRequest,
+		useCookie: true,
+	},
+}
+
+func TestHandleCreateAPIToken(t *testing.T) {
+	testProjRequests(t, createAPITokenTests, true)
+}
+
+var revokeAPITokenTests = []*projTest{
+	&projTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+			initAPIToken,
+		},
+		msg:       "Revoke API token",
+		method:    "POST",
+		endpoint:  "/api/projects/1/api_tokens/1/revoke",
+		body:      ``,
+		expStatus: http.StatusOK,
+		useCookie: true,
+		validators: []func(c *projTest, tester *tester, t *testing.T){
+			apiTokenRevokedValidator,
+		},
+	},
+}
+
+func TestHandleRevokeAPIToken(t *testing.T) {
+	testProjRequests(t, revokeAPITokenTests, true)
+}
+
+// ------------------------- INITIALIZERS AND VALIDATORS ------------------------- //
+
+func initAPIToken(tester *tester) {
+	tester.repo.APIToken.CreateAPIToken(&models.APIToken{
+		UniqueID:        "abcdef",
+		ProjectID:       1,
+		CreatedByUserID: 1,
+		Name:            "ci",
+		Kind:            models.RoleViewer,
+	})
+}
+
+// readProjectWithToken reads the project with the given encoded token, and
+// returns the response status
+func readProjectWithToken(tester *tester, encoded string) int {
+	req, _ := http.NewRequest("GET", "/api/projects/1", nil)
+	req.Header.Set("Authorization", "Bearer "+encoded)
+
+	rr := httptest.NewRecorder()
+	tester.router.ServeHTTP(rr, req)
+
+	return rr.Code
+}
+
+func apiTokenCreatedValidator(c *projTest, tester *tester, t *testing.T) {
+	gotBody := &models.APITokenExternal{}
+
+	json.Unmarshal(tester.rr.Body.Bytes(), gotBody)
+
+	if gotBody.Name != "ci" || gotBody.Kind != models.RoleDeveloper {
+		t.Errorf("%s, handler returned wrong token: got %v", c.msg, gotBody)
+	}
+
+	if gotBody.Expiry == nil {
+		t.Errorf("%s, expected token to have an expiry", c.msg)
+	}
+
+	if status := readProjectWithToken(tester, gotBody.Token); status != http.StatusOK {
+		t.Errorf("%s, expected created token to read project: got status %d", c.msg, status)
+	}
+}
+
+func apiTokenRevokedValidator(c *projTest, tester *tester, t *testing.T) {
+	jwt, _ := token.GetTokenForAPI(1, 1)
+	jwt.TokenID = "abcdef"
+
+	encoded, _ := jwt.EncodeToken(&token.TokenGeneratorConf{
+		TokenSecret: "secret",
+	})
+
+	if status := readProjectWithToken(tester, encoded); status != http.StatusForbidden {
+		t.Errorf("%s, expected revoked token to be rejected: got status %d", c.msg, status)
+	}
+}
@@ -0,0 +1,65 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+)
+
+// HandleListAuditEvents lists the audit events of a project, newest first. The
+// results can be filtered by user, resource type and time range, and are paginated
+// with the limit and skip query params.
+func (app *App) HandleListAuditEvents(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	vals, err := url.ParseQuery(r.URL.RawQuery)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	form := &forms.ListAuditEventsForm{
+		AuditEventFilter: &repository.AuditEventFilter{},
+	}
+
+	if err := form.PopulateListFromQueryParams(vals); err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrProjectValidateFields,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return
+	}
+
+	events, err := app.Repo.AuditEvent.ListAuditEventsByProjectID(uint(projID), form.AuditEventFilter)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return
+	}
+
+	res := make([]*models.AuditEventExternal, 0)
+
+	for _, event := range events {
+		res = append(res, event.Externalize())
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
@@ -0,0 +1,84 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// ------------------------- TEST FIXTURES AND FUNCTIONS  ------------------------- //
+
+var listAuditEventsTests = []*projTest{
+	&projTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+			initAuditedRequest,
+		},
+		msg:       "List audit events",
+		method:    "GET",
+		endpoint:  "/api/projects/1/audit_logs?resource_type=api_tokens",
+		body:      ``,
+		expStatus: http.StatusOK,
+		useCookie: true,
+		validators: []func(c *projTest, tester *tester, t *testing.T){
+			auditEventsValidator,
+		},
+	},
+	&projTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+		},
+		msg:       "List audit events with invalid time range",
+		method:    "GET",
+		endpoint:  "/api/projects/1/audit_logs?since=yesterday",
+		body:      ``,
+		expStatus: http.StatusBadRequest,
+		useCookie: true,
+	},
+}
+
+func TestHandleListAuditEvents(t *testing.T) {
+	testProjRequests(t, listAuditEventsTests, true)
+}
+
+// ------------------------- INITIALIZERS AND VALIDATORS ------------------------- //
+
+// initAuditedRequest creates an API token through the router, which should
+// generate an audit event
+func initAuditedRequest(tester *tester) {
+	req, _ := http.NewRequest(
+		"POST",
+		"/api/projects/1/api_tokens",
+		strings.NewReader(`{"name":"ci","kind":"viewer"}`),
+	)
+
+	req.AddCookie(tester.cookie)
+
+	tester.router.ServeHTTP(httptest.NewRecorder(), req)
+}
+
+func auditEventsValidator(c *projTest, tester *tester, t *testing.T) {
+	gotBody := make([]*models.AuditEventExternal, 0)
+
+	json.Unmarshal(tester.rr.Body.Bytes(), &gotBody)
+
+	if len(gotBody) != 1 {
+		t.Fatalf("%s, expected 1 audit event, got %d", c.msg, len(gotBody))
+	}
+
+	event := gotBody[0]
+
+	if event.UserID != 1 || event.Method != "POST" || event.Status != http.StatusCreated || !event.Success {
+		t.Errorf("%s, incorrect audit event: got %v", c.msg, event)
+	}
+
+	if event.Route != "/api/projects/{project_id}/api_tokens" {
+		t.Errorf("%s, incorrect audit event route: got %s", c.msg, event.Route)
+	}
+}
@@ -0,0 +1,185 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/helm/grapher"
+	"github.com/porter-dev/porter/internal/integrations/slack"
+	"github.com/porter-dev/porter/internal/kubernetes"
+	"github.com/porter-dev/porter/internal/models"
+	"helm.sh/helm/v3/pkg/release"
+)
+
+// autoRollbackInterval is the interval at which the controllers of a new revision
+// are checked for readiness
+const autoRollbackInterval = 10 * time.Second
+
+// HandleUpdateAutoRollback enables or disables automatic rollbacks of a release.
+// When enabled, the controllers of each new revision are watched after an upgrade,
+// and the release is rolled back to the previous revision if they are not ready
+// within the timeout.
+func (app *App) HandleUpdateAutoRollback(w http.ResponseWriter, r *http.Request) {
+	release, ok := app.readReleaseFromQueryParams(w, r, true)
+
+	if !ok {
+		return
+	}
+
+	form := &forms.UpdateAutoRollbackForm{}
+
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrReleaseValidateFields, w)
+		return
+	}
+
+	release.AutoRollback = form.Enabled
+	release.AutoRollbackTimeout = form.Timeout
+
+	release, err := app.Repo.Release.UpdateRelease(release)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(release.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// watchAutoRollback waits for the controllers of a new revision to become ready if
+// the release has automatic rollbacks enabled. If they are not ready within the
+// timeout of the release, the release is rolled back to the previous revision and
+// a failure is notified with the controller that was not ready. The agent is read
+// from the pool on each poll, since the credentials of an agent may expire while
+// the revision is watched.
+func (app *App) watchAutoRollback(
+	dbRelease *models.Release,
+	form helm.Form,
+	rel *release.Release,
+	prevRevision int,
+	notifyOpts slack.NotifyOpts,
+) {
+	if dbRelease == nil || !dbRelease.AutoRollback || prevRevision == 0 {
+		return
+	}
+
+	timeout := time.Duration(dbRelease.GetAutoRollbackTimeout()) * time.Second
+	deadline := time.Now().Add(timeout)
+
+	controllers := grapher.ParseControllers(grapher.ImportMultiDocYAML([]byte(rel.Manifest)))
+
+	var reason string
+
+	for {
+		retrieved, err := app.getWatchControllers(&form, controllers, rel.Namespace)
+
+		if err != nil {
+			reason = fmt.Sprintf("could not read controllers: %v", err)
+		} else {
+			var ready bool
+
+			if ready, reason = kubernetes.ControllersReady(retrieved); ready {
+				return
+			}
+		}
+
+		if time.Now().After(deadline) {
+			break
+		}
+
+		time.Sleep(autoRollbackInterval)
+	}
+
+	agent, err := app.getPooledHelmAgent(&form)
+
+	if err != nil {
+		app.Logger.Warn().Err(err).Msg("could not get agent to roll back release")
+		return
+	}
+
+	// the revision is not rolled back if it has been replaced by another upgrade
+	if latest, err := agent.GetRelease(rel.Name, 0, false); err != nil || latest.Version != rel.Version {
+		return
+	}
+
+	deployment := &models.Deployment{
+		ProjectID: dbRelease.ProjectID,
+		ClusterID: dbRelease.ClusterID,
+		Namespace: rel.Namespace,
+		Name:      rel.Name,
+		Trigger:   models.DeploymentTriggerRollback,
+	}
+
+	notifyOpts.Status = slack.StatusFailed
+	notifyOpts.Version = rel.Version
+
+	info := fmt.Sprintf("revision %d was not ready within %s: %s", rel.Version, timeout, reason)
+
+	if err := agent.RollbackRelease(rel.Name, prevRevision); err != nil {
+		notifyOpts.Info = fmt.Sprintf("%s, and could not be rolled back to revision %d: %v", info, prevRevision, err)
+
+		deployment.Status = models.DeploymentStatusFailed
+		deployment.Info = notifyOpts.Info
+
+		app.recordDeployment(deployment, rel.Config, rel.Config)
+	} else {
+		notifyOpts.Info = fmt.Sprintf("%s, so it was automatically rolled back to revision %d", info, prevRevision)
+
+		deployment.Info = notifyOpts.Info
+
+		// the rollback creates a new revision with the values of the previous revision
+		if latestRel, err := agent.GetRelease(rel.Name, 0, false); err == nil {
+			deployment.Revision = latestRel.Version
+			deployment.Status = string(latestRel.Info.Status)
+
+			app.recordDeployment(deployment, rel.Config, latestRel.Config)
+		} else {
+			app.Logger.Warn().Err(err).Msg("could not read release to record automatic rollback")
+		}
+	}
+
+	app.notifyRelease(dbRelease, dbRelease.ProjectID, &notifyOpts)
+}
+
+// getWatchControllers reads the controllers of a release with an agent from the pool
+func (app *App) getWatchControllers(
+	form *helm.Form,
+	controllers []grapher.Object,
+	namespace string,
+) ([]interface{}, error) {
+	agent, err := app.getPooledHelmAgent(form)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if agent.K8sAgent == nil {
+		return nil, fmt.Errorf("agent has no kubernetes client")
+	}
+
+	return agent.K8sAgent.GetControllers(controllers, namespace)
+}
+
+// getPooledHelmAgent returns a Helm agent from the pool for work that outlives the
+// request that started it
+func (app *App) getPooledHelmAgent(form *helm.Form) (*helm.Agent, error) {
+	if app.ServerConf.IsTesting {
+		return app.TestAgents.HelmAgent, nil
+	}
+
+	return helm.GetAgentFromPool(form, app.Logger, app.AgentPool)
+}
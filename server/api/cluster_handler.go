@@ -98,7 +98,7 @@ func (app *App) HandleReadProjectCluster(w http.ResponseWriter, r *http.Request)
 	if app.ServerConf.IsTesting {
 		agent = app.TestAgents.K8sAgent
 	} else {
-		agent, _ = kubernetes.GetAgentOutOfClusterConfig(form.OutOfClusterConfig)
+		agent, _ = app.AgentPool.GetAgent(form.OutOfClusterConfig)
 	}
 
 	endpoint, found, ingressErr := domain.GetNGINXIngressServiceIP(agent.Clientset)
@@ -197,6 +197,8 @@ func (app *App) HandleUpdateProjectCluster(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	app.AgentPool.Invalidate(cluster.ID)
+
 	w.WriteHeader(http.StatusOK)
 
 	clusterExt := cluster.Externalize()
@@ -230,6 +232,8 @@ func (app *App) HandleDeleteProjectCluster(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	app.AgentPool.Invalidate(cluster.ID)
+
 	w.WriteHeader(http.StatusOK)
 }
 
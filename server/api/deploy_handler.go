@@ -19,6 +19,7 @@ import (
 	"github.com/porter-dev/porter/internal/models"
 	"github.com/porter-dev/porter/internal/oauth"
 	"github.com/porter-dev/porter/internal/repository"
+	mw "github.com/porter-dev/porter/server/middleware"
 	"gopkg.in/yaml.v2"
 )
 
@@ -98,6 +99,13 @@ func (app *App) HandleDeployTemplate(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkReleaseFormTarget(w, vals, form.ReleaseForm.Namespace, "", "") {
+		return
+	}
+
+	// the name URL parameter is the name of the template
+	mw.SetAuditTarget(r, "release_name", form.ChartTemplateForm.Name)
+
 	agent, err := app.getAgentFromReleaseForm(
 		w,
 		r,
@@ -281,6 +289,13 @@ func (app *App) HandleDeployAddon(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkReleaseFormTarget(w, vals, form.ReleaseForm.Namespace, "", "") {
+		return
+	}
+
+	// the name URL parameter is the name of the template
+	mw.SetAuditTarget(r, "release_name", form.ChartTemplateForm.Name)
+
 	app.AnalyticsClient.Track(analytics.ApplicationLaunchStartTrack(
 		&analytics.ApplicationLaunchStartTrackOpts{
 			ClusterScopedTrackOpts: analytics.GetClusterScopedTrackOpts(userID, uint(projID), uint(form.ReleaseForm.Cluster.ID)),
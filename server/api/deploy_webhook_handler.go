@@ -0,0 +1,251 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// HandleCreateDeployWebhookToken creates a named deploy webhook token for a release.
+// The token and signing secret are only returned in this response.
+func (app *App) HandleCreateDeployWebhookToken(w http.ResponseWriter, r *http.Request) {
+	name := chi.URLParam(r, "name")
+
+	vals, err := url.ParseQuery(r.URL.RawQuery)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	userID, err := app.getUserIDFromRequest(r)
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	form := &forms.CreateDeployWebhookTokenForm{
+		CreatedByUserID: userID,
+	}
+
+	// decode from JSON to form value
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	release, ok := app.readReleaseFromQueryParams(w, r, false)
+
+	if !ok {
+		return
+	}
+
+	// the release is stored the first time that a webhook is created for it
+	if release == nil {
+		release, err = app.createReleaseWithWebhookToken(w, r, name, vals)
+
+		// errors are handled in app.createReleaseWithWebhookToken
+		if err != nil {
+			return
+		}
+	}
+
+	form.ReleaseID = release.ID
+	form.ProjectID = release.ProjectID
+
+	// validate the form
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrReleaseValidateFields, w)
+		return
+	}
+
+	webhookToken, err := form.ToDeployWebhookToken()
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	secret := string(webhookToken.SigningSecret)
+
+	webhookToken, err = app.Repo.DeployWebhookToken.CreateDeployWebhookToken(webhookToken)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	app.Logger.Info().Msgf("New deploy webhook token created: %d", webhookToken.ID)
+
+	res := webhookToken.Externalize()
+	res.Token = webhookToken.Token
+	res.SigningSecret = secret
+
+	w.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// HandleListDeployWebhookTokens lists the named deploy webhook tokens of a release,
+// including revoked tokens
+func (app *App) HandleListDeployWebhookTokens(w http.ResponseWriter, r *http.Request) {
+	release, ok := app.readReleaseFromQueryParams(w, r, true)
+
+	if !ok {
+		return
+	}
+
+	webhookTokens, err := app.Repo.DeployWebhookToken.ListDeployWebhookTokensByReleaseID(release.ID)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrReleaseReadData, w)
+		return
+	}
+
+	res := make([]*models.DeployWebhookTokenExternal, 0)
+
+	for _, webhookToken := range webhookTokens {
+		res = append(res, webhookToken.Externalize())
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// HandleRevokeDeployWebhookToken revokes a named deploy webhook token. Revoked tokens
+// are kept, so that the deployments that they triggered can still be attributed.
+func (app *App) HandleRevokeDeployWebhookToken(w http.ResponseWriter, r *http.Request) {
+	tokenID, err := strconv.ParseUint(chi.URLParam(r, "webhook_token_id"), 0, 64)
+
+	if err != nil || tokenID == 0 {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	release, ok := app.readReleaseFromQueryParams(w, r, true)
+
+	if !ok {
+		return
+	}
+
+	webhookToken, err := app.Repo.DeployWebhookToken.ReadDeployWebhookToken(release.ID, uint(tokenID))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrReleaseReadData, w)
+		return
+	}
+
+	webhookToken.Revoked = true
+
+	webhookToken, err = app.Repo.DeployWebhookToken.UpdateDeployWebhookToken(webhookToken)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(webhookToken.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// HandleRotateReleaseWebhookToken replaces the webhook token of a release with a new
+// token, so that a leaked token can no longer be used to deploy the release
+func (app *App) HandleRotateReleaseWebhookToken(w http.ResponseWriter, r *http.Request) {
+	release, ok := app.readReleaseFromQueryParams(w, r, true)
+
+	if !ok {
+		return
+	}
+
+	token, err := repository.GenerateRandomBytes(16)
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	release.WebhookToken = token
+
+	release, err = app.Repo.Release.UpdateRelease(release)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	app.Logger.Info().Msgf("Webhook token rotated for release: %d", release.ID)
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(release.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// readReleaseFromQueryParams reads the stored release given by the name URL param
+// and the cluster_id and namespace query params, and checks that it belongs to the
+// project. If mustExist is false, a nil release is returned if it is not stored.
+// Errors are written to the response.
+func (app *App) readReleaseFromQueryParams(
+	w http.ResponseWriter,
+	r *http.Request,
+	mustExist bool,
+) (*models.Release, bool) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return nil, false
+	}
+
+	vals, err := url.ParseQuery(r.URL.RawQuery)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return nil, false
+	}
+
+	clusterID, err := strconv.ParseUint(vals.Get("cluster_id"), 10, 64)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return nil, false
+	}
+
+	release, err := app.Repo.Release.ReadRelease(uint(clusterID), chi.URLParam(r, "name"), vals.Get("namespace"))
+
+	if err == gorm.ErrRecordNotFound && !mustExist {
+		return nil, true
+	} else if err != nil {
+		app.handleErrorRead(err, ErrReleaseReadData, w)
+		return nil, false
+	}
+
+	// releases of other projects are reported as not found
+	if release.ProjectID != uint(projID) {
+		app.handleErrorRead(gorm.ErrRecordNotFound, ErrReleaseReadData, w)
+		return nil, false
+	}
+
+	return release, true
+}
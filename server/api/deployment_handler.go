@@ -0,0 +1,111 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/helm/diff"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+)
+
+// HandleListDeployments lists the recorded deployments of a release, newest first.
+// Unlike the release history, deployments are kept after Helm prunes old revisions.
+func (app *App) HandleListDeployments(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	vals, err := url.ParseQuery(r.URL.RawQuery)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	form := &forms.ListDeploymentsForm{
+		DeploymentFilter: &repository.DeploymentFilter{
+			Name: chi.URLParam(r, "name"),
+		},
+	}
+
+	if err := form.PopulateListFromQueryParams(vals); err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return
+	}
+
+	deployments, err := app.Repo.Deployment.ListDeploymentsByProjectID(uint(projID), form.DeploymentFilter)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrReleaseReadData, w)
+		return
+	}
+
+	res := make([]*models.DeploymentExternal, 0)
+
+	for _, deployment := range deployments {
+		res = append(res, deployment.Externalize())
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// setDeploymentActor sets the user and API token that made the request as the
+// actor of the deployment
+func (app *App) setDeploymentActor(deployment *models.Deployment, r *http.Request) {
+	deployment.UserID, _ = app.getUserIDFromRequest(r)
+
+	if tok := app.getTokenFromRequest(r); tok != nil {
+		deployment.APITokenID = tok.TokenID
+	}
+}
+
+// recordDeployment stores a deployment along with the image and the diff between
+// the previous and new values of the release. Failures are logged, since the
+// release has already been changed at this point.
+func (app *App) recordDeployment(
+	deployment *models.Deployment,
+	prevValues, values map[string]interface{},
+) {
+	if image, ok := values["image"].(map[string]interface{}); ok {
+		if repo, ok := image["repository"]; ok && repo != nil {
+			deployment.ImageRepoURI = fmt.Sprintf("%v", repo)
+		}
+
+		// tags that are pinned to a digest are recorded as the tag and digest
+		if tag, ok := image["tag"]; ok && tag != nil {
+			spl := strings.SplitN(fmt.Sprintf("%v", tag), "@", 2)
+			deployment.ImageTag = spl[0]
+
+			if len(spl) == 2 {
+				deployment.ImageDigest = spl[1]
+			}
+		}
+	}
+
+	if err := deployment.SetValuesDiff(diff.Values(prevValues, values)); err != nil {
+		app.Logger.Warn().Err(err).Msg("could not encode deployment values diff")
+	}
+
+	if _, err := app.Repo.Deployment.CreateDeployment(deployment); err != nil {
+		app.Logger.Warn().Err(err).Msg("could not record deployment")
+	}
+}
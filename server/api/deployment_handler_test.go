@@ -0,0 +1,125 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// ------------------------- TEST FIXTURES AND FUNCTIONS  ------------------------- //
+
+var listDeploymentsTests = []*projTest{
+	&projTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+			initDeployments,
+		},
+		msg:    "List deployments",
+		method: "GET",
+		endpoint: "/api/projects/1/releases/wordpress/deployments?" + url.Values{
+			"namespace":  []string{"default"},
+			"cluster_id": []string{"1"},
+		}.Encode(),
+		body:      ``,
+		expStatus: http.StatusOK,
+		useCookie: true,
+		validators: []func(c *projTest, tester *tester, t *testing.T){
+			deploymentsValidator,
+		},
+	},
+	&projTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+			initDeployments,
+		},
+		msg:    "List deployments with invalid limit",
+		method: "GET",
+		endpoint: "/api/projects/1/releases/wordpress/deployments?" + url.Values{
+			"namespace":  []string{"default"},
+			"cluster_id": []string{"1"},
+			"limit":      []string{"-1"},
+		}.Encode(),
+		body:      ``,
+		expStatus: http.StatusBadRequest,
+		useCookie: true,
+	},
+}
+
+func TestHandleListDeployments(t *testing.T) {
+	testProjRequests(t, listDeploymentsTests, true)
+}
+
+// ------------------------- INITIALIZERS AND VALIDATORS ------------------------- //
+
+func initDeployments(tester *tester) {
+	// the cluster is created directly, since deployments do not need a working
+	// kubeconfig
+	tester.repo.Cluster.CreateCluster(&models.Cluster{
+		ProjectID: 1,
+		Name:      "cluster-test",
+	})
+
+	deployments := []*models.Deployment{
+		{
+			ProjectID: 1,
+			ClusterID: 1,
+			Namespace: "default",
+			Name:      "wordpress",
+			Revision:  1,
+			UserID:    1,
+			Trigger:   models.DeploymentTriggerManual,
+			ImageTag:  "v1",
+			Status:    "deployed",
+		},
+		{
+			ProjectID:   1,
+			ClusterID:   1,
+			Namespace:   "default",
+			Name:        "wordpress",
+			Revision:    2,
+			Trigger:     models.DeploymentTriggerWebhook,
+			ImageTag:    "v2",
+			ImageDigest: "sha256:" + strings.Repeat("a", 64),
+			GitCommit:   "v2",
+			Status:      "deployed",
+		},
+		{
+			ProjectID: 1,
+			ClusterID: 1,
+			Namespace: "default",
+			Name:      "redis",
+			Revision:  1,
+			Trigger:   models.DeploymentTriggerManual,
+			Status:    "deployed",
+		},
+	}
+
+	for _, deployment := range deployments {
+		tester.repo.Deployment.CreateDeployment(deployment)
+	}
+}
+
+func deploymentsValidator(c *projTest, tester *tester, t *testing.T) {
+	gotBody := make([]*models.DeploymentExternal, 0)
+
+	json.Unmarshal(tester.rr.Body.Bytes(), &gotBody)
+
+	if len(gotBody) != 2 {
+		t.Fatalf("%s, expected 2 deployments, got %d", c.msg, len(gotBody))
+	}
+
+	if gotBody[0].Revision != 2 || gotBody[0].Trigger != models.DeploymentTriggerWebhook ||
+		gotBody[0].ImageTag != "v2" || gotBody[0].ImageDigest != "sha256:"+strings.Repeat("a", 64) {
+		t.Errorf("%s, incorrect latest deployment: got %v", c.msg, gotBody[0])
+	}
+
+	if gotBody[1].Revision != 1 || gotBody[1].UserID != 1 {
+		t.Errorf("%s, incorrect first deployment: got %v", c.msg, gotBody[1])
+	}
+}
@@ -49,7 +49,7 @@ func (app *App) HandleCreateDNSRecord(w http.ResponseWriter, r *http.Request) {
 	if app.ServerConf.IsTesting {
 		agent = app.TestAgents.K8sAgent
 	} else {
-		agent, err = kubernetes.GetAgentOutOfClusterConfig(form.OutOfClusterConfig)
+		agent, err = app.AgentPool.GetAgent(form.OutOfClusterConfig)
 	}
 
 	endpoint, found, err := domain.GetNGINXIngressServiceIP(agent.Clientset)
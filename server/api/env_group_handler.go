@@ -0,0 +1,878 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/helm/diff"
+	"github.com/porter-dev/porter/internal/kubernetes"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	mw "github.com/porter-dev/porter/server/middleware"
+	"gorm.io/gorm"
+
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// EnvGroupResponse is an env group along with its current version
+type EnvGroupResponse struct {
+	*models.EnvGroupExternal
+
+	CurrentVersion *models.EnvGroupVersionExternal `json:"current_version"`
+}
+
+// EnvGroupReleaseUpgrade is the result of upgrading a release linked to an env group
+type EnvGroupReleaseUpgrade struct {
+	Name     string `json:"name"`
+	Revision int    `json:"revision,omitempty"`
+	Error    string `json:"error,omitempty"`
+}
+
+// EnvGroupUpdateResponse is the response to a change to an env group, along with
+// the results of upgrading the linked releases
+type EnvGroupUpdateResponse struct {
+	*EnvGroupResponse
+
+	Upgrades []EnvGroupReleaseUpgrade `json:"upgrades"`
+}
+
+// HandleListEnvGroups lists the env groups of a namespace
+func (app *App) HandleListEnvGroups(w http.ResponseWriter, r *http.Request) {
+	clusterID, err := getEnvGroupClusterID(r)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrEnvDecode, w)
+		return
+	}
+
+	groups, err := app.Repo.EnvGroup.ListEnvGroups(clusterID, chi.URLParam(r, "namespace"))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrK8sDecode, w)
+		return
+	}
+
+	res := make([]*models.EnvGroupExternal, 0)
+
+	for _, group := range groups {
+		res = append(res, group.Externalize())
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		app.handleErrorFormDecoding(err, ErrEnvDecode, w)
+		return
+	}
+}
+
+// HandleGetEnvGroup returns an env group along with the variables of its current
+// version. The values of secret variables are not returned.
+func (app *App) HandleGetEnvGroup(w http.ResponseWriter, r *http.Request) {
+	group, err := app.readEnvGroupFromRequest(r)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrK8sDecode, w)
+		return
+	}
+
+	version, err := app.Repo.EnvGroup.ReadEnvGroupVersion(group.ID, group.Version)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrK8sDecode, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(&EnvGroupResponse{
+		EnvGroupExternal: group.Externalize(),
+		CurrentVersion:   version.Externalize(),
+	}); err != nil {
+		app.handleErrorFormDecoding(err, ErrEnvDecode, w)
+		return
+	}
+}
+
+// HandleListEnvGroupVersions lists the versions of an env group, newest first
+func (app *App) HandleListEnvGroupVersions(w http.ResponseWriter, r *http.Request) {
+	group, err := app.readEnvGroupFromRequest(r)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrK8sDecode, w)
+		return
+	}
+
+	versions, err := app.Repo.EnvGroup.ListEnvGroupVersions(group.ID)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrK8sDecode, w)
+		return
+	}
+
+	res := make([]*models.EnvGroupVersionExternal, 0)
+
+	for _, version := range versions {
+		res = append(res, version.Externalize())
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		app.handleErrorFormDecoding(err, ErrEnvDecode, w)
+		return
+	}
+}
+
+// HandleCreateEnvGroup creates an env group along with its ConfigMap and Secret. If a
+// ConfigMap with the same name already exists, it is adopted by the env group and its
+// variables are replaced.
+func (app *App) HandleCreateEnvGroup(w http.ResponseWriter, r *http.Request) {
+	form := &forms.CreateEnvGroupForm{}
+
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrEnvDecode, w)
+		return
+	}
+
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrK8sValidate, w)
+		return
+	}
+
+	agent, releaseForm, err := app.getEnvGroupAgent(w, r)
+
+	// errors are handled in app.getEnvGroupAgent
+	if err != nil {
+		return
+	}
+
+	if _, err := app.Repo.EnvGroup.ReadEnvGroup(
+		releaseForm.Cluster.ID,
+		releaseForm.Namespace,
+		form.Name,
+	); err == nil {
+		app.sendExternalError(fmt.Errorf("env group already exists"), http.StatusConflict, HTTPError{
+			Code:   ErrK8sValidate,
+			Errors: []string{"env group already exists"},
+		}, w)
+
+		return
+	}
+
+	group := &models.EnvGroup{
+		ProjectID: uint(releaseForm.Cluster.ProjectID),
+		ClusterID: releaseForm.Cluster.ID,
+		Namespace: releaseForm.Namespace,
+		Name:      form.Name,
+		Version:   1,
+	}
+
+	version := &models.EnvGroupVersion{
+		Version: 1,
+	}
+
+	version.UserID, _ = app.getUserIDFromRequest(r)
+
+	if err := version.SetVariables(form.Variables, form.SecretVariables); err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	if err := applyEnvGroupVersion(agent.K8sAgent, group, version); err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	group, err = app.Repo.EnvGroup.CreateEnvGroup(group)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	version.EnvGroupID = group.ID
+
+	version, err = app.Repo.EnvGroup.CreateEnvGroupVersion(version)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(w).Encode(&EnvGroupResponse{
+		EnvGroupExternal: group.Externalize(),
+		CurrentVersion:   version.Externalize(),
+	}); err != nil {
+		app.handleErrorFormDecoding(err, ErrEnvDecode, w)
+		return
+	}
+}
+
+// HandleUpdateEnvGroup creates a new version of an env group from the variables in
+// the request, and upgrades all releases linked to the env group
+func (app *App) HandleUpdateEnvGroup(w http.ResponseWriter, r *http.Request) {
+	form := &forms.UpdateEnvGroupForm{}
+
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrEnvDecode, w)
+		return
+	}
+
+	group, err := app.readEnvGroupFromRequest(r)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrK8sDecode, w)
+		return
+	}
+
+	prevVersion, err := app.Repo.EnvGroup.ReadEnvGroupVersion(group.ID, group.Version)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrK8sDecode, w)
+		return
+	}
+
+	_, prevSecrets, err := prevVersion.GetVariables()
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	secrets := make(map[string]string)
+
+	for key, val := range form.SecretVariables {
+		// secret values are not returned to clients, so an empty value keeps the
+		// current value of the secret
+		if prevVal, ok := prevSecrets[key]; ok && val == "" {
+			val = prevVal
+		}
+
+		secrets[key] = val
+	}
+
+	version := &models.EnvGroupVersion{}
+
+	if err := version.SetVariables(form.Variables, secrets); err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	app.updateEnvGroup(w, r, group, prevVersion, version)
+}
+
+// HandleRollbackEnvGroup rolls an env group back to an earlier version, by creating a
+// new version with the variables of the earlier version, and upgrades all releases
+// linked to the env group
+func (app *App) HandleRollbackEnvGroup(w http.ResponseWriter, r *http.Request) {
+	form := &forms.RollbackEnvGroupForm{}
+
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrEnvDecode, w)
+		return
+	}
+
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrK8sValidate, w)
+		return
+	}
+
+	group, err := app.readEnvGroupFromRequest(r)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrK8sDecode, w)
+		return
+	}
+
+	prevVersion, err := app.Repo.EnvGroup.ReadEnvGroupVersion(group.ID, group.Version)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrK8sDecode, w)
+		return
+	}
+
+	target, err := app.Repo.EnvGroup.ReadEnvGroupVersion(group.ID, form.Version)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrK8sDecode, w)
+		return
+	}
+
+	version := &models.EnvGroupVersion{
+		VariablesBytes:       target.VariablesBytes,
+		SecretVariablesBytes: target.SecretVariablesBytes,
+	}
+
+	app.updateEnvGroup(w, r, group, prevVersion, version)
+}
+
+// HandleDeleteEnvGroup deletes an env group along with its ConfigMap and Secret. An env
+// group that is linked to releases cannot be deleted.
+func (app *App) HandleDeleteEnvGroup(w http.ResponseWriter, r *http.Request) {
+	group, err := app.readEnvGroupFromRequest(r)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrK8sDecode, w)
+		return
+	}
+
+	if len(group.Releases) > 0 {
+		app.sendExternalError(fmt.Errorf("env group is linked to releases"), http.StatusBadRequest, HTTPError{
+			Code:   ErrK8sValidate,
+			Errors: []string{fmt.Sprintf("env group is used by releases: %v", group.Externalize().Releases)},
+		}, w)
+
+		return
+	}
+
+	agent, _, err := app.getEnvGroupAgent(w, r)
+
+	// errors are handled in app.getEnvGroupAgent
+	if err != nil {
+		return
+	}
+
+	// the ConfigMap and Secret may have been removed outside of Porter
+	if err := agent.K8sAgent.DeleteLinkedSecret(group.Name, group.Namespace); err != nil && !k8sErrors.IsNotFound(err) {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	if err := agent.K8sAgent.DeleteConfigMap(group.Name, group.Namespace); err != nil && !k8sErrors.IsNotFound(err) {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	if err := app.Repo.EnvGroup.DeleteEnvGroup(group); err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// HandleLinkEnvGroupRelease links a release to an env group, and upgrades the release
+// with the variables of the current version of the env group
+func (app *App) HandleLinkEnvGroupRelease(w http.ResponseWriter, r *http.Request) {
+	form := &forms.LinkEnvGroupReleaseForm{}
+
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrEnvDecode, w)
+		return
+	}
+
+	mw.SetAuditTarget(r, "release_name", form.ReleaseName)
+
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrK8sValidate, w)
+		return
+	}
+
+	group, err := app.readEnvGroupFromRequest(r)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrK8sDecode, w)
+		return
+	}
+
+	version, err := app.Repo.EnvGroup.ReadEnvGroupVersion(group.ID, group.Version)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrK8sDecode, w)
+		return
+	}
+
+	agent, releaseForm, err := app.getEnvGroupAgent(w, r)
+
+	// errors are handled in app.getEnvGroupAgent
+	if err != nil {
+		return
+	}
+
+	if _, err := agent.GetRelease(form.ReleaseName, 0, false); err != nil {
+		app.sendExternalError(err, http.StatusNotFound, HTTPError{
+			Code:   ErrReleaseReadData,
+			Errors: []string{"release not found"},
+		}, w)
+
+		return
+	}
+
+	// linking upgrades the release, so the release must be writable by the user
+	if !mw.HasApplicationAccess(r, group.Namespace, form.ReleaseName) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	if !group.HasRelease(form.ReleaseName) {
+		rel, err := app.Repo.EnvGroup.AddEnvGroupRelease(&models.EnvGroupRelease{
+			EnvGroupID:  group.ID,
+			ReleaseName: form.ReleaseName,
+		})
+
+		if err != nil {
+			app.handleErrorDataWrite(err, w)
+			return
+		}
+
+		group.Releases = append(group.Releases, *rel)
+	}
+
+	upgrades := app.upgradeEnvGroupReleases(
+		r,
+		releaseForm,
+		group,
+		[]string{form.ReleaseName},
+		nil,
+		version,
+	)
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(&EnvGroupUpdateResponse{
+		EnvGroupResponse: &EnvGroupResponse{
+			EnvGroupExternal: group.Externalize(),
+			CurrentVersion:   version.Externalize(),
+		},
+		Upgrades: upgrades,
+	}); err != nil {
+		app.handleErrorFormDecoding(err, ErrEnvDecode, w)
+		return
+	}
+}
+
+// HandleUnlinkEnvGroupRelease unlinks a release from an env group, so that it is no
+// longer upgraded when the env group changes. The variables of the env group are
+// left in the values of the release.
+func (app *App) HandleUnlinkEnvGroupRelease(w http.ResponseWriter, r *http.Request) {
+	group, err := app.readEnvGroupFromRequest(r)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrK8sDecode, w)
+		return
+	}
+
+	releaseName := chi.URLParam(r, "release_name")
+
+	if !group.HasRelease(releaseName) {
+		app.handleErrorRead(gorm.ErrRecordNotFound, ErrK8sDecode, w)
+		return
+	}
+
+	if err := app.Repo.EnvGroup.RemoveEnvGroupRelease(group.ID, releaseName); err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// updateEnvGroup stores version as the next version of the env group, writes it to
+// the cluster and upgrades the linked releases
+func (app *App) updateEnvGroup(
+	w http.ResponseWriter,
+	r *http.Request,
+	group *models.EnvGroup,
+	prevVersion, version *models.EnvGroupVersion,
+) {
+	agent, releaseForm, err := app.getEnvGroupAgent(w, r)
+
+	// errors are handled in app.getEnvGroupAgent
+	if err != nil {
+		return
+	}
+
+	version.EnvGroupID = group.ID
+	version.Version = group.Version + 1
+	version.UserID, _ = app.getUserIDFromRequest(r)
+
+	if err := applyEnvGroupVersion(agent.K8sAgent, group, version); err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	version, err = app.Repo.EnvGroup.CreateEnvGroupVersion(version)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	group.Version = version.Version
+
+	group, err = app.Repo.EnvGroup.UpdateEnvGroup(group)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	upgrades := app.upgradeEnvGroupReleases(
+		r,
+		releaseForm,
+		group,
+		group.Externalize().Releases,
+		prevVersion,
+		version,
+	)
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(&EnvGroupUpdateResponse{
+		EnvGroupResponse: &EnvGroupResponse{
+			EnvGroupExternal: group.Externalize(),
+			CurrentVersion:   version.Externalize(),
+		},
+		Upgrades: upgrades,
+	}); err != nil {
+		app.handleErrorFormDecoding(err, ErrEnvDecode, w)
+		return
+	}
+}
+
+// upgradeEnvGroupReleases sets the variables of version in the values of each release,
+// removing the variables of prevVersion that are no longer in the env group, and
+// records a deployment for each upgrade. Failed upgrades are reported in the result
+// rather than failing the request, since the env group has already been changed.
+// Releases are upgraded concurrently, each with its own agent, since a Helm agent
+// cannot run several actions at once.
+func (app *App) upgradeEnvGroupReleases(
+	r *http.Request,
+	form *forms.ReleaseForm,
+	group *models.EnvGroup,
+	releaseNames []string,
+	prevVersion, version *models.EnvGroupVersion,
+) []EnvGroupReleaseUpgrade {
+	upgrades := make([]EnvGroupReleaseUpgrade, 0)
+
+	if len(releaseNames) == 0 {
+		return upgrades
+	}
+
+	registries, err := app.Repo.Registry.ListRegistriesByProjectID(uint(form.Cluster.ProjectID))
+
+	if err != nil {
+		for _, name := range releaseNames {
+			upgrades = append(upgrades, EnvGroupReleaseUpgrade{Name: name, Error: err.Error()})
+		}
+
+		return upgrades
+	}
+
+	prevVars := map[string]string{}
+
+	if prevVersion != nil {
+		prevVars = envGroupReleaseVariables(group, prevVersion)
+	}
+
+	vars := envGroupReleaseVariables(group, version)
+
+	// the actor is read once, since the request is shared by the goroutines
+	actor := &models.Deployment{}
+	app.setDeploymentActor(actor, r)
+
+	var wg sync.WaitGroup
+	mu := &sync.Mutex{}
+
+	for i := range releaseNames {
+		name := releaseNames[i]
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			upgrade := EnvGroupReleaseUpgrade{Name: name}
+
+			defer func() {
+				mu.Lock()
+				upgrades = append(upgrades, upgrade)
+				mu.Unlock()
+			}()
+
+			// releases that the user cannot write to are not upgraded, even if
+			// they are linked to the env group
+			if !mw.HasApplicationAccess(r, group.Namespace, name) {
+				upgrade.Error = http.StatusText(http.StatusForbidden)
+				return
+			}
+
+			agent, err := app.getPooledHelmAgent(form.Form)
+
+			if err != nil {
+				upgrade.Error = err.Error()
+				return
+			}
+
+			rel, err := agent.GetRelease(name, 0, false)
+
+			if err != nil {
+				upgrade.Error = err.Error()
+				return
+			}
+
+			prevValues := diff.CopyValues(rel.Config)
+			values := diff.CopyValues(rel.Config)
+
+			setEnvGroupValues(values, prevVars, vars)
+
+			conf := &helm.UpgradeReleaseConfig{
+				Name:       name,
+				Cluster:    form.Cluster,
+				Repo:       *app.Repo,
+				Registries: registries,
+				Values:     values,
+			}
+
+			deployment := &models.Deployment{
+				ProjectID:  group.ProjectID,
+				ClusterID:  group.ClusterID,
+				Namespace:  group.Namespace,
+				Name:       name,
+				UserID:     actor.UserID,
+				APITokenID: actor.APITokenID,
+				Trigger:    models.DeploymentTriggerEnvGroup,
+			}
+
+			// releases that are not stored have no vulnerability policy
+			dbRelease, _ := app.Repo.Release.ReadRelease(group.ClusterID, name, group.Namespace)
+
+			if err := app.checkUpgradeVulnerabilityPolicy(r.Context(), dbRelease, registries, conf.Values); err != nil {
+				upgrade.Error = err.Error()
+
+				deployment.Status = models.DeploymentStatusFailed
+				deployment.Info = err.Error()
+
+				mu.Lock()
+				app.recordDeployment(deployment, prevValues, conf.Values)
+				mu.Unlock()
+
+				return
+			}
+
+			newRel, err := agent.UpgradeReleaseByValues(conf, app.DOConf)
+
+			if err != nil {
+				upgrade.Error = err.Error()
+
+				deployment.Status = models.DeploymentStatusFailed
+				deployment.Info = err.Error()
+			} else {
+				upgrade.Revision = newRel.Version
+
+				deployment.Revision = newRel.Version
+				deployment.Status = string(newRel.Info.Status)
+			}
+
+			mu.Lock()
+			app.recordDeployment(deployment, prevValues, conf.Values)
+			mu.Unlock()
+		}()
+	}
+
+	wg.Wait()
+
+	sort.Slice(upgrades, func(i, j int) bool {
+		return upgrades[i].Name < upgrades[j].Name
+	})
+
+	return upgrades
+}
+
+// readEnvGroupFromRequest reads the env group named in the path of the request
+func (app *App) readEnvGroupFromRequest(r *http.Request) (*models.EnvGroup, error) {
+	clusterID, err := getEnvGroupClusterID(r)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return app.Repo.EnvGroup.ReadEnvGroup(
+		clusterID,
+		chi.URLParam(r, "namespace"),
+		chi.URLParam(r, "name"),
+	)
+}
+
+// getEnvGroupAgent returns a Helm agent for the namespace in the path of the request
+func (app *App) getEnvGroupAgent(
+	w http.ResponseWriter,
+	r *http.Request,
+) (*helm.Agent, *forms.ReleaseForm, error) {
+	namespace := chi.URLParam(r, "namespace")
+
+	form := &forms.ReleaseForm{
+		Form: &helm.Form{
+			Repo:              app.Repo,
+			DigitalOceanOAuth: app.DOConf,
+		},
+	}
+
+	agent, err := app.getAgentFromQueryParams(
+		w,
+		r,
+		form,
+		form.PopulateHelmOptionsFromQueryParams,
+		// the namespace in the path takes precedence over the query params
+		func(_ url.Values, _ repository.ClusterRepository) error {
+			form.Namespace = namespace
+			return nil
+		},
+	)
+
+	return agent, form, err
+}
+
+func getEnvGroupClusterID(r *http.Request) (uint, error) {
+	clusterID, err := strconv.ParseUint(r.URL.Query().Get("cluster_id"), 0, 64)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return uint(clusterID), nil
+}
+
+// envGroupReleaseVariables returns the variables that a version of an env group sets
+// on a release. Secret variables are set to a PORTERSECRET_ reference to the Secret of
+// the env group, matching the values stored in its ConfigMap.
+func envGroupReleaseVariables(group *models.EnvGroup, version *models.EnvGroupVersion) map[string]string {
+	res := make(map[string]string)
+
+	vars, secrets, err := version.GetVariables()
+
+	if err != nil {
+		return res
+	}
+
+	for key, val := range vars {
+		res[key] = val
+	}
+
+	for key := range secrets {
+		res[key] = fmt.Sprintf("PORTERSECRET_%s", group.Name)
+	}
+
+	return res
+}
+
+// setEnvGroupValues sets vars under container.env.normal in the values of a release,
+// and removes the keys of prevVars that are not in vars
+func setEnvGroupValues(values map[string]interface{}, prevVars, vars map[string]string) {
+	container, ok := values["container"].(map[string]interface{})
+
+	if !ok {
+		container = make(map[string]interface{})
+		values["container"] = container
+	}
+
+	env, ok := container["env"].(map[string]interface{})
+
+	if !ok {
+		env = make(map[string]interface{})
+		container["env"] = env
+	}
+
+	normal, ok := env["normal"].(map[string]interface{})
+
+	if !ok {
+		normal = make(map[string]interface{})
+		env["normal"] = normal
+	}
+
+	for key := range prevVars {
+		if _, ok := vars[key]; !ok {
+			delete(normal, key)
+		}
+	}
+
+	for key, val := range vars {
+		normal[key] = val
+	}
+}
+
+// applyEnvGroupVersion writes the variables of a version to the ConfigMap and Secret
+// of an env group, creating them if they do not exist. Keys that are not in the
+// version are removed.
+func applyEnvGroupVersion(
+	agent *kubernetes.Agent,
+	group *models.EnvGroup,
+	version *models.EnvGroupVersion,
+) error {
+	vars, secrets, err := version.GetVariables()
+
+	if err != nil {
+		return err
+	}
+
+	cm, err := agent.GetConfigMap(group.Name, group.Namespace)
+
+	if err != nil {
+		if !k8sErrors.IsNotFound(err) {
+			return err
+		}
+
+		_, err = createConfigMap(agent, &forms.ConfigMapForm{
+			Name:               group.Name,
+			Namespace:          group.Namespace,
+			EnvVariables:       vars,
+			SecretEnvVariables: secrets,
+		})
+
+		return err
+	}
+
+	secretRef := fmt.Sprintf("PORTERSECRET_%s", group.Name)
+	cmData := make(map[string]string)
+	secretData := make(map[string][]byte)
+
+	for key, val := range vars {
+		cmData[key] = val
+	}
+
+	for key, val := range secrets {
+		cmData[key] = secretRef
+		secretData[key] = []byte(val)
+	}
+
+	// empty values remove keys from the ConfigMap and Secret
+	for key, val := range cm.Data {
+		if _, ok := cmData[key]; !ok {
+			cmData[key] = ""
+		}
+
+		if _, ok := secrets[key]; !ok && val == secretRef {
+			secretData[key] = []byte{}
+		}
+	}
+
+	if len(secretData) > 0 {
+		err := agent.UpdateLinkedSecret(group.Name, group.Namespace, group.Name, secretData)
+
+		if err != nil && k8sErrors.IsNotFound(err) {
+			newSecretData := make(map[string][]byte)
+
+			for key, val := range secrets {
+				newSecretData[key] = []byte(val)
+			}
+
+			_, err = agent.CreateLinkedSecret(group.Name, group.Namespace, group.Name, newSecretData)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return agent.UpdateConfigMap(group.Name, group.Namespace, cmData)
+}
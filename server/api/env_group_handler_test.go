@@ -0,0 +1,193 @@
+package api_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/server/api"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ------------------------- TEST FIXTURES AND FUNCTIONS  ------------------------- //
+
+var envGroupQuery = url.Values{
+	"cluster_id": []string{"1"},
+	"storage":    []string{"memory"},
+}.Encode()
+
+var envGroupTests = []*projTest{
+	&projTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+			initEnvGroup,
+		},
+		msg:       "List env groups",
+		method:    "GET",
+		endpoint:  "/api/projects/1/k8s/default/env_groups?" + envGroupQuery,
+		body:      ``,
+		expStatus: http.StatusOK,
+		useCookie: true,
+		validators: []func(c *projTest, tester *tester, t *testing.T){
+			envGroupListValidator,
+		},
+	},
+	&projTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+			initEnvGroup,
+		},
+		msg:       "Get env group",
+		method:    "GET",
+		endpoint:  "/api/projects/1/k8s/default/env_groups/shared?" + envGroupQuery,
+		body:      ``,
+		expStatus: http.StatusOK,
+		useCookie: true,
+		validators: []func(c *projTest, tester *tester, t *testing.T){
+			envGroupVersionValidator(2, map[string]string{"A": "2"}),
+		},
+	},
+	&projTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+			initEnvGroup,
+		},
+		msg:       "Get missing env group",
+		method:    "GET",
+		endpoint:  "/api/projects/1/k8s/default/env_groups/missing?" + envGroupQuery,
+		body:      ``,
+		expStatus: http.StatusNotFound,
+		useCookie: true,
+	},
+	&projTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+			initEnvGroup,
+		},
+		msg:       "Rollback env group",
+		method:    "POST",
+		endpoint:  "/api/projects/1/k8s/default/env_groups/shared/rollback?" + envGroupQuery,
+		body:      `{"version":1}`,
+		expStatus: http.StatusOK,
+		useCookie: true,
+		validators: []func(c *projTest, tester *tester, t *testing.T){
+			envGroupVersionValidator(3, map[string]string{"A": "1", "B": "1"}),
+			envGroupConfigMapValidator(map[string]string{
+				"A":      "1",
+				"B":      "1",
+				"SECRET": "PORTERSECRET_shared",
+			}),
+		},
+	},
+	&projTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+			initEnvGroup,
+		},
+		msg:       "Rollback env group to missing version",
+		method:    "POST",
+		endpoint:  "/api/projects/1/k8s/default/env_groups/shared/rollback?" + envGroupQuery,
+		body:      `{"version":5}`,
+		expStatus: http.StatusNotFound,
+		useCookie: true,
+	},
+}
+
+func TestHandleEnvGroups(t *testing.T) {
+	testProjRequests(t, envGroupTests, true)
+}
+
+// ------------------------- INITIALIZERS AND VALIDATORS ------------------------- //
+
+func initEnvGroup(tester *tester) {
+	// the cluster is created directly, since the test agents are used
+	tester.repo.Cluster.CreateCluster(&models.Cluster{
+		ProjectID: 1,
+		Name:      "cluster-test",
+	})
+
+	group, _ := tester.repo.EnvGroup.CreateEnvGroup(&models.EnvGroup{
+		ProjectID: 1,
+		ClusterID: 1,
+		Namespace: "default",
+		Name:      "shared",
+		Version:   2,
+	})
+
+	for i, vars := range []map[string]string{{"A": "1", "B": "1"}, {"A": "2"}} {
+		version := &models.EnvGroupVersion{
+			EnvGroupID: group.ID,
+			Version:    uint(i + 1),
+		}
+
+		version.SetVariables(vars, map[string]string{"SECRET": "hidden"})
+		tester.repo.EnvGroup.CreateEnvGroupVersion(version)
+	}
+}
+
+func envGroupListValidator(c *projTest, tester *tester, t *testing.T) {
+	gotBody := make([]*models.EnvGroupExternal, 0)
+
+	json.Unmarshal(tester.rr.Body.Bytes(), &gotBody)
+
+	if len(gotBody) != 1 || gotBody[0].Name != "shared" || gotBody[0].Version != 2 {
+		t.Errorf("%s, incorrect env groups: got %v", c.msg, gotBody)
+	}
+}
+
+func envGroupVersionValidator(
+	version uint,
+	vars map[string]string,
+) func(c *projTest, tester *tester, t *testing.T) {
+	return func(c *projTest, tester *tester, t *testing.T) {
+		gotBody := &api.EnvGroupResponse{}
+
+		json.Unmarshal(tester.rr.Body.Bytes(), gotBody)
+
+		if gotBody.CurrentVersion == nil || gotBody.EnvGroupExternal == nil {
+			t.Fatalf("%s, missing env group in response", c.msg)
+		}
+
+		if gotBody.Version != version || gotBody.CurrentVersion.Version != version {
+			t.Errorf("%s, incorrect version: expected %d, got %d", c.msg, version, gotBody.Version)
+		}
+
+		if diff := deep.Equal(gotBody.CurrentVersion.Variables, vars); diff != nil {
+			t.Errorf("%s, incorrect variables:\n%v", c.msg, diff)
+		}
+
+		// secret values should not be returned
+		if diff := deep.Equal(gotBody.CurrentVersion.SecretKeys, []string{"SECRET"}); diff != nil {
+			t.Errorf("%s, incorrect secret keys:\n%v", c.msg, diff)
+		}
+	}
+}
+
+func envGroupConfigMapValidator(
+	data map[string]string,
+) func(c *projTest, tester *tester, t *testing.T) {
+	return func(c *projTest, tester *tester, t *testing.T) {
+		cm, err := tester.app.TestAgents.K8sAgent.Clientset.CoreV1().ConfigMaps("default").Get(
+			context.TODO(),
+			"shared",
+			metav1.GetOptions{},
+		)
+
+		if err != nil {
+			t.Fatalf("%s, %v", c.msg, err)
+		}
+
+		if diff := deep.Equal(cm.Data, data); diff != nil {
+			t.Errorf("%s, incorrect configmap data:\n%v", c.msg, diff)
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/porteryaml"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+)
+
+// HandleExportNamespace returns the releases of Porter application templates in a
+// namespace as a porter.yaml config, along with their linked env groups and the
+// settings of the Github actions that build them
+func (app *App) HandleExportNamespace(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	namespace := chi.URLParam(r, "namespace")
+
+	form := &forms.ReleaseForm{
+		Form: &helm.Form{
+			Repo:              app.Repo,
+			DigitalOceanOAuth: app.DOConf,
+		},
+	}
+
+	agent, err := app.getAgentFromQueryParams(
+		w,
+		r,
+		form,
+		form.PopulateHelmOptionsFromQueryParams,
+		// the namespace in the path takes precedence over the query params
+		func(_ url.Values, _ repository.ClusterRepository) error {
+			form.Namespace = namespace
+			return nil
+		},
+	)
+
+	// errors are handled in app.getAgentFromQueryParams
+	if err != nil {
+		return
+	}
+
+	clusterID := form.Cluster.ID
+
+	releases, err := agent.ListReleases(namespace, &helm.ListFilter{
+		Namespace:    namespace,
+		StatusFilter: graphReleaseStatuses,
+	})
+
+	if err != nil {
+		app.handleErrorRead(err, ErrReleaseReadData, w)
+		return
+	}
+
+	groups, err := app.Repo.EnvGroup.ListEnvGroups(clusterID, namespace)
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	exports := make([]*porteryaml.ExportRelease, 0, len(releases))
+
+	for _, rel := range releases {
+		export := &porteryaml.ExportRelease{
+			Release:           rel,
+			EnvGroups:         make([]string, 0),
+			EnvGroupVariables: make(map[string]string),
+		}
+
+		for _, group := range groups {
+			if !group.HasRelease(rel.Name) {
+				continue
+			}
+
+			version, err := app.Repo.EnvGroup.ReadEnvGroupVersion(group.ID, group.Version)
+
+			if err != nil {
+				app.handleErrorDataRead(err, w)
+				return
+			}
+
+			export.EnvGroups = append(export.EnvGroups, group.Name)
+
+			for key, val := range envGroupReleaseVariables(group, version) {
+				export.EnvGroupVariables[key] = val
+			}
+		}
+
+		dbRelease, err := app.Repo.Release.ReadRelease(clusterID, rel.Name, rel.Namespace)
+
+		if err != nil && err != gorm.ErrRecordNotFound {
+			app.handleErrorDataRead(err, w)
+			return
+		}
+
+		if dbRelease != nil && dbRelease.GitActionConfig.ID != 0 {
+			export.GitActionConfig = &dbRelease.GitActionConfig
+		}
+
+		exports = append(exports, export)
+	}
+
+	conf := porteryaml.Export(uint(projID), clusterID, namespace, exports)
+
+	if err := json.NewEncoder(w).Encode(conf); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
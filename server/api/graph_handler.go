@@ -0,0 +1,81 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/url"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/helm/grapher"
+	"github.com/porter-dev/porter/internal/repository"
+)
+
+// graphReleaseStatuses are the states of the releases that are included in a
+// dependency graph: uninstalled and superseded revisions are left out
+var graphReleaseStatuses = []string{
+	"deployed",
+	"failed",
+	"pending-install",
+	"pending-upgrade",
+	"pending-rollback",
+}
+
+// HandleGetNamespaceGraph returns the dependencies between the releases of a
+// namespace, such as Services that a release reads from env vars, Ingress backends,
+// and ConfigMaps, Secrets and PVCs that are shared between releases
+func (app *App) HandleGetNamespaceGraph(w http.ResponseWriter, r *http.Request) {
+	namespace := chi.URLParam(r, "namespace")
+
+	form := &forms.ReleaseForm{
+		Form: &helm.Form{
+			Repo:              app.Repo,
+			DigitalOceanOAuth: app.DOConf,
+		},
+	}
+
+	agent, err := app.getAgentFromQueryParams(
+		w,
+		r,
+		form,
+		form.PopulateHelmOptionsFromQueryParams,
+		// the namespace in the path takes precedence over the query params
+		func(_ url.Values, _ repository.ClusterRepository) error {
+			form.Namespace = namespace
+			return nil
+		},
+	)
+
+	// errors are handled in app.getAgentFromQueryParams
+	if err != nil {
+		return
+	}
+
+	releases, err := agent.ListReleases(namespace, &helm.ListFilter{
+		Namespace:    namespace,
+		StatusFilter: graphReleaseStatuses,
+	})
+
+	if err != nil {
+		app.handleErrorRead(err, ErrReleaseReadData, w)
+		return
+	}
+
+	manifests := make([]grapher.ReleaseManifest, 0, len(releases))
+
+	for _, release := range releases {
+		manifests = append(manifests, grapher.ReleaseManifest{
+			Name:      release.Name,
+			Namespace: release.Namespace,
+			Manifest:  release.Manifest,
+		})
+	}
+
+	graph := grapher.GetDependencyGraph(namespace, manifests)
+
+	if err := json.NewEncoder(w).Encode(graph); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
@@ -1,6 +1,7 @@
 package api
 
 import (
+	"fmt"
 	"net/http"
 )
 
@@ -31,6 +32,34 @@ func (app *App) HandleReady(w http.ResponseWriter, r *http.Request) {
 	writeHealthy(w)
 }
 
+// HandleMetrics writes the counters of the cluster connection pool in the
+// Prometheus text format
+func (app *App) HandleMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+	w.WriteHeader(http.StatusOK)
+
+	if app.AgentPool == nil {
+		return
+	}
+
+	stats := app.AgentPool.Stats()
+
+	metrics := []struct {
+		name, kind, help string
+		value            uint64
+	}{
+		{"porter_agent_pool_size", "gauge", "Number of cluster connections in the pool.", uint64(stats.Size)},
+		{"porter_agent_pool_hits_total", "counter", "Lookups that were served from the pool.", stats.Hits},
+		{"porter_agent_pool_misses_total", "counter", "Lookups that created a new connection.", stats.Misses},
+		{"porter_agent_pool_evictions_total", "counter", "Connections that were removed after they expired.", stats.Evictions},
+		{"porter_agent_pool_invalidations_total", "counter", "Connections that were removed after the credentials of their cluster changed.", stats.Invalidations},
+	}
+
+	for _, m := range metrics {
+		fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n%s %d\n", m.name, m.help, m.name, m.kind, m.name, m.value)
+	}
+}
+
 func writeHealthy(w http.ResponseWriter) {
 	w.Header().Set("Content-Type", "text/plain")
 	w.WriteHeader(http.StatusOK)
@@ -0,0 +1,67 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/registry"
+)
+
+// HandleUpdateImageDigestPinning enables or disables the pinning of the images of a
+// release to their digests. When enabled, the tag of each image that is deployed by
+// the deploy webhook or a batch image update is resolved to its digest through the
+// registry API, and the release is upgraded to <tag>@<digest>.
+func (app *App) HandleUpdateImageDigestPinning(w http.ResponseWriter, r *http.Request) {
+	release, ok := app.readReleaseFromQueryParams(w, r, true)
+
+	if !ok {
+		return
+	}
+
+	form := &forms.UpdateImageDigestPinningForm{}
+
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	release.PinImageDigest = form.Enabled
+
+	release, err := app.Repo.Release.UpdateRelease(release)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(release.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// resolveImageDigest resolves a tag of an image repository to its digest, using
+// the registry of the project that the repository is pulled from
+func (app *App) resolveImageDigest(
+	registries []*models.Registry,
+	imageRepo, tag string,
+) (string, error) {
+	reg, repoName := registry.FindImageRegistry(registries, imageRepo)
+
+	if reg == nil {
+		return "", fmt.Errorf("image repository %s does not belong to a registry of the project", imageRepo)
+	}
+
+	digest, err := reg.GetImageDigest(repoName, tag, *app.Repo, app.DOConf)
+
+	if err != nil {
+		return "", fmt.Errorf("could not resolve the digest of %s:%s: %v", imageRepo, tag, err)
+	}
+
+	return digest, nil
+}
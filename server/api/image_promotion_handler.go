@@ -0,0 +1,321 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/helm/diff"
+	"github.com/porter-dev/porter/internal/integrations/slack"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/registry"
+	mw "github.com/porter-dev/porter/server/middleware"
+)
+
+// PromoteImageResponse is the image that was promoted, along with the revision of
+// the release that was upgraded to it
+type PromoteImageResponse struct {
+	// Image is the promoted image, pinned to its digest
+	Image    string `json:"image"`
+	Digest   string `json:"digest"`
+	Tag      string `json:"tag,omitempty"`
+	Revision int    `json:"revision,omitempty"`
+}
+
+// HandlePromoteImage copies an image from a repository of the registry in the URL
+// to a repository of another registry of the project, with every platform of a
+// manifest list. The image keeps its digest, so the same image that was tested is
+// deployed. If a release is set, it is upgraded to the promoted image.
+func (app *App) HandlePromoteImage(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	regID, err := strconv.ParseUint(chi.URLParam(r, "registry_id"), 0, 64)
+
+	if err != nil || regID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	form := &forms.PromoteImageForm{}
+
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	mw.SetAuditTarget(r, "repository_name", form.RepositoryName)
+	mw.SetAuditTarget(r, "target_registry_id", strconv.FormatUint(uint64(form.TargetRegistryID), 10))
+
+	if form.Release != nil {
+		mw.SetAuditTarget(r, "cluster_id", strconv.FormatUint(uint64(form.Release.ClusterID), 10))
+		mw.SetAuditTarget(r, "namespace", form.Release.Namespace)
+		mw.SetAuditTarget(r, "release_name", form.Release.Name)
+	}
+
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrProjectValidateFields, w)
+		return
+	}
+
+	if !form.IsValidDigest() {
+		app.sendExternalError(fmt.Errorf("invalid digest"), http.StatusBadRequest, HTTPError{
+			Code:   ErrProjectValidateFields,
+			Errors: []string{"digest must be of the form sha256:<hex>"},
+		}, w)
+
+		return
+	}
+
+	srcReg, err := app.Repo.Registry.ReadRegistry(uint(regID))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return
+	}
+
+	// the target registry must belong to the same project as the source registry
+	targetReg, err := app.Repo.Registry.ReadRegistry(form.TargetRegistryID)
+
+	if err != nil || targetReg.ProjectID != uint(projID) {
+		app.sendExternalError(fmt.Errorf("target registry not found"), http.StatusNotFound, HTTPError{
+			Code:   ErrProjectDataRead,
+			Errors: []string{"target registry not found"},
+		}, w)
+
+		return
+	}
+
+	var dbRelease *models.Release
+	var cluster *models.Cluster
+
+	// the release is checked before the image is copied, so that a release that
+	// cannot be upgraded does not leave a promoted image behind
+	if form.Release != nil {
+		cluster, err = app.Repo.Cluster.ReadCluster(form.Release.ClusterID)
+
+		if err != nil || cluster.ProjectID != uint(projID) {
+			app.sendExternalError(fmt.Errorf("cluster not found"), http.StatusNotFound, HTTPError{
+				Code:   ErrReleaseReadData,
+				Errors: []string{"cluster not found"},
+			}, w)
+
+			return
+		}
+
+		// the route is only scoped to the project, so the release is checked against
+		// the policy of the user here
+		if !mw.HasReleaseAccess(r, cluster.ID, form.Release.Namespace, form.Release.Name) {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		// releases that are not tracked in the database can still be upgraded
+		dbRelease, _ = app.Repo.Release.ReadRelease(cluster.ID, form.Release.Name, form.Release.Namespace)
+	}
+
+	_srcReg := registry.Registry(*srcReg)
+	_targetReg := registry.Registry(*targetReg)
+
+	targetRepo := form.GetTargetRepositoryName()
+	targetTag := form.GetTargetTag()
+
+	digest, err := _srcReg.CopyImage(&_targetReg, &registry.CopyImageOpts{
+		SourceRepository: form.RepositoryName,
+		Reference:        form.Reference(),
+		TargetRepository: targetRepo,
+		TargetTag:        targetTag,
+	}, *app.Repo, app.DOConf)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrProjectDataRead,
+			Errors: []string{fmt.Sprintf("could not promote image: %v", err)},
+		}, w)
+
+		return
+	}
+
+	imageRepo := _targetReg.GetRepositoryURI(targetRepo)
+
+	resp := &PromoteImageResponse{
+		Image:  imageRepo + "@" + digest,
+		Digest: digest,
+		Tag:    targetTag,
+	}
+
+	if form.Release != nil {
+		revision, ok := app.upgradeReleaseToPromotedImage(w, r, form.Release, cluster, dbRelease, imageRepo, targetTag, digest)
+
+		if !ok {
+			return
+		}
+
+		resp.Revision = revision
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(resp); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// upgradeReleaseToPromotedImage upgrades a release to an image that was promoted,
+// pinning the tag of the image to its digest
+func (app *App) upgradeReleaseToPromotedImage(
+	w http.ResponseWriter,
+	r *http.Request,
+	releaseForm *forms.PromoteImageReleaseForm,
+	cluster *models.Cluster,
+	dbRelease *models.Release,
+	imageRepo, tag, digest string,
+) (int, bool) {
+	form := &forms.ReleaseForm{
+		Form: &helm.Form{
+			Repo:              app.Repo,
+			DigitalOceanOAuth: app.DOConf,
+		},
+	}
+
+	form.PopulateHelmOptionsFromQueryParams(url.Values{
+		"cluster_id": []string{fmt.Sprintf("%d", cluster.ID)},
+		"namespace":  []string{releaseForm.Namespace},
+	}, app.Repo.Cluster)
+
+	agent, err := app.getAgentFromReleaseForm(w, r, form)
+
+	// errors are handled in app.getAgentFromReleaseForm
+	if err != nil {
+		return 0, false
+	}
+
+	rel, err := agent.GetRelease(releaseForm.Name, 0, false)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusNotFound, HTTPError{
+			Code:   ErrReleaseReadData,
+			Errors: []string{"release not found"},
+		}, w)
+
+		return 0, false
+	}
+
+	prevValues := diff.CopyValues(rel.Config)
+	prevRevision := rel.Version
+
+	image, ok := rel.Config["image"].(map[string]interface{})
+
+	if !ok {
+		image = make(map[string]interface{})
+	}
+
+	image["repository"] = imageRepo
+	image["tag"] = (&forms.DeployWebhookForm{Tag: tag, Digest: digest}).ImageTag()
+	rel.Config["image"] = image
+
+	registries, err := app.Repo.Registry.ListRegistriesByProjectID(cluster.ProjectID)
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return 0, false
+	}
+
+	conf := &helm.UpgradeReleaseConfig{
+		Name:       rel.Name,
+		Cluster:    cluster,
+		Repo:       *app.Repo,
+		Registries: registries,
+		Values:     rel.Config,
+	}
+
+	notifyOpts := &slack.NotifyOpts{
+		ProjectID:   cluster.ProjectID,
+		ClusterID:   cluster.ID,
+		ClusterName: cluster.Name,
+		Name:        rel.Name,
+		Namespace:   rel.Namespace,
+		Trigger:     models.NotificationTriggerManual,
+		URL: fmt.Sprintf(
+			"%s/applications/%s/%s/%s",
+			app.ServerConf.ServerURL,
+			url.PathEscape(cluster.Name),
+			rel.Namespace,
+			rel.Name,
+		) + fmt.Sprintf("?project_id=%d", cluster.ProjectID),
+	}
+
+	deployment := &models.Deployment{
+		ProjectID: cluster.ProjectID,
+		ClusterID: cluster.ID,
+		Namespace: rel.Namespace,
+		Name:      rel.Name,
+		Trigger:   models.DeploymentTriggerPromotion,
+	}
+
+	app.setDeploymentActor(deployment, r)
+
+	if err := app.checkUpgradeVulnerabilityPolicy(r.Context(), dbRelease, registries, conf.Values); err != nil {
+		notifyOpts.Status = slack.StatusFailed
+		notifyOpts.Info = err.Error()
+
+		app.notifyRelease(dbRelease, cluster.ProjectID, notifyOpts)
+
+		deployment.Status = models.DeploymentStatusFailed
+		deployment.Info = err.Error()
+
+		app.recordDeployment(deployment, prevValues, conf.Values)
+
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseDeploy,
+			Errors: []string{fmt.Sprintf("image was promoted, but the release was not upgraded: %v", err)},
+		}, w)
+
+		return 0, false
+	}
+
+	rel, err = agent.UpgradeReleaseByValues(conf, app.DOConf)
+
+	if err != nil {
+		notifyOpts.Status = slack.StatusFailed
+		notifyOpts.Info = err.Error()
+
+		app.notifyRelease(dbRelease, cluster.ProjectID, notifyOpts)
+
+		deployment.Status = models.DeploymentStatusFailed
+		deployment.Info = err.Error()
+
+		app.recordDeployment(deployment, prevValues, conf.Values)
+
+		app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
+			Code:   ErrReleaseDeploy,
+			Errors: []string{fmt.Sprintf("image was promoted, but the release could not be upgraded: %v", err)},
+		}, w)
+
+		return 0, false
+	}
+
+	notifyOpts.Status = string(rel.Info.Status)
+	notifyOpts.Version = rel.Version
+
+	app.notifyRelease(dbRelease, cluster.ProjectID, notifyOpts)
+
+	deployment.Revision = rel.Version
+	deployment.Status = string(rel.Info.Status)
+
+	app.recordDeployment(deployment, prevValues, rel.Config)
+
+	go app.watchAutoRollback(dbRelease, *form.Form, rel, prevRevision, *notifyOpts)
+
+	return rel.Version, true
+}
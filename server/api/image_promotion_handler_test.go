@@ -0,0 +1,54 @@
+package api_test
+
+import (
+	"net/http"
+	"testing"
+)
+
+// ------------------------- TEST FIXTURES AND FUNCTIONS  ------------------------- //
+
+var promoteImageTests = []*regTest{
+	&regTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+			initRegistry,
+		},
+		msg:       "Promote image without a digest or tag",
+		method:    "POST",
+		endpoint:  "/api/projects/1/registries/1/promote",
+		body:      `{"repository_name":"web","target_registry_id":1}`,
+		expStatus: http.StatusUnprocessableEntity,
+		useCookie: true,
+	},
+	&regTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+			initRegistry,
+		},
+		msg:       "Promote image with an invalid digest",
+		method:    "POST",
+		endpoint:  "/api/projects/1/registries/1/promote",
+		body:      `{"repository_name":"web","digest":"sha256:abc","target_registry_id":1}`,
+		expStatus: http.StatusBadRequest,
+		useCookie: true,
+	},
+	&regTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+			initRegistry,
+		},
+		msg:       "Promote image to a registry that does not exist",
+		method:    "POST",
+		endpoint:  "/api/projects/1/registries/1/promote",
+		body:      `{"repository_name":"web","tag":"v1","target_registry_id":2}`,
+		expStatus: http.StatusNotFound,
+		useCookie: true,
+	},
+}
+
+func TestHandlePromoteImage(t *testing.T) {
+	testRegistryRequests(t, promoteImageTests, true)
+}
@@ -18,7 +18,9 @@ import (
 	"github.com/google/go-github/github"
 	"github.com/porter-dev/porter/internal/analytics"
 	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/models"
 	"github.com/porter-dev/porter/internal/oauth"
+	"github.com/porter-dev/porter/internal/repository"
 	"golang.org/x/oauth2"
 	"gorm.io/gorm"
 
@@ -288,6 +290,16 @@ func (app *App) HandleOverwriteAWSIntegration(w http.ResponseWriter, r *http.Req
 		}
 	}
 
+	// change the credential version of clusters that authenticate with this
+	// integration, so that their pooled connections are dropped
+	err = repository.TouchClusters(app.Repo.Cluster, uint(projID), func(cluster *models.Cluster) bool {
+		return cluster.AWSIntegrationID == awsIntegration.ID
+	})
+
+	if err != nil {
+		app.Logger.Warn().Err(err).Msgf("could not update clusters of AWS integration %d", awsIntegration.ID)
+	}
+
 	app.Logger.Info().Msgf("AWS integration overwritten: %d", awsIntegration.ID)
 
 	w.WriteHeader(http.StatusCreated)
@@ -459,6 +471,16 @@ func (app *App) HandleGithubAppEvent(w http.ResponseWriter, r *http.Request) {
 				return
 			}
 		}
+	case *github.PullRequestEvent:
+		// preview environments are deployed in the background, since installing a
+		// release can take longer than GitHub waits for a response
+		go app.handlePreviewPullRequest(e.GetAction(), e.GetRepo().GetFullName(), &previewPullRequest{
+			Number:   e.GetNumber(),
+			BaseRef:  e.GetPullRequest().GetBase().GetRef(),
+			HeadRef:  e.GetPullRequest().GetHead().GetRef(),
+			HeadSHA:  e.GetPullRequest().GetHead().GetSHA(),
+			HeadRepo: e.GetPullRequest().GetHead().GetRepo().GetFullName(),
+		})
 	}
 
 }
@@ -101,7 +101,7 @@ var listRegistryIntegrationsTests = []*publicIntTest{
 		endpoint:  "/api/integrations/registry",
 		body:      ``,
 		expStatus: http.StatusOK,
-		expBody:   `[{"auth_mechanism":"gcp","category":"registry","service":"gcr"},{"auth_mechanism":"aws","category":"registry","service":"ecr"},{"auth_mechanism":"oauth","category":"registry","service":"docker"}]`,
+		expBody:   `[{"auth_mechanism":"gcp","category":"registry","service":"gcr"},{"auth_mechanism":"aws","category":"registry","service":"ecr"},{"auth_mechanism":"oauth","category":"registry","service":"docker"},{"auth_mechanism":"basic","category":"registry","service":"ghcr"},{"auth_mechanism":"basic","category":"registry","service":"acr"},{"auth_mechanism":"basic","category":"registry","service":"quay"},{"auth_mechanism":"basic","category":"registry","service":"harbor"}]`,
 		useCookie: true,
 		validators: []func(c *publicIntTest, tester *tester, t *testing.T){
 			publicIntBodyValidator,
@@ -38,6 +38,11 @@ func (app *App) HandleCreateInvite(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := app.validateRolePolicy(uint(projID), form.Kind, form.PolicyID); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
 	// convert the form to an invite
 	invite, err := form.ToInvite()
 
@@ -122,7 +127,13 @@ func (app *App) HandleUpdateInviteRole(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if err := app.validateRolePolicy(invite.ProjectID, form.Kind, form.PolicyID); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
 	invite.Kind = form.Kind
+	invite.PolicyID = form.PolicyID
 
 	invite, err = app.Repo.Invite.UpdateInvite(invite)
 
@@ -214,6 +225,7 @@ func (app *App) HandleAcceptInvite(w http.ResponseWriter, r *http.Request) {
 		UserID:    userID,
 		ProjectID: uint(projID),
 		Kind:      kind,
+		PolicyID:  invite.PolicyID,
 	})
 
 	if err != nil {
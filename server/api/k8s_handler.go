@@ -61,7 +61,7 @@ func (app *App) HandleListNamespaces(w http.ResponseWriter, r *http.Request) {
 	if app.ServerConf.IsTesting {
 		agent = app.TestAgents.K8sAgent
 	} else {
-		agent, err = kubernetes.GetAgentOutOfClusterConfig(form.OutOfClusterConfig)
+		agent, err = app.AgentPool.GetAgent(form.OutOfClusterConfig)
 	}
 
 	namespaces, err := agent.ListNamespaces()
@@ -108,7 +108,7 @@ func (app *App) HandleCreateNamespace(w http.ResponseWriter, r *http.Request) {
 	if app.ServerConf.IsTesting {
 		agent = app.TestAgents.K8sAgent
 	} else {
-		agent, err = kubernetes.GetAgentOutOfClusterConfig(form.OutOfClusterConfig)
+		agent, err = app.AgentPool.GetAgent(form.OutOfClusterConfig)
 	}
 
 	ns := &forms.NamespaceForm{}
@@ -164,7 +164,7 @@ func (app *App) HandleDeleteNamespace(w http.ResponseWriter, r *http.Request) {
 	if app.ServerConf.IsTesting {
 		agent = app.TestAgents.K8sAgent
 	} else {
-		agent, err = kubernetes.GetAgentOutOfClusterConfig(form.OutOfClusterConfig)
+		agent, err = app.AgentPool.GetAgent(form.OutOfClusterConfig)
 	}
 
 	if err != nil {
@@ -222,7 +222,7 @@ func (app *App) HandleListPodEvents(w http.ResponseWriter, r *http.Request) {
 	if app.ServerConf.IsTesting {
 		agent = app.TestAgents.K8sAgent
 	} else {
-		agent, err = kubernetes.GetAgentOutOfClusterConfig(form.OutOfClusterConfig)
+		agent, err = app.AgentPool.GetAgent(form.OutOfClusterConfig)
 	}
 
 	events, err := agent.ListEvents(name, namespace)
@@ -295,7 +295,7 @@ func (app *App) HandleCreateConfigMap(w http.ResponseWriter, r *http.Request) {
 	if app.ServerConf.IsTesting {
 		agent = app.TestAgents.K8sAgent
 	} else {
-		agent, err = kubernetes.GetAgentOutOfClusterConfig(form.OutOfClusterConfig)
+		agent, err = app.AgentPool.GetAgent(form.OutOfClusterConfig)
 	}
 
 	configMapForm := &forms.ConfigMapForm{}
@@ -350,7 +350,7 @@ func (app *App) HandleListConfigMaps(w http.ResponseWriter, r *http.Request) {
 	if app.ServerConf.IsTesting {
 		agent = app.TestAgents.K8sAgent
 	} else {
-		agent, err = kubernetes.GetAgentOutOfClusterConfig(form.OutOfClusterConfig)
+		agent, err = app.AgentPool.GetAgent(form.OutOfClusterConfig)
 	}
 
 	configMaps, err := agent.ListConfigMaps(vals["namespace"][0])
@@ -400,7 +400,7 @@ func (app *App) HandleGetConfigMap(w http.ResponseWriter, r *http.Request) {
 	if app.ServerConf.IsTesting {
 		agent = app.TestAgents.K8sAgent
 	} else {
-		agent, err = kubernetes.GetAgentOutOfClusterConfig(form.OutOfClusterConfig)
+		agent, err = app.AgentPool.GetAgent(form.OutOfClusterConfig)
 	}
 
 	configMap, err := agent.GetConfigMap(vals["name"][0], vals["namespace"][0])
@@ -461,7 +461,7 @@ func (app *App) HandleDeleteConfigMap(w http.ResponseWriter, r *http.Request) {
 	if app.ServerConf.IsTesting {
 		agent = app.TestAgents.K8sAgent
 	} else {
-		agent, err = kubernetes.GetAgentOutOfClusterConfig(form.OutOfClusterConfig)
+		agent, err = app.AgentPool.GetAgent(form.OutOfClusterConfig)
 	}
 
 	if err := deleteConfigMap(agent, vals["name"][0], vals["namespace"][0]); err != nil {
@@ -504,7 +504,7 @@ func (app *App) HandleUpdateConfigMap(w http.ResponseWriter, r *http.Request) {
 	if app.ServerConf.IsTesting {
 		agent = app.TestAgents.K8sAgent
 	} else {
-		agent, err = kubernetes.GetAgentOutOfClusterConfig(form.OutOfClusterConfig)
+		agent, err = app.AgentPool.GetAgent(form.OutOfClusterConfig)
 	}
 
 	configMap := &forms.ConfigMapForm{}
@@ -592,7 +592,7 @@ func (app *App) HandleRenameConfigMap(w http.ResponseWriter, r *http.Request) {
 	if app.ServerConf.IsTesting {
 		agent = app.TestAgents.K8sAgent
 	} else {
-		agent, err = kubernetes.GetAgentOutOfClusterConfig(form.OutOfClusterConfig)
+		agent, err = app.AgentPool.GetAgent(form.OutOfClusterConfig)
 	}
 
 	renameConfigMapForm := &forms.RenameConfigMapForm{}
@@ -697,7 +697,7 @@ func (app *App) HandleGetPodLogs(w http.ResponseWriter, r *http.Request) {
 	if app.ServerConf.IsTesting {
 		agent = app.TestAgents.K8sAgent
 	} else {
-		agent, err = kubernetes.GetAgentOutOfClusterConfig(form.OutOfClusterConfig)
+		agent, err = app.AgentPool.GetAgent(form.OutOfClusterConfig)
 	}
 
 	upgrader.CheckOrigin = func(r *http.Request) bool { return true }
@@ -752,7 +752,7 @@ func (app *App) HandleDeletePod(w http.ResponseWriter, r *http.Request) {
 	if app.ServerConf.IsTesting {
 		agent = app.TestAgents.K8sAgent
 	} else {
-		agent, err = kubernetes.GetAgentOutOfClusterConfig(form.OutOfClusterConfig)
+		agent, err = app.AgentPool.GetAgent(form.OutOfClusterConfig)
 	}
 
 	err = agent.DeletePod(namespace, name)
@@ -810,7 +810,7 @@ func (app *App) HandleGetIngress(w http.ResponseWriter, r *http.Request) {
 	if app.ServerConf.IsTesting {
 		agent = app.TestAgents.K8sAgent
 	} else {
-		agent, err = kubernetes.GetAgentOutOfClusterConfig(form.OutOfClusterConfig)
+		agent, err = app.AgentPool.GetAgent(form.OutOfClusterConfig)
 	}
 
 	ingress, err := agent.GetIngress(namespace, name)
@@ -867,7 +867,7 @@ func (app *App) HandleListPods(w http.ResponseWriter, r *http.Request) {
 	if app.ServerConf.IsTesting {
 		agent = app.TestAgents.K8sAgent
 	} else {
-		agent, err = kubernetes.GetAgentOutOfClusterConfig(form.OutOfClusterConfig)
+		agent, err = app.AgentPool.GetAgent(form.OutOfClusterConfig)
 	}
 
 	namespace := vals.Get("namespace")
@@ -927,7 +927,7 @@ func (app *App) HandleListJobsByChart(w http.ResponseWriter, r *http.Request) {
 	if app.ServerConf.IsTesting {
 		agent = app.TestAgents.K8sAgent
 	} else {
-		agent, err = kubernetes.GetAgentOutOfClusterConfig(form.OutOfClusterConfig)
+		agent, err = app.AgentPool.GetAgent(form.OutOfClusterConfig)
 	}
 
 	jobs, err := agent.ListJobsByLabel(namespace, kubernetes.Label{
@@ -983,7 +983,7 @@ func (app *App) HandleDeleteJob(w http.ResponseWriter, r *http.Request) {
 	if app.ServerConf.IsTesting {
 		agent = app.TestAgents.K8sAgent
 	} else {
-		agent, err = kubernetes.GetAgentOutOfClusterConfig(form.OutOfClusterConfig)
+		agent, err = app.AgentPool.GetAgent(form.OutOfClusterConfig)
 	}
 
 	err = agent.DeleteJob(name, namespace)
@@ -1032,7 +1032,7 @@ func (app *App) HandleStopJob(w http.ResponseWriter, r *http.Request) {
 	if app.ServerConf.IsTesting {
 		agent = app.TestAgents.K8sAgent
 	} else {
-		agent, err = kubernetes.GetAgentOutOfClusterConfig(form.OutOfClusterConfig)
+		agent, err = app.AgentPool.GetAgent(form.OutOfClusterConfig)
 	}
 
 	err = agent.StopJobWithJobSidecar(namespace, name)
@@ -1084,7 +1084,7 @@ func (app *App) HandleListJobPods(w http.ResponseWriter, r *http.Request) {
 	if app.ServerConf.IsTesting {
 		agent = app.TestAgents.K8sAgent
 	} else {
-		agent, err = kubernetes.GetAgentOutOfClusterConfig(form.OutOfClusterConfig)
+		agent, err = app.AgentPool.GetAgent(form.OutOfClusterConfig)
 	}
 
 	pods, err := agent.GetJobPods(namespace, name)
@@ -1140,7 +1140,7 @@ func (app *App) HandleStreamControllerStatus(w http.ResponseWriter, r *http.Requ
 	if app.ServerConf.IsTesting {
 		agent = app.TestAgents.K8sAgent
 	} else {
-		agent, err = kubernetes.GetAgentOutOfClusterConfig(form.OutOfClusterConfig)
+		agent, err = app.AgentPool.GetAgent(form.OutOfClusterConfig)
 	}
 
 	upgrader.CheckOrigin = func(r *http.Request) bool { return true }
@@ -1205,7 +1205,7 @@ func (app *App) HandleStreamHelmReleases(w http.ResponseWriter, r *http.Request)
 	if app.ServerConf.IsTesting {
 		agent = app.TestAgents.K8sAgent
 	} else {
-		agent, err = kubernetes.GetAgentOutOfClusterConfig(form.OutOfClusterConfig)
+		agent, err = app.AgentPool.GetAgent(form.OutOfClusterConfig)
 	}
 
 	upgrader.CheckOrigin = func(r *http.Request) bool { return true }
@@ -1272,7 +1272,7 @@ func (app *App) HandleDetectPrometheusInstalled(w http.ResponseWriter, r *http.R
 	if app.ServerConf.IsTesting {
 		agent = app.TestAgents.K8sAgent
 	} else {
-		agent, err = kubernetes.GetAgentOutOfClusterConfig(form.OutOfClusterConfig)
+		agent, err = app.AgentPool.GetAgent(form.OutOfClusterConfig)
 	}
 
 	// detect prometheus service
@@ -1318,7 +1318,7 @@ func (app *App) HandleListNGINXIngresses(w http.ResponseWriter, r *http.Request)
 	if app.ServerConf.IsTesting {
 		agent = app.TestAgents.K8sAgent
 	} else {
-		agent, err = kubernetes.GetAgentOutOfClusterConfig(form.OutOfClusterConfig)
+		agent, err = app.AgentPool.GetAgent(form.OutOfClusterConfig)
 	}
 
 	res, err := prometheus.GetIngressesWithNGINXAnnotation(agent.Clientset)
@@ -1378,7 +1378,7 @@ func (app *App) HandleGetPodMetrics(w http.ResponseWriter, r *http.Request) {
 	if app.ServerConf.IsTesting {
 		agent = app.TestAgents.K8sAgent
 	} else {
-		agent, err = kubernetes.GetAgentOutOfClusterConfig(form.OutOfClusterConfig)
+		agent, err = app.AgentPool.GetAgent(form.OutOfClusterConfig)
 	}
 
 	// get prometheus service
@@ -1473,7 +1473,7 @@ func (app *App) HandleListNodes(w http.ResponseWriter, r *http.Request) {
 	if app.ServerConf.IsTesting {
 		agent = app.TestAgents.K8sAgent
 	} else {
-		agent, _ = kubernetes.GetAgentOutOfClusterConfig(form.OutOfClusterConfig)
+		agent, _ = app.AgentPool.GetAgent(form.OutOfClusterConfig)
 	}
 
 	nodeWithUsageList := nodes.GetNodesUsage(agent.Clientset)
@@ -1515,7 +1515,7 @@ func (app *App) HandleGetNode(w http.ResponseWriter, r *http.Request) {
 	if app.ServerConf.IsTesting {
 		agent = app.TestAgents.K8sAgent
 	} else {
-		agent, _ = kubernetes.GetAgentOutOfClusterConfig(form.OutOfClusterConfig)
+		agent, _ = app.AgentPool.GetAgent(form.OutOfClusterConfig)
 	}
 
 	nodeWithUsageData := nodes.DescribeNode(agent.Clientset, node_name)
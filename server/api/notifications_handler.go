@@ -2,8 +2,14 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/integrations/email"
+	"github.com/porter-dev/porter/internal/integrations/slack"
+	"github.com/porter-dev/porter/internal/integrations/webhook"
+	"github.com/porter-dev/porter/internal/kubernetes/alerts"
 	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/models/integrations"
 	"gorm.io/gorm"
 	"net/http"
 	"net/url"
@@ -15,6 +21,31 @@ type HandleUpdateNotificationConfigForm struct {
 		Enabled bool `json:"enabled"`
 		Success bool `json:"success"`
 		Failure bool `json:"failure"`
+
+		// Email is true if notifications should be emailed to project collaborators
+		Email bool `json:"email"`
+
+		// WebhookIDs are the webhook integrations that should receive notifications
+		// for this release
+		WebhookIDs []uint `json:"webhook_ids"`
+
+		// Namespaces limits notifications to deploys in these namespaces
+		Namespaces []string `json:"namespaces"`
+
+		// Triggers limits notifications to deploys started by a user ("manual") or
+		// by a deploy webhook ("webhook")
+		Triggers []string `json:"triggers"`
+
+		// FailureThreshold is the number of consecutive failures before a failure
+		// is notified
+		FailureThreshold uint `json:"failure_threshold"`
+
+		// QuietHours is a daily window in which notifications are not sent
+		QuietHours *models.QuietHours `json:"quiet_hours"`
+
+		// SlackIntegrationIDs routes notifications to these slack integrations
+		// instead of every slack integration in the project
+		SlackIntegrationIDs []uint `json:"slack_integration_ids"`
 	} `json:"payload"`
 	Namespace string `json:"namespace"`
 	ClusterID uint   `json:"cluster_id"`
@@ -22,6 +53,13 @@ type HandleUpdateNotificationConfigForm struct {
 
 // HandleUpdateNotificationConfig updates notification settings for a given release
 func (app *App) HandleUpdateNotificationConfig(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
 	name := chi.URLParam(r, "name")
 
 	form := &HandleUpdateNotificationConfigForm{}
@@ -47,9 +85,50 @@ func (app *App) HandleUpdateNotificationConfig(w http.ResponseWriter, r *http.Re
 
 	// either create a new notification config or update the current one
 	newConfig := &models.NotificationConfig{
-		Enabled: form.Payload.Enabled,
-		Success: form.Payload.Success,
-		Failure: form.Payload.Failure,
+		Enabled:          form.Payload.Enabled,
+		Success:          form.Payload.Success,
+		Failure:          form.Payload.Failure,
+		Email:            form.Payload.Email,
+		FailureThreshold: form.Payload.FailureThreshold,
+	}
+
+	if err := app.validateNotificationRules(uint(projID), form); err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrProjectValidateFields,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return
+	}
+
+	if quietHours := form.Payload.QuietHours; quietHours != nil {
+		newConfig.QuietHoursStart = quietHours.Start
+		newConfig.QuietHoursEnd = quietHours.End
+		newConfig.QuietHoursTimezone = quietHours.Timezone
+	}
+
+	err = newConfig.SetRules(form.Payload.Namespaces, form.Payload.Triggers, form.Payload.SlackIntegrationIDs)
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	// make sure the selected webhooks belong to this project
+	for _, webhookID := range form.Payload.WebhookIDs {
+		if _, err := app.Repo.WebhookIntegration.ReadWebhookIntegration(uint(projID), webhookID); err != nil {
+			app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+				Code:   ErrProjectValidateFields,
+				Errors: []string{fmt.Sprintf("webhook integration %d not found", webhookID)},
+			}, w)
+
+			return
+		}
+	}
+
+	if err := newConfig.SetWebhookIDs(form.Payload.WebhookIDs); err != nil {
+		app.handleErrorInternal(err, w)
+		return
 	}
 
 	if release.NotificationConfig == 0 {
@@ -65,6 +144,11 @@ func (app *App) HandleUpdateNotificationConfig(w http.ResponseWriter, r *http.Re
 		release, err = app.Repo.Release.UpdateRelease(release)
 
 	} else {
+		// keep counting failures across rule changes
+		if prevConfig, err := app.Repo.NotificationConfig.ReadNotificationConfig(release.NotificationConfig); err == nil {
+			newConfig.ConsecutiveFailures = prevConfig.ConsecutiveFailures
+		}
+
 		newConfig.ID = release.NotificationConfig
 		newConfig, err = app.Repo.NotificationConfig.UpdateNotificationConfig(newConfig)
 	}
@@ -116,9 +200,13 @@ func (app *App) HandleGetNotificationConfig(w http.ResponseWriter, r *http.Reque
 	}
 
 	config := &models.NotificationConfigExternal{
-		Enabled: true,
-		Success: true,
-		Failure: true,
+		Enabled:             true,
+		Success:             true,
+		Failure:             true,
+		WebhookIDs:          []uint{},
+		Namespaces:          []string{},
+		Triggers:            []string{},
+		SlackIntegrationIDs: []uint{},
 	}
 
 	if release.NotificationConfig != 0 {
@@ -137,3 +225,202 @@ func (app *App) HandleGetNotificationConfig(w http.ResponseWriter, r *http.Reque
 		app.handleErrorInternal(err, w)
 	}
 }
+
+// validateNotificationRules checks the triggers and quiet hours in the form, and
+// that the selected slack integrations belong to the project
+func (app *App) validateNotificationRules(projID uint, form *HandleUpdateNotificationConfigForm) error {
+	for _, trigger := range form.Payload.Triggers {
+		if trigger != models.NotificationTriggerManual && trigger != models.NotificationTriggerWebhook {
+			return fmt.Errorf("invalid trigger %s", trigger)
+		}
+	}
+
+	if form.Payload.QuietHours != nil {
+		if _, _, _, err := slack.ParseQuietHours(form.Payload.QuietHours); err != nil {
+			return err
+		}
+	}
+
+	if len(form.Payload.SlackIntegrationIDs) == 0 {
+		return nil
+	}
+
+	slackInts, err := app.Repo.SlackIntegration.ListSlackIntegrationsByProjectID(projID)
+
+	if err != nil {
+		return err
+	}
+
+	for _, slackIntID := range form.Payload.SlackIntegrationIDs {
+		found := false
+
+		for _, slackInt := range slackInts {
+			if slackInt.ID == slackIntID {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return fmt.Errorf("slack integration %d not found", slackIntID)
+		}
+	}
+
+	return nil
+}
+
+// notifyRelease records the outcome of a deploy against the release's notification
+// config, and sends the notification if the config's rules allow it. Notifications
+// are not sent if the config cannot be read, since its rules could not be applied.
+func (app *App) notifyRelease(release *models.Release, projID uint, opts *slack.NotifyOpts) {
+	var notifConf *models.NotificationConfigExternal
+
+	if release != nil && release.NotificationConfig != 0 {
+		conf, err := app.Repo.NotificationConfig.ReadNotificationConfig(release.NotificationConfig)
+
+		if err != nil {
+			app.Logger.Error().Err(err).Msgf(
+				"could not read notification config %d, dropping %s notification for %s/%s",
+				release.NotificationConfig,
+				opts.Status,
+				opts.Namespace,
+				opts.Name,
+			)
+
+			return
+		}
+
+		// the notification is still sent if the failure count could not be stored
+		if updated, err := app.Repo.NotificationConfig.UpdateNotificationConfigFailures(
+			conf.ID,
+			opts.Status == slack.StatusFailed,
+		); err != nil {
+			app.Logger.Warn().Err(err).Msgf("could not update notification config %d", conf.ID)
+		} else {
+			conf = updated
+		}
+
+		notifConf = conf.Externalize()
+	}
+
+	if opts.Status == slack.StatusFailed && notifConf != nil {
+		opts.ConsecutiveFailures = notifConf.ConsecutiveFailures
+	}
+
+	if err := app.getReleaseNotifier(projID, notifConf).Notify(opts); err != nil {
+		app.Logger.Warn().Err(err).Msgf("could not send %s notification for %s/%s", opts.Status, opts.Namespace, opts.Name)
+	}
+}
+
+// NotifyClusterAlert sends an alert detected by a cluster watcher through the
+// notifiers of the release that the failing workload belongs to. Alerts for
+// workloads that are not managed by Porter are dropped.
+func (app *App) NotifyClusterAlert(cluster *models.Cluster, alert *alerts.Alert) {
+	release, err := app.Repo.Release.ReadRelease(cluster.ID, alert.ReleaseName, alert.Namespace)
+
+	if err != nil {
+		return
+	}
+
+	var notifConf *models.NotificationConfigExternal
+
+	if release.NotificationConfig != 0 {
+		conf, err := app.Repo.NotificationConfig.ReadNotificationConfig(release.NotificationConfig)
+
+		if err != nil {
+			app.Logger.Warn().Err(err).Msg("could not read notification config")
+			return
+		}
+
+		notifConf = conf.Externalize()
+	}
+
+	opts := &slack.NotifyOpts{
+		ProjectID:   cluster.ProjectID,
+		ClusterID:   cluster.ID,
+		ClusterName: cluster.Name,
+		Status:      slack.StatusAlert,
+		Reason:      string(alert.Kind),
+		Info:        alert.Message,
+		Name:        alert.ReleaseName,
+		Namespace:   alert.Namespace,
+		URL: fmt.Sprintf(
+			"%s/applications/%s/%s/%s",
+			app.ServerConf.ServerURL,
+			url.PathEscape(cluster.Name),
+			alert.Namespace,
+			alert.ReleaseName,
+		) + fmt.Sprintf("?project_id=%d", cluster.ProjectID),
+	}
+
+	if err := app.getReleaseNotifier(cluster.ProjectID, notifConf).Notify(opts); err != nil {
+		app.Logger.Warn().Err(err).Msgf("could not send %s alert for %s", alert.Kind, alert.ReleaseName)
+	}
+}
+
+// getReleaseNotifier returns a notifier that sends release notifications to all
+// slack integrations in the project, to the webhook integrations selected in the
+// release's notification config, and to project collaborators by email if the
+// release has opted in
+func (app *App) getReleaseNotifier(
+	projID uint,
+	notifConf *models.NotificationConfigExternal,
+) slack.Notifier {
+	slackInts, _ := app.Repo.SlackIntegration.ListSlackIntegrationsByProjectID(projID)
+
+	webhookInts := make([]*integrations.WebhookIntegration, 0)
+
+	if notifConf != nil {
+		for _, webhookID := range notifConf.WebhookIDs {
+			webhookInt, err := app.Repo.WebhookIntegration.ReadWebhookIntegration(projID, webhookID)
+
+			// webhooks that have been deleted since they were selected are skipped
+			if err != nil {
+				continue
+			}
+
+			webhookInts = append(webhookInts, webhookInt)
+		}
+	}
+
+	webhookNotifier := webhook.NewWebhookNotifier(notifConf, webhookInts...)
+	webhookNotifier.Queue = app.WebhookQueue
+
+	notifiers := []slack.Notifier{
+		slack.NewSlackNotifier(notifConf, slackInts...),
+		webhookNotifier,
+	}
+
+	if notifConf != nil && notifConf.Email && app.EmailTransport != nil {
+		notifiers = append(
+			notifiers,
+			email.NewEmailNotifier(notifConf, app.EmailTransport, app.getCollaboratorEmails(projID)...),
+		)
+	}
+
+	return slack.NewMultiNotifier(notifiers...)
+}
+
+// getCollaboratorEmails returns the email addresses of all users with a role in
+// the project
+func (app *App) getCollaboratorEmails(projID uint) []string {
+	res := make([]string, 0)
+
+	roles, err := app.Repo.Project.ListProjectRoles(projID)
+
+	if err != nil {
+		return res
+	}
+
+	for _, role := range roles {
+		user, err := app.Repo.User.ReadUser(role.UserID)
+
+		if err != nil || user.Email == "" {
+			continue
+		}
+
+		res = append(res, user.Email)
+	}
+
+	return res
+}
@@ -0,0 +1,223 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// HandleCreatePolicy creates a new named policy document in a project, which can
+// then be assigned to collaborators through a custom role
+func (app *App) HandleCreatePolicy(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	form := &forms.CreatePolicyForm{
+		ProjectID: uint(projID),
+	}
+
+	// decode from JSON to form value
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	// validate the form
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrProjectValidateFields, w)
+		return
+	}
+
+	policy, err := form.ToPolicy()
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrProjectValidateFields,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return
+	}
+
+	policy, err = app.Repo.Policy.CreatePolicy(policy)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	app.Logger.Info().Msgf("New policy created: %d", policy.ID)
+
+	w.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(w).Encode(policy.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleListPolicies lists the policies in a project
+func (app *App) HandleListPolicies(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	policies, err := app.Repo.Policy.ListPoliciesByProjectID(uint(projID))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return
+	}
+
+	res := make([]*models.PolicyExternal, 0)
+
+	for _, policy := range policies {
+		res = append(res, policy.Externalize())
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleReadPolicy reads a single policy by its id
+func (app *App) HandleReadPolicy(w http.ResponseWriter, r *http.Request) {
+	policy, ok := app.readPolicyFromURLParams(w, r)
+
+	if !ok {
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(policy.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleUpdatePolicy updates the name or the policy document of a policy. The new
+// document applies to every collaborator that has been assigned the policy.
+func (app *App) HandleUpdatePolicy(w http.ResponseWriter, r *http.Request) {
+	policy, ok := app.readPolicyFromURLParams(w, r)
+
+	if !ok {
+		return
+	}
+
+	form := &forms.UpdatePolicyForm{}
+
+	// decode from JSON to form value
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	policy, err := form.ToPolicy(policy)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrProjectValidateFields,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return
+	}
+
+	policy, err = app.Repo.Policy.UpdatePolicy(policy)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(policy.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleDeletePolicy deletes a policy, as long as it is not assigned to any
+// collaborator in the project
+func (app *App) HandleDeletePolicy(w http.ResponseWriter, r *http.Request) {
+	policy, ok := app.readPolicyFromURLParams(w, r)
+
+	if !ok {
+		return
+	}
+
+	roles, err := app.Repo.Project.ListProjectRoles(policy.ProjectID)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return
+	}
+
+	for _, role := range roles {
+		if role.Kind == models.RoleCustom && role.PolicyID == policy.ID {
+			app.sendExternalError(nil, http.StatusBadRequest, HTTPError{
+				Code:   ErrProjectValidateFields,
+				Errors: []string{"policy is assigned to a collaborator and cannot be deleted"},
+			}, w)
+
+			return
+		}
+	}
+
+	policy, err = app.Repo.Policy.DeletePolicy(policy)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(policy.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// readPolicyFromURLParams reads the policy referenced by the project_id and policy_id
+// URL params. If the policy cannot be read, an error is written to the response.
+func (app *App) readPolicyFromURLParams(w http.ResponseWriter, r *http.Request) (*models.Policy, bool) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return nil, false
+	}
+
+	policyID, err := strconv.ParseUint(chi.URLParam(r, "policy_id"), 0, 64)
+
+	if err != nil || policyID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return nil, false
+	}
+
+	policy, err := app.Repo.Policy.ReadPolicy(uint(projID), uint(policyID))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return nil, false
+	}
+
+	return policy, true
+}
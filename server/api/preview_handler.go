@@ -0,0 +1,647 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/google/go-github/v33/github"
+	"github.com/porter-dev/porter/internal/auth/token"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/helm/diff"
+	"github.com/porter-dev/porter/internal/integrations/ci/actions"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+	"gorm.io/gorm"
+	"helm.sh/helm/v3/pkg/storage/driver"
+
+	k8sErrors "k8s.io/apimachinery/pkg/api/errors"
+)
+
+// PreviewEnvironmentsResponse is the preview environment config of a release along
+// with its preview environments
+type PreviewEnvironmentsResponse struct {
+	Config       *models.PreviewEnvironmentConfigExternal `json:"config"`
+	Environments []*models.PreviewEnvironmentExternal     `json:"environments"`
+}
+
+// HandleCreatePreviewEnvironmentConfig enables preview environments for a release
+// that is built from a GitHub repository through the Porter GitHub app, and commits
+// the preview workflow to the branch that the release is built from
+func (app *App) HandleCreatePreviewEnvironmentConfig(w http.ResponseWriter, r *http.Request) {
+	if app.GithubAppConf == nil {
+		app.sendExternalError(fmt.Errorf("github app is not configured"), http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{"the Porter GitHub app is not configured"},
+		}, w)
+
+		return
+	}
+
+	release, ok := app.readReleaseFromQueryParams(w, r, true)
+
+	if !ok {
+		return
+	}
+
+	form := &forms.CreatePreviewEnvironmentConfigForm{}
+
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrReleaseValidateFields, w)
+		return
+	}
+
+	gitAction := release.GitActionConfig
+
+	if gitAction.ID == 0 || !gitAction.IsInstallation || len(strings.Split(gitAction.GitRepo, "/")) != 2 {
+		app.sendExternalError(fmt.Errorf("release is not built from a github app repository"), http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{"release must be built from a GitHub repository through the Porter GitHub app"},
+		}, w)
+
+		return
+	}
+
+	if _, err := app.Repo.PreviewEnvironment.ReadPreviewEnvironmentConfigByReleaseID(release.ID); err == nil {
+		app.sendExternalError(fmt.Errorf("preview environments already enabled"), http.StatusConflict, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{"preview environments are already enabled for this release"},
+		}, w)
+
+		return
+	}
+
+	userID, err := app.getUserIDFromRequest(r)
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	// generate porter jwt token for the preview workflow
+	jwt, _ := token.GetTokenForAPI(userID, release.ProjectID)
+
+	encoded, err := jwt.EncodeToken(&token.TokenGeneratorConf{
+		TokenSecret: app.ServerConf.TokenGeneratorSecret,
+	})
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	storage := r.URL.Query().Get("storage")
+
+	if storage == "" {
+		storage = "secret"
+	}
+
+	conf := &models.PreviewEnvironmentConfig{
+		ProjectID:            release.ProjectID,
+		ClusterID:            release.ClusterID,
+		ReleaseID:            release.ID,
+		Namespace:            release.Namespace,
+		ReleaseName:          release.Name,
+		Storage:              storage,
+		GitRepo:              gitAction.GitRepo,
+		GithubInstallationID: gitAction.GithubInstallationID,
+		GitBranch:            gitAction.GitBranch,
+		BaseDomain:           form.BaseDomain,
+	}
+
+	// the config is created before the workflow, since the preview namespaces are
+	// derived from its ID
+	conf, err = app.Repo.PreviewEnvironment.CreatePreviewEnvironmentConfig(conf)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	gaRunner := app.getPreviewGithubActions(conf, &gitAction)
+	gaRunner.PorterToken = encoded
+
+	if _, err := gaRunner.SetupPreview(); err != nil {
+		app.Repo.PreviewEnvironment.DeletePreviewEnvironmentConfig(conf)
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(w).Encode(&PreviewEnvironmentsResponse{
+		Config:       conf.Externalize(),
+		Environments: []*models.PreviewEnvironmentExternal{},
+	}); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// HandleGetPreviewEnvironments returns the preview environment config of a release
+// along with its preview environments
+func (app *App) HandleGetPreviewEnvironments(w http.ResponseWriter, r *http.Request) {
+	release, ok := app.readReleaseFromQueryParams(w, r, true)
+
+	if !ok {
+		return
+	}
+
+	conf, err := app.Repo.PreviewEnvironment.ReadPreviewEnvironmentConfigByReleaseID(release.ID)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrReleaseReadData, w)
+		return
+	}
+
+	envs, err := app.Repo.PreviewEnvironment.ListPreviewEnvironments(conf.ID)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrReleaseReadData, w)
+		return
+	}
+
+	res := &PreviewEnvironmentsResponse{
+		Config:       conf.Externalize(),
+		Environments: make([]*models.PreviewEnvironmentExternal, 0),
+	}
+
+	for _, env := range envs {
+		res.Environments = append(res.Environments, env.Externalize())
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// HandleDeletePreviewEnvironmentConfig disables preview environments for a release,
+// tearing down the preview environments that are still running and removing the
+// preview workflow from the repository
+func (app *App) HandleDeletePreviewEnvironmentConfig(w http.ResponseWriter, r *http.Request) {
+	release, ok := app.readReleaseFromQueryParams(w, r, true)
+
+	if !ok {
+		return
+	}
+
+	conf, err := app.Repo.PreviewEnvironment.ReadPreviewEnvironmentConfigByReleaseID(release.ID)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrReleaseReadData, w)
+		return
+	}
+
+	envs, err := app.Repo.PreviewEnvironment.ListPreviewEnvironments(conf.ID)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrReleaseReadData, w)
+		return
+	}
+
+	for _, env := range envs {
+		if env.Status == models.PreviewEnvironmentStatusDeleted {
+			continue
+		}
+
+		if err := app.uninstallPreviewEnvironment(conf, env); err != nil {
+			app.handleErrorInternal(err, w)
+			return
+		}
+	}
+
+	// the workflow may have been removed from the repository already
+	if app.GithubAppConf != nil {
+		if err := app.getPreviewGithubActions(conf, &release.GitActionConfig).CleanupPreview(); err != nil {
+			app.Logger.Warn().Err(err).Msg("could not remove preview workflow")
+		}
+	}
+
+	if err := app.Repo.PreviewEnvironment.DeletePreviewEnvironmentConfig(conf); err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// previewPullRequest is the pull request of a GitHub pull request event
+type previewPullRequest struct {
+	Number  int
+	BaseRef string
+	HeadRef string
+	HeadSHA string
+
+	// HeadRepo is the repository of the head branch in ${owner}/${repo} form, which
+	// is a fork if it is not the repository of the event
+	HeadRepo string
+}
+
+// handlePreviewPullRequest creates or updates the preview environments of a pull
+// request when it is opened or updated, and tears them down when it is closed.
+// Pull requests from forks do not get preview environments, since anyone can open
+// them.
+func (app *App) handlePreviewPullRequest(action, gitRepo string, pr *previewPullRequest) {
+	if pr.HeadRepo != gitRepo {
+		return
+	}
+
+	confs, err := app.Repo.PreviewEnvironment.ListPreviewEnvironmentConfigsByGitRepo(gitRepo)
+
+	if err != nil {
+		app.Logger.Warn().Err(err).Msg("could not list preview environment configs")
+		return
+	}
+
+	for _, conf := range confs {
+		if conf.GitBranch != "" && pr.BaseRef != conf.GitBranch {
+			continue
+		}
+
+		switch action {
+		case "opened", "reopened", "synchronize":
+			app.deployPreviewEnvironment(conf, pr)
+		case "closed":
+			app.deletePreviewEnvironment(conf, pr)
+		}
+	}
+}
+
+// deployPreviewEnvironment installs the copy of the release for a pull request if it
+// does not exist, and posts a pending status to the head commit of the pull request
+// until the preview workflow deploys the head commit
+func (app *App) deployPreviewEnvironment(conf *models.PreviewEnvironmentConfig, pr *previewPullRequest) {
+	env, err := app.Repo.PreviewEnvironment.ReadPreviewEnvironment(conf.ID, pr.Number)
+
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		app.Logger.Warn().Err(err).Msg("could not read preview environment")
+		return
+	} else if err != nil {
+		env = &models.PreviewEnvironment{
+			PreviewEnvironmentConfigID: conf.ID,
+			PullRequestNumber:          pr.Number,
+		}
+	}
+
+	env.Namespace = conf.PreviewNamespace(pr.Number)
+	env.HeadRef = pr.HeadRef
+	env.HeadSHA = pr.HeadSHA
+	env.URL = ""
+
+	if host := conf.PreviewHost(pr.Number); host != "" {
+		env.URL = "https://" + host
+	}
+
+	if err := app.installPreviewEnvironment(conf, env); err != nil {
+		env.Status = models.PreviewEnvironmentStatusFailed
+		env.Info = err.Error()
+	} else {
+		env.Status = models.PreviewEnvironmentStatusPending
+		env.Info = ""
+	}
+
+	if env.ID == 0 {
+		env, err = app.Repo.PreviewEnvironment.CreatePreviewEnvironment(env)
+	} else {
+		env, err = app.Repo.PreviewEnvironment.UpdatePreviewEnvironment(env)
+	}
+
+	if err != nil {
+		app.Logger.Warn().Err(err).Msg("could not store preview environment")
+		return
+	}
+
+	app.setPreviewCommitStatus(conf, env)
+}
+
+// deletePreviewEnvironment tears down the preview environment of a closed pull request
+func (app *App) deletePreviewEnvironment(conf *models.PreviewEnvironmentConfig, pr *previewPullRequest) {
+	env, err := app.Repo.PreviewEnvironment.ReadPreviewEnvironment(conf.ID, pr.Number)
+
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			app.Logger.Warn().Err(err).Msg("could not read preview environment")
+		}
+
+		return
+	}
+
+	if env.Status == models.PreviewEnvironmentStatusDeleted {
+		return
+	}
+
+	if err := app.uninstallPreviewEnvironment(conf, env); err != nil {
+		env.Status = models.PreviewEnvironmentStatusFailed
+		env.Info = err.Error()
+	} else {
+		env.Status = models.PreviewEnvironmentStatusDeleted
+		env.Info = ""
+	}
+
+	if _, err := app.Repo.PreviewEnvironment.UpdatePreviewEnvironment(env); err != nil {
+		app.Logger.Warn().Err(err).Msg("could not store preview environment")
+	}
+}
+
+// installPreviewEnvironment creates the namespace of a preview environment and
+// installs a copy of the release into it. The release is only installed once: the
+// preview workflow builds the head of the pull request and updates its image. The
+// environment variables of the release are not copied, since they may hold the
+// credentials of the release.
+func (app *App) installPreviewEnvironment(conf *models.PreviewEnvironmentConfig, env *models.PreviewEnvironment) error {
+	cluster, err := app.Repo.Cluster.ReadCluster(conf.ClusterID)
+
+	if err != nil {
+		return err
+	}
+
+	srcAgent, err := app.getPreviewAgent(cluster, conf.Storage, conf.Namespace)
+
+	if err != nil {
+		return err
+	}
+
+	srcRel, err := srcAgent.GetRelease(conf.ReleaseName, 0, false)
+
+	if err != nil {
+		return err
+	}
+
+	agent, err := app.getPreviewAgent(cluster, conf.Storage, env.Namespace)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := agent.K8sAgent.CreateNamespace(env.Namespace); err != nil && !k8sErrors.IsAlreadyExists(err) {
+		return err
+	}
+
+	if _, err := agent.GetRelease(conf.ReleaseName, 0, false); err != nil {
+		values := diff.CopyValues(srcRel.Config)
+		removePreviewEnvValues(values)
+		setPreviewIngressValues(values, conf.PreviewHost(env.PullRequestNumber))
+
+		registries, err := app.Repo.Registry.ListRegistriesByProjectID(conf.ProjectID)
+
+		if err != nil {
+			return err
+		}
+
+		_, err = agent.InstallChart(&helm.InstallChartConfig{
+			Chart:      srcRel.Chart,
+			Name:       conf.ReleaseName,
+			Namespace:  env.Namespace,
+			Values:     values,
+			Cluster:    cluster,
+			Repo:       *app.Repo,
+			Registries: registries,
+		}, app.DOConf)
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return app.createPreviewRelease(conf, env)
+}
+
+// createPreviewRelease stores the copy of the release, so that the preview workflow
+// can update it
+func (app *App) createPreviewRelease(conf *models.PreviewEnvironmentConfig, env *models.PreviewEnvironment) error {
+	if _, err := app.Repo.Release.ReadRelease(conf.ClusterID, conf.ReleaseName, env.Namespace); err == nil {
+		return nil
+	}
+
+	srcRelease, err := app.Repo.Release.ReadReleaseByID(conf.ReleaseID)
+
+	if err != nil {
+		return err
+	}
+
+	webhookToken, err := repository.GenerateRandomBytes(16)
+
+	if err != nil {
+		return err
+	}
+
+	release, err := app.Repo.Release.CreateRelease(&models.Release{
+		ClusterID:    conf.ClusterID,
+		ProjectID:    conf.ProjectID,
+		Namespace:    env.Namespace,
+		Name:         conf.ReleaseName,
+		WebhookToken: webhookToken,
+		ImageRepoURI: srcRelease.ImageRepoURI,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	gitAction := srcRelease.GitActionConfig
+
+	_, err = app.Repo.GitActionConfig.CreateGitActionConfig(&models.GitActionConfig{
+		ReleaseID:            release.ID,
+		GitRepo:              gitAction.GitRepo,
+		GitBranch:            env.HeadRef,
+		ImageRepoURI:         gitAction.ImageRepoURI,
+		GithubInstallationID: gitAction.GithubInstallationID,
+		DockerfilePath:       gitAction.DockerfilePath,
+		FolderPath:           gitAction.FolderPath,
+		IsInstallation:       gitAction.IsInstallation,
+		Version:              gitAction.Version,
+	})
+
+	return err
+}
+
+// uninstallPreviewEnvironment uninstalls the copy of the release and deletes the
+// namespace of a preview environment
+func (app *App) uninstallPreviewEnvironment(conf *models.PreviewEnvironmentConfig, env *models.PreviewEnvironment) error {
+	cluster, err := app.Repo.Cluster.ReadCluster(conf.ClusterID)
+
+	if err != nil {
+		return err
+	}
+
+	agent, err := app.getPreviewAgent(cluster, conf.Storage, env.Namespace)
+
+	if err != nil {
+		return err
+	}
+
+	if _, err := agent.UninstallChart(conf.ReleaseName); err != nil && !errors.Is(err, driver.ErrReleaseNotFound) {
+		return err
+	}
+
+	if release, err := app.Repo.Release.ReadRelease(conf.ClusterID, conf.ReleaseName, env.Namespace); err == nil {
+		if _, err := app.Repo.Release.DeleteRelease(release); err != nil {
+			return err
+		}
+	}
+
+	if err := agent.K8sAgent.DeleteNamespace(env.Namespace); err != nil && !k8sErrors.IsNotFound(err) {
+		return err
+	}
+
+	return nil
+}
+
+// setPreviewCommitStatus posts the state of a preview environment, along with its
+// URL, to the head commit of the pull request
+func (app *App) setPreviewCommitStatus(conf *models.PreviewEnvironmentConfig, env *models.PreviewEnvironment) {
+	status := &github.RepoStatus{
+		Context: github.String(fmt.Sprintf("porter/preview/%s", conf.ReleaseName)),
+	}
+
+	switch env.Status {
+	case models.PreviewEnvironmentStatusDeployed:
+		status.State = github.String("success")
+		status.Description = github.String(fmt.Sprintf("Preview deployed to namespace %s", env.Namespace))
+
+		if env.URL != "" {
+			status.TargetURL = github.String(env.URL)
+		}
+	case models.PreviewEnvironmentStatusPending:
+		status.State = github.String("pending")
+		status.Description = github.String("Waiting for the preview workflow to deploy the pull request")
+	default:
+		status.State = github.String("error")
+		status.Description = github.String("Preview environment could not be deployed")
+	}
+
+	err := app.getPreviewGithubActions(conf, nil).CreateCommitStatus(env.HeadSHA, status)
+
+	if err != nil {
+		app.Logger.Warn().Err(err).Msg("could not set preview commit status")
+	}
+}
+
+// markPreviewEnvironmentDeployed marks the preview environment of a namespace as
+// deployed once the preview workflow upgrades its release, and posts the preview URL
+// to the head commit of the pull request. Releases outside of preview environments
+// are ignored.
+func (app *App) markPreviewEnvironmentDeployed(clusterID uint, namespace, name string) {
+	env, err := app.Repo.PreviewEnvironment.ReadPreviewEnvironmentByNamespace(namespace)
+
+	if err != nil || env.Status != models.PreviewEnvironmentStatusPending {
+		return
+	}
+
+	conf, err := app.Repo.PreviewEnvironment.ReadPreviewEnvironmentConfig(env.PreviewEnvironmentConfigID)
+
+	if err != nil || conf.ClusterID != clusterID || conf.ReleaseName != name {
+		return
+	}
+
+	env.Status = models.PreviewEnvironmentStatusDeployed
+
+	if _, err := app.Repo.PreviewEnvironment.UpdatePreviewEnvironment(env); err != nil {
+		app.Logger.Warn().Err(err).Msg("could not store preview environment")
+		return
+	}
+
+	if app.GithubAppConf != nil {
+		app.setPreviewCommitStatus(conf, env)
+	}
+}
+
+// getPreviewGithubActions returns the GithubActions runner of the preview workflow of
+// a release. The git action config of the release is only required to generate the
+// workflow.
+func (app *App) getPreviewGithubActions(
+	conf *models.PreviewEnvironmentConfig,
+	gitAction *models.GitActionConfig,
+) *actions.GithubActions {
+	repoSplit := strings.SplitN(conf.GitRepo, "/", 2)
+
+	gaRunner := &actions.GithubActions{
+		ServerURL:            app.ServerConf.ServerURL,
+		GithubAppID:          app.GithubAppConf.AppID,
+		GithubAppSecretPath:  app.GithubAppConf.SecretPath,
+		GithubInstallationID: conf.GithubInstallationID,
+		GitRepoOwner:         repoSplit[0],
+		Repo:                 *app.Repo,
+		GithubConf:           app.GithubProjectConf,
+		ProjectID:            conf.ProjectID,
+		ClusterID:            conf.ClusterID,
+		ReleaseName:          conf.ReleaseName,
+		PreviewConfigID:      conf.ID,
+		GitBranch:            conf.GitBranch,
+		Version:              updateAppActionVersion,
+	}
+
+	if len(repoSplit) == 2 {
+		gaRunner.GitRepoName = repoSplit[1]
+	}
+
+	if gitAction != nil {
+		gaRunner.DockerFilePath = gitAction.DockerfilePath
+		gaRunner.FolderPath = gitAction.FolderPath
+		gaRunner.ImageRepoURL = gitAction.ImageRepoURI
+		gaRunner.Version = gitAction.Version
+	}
+
+	return gaRunner
+}
+
+// getPreviewAgent returns a Helm agent for a namespace of the cluster of a preview
+// environment config
+func (app *App) getPreviewAgent(cluster *models.Cluster, storage, namespace string) (*helm.Agent, error) {
+	if app.ServerConf.IsTesting {
+		return app.TestAgents.HelmAgent, nil
+	}
+
+	return helm.GetAgentFromPool(&helm.Form{
+		Cluster:           cluster,
+		Repo:              app.Repo,
+		DigitalOceanOAuth: app.DOConf,
+		Storage:           storage,
+		Namespace:         namespace,
+	}, app.Logger, app.AgentPool)
+}
+
+// removePreviewEnvValues removes the environment variables from the values of a
+// preview environment
+func removePreviewEnvValues(values map[string]interface{}) {
+	if container, ok := values["container"].(map[string]interface{}); ok {
+		delete(container, "env")
+	}
+}
+
+// setPreviewIngressValues points the ingress of a preview environment at its preview
+// host, so that it does not serve the hosts of the release that it copies. If there
+// is no preview host, the ingress is disabled.
+func setPreviewIngressValues(values map[string]interface{}, host string) {
+	ingress, ok := values["ingress"].(map[string]interface{})
+
+	if !ok {
+		if host == "" {
+			return
+		}
+
+		ingress = make(map[string]interface{})
+		values["ingress"] = ingress
+	}
+
+	if host == "" {
+		ingress["enabled"] = false
+		return
+	}
+
+	ingress["enabled"] = true
+	ingress["custom_domain"] = true
+	ingress["hosts"] = []interface{}{host}
+	ingress["porter_hosts"] = []interface{}{}
+}
@@ -0,0 +1,98 @@
+package api
+
+import (
+	"testing"
+
+	"github.com/porter-dev/porter/internal/models"
+	memory "github.com/porter-dev/porter/internal/repository/memory"
+)
+
+func TestPreviewPullRequestFromFork(t *testing.T) {
+	repo := memory.NewRepository(true)
+
+	_, err := repo.PreviewEnvironment.CreatePreviewEnvironmentConfig(&models.PreviewEnvironmentConfig{
+		ClusterID:   1,
+		ReleaseID:   1,
+		Namespace:   "default",
+		ReleaseName: "web",
+		GitRepo:     "porter-dev/porter",
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	app := &App{Repo: repo}
+
+	app.handlePreviewPullRequest("opened", "porter-dev/porter", &previewPullRequest{
+		Number:   12,
+		HeadRef:  "main",
+		HeadSHA:  "abc123",
+		HeadRepo: "someone/porter",
+	})
+
+	if envs, _ := repo.PreviewEnvironment.ListPreviewEnvironments(1); len(envs) != 0 {
+		t.Errorf("expected no preview environment for a fork, got %d\n", len(envs))
+	}
+}
+
+func TestMarkPreviewEnvironmentDeployed(t *testing.T) {
+	repo := memory.NewRepository(true)
+
+	conf, err := repo.PreviewEnvironment.CreatePreviewEnvironmentConfig(&models.PreviewEnvironmentConfig{
+		ClusterID:   1,
+		ReleaseID:   1,
+		Namespace:   "default",
+		ReleaseName: "web",
+		GitRepo:     "porter-dev/porter",
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	env, err := repo.PreviewEnvironment.CreatePreviewEnvironment(&models.PreviewEnvironment{
+		PreviewEnvironmentConfigID: conf.ID,
+		PullRequestNumber:          12,
+		Namespace:                  conf.PreviewNamespace(12),
+		Status:                     models.PreviewEnvironmentStatusPending,
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	app := &App{Repo: repo}
+
+	// other releases of the namespace do not deploy the environment
+	app.markPreviewEnvironmentDeployed(1, env.Namespace, "worker")
+
+	if env.Status != models.PreviewEnvironmentStatusPending {
+		t.Errorf("expected status %s, got %s\n", models.PreviewEnvironmentStatusPending, env.Status)
+	}
+
+	app.markPreviewEnvironmentDeployed(1, env.Namespace, "web")
+
+	if env.Status != models.PreviewEnvironmentStatusDeployed {
+		t.Errorf("expected status %s, got %s\n", models.PreviewEnvironmentStatusDeployed, env.Status)
+	}
+}
+
+func TestRemovePreviewEnvValues(t *testing.T) {
+	values := map[string]interface{}{
+		"container": map[string]interface{}{
+			"port": 80,
+			"env": map[string]interface{}{
+				"normal": map[string]interface{}{"DATABASE_URL": "postgres://admin:secret@db"},
+			},
+		},
+	}
+
+	removePreviewEnvValues(values)
+
+	container := values["container"].(map[string]interface{})
+
+	if _, ok := container["env"]; ok || container["port"] != 80 {
+		t.Errorf("expected only the env of the container to be removed, got %v\n", container)
+	}
+}
@@ -2,12 +2,13 @@ package api
 
 import (
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"strconv"
 
 	"github.com/go-chi/chi"
-	"github.com/porter-dev/porter/api/types"
 	"github.com/porter-dev/porter/internal/analytics"
+	"github.com/porter-dev/porter/internal/auth/policy"
 	"github.com/porter-dev/porter/internal/forms"
 	"github.com/porter-dev/porter/internal/models"
 )
@@ -89,10 +90,10 @@ func (app *App) HandleCreateProject(w http.ResponseWriter, r *http.Request) {
 	}
 }
 
-// HandleGetProjectRoles lists the roles available to the project. For now, these
-// roles are static.
+// HandleGetProjectRoles lists the role kinds available to the project. Custom roles
+// additionally reference one of the project's policies.
 func (app *App) HandleGetProjectRoles(w http.ResponseWriter, r *http.Request) {
-	roles := []string{models.RoleAdmin, models.RoleDeveloper, models.RoleViewer}
+	roles := []string{models.RoleAdmin, models.RoleDeveloper, models.RoleViewer, models.RoleCustom}
 
 	w.WriteHeader(http.StatusOK)
 
@@ -108,6 +109,7 @@ type Collaborator struct {
 	UserID    uint   `json:"user_id"`
 	Email     string `json:"email"`
 	ProjectID uint   `json:"project_id"`
+	PolicyID  uint   `json:"policy_id,omitempty"`
 }
 
 // HandleListProjectCollaborators lists the collaborators in the project
@@ -150,6 +152,7 @@ func (app *App) HandleListProjectCollaborators(w http.ResponseWriter, r *http.Re
 			UserID:    roleMap[user.ID].UserID,
 			Email:     user.Email,
 			ProjectID: roleMap[user.ID].ProjectID,
+			PolicyID:  roleMap[user.ID].PolicyID,
 		})
 	}
 
@@ -211,18 +214,14 @@ func (app *App) HandleReadProjectPolicy(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	// case on the role to get the policy document
-	var policy types.Policy
-	switch role.Kind {
-	case models.RoleAdmin:
-		policy = types.AdminPolicy
-	case models.RoleDeveloper:
-		policy = types.DeveloperPolicy
-	case models.RoleViewer:
-		policy = types.ViewerPolicy
+	rolePolicy, err := policy.GetPolicyForRole(app.Repo.Policy, role)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return
 	}
 
-	if err := json.NewEncoder(w).Encode(policy); err != nil {
+	if err := json.NewEncoder(w).Encode(rolePolicy); err != nil {
 		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
 		return
 	}
@@ -259,7 +258,13 @@ func (app *App) HandleUpdateProjectRole(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	if err := app.validateRolePolicy(uint(id), form.Kind, form.PolicyID); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
 	role.Kind = form.Kind
+	role.PolicyID = form.PolicyID
 
 	role, err = app.Repo.Project.UpdateProjectRole(uint(id), role)
 
@@ -344,3 +349,23 @@ func (app *App) HandleDeleteProjectRole(w http.ResponseWriter, r *http.Request)
 		return
 	}
 }
+
+// validateRolePolicy checks that a custom role kind references a policy in the
+// project, and that the other role kinds do not reference a policy
+func (app *App) validateRolePolicy(projID uint, kind string, policyID uint) error {
+	if kind != models.RoleCustom {
+		if policyID != 0 {
+			return fmt.Errorf("policy_id can only be set for %s roles", models.RoleCustom)
+		}
+
+		return nil
+	}
+
+	if policyID == 0 {
+		return fmt.Errorf("policy_id is required for %s roles", models.RoleCustom)
+	}
+
+	_, err := app.Repo.Policy.ReadPolicy(projID, policyID)
+
+	return err
+}
@@ -10,6 +10,8 @@ import (
 	"github.com/go-test/deep"
 	"github.com/porter-dev/porter/internal/kubernetes"
 	"github.com/porter-dev/porter/internal/models"
+	ints "github.com/porter-dev/porter/internal/models/integrations"
+	"github.com/porter-dev/porter/server/api"
 )
 
 // ------------------------- TEST TYPES AND MAIN LOOP ------------------------- //
@@ -253,6 +255,79 @@ func TestHandleDeleteRegistry(t *testing.T) {
 
 // ------------------------- INITIALIZERS AND VALIDATORS ------------------------- //
 
+var basicTokenTests = []*regTest{
+	&regTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+			initBasicRegistry,
+		},
+		msg:       "Get basic token for registry host",
+		method:    "GET",
+		endpoint:  "/api/projects/1/registries/basic/token",
+		body:      `{"server_url":"https://harbor.example.com/v2/"}`,
+		expStatus: http.StatusOK,
+		expBody:   `{"token":"dXNlcm5hbWU6cGFzc3dvcmQ="}`,
+		useCookie: true,
+		validators: []func(c *regTest, tester *tester, t *testing.T){
+			regTokenValidator,
+		},
+	},
+	&regTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+			initBasicRegistry,
+		},
+		msg:       "Get basic token for part of registry host",
+		method:    "GET",
+		endpoint:  "/api/projects/1/registries/basic/token",
+		body:      `{"server_url":"https://example.com"}`,
+		expStatus: http.StatusOK,
+		expBody:   `{"token":""}`,
+		useCookie: true,
+		validators: []func(c *regTest, tester *tester, t *testing.T){
+			regTokenValidator,
+		},
+	},
+}
+
+func TestHandleGetRegistryBasicToken(t *testing.T) {
+	testRegistryRequests(t, basicTokenTests, true)
+}
+
+func initBasicRegistry(tester *tester) {
+	proj, _ := tester.repo.Project.ReadProject(1)
+
+	basic, _ := tester.repo.BasicIntegration.CreateBasicIntegration(&ints.BasicIntegration{
+		ProjectID: proj.Model.ID,
+		Username:  []byte("username"),
+		Password:  []byte("password"),
+	})
+
+	reg := &models.Registry{
+		Name:               "registry-test",
+		ProjectID:          proj.Model.ID,
+		URL:                "harbor.example.com/library",
+		Service:            ints.Harbor,
+		BasicIntegrationID: basic.ID,
+	}
+
+	tester.repo.Registry.CreateRegistry(reg)
+}
+
+func regTokenValidator(c *regTest, tester *tester, t *testing.T) {
+	gotBody := &api.RegTokenResponse{}
+	expBody := &api.RegTokenResponse{}
+
+	json.Unmarshal(tester.rr.Body.Bytes(), gotBody)
+	json.Unmarshal([]byte(c.expBody), expBody)
+
+	if gotBody.Token != expBody.Token {
+		t.Errorf("%s, handler returned wrong token: got %q want %q\n", c.msg, gotBody.Token, expBody.Token)
+	}
+}
+
 func initRegistry(tester *tester) {
 	proj, _ := tester.repo.Project.ReadProject(1)
 
@@ -1,13 +1,16 @@
 package api
 
 import (
+	"bytes"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"net/url"
 	"strconv"
 	"strings"
 	"sync"
+	"time"
 
 	"gorm.io/gorm"
 
@@ -17,6 +20,7 @@ import (
 	"github.com/porter-dev/porter/internal/models"
 	"github.com/porter-dev/porter/internal/templater/parser"
 	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
 	"helm.sh/helm/v3/pkg/release"
 	v1 "k8s.io/api/core/v1"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
@@ -24,12 +28,16 @@ import (
 	"github.com/go-chi/chi"
 	"github.com/porter-dev/porter/internal/forms"
 	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/helm/diff"
 	"github.com/porter-dev/porter/internal/helm/grapher"
 	"github.com/porter-dev/porter/internal/helm/loader"
 	"github.com/porter-dev/porter/internal/integrations/ci/actions"
 	"github.com/porter-dev/porter/internal/integrations/slack"
+	"github.com/porter-dev/porter/internal/integrations/webhook"
 	"github.com/porter-dev/porter/internal/kubernetes"
+	"github.com/porter-dev/porter/internal/registry"
 	"github.com/porter-dev/porter/internal/repository"
+	"github.com/porter-dev/porter/internal/templater/utils"
 	"gopkg.in/yaml.v2"
 )
 
@@ -168,7 +176,7 @@ func (app *App) HandleGetRelease(w http.ResponseWriter, r *http.Request) {
 	}
 
 	// create a new dynamic client
-	dynClient, err := kubernetes.GetDynamicClientOutOfClusterConfig(k8sForm.OutOfClusterConfig)
+	dynClient, err := app.AgentPool.GetDynamicClient(k8sForm.OutOfClusterConfig)
 
 	if err != nil {
 		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
@@ -390,69 +398,18 @@ func (app *App) HandleGetReleaseControllers(w http.ResponseWriter, r *http.Reque
 	if app.ServerConf.IsTesting {
 		k8sAgent = app.TestAgents.K8sAgent
 	} else {
-		k8sAgent, err = kubernetes.GetAgentOutOfClusterConfig(k8sForm.OutOfClusterConfig)
+		k8sAgent, err = app.AgentPool.GetAgent(k8sForm.OutOfClusterConfig)
 	}
 
 	yamlArr := grapher.ImportMultiDocYAML([]byte(release.Manifest))
 	controllers := grapher.ParseControllers(yamlArr)
-	retrievedControllers := []interface{}{}
 
 	// get current status of each controller
-	// TODO: refactor with type assertion
-	for _, c := range controllers {
-		c.Namespace = form.ReleaseForm.Form.Namespace
-		switch c.Kind {
-		case "Deployment":
-			rc, err := k8sAgent.GetDeployment(c)
-
-			if err != nil {
-				app.handleErrorDataRead(err, w)
-				return
-			}
-
-			rc.Kind = c.Kind
-			retrievedControllers = append(retrievedControllers, rc)
-		case "StatefulSet":
-			rc, err := k8sAgent.GetStatefulSet(c)
-
-			if err != nil {
-				app.handleErrorDataRead(err, w)
-				return
-			}
-
-			rc.Kind = c.Kind
-			retrievedControllers = append(retrievedControllers, rc)
-		case "DaemonSet":
-			rc, err := k8sAgent.GetDaemonSet(c)
-
-			if err != nil {
-				app.handleErrorDataRead(err, w)
-				return
-			}
-
-			rc.Kind = c.Kind
-			retrievedControllers = append(retrievedControllers, rc)
-		case "ReplicaSet":
-			rc, err := k8sAgent.GetReplicaSet(c)
+	retrievedControllers, err := k8sAgent.GetControllers(controllers, form.ReleaseForm.Form.Namespace)
 
-			if err != nil {
-				app.handleErrorDataRead(err, w)
-				return
-			}
-
-			rc.Kind = c.Kind
-			retrievedControllers = append(retrievedControllers, rc)
-		case "CronJob":
-			rc, err := k8sAgent.GetCronJob(c)
-
-			if err != nil {
-				app.handleErrorDataRead(err, w)
-				return
-			}
-
-			rc.Kind = c.Kind
-			retrievedControllers = append(retrievedControllers, rc)
-		}
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
 	}
 
 	if err := json.NewEncoder(w).Encode(retrievedControllers); err != nil {
@@ -530,7 +487,7 @@ func (app *App) HandleGetReleaseAllPods(w http.ResponseWriter, r *http.Request)
 	if app.ServerConf.IsTesting {
 		k8sAgent = app.TestAgents.K8sAgent
 	} else {
-		k8sAgent, err = kubernetes.GetAgentOutOfClusterConfig(k8sForm.OutOfClusterConfig)
+		k8sAgent, err = app.AgentPool.GetAgent(k8sForm.OutOfClusterConfig)
 	}
 
 	yamlArr := grapher.ImportMultiDocYAML([]byte(release.Manifest))
@@ -688,7 +645,7 @@ func (app *App) HandleGetJobStatus(w http.ResponseWriter, r *http.Request) {
 	if app.ServerConf.IsTesting {
 		k8sAgent = app.TestAgents.K8sAgent
 	} else {
-		k8sAgent, err = kubernetes.GetAgentOutOfClusterConfig(k8sForm.OutOfClusterConfig)
+		k8sAgent, err = app.AgentPool.GetAgent(k8sForm.OutOfClusterConfig)
 	}
 
 	jobs, err := k8sAgent.ListJobsByLabel(namespace, kubernetes.Label{
@@ -831,6 +788,29 @@ func (app *App) HandleCreateWebhookToken(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
+	release, err := app.createReleaseWithWebhookToken(w, r, name, vals)
+
+	// errors are handled in app.createReleaseWithWebhookToken
+	if err != nil {
+		return
+	}
+
+	releaseExt := release.Externalize()
+
+	if err := json.NewEncoder(w).Encode(releaseExt); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// createReleaseWithWebhookToken reads a release from the target cluster and stores
+// it with a new webhook token. Errors are written to the response.
+func (app *App) createReleaseWithWebhookToken(
+	w http.ResponseWriter,
+	r *http.Request,
+	name string,
+	vals url.Values,
+) (*models.Release, error) {
 	// read the release from the target cluster
 	form := &forms.ReleaseForm{
 		Form: &helm.Form{
@@ -852,36 +832,38 @@ func (app *App) HandleCreateWebhookToken(w http.ResponseWriter, r *http.Request)
 
 	if err != nil {
 		app.handleErrorFormDecoding(err, ErrUserDecode, w)
-		return
+		return nil, err
 	}
 
 	rel, err := agent.GetRelease(name, 0, false)
 
 	if err != nil {
 		app.handleErrorDataRead(err, w)
-		return
+		return nil, err
 	}
 
 	token, err := repository.GenerateRandomBytes(16)
 
 	if err != nil {
 		app.handleErrorInternal(err, w)
-		return
+		return nil, err
 	}
 
 	// create release with webhook token in db
 	image, ok := rel.Config["image"].(map[string]interface{})
 	if !ok {
-		app.handleErrorInternal(fmt.Errorf("Could not find field image in config"), w)
-		return
+		err = fmt.Errorf("Could not find field image in config")
+		app.handleErrorInternal(err, w)
+		return nil, err
 	}
 
 	repository := image["repository"]
 	repoStr, ok := repository.(string)
 
 	if !ok {
-		app.handleErrorInternal(fmt.Errorf("Could not find field repository in config"), w)
-		return
+		err = fmt.Errorf("Could not find field repository in config")
+		app.handleErrorInternal(err, w)
+		return nil, err
 	}
 
 	release := &models.Release{
@@ -897,15 +879,10 @@ func (app *App) HandleCreateWebhookToken(w http.ResponseWriter, r *http.Request)
 
 	if err != nil {
 		app.handleErrorInternal(err, w)
-		return
+		return nil, err
 	}
 
-	releaseExt := release.Externalize()
-
-	if err := json.NewEncoder(w).Encode(releaseExt); err != nil {
-		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
-		return
-	}
+	return release, nil
 }
 
 type ContainerEnvConfig struct {
@@ -954,6 +931,10 @@ func (app *App) HandleUpgradeRelease(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkReleaseFormTarget(w, vals, form.Namespace, form.Name, name) {
+		return
+	}
+
 	agent, err := app.getAgentFromReleaseForm(
 		w,
 		r,
@@ -981,75 +962,35 @@ func (app *App) HandleUpgradeRelease(w http.ResponseWriter, r *http.Request) {
 
 	// if the chart version is set, load a chart from the repo
 	if form.ChartVersion != "" {
-		release, err := agent.GetRelease(form.Name, 0, false)
-
-		if err != nil {
-			app.sendExternalError(err, http.StatusNotFound, HTTPError{
-				Code:   ErrReleaseReadData,
-				Errors: []string{"chart version not found"},
-			}, w)
-
-			return
-		}
-
-		chartRepoURL, foundFirst := app.ChartLookupURLs[release.Chart.Metadata.Name]
-
-		if !foundFirst {
-			app.updateChartRepoURLs()
-
-			var found bool
-
-			chartRepoURL, found = app.ChartLookupURLs[release.Chart.Metadata.Name]
-
-			if !found {
-				app.sendExternalError(err, http.StatusNotFound, HTTPError{
-					Code:   ErrReleaseReadData,
-					Errors: []string{"chart not found"},
-				}, w)
-
-				return
-			}
-		}
-
-		chart, err := loader.LoadChartPublic(
-			chartRepoURL,
-			release.Chart.Metadata.Name,
-			form.ChartVersion,
-		)
+		conf.Chart, err = app.loadReleaseChartVersion(w, agent, form.Name, form.ChartVersion)
 
+		// errors are handled in app.loadReleaseChartVersion
 		if err != nil {
-			app.sendExternalError(err, http.StatusNotFound, HTTPError{
-				Code:   ErrReleaseReadData,
-				Errors: []string{"chart not found"},
-			}, w)
-
 			return
 		}
-
-		conf.Chart = chart
 	}
 
-	rel, upgradeErr := agent.UpgradeRelease(conf, form.Values, app.DOConf)
+	// read the values of the current revision to record the diff of the deployment
+	var prevValues map[string]interface{}
+	var prevRevision int
 
-	slackInts, _ := app.Repo.SlackIntegration.ListSlackIntegrationsByProjectID(uint(projID))
+	if prevRel, err := agent.GetRelease(form.Name, 0, false); err == nil {
+		prevValues = prevRel.Config
+		prevRevision = prevRel.Version
+	}
 
 	clusterID, err := strconv.ParseUint(vals["cluster_id"][0], 10, 64)
 	release, _ := app.Repo.Release.ReadRelease(uint(clusterID), name, form.Namespace)
 
-	var notifConf *models.NotificationConfigExternal
-	notifConf = nil
-	if release != nil && release.NotificationConfig != 0 {
-		conf, err := app.Repo.NotificationConfig.ReadNotificationConfig(release.NotificationConfig)
-
-		if err != nil {
-			app.handleErrorInternal(err, w)
-			return
-		}
-
-		notifConf = conf.Externalize()
+	deployment := &models.Deployment{
+		ProjectID: uint(projID),
+		ClusterID: form.Cluster.ID,
+		Namespace: form.Namespace,
+		Name:      name,
+		Trigger:   models.DeploymentTriggerManual,
 	}
 
-	notifier := slack.NewSlackNotifier(notifConf, slackInts...)
+	app.setDeploymentActor(deployment, r)
 
 	notifyOpts := &slack.NotifyOpts{
 		ProjectID:   uint(projID),
@@ -1057,6 +998,7 @@ func (app *App) HandleUpgradeRelease(w http.ResponseWriter, r *http.Request) {
 		ClusterName: form.Cluster.Name,
 		Name:        name,
 		Namespace:   form.Namespace,
+		Trigger:     models.NotificationTriggerManual,
 		URL: fmt.Sprintf(
 			"%s/applications/%s/%s/%s",
 			app.ServerConf.ServerURL,
@@ -1066,11 +1008,60 @@ func (app *App) HandleUpgradeRelease(w http.ResponseWriter, r *http.Request) {
 		) + fmt.Sprintf("?project_id=%d", uint(projID)),
 	}
 
+	// the image of the new values is checked before an upgrade or a rollout starts.
+	// Values that cannot be parsed fail the upgrade below.
+	if values, parseErr := chartutil.ReadValues([]byte(form.Values)); parseErr == nil {
+		blockErr := app.checkUpgradeVulnerabilityPolicy(r.Context(), release, registries, values)
+
+		// the values are read from the form by the upgrade, so the image that was
+		// checked is written back to the form
+		if blockErr == nil && release != nil && release.VulnerabilityBlockSeverity != "" {
+			if pinnedValues, err := values.YAML(); err == nil {
+				form.Values = pinnedValues
+			} else {
+				blockErr = fmt.Errorf("deploy blocked, since the checked image could not be set: %v", err)
+			}
+		}
+
+		if blockErr != nil {
+			notifyOpts.Status = slack.StatusFailed
+			notifyOpts.Info = blockErr.Error()
+
+			app.notifyRelease(release, uint(projID), notifyOpts)
+
+			deployment.Status = models.DeploymentStatusFailed
+			deployment.Info = blockErr.Error()
+
+			app.recordDeployment(deployment, prevValues, values)
+
+			app.sendExternalError(blockErr, http.StatusBadRequest, HTTPError{
+				Code:   ErrReleaseDeploy,
+				Errors: []string{blockErr.Error()},
+			}, w)
+
+			return
+		}
+	}
+
+	// web releases can be upgraded through a rollout, which runs in the background
+	if form.Rollout != nil {
+		app.startRollout(w, r, uint(projID), form, agent, conf)
+		return
+	}
+
+	rel, upgradeErr := agent.UpgradeRelease(conf, form.Values, app.DOConf)
+
 	if upgradeErr != nil {
 		notifyOpts.Status = slack.StatusFailed
 		notifyOpts.Info = upgradeErr.Error()
 
-		notifier.Notify(notifyOpts)
+		app.notifyRelease(release, uint(projID), notifyOpts)
+
+		deployment.Status = models.DeploymentStatusFailed
+		deployment.Info = upgradeErr.Error()
+
+		// conf.Values is only set if the new values could be parsed
+		app.recordDeployment(deployment, prevValues, conf.Values)
 
 		app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
 			Code:   ErrReleaseDeploy,
@@ -1083,7 +1074,18 @@ func (app *App) HandleUpgradeRelease(w http.ResponseWriter, r *http.Request) {
 	notifyOpts.Status = string(rel.Info.Status)
 	notifyOpts.Version = rel.Version
 
-	notifier.Notify(notifyOpts)
+	app.notifyRelease(release, uint(projID), notifyOpts)
+
+	deployment.Revision = rel.Version
+	deployment.Status = string(rel.Info.Status)
+
+	app.recordDeployment(deployment, prevValues, rel.Config)
+
+	go app.watchAutoRollback(release, *form.ReleaseForm.Form, rel, prevRevision, *notifyOpts)
+
+	// the preview workflow deploys the head of a pull request by upgrading the copy
+	// of the release in its preview environment
+	app.markPreviewEnvironmentDeployed(form.Cluster.ID, rel.Namespace, rel.Name)
 
 	// update the github actions env if the release exists and is built from source
 	if cName := rel.Chart.Metadata.Name; cName == "job" || cName == "web" || cName == "worker" {
@@ -1177,14 +1179,210 @@ func (app *App) HandleUpgradeRelease(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusOK)
 }
 
+// HandleUpgradeReleasePreview renders an upgrade of a release with new values.yaml
+// without applying it, and returns the changes to the values and to each rendered
+// resource compared to the currently deployed revision.
+func (app *App) HandleUpgradeReleasePreview(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	name := chi.URLParam(r, "name")
+
+	vals, err := url.ParseQuery(r.URL.RawQuery)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	form := &forms.UpgradeReleaseForm{
+		ReleaseForm: &forms.ReleaseForm{
+			Form: &helm.Form{
+				Repo:              app.Repo,
+				DigitalOceanOAuth: app.DOConf,
+			},
+		},
+		Name: name,
+	}
+
+	form.ReleaseForm.PopulateHelmOptionsFromQueryParams(
+		vals,
+		app.Repo.Cluster,
+	)
+
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrUserDecode, w)
+		return
+	}
+
+	if !checkReleaseFormTarget(w, vals, form.Namespace, form.Name, name) {
+		return
+	}
+
+	agent, err := app.getAgentFromReleaseForm(
+		w,
+		r,
+		form.ReleaseForm,
+	)
+
+	// errors are handled in app.getAgentFromBodyParams
+	if err != nil {
+		return
+	}
+
+	prevRel, err := agent.GetRelease(form.Name, 0, false)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusNotFound, HTTPError{
+			Code:   ErrReleaseReadData,
+			Errors: []string{"release not found"},
+		}, w)
+
+		return
+	}
+
+	registries, err := app.Repo.Registry.ListRegistriesByProjectID(uint(projID))
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	conf := &helm.UpgradeReleaseConfig{
+		Name:       form.Name,
+		Cluster:    form.ReleaseForm.Cluster,
+		Repo:       *app.Repo,
+		Registries: registries,
+		DryRun:     true,
+	}
+
+	if form.ChartVersion != "" {
+		conf.Chart, err = app.loadReleaseChartVersion(w, agent, form.Name, form.ChartVersion)
+
+		// errors are handled in app.loadReleaseChartVersion
+		if err != nil {
+			return
+		}
+	}
+
+	rel, err := agent.UpgradeRelease(conf, form.Values, app.DOConf)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseDeploy,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return
+	}
+
+	resources, err := diff.Manifests(prevRel.Manifest, rel.Manifest)
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	preview := &diff.Preview{
+		ValuesDiff: diff.Values(prevRel.Config, conf.Values),
+		Resources:  resources,
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(preview); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// loadReleaseChartVersion loads a version of the chart that a release was installed
+// from, using the chart repository that the chart is listed in. Errors are written
+// to the response.
+func (app *App) loadReleaseChartVersion(
+	w http.ResponseWriter,
+	agent *helm.Agent,
+	name, version string,
+) (*chart.Chart, error) {
+	release, err := agent.GetRelease(name, 0, false)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusNotFound, HTTPError{
+			Code:   ErrReleaseReadData,
+			Errors: []string{"chart version not found"},
+		}, w)
+
+		return nil, err
+	}
+
+	chartRepoURL, foundFirst := app.ChartLookupURLs[release.Chart.Metadata.Name]
+
+	if !foundFirst {
+		app.updateChartRepoURLs()
+
+		var found bool
+
+		chartRepoURL, found = app.ChartLookupURLs[release.Chart.Metadata.Name]
+
+		if !found {
+			err = fmt.Errorf("chart not found")
+
+			app.sendExternalError(err, http.StatusNotFound, HTTPError{
+				Code:   ErrReleaseReadData,
+				Errors: []string{"chart not found"},
+			}, w)
+
+			return nil, err
+		}
+	}
+
+	ch, err := loader.LoadChartPublic(
+		chartRepoURL,
+		release.Chart.Metadata.Name,
+		version,
+	)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusNotFound, HTTPError{
+			Code:   ErrReleaseReadData,
+			Errors: []string{"chart not found"},
+		}, w)
+
+		return nil, err
+	}
+
+	return ch, nil
+}
+
 // HandleReleaseDeployWebhook upgrades a release when a chart specific webhook is called.
+// The token is either the webhook token of the release or a named deploy webhook token.
+// Named tokens can require the request to be signed, and the new image can be passed
+// in a JSON body along with value overrides.
 func (app *App) HandleReleaseDeployWebhook(w http.ResponseWriter, r *http.Request) {
 	token := chi.URLParam(r, "token")
 
-	// retrieve release by token
-	release, err := app.Repo.Release.ReadReleaseByWebhookToken(token)
+	// the raw body is read first, since the signature is computed over it
+	body, err := ioutil.ReadAll(r.Body)
 
 	if err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	release, webhookToken, err := app.getReleaseFromDeployWebhookToken(token)
+
+	if err == errLegacyWebhookTokenDisabled {
+		app.sendExternalError(err, http.StatusForbidden, HTTPError{
+			Code:   ErrReleaseDeploy,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return
+	} else if err != nil {
 		app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
 			Code:   ErrReleaseReadData,
 			Errors: []string{"release not found with given webhook"},
@@ -1193,6 +1391,30 @@ func (app *App) HandleReleaseDeployWebhook(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	if webhookToken != nil {
+		if webhookToken.Revoked {
+			app.sendExternalError(fmt.Errorf("webhook token revoked"), http.StatusForbidden, HTTPError{
+				Code:   ErrReleaseDeploy,
+				Errors: []string{"webhook token has been revoked"},
+			}, w)
+
+			return
+		}
+
+		if len(webhookToken.SigningSecret) > 0 {
+			err := webhook.Verify(webhookToken.SigningSecret, r.Header, body, time.Now(), webhook.DefaultReplayWindow)
+
+			if err != nil {
+				app.sendExternalError(err, http.StatusUnauthorized, HTTPError{
+					Code:   ErrReleaseDeploy,
+					Errors: []string{err.Error()},
+				}, w)
+
+				return
+			}
+		}
+	}
+
 	params := map[string][]string{}
 	params["cluster_id"] = []string{fmt.Sprint(release.ClusterID)}
 	params["storage"] = []string{"secret"}
@@ -1205,6 +1427,43 @@ func (app *App) HandleReleaseDeployWebhook(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	deployForm := &forms.DeployWebhookForm{}
+
+	if len(bytes.TrimSpace(body)) > 0 {
+		if err := json.Unmarshal(body, deployForm); err != nil {
+			app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+			return
+		}
+	}
+
+	// older webhook requests pass the tag in the commit query param
+	if deployForm.Tag == "" {
+		deployForm.Tag = vals.Get("commit")
+	}
+
+	if err := app.validator.Struct(deployForm); err != nil {
+		app.handleErrorFormValidation(err, ErrReleaseValidateFields, w)
+		return
+	}
+
+	if deployForm.Tag == "" && deployForm.Digest == "" {
+		app.sendExternalError(fmt.Errorf("no tag or digest"), http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{"a tag or digest is required"},
+		}, w)
+
+		return
+	}
+
+	if !deployForm.IsValidDigest() {
+		app.sendExternalError(fmt.Errorf("invalid digest"), http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{"digest must be of the form sha256:<hex>"},
+		}, w)
+
+		return
+	}
+
 	form := &forms.UpgradeReleaseForm{
 		ReleaseForm: &forms.ReleaseForm{
 			Form: &helm.Form{
@@ -1233,23 +1492,16 @@ func (app *App) HandleReleaseDeployWebhook(w http.ResponseWriter, r *http.Reques
 
 	rel, err := agent.GetRelease(form.Name, 0, false)
 
-	// repository is set to current repository by default
-	commit := vals["commit"][0]
-	repository := rel.Config["image"].(map[string]interface{})["repository"]
-
-	gitAction := release.GitActionConfig
+	if err != nil {
+		app.sendExternalError(err, http.StatusNotFound, HTTPError{
+			Code:   ErrReleaseReadData,
+			Errors: []string{"release not found"},
+		}, w)
 
-	if gitAction.ID != 0 && (repository == "porterdev/hello-porter" || repository == "public.ecr.aws/o1j4x7p4/hello-porter") {
-		repository = gitAction.ImageRepoURI
-	} else if gitAction.ID != 0 && (repository == "porterdev/hello-porter-job" || repository == "public.ecr.aws/o1j4x7p4/hello-porter-job") {
-		repository = gitAction.ImageRepoURI
+		return
 	}
 
-	image := map[string]interface{}{}
-	image["repository"] = repository
-	image["tag"] = commit
-	rel.Config["image"] = image
-
+	// the overrides are not able to re-enable a disabled webhook
 	if rel.Config["auto_deploy"] == false {
 		app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
 			Code:   ErrReleaseDeploy,
@@ -1259,6 +1511,25 @@ func (app *App) HandleReleaseDeployWebhook(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
+	// copy the current values before the image is changed, to record the diff
+	prevValues := diff.CopyValues(rel.Config)
+	prevRevision := rel.Version
+
+	// repository is set to current repository by default, and is read before the
+	// overrides are merged so that a webhook caller cannot change the image
+	var repository interface{}
+
+	if currImage, ok := rel.Config["image"].(map[string]interface{}); ok {
+		repository = currImage["repository"]
+	}
+
+	repository = getDeployImageRepository(release, repository)
+
+	// the image is only set through the tag or digest of the request
+	delete(deployForm.Values, "image")
+
+	rel.Config = utils.CoalesceValues(rel.Config, deployForm.Values)
+
 	registries, err := app.Repo.Registry.ListRegistriesByProjectID(uint(form.ReleaseForm.Cluster.ProjectID))
 
 	if err != nil {
@@ -1266,30 +1537,35 @@ func (app *App) HandleReleaseDeployWebhook(w http.ResponseWriter, r *http.Reques
 		return
 	}
 
-	conf := &helm.UpgradeReleaseConfig{
-		Name:       form.Name,
-		Cluster:    form.ReleaseForm.Cluster,
-		Repo:       *app.Repo,
-		Registries: registries,
-		Values:     rel.Config,
-	}
-
-	slackInts, _ := app.Repo.SlackIntegration.ListSlackIntegrationsByProjectID(uint(form.ReleaseForm.Cluster.ProjectID))
-
-	var notifConf *models.NotificationConfigExternal
-	notifConf = nil
-	if release != nil && release.NotificationConfig != 0 {
-		conf, err := app.Repo.NotificationConfig.ReadNotificationConfig(release.NotificationConfig)
+	// the tag is resolved to its digest at deploy time, so that the release keeps
+	// running the same image if the tag is pushed again
+	if release.PinImageDigest && deployForm.Digest == "" {
+		digest, err := app.resolveImageDigest(registries, fmt.Sprintf("%v", repository), deployForm.Tag)
 
 		if err != nil {
-			app.handleErrorInternal(err, w)
+			app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+				Code:   ErrReleaseDeploy,
+				Errors: []string{err.Error()},
+			}, w)
+
 			return
 		}
 
-		notifConf = conf.Externalize()
+		deployForm.Digest = digest
 	}
 
-	notifier := slack.NewSlackNotifier(notifConf, slackInts...)
+	image := map[string]interface{}{}
+	image["repository"] = repository
+	image["tag"] = deployForm.ImageTag()
+	rel.Config["image"] = image
+
+	conf := &helm.UpgradeReleaseConfig{
+		Name:       form.Name,
+		Cluster:    form.ReleaseForm.Cluster,
+		Repo:       *app.Repo,
+		Registries: registries,
+		Values:     rel.Config,
+	}
 
 	notifyOpts := &slack.NotifyOpts{
 		ProjectID:   uint(form.ReleaseForm.Cluster.ProjectID),
@@ -1297,6 +1573,7 @@ func (app *App) HandleReleaseDeployWebhook(w http.ResponseWriter, r *http.Reques
 		ClusterName: form.Cluster.Name,
 		Name:        rel.Name,
 		Namespace:   rel.Namespace,
+		Trigger:     models.NotificationTriggerWebhook,
 		URL: fmt.Sprintf(
 			"%s/applications/%s/%s/%s",
 			app.ServerConf.ServerURL,
@@ -1306,13 +1583,72 @@ func (app *App) HandleReleaseDeployWebhook(w http.ResponseWriter, r *http.Reques
 		) + fmt.Sprintf("?project_id=%d", uint(form.ReleaseForm.Cluster.ProjectID)),
 	}
 
+	deployment := &models.Deployment{
+		ProjectID: uint(form.ReleaseForm.Cluster.ProjectID),
+		ClusterID: form.Cluster.ID,
+		Namespace: release.Namespace,
+		Name:      release.Name,
+		Trigger:   models.DeploymentTriggerWebhook,
+		GitCommit: deployForm.Tag,
+	}
+
+	if webhookToken != nil {
+		deployment.DeployWebhookTokenID = webhookToken.ID
+
+		now := time.Now()
+		webhookToken.LastUsedAt = &now
+
+		if _, err := app.Repo.DeployWebhookToken.UpdateDeployWebhookToken(webhookToken); err != nil {
+			app.Logger.Warn().Err(err).Msg("could not update last use of deploy webhook token")
+		}
+	}
+
+	if release.VulnerabilityBlockSeverity != "" {
+		scan, err := app.scanImage(r.Context(), registries, fmt.Sprintf("%v", repository), deployForm.ImageTag())
+
+		err = checkVulnerabilityPolicy(release, scan, err)
+
+		// the digest that was checked is deployed, even if the tag is pushed again
+		if err == nil {
+			var pinnedTag string
+
+			if pinnedTag, err = pinScannedDigest(deployForm.ImageTag(), scan); err == nil {
+				image["tag"] = pinnedTag
+			}
+		}
+
+		if err != nil {
+			notifyOpts.Status = slack.StatusFailed
+			notifyOpts.Info = err.Error()
+
+			app.notifyRelease(release, uint(form.ReleaseForm.Cluster.ProjectID), notifyOpts)
+
+			deployment.Status = models.DeploymentStatusFailed
+			deployment.Info = err.Error()
+
+			app.recordDeployment(deployment, prevValues, conf.Values)
+
+			app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+				Code:   ErrReleaseDeploy,
+				Errors: []string{err.Error()},
+			}, w)
+
+			return
+		}
+	}
+
 	rel, err = agent.UpgradeReleaseByValues(conf, app.DOConf)
 
 	if err != nil {
 		notifyOpts.Status = slack.StatusFailed
 		notifyOpts.Info = err.Error()
 
-		notifier.Notify(notifyOpts)
+		app.notifyRelease(release, uint(form.ReleaseForm.Cluster.ProjectID), notifyOpts)
+
+		deployment.Status = models.DeploymentStatusFailed
+		deployment.Info = err.Error()
+
+		app.recordDeployment(deployment, prevValues, conf.Values)
 
 		app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
 			Code:   ErrReleaseDeploy,
@@ -1325,7 +1661,18 @@ func (app *App) HandleReleaseDeployWebhook(w http.ResponseWriter, r *http.Reques
 	notifyOpts.Status = string(rel.Info.Status)
 	notifyOpts.Version = rel.Version
 
-	notifier.Notify(notifyOpts)
+	app.notifyRelease(release, uint(form.ReleaseForm.Cluster.ProjectID), notifyOpts)
+
+	deployment.Revision = rel.Version
+	deployment.Status = string(rel.Info.Status)
+
+	app.recordDeployment(deployment, prevValues, rel.Config)
+
+	go app.watchAutoRollback(release, *form.ReleaseForm.Form, rel, prevRevision, *notifyOpts)
+
+	// the preview workflow deploys the head of a pull request by upgrading the copy
+	// of the release in its preview environment
+	app.markPreviewEnvironmentDeployed(form.Cluster.ID, rel.Namespace, rel.Name)
 
 	userID, _ := app.getUserIDFromRequest(r)
 
@@ -1344,6 +1691,73 @@ func (app *App) HandleReleaseDeployWebhook(w http.ResponseWriter, r *http.Reques
 	w.WriteHeader(http.StatusOK)
 }
 
+// placeholderImageRepos are the images that a release is created with before the
+// first image has been built from source
+var placeholderImageRepos = map[string]bool{
+	"porterdev/hello-porter":                   true,
+	"porterdev/hello-porter-job":               true,
+	"public.ecr.aws/o1j4x7p4/hello-porter":     true,
+	"public.ecr.aws/o1j4x7p4/hello-porter-job": true,
+}
+
+// getDeployImageRepository returns the image repository to deploy a release with. A
+// placeholder image is replaced with the image repository that the release's GitHub
+// action pushes to.
+func getDeployImageRepository(release *models.Release, current interface{}) interface{} {
+	repoStr, _ := current.(string)
+
+	if release.GitActionConfig.ID != 0 && placeholderImageRepos[repoStr] {
+		return release.GitActionConfig.ImageRepoURI
+	}
+
+	return current
+}
+
+// errLegacyWebhookTokenDisabled is returned when the webhook token of a release is
+// used after named deploy webhook tokens have been created for the release
+var errLegacyWebhookTokenDisabled = fmt.Errorf("the release webhook token is disabled since the release has named deploy webhook tokens")
+
+// getReleaseFromDeployWebhookToken finds the release of a deploy webhook token. The
+// token is either the webhook token of the release, in which case the returned
+// named token is nil, or a named deploy webhook token.
+func (app *App) getReleaseFromDeployWebhookToken(token string) (*models.Release, *models.DeployWebhookToken, error) {
+	if token == "" {
+		return nil, nil, fmt.Errorf("empty webhook token")
+	}
+
+	release, err := app.Repo.Release.ReadReleaseByWebhookToken(token)
+
+	if err == nil {
+		// the webhook token of the release cannot be signed or revoked, so it is
+		// no longer accepted once the release has named deploy webhook tokens
+		webhookTokens, err := app.Repo.DeployWebhookToken.ListDeployWebhookTokensByReleaseID(release.ID)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if len(webhookTokens) > 0 {
+			return nil, nil, errLegacyWebhookTokenDisabled
+		}
+
+		return release, nil, nil
+	}
+
+	webhookToken, err := app.Repo.DeployWebhookToken.ReadDeployWebhookTokenByToken(token)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	release, err = app.Repo.Release.ReadReleaseByID(webhookToken.ReleaseID)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return release, webhookToken, nil
+}
+
 // HandleReleaseJobUpdateImage
 func (app *App) HandleReleaseUpdateJobImages(w http.ResponseWriter, r *http.Request) {
 	vals, err := url.ParseQuery(r.URL.RawQuery)
@@ -1372,7 +1786,11 @@ func (app *App) HandleReleaseUpdateJobImages(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
-	releases, err := app.Repo.Release.ListReleasesByImageRepoURI(form.Cluster.ID, form.ImageRepoURI)
+	if !checkReleaseFormTarget(w, vals, form.Namespace, "", "") {
+		return
+	}
+
+	allReleases, err := app.Repo.Release.ListReleasesByImageRepoURI(form.Cluster.ID, form.ImageRepoURI)
 
 	if err != nil {
 		app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
@@ -1383,6 +1801,16 @@ func (app *App) HandleReleaseUpdateJobImages(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	// only the releases of the authorized namespace are updated, unless the request
+	// was authorized for the whole cluster
+	releases := make([]*models.Release, 0)
+
+	for _, release := range allReleases {
+		if form.Namespace == "" || release.Namespace == form.Namespace {
+			releases = append(releases, release)
+		}
+	}
+
 	agent, err := app.getAgentFromReleaseForm(
 		w,
 		r,
@@ -1401,6 +1829,41 @@ func (app *App) HandleReleaseUpdateJobImages(w http.ResponseWriter, r *http.Requ
 		return
 	}
 
+	// the actor is read once, since the request is shared by the goroutines
+	actor := &models.Deployment{}
+	app.setDeploymentActor(actor, r)
+
+	// the tag is resolved to its digest once, so that every release that pins its
+	// image is upgraded to the same image
+	imageTag := form.Tag
+	var pinErr error
+
+	for _, release := range releases {
+		if release.PinImageDigest && !strings.Contains(form.Tag, "@") {
+			digest, err := app.resolveImageDigest(registries, form.ImageRepoURI, form.Tag)
+
+			if err != nil {
+				pinErr = err
+			} else {
+				imageTag = form.Tag + "@" + digest
+			}
+
+			break
+		}
+	}
+
+	// the image is scanned once, if any of the releases blocks vulnerable images
+	var scan *registry.ImageScan
+	var scanErr error
+
+	for _, release := range releases {
+		if release.VulnerabilityBlockSeverity != "" {
+			scan, scanErr = app.scanImage(r.Context(), registries, form.ImageRepoURI, imageTag)
+
+			break
+		}
+	}
+
 	// asynchronously update releases with that image repo uri
 	var wg sync.WaitGroup
 	mu := &sync.Mutex{}
@@ -1419,12 +1882,21 @@ func (app *App) HandleReleaseUpdateJobImages(w http.ResponseWriter, r *http.Requ
 				mu.Lock()
 				errors = append(errors, err.Error())
 				mu.Unlock()
+
+				return
 			}
 
 			if rel.Chart.Name() == "job" {
+				prevValues := diff.CopyValues(rel.Config)
+
 				image := map[string]interface{}{}
 				image["repository"] = releases[index].ImageRepoURI
 				image["tag"] = form.Tag
+
+				if releases[index].PinImageDigest {
+					image["tag"] = imageTag
+				}
+
 				rel.Config["image"] = image
 				rel.Config["paused"] = true
 
@@ -1436,13 +1908,62 @@ func (app *App) HandleReleaseUpdateJobImages(w http.ResponseWriter, r *http.Requ
 					Values:     rel.Config,
 				}
 
-				_, err = agent.UpgradeReleaseByValues(conf, app.DOConf)
+				deployment := &models.Deployment{
+					ProjectID:  uint(form.ReleaseForm.Cluster.ProjectID),
+					ClusterID:  form.Cluster.ID,
+					Namespace:  releases[index].Namespace,
+					Name:       releases[index].Name,
+					UserID:     actor.UserID,
+					APITokenID: actor.APITokenID,
+					Trigger:    models.DeploymentTriggerBatchImageUpdate,
+				}
+
+				// releases are not upgraded to an image that could not be pinned
+				// to its digest, or that their vulnerability policy blocks
+				blockErr := checkVulnerabilityPolicy(releases[index], scan, scanErr)
+
+				if releases[index].PinImageDigest && pinErr != nil {
+					blockErr = pinErr
+				}
+
+				// releases that block vulnerable images deploy the digest that was
+				// checked, even if the tag is pushed again
+				if blockErr == nil && releases[index].VulnerabilityBlockSeverity != "" {
+					var pinnedTag string
+
+					if pinnedTag, blockErr = pinScannedDigest(imageTag, scan); blockErr == nil {
+						image["tag"] = pinnedTag
+					}
+				}
+
+				if blockErr != nil {
+					mu.Lock()
+					errors = append(errors, blockErr.Error())
+					mu.Unlock()
+
+					deployment.Status = models.DeploymentStatusFailed
+					deployment.Info = blockErr.Error()
+
+					app.recordDeployment(deployment, prevValues, conf.Values)
+
+					return
+				}
+
+				newRel, err := agent.UpgradeReleaseByValues(conf, app.DOConf)
 
 				if err != nil {
 					mu.Lock()
 					errors = append(errors, err.Error())
 					mu.Unlock()
+
+					deployment.Status = models.DeploymentStatusFailed
+					deployment.Info = err.Error()
+				} else {
+					deployment.Revision = newRel.Version
+					deployment.Status = string(newRel.Info.Status)
 				}
+
+				app.recordDeployment(deployment, prevValues, conf.Values)
 			}
 		}()
 	}
@@ -1483,6 +2004,10 @@ func (app *App) HandleRollbackRelease(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	if !checkReleaseFormTarget(w, vals, form.Namespace, form.Name, name) {
+		return
+	}
+
 	agent, err := app.getAgentFromReleaseForm(
 		w,
 		r,
@@ -1494,9 +2019,32 @@ func (app *App) HandleRollbackRelease(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// read the values of the current revision to record the diff of the deployment
+	var prevValues map[string]interface{}
+
+	if prevRel, err := agent.GetRelease(form.Name, 0, false); err == nil {
+		prevValues = prevRel.Config
+	}
+
+	deployment := &models.Deployment{
+		ProjectID: form.Cluster.ProjectID,
+		ClusterID: form.Cluster.ID,
+		Namespace: form.Namespace,
+		Name:      form.Name,
+		Trigger:   models.DeploymentTriggerRollback,
+		Info:      fmt.Sprintf("rolled back to revision %d", form.Revision),
+	}
+
+	app.setDeploymentActor(deployment, r)
+
 	err = agent.RollbackRelease(form.Name, form.Revision)
 
 	if err != nil {
+		deployment.Status = models.DeploymentStatusFailed
+		deployment.Info = err.Error()
+
+		app.recordDeployment(deployment, prevValues, prevValues)
+
 		app.sendExternalError(err, http.StatusInternalServerError, HTTPError{
 			Code:   ErrReleaseDeploy,
 			Errors: []string{"error rolling back release " + err.Error()},
@@ -1505,6 +2053,16 @@ func (app *App) HandleRollbackRelease(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// the rollback creates a new revision with the values of the target revision
+	if latestRel, err := agent.GetRelease(form.Name, 0, false); err == nil {
+		deployment.Revision = latestRel.Version
+		deployment.Status = string(latestRel.Info.Status)
+
+		app.recordDeployment(deployment, prevValues, latestRel.Config)
+	} else {
+		app.Logger.Warn().Err(err).Msg("could not read release to record rollback")
+	}
+
 	// get the full release data for GHA updating
 	rel, err := agent.GetRelease(form.Name, form.Revision, false)
 
@@ -1777,6 +2335,22 @@ func (app *App) getAgentFromQueryParams(
 	return app.getAgentFromReleaseForm(w, r, form)
 }
 
+// checkReleaseFormTarget checks that the body of a request, which is decoded over a
+// form that was populated from the query and URL parameters, does not change the
+// namespace or release name that the auth middleware authorized. A namespace in the
+// body is only accepted if the query has none, since the middleware then required
+// access to the whole cluster.
+func checkReleaseFormTarget(w http.ResponseWriter, vals url.Values, namespace, name, authorizedName string) bool {
+	queryNamespace := vals.Get("namespace")
+
+	if len(vals["namespace"]) > 1 || (queryNamespace != "" && namespace != queryNamespace) || name != authorizedName {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return false
+	}
+
+	return true
+}
+
 // getAgentFromReleaseForm uses a non-validated form to construct a new Helm agent based on
 // the userID found in the session and the options required by the Helm agent.
 func (app *App) getAgentFromReleaseForm(
@@ -1798,7 +2372,7 @@ func (app *App) getAgentFromReleaseForm(
 	if app.ServerConf.IsTesting {
 		agent = app.TestAgents.HelmAgent
 	} else {
-		agent, err = helm.GetAgentOutOfClusterConfig(form.Form, app.Logger)
+		agent, err = helm.GetAgentFromPool(form.Form, app.Logger, app.AgentPool)
 	}
 
 	if err != nil {
@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/models"
 
 	"helm.sh/helm/v3/pkg/chart"
 	"helm.sh/helm/v3/pkg/release"
@@ -505,3 +506,90 @@ func releaseReleaseArrBodyValidator(c *releaseTest, tester *tester, t *testing.T
 			c.msg, gotBody, expBody)
 	}
 }
+
+var releaseTargetTests = []*releaseTest{
+	&releaseTest{
+		initializers: []func(tester *tester){
+			initReleaseTargetCluster,
+		},
+		msg:       "Upgrade release in another namespace than the authorized one",
+		method:    "POST",
+		namespace: "default",
+		endpoint: "/api/projects/1/releases/wordpress/upgrade?" + url.Values{
+			"cluster_id": []string{"1"},
+			"namespace":  []string{"default"},
+			"storage":    []string{"memory"},
+		}.Encode(),
+		body: `
+			{
+				"namespace": "production",
+				"values": "foo: bar"
+			}
+		`,
+		expStatus:  http.StatusForbidden,
+		expBody:    http.StatusText(http.StatusForbidden) + "\n",
+		useCookie:  true,
+		validators: []func(c *releaseTest, tester *tester, t *testing.T){},
+	},
+	&releaseTest{
+		initializers: []func(tester *tester){
+			initReleaseTargetCluster,
+		},
+		msg:       "Roll back another release than the authorized one",
+		method:    "POST",
+		namespace: "default",
+		endpoint: "/api/projects/1/releases/wordpress/rollback?" + url.Values{
+			"cluster_id": []string{"1"},
+			"namespace":  []string{"default"},
+			"storage":    []string{"memory"},
+		}.Encode(),
+		body: `
+			{
+				"name": "api",
+				"revision": 1
+			}
+		`,
+		expStatus:  http.StatusForbidden,
+		expBody:    http.StatusText(http.StatusForbidden) + "\n",
+		useCookie:  true,
+		validators: []func(c *releaseTest, tester *tester, t *testing.T){},
+	},
+	&releaseTest{
+		initializers: []func(tester *tester){
+			initReleaseTargetCluster,
+		},
+		msg:       "Upgrade release with a repeated namespace parameter",
+		method:    "POST",
+		namespace: "default",
+		endpoint: "/api/projects/1/releases/wordpress/upgrade?" + url.Values{
+			"cluster_id": []string{"1"},
+			"namespace":  []string{"default", "production"},
+			"storage":    []string{"memory"},
+		}.Encode(),
+		body: `
+			{
+				"values": "foo: bar"
+			}
+		`,
+		expStatus:  http.StatusForbidden,
+		expBody:    http.StatusText(http.StatusForbidden) + "\n",
+		useCookie:  true,
+		validators: []func(c *releaseTest, tester *tester, t *testing.T){},
+	},
+}
+
+// initReleaseTargetCluster creates a cluster without credentials, since the requests
+// are rejected before the cluster is connected to
+func initReleaseTargetCluster(tester *tester) {
+	initUserDefault(tester)
+	initProject(tester)
+
+	tester.repo.Cluster.CreateCluster(&models.Cluster{
+		ProjectID: 1,
+		Name:      "cluster-test",
+	})
+}
+
+func TestReleaseFormTarget(t *testing.T) {
+	testReleaseRequests(t, releaseTargetTests, true)
+}
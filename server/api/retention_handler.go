@@ -0,0 +1,445 @@
+package api
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/kubernetes"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/registry"
+	"gorm.io/gorm"
+)
+
+// HandleCreateRetentionPolicy creates the retention policy of a registry
+// repository, or updates it if the repository already has a policy
+func (app *App) HandleCreateRetentionPolicy(w http.ResponseWriter, r *http.Request) {
+	reg, ok := app.readRetentionRegistry(w, r)
+
+	if !ok {
+		return
+	}
+
+	form := &forms.CreateRetentionPolicyForm{}
+
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrProjectValidateFields, w)
+		return
+	}
+
+	existing, err := app.Repo.RetentionPolicy.ReadRetentionPolicyByRepository(reg.ID, form.RepositoryName)
+
+	if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	policy := form.ToRetentionPolicy(reg.ProjectID, reg.ID, existing)
+
+	if existing != nil {
+		policy, err = app.Repo.RetentionPolicy.UpdateRetentionPolicy(policy)
+	} else {
+		policy, err = app.Repo.RetentionPolicy.CreateRetentionPolicy(policy)
+	}
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	if existing != nil {
+		w.WriteHeader(http.StatusOK)
+	} else {
+		w.WriteHeader(http.StatusCreated)
+	}
+
+	if err := json.NewEncoder(w).Encode(policy.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleListRetentionPolicies lists the retention policies of a registry
+func (app *App) HandleListRetentionPolicies(w http.ResponseWriter, r *http.Request) {
+	reg, ok := app.readRetentionRegistry(w, r)
+
+	if !ok {
+		return
+	}
+
+	policies, err := app.Repo.RetentionPolicy.ListRetentionPoliciesByRegistryID(reg.ID)
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	extPolicies := make([]*models.RetentionPolicyExternal, 0)
+
+	for _, policy := range policies {
+		extPolicies = append(extPolicies, policy.Externalize())
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(extPolicies); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleGetRetentionReport evaluates a retention policy without deleting any
+// images, and lists the images that the policy would keep and delete
+func (app *App) HandleGetRetentionReport(w http.ResponseWriter, r *http.Request) {
+	reg, ok := app.readRetentionRegistry(w, r)
+
+	if !ok {
+		return
+	}
+
+	policy, ok := app.readRetentionPolicy(w, r, reg)
+
+	if !ok {
+		return
+	}
+
+	deployed := newDeployedImageScan(app)
+
+	report, _, err := app.evaluateRetentionPolicy(reg, policy, deployed)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(report); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleDeleteRetentionPolicy deletes a retention policy. Images that were already
+// deleted by the policy are not restored.
+func (app *App) HandleDeleteRetentionPolicy(w http.ResponseWriter, r *http.Request) {
+	reg, ok := app.readRetentionRegistry(w, r)
+
+	if !ok {
+		return
+	}
+
+	policy, ok := app.readRetentionPolicy(w, r, reg)
+
+	if !ok {
+		return
+	}
+
+	if err := app.Repo.RetentionPolicy.DeleteRetentionPolicy(policy.ID); err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// RunImageRetention applies the enabled retention policies of every registry at
+// the given interval, until stop is closed
+func (app *App) RunImageRetention(stop <-chan struct{}, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	app.applyRetentionPolicies()
+
+	for {
+		select {
+		case <-ticker.C:
+			app.applyRetentionPolicies()
+		case <-stop:
+			return
+		}
+	}
+}
+
+func (app *App) applyRetentionPolicies() {
+	policies, err := app.Repo.RetentionPolicy.ListEnabledRetentionPolicies()
+
+	if err != nil {
+		app.Logger.Warn().Err(err).Msg("could not list image retention policies")
+		return
+	}
+
+	// the clusters of each project are scanned once per run
+	deployed := newDeployedImageScan(app)
+
+	for _, policy := range policies {
+		app.applyRetentionPolicy(policy, deployed)
+	}
+}
+
+// applyRetentionPolicy deletes the images that a retention policy does not keep.
+// If a cluster of the project could not be scanned for deployed images, no images
+// are deleted, since the images that it deploys are not known.
+func (app *App) applyRetentionPolicy(policy *models.RetentionPolicy, deployed *deployedImageScan) {
+	reg, err := app.Repo.Registry.ReadRegistry(policy.RegistryID)
+
+	if err != nil {
+		app.Logger.Warn().Err(err).Msgf("could not read registry %d for image retention", policy.RegistryID)
+		return
+	}
+
+	now := time.Now()
+	policy.LastRunAt = &now
+	policy.LastRunDeleted = 0
+	policy.LastRunError = ""
+
+	report, images, err := app.evaluateRetentionPolicy(reg, policy, deployed)
+
+	switch {
+	case err != nil:
+		policy.LastRunError = err.Error()
+	case len(report.Warnings) > 0:
+		policy.LastRunError = fmt.Sprintf("no images were deleted: %s", strings.Join(report.Warnings, "; "))
+	case len(report.Deleted) > 0:
+		toDelete := make([]*registry.Image, 0, len(report.Deleted))
+
+		for _, decision := range report.Deleted {
+			toDelete = append(toDelete, decision.Image)
+		}
+
+		_reg := registry.Registry(*reg)
+
+		if err := _reg.DeleteImages(policy.RepositoryName, toDelete, *app.Repo, app.DOConf); err != nil {
+			policy.LastRunError = err.Error()
+		} else {
+			policy.LastRunDeleted = uint(len(toDelete))
+		}
+	}
+
+	if policy.LastRunError != "" {
+		app.Logger.Warn().Msgf(
+			"image retention for repository %s of registry %d: %s",
+			policy.RepositoryName,
+			reg.ID,
+			policy.LastRunError,
+		)
+	} else {
+		app.Logger.Info().Msgf(
+			"image retention deleted %d of %d tags of repository %s of registry %d",
+			policy.LastRunDeleted,
+			len(images),
+			policy.RepositoryName,
+			reg.ID,
+		)
+	}
+
+	if _, err := app.Repo.RetentionPolicy.UpdateRetentionPolicy(policy); err != nil {
+		app.Logger.Warn().Err(err).Msgf("could not update image retention policy %d", policy.ID)
+	}
+}
+
+// evaluateRetentionPolicy lists the images of the repository of a retention policy
+// and decides which of them are kept and deleted
+func (app *App) evaluateRetentionPolicy(
+	reg *models.Registry,
+	policy *models.RetentionPolicy,
+	deployed *deployedImageScan,
+) (*registry.RetentionReport, []*registry.Image, error) {
+	_reg := registry.Registry(*reg)
+
+	if !_reg.SupportsRetention() {
+		return nil, nil, fmt.Errorf("image retention is only supported for ECR and DOCR registries")
+	}
+
+	images, err := _reg.ListImages(policy.RepositoryName, *app.Repo, app.DOConf)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	deployedImages := registry.NewDeployedImages()
+	warnings := make([]string, 0)
+
+	if policy.KeepDeployed {
+		refs, scanWarnings, err := deployed.get(reg.ProjectID)
+
+		if err != nil {
+			return nil, nil, err
+		}
+
+		deployedImages.Add(refs, _reg.GetRepositoryURI(policy.RepositoryName))
+		warnings = scanWarnings
+	}
+
+	report := registry.EvaluateRetention(policy, images, deployedImages, time.Now())
+	report.Warnings = warnings
+
+	return report, images, nil
+}
+
+func (app *App) readRetentionRegistry(w http.ResponseWriter, r *http.Request) (*models.Registry, bool) {
+	regID, err := strconv.ParseUint(chi.URLParam(r, "registry_id"), 0, 64)
+
+	if err != nil || regID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return nil, false
+	}
+
+	reg, err := app.Repo.Registry.ReadRegistry(uint(regID))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return nil, false
+	}
+
+	if !(*registry.Registry)(reg).SupportsRetention() {
+		app.sendExternalError(fmt.Errorf("registry does not support retention"), http.StatusBadRequest, HTTPError{
+			Code:   ErrProjectValidateFields,
+			Errors: []string{"image retention is only supported for ECR and DOCR registries"},
+		}, w)
+
+		return nil, false
+	}
+
+	return reg, true
+}
+
+func (app *App) readRetentionPolicy(
+	w http.ResponseWriter,
+	r *http.Request,
+	reg *models.Registry,
+) (*models.RetentionPolicy, bool) {
+	policyID, err := strconv.ParseUint(chi.URLParam(r, "policy_id"), 0, 64)
+
+	if err != nil || policyID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return nil, false
+	}
+
+	policy, err := app.Repo.RetentionPolicy.ReadRetentionPolicy(reg.ID, uint(policyID))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return nil, false
+	}
+
+	return policy, true
+}
+
+// deployedImageScan lists the image references that are deployed in the clusters
+// of a project, scanning each project at most once
+type deployedImageScan struct {
+	app      *App
+	refs     map[uint][]string
+	warnings map[uint][]string
+}
+
+func newDeployedImageScan(app *App) *deployedImageScan {
+	return &deployedImageScan{
+		app:      app,
+		refs:     make(map[uint][]string),
+		warnings: make(map[uint][]string),
+	}
+}
+
+// get returns the image references of a project, along with a warning for each
+// cluster that could not be scanned
+func (s *deployedImageScan) get(projectID uint) ([]string, []string, error) {
+	if refs, ok := s.refs[projectID]; ok {
+		return refs, s.warnings[projectID], nil
+	}
+
+	clusters, err := s.app.Repo.Cluster.ListClustersByProjectID(projectID)
+
+	if err != nil {
+		return nil, nil, err
+	}
+
+	refs := make([]string, 0)
+	warnings := make([]string, 0)
+
+	for _, listed := range clusters {
+		clusterRefs, err := s.scanCluster(listed.ID)
+
+		if err != nil {
+			warnings = append(warnings, fmt.Sprintf("could not scan cluster %s for deployed images: %v", listed.Name, err))
+			continue
+		}
+
+		refs = append(refs, clusterRefs...)
+	}
+
+	s.refs[projectID] = refs
+	s.warnings[projectID] = warnings
+
+	return refs, warnings, nil
+}
+
+// scanCluster lists the images that are deployed in a cluster, along with the images
+// of every stored Helm revision, since the releases of the cluster can be rolled
+// back to them
+func (s *deployedImageScan) scanCluster(clusterID uint) ([]string, error) {
+	if s.app.ServerConf.IsTesting {
+		return s.app.TestAgents.K8sAgent.ListImageRefs()
+	}
+
+	// read the cluster again to load the token cache
+	cluster, err := s.app.Repo.Cluster.ReadCluster(clusterID)
+
+	if err != nil {
+		return nil, err
+	}
+
+	agent, err := s.app.AgentPool.GetAgent(&kubernetes.OutOfClusterConfig{
+		Cluster:           cluster,
+		Repo:              s.app.Repo,
+		DigitalOceanOAuth: s.app.DOConf,
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	refs, err := agent.ListImageRefs()
+
+	if err != nil {
+		return nil, err
+	}
+
+	// releases may be stored in the cluster or in the Porter database, and the
+	// empty namespace reads the releases of every namespace
+	for _, storage := range []string{"secret", helm.SQLDriverName} {
+		helmAgent, err := helm.GetAgentFromPool(&helm.Form{
+			Cluster:           cluster,
+			Repo:              s.app.Repo,
+			DigitalOceanOAuth: s.app.DOConf,
+			Storage:           storage,
+		}, s.app.Logger, s.app.AgentPool)
+
+		if err != nil {
+			return nil, err
+		}
+
+		revisionRefs, err := helmAgent.ListRevisionImageRefs()
+
+		if err != nil {
+			return nil, fmt.Errorf("could not list the images of %s release revisions: %v", storage, err)
+		}
+
+		refs = append(refs, revisionRefs...)
+	}
+
+	return refs, nil
+}
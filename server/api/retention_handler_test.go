@@ -0,0 +1,130 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/go-test/deep"
+	"github.com/porter-dev/porter/internal/models"
+)
+
+// ------------------------- TEST FIXTURES AND FUNCTIONS  ------------------------- //
+
+var createRetentionPolicyTests = []*regTest{
+	&regTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+			initRegistry,
+		},
+		msg:       "Create retention policy",
+		method:    "POST",
+		endpoint:  "/api/projects/1/registries/1/retention_policies",
+		body:      `{"repository_name":"web","keep_last":20}`,
+		expStatus: http.StatusCreated,
+		expBody:   `{"id":1,"project_id":1,"registry_id":1,"repository_name":"web","keep_last":20,"max_age_days":0,"keep_deployed":true,"enabled":false,"last_run_deleted":0}`,
+		useCookie: true,
+		validators: []func(c *regTest, tester *tester, t *testing.T){
+			retentionPolicyBodyValidator,
+		},
+	},
+	&regTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+			initRegistry,
+			initRetentionPolicy,
+		},
+		msg:       "Update retention policy of a repository",
+		method:    "POST",
+		endpoint:  "/api/projects/1/registries/1/retention_policies",
+		body:      `{"repository_name":"web","max_age_days":30,"keep_deployed":false,"enabled":true}`,
+		expStatus: http.StatusOK,
+		expBody:   `{"id":1,"project_id":1,"registry_id":1,"repository_name":"web","keep_last":0,"max_age_days":30,"keep_deployed":false,"enabled":true,"last_run_deleted":0}`,
+		useCookie: true,
+		validators: []func(c *regTest, tester *tester, t *testing.T){
+			retentionPolicyBodyValidator,
+		},
+	},
+	&regTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+			initRegistry,
+		},
+		msg:       "Create retention policy without deletion rules",
+		method:    "POST",
+		endpoint:  "/api/projects/1/registries/1/retention_policies",
+		body:      `{"repository_name":"web","keep_deployed":true}`,
+		expStatus: http.StatusUnprocessableEntity,
+		useCookie: true,
+	},
+}
+
+func TestHandleCreateRetentionPolicy(t *testing.T) {
+	testRegistryRequests(t, createRetentionPolicyTests, true)
+}
+
+var listRetentionPoliciesTests = []*regTest{
+	&regTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+			initRegistry,
+			initRetentionPolicy,
+		},
+		msg:       "List retention policies",
+		method:    "GET",
+		endpoint:  "/api/projects/1/registries/1/retention_policies",
+		body:      ``,
+		expStatus: http.StatusOK,
+		expBody:   `[{"id":1,"project_id":1,"registry_id":1,"repository_name":"web","keep_last":20,"max_age_days":0,"keep_deployed":true,"enabled":false,"last_run_deleted":0}]`,
+		useCookie: true,
+		validators: []func(c *regTest, tester *tester, t *testing.T){
+			retentionPoliciesBodyValidator,
+		},
+	},
+}
+
+func TestHandleListRetentionPolicies(t *testing.T) {
+	testRegistryRequests(t, listRetentionPoliciesTests, true)
+}
+
+// ------------------------- INITIALIZERS AND VALIDATORS ------------------------- //
+
+func initRetentionPolicy(tester *tester) {
+	tester.repo.RetentionPolicy.CreateRetentionPolicy(&models.RetentionPolicy{
+		ProjectID:      1,
+		RegistryID:     1,
+		RepositoryName: "web",
+		KeepLast:       20,
+		KeepDeployed:   true,
+	})
+}
+
+func retentionPolicyBodyValidator(c *regTest, tester *tester, t *testing.T) {
+	gotBody := &models.RetentionPolicyExternal{}
+	expBody := &models.RetentionPolicyExternal{}
+
+	json.Unmarshal(tester.rr.Body.Bytes(), gotBody)
+	json.Unmarshal([]byte(c.expBody), expBody)
+
+	if diff := deep.Equal(gotBody, expBody); diff != nil {
+		t.Errorf("handler returned wrong body:\n")
+		t.Error(diff)
+	}
+}
+
+func retentionPoliciesBodyValidator(c *regTest, tester *tester, t *testing.T) {
+	gotBody := make([]*models.RetentionPolicyExternal, 0)
+	expBody := make([]*models.RetentionPolicyExternal, 0)
+
+	json.Unmarshal(tester.rr.Body.Bytes(), &gotBody)
+	json.Unmarshal([]byte(c.expBody), &expBody)
+
+	if diff := deep.Equal(gotBody, expBody); diff != nil {
+		t.Errorf("handler returned wrong body:\n")
+		t.Error(diff)
+	}
+}
@@ -0,0 +1,586 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/helm/grapher"
+	"github.com/porter-dev/porter/internal/helm/rollout"
+	"github.com/porter-dev/porter/internal/integrations/slack"
+	"github.com/porter-dev/porter/internal/kubernetes/prometheus"
+	"github.com/porter-dev/porter/internal/models"
+	"golang.org/x/oauth2"
+	"helm.sh/helm/v3/pkg/chart"
+	"helm.sh/helm/v3/pkg/chartutil"
+	"helm.sh/helm/v3/pkg/release"
+
+	v1 "k8s.io/api/core/v1"
+)
+
+// HandleListRollouts lists the rollouts of a release, newest first
+func (app *App) HandleListRollouts(w http.ResponseWriter, r *http.Request) {
+	vals, err := url.ParseQuery(r.URL.RawQuery)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	clusterID, err := strconv.ParseUint(vals.Get("cluster_id"), 10, 64)
+
+	if err != nil || clusterID == 0 {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	rollouts, err := app.Repo.Rollout.ListRolloutsByRelease(
+		uint(clusterID),
+		vals.Get("namespace"),
+		chi.URLParam(r, "name"),
+	)
+
+	if err != nil {
+		app.handleErrorRead(err, ErrReleaseReadData, w)
+		return
+	}
+
+	res := make([]*models.RolloutExternal, 0)
+
+	for _, rollout := range rollouts {
+		res = append(res, rollout.Externalize())
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// HandleCancelRollout cancels a rollout that is in progress, and removes its canary
+// release. A rollout that is run by this server is stopped at the end of its
+// current step.
+func (app *App) HandleCancelRollout(w http.ResponseWriter, r *http.Request) {
+	vals, err := url.ParseQuery(r.URL.RawQuery)
+
+	if err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	clusterID, err := strconv.ParseUint(vals.Get("cluster_id"), 10, 64)
+
+	if err != nil || clusterID == 0 {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	rolloutID, err := strconv.ParseUint(chi.URLParam(r, "rollout_id"), 0, 64)
+
+	if err != nil || rolloutID == 0 {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	model, err := app.Repo.Rollout.ReadRollout(uint(rolloutID))
+
+	if err != nil || model.ClusterID != uint(clusterID) ||
+		model.Namespace != vals.Get("namespace") || model.ReleaseName != chi.URLParam(r, "name") {
+		app.handleErrorRead(fmt.Errorf("rollout not found"), ErrReleaseReadData, w)
+		return
+	}
+
+	if running, ok := app.rollouts.LoadAndDelete(model.ID); ok {
+		close(running.(*runningRollout).cancel)
+		<-running.(*runningRollout).done
+
+		model, err = app.Repo.Rollout.ReadRollout(model.ID)
+
+		if err != nil {
+			app.handleErrorRead(err, ErrReleaseReadData, w)
+			return
+		}
+	} else if model.Status == models.RolloutStatusProgressing {
+		// the rollout was started by a server that stopped before it finished
+		app.stopRollout(model, models.RolloutStatusCanceled, "canceled")
+	} else {
+		app.sendExternalError(fmt.Errorf("rollout is not in progress"), http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{fmt.Sprintf("rollout is %s and cannot be canceled", strings.Replace(model.Status, "_", " ", -1))},
+		}, w)
+
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(model.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// FailInterruptedRollouts fails the rollouts that are still in progress when the
+// server starts, and removes their canary releases. Rollouts are run by the server
+// that starts them, so a rollout that is in progress was interrupted by a restart
+// and cannot be resumed, since its new values are not stored.
+func (app *App) FailInterruptedRollouts() {
+	rollouts, err := app.Repo.Rollout.ListRolloutsByStatus(models.RolloutStatusProgressing)
+
+	if err != nil {
+		app.Logger.Warn().Err(err).Msg("could not list interrupted rollouts")
+		return
+	}
+
+	for _, model := range rollouts {
+		app.stopRollout(model, models.RolloutStatusFailed, "the server restarted before the rollout finished")
+	}
+}
+
+// stopRollout removes the canary release of a rollout that is not run by this
+// server, and stores the rollout with a final status
+func (app *App) stopRollout(model *models.Rollout, status, info string) {
+	if err := app.removeRolloutCanary(model); err != nil {
+		info = fmt.Sprintf("%s; could not remove the canary: %v", info, err)
+	}
+
+	finishedAt := time.Now()
+
+	model.Status = status
+	model.Info = info
+	model.FinishedAt = &finishedAt
+
+	if _, err := app.Repo.Rollout.UpdateRollout(model); err != nil {
+		app.Logger.Warn().Err(err).Msg("could not update rollout")
+	}
+}
+
+// removeRolloutCanary uninstalls the canary release of a rollout
+func (app *App) removeRolloutCanary(model *models.Rollout) error {
+	cluster, err := app.Repo.Cluster.ReadCluster(model.ClusterID)
+
+	if err != nil {
+		return err
+	}
+
+	agent, err := app.getPooledHelmAgent(&helm.Form{
+		Cluster:           cluster,
+		Repo:              app.Repo,
+		DigitalOceanOAuth: app.DOConf,
+		Storage:           model.Storage,
+		Namespace:         model.Namespace,
+	})
+
+	if err != nil {
+		return err
+	}
+
+	_, err = agent.UninstallChart(rollout.CanaryName(model.ReleaseName))
+
+	return err
+}
+
+// startRollout validates that a web release can be rolled out, stores the rollout
+// and runs it in the background. The rollout is returned with a 202, and its
+// progress can be read from the rollouts of the release.
+func (app *App) startRollout(
+	w http.ResponseWriter,
+	r *http.Request,
+	projID uint,
+	form *forms.UpgradeReleaseForm,
+	agent *helm.Agent,
+	conf *helm.UpgradeReleaseConfig,
+) {
+	if err := app.validator.Struct(form.Rollout); err != nil {
+		app.handleErrorFormValidation(err, ErrReleaseValidateFields, w)
+		return
+	}
+
+	rel, err := agent.GetRelease(form.Name, 0, false)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusNotFound, HTTPError{
+			Code:   ErrReleaseReadData,
+			Errors: []string{"release not found"},
+		}, w)
+
+		return
+	}
+
+	if rel.Chart == nil || rel.Chart.Metadata == nil || rel.Chart.Metadata.Name != "web" {
+		app.sendExternalError(fmt.Errorf("not a web release"), http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{"rollouts are only supported for web releases"},
+		}, w)
+
+		return
+	}
+
+	// a canary release that is left over means that another rollout is running, or
+	// that the canary could not be removed
+	if _, err := agent.GetRelease(rollout.CanaryName(form.Name), 0, false); err == nil {
+		app.sendExternalError(fmt.Errorf("canary release exists"), http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{fmt.Sprintf("release %s already exists, a rollout may be in progress", rollout.CanaryName(form.Name))},
+		}, w)
+
+		return
+	}
+
+	conf.Values, err = chartutil.ReadValues([]byte(form.Values))
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{fmt.Sprintf("values could not be parsed: %v", err)},
+		}, w)
+
+		return
+	}
+
+	promSvc, err := app.getRolloutPrometheus(agent, rel)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseValidateFields,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return
+	}
+
+	model := form.Rollout.ToRollout(projID, form.Cluster.ID, rel.Namespace, form.Name)
+	model.UserID, _ = app.getUserIDFromRequest(r)
+	model.Storage = form.Storage
+
+	model, err = app.Repo.Rollout.CreateRollout(model)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	ch := conf.Chart
+
+	if ch == nil {
+		ch = rel.Chart
+	}
+
+	// the rollout outlives the request, so the agent is read from the pool on each
+	// step instead of reusing the agent of the request
+	helmForm := *form.ReleaseForm.Form
+	helmForm.Namespace = rel.Namespace
+
+	target := &releaseRolloutTarget{
+		getAgent: func() (*helm.Agent, error) {
+			return app.getPooledHelmAgent(&helmForm)
+		},
+		conf:       conf,
+		chart:      ch,
+		namespace:  rel.Namespace,
+		prometheus: promSvc,
+		doConf:     app.DOConf,
+	}
+
+	deployment := &models.Deployment{
+		ProjectID: projID,
+		ClusterID: form.Cluster.ID,
+		Namespace: rel.Namespace,
+		Name:      form.Name,
+		Trigger:   models.DeploymentTriggerRollout,
+	}
+
+	app.setDeploymentActor(deployment, r)
+
+	notifyOpts := &slack.NotifyOpts{
+		ProjectID:   projID,
+		ClusterID:   form.Cluster.ID,
+		ClusterName: form.Cluster.Name,
+		Name:        form.Name,
+		Namespace:   rel.Namespace,
+		Trigger:     models.NotificationTriggerManual,
+		URL: fmt.Sprintf(
+			"%s/applications/%s/%s/%s",
+			app.ServerConf.ServerURL,
+			url.PathEscape(form.Cluster.Name),
+			rel.Namespace,
+			form.Name,
+		) + fmt.Sprintf("?project_id=%d", projID),
+	}
+
+	running := &runningRollout{
+		cancel: make(chan struct{}),
+		done:   make(chan struct{}),
+	}
+
+	app.rollouts.Store(model.ID, running)
+
+	go app.runRollout(model, target, running, deployment, rel.Config, notifyOpts)
+
+	w.WriteHeader(http.StatusAccepted)
+
+	if err := json.NewEncoder(w).Encode(model.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// getRolloutPrometheus returns the Prometheus service that analyzes a rollout,
+// after checking that the release is served by an NGINX ingress that Prometheus
+// collects metrics for
+func (app *App) getRolloutPrometheus(agent *helm.Agent, rel *release.Release) (*v1.Service, error) {
+	if agent.K8sAgent == nil {
+		return nil, fmt.Errorf("could not connect to the cluster")
+	}
+
+	promSvc, found, err := prometheus.GetPrometheusService(agent.K8sAgent.Clientset)
+
+	if err != nil || !found {
+		return nil, fmt.Errorf("prometheus must be installed in the cluster to analyze a rollout")
+	}
+
+	nginxIngresses, err := prometheus.GetIngressesWithNGINXAnnotation(agent.K8sAgent.Clientset)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not list NGINX ingresses: %v", err)
+	}
+
+	for _, name := range manifestIngresses(rel) {
+		for _, ingress := range nginxIngresses {
+			if ingress.Name == name && ingress.Namespace == rel.Namespace {
+				return promSvc, nil
+			}
+		}
+	}
+
+	return nil, fmt.Errorf("the release must be exposed through an NGINX ingress to be rolled out")
+}
+
+// runningRollout is a rollout that is run by this server. The rollout stops at the
+// end of its current step once cancel is closed, and done is closed once the
+// rollout has stopped.
+type runningRollout struct {
+	cancel chan struct{}
+	done   chan struct{}
+}
+
+// runRollout runs a rollout to completion, storing its progress and recording the
+// deployment and notifying once it is promoted, rolled back or failed
+func (app *App) runRollout(
+	model *models.Rollout,
+	target *releaseRolloutTarget,
+	running *runningRollout,
+	deployment *models.Deployment,
+	prevValues map[string]interface{},
+	notifyOpts *slack.NotifyOpts,
+) {
+	defer close(running.done)
+	defer app.rollouts.Delete(model.ID)
+
+	wait := func(d time.Duration) bool {
+		select {
+		case <-time.After(d):
+			return true
+		case <-running.cancel:
+			return false
+		}
+	}
+
+	res := rollout.Run(target, rollout.NewConfig(model), wait, func(weight uint) {
+		model.Weight = weight
+
+		if _, err := app.Repo.Rollout.UpdateRollout(model); err != nil {
+			app.Logger.Warn().Err(err).Msg("could not update rollout")
+		}
+	})
+
+	finishedAt := time.Now()
+
+	model.Status = res.Status
+	model.Revision = res.Revision
+	model.Info = res.Info
+	model.FinishedAt = &finishedAt
+
+	if _, err := app.Repo.Rollout.UpdateRollout(model); err != nil {
+		app.Logger.Warn().Err(err).Msg("could not update rollout")
+	}
+
+	// the user that canceled the rollout is not notified
+	if res.Status == models.RolloutStatusCanceled {
+		return
+	}
+
+	if res.Status == models.RolloutStatusPromoted {
+		deployment.Revision = res.Revision
+		deployment.Status = string(release.StatusDeployed)
+
+		app.recordDeployment(deployment, prevValues, target.conf.Values)
+
+		notifyOpts.Status = slack.StatusDeployed
+		notifyOpts.Version = res.Revision
+	} else {
+		notifyOpts.Status = slack.StatusFailed
+		notifyOpts.Info = fmt.Sprintf("rollout %s: %s", strings.Replace(res.Status, "_", " ", -1), res.Info)
+	}
+
+	dbRelease, _ := app.Repo.Release.ReadRelease(model.ClusterID, model.ReleaseName, model.Namespace)
+
+	app.notifyRelease(dbRelease, model.ProjectID, notifyOpts)
+}
+
+// releaseRolloutTarget runs a rollout against a web release, by installing a canary
+// copy of the release with the new values, and analyzing the error rate and latency
+// of the canary ingress through Prometheus
+type releaseRolloutTarget struct {
+	// getAgent returns an agent for the namespace of the release
+	getAgent func() (*helm.Agent, error)
+
+	conf      *helm.UpgradeReleaseConfig
+	chart     *chart.Chart
+	namespace string
+
+	prometheus *v1.Service
+	doConf     *oauth2.Config
+
+	// canaryIngresses are the names of the ingresses of the canary release
+	canaryIngresses []string
+}
+
+func (t *releaseRolloutTarget) InstallCanary(weight uint) error {
+	agent, err := t.getAgent()
+
+	if err != nil {
+		return err
+	}
+
+	rel, err := agent.InstallChart(&helm.InstallChartConfig{
+		Chart:      t.chart,
+		Name:       rollout.CanaryName(t.conf.Name),
+		Namespace:  t.namespace,
+		Values:     rollout.CanaryValues(t.conf.Values, weight),
+		Cluster:    t.conf.Cluster,
+		Repo:       t.conf.Repo,
+		Registries: t.conf.Registries,
+	}, t.doConf)
+
+	if err != nil {
+		return err
+	}
+
+	t.canaryIngresses = manifestIngresses(rel)
+
+	if len(t.canaryIngresses) == 0 {
+		return fmt.Errorf("the canary release does not have an ingress")
+	}
+
+	return nil
+}
+
+func (t *releaseRolloutTarget) SetCanaryWeight(weight uint) error {
+	agent, err := t.getAgent()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = agent.UpgradeReleaseByValues(&helm.UpgradeReleaseConfig{
+		Name:       rollout.CanaryName(t.conf.Name),
+		Values:     rollout.CanaryValues(t.conf.Values, weight),
+		Cluster:    t.conf.Cluster,
+		Repo:       t.conf.Repo,
+		Registries: t.conf.Registries,
+		Chart:      t.chart,
+	}, t.doConf)
+
+	return err
+}
+
+func (t *releaseRolloutTarget) Analyze(interval time.Duration) (*rollout.Analysis, error) {
+	agent, err := t.getAgent()
+
+	if err != nil {
+		return nil, err
+	}
+
+	if agent.K8sAgent == nil {
+		return nil, fmt.Errorf("could not connect to the cluster")
+	}
+
+	end := time.Now()
+
+	opts := &prometheus.QueryOpts{
+		Kind:       "ingress",
+		Name:       strings.Join(t.canaryIngresses, "|"),
+		Namespace:  t.namespace,
+		StartRange: uint(end.Add(-interval).Unix()),
+		EndRange:   uint(end.Unix()),
+		Resolution: "60s",
+	}
+
+	res := &rollout.Analysis{}
+
+	opts.Metric = "nginx:errors"
+
+	if res.ErrorRate, err = prometheus.QueryLatestValue(agent.K8sAgent.Clientset, t.prometheus, opts); err != nil {
+		return nil, err
+	}
+
+	opts.Metric = "nginx:latency"
+
+	if res.Latency, err = prometheus.QueryLatestValue(agent.K8sAgent.Clientset, t.prometheus, opts); err != nil {
+		return nil, err
+	}
+
+	return res, nil
+}
+
+func (t *releaseRolloutTarget) Promote() (int, error) {
+	agent, err := t.getAgent()
+
+	if err != nil {
+		return 0, err
+	}
+
+	rel, err := agent.UpgradeReleaseByValues(t.conf, t.doConf)
+
+	if err != nil {
+		return 0, err
+	}
+
+	return rel.Version, nil
+}
+
+func (t *releaseRolloutTarget) RemoveCanary() error {
+	agent, err := t.getAgent()
+
+	if err != nil {
+		return err
+	}
+
+	_, err = agent.UninstallChart(rollout.CanaryName(t.conf.Name))
+	return err
+}
+
+// manifestIngresses returns the names of the ingresses in the manifest of a release
+func manifestIngresses(rel *release.Release) []string {
+	res := make([]string, 0)
+
+	objs := grapher.ParseObjs(grapher.ImportMultiDocYAML([]byte(rel.Manifest)), rel.Namespace)
+
+	for _, obj := range objs {
+		if obj.Kind == "Ingress" {
+			res = append(res, obj.Name)
+		}
+	}
+
+	return res
+}
@@ -0,0 +1,275 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/helm"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/registry"
+)
+
+// ReleaseVulnerabilitiesResponse is the vulnerability scan of the image of a
+// release, along with a count of its vulnerabilities by severity
+type ReleaseVulnerabilitiesResponse struct {
+	*registry.ImageScan
+
+	ImageRepoURI string                         `json:"image_repo_uri"`
+	ImageTag     string                         `json:"image_tag"`
+	Summary      *registry.VulnerabilitySummary `json:"summary"`
+
+	// BlockSeverity is the severity that deploys of the release are blocked at, if
+	// the release is stored
+	BlockSeverity string `json:"block_severity,omitempty"`
+}
+
+// HandleGetReleaseVulnerabilities returns the vulnerabilities of the image that a
+// release is running. The findings are read from the registry for ECR and GCR
+// registries, and the image is scanned by the local scanner for other registries.
+func (app *App) HandleGetReleaseVulnerabilities(w http.ResponseWriter, r *http.Request) {
+	form := &forms.GetReleaseForm{
+		ReleaseForm: &forms.ReleaseForm{
+			Form: &helm.Form{
+				Repo:              app.Repo,
+				DigitalOceanOAuth: app.DOConf,
+			},
+		},
+		Name: chi.URLParam(r, "name"),
+	}
+
+	agent, err := app.getAgentFromQueryParams(
+		w,
+		r,
+		form.ReleaseForm,
+		form.ReleaseForm.PopulateHelmOptionsFromQueryParams,
+	)
+
+	// errors are handled in app.getAgentFromQueryParams
+	if err != nil {
+		return
+	}
+
+	rel, err := agent.GetRelease(form.Name, 0, false)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusNotFound, HTTPError{
+			Code:   ErrReleaseReadData,
+			Errors: []string{"release not found"},
+		}, w)
+
+		return
+	}
+
+	imageRepo, imageTag := getImageFromValues(rel.Config)
+
+	if imageRepo == "" {
+		app.sendExternalError(fmt.Errorf("release has no image"), http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseReadData,
+			Errors: []string{"the release does not set image.repository"},
+		}, w)
+
+		return
+	}
+
+	registries, err := app.Repo.Registry.ListRegistriesByProjectID(uint(form.ReleaseForm.Cluster.ProjectID))
+
+	if err != nil {
+		app.handleErrorDataRead(err, w)
+		return
+	}
+
+	// the scan is stopped if the client goes away
+	scan, err := app.scanImage(r.Context(), registries, imageRepo, imageTag)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrReleaseReadData,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return
+	}
+
+	res := &ReleaseVulnerabilitiesResponse{
+		ImageScan:    scan,
+		ImageRepoURI: imageRepo,
+		ImageTag:     imageTag,
+		Summary:      scan.Summary(),
+	}
+
+	if dbRelease, err := app.Repo.Release.ReadRelease(form.Cluster.ID, rel.Name, rel.Namespace); err == nil {
+		res.BlockSeverity = dbRelease.VulnerabilityBlockSeverity
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// HandleUpdateVulnerabilityPolicy sets the severity that deploys of a release are
+// blocked at. When set, the image of every upgrade of the release -- including
+// webhook deploys, batch image updates, rollouts, promotions and env group
+// upgrades -- is checked for vulnerabilities before the release is upgraded, and
+// the deploy fails if the image has vulnerabilities of that severity or above, or
+// if the image could not be checked. The upgrade deploys the digest that was
+// checked, so that a push of the tag after the check is not deployed. Rollbacks,
+// including automatic rollbacks, are not checked, since they return the release to
+// the values of a revision that was already deployed.
+func (app *App) HandleUpdateVulnerabilityPolicy(w http.ResponseWriter, r *http.Request) {
+	release, ok := app.readReleaseFromQueryParams(w, r, true)
+
+	if !ok {
+		return
+	}
+
+	form := &forms.UpdateVulnerabilityPolicyForm{}
+
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrReleaseValidateFields, w)
+		return
+	}
+
+	release.VulnerabilityBlockSeverity = form.BlockSeverity
+
+	release, err := app.Repo.Release.UpdateRelease(release)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(release.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrReleaseDecode, w)
+		return
+	}
+}
+
+// scanImage returns the vulnerability scan of an image, given by the value of its
+// image tag. Tags that are pinned to a digest are scanned by digest.
+func (app *App) scanImage(
+	ctx context.Context,
+	registries []*models.Registry,
+	imageRepo, tag string,
+) (*registry.ImageScan, error) {
+	ref := tag
+
+	if spl := strings.SplitN(tag, "@", 2); len(spl) == 2 {
+		ref = spl[1]
+	}
+
+	reg, repoName := registry.FindImageRegistry(registries, imageRepo)
+
+	if reg == nil {
+		return nil, fmt.Errorf("image repository %s does not belong to a registry of the project", imageRepo)
+	}
+
+	scan, err := reg.GetImageScan(ctx, repoName, ref, *app.Repo, app.DOConf, app.LocalScanner)
+
+	if err != nil {
+		return nil, fmt.Errorf("could not read the vulnerabilities of %s:%s: %v", imageRepo, tag, err)
+	}
+
+	return scan, nil
+}
+
+// checkVulnerabilityPolicy returns an error if a release blocks deploys of images
+// with vulnerabilities, and the scan of the image that is deployed failed or found
+// vulnerabilities of the blocked severity or above
+func checkVulnerabilityPolicy(release *models.Release, scan *registry.ImageScan, scanErr error) error {
+	if release == nil || release.VulnerabilityBlockSeverity == "" {
+		return nil
+	}
+
+	if scanErr != nil {
+		return fmt.Errorf("deploy blocked, since the image could not be checked for vulnerabilities: %v", scanErr)
+	}
+
+	if count := scan.CountAtOrAbove(release.VulnerabilityBlockSeverity); count > 0 {
+		return fmt.Errorf(
+			"deploy blocked, since the image has %d vulnerabilities of severity %s or above",
+			count,
+			release.VulnerabilityBlockSeverity,
+		)
+	}
+
+	return nil
+}
+
+// checkUpgradeVulnerabilityPolicy checks the image that an upgrade of a release
+// deploys, given by the new values of the release, against the vulnerability policy
+// of the release. If the image is allowed, its tag in the values is pinned to the
+// digest that was scanned. It is called by every handler that upgrades a release.
+func (app *App) checkUpgradeVulnerabilityPolicy(
+	ctx context.Context,
+	release *models.Release,
+	registries []*models.Registry,
+	values map[string]interface{},
+) error {
+	if release == nil || release.VulnerabilityBlockSeverity == "" {
+		return nil
+	}
+
+	imageRepo, imageTag := getImageFromValues(values)
+
+	if imageRepo == "" {
+		return checkVulnerabilityPolicy(release, nil, fmt.Errorf("the values do not set image.repository"))
+	}
+
+	scan, err := app.scanImage(ctx, registries, imageRepo, imageTag)
+
+	if err := checkVulnerabilityPolicy(release, scan, err); err != nil {
+		return err
+	}
+
+	pinnedTag, err := pinScannedDigest(imageTag, scan)
+
+	if err != nil {
+		return err
+	}
+
+	values["image"].(map[string]interface{})["tag"] = pinnedTag
+
+	return nil
+}
+
+// pinScannedDigest returns the tag of an image pinned to the digest that was
+// scanned. Tags that are already pinned to a digest were scanned by that digest.
+func pinScannedDigest(tag string, scan *registry.ImageScan) (string, error) {
+	if strings.Contains(tag, "@") {
+		return tag, nil
+	}
+
+	if scan.Digest == "" {
+		return "", fmt.Errorf("deploy blocked, since the digest of the scanned image is unknown")
+	}
+
+	return tag + "@" + scan.Digest, nil
+}
+
+// getImageFromValues returns the image repository and tag set in the values of a
+// release. The tag defaults to latest.
+func getImageFromValues(values map[string]interface{}) (string, string) {
+	image, _ := values["image"].(map[string]interface{})
+	imageRepo, _ := image["repository"].(string)
+
+	if image["tag"] == nil {
+		return imageRepo, "latest"
+	}
+
+	return imageRepo, fmt.Sprintf("%v", image["tag"])
+}
@@ -0,0 +1,95 @@
+package api
+
+import (
+	"context"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/models/integrations"
+	"github.com/porter-dev/porter/internal/registry"
+	memory "github.com/porter-dev/porter/internal/repository/memory"
+)
+
+type fakeScanner struct {
+	vulns []*registry.Vulnerability
+}
+
+func (f *fakeScanner) ScanImage(ctx context.Context, imageRef string, dockerConfigJSON []byte) ([]*registry.Vulnerability, error) {
+	return f.vulns, nil
+}
+
+const testImageDigest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+func TestCheckUpgradeVulnerabilityPolicy(t *testing.T) {
+	repo := memory.NewRepository(true)
+
+	basic, err := repo.BasicIntegration.CreateBasicIntegration(&integrations.BasicIntegration{
+		Username: []byte("porter"),
+		Password: []byte("secret"),
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	registries := []*models.Registry{
+		{
+			URL:                "registry.example.com/porter",
+			BasicIntegrationID: basic.ID,
+		},
+	}
+
+	scanner := &fakeScanner{
+		vulns: []*registry.Vulnerability{
+			{ID: "CVE-2021-0001", Severity: "CRITICAL"},
+		},
+	}
+
+	app := &App{Repo: repo, LocalScanner: scanner}
+
+	values := map[string]interface{}{
+		"image": map[string]interface{}{
+			"repository": "registry.example.com/porter/web",
+			"tag":        "v1@" + testImageDigest,
+		},
+	}
+
+	blocking := &models.Release{Name: "web", VulnerabilityBlockSeverity: registry.SeverityHigh}
+
+	if err := app.checkUpgradeVulnerabilityPolicy(context.Background(), blocking, registries, values); err == nil {
+		t.Errorf("expected upgrade with a critical finding to be blocked\n")
+	}
+
+	if err := app.checkUpgradeVulnerabilityPolicy(context.Background(), &models.Release{Name: "web"}, registries, values); err != nil {
+		t.Errorf("expected upgrade of release without a policy to be allowed, got %v\n", err)
+	}
+
+	if err := app.checkUpgradeVulnerabilityPolicy(context.Background(), blocking, registries, map[string]interface{}{}); err == nil {
+		t.Errorf("expected upgrade without an image to be blocked\n")
+	}
+
+	scanner.vulns = []*registry.Vulnerability{
+		{ID: "CVE-2021-0002", Severity: "LOW"},
+	}
+
+	if err := app.checkUpgradeVulnerabilityPolicy(context.Background(), blocking, registries, values); err != nil {
+		t.Errorf("expected upgrade with a low finding to be allowed, got %v\n", err)
+	}
+}
+
+func TestPinScannedDigest(t *testing.T) {
+	scan := &registry.ImageScan{Digest: testImageDigest}
+
+	if tag, err := pinScannedDigest("v1", scan); err != nil || tag != "v1@"+testImageDigest {
+		t.Errorf("expected tag to be pinned to the scanned digest, got %s, %v\n", tag, err)
+	}
+
+	// tags that are pinned were scanned by their digest
+	if tag, err := pinScannedDigest("v1@sha256:abc", scan); err != nil || tag != "v1@sha256:abc" {
+		t.Errorf("expected pinned tag to be kept, got %s, %v\n", tag, err)
+	}
+
+	if _, err := pinScannedDigest("v1", &registry.ImageScan{}); err == nil {
+		t.Errorf("expected tag without a scanned digest to be blocked\n")
+	}
+}
@@ -0,0 +1,197 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi"
+	"github.com/porter-dev/porter/internal/forms"
+	"github.com/porter-dev/porter/internal/models/integrations"
+)
+
+// HandleCreateWebhookIntegration creates a generic outbound webhook for a project. The
+// signing secret is only returned in this response.
+func (app *App) HandleCreateWebhookIntegration(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	userID, err := app.getUserIDFromRequest(r)
+
+	if err != nil {
+		app.handleErrorInternal(err, w)
+		return
+	}
+
+	form := &forms.CreateWebhookIntegrationForm{
+		ProjectID: uint(projID),
+		UserID:    userID,
+	}
+
+	// decode from JSON to form value
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	// validate the form
+	if err := app.validator.Struct(form); err != nil {
+		app.handleErrorFormValidation(err, ErrProjectValidateFields, w)
+		return
+	}
+
+	webhookInt, err := form.ToWebhookIntegration()
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrProjectValidateFields,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return
+	}
+
+	secret := string(webhookInt.Secret)
+
+	webhookInt, err = app.Repo.WebhookIntegration.CreateWebhookIntegration(webhookInt)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	app.Logger.Info().Msgf("New webhook integration created: %d", webhookInt.ID)
+
+	res := webhookInt.Externalize()
+	res.Secret = secret
+
+	w.WriteHeader(http.StatusCreated)
+
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleListWebhookIntegrations lists the webhook integrations in a project
+func (app *App) HandleListWebhookIntegrations(w http.ResponseWriter, r *http.Request) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	webhookInts, err := app.Repo.WebhookIntegration.ListWebhookIntegrationsByProjectID(uint(projID))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return
+	}
+
+	res := make([]*integrations.WebhookIntegrationExternal, 0)
+
+	for _, webhookInt := range webhookInts {
+		res = append(res, webhookInt.Externalize())
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(res); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleUpdateWebhookIntegration updates the name, url or secret of a webhook integration
+func (app *App) HandleUpdateWebhookIntegration(w http.ResponseWriter, r *http.Request) {
+	webhookInt, ok := app.readWebhookIntegrationFromURLParams(w, r)
+
+	if !ok {
+		return
+	}
+
+	form := &forms.UpdateWebhookIntegrationForm{}
+
+	// decode from JSON to form value
+	if err := json.NewDecoder(r.Body).Decode(form); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+
+	webhookInt, err := form.ToWebhookIntegration(webhookInt)
+
+	if err != nil {
+		app.sendExternalError(err, http.StatusBadRequest, HTTPError{
+			Code:   ErrProjectValidateFields,
+			Errors: []string{err.Error()},
+		}, w)
+
+		return
+	}
+
+	webhookInt, err = app.Repo.WebhookIntegration.UpdateWebhookIntegration(webhookInt)
+
+	if err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+
+	if err := json.NewEncoder(w).Encode(webhookInt.Externalize()); err != nil {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return
+	}
+}
+
+// HandleDeleteWebhookIntegration deletes a webhook integration for a project by ID
+func (app *App) HandleDeleteWebhookIntegration(w http.ResponseWriter, r *http.Request) {
+	webhookInt, ok := app.readWebhookIntegrationFromURLParams(w, r)
+
+	if !ok {
+		return
+	}
+
+	if err := app.Repo.WebhookIntegration.DeleteWebhookIntegration(webhookInt.ID); err != nil {
+		app.handleErrorDataWrite(err, w)
+		return
+	}
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// readWebhookIntegrationFromURLParams reads the webhook integration referenced by the
+// project_id and webhook_integration_id URL params. If the integration cannot be read,
+// an error is written to the response.
+func (app *App) readWebhookIntegrationFromURLParams(
+	w http.ResponseWriter,
+	r *http.Request,
+) (*integrations.WebhookIntegration, bool) {
+	projID, err := strconv.ParseUint(chi.URLParam(r, "project_id"), 0, 64)
+
+	if err != nil || projID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return nil, false
+	}
+
+	integrationID, err := strconv.ParseUint(chi.URLParam(r, "webhook_integration_id"), 0, 64)
+
+	if err != nil || integrationID == 0 {
+		app.handleErrorFormDecoding(err, ErrProjectDecode, w)
+		return nil, false
+	}
+
+	webhookInt, err := app.Repo.WebhookIntegration.ReadWebhookIntegration(uint(projID), uint(integrationID))
+
+	if err != nil {
+		app.handleErrorRead(err, ErrProjectDataRead, w)
+		return nil, false
+	}
+
+	return webhookInt, true
+}
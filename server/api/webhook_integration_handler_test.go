@@ -0,0 +1,95 @@
+package api_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"testing"
+
+	"github.com/porter-dev/porter/internal/models/integrations"
+)
+
+// ------------------------- TEST FIXTURES AND FUNCTIONS  ------------------------- //
+
+var createWebhookIntegrationTests = []*projTest{
+	&projTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+		},
+		msg:       "Create webhook integration",
+		method:    "POST",
+		endpoint:  "/api/projects/1/webhook_integrations",
+		body:      `{"name":"deploys","url":"https://example.com/hook"}`,
+		expStatus: http.StatusCreated,
+		useCookie: true,
+		validators: []func(c *projTest, tester *tester, t *testing.T){
+			webhookIntegrationCreatedValidator,
+		},
+	},
+	&projTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+		},
+		msg:       "Create webhook integration with invalid url",
+		method:    "POST",
+		endpoint:  "/api/projects/1/webhook_integrations",
+		body:      `{"name":"deploys","url":"example.com/hook"}`,
+		expStatus: http.StatusBadRequest,
+		useCookie: true,
+	},
+}
+
+func TestHandleCreateWebhookIntegration(t *testing.T) {
+	testProjRequests(t, createWebhookIntegrationTests, true)
+}
+
+var listWebhookIntegrationsTests = []*projTest{
+	&projTest{
+		initializers: []func(t *tester){
+			initUserDefault,
+			initProject,
+			initWebhookIntegration,
+		},
+		msg:       "List webhook integrations",
+		method:    "GET",
+		endpoint:  "/api/projects/1/webhook_integrations",
+		body:      ``,
+		expStatus: http.StatusOK,
+		expBody:   `[{"id":1,"project_id":1,"name":"deploys"}]`,
+		useCookie: true,
+		validators: []func(c *projTest, tester *tester, t *testing.T){
+			projectBasicBodyValidator,
+		},
+	},
+}
+
+func TestHandleListWebhookIntegrations(t *testing.T) {
+	testProjRequests(t, listWebhookIntegrationsTests, true)
+}
+
+// ------------------------- INITIALIZERS AND VALIDATORS ------------------------- //
+
+func initWebhookIntegration(tester *tester) {
+	tester.repo.WebhookIntegration.CreateWebhookIntegration(&integrations.WebhookIntegration{
+		UserID:    1,
+		ProjectID: 1,
+		Name:      "deploys",
+		URL:       []byte("https://example.com/hook"),
+		Secret:    []byte("secret"),
+	})
+}
+
+func webhookIntegrationCreatedValidator(c *projTest, tester *tester, t *testing.T) {
+	gotBody := &integrations.WebhookIntegrationExternal{}
+
+	json.Unmarshal(tester.rr.Body.Bytes(), gotBody)
+
+	if gotBody.Name != "deploys" || gotBody.ProjectID != 1 {
+		t.Errorf("%s, handler returned wrong webhook: got %v", c.msg, gotBody)
+	}
+
+	if gotBody.Secret == "" {
+		t.Errorf("%s, expected a generated secret to be returned", c.msg)
+	}
+}
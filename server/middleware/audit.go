@@ -0,0 +1,169 @@
+package middleware
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/go-chi/chi"
+	"github.com/go-chi/chi/middleware"
+	lr "github.com/porter-dev/porter/internal/logger"
+	"github.com/porter-dev/porter/internal/models"
+	"github.com/porter-dev/porter/internal/repository"
+)
+
+// auditedQueryParams are the query parameters that identify the target of a request,
+// and are stored along with the URL parameters of the route
+var auditedQueryParams = []string{"cluster_id", "namespace", "name"}
+
+// redactedURLParams are URL parameters which contain secrets, and are never stored
+var redactedURLParams = map[string]bool{
+	"token": true,
+}
+
+// auditTargetKey is the context key of the targets that handlers set explicitly
+type auditTargetKey struct{}
+
+// auditTargets are the resources that a handler names as the target of a request,
+// for requests which name their target in the request body
+type auditTargets struct {
+	mu        sync.Mutex
+	resources map[string]string
+}
+
+// SetAuditTarget records a resource that is targeted by a request, for handlers which
+// read the target from the request body instead of the URL. Resources set by the
+// handler take precedence over query parameters, but not over URL parameters.
+func SetAuditTarget(r *http.Request, key, value string) {
+	targets, ok := r.Context().Value(auditTargetKey{}).(*auditTargets)
+
+	if !ok || value == "" {
+		return
+	}
+
+	targets.mu.Lock()
+	defer targets.mu.Unlock()
+
+	targets.resources[key] = value
+}
+
+// Auditor records an audit event for every mutating API call
+type Auditor struct {
+	auth   *Auth
+	repo   *repository.Repository
+	logger *lr.Logger
+}
+
+// NewAuditor returns a new Auditor instance
+func NewAuditor(auth *Auth, repo *repository.Repository, logger *lr.Logger) *Auditor {
+	return &Auditor{auth, repo, logger}
+}
+
+// AuditMutations records the actor, project, route, target resources and response
+// status of every request that is not a GET, HEAD or OPTIONS request. The event is
+// written after the request has been handled, so it should be registered before the
+// routes are matched. Handlers can add targets with SetAuditTarget.
+func (a *Auditor) AuditMutations(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == "GET" || r.Method == "HEAD" || r.Method == "OPTIONS" {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		targets := &auditTargets{resources: make(map[string]string)}
+		r = r.WithContext(context.WithValue(r.Context(), auditTargetKey{}, targets))
+
+		next.ServeHTTP(ww, r)
+
+		status := ww.Status()
+
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		a.recordEvent(r, status, targets)
+	})
+}
+
+func (a *Auditor) recordEvent(r *http.Request, status int, targets *auditTargets) {
+	rctx := chi.RouteContext(r.Context())
+
+	if rctx == nil || rctx.RoutePattern() == "" {
+		return
+	}
+
+	route := rctx.RoutePattern()
+
+	userID, tokenID := a.auth.getActorFromRequest(r)
+
+	event := &models.AuditEvent{
+		UserID:       userID,
+		APITokenID:   tokenID,
+		Method:       r.Method,
+		Route:        route,
+		ResourceType: getResourceType(route),
+		Status:       status,
+	}
+
+	resources := make(map[string]string)
+
+	for i, key := range rctx.URLParams.Keys {
+		if key == "project_id" {
+			projID, _ := strconv.ParseUint(rctx.URLParams.Values[i], 10, 64)
+			event.ProjectID = uint(projID)
+		} else if !redactedURLParams[key] && key != "*" {
+			resources[key] = rctx.URLParams.Values[i]
+		}
+	}
+
+	targets.mu.Lock()
+
+	for key, val := range targets.resources {
+		if _, exists := resources[key]; !exists {
+			resources[key] = val
+		}
+	}
+
+	targets.mu.Unlock()
+
+	if vals, err := url.ParseQuery(r.URL.RawQuery); err == nil {
+		for _, key := range auditedQueryParams {
+			if _, exists := resources[key]; !exists && vals.Get(key) != "" {
+				resources[key] = vals.Get(key)
+			}
+		}
+	}
+
+	resourceBytes, err := json.Marshal(resources)
+
+	if err != nil {
+		a.logger.Error().Err(err).Msg("could not encode audit event resources")
+		return
+	}
+
+	event.ResourceBytes = resourceBytes
+
+	if _, err := a.repo.AuditEvent.CreateAuditEvent(event); err != nil {
+		a.logger.Error().Err(err).Msg("could not write audit event")
+	}
+}
+
+// getResourceType returns the type of resource targeted by a route, which is the
+// first path segment after the project, such as "releases" or "registries". Routes
+// outside of a project use the first path segment after /api.
+func getResourceType(route string) string {
+	path := strings.TrimPrefix(route, "/api")
+	path = strings.TrimPrefix(path, "/")
+
+	if strings.HasPrefix(path, "projects/{project_id}/") {
+		path = strings.TrimPrefix(path, "projects/{project_id}/")
+	}
+
+	return strings.Split(path, "/")[0]
+}
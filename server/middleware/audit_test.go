@@ -0,0 +1,87 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/gorilla/sessions"
+	"github.com/porter-dev/porter/internal/auth/token"
+	lr "github.com/porter-dev/porter/internal/logger"
+	"github.com/porter-dev/porter/internal/repository"
+	memory "github.com/porter-dev/porter/internal/repository/memory"
+	mw "github.com/porter-dev/porter/server/middleware"
+)
+
+func TestAuditMutationsBodyTarget(t *testing.T) {
+	repo := memory.NewRepository(true)
+	tokenConf := &token.TokenGeneratorConf{TokenSecret: "secret"}
+	auth := mw.NewAuth(sessions.NewCookieStore([]byte("secret")), "porter", tokenConf, repo, nil)
+	auditor := mw.NewAuditor(auth, repo, lr.NewConsole(false))
+
+	r := chi.NewRouter()
+	r.Use(auditor.AuditMutations)
+
+	// the release is named in the request body, like a release that is linked to an
+	// env group
+	r.Method(
+		"POST",
+		"/projects/{project_id}/k8s/{namespace}/env_groups/{name}/releases",
+		http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			form := make(map[string]string)
+
+			if err := json.NewDecoder(r.Body).Decode(&form); err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+
+			// the URL parameter is kept, since it names the env group
+			mw.SetAuditTarget(r, "name", "overwritten")
+			mw.SetAuditTarget(r, "release_name", form["release_name"])
+
+			w.WriteHeader(http.StatusCreated)
+		}),
+	)
+
+	req := httptest.NewRequest(
+		"POST",
+		"/projects/1/k8s/default/env_groups/shared/releases?cluster_id=1",
+		strings.NewReader(`{"release_name":"web"}`),
+	)
+
+	rr := httptest.NewRecorder()
+	r.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusCreated {
+		t.Fatalf("expected status %d, got %d", http.StatusCreated, rr.Code)
+	}
+
+	events, err := repo.AuditEvent.ListAuditEventsByProjectID(1, &repository.AuditEventFilter{})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if len(events) != 1 {
+		t.Fatalf("expected 1 audit event, got %d", len(events))
+	}
+
+	expResources := map[string]string{
+		"cluster_id":   "1",
+		"namespace":    "default",
+		"name":         "shared",
+		"release_name": "web",
+	}
+
+	if gotResources := events[0].GetResources(); !reflect.DeepEqual(expResources, gotResources) {
+		t.Errorf("resources not equal:\nexpected: %v\ngot: %v", expResources, gotResources)
+	}
+
+	if events[0].Status != http.StatusCreated {
+		t.Errorf("expected status %d, got %d", http.StatusCreated, events[0].Status)
+	}
+}
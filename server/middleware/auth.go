@@ -17,8 +17,9 @@ import (
 
 	"github.com/go-chi/chi"
 	"github.com/gorilla/sessions"
+	"github.com/porter-dev/porter/api/types"
+	"github.com/porter-dev/porter/internal/auth/policy"
 	"github.com/porter-dev/porter/internal/auth/token"
-	"github.com/porter-dev/porter/internal/models"
 	"github.com/porter-dev/porter/internal/repository"
 )
 
@@ -56,6 +57,22 @@ func (auth *Auth) BasicAuthenticate(next http.Handler) http.Handler {
 	})
 }
 
+// BasicAuthenticateWithAPIToken checks that a user is logged in, and also accepts
+// persisted API tokens. It is only used for routes that identify the caller or
+// read public data, which the CLI calls when it is configured with an API token.
+func (auth *Auth) BasicAuthenticateWithAPIToken(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if auth.getTokenFromRequest(r) != nil || auth.isLoggedIn(w, r) {
+			next.ServeHTTP(w, r)
+		} else {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		return
+	})
+}
+
 // BasicAuthenticateWithRedirect checks that a user is logged in, and if they're not, the
 // user is redirected to the login page with the redirect path stored in the session
 func (auth *Auth) BasicAuthenticateWithRedirect(next http.Handler) http.Handler {
@@ -138,6 +155,14 @@ type bodyDOIntegrationID struct {
 	DOIntegrationID uint64 `json:"do_integration_id"`
 }
 
+type bodyNamespace struct {
+	Namespace string `json:"namespace"`
+}
+
+type bodyReleaseName struct {
+	Name string `json:"name"`
+}
+
 // DoesUserIDMatch checks the id URL parameter and verifies that it matches
 // the one stored in the session
 func (auth *Auth) DoesUserIDMatch(next http.Handler, loc IDLocation) http.Handler {
@@ -146,7 +171,7 @@ func (auth *Auth) DoesUserIDMatch(next http.Handler, loc IDLocation) http.Handle
 		id, err := findUserIDInRequest(r, loc)
 
 		// first check for token
-		tok := auth.getTokenFromRequest(r)
+		tok := auth.getUserTokenFromRequest(r)
 
 		if err != nil {
 			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
@@ -175,8 +200,174 @@ const (
 	WriteAccess AccessType = "write"
 )
 
+// contextKey is used for values that the auth middleware stores in the request
+// context
+type contextKey string
+
+const requestPolicyKey contextKey = "request_policy"
+
+// requestPolicy is the policy of the user that is making the request, along with the
+// scopes that have been authorized so far. Middleware that runs after
+// DoesUserHaveProjectAccess uses it to check access to more specific scopes.
+type requestPolicy struct {
+	policy    types.Policy
+	verb      types.APIVerb
+	reqScopes map[types.PermissionScope]*types.RequestAction
+}
+
+// scopeHandler is the handler returned by the middleware that checks a scope below
+// the project scope. The verb of a request is only checked at the deepest scope
+// that its route reaches, and the scopes above it are checked for read access, so
+// that a policy can allow writes to a single namespace or application of a project
+// that is otherwise read-only.
+type scopeHandler struct {
+	http.HandlerFunc
+}
+
+// isDeepestScope returns true if the next handler does not check a more specific
+// scope, in which case the verb of the request is checked at the current scope
+func isDeepestScope(next http.Handler) bool {
+	_, ok := next.(*scopeHandler)
+
+	return !ok
+}
+
+// getRequestScopes converts an access type into the verb and the set of scopes that
+// the user's policy must allow. Admin access corresponds to writing project settings,
+// while read and write access apply to the project itself. If the route checks a
+// more specific scope, write access is only checked at that scope. Reads are checked
+// with the get verb and writes with the update verb, which policy.ValidatePolicy
+// requires to be granted along with the other verbs of their group.
+func getRequestScopes(
+	projID uint,
+	accessType AccessType,
+	isDeepest bool,
+) (types.APIVerb, map[types.PermissionScope]*types.RequestAction) {
+	verb := types.APIVerbGet
+
+	if accessType == WriteAccess || accessType == AdminAccess {
+		verb = types.APIVerbUpdate
+	}
+
+	projVerb := verb
+
+	if !isDeepest && accessType != AdminAccess {
+		projVerb = types.APIVerbGet
+	}
+
+	reqScopes := map[types.PermissionScope]*types.RequestAction{
+		types.ProjectScope: {
+			Verb:     projVerb,
+			Resource: types.NameOrUInt{UInt: projID},
+		},
+	}
+
+	if accessType == AdminAccess {
+		reqScopes[types.SettingsScope] = &types.RequestAction{
+			Verb: verb,
+		}
+	}
+
+	return verb, reqScopes
+}
+
+// hasScopeAccess checks the policy stored in the request context against an additional
+// scope, and returns a request which stores the extended set of scopes. The verb of
+// the request is checked if the scope is the deepest scope of the route, and read
+// access is checked otherwise. If no policy was stored in the context, access is
+// denied.
+func hasScopeAccess(
+	r *http.Request,
+	scope types.PermissionScope,
+	resource types.NameOrUInt,
+	isDeepest bool,
+) (*http.Request, bool) {
+	reqPolicy, ok := r.Context().Value(requestPolicyKey).(*requestPolicy)
+
+	if !ok || reqPolicy == nil {
+		return r, false
+	}
+
+	verb := types.APIVerbGet
+
+	if isDeepest {
+		verb = reqPolicy.verb
+	}
+
+	reqScopes := reqPolicy.withScope(scope, resource, verb)
+
+	if !policy.HasScopeAccess(reqPolicy.policy, reqScopes) {
+		return r, false
+	}
+
+	return reqPolicy.withScopes(r, reqScopes), true
+}
+
+// hasClusterWideAccess checks that the policy stored in the request context allows
+// the verb of the request on the whole cluster that was authorized by
+// DoesUserHaveClusterAccess, including every namespace and application of the
+// cluster. It is used for requests that do not target a single namespace, since
+// their handlers act on every namespace or fall back to a default.
+func hasClusterWideAccess(r *http.Request) (*http.Request, bool) {
+	reqPolicy, ok := r.Context().Value(requestPolicyKey).(*requestPolicy)
+
+	if !ok || reqPolicy == nil {
+		return r, false
+	}
+
+	cluster, ok := reqPolicy.reqScopes[types.ClusterScope]
+
+	if !ok {
+		return r, false
+	}
+
+	reqScopes := reqPolicy.withScope(types.ClusterScope, cluster.Resource, reqPolicy.verb)
+
+	if !policy.HasUnrestrictedScopeAccess(reqPolicy.policy, reqScopes) {
+		return r, false
+	}
+
+	return reqPolicy.withScopes(r, reqScopes), true
+}
+
+// withScope returns a copy of the authorized scopes, extended with an action on an
+// additional scope
+func (p *requestPolicy) withScope(
+	scope types.PermissionScope,
+	resource types.NameOrUInt,
+	verb types.APIVerb,
+) map[types.PermissionScope]*types.RequestAction {
+	reqScopes := make(map[types.PermissionScope]*types.RequestAction)
+
+	for key, val := range p.reqScopes {
+		reqScopes[key] = val
+	}
+
+	reqScopes[scope] = &types.RequestAction{
+		Verb:     verb,
+		Resource: resource,
+	}
+
+	return reqScopes
+}
+
+// withScopes returns a request which stores the policy with a new set of authorized
+// scopes
+func (p *requestPolicy) withScopes(
+	r *http.Request,
+	reqScopes map[types.PermissionScope]*types.RequestAction,
+) *http.Request {
+	ctx := context.WithValue(r.Context(), requestPolicyKey, &requestPolicy{
+		policy:    p.policy,
+		verb:      p.verb,
+		reqScopes: reqScopes,
+	})
+
+	return r.WithContext(ctx)
+}
+
 // DoesUserHaveProjectAccess looks for a project_id parameter and checks that the
-// user has access via the specified accessType
+// user's policy in the project allows the specified accessType
 func (auth *Auth) DoesUserHaveProjectAccess(
 	next http.Handler,
 	projLoc IDLocation,
@@ -197,7 +388,33 @@ func (auth *Auth) DoesUserHaveProjectAccess(
 		var userID uint
 
 		if tok != nil && tok.ProjectID != 0 && tok.ProjectID == uint(projID) {
-			next.ServeHTTP(w, r)
+			// persisted API tokens are limited to the policy they were issued with
+			if tok.TokenID != "" {
+				apiToken, err := auth.repo.APIToken.ReadAPITokenByUniqueID(tok.TokenID)
+
+				if err != nil {
+					http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+					return
+				}
+
+				tokPolicy, err := policy.GetPolicyForAPIToken(auth.repo.Policy, apiToken)
+
+				if err != nil {
+					http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+					return
+				}
+
+				auth.serveWithPolicy(w, r, next, tokPolicy, uint(projID), accessType)
+				return
+			}
+
+			// tokens issued by the project without a persisted API token, such as
+			// the tokens of GitHub workflows, have full access to the project
+			auth.serveWithPolicy(w, r, next, types.AdminPolicy, uint(projID), accessType)
+			return
+		} else if tok != nil && tok.TokenID != "" {
+			// API tokens cannot be used outside of the project they were issued for
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
 			return
 		} else if tok != nil {
 			userID = tok.IBy
@@ -240,23 +457,18 @@ func (auth *Auth) DoesUserHaveProjectAccess(
 			return
 		}
 
-		// look for the user role in the project
+		// look for the user role in the project, and evaluate the role's policy
 		for _, role := range proj.Roles {
 			if role.UserID == userID {
-				if accessType == AdminAccess {
-					if role.Kind == models.RoleAdmin {
-						next.ServeHTTP(w, r)
-						return
-					}
-				} else if accessType == WriteAccess {
-					if role.Kind == models.RoleAdmin || role.Kind == models.RoleDeveloper {
-						next.ServeHTTP(w, r)
-						return
-					}
-				} else if accessType == ReadAccess {
-					next.ServeHTTP(w, r)
+				rolePolicy, err := policy.GetPolicyForRole(auth.repo.Policy, &role)
+
+				if err != nil {
+					http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
 					return
 				}
+
+				auth.serveWithPolicy(w, r, next, rolePolicy, uint(projID), accessType)
+				return
 			}
 		}
 
@@ -265,15 +477,41 @@ func (auth *Auth) DoesUserHaveProjectAccess(
 	})
 }
 
+// serveWithPolicy checks that the policy allows the specified accessType in the
+// project, and calls the next handler with the policy stored in the request context
+func (auth *Auth) serveWithPolicy(
+	w http.ResponseWriter,
+	r *http.Request,
+	next http.Handler,
+	reqPolicy types.Policy,
+	projID uint,
+	accessType AccessType,
+) {
+	verb, reqScopes := getRequestScopes(projID, accessType, isDeepestScope(next))
+
+	if !policy.HasScopeAccess(reqPolicy, reqScopes) {
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}
+
+	ctx := context.WithValue(r.Context(), requestPolicyKey, &requestPolicy{
+		policy:    reqPolicy,
+		verb:      verb,
+		reqScopes: reqScopes,
+	})
+
+	next.ServeHTTP(w, r.WithContext(ctx))
+}
+
 // DoesUserHaveClusterAccess looks for a project_id parameter and a
 // cluster_id parameter, and verifies that the cluster belongs
-// to the project
+// to the project and that the user's policy allows access to the cluster
 func (auth *Auth) DoesUserHaveClusterAccess(
 	next http.Handler,
 	projLoc IDLocation,
 	clusterLoc IDLocation,
 ) http.Handler {
-	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+	return &scopeHandler{func(w http.ResponseWriter, r *http.Request) {
 		clusterID, err := findClusterIDInRequest(r, clusterLoc)
 
 		if err != nil {
@@ -305,14 +543,141 @@ func (auth *Auth) DoesUserHaveClusterAccess(
 			}
 		}
 
-		if doesExist {
+		if !doesExist {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		// check that the user's policy allows access to this cluster
+		if r, ok := hasScopeAccess(r, types.ClusterScope, types.NameOrUInt{UInt: uint(clusterID)}, isDeepestScope(next)); ok {
 			next.ServeHTTP(w, r)
 			return
 		}
 
 		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
 		return
-	})
+	}}
+}
+
+// DoesUserHaveNamespaceAccess looks for a namespace parameter and checks that the
+// user's policy allows access to the namespace. This should be called after
+// DoesUserHaveClusterAccess. Requests that do not target a single namespace (for
+// example, listing releases across all namespaces) must be allowed on the whole
+// cluster.
+func (auth *Auth) DoesUserHaveNamespaceAccess(
+	next http.Handler,
+	namespaceLoc IDLocation,
+) http.Handler {
+	return &scopeHandler{func(w http.ResponseWriter, r *http.Request) {
+		namespace, err := findNamespaceInRequest(r, namespaceLoc)
+
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		ok := false
+
+		if namespace == "" {
+			r, ok = hasClusterWideAccess(r)
+		} else {
+			r, ok = hasScopeAccess(r, types.NamespaceScope, types.NameOrUInt{Name: namespace}, true)
+		}
+
+		if ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}}
+}
+
+// DoesUserHaveClusterWideAccess checks that the user's policy allows access to
+// every namespace of the cluster. This should be called after
+// DoesUserHaveClusterAccess, for requests which act on the whole cluster.
+func (auth *Auth) DoesUserHaveClusterWideAccess(next http.Handler) http.Handler {
+	return &scopeHandler{func(w http.ResponseWriter, r *http.Request) {
+		if r, ok := hasClusterWideAccess(r); ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}}
+}
+
+// DoesUserHaveApplicationAccess looks for a namespace parameter and a release name
+// parameter, and checks that the user's policy allows access to both the namespace
+// and the application. This should be called after DoesUserHaveClusterAccess. If
+// the namespace is empty, the handler may act on a release of any namespace, so the
+// request must also be allowed on the whole cluster.
+func (auth *Auth) DoesUserHaveApplicationAccess(
+	next http.Handler,
+	namespaceLoc IDLocation,
+	nameLoc IDLocation,
+) http.Handler {
+	return &scopeHandler{func(w http.ResponseWriter, r *http.Request) {
+		namespace, err := findNamespaceInRequest(r, namespaceLoc)
+
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		name, err := findReleaseNameInRequest(r, nameLoc)
+
+		if err != nil || name == "" {
+			http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+			return
+		}
+
+		ok := false
+
+		if namespace == "" {
+			r, ok = hasClusterWideAccess(r)
+		} else {
+			r, ok = hasScopeAccess(r, types.NamespaceScope, types.NameOrUInt{Name: namespace}, false)
+		}
+
+		if ok {
+			r, ok = hasScopeAccess(r, types.ApplicationScope, types.NameOrUInt{Name: name}, true)
+		}
+
+		if ok {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		http.Error(w, http.StatusText(http.StatusForbidden), http.StatusForbidden)
+		return
+	}}
+}
+
+// HasReleaseAccess checks that the policy stored in the request context allows the
+// verb of the request on a release of a cluster. Handlers of routes that are not
+// scoped to a cluster use it for releases named in the request body, for example the
+// release that is upgraded to a promoted image.
+func HasReleaseAccess(r *http.Request, clusterID uint, namespace, name string) bool {
+	r, ok := hasScopeAccess(r, types.ClusterScope, types.NameOrUInt{UInt: clusterID}, false)
+
+	return ok && HasApplicationAccess(r, namespace, name)
+}
+
+// HasApplicationAccess checks that the policy stored in the request context allows
+// the verb of the request on an application of a namespace. Handlers use it for
+// releases that are not named in the path or query of the request, for example
+// the releases that are upgraded when an env group changes.
+func HasApplicationAccess(r *http.Request, namespace, name string) bool {
+	r, ok := hasScopeAccess(r, types.NamespaceScope, types.NameOrUInt{Name: namespace}, false)
+
+	if ok {
+		_, ok = hasScopeAccess(r, types.ApplicationScope, types.NameOrUInt{Name: name}, true)
+	}
+
+	return ok
 }
 
 // DoesUserHaveInviteAccess looks for a project_id parameter and a
@@ -431,7 +796,7 @@ func (auth *Auth) DoesUserHaveGitInstallationAccess(
 			return
 		}
 
-		tok := auth.getTokenFromRequest(r)
+		tok := auth.getUserTokenFromRequest(r)
 
 		var userID uint
 
@@ -769,7 +1134,7 @@ func (auth *Auth) doesSessionMatchID(r *http.Request, id uint) bool {
 func (auth *Auth) isLoggedIn(w http.ResponseWriter, r *http.Request) bool {
 	// first check for Bearer token
 
-	tok := auth.getTokenFromRequest(r)
+	tok := auth.getUserTokenFromRequest(r)
 
 	if tok != nil {
 		return true
@@ -790,6 +1155,25 @@ func (auth *Auth) isLoggedIn(w http.ResponseWriter, r *http.Request) bool {
 	return true
 }
 
+// getActorFromRequest returns the id of the user that made a request, along with
+// the unique id of the API token that was used, if any. The user id is 0 if the
+// request is not authenticated.
+func (auth *Auth) getActorFromRequest(r *http.Request) (uint, string) {
+	if tok := auth.getTokenFromRequest(r); tok != nil {
+		return tok.IBy, tok.TokenID
+	}
+
+	session, err := auth.store.Get(r, auth.cookieName)
+
+	if err != nil {
+		return 0, ""
+	}
+
+	userID, _ := session.Values["user_id"].(uint)
+
+	return userID, ""
+}
+
 func (auth *Auth) getTokenFromRequest(r *http.Request) *token.Token {
 	reqToken := r.Header.Get("Authorization")
 
@@ -807,6 +1191,28 @@ func (auth *Auth) getTokenFromRequest(r *http.Request) *token.Token {
 		return nil
 	}
 
+	// tokens which are persisted as API tokens must not be revoked or expired
+	if tok.TokenID != "" {
+		apiToken, err := auth.repo.APIToken.ReadAPITokenByUniqueID(tok.TokenID)
+
+		if err != nil || !apiToken.IsValid() || apiToken.ProjectID != tok.ProjectID {
+			return nil
+		}
+	}
+
+	return tok
+}
+
+// getUserTokenFromRequest returns the token of the request if it can act on behalf
+// of the user that issued it. Persisted API tokens are limited to the policy of a
+// single project, so they are not accepted for user-level routes.
+func (auth *Auth) getUserTokenFromRequest(r *http.Request) *token.Token {
+	tok := auth.getTokenFromRequest(r)
+
+	if tok == nil || tok.TokenID != "" {
+		return nil
+	}
+
 	return tok
 }
 
@@ -1260,3 +1666,86 @@ func findDOIntegrationIDInRequest(r *http.Request, doLoc IDLocation) (uint64, er
 
 	return doID, nil
 }
+
+// findNamespaceInRequest extracts the namespace from a request. An empty string is
+// returned if the namespace is not set in the query string or the body.
+func findNamespaceInRequest(r *http.Request, namespaceLoc IDLocation) (string, error) {
+	if namespaceLoc == URLParam {
+		namespace := chi.URLParam(r, "namespace")
+
+		if namespace == "" {
+			return "", errors.New("namespace not found")
+		}
+
+		return namespace, nil
+	} else if namespaceLoc == BodyParam {
+		form := &bodyNamespace{}
+		body, err := ioutil.ReadAll(r.Body)
+
+		if err != nil {
+			return "", err
+		}
+
+		err = json.Unmarshal(body, form)
+
+		if err != nil {
+			return "", err
+		}
+
+		// need to create a new stream for the body
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		return form.Namespace, nil
+	}
+
+	vals, err := url.ParseQuery(r.URL.RawQuery)
+
+	if err != nil {
+		return "", err
+	}
+
+	// handlers read a single namespace parameter, so repeated parameters could be
+	// authorized for one namespace and act on another
+	if len(vals["namespace"]) > 1 {
+		return "", errors.New("namespace must be set at most once")
+	}
+
+	return vals.Get("namespace"), nil
+}
+
+// findReleaseNameInRequest extracts the name of a release from a request
+func findReleaseNameInRequest(r *http.Request, nameLoc IDLocation) (string, error) {
+	if nameLoc == URLParam {
+		return chi.URLParam(r, "name"), nil
+	} else if nameLoc == BodyParam {
+		form := &bodyReleaseName{}
+		body, err := ioutil.ReadAll(r.Body)
+
+		if err != nil {
+			return "", err
+		}
+
+		err = json.Unmarshal(body, form)
+
+		if err != nil {
+			return "", err
+		}
+
+		// need to create a new stream for the body
+		r.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+		return form.Name, nil
+	}
+
+	vals, err := url.ParseQuery(r.URL.RawQuery)
+
+	if err != nil {
+		return "", err
+	}
+
+	if len(vals["name"]) > 1 {
+		return "", errors.New("name must be set at most once")
+	}
+
+	return vals.Get("name"), nil
+}
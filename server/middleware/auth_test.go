@@ -0,0 +1,776 @@
+package middleware_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+
+	"github.com/go-chi/chi"
+	"github.com/gorilla/sessions"
+	"github.com/porter-dev/porter/api/types"
+	"github.com/porter-dev/porter/internal/auth/token"
+	"github.com/porter-dev/porter/internal/models"
+	memory "github.com/porter-dev/porter/internal/repository/memory"
+	mw "github.com/porter-dev/porter/server/middleware"
+)
+
+// deployerPolicy can read everything in the project, and can write to the "staging"
+// namespace in cluster 1
+var deployerPolicy = types.Policy{
+	{
+		Scope: types.ProjectScope,
+		Verbs: types.ReadVerbGroup(),
+		Children: map[types.PermissionScope]*types.PolicyDocument{
+			types.ClusterScope: {
+				Scope:     types.ClusterScope,
+				Resources: []types.NameOrUInt{{UInt: 1}},
+				Verbs:     types.ReadVerbGroup(),
+				Children: map[types.PermissionScope]*types.PolicyDocument{
+					types.NamespaceScope: {
+						Scope:     types.NamespaceScope,
+						Resources: []types.NameOrUInt{{Name: "staging"}},
+						Verbs:     types.ReadWriteVerbGroup(),
+					},
+				},
+			},
+		},
+	},
+}
+
+// webPolicy can read everything in the project, and can only write to the "web"
+// application in the "production" namespace
+var webPolicy = types.Policy{
+	{
+		Scope: types.ProjectScope,
+		Verbs: types.ReadVerbGroup(),
+		Children: map[types.PermissionScope]*types.PolicyDocument{
+			types.ClusterScope: {
+				Scope: types.ClusterScope,
+				Verbs: types.ReadVerbGroup(),
+				Children: map[types.PermissionScope]*types.PolicyDocument{
+					types.NamespaceScope: {
+						Scope:     types.NamespaceScope,
+						Resources: []types.NameOrUInt{{Name: "production"}},
+						Verbs:     types.ReadVerbGroup(),
+						Children: map[types.PermissionScope]*types.PolicyDocument{
+							types.ApplicationScope: {
+								Scope:     types.ApplicationScope,
+								Resources: []types.NameOrUInt{{Name: "web"}},
+								Verbs:     types.ReadWriteVerbGroup(),
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+type chainTest struct {
+	msg       string
+	method    string
+	path      string
+	expStatus int
+}
+
+// newPolicyRouter creates a router with project, cluster, namespace and application
+// routes, which are authorized for a user with a custom role that uses the given
+// policy. It returns the router along with a bearer token for the user.
+func newPolicyRouter(t *testing.T, rolePolicy types.Policy) (*chi.Mux, string) {
+	t.Helper()
+
+	repo := memory.NewRepository(true)
+	tokenConf := &token.TokenGeneratorConf{TokenSecret: "secret"}
+
+	user, err := repo.User.CreateUser(&models.User{
+		Email:         "deployer@example.com",
+		EmailVerified: true,
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	proj, err := repo.Project.CreateProject(&models.Project{Name: "project"})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	if _, err := repo.Cluster.CreateCluster(&models.Cluster{ProjectID: proj.ID}); err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	policyBytes, err := json.Marshal(rolePolicy)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	pol, err := repo.Policy.CreatePolicy(&models.Policy{
+		ProjectID:   proj.ID,
+		Name:        "custom",
+		PolicyBytes: policyBytes,
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	_, err = repo.Project.CreateProjectRole(proj, &models.Role{
+		Kind:      models.RoleCustom,
+		UserID:    user.ID,
+		ProjectID: proj.ID,
+		PolicyID:  pol.ID,
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	tok, err := token.GetTokenForUser(user.ID)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	encoded, err := tok.EncodeToken(tokenConf)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	auth := mw.NewAuth(sessions.NewCookieStore([]byte("secret")), "porter", tokenConf, repo, nil)
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	r := chi.NewRouter()
+
+	r.Method(
+		"POST",
+		"/projects/{project_id}",
+		auth.DoesUserHaveProjectAccess(ok, mw.URLParam, mw.WriteAccess),
+	)
+
+	// the release is named in the request, as for image promotion
+	r.Method(
+		"POST",
+		"/projects/{project_id}/promote",
+		auth.DoesUserHaveProjectAccess(
+			http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				vals := r.URL.Query()
+				clusterID, _ := strconv.ParseUint(vals.Get("cluster_id"), 10, 64)
+
+				if !mw.HasReleaseAccess(r, uint(clusterID), vals.Get("namespace"), vals.Get("name")) {
+					w.WriteHeader(http.StatusForbidden)
+					return
+				}
+
+				w.WriteHeader(http.StatusOK)
+			}),
+			mw.URLParam,
+			mw.WriteAccess,
+		),
+	)
+
+	for _, accessType := range []mw.AccessType{mw.ReadAccess, mw.WriteAccess} {
+		method := "GET"
+
+		if accessType == mw.WriteAccess {
+			method = "POST"
+		}
+
+		r.Method(
+			method,
+			"/projects/{project_id}/clusters/{cluster_id}",
+			auth.DoesUserHaveProjectAccess(
+				auth.DoesUserHaveClusterAccess(ok, mw.URLParam, mw.URLParam),
+				mw.URLParam,
+				accessType,
+			),
+		)
+
+		r.Method(
+			method,
+			"/projects/{project_id}/clusters/{cluster_id}/namespace",
+			auth.DoesUserHaveProjectAccess(
+				auth.DoesUserHaveClusterAccess(
+					auth.DoesUserHaveNamespaceAccess(ok, mw.QueryParam),
+					mw.URLParam,
+					mw.URLParam,
+				),
+				mw.URLParam,
+				accessType,
+			),
+		)
+
+		// the application is checked by the handler, as for env group upgrades
+		r.Method(
+			method,
+			"/projects/{project_id}/clusters/{cluster_id}/namespace/upgrade",
+			auth.DoesUserHaveProjectAccess(
+				auth.DoesUserHaveClusterAccess(
+					auth.DoesUserHaveNamespaceAccess(
+						http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+							if !mw.HasApplicationAccess(r, r.URL.Query().Get("namespace"), r.URL.Query().Get("name")) {
+								w.WriteHeader(http.StatusForbidden)
+								return
+							}
+
+							w.WriteHeader(http.StatusOK)
+						}),
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					mw.URLParam,
+				),
+				mw.URLParam,
+				accessType,
+			),
+		)
+
+		r.Method(
+			method,
+			"/projects/{project_id}/clusters/{cluster_id}/kubeconfig",
+			auth.DoesUserHaveProjectAccess(
+				auth.DoesUserHaveClusterAccess(
+					auth.DoesUserHaveClusterWideAccess(ok),
+					mw.URLParam,
+					mw.URLParam,
+				),
+				mw.URLParam,
+				accessType,
+			),
+		)
+
+		// scopes below the project cannot be checked without the project's policy
+		r.Method(
+			method,
+			"/clusters/{cluster_id}/namespace",
+			auth.DoesUserHaveClusterAccess(
+				auth.DoesUserHaveNamespaceAccess(ok, mw.QueryParam),
+				mw.QueryParam,
+				mw.URLParam,
+			),
+		)
+
+		r.Method(
+			method,
+			"/projects/{project_id}/clusters/{cluster_id}/releases/{name}",
+			auth.DoesUserHaveProjectAccess(
+				auth.DoesUserHaveClusterAccess(
+					auth.DoesUserHaveApplicationAccess(ok, mw.QueryParam, mw.URLParam),
+					mw.URLParam,
+					mw.URLParam,
+				),
+				mw.URLParam,
+				accessType,
+			),
+		)
+	}
+
+	return r, encoded
+}
+
+func testChain(t *testing.T, rolePolicy types.Policy, tests []*chainTest) {
+	r, encoded := newPolicyRouter(t, rolePolicy)
+
+	for _, c := range tests {
+		req := httptest.NewRequest(c.method, c.path, nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", encoded))
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		if rr.Code != c.expStatus {
+			t.Errorf("%s: expected status %d, got %d\n", c.msg, c.expStatus, rr.Code)
+		}
+	}
+}
+
+var deployerChainTests = []*chainTest{
+	{
+		msg:       "deployer can read namespace",
+		method:    "GET",
+		path:      "/projects/1/clusters/1/namespace?namespace=production",
+		expStatus: http.StatusOK,
+	},
+	{
+		msg:       "deployer can write to staging namespace",
+		method:    "POST",
+		path:      "/projects/1/clusters/1/namespace?namespace=staging",
+		expStatus: http.StatusOK,
+	},
+	{
+		msg:       "deployer can write to application in staging namespace",
+		method:    "POST",
+		path:      "/projects/1/clusters/1/releases/web?namespace=staging",
+		expStatus: http.StatusOK,
+	},
+	{
+		msg:       "deployer cannot write to production namespace",
+		method:    "POST",
+		path:      "/projects/1/clusters/1/namespace?namespace=production",
+		expStatus: http.StatusForbidden,
+	},
+	{
+		msg:       "deployer cannot write to application in production namespace",
+		method:    "POST",
+		path:      "/projects/1/clusters/1/releases/web?namespace=production",
+		expStatus: http.StatusForbidden,
+	},
+	{
+		msg:       "deployer can read cluster",
+		method:    "GET",
+		path:      "/projects/1/clusters/1",
+		expStatus: http.StatusOK,
+	},
+	{
+		msg:       "deployer cannot write to cluster",
+		method:    "POST",
+		path:      "/projects/1/clusters/1",
+		expStatus: http.StatusForbidden,
+	},
+	{
+		msg:       "deployer cannot write to project",
+		method:    "POST",
+		path:      "/projects/1",
+		expStatus: http.StatusForbidden,
+	},
+}
+
+func TestScopedWriteAccess(t *testing.T) {
+	testChain(t, deployerPolicy, deployerChainTests)
+}
+
+var emptyNamespaceChainTests = []*chainTest{
+	{
+		msg:       "deployer can read all namespaces",
+		method:    "GET",
+		path:      "/projects/1/clusters/1/namespace",
+		expStatus: http.StatusOK,
+	},
+	{
+		msg:       "deployer cannot write to all namespaces",
+		method:    "POST",
+		path:      "/projects/1/clusters/1/namespace",
+		expStatus: http.StatusForbidden,
+	},
+	{
+		msg:       "deployer can read application without namespace",
+		method:    "GET",
+		path:      "/projects/1/clusters/1/releases/web",
+		expStatus: http.StatusOK,
+	},
+	{
+		msg:       "deployer cannot write to application without namespace",
+		method:    "POST",
+		path:      "/projects/1/clusters/1/releases/web",
+		expStatus: http.StatusForbidden,
+	},
+}
+
+func TestEmptyNamespaceAccess(t *testing.T) {
+	testChain(t, deployerPolicy, emptyNamespaceChainTests)
+	testChain(t, webPolicy, emptyNamespaceChainTests[2:])
+}
+
+// clusterPolicy can read everything in the project, and can write to cluster 1
+var clusterPolicy = types.Policy{
+	{
+		Scope: types.ProjectScope,
+		Verbs: types.ReadVerbGroup(),
+		Children: map[types.PermissionScope]*types.PolicyDocument{
+			types.ClusterScope: {
+				Scope:     types.ClusterScope,
+				Resources: []types.NameOrUInt{{UInt: 1}},
+				Verbs:     types.ReadWriteVerbGroup(),
+			},
+		},
+	},
+}
+
+func TestClusterWideWriteAccess(t *testing.T) {
+	testChain(t, clusterPolicy, []*chainTest{
+		{
+			msg:       "cluster deployer can write to all namespaces",
+			method:    "POST",
+			path:      "/projects/1/clusters/1/namespace",
+			expStatus: http.StatusOK,
+		},
+		{
+			msg:       "cluster deployer can write to application without namespace",
+			method:    "POST",
+			path:      "/projects/1/clusters/1/releases/web",
+			expStatus: http.StatusOK,
+		},
+		{
+			msg:       "cluster deployer cannot write to project",
+			method:    "POST",
+			path:      "/projects/1",
+			expStatus: http.StatusForbidden,
+		},
+	})
+}
+
+var webChainTests = []*chainTest{
+	{
+		msg:       "web deployer can write to web application",
+		method:    "POST",
+		path:      "/projects/1/clusters/1/releases/web?namespace=production",
+		expStatus: http.StatusOK,
+	},
+	{
+		msg:       "web deployer can read other application",
+		method:    "GET",
+		path:      "/projects/1/clusters/1/releases/api?namespace=production",
+		expStatus: http.StatusOK,
+	},
+	{
+		msg:       "web deployer cannot write to other application",
+		method:    "POST",
+		path:      "/projects/1/clusters/1/releases/api?namespace=production",
+		expStatus: http.StatusForbidden,
+	},
+	{
+		msg:       "web deployer cannot write to web application in other namespace",
+		method:    "POST",
+		path:      "/projects/1/clusters/1/releases/web?namespace=staging",
+		expStatus: http.StatusForbidden,
+	},
+	{
+		msg:       "web deployer cannot write to production namespace",
+		method:    "POST",
+		path:      "/projects/1/clusters/1/namespace?namespace=production",
+		expStatus: http.StatusForbidden,
+	},
+}
+
+func TestApplicationWriteAccess(t *testing.T) {
+	testChain(t, webPolicy, webChainTests)
+}
+
+func TestAPITokenUserAccess(t *testing.T) {
+	repo := memory.NewRepository(true)
+	tokenConf := &token.TokenGeneratorConf{TokenSecret: "secret"}
+
+	user, err := repo.User.CreateUser(&models.User{
+		Email:         "deployer@example.com",
+		EmailVerified: true,
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	proj, err := repo.Project.CreateProject(&models.Project{Name: "project"})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	apiToken, err := repo.APIToken.CreateAPIToken(&models.APIToken{
+		UniqueID:        "viewer-token",
+		ProjectID:       proj.ID,
+		CreatedByUserID: user.ID,
+		Name:            "viewer",
+		Kind:            models.RoleViewer,
+	})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	userTok, err := token.GetTokenForUser(user.ID)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	apiTok, err := token.GetTokenForAPI(user.ID, proj.ID)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	apiTok.TokenID = apiToken.UniqueID
+
+	auth := mw.NewAuth(sessions.NewCookieStore([]byte("secret")), "porter", tokenConf, repo, nil)
+
+	ok := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+
+	r := chi.NewRouter()
+
+	r.Method("DELETE", "/users/{user_id}", auth.DoesUserIDMatch(ok, mw.URLParam))
+	r.Method("GET", "/projects", auth.BasicAuthenticate(ok))
+	r.Method("GET", "/auth/check", auth.BasicAuthenticateWithAPIToken(ok))
+
+	tests := []struct {
+		msg       string
+		tok       *token.Token
+		method    string
+		path      string
+		expStatus int
+	}{
+		{
+			msg:       "user token can delete user",
+			tok:       userTok,
+			method:    "DELETE",
+			path:      fmt.Sprintf("/users/%d", user.ID),
+			expStatus: http.StatusOK,
+		},
+		{
+			msg:       "api token cannot delete user",
+			tok:       apiTok,
+			method:    "DELETE",
+			path:      fmt.Sprintf("/users/%d", user.ID),
+			expStatus: http.StatusForbidden,
+		},
+		{
+			msg:       "api token cannot call user routes",
+			tok:       apiTok,
+			method:    "GET",
+			path:      "/projects",
+			expStatus: http.StatusForbidden,
+		},
+		{
+			msg:       "api token can check auth",
+			tok:       apiTok,
+			method:    "GET",
+			path:      "/auth/check",
+			expStatus: http.StatusOK,
+		},
+	}
+
+	for _, c := range tests {
+		encoded, err := c.tok.EncodeToken(tokenConf)
+
+		if err != nil {
+			t.Fatalf("%v\n", err)
+		}
+
+		req := httptest.NewRequest(c.method, c.path, nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", encoded))
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		if rr.Code != c.expStatus {
+			t.Errorf("%s: expected status %d, got %d\n", c.msg, c.expStatus, rr.Code)
+		}
+	}
+}
+
+// namespaceWriterPolicy can write to the "production" namespace in cluster 1, except
+// for the "api" application, which it can only read
+var namespaceWriterPolicy = types.Policy{
+	{
+		Scope: types.ProjectScope,
+		Verbs: types.ReadVerbGroup(),
+		Children: map[types.PermissionScope]*types.PolicyDocument{
+			types.ClusterScope: {
+				Scope:     types.ClusterScope,
+				Resources: []types.NameOrUInt{{UInt: 1}},
+				Verbs:     types.ReadVerbGroup(),
+				Children: map[types.PermissionScope]*types.PolicyDocument{
+					types.NamespaceScope: {
+						Scope:     types.NamespaceScope,
+						Resources: []types.NameOrUInt{{Name: "production"}},
+						Verbs:     types.ReadWriteVerbGroup(),
+						Children: map[types.PermissionScope]*types.PolicyDocument{
+							types.ApplicationScope: {
+								Scope:     types.ApplicationScope,
+								Resources: []types.NameOrUInt{{Name: "api"}},
+								Verbs:     types.ReadVerbGroup(),
+							},
+						},
+					},
+				},
+			},
+		},
+	},
+}
+
+func TestHandlerApplicationAccess(t *testing.T) {
+	testChain(t, namespaceWriterPolicy, []*chainTest{
+		{
+			msg:       "namespace writer can upgrade web application",
+			method:    "POST",
+			path:      "/projects/1/clusters/1/namespace/upgrade?namespace=production&name=web",
+			expStatus: http.StatusOK,
+		},
+		{
+			msg:       "namespace writer cannot upgrade read-only application",
+			method:    "POST",
+			path:      "/projects/1/clusters/1/namespace/upgrade?namespace=production&name=api",
+			expStatus: http.StatusForbidden,
+		},
+		{
+			msg:       "namespace writer can read read-only application",
+			method:    "GET",
+			path:      "/projects/1/clusters/1/namespace/upgrade?namespace=production&name=api",
+			expStatus: http.StatusOK,
+		},
+	})
+}
+
+// restrictedClusterPolicy can write to cluster 1, except for the "production"
+// namespace, which is read-only
+var restrictedClusterPolicy = types.Policy{
+	{
+		Scope: types.ProjectScope,
+		Verbs: types.ReadVerbGroup(),
+		Children: map[types.PermissionScope]*types.PolicyDocument{
+			types.ClusterScope: {
+				Scope:     types.ClusterScope,
+				Resources: []types.NameOrUInt{{UInt: 1}},
+				Verbs:     types.ReadWriteVerbGroup(),
+				Children: map[types.PermissionScope]*types.PolicyDocument{
+					types.NamespaceScope: {
+						Scope:     types.NamespaceScope,
+						Resources: []types.NameOrUInt{{Name: "production"}},
+						Verbs:     types.ReadVerbGroup(),
+					},
+				},
+			},
+		},
+	},
+}
+
+func TestClusterWideRouteAccess(t *testing.T) {
+	testChain(t, clusterPolicy, []*chainTest{
+		{
+			msg:       "cluster deployer can write to the whole cluster",
+			method:    "POST",
+			path:      "/projects/1/clusters/1/kubeconfig",
+			expStatus: http.StatusOK,
+		},
+	})
+
+	testChain(t, deployerPolicy, []*chainTest{
+		{
+			msg:       "deployer can read the whole cluster",
+			method:    "GET",
+			path:      "/projects/1/clusters/1/kubeconfig",
+			expStatus: http.StatusOK,
+		},
+		{
+			msg:       "deployer cannot write to the whole cluster",
+			method:    "POST",
+			path:      "/projects/1/clusters/1/kubeconfig",
+			expStatus: http.StatusForbidden,
+		},
+	})
+
+	testChain(t, restrictedClusterPolicy, []*chainTest{
+		{
+			msg:       "restricted cluster deployer can write to staging namespace",
+			method:    "POST",
+			path:      "/projects/1/clusters/1/namespace?namespace=staging",
+			expStatus: http.StatusOK,
+		},
+		{
+			msg:       "restricted cluster deployer cannot write to the whole cluster",
+			method:    "POST",
+			path:      "/projects/1/clusters/1/kubeconfig",
+			expStatus: http.StatusForbidden,
+		},
+		{
+			msg:       "restricted cluster deployer cannot write to all namespaces",
+			method:    "POST",
+			path:      "/projects/1/clusters/1/namespace",
+			expStatus: http.StatusForbidden,
+		},
+	})
+}
+
+func TestScopeAccessWithoutPolicy(t *testing.T) {
+	testChain(t, types.AdminPolicy, []*chainTest{
+		{
+			msg:       "admin cannot access namespace without project access check",
+			method:    "GET",
+			path:      "/clusters/1/namespace?project_id=1&namespace=staging",
+			expStatus: http.StatusForbidden,
+		},
+	})
+}
+
+func TestProjectTokenAccess(t *testing.T) {
+	r, _ := newPolicyRouter(t, deployerPolicy)
+
+	// tokens that are issued by the project, such as the tokens of GitHub
+	// workflows, are not limited by a policy
+	tok, err := token.GetTokenForAPI(1, 1)
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	encoded, err := tok.EncodeToken(&token.TokenGeneratorConf{TokenSecret: "secret"})
+
+	if err != nil {
+		t.Fatalf("%v\n", err)
+	}
+
+	for _, path := range []string{
+		"/projects/1/clusters/1/kubeconfig",
+		"/projects/1/clusters/1/releases/web?namespace=production",
+	} {
+		req := httptest.NewRequest("POST", path, nil)
+		req.Header.Set("Authorization", fmt.Sprintf("Bearer %s", encoded))
+
+		rr := httptest.NewRecorder()
+		r.ServeHTTP(rr, req)
+
+		if rr.Code != http.StatusOK {
+			t.Errorf("project token on %s: expected status %d, got %d\n", path, http.StatusOK, rr.Code)
+		}
+	}
+}
+
+// projectWriterPolicy can write to the project, except for the "production" namespace
+// of cluster 1, which is read-only
+var projectWriterPolicy = types.Policy{
+	{
+		Scope: types.ProjectScope,
+		Verbs: types.ReadWriteVerbGroup(),
+		Children: map[types.PermissionScope]*types.PolicyDocument{
+			types.ClusterScope: {
+				Scope:     types.ClusterScope,
+				Resources: []types.NameOrUInt{{UInt: 1}},
+				Verbs:     types.ReadWriteVerbGroup(),
+				Children: map[types.PermissionScope]*types.PolicyDocument{
+					types.NamespaceScope: {
+						Scope:     types.NamespaceScope,
+						Resources: []types.NameOrUInt{{Name: "production"}},
+						Verbs:     types.ReadVerbGroup(),
+					},
+				},
+			},
+		},
+	},
+}
+
+func TestHandlerReleaseAccess(t *testing.T) {
+	testChain(t, projectWriterPolicy, []*chainTest{
+		{
+			msg:       "project writer can upgrade release in staging namespace",
+			method:    "POST",
+			path:      "/projects/1/promote?cluster_id=1&namespace=staging&name=web",
+			expStatus: http.StatusOK,
+		},
+		{
+			msg:       "project writer cannot upgrade release in production namespace",
+			method:    "POST",
+			path:      "/projects/1/promote?cluster_id=1&namespace=production&name=web",
+			expStatus: http.StatusForbidden,
+		},
+	})
+}
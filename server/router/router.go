@@ -32,8 +32,11 @@ func New(a *api.App) *chi.Mux {
 		TokenSecret: a.ServerConf.TokenGeneratorSecret,
 	}, a.Repo, ghAppConf)
 
+	auditor := mw.NewAuditor(auth, a.Repo, l)
+
 	r.Route("/api", func(r chi.Router) {
 		r.Use(mw.ContentTypeJSON)
+		r.Use(auditor.AuditMutations)
 
 		// Group for default operations with 10s timeout
 		r.Group(func(r chi.Router) {
@@ -42,6 +45,7 @@ func New(a *api.App) *chi.Mux {
 			// health checks
 			r.Method("GET", "/livez", http.HandlerFunc(a.HandleLive))
 			r.Method("GET", "/readyz", http.HandlerFunc(a.HandleReady))
+			r.Method("GET", "/metrics", http.HandlerFunc(a.HandleMetrics))
 
 			// /api/users routes
 			r.Method(
@@ -103,7 +107,7 @@ func New(a *api.App) *chi.Mux {
 			r.Method(
 				"GET",
 				"/auth/check",
-				auth.BasicAuthenticate(
+				auth.BasicAuthenticateWithAPIToken(
 					requestlog.NewHandler(a.HandleAuthCheck, l),
 				),
 			)
@@ -219,7 +223,7 @@ func New(a *api.App) *chi.Mux {
 			r.Method(
 				"GET",
 				"/templates",
-				auth.BasicAuthenticate(
+				auth.BasicAuthenticateWithAPIToken(
 					requestlog.NewHandler(a.HandleListTemplates, l),
 				),
 			)
@@ -227,7 +231,7 @@ func New(a *api.App) *chi.Mux {
 			r.Method(
 				"GET",
 				"/templates/{name}/{version}",
-				auth.BasicAuthenticate(
+				auth.BasicAuthenticateWithAPIToken(
 					requestlog.NewHandler(a.HandleReadTemplate, l),
 				),
 			)
@@ -235,7 +239,7 @@ func New(a *api.App) *chi.Mux {
 			r.Method(
 				"GET",
 				"/templates/upgrade_notes/{name}/{version}",
-				auth.BasicAuthenticate(
+				auth.BasicAuthenticateWithAPIToken(
 					requestlog.NewHandler(a.HandleGetTemplateUpgradeNotes, l),
 				),
 			)
@@ -363,6 +367,99 @@ func New(a *api.App) *chi.Mux {
 				),
 			)
 
+			// /api/projects/{project_id}/policies routes
+			r.Method(
+				"GET",
+				"/projects/{project_id}/policies",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleListPolicies, l),
+					mw.URLParam,
+					mw.AdminAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/policies",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleCreatePolicy, l),
+					mw.URLParam,
+					mw.AdminAccess,
+				),
+			)
+
+			r.Method(
+				"GET",
+				"/projects/{project_id}/policies/{policy_id}",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleReadPolicy, l),
+					mw.URLParam,
+					mw.AdminAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/policies/{policy_id}",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleUpdatePolicy, l),
+					mw.URLParam,
+					mw.AdminAccess,
+				),
+			)
+
+			r.Method(
+				"DELETE",
+				"/projects/{project_id}/policies/{policy_id}",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleDeletePolicy, l),
+					mw.URLParam,
+					mw.AdminAccess,
+				),
+			)
+
+			// /api/projects/{project_id}/api_tokens routes
+			r.Method(
+				"GET",
+				"/projects/{project_id}/api_tokens",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleListAPITokens, l),
+					mw.URLParam,
+					mw.AdminAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/api_tokens",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleCreateAPIToken, l),
+					mw.URLParam,
+					mw.AdminAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/api_tokens/{api_token_id}/revoke",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleRevokeAPIToken, l),
+					mw.URLParam,
+					mw.AdminAccess,
+				),
+			)
+
+			// /api/projects/{project_id}/audit_logs routes
+			r.Method(
+				"GET",
+				"/projects/{project_id}/audit_logs",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleListAuditEvents, l),
+					mw.URLParam,
+					mw.AdminAccess,
+				),
+			)
+
 			r.Method(
 				"POST",
 				"/projects",
@@ -910,13 +1007,58 @@ func New(a *api.App) *chi.Mux {
 				),
 			)
 
+			// /api/projects/{project_id}/webhook_integrations routes
+			r.Method(
+				"GET",
+				"/projects/{project_id}/webhook_integrations",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleListWebhookIntegrations, l),
+					mw.URLParam,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/webhook_integrations",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleCreateWebhookIntegration, l),
+					mw.URLParam,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/webhook_integrations/{webhook_integration_id}",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleUpdateWebhookIntegration, l),
+					mw.URLParam,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"DELETE",
+				"/projects/{project_id}/webhook_integrations/{webhook_integration_id}",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleDeleteWebhookIntegration, l),
+					mw.URLParam,
+					mw.WriteAccess,
+				),
+			)
+
 			// /projects/{project_id}/releases/{name}/notifications routes
 			r.Method(
 				"POST",
 				"/projects/{project_id}/releases/{name}/notifications",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleUpdateNotificationConfig, l),
+						auth.DoesUserHaveApplicationAccess(
+							requestlog.NewHandler(a.HandleUpdateNotificationConfig, l),
+							mw.BodyParam,
+							mw.URLParam,
+						),
 						mw.URLParam,
 						mw.BodyParam,
 					),
@@ -930,7 +1072,11 @@ func New(a *api.App) *chi.Mux {
 				"/projects/{project_id}/releases/{name}/notifications",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleGetNotificationConfig, l),
+						auth.DoesUserHaveApplicationAccess(
+							requestlog.NewHandler(a.HandleGetNotificationConfig, l),
+							mw.QueryParam,
+							mw.URLParam,
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1084,6 +1230,16 @@ func New(a *api.App) *chi.Mux {
 				),
 			)
 
+			r.Method(
+				"GET",
+				"/projects/{project_id}/registries/basic/token",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleGetProjectRegistryBasicToken, l),
+					mw.URLParam,
+					mw.WriteAccess,
+				),
+			)
+
 			r.Method(
 				"DELETE",
 				"/projects/{project_id}/registries/{registry_id}",
@@ -1130,15 +1286,15 @@ func New(a *api.App) *chi.Mux {
 				),
 			)
 
-			// /api/projects/{project_id}/releases routes
+			// /api/projects/{project_id}/registries/{registry_id}/retention_policies routes
 			r.Method(
 				"GET",
-				"/projects/{project_id}/releases",
+				"/projects/{project_id}/registries/{registry_id}/retention_policies",
 				auth.DoesUserHaveProjectAccess(
-					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleListReleases, l),
+					auth.DoesUserHaveRegistryAccess(
+						requestlog.NewHandler(a.HandleListRetentionPolicies, l),
+						mw.URLParam,
 						mw.URLParam,
-						mw.QueryParam,
 					),
 					mw.URLParam,
 					mw.ReadAccess,
@@ -1146,39 +1302,43 @@ func New(a *api.App) *chi.Mux {
 			)
 
 			r.Method(
-				"GET",
-				"/projects/{project_id}/releases/{name}/{revision}/components",
+				"POST",
+				"/projects/{project_id}/registries/{registry_id}/retention_policies",
 				auth.DoesUserHaveProjectAccess(
-					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleGetReleaseComponents, l),
+					auth.DoesUserHaveRegistryAccess(
+						requestlog.NewHandler(a.HandleCreateRetentionPolicy, l),
+						mw.URLParam,
 						mw.URLParam,
-						mw.QueryParam,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					mw.WriteAccess,
 				),
 			)
 
 			r.Method(
-				"GET",
-				"/projects/{project_id}/releases/{name}/{revision}/controllers",
+				"DELETE",
+				"/projects/{project_id}/registries/{registry_id}/retention_policies/{policy_id}",
 				auth.DoesUserHaveProjectAccess(
-					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleGetReleaseControllers, l),
+					auth.DoesUserHaveRegistryAccess(
+						requestlog.NewHandler(a.HandleDeleteRetentionPolicy, l),
+						mw.URLParam,
 						mw.URLParam,
-						mw.QueryParam,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					mw.WriteAccess,
 				),
 			)
 
+			// /api/projects/{project_id}/releases routes
 			r.Method(
 				"GET",
-				"/projects/{project_id}/releases/{name}/{revision}/pods/all",
+				"/projects/{project_id}/releases",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleGetReleaseAllPods, l),
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleListReleases, l),
+							mw.QueryParam,
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1189,10 +1349,14 @@ func New(a *api.App) *chi.Mux {
 
 			r.Method(
 				"GET",
-				"/projects/{project_id}/releases/{name}/history",
+				"/projects/{project_id}/releases/{name}/{revision}/components",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleListReleaseHistory, l),
+						auth.DoesUserHaveApplicationAccess(
+							requestlog.NewHandler(a.HandleGetReleaseComponents, l),
+							mw.QueryParam,
+							mw.URLParam,
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1203,10 +1367,14 @@ func New(a *api.App) *chi.Mux {
 
 			r.Method(
 				"GET",
-				"/projects/{project_id}/releases/{name}/webhook_token",
+				"/projects/{project_id}/releases/{name}/{revision}/controllers",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleGetReleaseToken, l),
+						auth.DoesUserHaveApplicationAccess(
+							requestlog.NewHandler(a.HandleGetReleaseControllers, l),
+							mw.QueryParam,
+							mw.URLParam,
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1216,25 +1384,33 @@ func New(a *api.App) *chi.Mux {
 			)
 
 			r.Method(
-				"POST",
-				"/projects/{project_id}/releases/{name}/webhook_token",
+				"GET",
+				"/projects/{project_id}/releases/{name}/{revision}/pods/all",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleCreateWebhookToken, l),
+						auth.DoesUserHaveApplicationAccess(
+							requestlog.NewHandler(a.HandleGetReleaseAllPods, l),
+							mw.QueryParam,
+							mw.URLParam,
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
 					mw.URLParam,
-					mw.WriteAccess,
+					mw.ReadAccess,
 				),
 			)
 
 			r.Method(
 				"GET",
-				"/projects/{project_id}/releases/{name}/{revision}",
+				"/projects/{project_id}/releases/{name}/history",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleGetRelease, l),
+						auth.DoesUserHaveApplicationAccess(
+							requestlog.NewHandler(a.HandleListReleaseHistory, l),
+							mw.QueryParam,
+							mw.URLParam,
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1245,10 +1421,14 @@ func New(a *api.App) *chi.Mux {
 
 			r.Method(
 				"GET",
-				"/projects/{project_id}/releases/{name}/steps",
+				"/projects/{project_id}/releases/{name}/deployments",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleGetReleaseSteps, l),
+						auth.DoesUserHaveApplicationAccess(
+							requestlog.NewHandler(a.HandleListDeployments, l),
+							mw.QueryParam,
+							mw.URLParam,
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1259,75 +1439,105 @@ func New(a *api.App) *chi.Mux {
 
 			r.Method(
 				"POST",
-				"/projects/{project_id}/releases/{name}/steps",
+				"/projects/{project_id}/releases/{name}/auto_rollback",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleUpdateReleaseSteps, l),
+						auth.DoesUserHaveApplicationAccess(
+							requestlog.NewHandler(a.HandleUpdateAutoRollback, l),
+							mw.QueryParam,
+							mw.URLParam,
+						),
 						mw.URLParam,
-						mw.BodyParam,
+						mw.QueryParam,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					mw.WriteAccess,
 				),
 			)
 
-			// /api/projects/{project_id}/gitrepos routes
 			r.Method(
-				"GET",
-				"/projects/{project_id}/gitrepos",
+				"POST",
+				"/projects/{project_id}/releases/{name}/pin_image_digest",
 				auth.DoesUserHaveProjectAccess(
-					requestlog.NewHandler(a.HandleListProjectGitRepos, l),
+					auth.DoesUserHaveClusterAccess(
+						auth.DoesUserHaveApplicationAccess(
+							requestlog.NewHandler(a.HandleUpdateImageDigestPinning, l),
+							mw.QueryParam,
+							mw.URLParam,
+						),
+						mw.URLParam,
+						mw.QueryParam,
+					),
 					mw.URLParam,
-					mw.ReadAccess,
+					mw.WriteAccess,
 				),
 			)
 
 			r.Method(
-				"GET",
-				"/projects/{project_id}/gitrepos/{installation_id}/repos",
+				"POST",
+				"/projects/{project_id}/releases/{name}/vulnerability_policy",
 				auth.DoesUserHaveProjectAccess(
-					auth.DoesUserHaveGitInstallationAccess(
-						requestlog.NewHandler(a.HandleListRepos, l),
+					auth.DoesUserHaveClusterAccess(
+						auth.DoesUserHaveApplicationAccess(
+							requestlog.NewHandler(a.HandleUpdateVulnerabilityPolicy, l),
+							mw.QueryParam,
+							mw.URLParam,
+						),
 						mw.URLParam,
+						mw.QueryParam,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					mw.WriteAccess,
 				),
 			)
 
 			r.Method(
 				"GET",
-				"/projects/{project_id}/gitrepos/{installation_id}/repos/{kind}/{owner}/{name}/branches",
+				"/projects/{project_id}/releases/{name}/rollouts",
 				auth.DoesUserHaveProjectAccess(
-					auth.DoesUserHaveGitInstallationAccess(
-						requestlog.NewHandler(a.HandleGetBranches, l),
+					auth.DoesUserHaveClusterAccess(
+						auth.DoesUserHaveApplicationAccess(
+							requestlog.NewHandler(a.HandleListRollouts, l),
+							mw.QueryParam,
+							mw.URLParam,
+						),
 						mw.URLParam,
+						mw.QueryParam,
 					),
 					mw.URLParam,
 					mw.ReadAccess,
 				),
 			)
-
 			r.Method(
-				"GET",
-				"/projects/{project_id}/gitrepos/{installation_id}/repos/{kind}/{owner}/{name}/{branch}/buildpack/detect",
+				"POST",
+				"/projects/{project_id}/releases/{name}/rollouts/{rollout_id}/cancel",
 				auth.DoesUserHaveProjectAccess(
-					auth.DoesUserHaveGitInstallationAccess(
-						requestlog.NewHandler(a.HandleDetectBuildpack, l),
+					auth.DoesUserHaveClusterAccess(
+						auth.DoesUserHaveApplicationAccess(
+							requestlog.NewHandler(a.HandleCancelRollout, l),
+							mw.QueryParam,
+							mw.URLParam,
+						),
 						mw.URLParam,
+						mw.QueryParam,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					mw.WriteAccess,
 				),
 			)
 
 			r.Method(
 				"GET",
-				"/projects/{project_id}/gitrepos/{installation_id}/repos/{kind}/{owner}/{name}/{branch}/contents",
+				"/projects/{project_id}/releases/{name}/previews",
 				auth.DoesUserHaveProjectAccess(
-					auth.DoesUserHaveGitInstallationAccess(
-						requestlog.NewHandler(a.HandleGetBranchContents, l),
+					auth.DoesUserHaveClusterAccess(
+						auth.DoesUserHaveApplicationAccess(
+							requestlog.NewHandler(a.HandleGetPreviewEnvironments, l),
+							mw.QueryParam,
+							mw.URLParam,
+						),
 						mw.URLParam,
+						mw.QueryParam,
 					),
 					mw.URLParam,
 					mw.ReadAccess,
@@ -1335,39 +1545,302 @@ func New(a *api.App) *chi.Mux {
 			)
 
 			r.Method(
-				"GET",
-				"/projects/{project_id}/gitrepos/{installation_id}/repos/{kind}/{owner}/{name}/{branch}/procfile",
+				"POST",
+				"/projects/{project_id}/releases/{name}/previews",
 				auth.DoesUserHaveProjectAccess(
-					auth.DoesUserHaveGitInstallationAccess(
-						requestlog.NewHandler(a.HandleGetProcfileContents, l),
+					auth.DoesUserHaveClusterAccess(
+						auth.DoesUserHaveApplicationAccess(
+							requestlog.NewHandler(a.HandleCreatePreviewEnvironmentConfig, l),
+							mw.QueryParam,
+							mw.URLParam,
+						),
 						mw.URLParam,
+						mw.QueryParam,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					mw.WriteAccess,
 				),
 			)
 
 			r.Method(
-				"GET",
-				"/projects/{project_id}/gitrepos/{installation_id}/repos/{kind}/{owner}/{name}/{branch}/tarball_url",
+				"DELETE",
+				"/projects/{project_id}/releases/{name}/previews",
 				auth.DoesUserHaveProjectAccess(
-					auth.DoesUserHaveGitInstallationAccess(
-						requestlog.NewHandler(a.HandleGetRepoZIPDownloadURL, l),
+					auth.DoesUserHaveClusterAccess(
+						auth.DoesUserHaveApplicationAccess(
+							requestlog.NewHandler(a.HandleDeletePreviewEnvironmentConfig, l),
+							mw.QueryParam,
+							mw.URLParam,
+						),
 						mw.URLParam,
+						mw.QueryParam,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					mw.WriteAccess,
 				),
 			)
 
-			// /api/projects/{project_id}/k8s routes
 			r.Method(
 				"GET",
-				"/projects/{project_id}/k8s/namespaces",
+				"/projects/{project_id}/releases/{name}/webhook_token",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleListNamespaces, l),
-						mw.URLParam,
+						auth.DoesUserHaveApplicationAccess(
+							requestlog.NewHandler(a.HandleGetReleaseToken, l),
+							mw.QueryParam,
+							mw.URLParam,
+						),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/releases/{name}/webhook_token",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						auth.DoesUserHaveApplicationAccess(
+							requestlog.NewHandler(a.HandleCreateWebhookToken, l),
+							mw.QueryParam,
+							mw.URLParam,
+						),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"PUT",
+				"/projects/{project_id}/releases/{name}/webhook_token",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						auth.DoesUserHaveApplicationAccess(
+							requestlog.NewHandler(a.HandleRotateReleaseWebhookToken, l),
+							mw.QueryParam,
+							mw.URLParam,
+						),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"GET",
+				"/projects/{project_id}/releases/{name}/webhook_tokens",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						auth.DoesUserHaveApplicationAccess(
+							requestlog.NewHandler(a.HandleListDeployWebhookTokens, l),
+							mw.QueryParam,
+							mw.URLParam,
+						),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/releases/{name}/webhook_tokens",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						auth.DoesUserHaveApplicationAccess(
+							requestlog.NewHandler(a.HandleCreateDeployWebhookToken, l),
+							mw.QueryParam,
+							mw.URLParam,
+						),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"DELETE",
+				"/projects/{project_id}/releases/{name}/webhook_tokens/{webhook_token_id}",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						auth.DoesUserHaveApplicationAccess(
+							requestlog.NewHandler(a.HandleRevokeDeployWebhookToken, l),
+							mw.QueryParam,
+							mw.URLParam,
+						),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"GET",
+				"/projects/{project_id}/releases/{name}/{revision}",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						auth.DoesUserHaveApplicationAccess(
+							requestlog.NewHandler(a.HandleGetRelease, l),
+							mw.QueryParam,
+							mw.URLParam,
+						),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"GET",
+				"/projects/{project_id}/releases/{name}/steps",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						auth.DoesUserHaveApplicationAccess(
+							requestlog.NewHandler(a.HandleGetReleaseSteps, l),
+							mw.QueryParam,
+							mw.URLParam,
+						),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/releases/{name}/steps",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						auth.DoesUserHaveApplicationAccess(
+							requestlog.NewHandler(a.HandleUpdateReleaseSteps, l),
+							mw.BodyParam,
+							mw.URLParam,
+						),
+						mw.URLParam,
+						mw.BodyParam,
+					),
+					mw.URLParam,
+					mw.ReadAccess,
+				),
+			)
+
+			// /api/projects/{project_id}/gitrepos routes
+			r.Method(
+				"GET",
+				"/projects/{project_id}/gitrepos",
+				auth.DoesUserHaveProjectAccess(
+					requestlog.NewHandler(a.HandleListProjectGitRepos, l),
+					mw.URLParam,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"GET",
+				"/projects/{project_id}/gitrepos/{installation_id}/repos",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveGitInstallationAccess(
+						requestlog.NewHandler(a.HandleListRepos, l),
+						mw.URLParam,
+					),
+					mw.URLParam,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"GET",
+				"/projects/{project_id}/gitrepos/{installation_id}/repos/{kind}/{owner}/{name}/branches",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveGitInstallationAccess(
+						requestlog.NewHandler(a.HandleGetBranches, l),
+						mw.URLParam,
+					),
+					mw.URLParam,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"GET",
+				"/projects/{project_id}/gitrepos/{installation_id}/repos/{kind}/{owner}/{name}/{branch}/buildpack/detect",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveGitInstallationAccess(
+						requestlog.NewHandler(a.HandleDetectBuildpack, l),
+						mw.URLParam,
+					),
+					mw.URLParam,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"GET",
+				"/projects/{project_id}/gitrepos/{installation_id}/repos/{kind}/{owner}/{name}/{branch}/contents",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveGitInstallationAccess(
+						requestlog.NewHandler(a.HandleGetBranchContents, l),
+						mw.URLParam,
+					),
+					mw.URLParam,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"GET",
+				"/projects/{project_id}/gitrepos/{installation_id}/repos/{kind}/{owner}/{name}/{branch}/procfile",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveGitInstallationAccess(
+						requestlog.NewHandler(a.HandleGetProcfileContents, l),
+						mw.URLParam,
+					),
+					mw.URLParam,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"GET",
+				"/projects/{project_id}/gitrepos/{installation_id}/repos/{kind}/{owner}/{name}/{branch}/tarball_url",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveGitInstallationAccess(
+						requestlog.NewHandler(a.HandleGetRepoZIPDownloadURL, l),
+						mw.URLParam,
+					),
+					mw.URLParam,
+					mw.ReadAccess,
+				),
+			)
+
+			// /api/projects/{project_id}/k8s routes
+			r.Method(
+				"GET",
+				"/projects/{project_id}/k8s/namespaces",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						auth.DoesUserHaveClusterWideAccess(
+							requestlog.NewHandler(a.HandleListNamespaces, l),
+						),
+						mw.URLParam,
 						mw.QueryParam,
 					),
 					mw.URLParam,
@@ -1385,7 +1858,7 @@ func New(a *api.App) *chi.Mux {
 						mw.QueryParam,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					mw.WriteAccess,
 				),
 			)
 
@@ -1399,7 +1872,7 @@ func New(a *api.App) *chi.Mux {
 						mw.QueryParam,
 					),
 					mw.URLParam,
-					mw.ReadAccess,
+					mw.WriteAccess,
 				),
 			)
 
@@ -1408,7 +1881,9 @@ func New(a *api.App) *chi.Mux {
 				"/projects/{project_id}/k8s/kubeconfig",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleGetTemporaryKubeconfig, l),
+						auth.DoesUserHaveClusterWideAccess(
+							requestlog.NewHandler(a.HandleGetTemporaryKubeconfig, l),
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1450,7 +1925,10 @@ func New(a *api.App) *chi.Mux {
 				"/projects/{project_id}/k8s/metrics",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleGetPodMetrics, l),
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleGetPodMetrics, l),
+							mw.QueryParam,
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1464,7 +1942,10 @@ func New(a *api.App) *chi.Mux {
 				"/projects/{project_id}/k8s/{namespace}/pod/{name}/logs",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleGetPodLogs, l),
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleGetPodLogs, l),
+							mw.URLParam,
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1478,7 +1959,10 @@ func New(a *api.App) *chi.Mux {
 				"/projects/{project_id}/k8s/{namespace}/{chart}/{release_name}/jobs",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleListJobsByChart, l),
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleListJobsByChart, l),
+							mw.URLParam,
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1492,7 +1976,11 @@ func New(a *api.App) *chi.Mux {
 				"/projects/{project_id}/k8s/{namespace}/{name}/jobs/status",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleGetJobStatus, l),
+						auth.DoesUserHaveApplicationAccess(
+							requestlog.NewHandler(a.HandleGetJobStatus, l),
+							mw.URLParam,
+							mw.URLParam,
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1506,7 +1994,10 @@ func New(a *api.App) *chi.Mux {
 				"/projects/{project_id}/k8s/jobs/{namespace}/{name}/pods",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleListJobPods, l),
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleListJobPods, l),
+							mw.URLParam,
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1520,7 +2011,61 @@ func New(a *api.App) *chi.Mux {
 				"/projects/{project_id}/k8s/{namespace}/ingress/{name}",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleGetIngress, l),
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleGetIngress, l),
+							mw.URLParam,
+						),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"GET",
+				"/projects/{project_id}/k8s/{namespace}/graph",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleGetNamespaceGraph, l),
+							mw.URLParam,
+						),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"GET",
+				"/projects/{project_id}/k8s/{namespace}/export",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleExportNamespace, l),
+							mw.URLParam,
+						),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"GET",
+				"/projects/{project_id}/k8s/{namespace}/env_groups",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleListEnvGroups, l),
+							mw.URLParam,
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1529,12 +2074,99 @@ func New(a *api.App) *chi.Mux {
 				),
 			)
 
+			r.Method(
+				"POST",
+				"/projects/{project_id}/k8s/{namespace}/env_groups",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleCreateEnvGroup, l),
+							mw.URLParam,
+						),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"GET",
+				"/projects/{project_id}/k8s/{namespace}/env_groups/{name}",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleGetEnvGroup, l),
+							mw.URLParam,
+						),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"DELETE",
+				"/projects/{project_id}/k8s/{namespace}/env_groups/{name}",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleDeleteEnvGroup, l),
+							mw.URLParam,
+						),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"GET",
+				"/projects/{project_id}/k8s/{namespace}/env_groups/{name}/versions",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleListEnvGroupVersions, l),
+							mw.URLParam,
+						),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"DELETE",
+				"/projects/{project_id}/k8s/{namespace}/env_groups/{name}/releases/{release_name}",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleUnlinkEnvGroupRelease, l),
+							mw.URLParam,
+						),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					mw.WriteAccess,
+				),
+			)
+
 			r.Method(
 				"GET",
 				"/projects/{project_id}/k8s/{kind}/status",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleStreamControllerStatus, l),
+						auth.DoesUserHaveClusterWideAccess(
+							requestlog.NewHandler(a.HandleStreamControllerStatus, l),
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1548,7 +2180,10 @@ func New(a *api.App) *chi.Mux {
 				"/projects/{project_id}/k8s/helm_releases",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleStreamHelmReleases, l),
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleStreamHelmReleases, l),
+							mw.QueryParam,
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1562,7 +2197,10 @@ func New(a *api.App) *chi.Mux {
 				"/projects/{project_id}/k8s/pods",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleListPods, l),
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleListPods, l),
+							mw.QueryParam,
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1576,7 +2214,10 @@ func New(a *api.App) *chi.Mux {
 				"/projects/{project_id}/k8s/pods/{namespace}/{name}",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleDeletePod, l),
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleDeletePod, l),
+							mw.URLParam,
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1590,7 +2231,10 @@ func New(a *api.App) *chi.Mux {
 				"/projects/{project_id}/k8s/pods/{namespace}/{name}/events/list",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleListPodEvents, l),
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleListPodEvents, l),
+							mw.URLParam,
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1604,7 +2248,10 @@ func New(a *api.App) *chi.Mux {
 				"/projects/{project_id}/k8s/configmap/create",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleCreateConfigMap, l),
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleCreateConfigMap, l),
+							mw.BodyParam,
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1618,7 +2265,10 @@ func New(a *api.App) *chi.Mux {
 				"/projects/{project_id}/k8s/configmap/delete",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleDeleteConfigMap, l),
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleDeleteConfigMap, l),
+							mw.QueryParam,
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1632,7 +2282,10 @@ func New(a *api.App) *chi.Mux {
 				"/projects/{project_id}/k8s/configmap",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleGetConfigMap, l),
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleGetConfigMap, l),
+							mw.QueryParam,
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1646,7 +2299,10 @@ func New(a *api.App) *chi.Mux {
 				"/projects/{project_id}/k8s/configmap/list",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleListConfigMaps, l),
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleListConfigMaps, l),
+							mw.QueryParam,
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1660,7 +2316,10 @@ func New(a *api.App) *chi.Mux {
 				"/projects/{project_id}/k8s/configmap/update",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleUpdateConfigMap, l),
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleUpdateConfigMap, l),
+							mw.BodyParam,
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1674,7 +2333,10 @@ func New(a *api.App) *chi.Mux {
 				"/projects/{project_id}/k8s/configmap/rename",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleRenameConfigMap, l),
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleRenameConfigMap, l),
+							mw.BodyParam,
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1688,7 +2350,10 @@ func New(a *api.App) *chi.Mux {
 				"/projects/{project_id}/k8s/jobs/{namespace}/{name}",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleDeleteJob, l),
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleDeleteJob, l),
+							mw.URLParam,
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1702,7 +2367,10 @@ func New(a *api.App) *chi.Mux {
 				"/projects/{project_id}/k8s/jobs/{namespace}/{name}/stop",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleStopJob, l),
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleStopJob, l),
+							mw.URLParam,
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1717,7 +2385,9 @@ func New(a *api.App) *chi.Mux {
 				"/projects/{project_id}/k8s/subdomain",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleCreateDNSRecord, l),
+						auth.DoesUserHaveClusterWideAccess(
+							requestlog.NewHandler(a.HandleCreateDNSRecord, l),
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1746,7 +2416,10 @@ func New(a *api.App) *chi.Mux {
 				"/projects/{project_id}/deploy/{name}/{version}",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleDeployTemplate, l),
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleDeployTemplate, l),
+							mw.QueryParam,
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1760,7 +2433,10 @@ func New(a *api.App) *chi.Mux {
 				"/projects/{project_id}/deploy/addon/{name}/{version}",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleDeployAddon, l),
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleDeployAddon, l),
+							mw.QueryParam,
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1779,7 +2455,11 @@ func New(a *api.App) *chi.Mux {
 				"/projects/{project_id}/releases/{name}/rollback",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleRollbackRelease, l),
+						auth.DoesUserHaveApplicationAccess(
+							requestlog.NewHandler(a.HandleRollbackRelease, l),
+							mw.QueryParam,
+							mw.URLParam,
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1799,7 +2479,11 @@ func New(a *api.App) *chi.Mux {
 				"/projects/{project_id}/delete/{name}",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleUninstallTemplate, l),
+						auth.DoesUserHaveApplicationAccess(
+							requestlog.NewHandler(a.HandleUninstallTemplate, l),
+							mw.QueryParam,
+							mw.URLParam,
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1813,7 +2497,29 @@ func New(a *api.App) *chi.Mux {
 				"/projects/{project_id}/releases/{name}/upgrade",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleUpgradeRelease, l),
+						auth.DoesUserHaveApplicationAccess(
+							requestlog.NewHandler(a.HandleUpgradeRelease, l),
+							mw.QueryParam,
+							mw.URLParam,
+						),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/releases/{name}/upgrade/preview",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						auth.DoesUserHaveApplicationAccess(
+							requestlog.NewHandler(a.HandleUpgradeReleasePreview, l),
+							mw.QueryParam,
+							mw.URLParam,
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1827,7 +2533,27 @@ func New(a *api.App) *chi.Mux {
 				"/projects/{project_id}/releases/image/update/batch",
 				auth.DoesUserHaveProjectAccess(
 					auth.DoesUserHaveClusterAccess(
-						requestlog.NewHandler(a.HandleReleaseUpdateJobImages, l),
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleReleaseUpdateJobImages, l),
+							mw.QueryParam,
+						),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"PUT",
+				"/projects/{project_id}/k8s/{namespace}/env_groups/{name}",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleUpdateEnvGroup, l),
+							mw.URLParam,
+						),
 						mw.URLParam,
 						mw.QueryParam,
 					),
@@ -1835,6 +2561,87 @@ func New(a *api.App) *chi.Mux {
 					mw.WriteAccess,
 				),
 			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/k8s/{namespace}/env_groups/{name}/rollback",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleRollbackEnvGroup, l),
+							mw.URLParam,
+						),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/k8s/{namespace}/env_groups/{name}/releases",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						auth.DoesUserHaveNamespaceAccess(
+							requestlog.NewHandler(a.HandleLinkEnvGroupRelease, l),
+							mw.URLParam,
+						),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					mw.WriteAccess,
+				),
+			)
+
+			r.Method(
+				"POST",
+				"/projects/{project_id}/registries/{registry_id}/promote",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveRegistryAccess(
+						requestlog.NewHandler(a.HandlePromoteImage, l),
+						mw.URLParam,
+						mw.URLParam,
+					),
+					mw.URLParam,
+					mw.WriteAccess,
+				),
+			)
+
+			// images without a scan in the registry are scanned by the local scanner
+			r.Method(
+				"GET",
+				"/projects/{project_id}/releases/{name}/vulnerabilities",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveClusterAccess(
+						auth.DoesUserHaveApplicationAccess(
+							requestlog.NewHandler(a.HandleGetReleaseVulnerabilities, l),
+							mw.QueryParam,
+							mw.URLParam,
+						),
+						mw.URLParam,
+						mw.QueryParam,
+					),
+					mw.URLParam,
+					mw.ReadAccess,
+				),
+			)
+
+			r.Method(
+				"GET",
+				"/projects/{project_id}/registries/{registry_id}/retention_policies/{policy_id}/report",
+				auth.DoesUserHaveProjectAccess(
+					auth.DoesUserHaveRegistryAccess(
+						requestlog.NewHandler(a.HandleGetRetentionReport, l),
+						mw.URLParam,
+						mw.URLParam,
+					),
+					mw.URLParam,
+					mw.ReadAccess,
+				),
+			)
 		})
 	})
 